@@ -12,9 +12,11 @@ import (
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/config"
+	"github.com/GTDGit/PPOB_BE/internal/domain"
 	"github.com/GTDGit/PPOB_BE/internal/external/firebase"
 	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
 	"github.com/GTDGit/PPOB_BE/internal/external/s3"
+	"github.com/GTDGit/PPOB_BE/internal/external/supplier"
 	"github.com/GTDGit/PPOB_BE/internal/handler"
 	"github.com/GTDGit/PPOB_BE/internal/job"
 	"github.com/GTDGit/PPOB_BE/internal/middleware"
@@ -67,6 +69,9 @@ func main() {
 	postpaidRepo := repository.NewPostpaidRepository(db)
 	transferRepo := repository.NewTransferRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	pricingRuleRepo := repository.NewPricingRuleRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
 	voucherRepo := repository.NewVoucherRepository(db)
 	contactRepo := repository.NewContactRepository(db)
 	homeRepo := repository.NewHomeRepository(db)
@@ -76,8 +81,12 @@ func main() {
 	depositRepo := repository.NewDepositRepository(db)
 	territoryRepo := repository.NewTerritoryRepository(db)
 	kycRepo := repository.NewKYCRepository(db)
+	kybRepo := repository.NewKYBRepository(db)
+	kycArtefactRepo := repository.NewKYCArtefactRepository(db)
 	adminRepo := repository.NewAdminRepository(db)
 	positionRepo := repository.NewPositionRepository(db)
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	accountLimitRepo := repository.NewAccountLimitRepository(db)
 
 	// Initialize external clients
 	gerbangClient := gerbang.NewClient(gerbang.Config{
@@ -85,6 +94,11 @@ func main() {
 		ClientID:     cfg.Gerbang.ClientID,
 		ClientSecret: cfg.Gerbang.ClientSecret,
 		Timeout:      cfg.Gerbang.Timeout,
+		Breaker: gerbang.BreakerConfig{
+			FailureThreshold:   cfg.Gerbang.BreakerFailureThreshold,
+			ErrorRateThreshold: float64(cfg.Gerbang.BreakerErrorRatePercent) / 100,
+			OpenTimeout:        cfg.Gerbang.BreakerOpenTimeout,
+		},
 	})
 
 	// Initialize S3 client for KYC files (KTP + face photos)
@@ -93,12 +107,29 @@ func main() {
 		Bucket:          cfg.S3.Bucket,
 		AccessKeyID:     cfg.S3.AccessKey,
 		SecretAccessKey: cfg.S3.SecretKey,
+		Endpoint:        cfg.S3.Endpoint,
+		UsePathStyle:    cfg.S3.UsePathStyle,
 		PublicURL:       cfg.S3.BaseURL,
 	})
 	if err != nil {
 		log.Fatalf("Failed to initialize S3 client: %v", err)
 	}
 
+	// KYC files are envelope-encrypted with per-object data keys
+	kycKeyring, err := s3.ParseKeyring(cfg.KYCArtefact.MasterKeys)
+	if err != nil {
+		log.Fatalf("Invalid KYC_MASTER_KEYS: %v", err)
+	}
+	if kycKeyring == nil {
+		if cfg.App.Env == "production" {
+			log.Fatal("KYC_MASTER_KEYS is required in production")
+		}
+		logger.Warn("KYC_MASTER_KEYS not set, KYC files are stored unencrypted")
+	}
+	if cfg.KYCArtefact.SigningSecret == "" {
+		cfg.KYCArtefact.SigningSecret = cfg.JWT.Secret
+	}
+
 	// Initialize public S3 client for app assets (service icons, etc.)
 	publicS3Client, err := s3.NewClient(s3.Config{
 		Region:          cfg.S3Public.Region,
@@ -153,13 +184,45 @@ func main() {
 		rdb,
 		cfg.JWT,
 	)
+	operatorService := service.NewOperatorService(productRepo, adminRepo, redisClient)
+	pricingService := service.NewPricingService(pricingRuleRepo, productRepo, redisClient)
+	contactService := service.NewContactService(contactRepo, operatorService, settingsRepo)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, redisClient)
+	accountLimitService := service.NewAccountLimitService(accountLimitRepo, userRepo, balanceRepo, kybRepo)
+
+	// Suppliers: Gerbang is primary, the secondary HTTP supplier is optional
+	var secondarySuppliers []supplier.Supplier
+	if cfg.Supplier.SecondaryEnabled {
+		secondarySuppliers = append(secondarySuppliers, supplier.NewHTTPSupplier(supplier.HTTPConfig{
+			Code:    "secondary",
+			BaseURL: cfg.Supplier.SecondaryBaseURL,
+			APIKey:  cfg.Supplier.SecondaryAPIKey,
+			Timeout: cfg.Supplier.SecondaryTimeout,
+		}))
+	}
+	supplierService := service.NewSupplierService(
+		supplierRepo,
+		productRepo,
+		adminRepo,
+		supplier.NewHealthTracker(cfg.Supplier.UnhealthyAfter, cfg.Supplier.UnhealthyCooldown),
+		cfg.Supplier.FailoverCodes,
+		supplier.NewGerbangSupplier(gerbangClient),
+		secondarySuppliers...,
+	)
+	reservationService := service.NewReservationService(reservationRepo, balanceRepo, cfg.Reservation.TTL)
 	prepaidService := service.NewPrepaidService(
 		prepaidRepo,
 		balanceRepo,
 		refundRepo,
 		userRepo,
 		productRepo,
-		gerbangClient,
+		contactService,
+		operatorService,
+		pricingService,
+		supplierService,
+		reservationService,
+		maintenanceService,
+		accountLimitService,
 		cfg.Fallback.PPOBEnabled,
 	)
 	postpaidService := service.NewPostpaidService(
@@ -169,7 +232,11 @@ func main() {
 		voucherRepo,
 		userRepo,
 		productRepo,
-		gerbangClient,
+		contactService,
+		supplierService,
+		reservationService,
+		maintenanceService,
+		accountLimitService,
 		cfg.Fallback.PPOBEnabled,
 	)
 	transferService := service.NewTransferService(
@@ -178,20 +245,28 @@ func main() {
 		refundRepo,
 		userRepo,
 		productRepo,
+		contactService,
 		gerbangClient,
+		reservationService,
+		maintenanceService,
+		accountLimitService,
 	)
+	reservationService.RegisterResolver(domain.TransactionTypePrepaid, prepaidService)
+	reservationService.RegisterResolver(domain.TransactionTypePostpaid, postpaidService)
+	reservationService.RegisterResolver(domain.TransactionTypeTransfer, transferService)
 	productService := service.NewProductService(productRepo, redisClient)
 	voucherService := service.NewVoucherService(voucherRepo)
-	contactService := service.NewContactService(contactRepo, productRepo)
-	homeService := service.NewHomeService(homeRepo, userRepo, balanceRepo, notificationRepo, redisClient)
+	homeService := service.NewHomeService(homeRepo, userRepo, balanceRepo, notificationRepo, redisClient, gerbangClient, maintenanceService)
 	userService := service.NewUserService(userRepo, balanceRepo, historyRepo, settingsRepo)
 	historyService := service.NewHistoryService(historyRepo)
 	notificationService := service.NewNotificationService(notificationRepo, firebaseClient)
-	depositService := service.NewDepositService(depositRepo, balanceRepo, userRepo, gerbangClient, cfg.Fallback.PaymentEnabled)
-	territoryService := service.NewTerritoryService(territoryRepo)
-	kycService := service.NewKYCService(kycRepo, userRepo, gerbangClient, s3Client, cfg.Fallback.KYCEnabled)
+	depositService := service.NewDepositService(depositRepo, balanceRepo, userRepo, gerbangClient, accountLimitService, cfg.Fallback.PaymentEnabled)
+	territoryService := service.NewTerritoryService(territoryRepo, redisClient)
+	kycArtefactService := service.NewKYCArtefactService(kycArtefactRepo, s3.NewSealedStore(s3Client, kycKeyring), cfg.KYCArtefact, cfg.App.URL, cfg.S3.BaseURL)
+	kycService := service.NewKYCService(kycRepo, userRepo, territoryRepo, gerbangClient, kycArtefactService, cfg.Fallback.KYCEnabled)
+	kybService := service.NewKYBService(kybRepo, userRepo, territoryRepo, kycArtefactService, cfg.Fallback.KYCEnabled)
 	sandboxService := service.NewSandboxService(historyRepo, balanceRepo, depositRepo, notificationService)
-	adminService := service.NewAdminService(adminRepo, emailService, s3Client, publicS3Client, cfg.Admin)
+	adminService := service.NewAdminService(adminRepo, emailService, s3Client, publicS3Client, cfg.Admin, pricingService, redisClient, maintenanceService, accountLimitService, kycArtefactService)
 	positionService := service.NewPositionService(positionRepo, adminRepo)
 	adminMailboxService := service.NewAdminMailboxService(adminRepo, emailService, emailStorageClient, cfg.Email)
 
@@ -204,15 +279,20 @@ func main() {
 	voucherHandler := handler.NewVoucherHandler(voucherService)
 	contactHandler := handler.NewContactHandler(contactService)
 	homeHandler := handler.NewHomeHandler(homeService)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, accountLimitService)
 	historyHandler := handler.NewHistoryHandler(historyService, depositService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	territoryHandler := handler.NewTerritoryHandler(territoryService)
 	kycHandler := handler.NewKYCHandler(kycService)
+	kybHandler := handler.NewKYBHandler(kybService)
+	kycArtefactHandler := handler.NewKYCArtefactHandler(kycArtefactService)
 	depositHandler := handler.NewDepositHandler(depositService, cfg.Gerbang.CallbackSecret)
 	sandboxHandler := handler.NewSandboxHandler(sandboxService)
 	adminHandler := handler.NewAdminHandler(adminService)
 	positionHandler := handler.NewPositionHandler(positionService)
+	operatorHandler := handler.NewOperatorHandler(operatorService)
+	supplierHandler := handler.NewSupplierHandler(supplierService)
+	providerHandler := handler.NewProviderHandler(gerbangClient)
 	adminMailboxHandler := handler.NewAdminMailboxHandler(adminMailboxService)
 	whatsAppWebhookHandler := handler.NewWhatsAppWebhookHandler(cfg.WhatsApp)
 	gerbangWebhookHandler := handler.NewGerbangWebhookHandler(
@@ -232,7 +312,14 @@ func main() {
 	productSyncJob := job.NewProductSyncJob(
 		gerbangClient,
 		productRepo,
+		pricingRuleRepo,
 		redisClient,
+		emailService,
+		job.ProductAlertConfig{
+			PriceChangePercent: cfg.ProductSync.AlertPriceChangePercent,
+			StatusChange:       cfg.ProductSync.AlertOnStatusChange,
+			Recipient:          cfg.ProductSync.AlertEmail,
+		},
 		logger,
 		cfg.ProductSync.Interval,
 	)
@@ -262,6 +349,66 @@ func main() {
 		logger.Info("bank sync job disabled")
 	}
 
+	// Initialize territory sync job (Start is a no-op when disabled)
+	territorySyncJob := job.NewTerritorySyncJob(
+		gerbangClient,
+		territoryRepo,
+		logger,
+		cfg.TerritorySync.Interval,
+		cfg.TerritorySync.Enabled,
+		cfg.TerritorySync.EnableOnStart,
+	)
+	go territorySyncJob.Start(context.Background())
+
+	// Background contact imports do not survive a restart
+	contactService.FailStaleImportJobs(context.Background())
+
+	// Recover balance reservations left held by interrupted payments
+	reservationRecoveryJob := job.NewReservationRecoveryJob(
+		reservationService,
+		logger,
+		cfg.Reservation.RecoveryInterval,
+	)
+	go reservationRecoveryJob.Start(context.Background())
+
+	// Close admin approval requests past their policy expiry
+	approvalExpiryJob := job.NewApprovalExpiryJob(
+		adminService,
+		logger,
+		cfg.Admin.ApprovalExpiryInterval,
+	)
+	go approvalExpiryJob.Start(context.Background())
+
+	// Escalate mailbox threads past their SLA
+	mailboxSLAJob := job.NewMailboxSLAJob(
+		adminMailboxService,
+		logger,
+		cfg.Admin.MailboxSLAInterval,
+	)
+	go mailboxSLAJob.Start(context.Background())
+
+	// Purge KYC files of abandoned sessions and expired closed accounts
+	if cfg.KYCArtefact.RetentionEnabled {
+		kycRetentionJob := job.NewKYCRetentionJob(
+			kycArtefactService,
+			logger,
+			cfg.KYCArtefact.RetentionInterval,
+		)
+		go kycRetentionJob.Start(context.Background())
+		logger.Info("kyc retention job enabled",
+			"abandoned_days", cfg.KYCArtefact.AbandonedSessionDays,
+			"verified_days", cfg.KYCArtefact.VerifiedRetentionDays,
+		)
+	}
+
+	// Apply and revert scheduled catalog changes
+	scheduledChangeJob := job.NewScheduledChangeJob(
+		adminService,
+		logger,
+		cfg.Admin.ScheduledChangeInterval,
+	)
+	go scheduledChangeJob.Start(context.Background())
+
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger(logger))
@@ -317,6 +464,7 @@ func main() {
 				adminProtected.GET("/roles", middleware.AdminRequirePermissions("roles.view"), adminHandler.ListRoles)
 				adminProtected.GET("/permissions", middleware.AdminRequirePermissions("roles.view"), adminHandler.ListPermissions)
 				adminProtected.GET("/dashboard/summary", middleware.AdminRequirePermissions("dashboard.view"), adminHandler.DashboardSummary)
+				adminProtected.GET("/providers/health", middleware.AdminRequirePermissions("dashboard.view"), providerHandler.GetHealth)
 
 				adminProtected.GET("/admins", middleware.AdminRequirePermissions("admins.view"), adminHandler.ListAdmins)
 				adminProtected.GET("/admins/:id", middleware.AdminRequirePermissions("admins.view"), adminHandler.GetAdminDetail)
@@ -343,13 +491,28 @@ func main() {
 				adminProtected.PATCH("/vouchers/:id/status", middleware.AdminRequirePermissions("vouchers.manage"), adminHandler.UpdateVoucherStatus)
 
 				adminProtected.GET("/catalog", middleware.AdminRequirePermissions("catalog.view"), adminHandler.GetCatalog)
+				adminProtected.GET("/catalog/product-changes", middleware.AdminRequirePermissions("catalog.view"), productHandler.ListProductChanges)
+				adminProtected.GET("/catalog/products/:sku/history", middleware.AdminRequirePermissions("catalog.view"), productHandler.GetProductHistory)
+				adminProtected.GET("/catalog/suppliers", middleware.AdminRequirePermissions("catalog.view"), supplierHandler.ListSuppliers)
+				adminProtected.GET("/catalog/products/:sku/suppliers", middleware.AdminRequirePermissions("catalog.view"), supplierHandler.GetRouting)
+				adminProtected.PUT("/catalog/supplier-routes", middleware.AdminRequirePermissions("catalog.manage"), supplierHandler.UpsertRoute)
+				adminProtected.DELETE("/catalog/supplier-routes/:id", middleware.AdminRequirePermissions("catalog.manage"), supplierHandler.DeleteRoute)
+				adminProtected.GET("/pricing/rules", middleware.AdminRequirePermissions("pricing.view"), adminHandler.ListPricingRules)
+				adminProtected.POST("/pricing/preview", middleware.AdminRequirePermissions("pricing.view"), adminHandler.PreviewPricingChange)
 				adminProtected.POST("/pricing/requests", middleware.AdminRequirePermissions("pricing.request"), adminHandler.CreatePricingRequest)
+				adminProtected.GET("/pricing/requests/:id/preview", middleware.AdminRequirePermissions("pricing.view"), adminHandler.PreviewPricingRequest)
 				adminProtected.POST("/finance/balance-adjustments", middleware.AdminRequirePermissions("finance.adjust_balance"), adminHandler.CreateBalanceAdjustmentRequest)
 
 				adminProtected.GET("/kyc", middleware.AdminRequirePermissions("kyc.view"), adminHandler.ListKYC)
 				adminProtected.GET("/kyc/:userId", middleware.AdminRequirePermissions("kyc.view"), adminHandler.GetKYCDetail)
 				adminProtected.POST("/kyc/:userId/approve", middleware.AdminRequirePermissions("kyc.approve"), adminHandler.ApproveKYC)
 				adminProtected.POST("/kyc/:userId/reject", middleware.AdminRequirePermissions("kyc.approve"), adminHandler.RejectKYC)
+				adminProtected.POST("/kyc/:userId/artefacts/:artefact/view", middleware.AdminRequirePermissions("kyc.view"), adminHandler.ViewKYCArtefact)
+				adminProtected.GET("/kyb", middleware.AdminRequirePermissions("kyb.view"), adminHandler.ListKYB)
+				adminProtected.GET("/kyb/:userId", middleware.AdminRequirePermissions("kyb.view"), adminHandler.GetKYBDetail)
+				adminProtected.POST("/kyb/:userId/approve", middleware.AdminRequirePermissions("kyb.approve"), adminHandler.ApproveKYB)
+				adminProtected.POST("/kyb/:userId/reject", middleware.AdminRequirePermissions("kyb.approve"), adminHandler.RejectKYB)
+				adminProtected.POST("/kyb/:userId/artefacts/:artefact/view", middleware.AdminRequirePermissions("kyb.view"), adminHandler.ViewKYBArtefact)
 
 				adminProtected.GET("/banners", middleware.AdminRequirePermissions("banners.view"), adminHandler.ListBanners)
 				adminProtected.POST("/banners", middleware.AdminRequirePermissions("banners.manage"), adminHandler.CreateBanner)
@@ -360,18 +523,40 @@ func main() {
 				adminProtected.PATCH("/catalog/services/:id", middleware.AdminRequirePermissions("catalog.view"), adminHandler.UpdateCatalogService)
 				adminProtected.POST("/catalog/services/:id/icon", middleware.AdminRequirePermissions("catalog.view"), adminHandler.UploadServiceIcon)
 
+				adminProtected.GET("/maintenance", middleware.AdminRequirePermissions("catalog.view"), adminHandler.ListMaintenanceWindows)
+				adminProtected.POST("/maintenance", middleware.AdminRequirePermissions("catalog.manage"), adminHandler.CreateMaintenanceWindow)
+				adminProtected.PATCH("/maintenance/:id", middleware.AdminRequirePermissions("catalog.manage"), adminHandler.UpdateMaintenanceWindow)
+				adminProtected.POST("/maintenance/:id/end", middleware.AdminRequirePermissions("catalog.manage"), adminHandler.EndMaintenanceWindow)
+
+				adminProtected.GET("/scheduled-changes", middleware.AdminRequireAnyPermission("catalog.view", "banners.view", "vouchers.view"), adminHandler.ListScheduledChanges)
+				adminProtected.POST("/scheduled-changes", middleware.AdminRequireAnyPermission("catalog.manage", "banners.manage", "vouchers.manage"), adminHandler.ScheduleChange)
+				adminProtected.POST("/scheduled-changes/:id/cancel", middleware.AdminRequireAnyPermission("catalog.manage", "banners.manage", "vouchers.manage"), adminHandler.CancelScheduledChange)
+
 				adminProtected.GET("/notifications", middleware.AdminRequirePermissions("notifications.view"), adminHandler.ListNotifications)
 				adminProtected.POST("/notifications/broadcast", middleware.AdminRequirePermissions("notifications.manage"), adminHandler.BroadcastNotification)
 
 				adminProtected.GET("/approvals", middleware.AdminRequirePermissions("approvals.view"), adminHandler.ListApprovals)
 				adminProtected.POST("/approvals/:id/approve", middleware.AdminRequirePermissions("approvals.act"), adminHandler.ApproveApproval)
 				adminProtected.POST("/approvals/:id/reject", middleware.AdminRequirePermissions("approvals.act"), adminHandler.RejectApproval)
+				adminProtected.POST("/approvals/:id/cancel", adminHandler.CancelApproval)
+				adminProtected.GET("/approval-policies", middleware.AdminRequirePermissions("approvals.view"), adminHandler.ListApprovalPolicies)
+				adminProtected.PUT("/approval-policies", middleware.AdminRequirePermissions("settings.manage"), adminHandler.UpsertApprovalPolicy)
 
 				adminProtected.GET("/audit-logs", middleware.AdminRequirePermissions("audit.view"), adminHandler.ListAuditLogs)
 				adminProtected.GET("/settings", middleware.AdminRequirePermissions("settings.view"), adminHandler.ListSettings)
 				adminProtected.PUT("/settings", middleware.AdminRequirePermissions("settings.manage"), adminHandler.UpsertSetting)
 				adminProtected.GET("/reference-data", middleware.AdminRequirePermissions("reference.view"), adminHandler.ListReferenceData)
 
+				// Mobile operator prefixes
+				operators := adminProtected.Group("/operators")
+				{
+					operators.GET("", middleware.AdminRequirePermissions("reference.view"), operatorHandler.ListOperators)
+					operators.GET("/detect", middleware.AdminRequirePermissions("reference.view"), operatorHandler.DetectOperator)
+					operators.POST("", middleware.AdminRequirePermissions("reference.manage"), operatorHandler.CreateOperator)
+					operators.PUT("/:id", middleware.AdminRequirePermissions("reference.manage"), operatorHandler.UpdateOperator)
+					operators.DELETE("/:id", middleware.AdminRequirePermissions("reference.manage"), operatorHandler.DeleteOperator)
+				}
+
 				adminProtected.PATCH("/admins/me/profile", adminHandler.UpdateProfile)
 				adminProtected.POST("/admins/me/avatar", adminHandler.UploadAvatar)
 				adminProtected.DELETE("/admins/me/avatar", adminHandler.RemoveAvatar)
@@ -392,14 +577,25 @@ func main() {
 				adminProtected.PATCH("/mailboxes/:id/display-name", middleware.AdminRequireAnyPermission("mailboxes.manage", "mailboxes.reply"), adminMailboxHandler.UpdateMailboxDisplayName)
 
 				adminProtected.GET("/mailboxes", middleware.AdminRequireAnyPermission("mailboxes.view_assigned", "mailboxes.view_all"), adminMailboxHandler.ListMailboxes)
+				adminProtected.GET("/mailbox-search", middleware.AdminRequireAnyPermission("mailboxes.view_assigned", "mailboxes.view_all"), adminMailboxHandler.SearchMailbox)
 				adminProtected.POST("/mailboxes", middleware.AdminRequirePermissions("mailboxes.manage"), adminMailboxHandler.CreateMailbox)
 				adminProtected.PATCH("/mailboxes/:id", middleware.AdminRequirePermissions("mailboxes.manage"), adminMailboxHandler.UpdateMailbox)
+				adminProtected.GET("/mailboxes/:id/sla", middleware.AdminRequireAnyPermission("mailboxes.view_assigned", "mailboxes.view_all"), adminMailboxHandler.GetMailboxSLAPolicy)
+				adminProtected.PUT("/mailboxes/:id/sla", middleware.AdminRequirePermissions("mailboxes.manage"), adminMailboxHandler.UpdateMailboxSLAPolicy)
 				adminProtected.GET("/mailboxes/:id/threads", middleware.AdminRequireAnyPermission("mailboxes.view_assigned", "mailboxes.view_all"), adminMailboxHandler.ListMailboxThreads)
 				adminProtected.GET("/threads/:id", middleware.AdminRequireAnyPermission("mailboxes.view_assigned", "mailboxes.view_all"), adminMailboxHandler.GetThreadDetail)
 				adminProtected.POST("/threads/:id/reply", middleware.AdminRequirePermissions("mailboxes.reply"), adminMailboxHandler.ReplyThread)
 				adminProtected.PATCH("/threads/:id/status", middleware.AdminRequirePermissions("mailboxes.status.manage"), adminMailboxHandler.UpdateThreadStatus)
 				adminProtected.PATCH("/threads/:id/important", middleware.AdminRequirePermissions("mailboxes.reply"), adminMailboxHandler.ToggleThreadImportant)
 				adminProtected.PATCH("/threads/:id/assign", middleware.AdminRequirePermissions("mailboxes.assign"), adminMailboxHandler.AssignThread)
+				adminProtected.PATCH("/threads/:id/customer", middleware.AdminRequirePermissions("mailboxes.reply"), adminMailboxHandler.LinkThreadCustomer)
+				adminProtected.POST("/threads/:id/links", middleware.AdminRequirePermissions("mailboxes.reply"), adminMailboxHandler.AddThreadLink)
+				adminProtected.DELETE("/threads/:id/links/:linkId", middleware.AdminRequirePermissions("mailboxes.reply"), adminMailboxHandler.RemoveThreadLink)
+				adminProtected.GET("/mailbox-templates", middleware.AdminRequireAnyPermission("mailboxes.view_assigned", "mailboxes.view_all"), adminMailboxHandler.ListMailboxTemplates)
+				adminProtected.POST("/mailbox-templates", middleware.AdminRequirePermissions("mailboxes.templates.manage"), adminMailboxHandler.CreateMailboxTemplate)
+				adminProtected.PATCH("/mailbox-templates/:id", middleware.AdminRequirePermissions("mailboxes.templates.manage"), adminMailboxHandler.UpdateMailboxTemplate)
+				adminProtected.DELETE("/mailbox-templates/:id", middleware.AdminRequirePermissions("mailboxes.templates.manage"), adminMailboxHandler.DeleteMailboxTemplate)
+				adminProtected.POST("/mailbox-templates/:id/render", middleware.AdminRequirePermissions("mailboxes.reply"), adminMailboxHandler.RenderMailboxTemplate)
 				adminProtected.GET("/email-logs", middleware.AdminRequirePermissions("email_logs.view"), adminMailboxHandler.ListEmailLogs)
 			}
 		}
@@ -418,6 +614,7 @@ func main() {
 		user.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
 		{
 			user.GET("/balance", homeHandler.GetBalance)
+			user.GET("/limits", userHandler.GetLimits)
 			user.GET("/profile", userHandler.GetProfile)
 			user.PUT("/profile", userHandler.UpdateProfile)
 			user.POST("/avatar", userHandler.UploadAvatar)
@@ -480,13 +677,16 @@ func main() {
 			auth.GET("/email/verify", authHandler.VerifyEmail)
 		}
 
+		// Idempotency-Key support for money-moving endpoints (after JWTAuth)
+		idempotency := middleware.Idempotency(redisClient, middleware.DefaultIdempotencyConfig())
+
 		// Prepaid routes (protected)
 		prepaid := v1.Group("/prepaid")
 		prepaid.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
 		{
 			prepaid.POST("/inquiry", prepaidHandler.Inquiry)
 			prepaid.POST("/order", prepaidHandler.CreateOrder)
-			prepaid.POST("/pay", prepaidHandler.Pay)
+			prepaid.POST("/pay", idempotency, prepaidHandler.Pay)
 		}
 
 		// Postpaid routes (protected)
@@ -494,7 +694,7 @@ func main() {
 		postpaid.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
 		{
 			postpaid.POST("/inquiry", postpaidHandler.Inquiry)
-			postpaid.POST("/pay", postpaidHandler.Pay)
+			postpaid.POST("/pay", idempotency, postpaidHandler.Pay)
 		}
 
 		// Transfer routes (protected)
@@ -502,7 +702,7 @@ func main() {
 		transfer.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
 		{
 			transfer.POST("/inquiry", transferHandler.Inquiry)
-			transfer.POST("/execute", transferHandler.Execute)
+			transfer.POST("/execute", idempotency, transferHandler.Execute)
 		}
 
 		// Products routes (PUBLIC - no auth)
@@ -529,6 +729,10 @@ func main() {
 		contacts.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
 		{
 			contacts.GET("", contactHandler.List)
+			contacts.GET("/suggestions", contactHandler.Suggestions)
+			contacts.GET("/export", contactHandler.Export)
+			contacts.POST("/import", contactHandler.Import)
+			contacts.GET("/import/:jobId", contactHandler.GetImportJob)
 			contacts.POST("", contactHandler.Create)
 			contacts.PUT("/:contactId", contactHandler.Update)
 			contacts.DELETE("/:contactId", contactHandler.Delete)
@@ -542,6 +746,7 @@ func main() {
 			territory.GET("/districts/:cityCode", territoryHandler.GetDistricts)
 			territory.GET("/sub-districts/:districtCode", territoryHandler.GetSubDistricts)
 			territory.GET("/postal-codes/:subDistrictCode", territoryHandler.GetPostalCodes)
+			territory.GET("/search", territoryHandler.Search)
 			territory.GET("/search/postal-code/:postalCode", territoryHandler.SearchByPostalCode)
 		}
 
@@ -573,6 +778,10 @@ func main() {
 			notifications.DELETE("/:id", notificationHandler.Delete)
 		}
 
+		// KYC artefact links (public): the signed, expiring token is the credential.
+		// Admin reviewers and Gerbang fetch KTP and face photos through it.
+		v1.GET("/kyc/artefacts/:token", kycArtefactHandler.Open)
+
 		// KYC routes (protected)
 		kyc := v1.Group("/kyc")
 		kyc.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
@@ -580,6 +789,7 @@ func main() {
 			kyc.GET("/status", kycHandler.GetStatus)
 			kyc.GET("/session", kycHandler.GetSession)
 			kyc.POST("/start", kycHandler.StartVerification)
+			kyc.POST("/resubmit", kycHandler.Resubmit) // Redo only the steps a rejection points at
 			kyc.POST("/cancel", kycHandler.CancelVerification)
 			kyc.POST("/ktp", kycHandler.UploadKTP)
 			kyc.POST("/face", kycHandler.UploadFacePhotos)
@@ -588,17 +798,26 @@ func main() {
 			kyc.POST("/submit", kycHandler.Submit)
 		}
 
+		// KYB routes (protected): business verification for merchants
+		kyb := v1.Group("/kyb")
+		kyb.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
+		{
+			kyb.GET("/status", kybHandler.GetStatus)
+			kyb.POST("/documents", kybHandler.UploadDocument)
+			kyb.POST("/submit", kybHandler.Submit)
+		}
+
 		// Deposit routes (protected)
 		deposit := v1.Group("/deposit")
 		deposit.Use(middleware.JWTAuth(cfg.JWT.Secret, sessionRepo))
 		{
 			deposit.GET("/methods", depositHandler.GetMethods)
-			deposit.POST("/bank-transfer", depositHandler.CreateBankTransfer)
-			deposit.POST("/qris", depositHandler.CreateQRIS)
+			deposit.POST("/bank-transfer", idempotency, depositHandler.CreateBankTransfer)
+			deposit.POST("/qris", idempotency, depositHandler.CreateQRIS)
 			deposit.GET("/retail/providers", depositHandler.GetRetailProviders)
-			deposit.POST("/retail", depositHandler.CreateRetail)
+			deposit.POST("/retail", idempotency, depositHandler.CreateRetail)
 			deposit.GET("/va/banks", depositHandler.GetVABanks)
-			deposit.POST("/va", depositHandler.CreateVA)
+			deposit.POST("/va", idempotency, depositHandler.CreateVA)
 			deposit.GET("/history", depositHandler.GetHistory)
 			deposit.GET("/:depositId", depositHandler.GetStatus)
 		}
@@ -0,0 +1,49 @@
+// Command supplierstub runs a local supplier speaking the generic HTTP
+// supplier protocol, for exercising supplier routing and failover.
+//
+//	STUB_ADDR=:8090 STUB_FAILURES=TSEL10=out_of_stock go run ./cmd/supplierstub
+package main
+
+import (
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/external/supplier"
+)
+
+func main() {
+	addr := getEnv("STUB_ADDR", ":8090")
+	latencyMS, _ := strconv.Atoi(getEnv("STUB_LATENCY_MS", "0"))
+
+	// STUB_FAILURES: comma separated SKU=code pairs, "*" matches every SKU
+	failures := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("STUB_FAILURES"), ",") {
+		sku, code, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && sku != "" && code != "" {
+			failures[sku] = code
+		}
+	}
+
+	handler := supplier.NewStubHandler(supplier.StubConfig{
+		APIKey:   os.Getenv("STUB_API_KEY"),
+		Failures: failures,
+		Latency:  time.Duration(latencyMS) * time.Millisecond,
+	})
+
+	slog.Info("supplier stub listening", slog.String("addr", addr), slog.Any("failures", failures))
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("supplier stub stopped: %v", err)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
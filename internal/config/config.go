@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,8 +23,11 @@ type Config struct {
 	Gerbang       GerbangConfig
 	S3            S3Config
 	S3Public      S3Config
+	KYCArtefact   KYCArtefactConfig
 	Fallback      FallbackConfig
 	ProductSync   ProductSyncConfig
+	Supplier      SupplierConfig
+	Reservation   ReservationConfig
 	BankCodeSync  BankCodeSyncConfig
 	TerritorySync TerritorySyncConfig
 }
@@ -76,6 +80,10 @@ type AdminConfig struct {
 	BootstrapPhone    string
 	BootstrapFullName string
 	BootstrapRoleID   string
+
+	ApprovalExpiryInterval  time.Duration // How often pending approvals past their expiry are closed
+	ScheduledChangeInterval time.Duration // How often due scheduled catalog changes are applied or reverted
+	MailboxSLAInterval      time.Duration // How often mailbox threads past their SLA are escalated
 }
 
 type OTPConfig struct {
@@ -164,6 +172,10 @@ type GerbangConfig struct {
 	ClientSecret   string
 	CallbackSecret string
 	Timeout        time.Duration
+
+	BreakerFailureThreshold int           // Consecutive failures that open an endpoint's circuit
+	BreakerErrorRatePercent int           // Failure percentage over recent calls that opens the circuit
+	BreakerOpenTimeout      time.Duration // How long a circuit stays open before probing
 }
 
 type FallbackConfig struct {
@@ -176,6 +188,27 @@ type ProductSyncConfig struct {
 	Interval      time.Duration // Sync interval (default: 15 minutes)
 	EnableOnStart bool          // Run sync immediately on startup
 	Enabled       bool          // Enable/disable sync job
+
+	AlertPriceChangePercent int    // Alert when supplier price moves by at least this percent (0 disables)
+	AlertOnStatusChange     bool   // Alert when a product is activated or deactivated
+	AlertEmail              string // Admin mailbox that receives change alerts (empty disables)
+}
+
+type SupplierConfig struct {
+	FailoverCodes     []string      // Supplier error codes that make a purchase move on to the next supplier
+	UnhealthyAfter    int           // Consecutive supplier failures before it is considered unhealthy
+	UnhealthyCooldown time.Duration // How long an unhealthy supplier is tried last
+
+	SecondaryEnabled bool // Enable the secondary HTTP supplier
+	SecondaryBaseURL string
+	SecondaryAPIKey  string
+	SecondaryTimeout time.Duration
+}
+
+// ReservationConfig controls balance reservations held during provider calls
+type ReservationConfig struct {
+	TTL              time.Duration // How long a reservation may stay held before recovery resolves it
+	RecoveryInterval time.Duration // How often the recovery job looks for expired reservations
 }
 
 type BankCodeSyncConfig struct {
@@ -187,11 +220,26 @@ type BankCodeSyncConfig struct {
 // S3Config untuk menyimpan file KYC (KTP, face photos)
 // Note: Liveness pakai Gerbang API, bukan S3 langsung
 type S3Config struct {
-	Bucket    string
-	Region    string // ap-southeast-3 (Jakarta) for data residency
-	AccessKey string
-	SecretKey string
-	BaseURL   string // untuk generate signed URLs
+	Bucket       string
+	Region       string // ap-southeast-3 (Jakarta) for data residency
+	AccessKey    string
+	SecretKey    string
+	BaseURL      string // untuk generate signed URLs
+	Endpoint     string // S3-compatible endpoint, e.g. MinIO for local testing
+	UsePathStyle bool   // MinIO addresses buckets by path
+}
+
+// KYCArtefactConfig controls encryption, viewing and retention of KYC files
+// (KTP, selfies) and KYB documents
+type KYCArtefactConfig struct {
+	MasterKeys            string        // "id:base64,..." AES-256 keys; the first wraps new data keys
+	SigningSecret         string        // Signs time-limited artefact links
+	ViewTTL               time.Duration // Lifetime of an admin viewing link
+	ProviderTTL           time.Duration // Lifetime of a link handed to Gerbang for OCR and face matching
+	RetentionEnabled      bool
+	RetentionInterval     time.Duration
+	AbandonedSessionDays  int // Files of sessions expired this long ago are purged
+	VerifiedRetentionDays int // Regulatory period after a deactivated account's last update
 }
 
 type TerritorySyncConfig struct {
@@ -246,6 +294,10 @@ func Load() (*Config, error) {
 			BootstrapPhone:    getEnv("ADMIN_BOOTSTRAP_PHONE", ""),
 			BootstrapFullName: getEnv("ADMIN_BOOTSTRAP_FULL_NAME", ""),
 			BootstrapRoleID:   getEnv("ADMIN_BOOTSTRAP_ROLE_ID", "super_admin"),
+
+			ApprovalExpiryInterval:  time.Duration(getEnvAsInt("ADMIN_APPROVAL_EXPIRY_INTERVAL_SECONDS", 600)) * time.Second,
+			ScheduledChangeInterval: time.Duration(getEnvAsInt("ADMIN_SCHEDULED_CHANGE_INTERVAL_SECONDS", 30)) * time.Second,
+			MailboxSLAInterval:      time.Duration(getEnvAsInt("ADMIN_MAILBOX_SLA_INTERVAL_SECONDS", 60)) * time.Second,
 		},
 		OTP: OTPConfig{
 			Length:         getEnvAsInt("OTP_LENGTH", 4),                             // 4 digits (SMS standard)
@@ -323,6 +375,10 @@ func Load() (*Config, error) {
 			ClientSecret:   getEnv("GERBANG_CLIENT_SECRET", ""),
 			CallbackSecret: getEnv("GERBANG_CALLBACK_SECRET", ""),
 			Timeout:        time.Duration(getEnvAsInt("GERBANG_TIMEOUT", 30)) * time.Second,
+
+			BreakerFailureThreshold: getEnvAsInt("GERBANG_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerErrorRatePercent: getEnvAsInt("GERBANG_BREAKER_ERROR_RATE_PERCENT", 50),
+			BreakerOpenTimeout:      time.Duration(getEnvAsInt("GERBANG_BREAKER_OPEN_SECONDS", 30)) * time.Second,
 		},
 		Fallback: FallbackConfig{
 			KYCEnabled:     getEnv("DUMMY_KYC_FALLBACK_ENABLED", "true") == "true",
@@ -333,6 +389,23 @@ func Load() (*Config, error) {
 			Interval:      time.Duration(getEnvAsInt("PRODUCT_SYNC_INTERVAL", 15)) * time.Minute,
 			EnableOnStart: getEnv("PRODUCT_SYNC_ON_START", "true") == "true",
 			Enabled:       getEnv("PRODUCT_SYNC_ENABLED", "true") == "true",
+
+			AlertPriceChangePercent: getEnvAsInt("PRODUCT_ALERT_PRICE_CHANGE_PERCENT", 20),
+			AlertOnStatusChange:     getEnv("PRODUCT_ALERT_ON_STATUS_CHANGE", "true") == "true",
+			AlertEmail:              getEnv("PRODUCT_ALERT_EMAIL", ""),
+		},
+		Supplier: SupplierConfig{
+			FailoverCodes:     strings.Split(getEnv("SUPPLIER_FAILOVER_CODES", "out_of_stock,unavailable,insufficient_deposit"), ","),
+			UnhealthyAfter:    getEnvAsInt("SUPPLIER_UNHEALTHY_AFTER", 3),
+			UnhealthyCooldown: time.Duration(getEnvAsInt("SUPPLIER_UNHEALTHY_COOLDOWN_SECONDS", 60)) * time.Second,
+			SecondaryEnabled:  getEnv("SUPPLIER_SECONDARY_ENABLED", "false") == "true",
+			SecondaryBaseURL:  getEnv("SUPPLIER_SECONDARY_BASE_URL", "http://localhost:8090"),
+			SecondaryAPIKey:   getEnv("SUPPLIER_SECONDARY_API_KEY", ""),
+			SecondaryTimeout:  time.Duration(getEnvAsInt("SUPPLIER_SECONDARY_TIMEOUT", 30)) * time.Second,
+		},
+		Reservation: ReservationConfig{
+			TTL:              time.Duration(getEnvAsInt("BALANCE_RESERVATION_TTL_SECONDS", 300)) * time.Second,
+			RecoveryInterval: time.Duration(getEnvAsInt("BALANCE_RESERVATION_RECOVERY_SECONDS", 60)) * time.Second,
 		},
 		BankCodeSync: BankCodeSyncConfig{
 			Interval:      time.Duration(getEnvAsInt("BANK_CODE_SYNC_INTERVAL", 4320)) * time.Minute, // 72 hours = 3 days
@@ -340,11 +413,23 @@ func Load() (*Config, error) {
 			Enabled:       getEnv("BANK_CODE_SYNC_ENABLED", "true") == "true",
 		},
 		S3: S3Config{
-			Bucket:    getEnv("S3_BUCKET", "ppob-id-kyc"),
-			Region:    getEnv("S3_REGION", "ap-southeast-3"), // Jakarta for data residency
-			AccessKey: getEnvRequired("S3_ACCESS_KEY"),
-			SecretKey: getEnvRequired("S3_SECRET_KEY"),
-			BaseURL:   getEnv("S3_BASE_URL", ""),
+			Bucket:       getEnv("S3_BUCKET", "ppob-id-kyc"),
+			Region:       getEnv("S3_REGION", "ap-southeast-3"), // Jakarta for data residency
+			AccessKey:    getEnvRequired("S3_ACCESS_KEY"),
+			SecretKey:    getEnvRequired("S3_SECRET_KEY"),
+			BaseURL:      getEnv("S3_BASE_URL", ""),
+			Endpoint:     getEnv("S3_ENDPOINT", ""),
+			UsePathStyle: getEnv("S3_USE_PATH_STYLE", "false") == "true",
+		},
+		KYCArtefact: KYCArtefactConfig{
+			MasterKeys:            getEnv("KYC_MASTER_KEYS", ""),
+			SigningSecret:         getEnv("KYC_ARTEFACT_SIGNING_SECRET", ""),
+			ViewTTL:               time.Duration(getEnvAsInt("KYC_ARTEFACT_VIEW_TTL_SECONDS", 300)) * time.Second,
+			ProviderTTL:           time.Duration(getEnvAsInt("KYC_ARTEFACT_PROVIDER_TTL_SECONDS", 600)) * time.Second,
+			RetentionEnabled:      getEnv("KYC_RETENTION_ENABLED", "false") == "true",
+			RetentionInterval:     time.Duration(getEnvAsInt("KYC_RETENTION_INTERVAL_HOURS", 24)) * time.Hour,
+			AbandonedSessionDays:  getEnvAsInt("KYC_RETENTION_ABANDONED_DAYS", 30),
+			VerifiedRetentionDays: getEnvAsInt("KYC_RETENTION_VERIFIED_DAYS", 1826), // 5 years
 		},
 		S3Public: S3Config{
 			Bucket:    getEnv("S3_PUBLIC_BUCKET", "ppob-app"),
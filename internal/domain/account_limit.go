@@ -0,0 +1,195 @@
+package domain
+
+import "strings"
+
+// AccountLimitsSettingKey is the admin_settings key holding the account limits
+const AccountLimitsSettingKey = "account_limits"
+
+// KYC levels account limits are defined for. Every status other than verified
+// (unverified, pending, rejected) counts as unverified; verified users whose
+// business passed KYB are merchants.
+const (
+	KYCLevelUnverified = "unverified"
+	KYCLevelVerified   = "verified"
+	KYCLevelMerchant   = "merchant"
+)
+
+// KYCLevel returns the limit level of a KYC status
+func KYCLevel(kycStatus string) string {
+	if kycStatus == KYCStatusVerified {
+		return KYCLevelVerified
+	}
+	return KYCLevelUnverified
+}
+
+// AccountLevel returns the limit level of a user from the KYC and KYB status
+func AccountLevel(kycStatus, kybStatus string) string {
+	level := KYCLevel(kycStatus)
+	if level == KYCLevelVerified && kybStatus == KYBStatusApproved {
+		return KYCLevelMerchant
+	}
+	return level
+}
+
+// AccountLimitRule caps an account of a KYC level, optionally only for one
+// tier. Outgoing covers prepaid, postpaid and transfer payments. A zero cap
+// means no limit.
+type AccountLimitRule struct {
+	KYCLevel        string `json:"kycLevel"`
+	Tier            string `json:"tier,omitempty"` // Empty: any tier
+	MaxBalance      int64  `json:"maxBalance"`
+	PerTransaction  int64  `json:"perTransaction"`
+	DailyOutgoing   int64  `json:"dailyOutgoing"`
+	MonthlyOutgoing int64  `json:"monthlyOutgoing"`
+}
+
+// AccountLimitConfig is the value of the account_limits setting
+type AccountLimitConfig struct {
+	Rules []AccountLimitRule `json:"rules"`
+}
+
+// DefaultAccountLimits are used until admins configure the setting. They
+// follow the e-money caps: Rp2 juta balance for unregistered accounts and
+// Rp20 juta for registered ones, with more room for verified merchants.
+func DefaultAccountLimits() *AccountLimitConfig {
+	return &AccountLimitConfig{Rules: []AccountLimitRule{
+		{KYCLevel: KYCLevelUnverified, MaxBalance: 2000000, PerTransaction: 1000000, DailyOutgoing: 2000000, MonthlyOutgoing: 20000000},
+		{KYCLevel: KYCLevelVerified, MaxBalance: 20000000, PerTransaction: 20000000, DailyOutgoing: 100000000, MonthlyOutgoing: 500000000},
+		{KYCLevel: KYCLevelMerchant, MaxBalance: 100000000, PerTransaction: 50000000, DailyOutgoing: 250000000, MonthlyOutgoing: 2000000000},
+	}}
+}
+
+// Validate checks the rules of a config
+func (c *AccountLimitConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		if rule.KYCLevel != KYCLevelUnverified && rule.KYCLevel != KYCLevelVerified && rule.KYCLevel != KYCLevelMerchant {
+			return ErrValidationFailed("Level KYC harus unverified, verified atau merchant")
+		}
+		if rule.MaxBalance < 0 || rule.PerTransaction < 0 || rule.DailyOutgoing < 0 || rule.MonthlyOutgoing < 0 {
+			return ErrValidationFailed("Limit tidak boleh negatif")
+		}
+		if rule.DailyOutgoing > 0 && rule.MonthlyOutgoing > 0 && rule.DailyOutgoing > rule.MonthlyOutgoing {
+			return ErrValidationFailed("Limit harian tidak boleh melebihi limit bulanan")
+		}
+		key := rule.KYCLevel + "|" + strings.ToUpper(rule.Tier)
+		if seen[key] {
+			return ErrValidationFailed("Limit untuk level KYC dan tier yang sama hanya boleh satu")
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Select returns the rule for a KYC status and tier: a rule for the tier wins
+// over the rule for any tier. It returns nil when no rule applies.
+func (c *AccountLimitConfig) Select(kycStatus, tier string) *AccountLimitRule {
+	return c.SelectLevel(KYCLevel(kycStatus), tier)
+}
+
+// SelectLevel returns the rule for a level and tier like Select. Merchants
+// without a rule of their own get the verified rule.
+func (c *AccountLimitConfig) SelectLevel(level, tier string) *AccountLimitRule {
+	if rule := c.selectRule(level, tier); rule != nil || level != KYCLevelMerchant {
+		return rule
+	}
+	return c.selectRule(KYCLevelVerified, tier)
+}
+
+func (c *AccountLimitConfig) selectRule(level, tier string) *AccountLimitRule {
+	var generic *AccountLimitRule
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if rule.KYCLevel != level {
+			continue
+		}
+		if rule.Tier == "" {
+			generic = rule
+			continue
+		}
+		if strings.EqualFold(rule.Tier, tier) {
+			return rule
+		}
+	}
+	return generic
+}
+
+// OutgoingUsage is what a user paid out in the current day and month
+type OutgoingUsage struct {
+	Daily   int64 `db:"daily"`
+	Monthly int64 `db:"monthly"`
+}
+
+// Account limit kinds
+const (
+	LimitPerTransaction = "per_transaction"
+	LimitDaily          = "daily"
+	LimitMonthly        = "monthly"
+	LimitMaxBalance     = "max_balance"
+)
+
+// LimitViolation names the cap an amount would break and what is left of it
+type LimitViolation struct {
+	Kind      string
+	Limit     int64
+	Remaining int64
+}
+
+// OutgoingViolation returns the first cap a payment of amount would break:
+// per transaction, then the remaining daily and monthly outgoing limits
+func (r *AccountLimitRule) OutgoingViolation(usage OutgoingUsage, amount int64) *LimitViolation {
+	if r.PerTransaction > 0 && amount > r.PerTransaction {
+		return &LimitViolation{Kind: LimitPerTransaction, Limit: r.PerTransaction, Remaining: r.PerTransaction}
+	}
+	if r.DailyOutgoing > 0 && usage.Daily+amount > r.DailyOutgoing {
+		return &LimitViolation{Kind: LimitDaily, Limit: r.DailyOutgoing, Remaining: remainingLimit(r.DailyOutgoing, usage.Daily)}
+	}
+	if r.MonthlyOutgoing > 0 && usage.Monthly+amount > r.MonthlyOutgoing {
+		return &LimitViolation{Kind: LimitMonthly, Limit: r.MonthlyOutgoing, Remaining: remainingLimit(r.MonthlyOutgoing, usage.Monthly)}
+	}
+	return nil
+}
+
+// BalanceViolation reports a top up that would take the balance above the cap
+func (r *AccountLimitRule) BalanceViolation(balance, amount int64) *LimitViolation {
+	if r.MaxBalance > 0 && balance+amount > r.MaxBalance {
+		return &LimitViolation{Kind: LimitMaxBalance, Limit: r.MaxBalance, Remaining: remainingLimit(r.MaxBalance, balance)}
+	}
+	return nil
+}
+
+func remainingLimit(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// LimitHeadroom is one cap and how much of it is left
+type LimitHeadroom struct {
+	Limit              int64  `json:"limit"` // 0: no limit
+	Used               int64  `json:"used"`
+	Remaining          int64  `json:"remaining"` // 0 when Unlimited
+	RemainingFormatted string `json:"remainingFormatted"`
+	Unlimited          bool   `json:"unlimited"`
+}
+
+// NewLimitHeadroom builds the headroom of a cap; the caller formats it
+func NewLimitHeadroom(limit, used int64) LimitHeadroom {
+	if limit <= 0 {
+		return LimitHeadroom{Used: used, Unlimited: true}
+	}
+	return LimitHeadroom{Limit: limit, Used: used, Remaining: remainingLimit(limit, used)}
+}
+
+// AccountLimitsResponse is returned by GET /v1/user/limits
+type AccountLimitsResponse struct {
+	KYCStatus      string        `json:"kycStatus"`
+	KYBStatus      string        `json:"kybStatus"`
+	KYCLevel       string        `json:"kycLevel"`
+	Tier           string        `json:"tier"`
+	Balance        LimitHeadroom `json:"balance"`
+	PerTransaction LimitHeadroom `json:"perTransaction"`
+	Daily          LimitHeadroom `json:"daily"`
+	Monthly        LimitHeadroom `json:"monthly"`
+}
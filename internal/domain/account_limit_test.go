@@ -0,0 +1,85 @@
+package domain
+
+import "testing"
+
+func TestAccountLimitSelect(t *testing.T) {
+	config := &AccountLimitConfig{Rules: []AccountLimitRule{
+		{KYCLevel: KYCLevelUnverified, MaxBalance: 2000000},
+		{KYCLevel: KYCLevelVerified, MaxBalance: 20000000},
+		{KYCLevel: KYCLevelVerified, Tier: "GOLD", MaxBalance: 50000000},
+	}}
+
+	cases := []struct {
+		kycStatus string
+		tier      string
+		want      int64
+	}{
+		{KYCStatusUnverified, TierBasic, 2000000},
+		{KYCStatusPending, TierBasic, 2000000},
+		{KYCStatusRejected, "GOLD", 2000000},
+		{KYCStatusVerified, TierBasic, 20000000},
+		{KYCStatusVerified, "gold", 50000000},
+	}
+	for _, tc := range cases {
+		got := config.Select(tc.kycStatus, tc.tier)
+		if got == nil || got.MaxBalance != tc.want {
+			t.Errorf("Select(%s, %s) = %+v; want max balance %d", tc.kycStatus, tc.tier, got, tc.want)
+		}
+	}
+
+	if got := config.SelectLevel(AccountLevel(KYCStatusVerified, KYBStatusApproved), TierBasic); got == nil || got.MaxBalance != 20000000 {
+		t.Errorf("merchant without a rule should get the verified rule, got %+v", got)
+	}
+	if AccountLevel(KYCStatusPending, KYBStatusApproved) != KYCLevelUnverified {
+		t.Errorf("KYB approval must not lift an unverified account")
+	}
+
+	if (&AccountLimitConfig{}).Select(KYCStatusVerified, TierBasic) != nil {
+		t.Errorf("expected no rule for an empty config")
+	}
+}
+
+func TestAccountLimitViolations(t *testing.T) {
+	rule := &AccountLimitRule{
+		KYCLevel:        KYCLevelUnverified,
+		MaxBalance:      2000000,
+		PerTransaction:  1000000,
+		DailyOutgoing:   2000000,
+		MonthlyOutgoing: 5000000,
+	}
+
+	cases := []struct {
+		name      string
+		usage     OutgoingUsage
+		amount    int64
+		wantKind  string
+		remaining int64
+	}{
+		{"within limits", OutgoingUsage{Daily: 500000, Monthly: 500000}, 500000, "", 0},
+		{"per transaction", OutgoingUsage{}, 1500000, LimitPerTransaction, 1000000},
+		{"daily", OutgoingUsage{Daily: 1500000, Monthly: 1500000}, 600000, LimitDaily, 500000},
+		{"monthly", OutgoingUsage{Daily: 0, Monthly: 4800000}, 300000, LimitMonthly, 200000},
+	}
+	for _, tc := range cases {
+		got := rule.OutgoingViolation(tc.usage, tc.amount)
+		if tc.wantKind == "" {
+			if got != nil {
+				t.Errorf("%s: unexpected violation %+v", tc.name, got)
+			}
+			continue
+		}
+		if got == nil || got.Kind != tc.wantKind || got.Remaining != tc.remaining {
+			t.Errorf("%s: got %+v; want %s with %d remaining", tc.name, got, tc.wantKind, tc.remaining)
+		}
+	}
+
+	if v := rule.BalanceViolation(1800000, 300000); v == nil || v.Remaining != 200000 {
+		t.Errorf("expected max balance violation with 200000 remaining, got %+v", v)
+	}
+	if v := rule.BalanceViolation(1800000, 200000); v != nil {
+		t.Errorf("top up to exactly the cap must pass, got %+v", v)
+	}
+	if v := (&AccountLimitRule{}).OutgoingViolation(OutgoingUsage{Daily: 1 << 40}, 1<<40); v != nil {
+		t.Errorf("zero caps must not limit, got %+v", v)
+	}
+}
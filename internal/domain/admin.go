@@ -11,10 +11,12 @@ const (
 	AdminStatusActive      = "active"
 	AdminStatusDisabled    = "disabled"
 
-	ApprovalStatusPending  = "pending"
-	ApprovalStatusApproved = "approved"
-	ApprovalStatusRejected = "rejected"
-	ApprovalStatusApplied  = "applied"
+	ApprovalStatusPending   = "pending"
+	ApprovalStatusApproved  = "approved"
+	ApprovalStatusRejected  = "rejected"
+	ApprovalStatusApplied   = "applied"
+	ApprovalStatusCancelled = "cancelled"
+	ApprovalStatusExpired   = "expired"
 )
 
 type AdminUser struct {
@@ -139,6 +141,49 @@ type AdminApprovalRequest struct {
 	DecidedAt       sql.NullTime   `db:"decided_at" json:"decidedAt"`
 	ExecutedAt      sql.NullTime   `db:"executed_at" json:"executedAt"`
 	CreatedAt       time.Time      `db:"created_at" json:"createdAt"`
+
+	// Policy snapshot taken when the request was created
+	Amount            int64          `db:"amount" json:"amount"`
+	PolicyID          sql.NullString `db:"policy_id" json:"policyId"`
+	RequiredApprovals int            `db:"required_approvals" json:"requiredApprovals"`
+	ApproverRoleID    sql.NullString `db:"approver_role_id" json:"approverRoleId"`
+	ExpiresAt         sql.NullTime   `db:"expires_at" json:"expiresAt"`
+
+	Approvals int `db:"-" json:"approvals"` // Approvals recorded so far
+}
+
+// Expired reports whether a pending request passed its expiry
+func (r *AdminApprovalRequest) Expired(now time.Time) bool {
+	return r.ExpiresAt.Valid && !now.Before(r.ExpiresAt.Time)
+}
+
+// AdminApprovalPolicy sets how many approvers, and from which role, a request
+// type needs once its amount reaches MinAmount
+type AdminApprovalPolicy struct {
+	ID                string         `db:"id" json:"id"`
+	RequestType       string         `db:"request_type" json:"requestType"`
+	MinAmount         int64          `db:"min_amount" json:"minAmount"`
+	RequiredApprovals int            `db:"required_approvals" json:"requiredApprovals"`
+	ApproverRoleID    sql.NullString `db:"approver_role_id" json:"approverRoleId"`
+	ExpiryHours       int            `db:"expiry_hours" json:"expiryHours"`
+	IsActive          bool           `db:"is_active" json:"isActive"`
+	CreatedAt         time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt         time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// SelectApprovalPolicy returns the active policy of a request type with the
+// highest threshold the amount reaches, or nil when none applies
+func SelectApprovalPolicy(policies []*AdminApprovalPolicy, requestType string, amount int64) *AdminApprovalPolicy {
+	var selected *AdminApprovalPolicy
+	for _, policy := range policies {
+		if !policy.IsActive || policy.RequestType != requestType || amount < policy.MinAmount {
+			continue
+		}
+		if selected == nil || policy.MinAmount > selected.MinAmount {
+			selected = policy
+		}
+	}
+	return selected
 }
 
 type AdminSetting struct {
@@ -204,6 +249,8 @@ type AdminDashboardResponse struct {
 	PendingApprovals   int   `json:"pendingApprovals"`
 	RevenueToday       int64 `json:"revenueToday"`
 	DepositAmountToday int64 `json:"depositAmountToday"`
+
+	MailboxSLA []AdminMailboxSLAMetric `json:"mailboxSla"` // Per agent, threads started in the last 30 days
 }
 
 type AdminListResponse struct {
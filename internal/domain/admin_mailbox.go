@@ -45,6 +45,14 @@ type AdminEmailThread struct {
 	LatestMessageAt    sql.NullTime   `db:"latest_message_at" json:"latestMessageAt"`
 	LastInboundAt      sql.NullTime   `db:"last_inbound_at" json:"lastInboundAt"`
 	LastOutboundAt     sql.NullTime   `db:"last_outbound_at" json:"lastOutboundAt"`
+	CustomerUserID     sql.NullString `db:"customer_user_id" json:"customerUserId"`
+	CustomerLinkSource sql.NullString `db:"customer_link_source" json:"customerLinkSource"`
+	CustomerLinkedAt   sql.NullTime   `db:"customer_linked_at" json:"customerLinkedAt"`
+	SLAStartedAt       sql.NullTime   `db:"sla_started_at" json:"slaStartedAt"`
+	FirstResponseDueAt sql.NullTime   `db:"first_response_due_at" json:"firstResponseDueAt"`
+	ResolutionDueAt    sql.NullTime   `db:"resolution_due_at" json:"resolutionDueAt"`
+	FirstRespondedAt   sql.NullTime   `db:"first_responded_at" json:"firstRespondedAt"`
+	ResolvedAt         sql.NullTime   `db:"resolved_at" json:"resolvedAt"`
 	Meta               interface{}    `db:"meta" json:"meta"`
 	CreatedAt          time.Time      `db:"created_at" json:"createdAt"`
 	UpdatedAt          time.Time      `db:"updated_at" json:"updatedAt"`
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// How a thread got linked to its customer
+const (
+	ThreadCustomerLinkEmail  = "email"  // Sender address matches users.email
+	ThreadCustomerLinkPhone  = "phone"  // Phone number in the sender's signature
+	ThreadCustomerLinkManual = "manual" // Attached by an agent
+)
+
+// ThreadCustomerLinkVerified reports whether a link shows the sender is the
+// customer. A phone number in a signature is text any sender can write, so
+// phone links stay unverified until an agent links the customer manually.
+func ThreadCustomerLinkVerified(source string) bool {
+	return source == ThreadCustomerLinkEmail || source == ThreadCustomerLinkManual
+}
+
+// Records an agent can attach to a thread
+const (
+	ThreadLinkTransaction = "transaction"
+	ThreadLinkDeposit     = "deposit"
+	ThreadLinkKYC         = "kyc"
+)
+
+// signatureLines is how many trailing lines of a message count as signature
+const signatureLines = 12
+
+var (
+	signaturePhonePattern = regexp.MustCompile(`(?:\+62|62|0)[\s.-]?8[\d\s.-]{7,16}\d`)
+	quotedReplyPattern    = regexp.MustCompile(`(?im)^(on .+ wrote:|pada .+ menulis:|-+\s*original message\s*-+|-+\s*pesan asli\s*-+)\s*$`)
+)
+
+// AdminEmailThreadLink is a transaction, deposit or KYC record attached to a
+// thread
+type AdminEmailThreadLink struct {
+	ID           string    `db:"id" json:"id"`
+	ThreadID     string    `db:"thread_id" json:"threadId"`
+	ResourceType string    `db:"resource_type" json:"resourceType"`
+	ResourceID   string    `db:"resource_id" json:"resourceId"`
+	UserID       *string   `db:"user_id" json:"userId"`
+	CreatedBy    *string   `db:"created_by" json:"createdBy"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+}
+
+// IsThreadLinkType reports whether resourceType can be attached to a thread
+func IsThreadLinkType(resourceType string) bool {
+	switch resourceType {
+	case ThreadLinkTransaction, ThreadLinkDeposit, ThreadLinkKYC:
+		return true
+	}
+	return false
+}
+
+// SignaturePhoneNumbers returns the Indonesian mobile numbers found in the
+// signature of a plain-text message, in 08xxx format. Quoted replies are
+// ignored so a number from our own earlier message is not picked up.
+func SignaturePhoneNumbers(body string) []string {
+	if loc := quotedReplyPattern.FindStringIndex(body); loc != nil {
+		body = body[:loc[0]]
+	}
+	lines := make([]string, 0)
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ">") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > signatureLines {
+		lines = lines[len(lines)-signatureLines:]
+	}
+
+	var phones []string
+	seen := make(map[string]bool)
+	for _, match := range signaturePhonePattern.FindAllString(strings.Join(lines, "\n"), -1) {
+		phone := normalizeSignaturePhone(match)
+		if phone != "" && !seen[phone] {
+			seen[phone] = true
+			phones = append(phones, phone)
+		}
+	}
+	return phones
+}
+
+func normalizeSignaturePhone(value string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+	switch {
+	case strings.HasPrefix(digits, "62"):
+		digits = "0" + digits[2:]
+	case !strings.HasPrefix(digits, "0"):
+		return ""
+	}
+	if !strings.HasPrefix(digits, "08") || len(digits) < 10 || len(digits) > 13 {
+		return ""
+	}
+	return digits
+}
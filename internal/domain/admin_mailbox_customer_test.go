@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSignaturePhoneNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "international and local formats",
+			body: "Saldo saya belum masuk.\n\nSalam,\nBudi\nHP: +62 812-3456-7890\nWA 0813.1111.2222",
+			want: []string{"081234567890", "081311112222"},
+		},
+		{
+			name: "quoted reply ignored",
+			body: "Terima kasih.\n\nOn Mon, 1 Jan 2026 at 10:00, CS PPOB wrote:\n> Hubungi 081299998888",
+			want: nil,
+		},
+		{
+			name: "not a mobile number",
+			body: "Kantor: 021-5551234\nID transaksi 628123",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignaturePhoneNumbers(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SignaturePhoneNumbers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreadCustomerLinkVerified(t *testing.T) {
+	for source, want := range map[string]bool{
+		ThreadCustomerLinkEmail:  true,
+		ThreadCustomerLinkManual: true,
+		ThreadCustomerLinkPhone:  false,
+		"":                       false,
+	} {
+		if got := ThreadCustomerLinkVerified(source); got != want {
+			t.Errorf("ThreadCustomerLinkVerified(%q) = %v; want %v", source, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MailboxSearchQuery is a parsed mailbox search. Text goes to the full-text
+// index as is, so quoted phrases and -exclusions keep working.
+type MailboxSearchQuery struct {
+	Text          string
+	From          []string // Sender name or address fragments, any of them matches
+	HasAttachment bool
+	After         *time.Time // Messages on or after this day
+	Before        *time.Time // Messages before this day
+	Status        string     // Thread status
+}
+
+// ParseMailboxSearchQuery parses a search such as
+// `from:budi has:attachment before:2026-10-01 status:selesai "bukti transfer"`.
+// Dates are whole days in loc. Unknown operators are searched as text.
+func ParseMailboxSearchQuery(raw string, loc *time.Location) (*MailboxSearchQuery, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	query := &MailboxSearchQuery{}
+	text := make([]string, 0)
+	for _, token := range splitSearchTokens(raw) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || strings.HasPrefix(key, `"`) {
+			text = append(text, token)
+			continue
+		}
+		value = strings.TrimSpace(strings.Trim(value, `"`))
+
+		switch strings.ToLower(key) {
+		case "from":
+			if value == "" {
+				return nil, ErrValidationFailed("Operator from: membutuhkan nama atau alamat email")
+			}
+			query.From = append(query.From, strings.ToLower(value))
+		case "has":
+			if !strings.EqualFold(value, "attachment") {
+				return nil, ErrValidationFailed("Operator has: hanya mendukung has:attachment")
+			}
+			query.HasAttachment = true
+		case "before", "after":
+			day, err := time.ParseInLocation("2006-01-02", value, loc)
+			if err != nil {
+				return nil, ErrValidationFailed("Tanggal pada operator " + strings.ToLower(key) + ": harus berformat YYYY-MM-DD")
+			}
+			if strings.EqualFold(key, "before") {
+				query.Before = &day
+			} else {
+				query.After = &day
+			}
+		case "status":
+			status := strings.ToLower(value)
+			switch status {
+			case AdminEmailThreadStatusBelumDibalas, AdminEmailThreadStatusDibalas, AdminEmailThreadStatusSelesai, AdminEmailThreadStatusArsip:
+				query.Status = status
+			default:
+				return nil, ErrValidationFailed("Status harus belum_dibalas, dibalas, selesai, atau arsip")
+			}
+		default:
+			text = append(text, token)
+		}
+	}
+	query.Text = strings.Join(text, " ")
+
+	if query.Before != nil && query.After != nil && !query.After.Before(*query.Before) {
+		return nil, ErrValidationFailed("Tanggal after: harus sebelum tanggal before:")
+	}
+	if query.Text == "" && len(query.From) == 0 && !query.HasAttachment && query.Before == nil && query.After == nil && query.Status == "" {
+		return nil, ErrValidationFailed("Kata kunci pencarian wajib diisi")
+	}
+	return query, nil
+}
+
+// splitSearchTokens splits on whitespace outside double quotes, keeping the
+// quotes in the tokens
+func splitSearchTokens(raw string) []string {
+	tokens := make([]string, 0)
+	var current strings.Builder
+	inQuote := false
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
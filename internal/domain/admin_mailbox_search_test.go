@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseMailboxSearchQuery(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	query, err := ParseMailboxSearchQuery(`from:budi FROM:"Sari Dewi" has:attachment after:2026-09-01 before:2026-10-01 status:Selesai "screenshot va" bca -gagal label:vip`, jakarta)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query.Text != `"screenshot va" bca -gagal label:vip` {
+		t.Errorf("text = %q", query.Text)
+	}
+	if !reflect.DeepEqual(query.From, []string{"budi", "sari dewi"}) {
+		t.Errorf("from = %v", query.From)
+	}
+	if !query.HasAttachment || query.Status != AdminEmailThreadStatusSelesai {
+		t.Errorf("hasAttachment = %v, status = %q", query.HasAttachment, query.Status)
+	}
+	if want := time.Date(2026, 9, 1, 0, 0, 0, 0, jakarta); query.After == nil || !query.After.Equal(want) {
+		t.Errorf("after = %v, want %v", query.After, want)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, jakarta); query.Before == nil || !query.Before.Equal(want) {
+		t.Errorf("before = %v, want %v", query.Before, want)
+	}
+}
+
+func TestParseMailboxSearchQueryRejects(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"   ",
+		"status:open",
+		"has:image",
+		"before:01-10-2026",
+		"from:",
+		"after:2026-10-01 before:2026-09-01",
+	} {
+		if _, err := ParseMailboxSearchQuery(raw, nil); err == nil {
+			t.Errorf("%q: expected an error", raw)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// How new threads of a mailbox are assigned
+const (
+	MailboxAutoAssignNone       = "none"
+	MailboxAutoAssignRoundRobin = "round_robin" // Member assigned least recently
+	MailboxAutoAssignLoadBased  = "load_based"  // Member with the fewest open threads
+)
+
+// What happens when a thread breaches its SLA
+const (
+	MailboxEscalationNotify   = "notify"   // Email the assignee and the escalation admin
+	MailboxEscalationReassign = "reassign" // Move the thread to another member, then notify
+)
+
+// SLA targets a thread can breach
+const (
+	MailboxSLAFirstResponse = "first_response"
+	MailboxSLAResolution    = "resolution"
+)
+
+// DefaultMailboxSLATimezone is where business hours are read when a policy
+// sets none
+const DefaultMailboxSLATimezone = "Asia/Jakarta"
+
+// BusinessWindow is one working period on a weekday (0 = Sunday), e.g.
+// 08:00-17:00
+type BusinessWindow struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// BusinessHours are the periods SLA timers run in. No windows means the
+// timers run around the clock.
+type BusinessHours []BusinessWindow
+
+// Scan reads business hours stored as JSONB
+func (h *BusinessHours) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported business hours type %T", value)
+	}
+	return json.Unmarshal(raw, h)
+}
+
+// Validate checks every window has a valid weekday and start before end
+func (h BusinessHours) Validate() error {
+	for _, window := range h {
+		if window.Weekday < 0 || window.Weekday > 6 {
+			return ErrValidationFailed("Hari jam kerja harus 0 (Minggu) sampai 6 (Sabtu)")
+		}
+		start, errStart := time.Parse("15:04", window.Start)
+		end, errEnd := time.Parse("15:04", window.End)
+		if errStart != nil || errEnd != nil {
+			return ErrValidationFailed("Jam kerja harus berformat HH:MM")
+		}
+		if !start.Before(end) {
+			return ErrValidationFailed("Jam mulai kerja harus sebelum jam selesai")
+		}
+	}
+	return nil
+}
+
+// AddBusinessMinutes returns the moment minutes of business time have passed
+// since from, counting only the windows in loc
+func (h BusinessHours) AddBusinessMinutes(from time.Time, minutes int, loc *time.Location) time.Time {
+	remaining := time.Duration(minutes) * time.Minute
+	if len(h) == 0 || h.Validate() != nil {
+		return from.Add(remaining)
+	}
+
+	cursor := from.In(loc)
+	day := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, loc)
+	// A year of days is plenty for any target with at least one window a week
+	for i := 0; i < 366; i++ {
+		for _, window := range h.windowsOn(day) {
+			if !cursor.Before(window[1]) {
+				continue
+			}
+			start := window[0]
+			if cursor.After(start) {
+				start = cursor
+			}
+			available := window[1].Sub(start)
+			if remaining <= available {
+				return start.Add(remaining)
+			}
+			remaining -= available
+			cursor = window[1]
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return from.Add(time.Duration(minutes) * time.Minute)
+}
+
+// windowsOn returns the start and end of the windows on day, in order
+func (h BusinessHours) windowsOn(day time.Time) [][2]time.Time {
+	var windows [][2]time.Time
+	for _, window := range h {
+		if window.Weekday != int(day.Weekday()) {
+			continue
+		}
+		start, _ := time.Parse("15:04", window.Start)
+		end, _ := time.Parse("15:04", window.End)
+		windows = append(windows, [2]time.Time{
+			time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, day.Location()),
+			time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, day.Location()),
+		})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i][0].Before(windows[j][0]) })
+	return windows
+}
+
+// AdminMailboxSLAPolicy holds the response targets and assignment rules of a
+// mailbox
+type AdminMailboxSLAPolicy struct {
+	MailboxID            string         `db:"mailbox_id" json:"mailboxId"`
+	FirstResponseMinutes int            `db:"first_response_minutes" json:"firstResponseMinutes"`
+	ResolutionMinutes    int            `db:"resolution_minutes" json:"resolutionMinutes"`
+	BusinessHours        BusinessHours  `db:"business_hours" json:"businessHours"`
+	Timezone             string         `db:"timezone" json:"timezone"`
+	AutoAssignMode       string         `db:"auto_assign_mode" json:"autoAssignMode"`
+	EscalationAction     string         `db:"escalation_action" json:"escalationAction"`
+	EscalationAdminID    sql.NullString `db:"escalation_admin_id" json:"escalationAdminId"`
+	IsActive             bool           `db:"is_active" json:"isActive"`
+	UpdatedBy            sql.NullString `db:"updated_by" json:"updatedBy"`
+	CreatedAt            time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt            time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// Location returns the timezone business hours are read in
+func (p *AdminMailboxSLAPolicy) Location() *time.Location {
+	if loc, err := time.LoadLocation(p.Timezone); err == nil {
+		return loc
+	}
+	loc, err := time.LoadLocation(DefaultMailboxSLATimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DueTimes returns the first response and resolution deadlines of a thread
+// opened at openedAt; a target of zero minutes has no deadline
+func (p *AdminMailboxSLAPolicy) DueTimes(openedAt time.Time) (*time.Time, *time.Time) {
+	loc := p.Location()
+	var firstResponse, resolution *time.Time
+	if p.FirstResponseMinutes > 0 {
+		due := p.BusinessHours.AddBusinessMinutes(openedAt, p.FirstResponseMinutes, loc)
+		firstResponse = &due
+	}
+	if p.ResolutionMinutes > 0 {
+		due := p.BusinessHours.AddBusinessMinutes(openedAt, p.ResolutionMinutes, loc)
+		resolution = &due
+	}
+	return firstResponse, resolution
+}
+
+// MailboxAssignee is a mailbox member that can take threads
+type MailboxAssignee struct {
+	AdminID        string       `db:"admin_user_id"`
+	Email          string       `db:"email"`
+	FullName       string       `db:"full_name"`
+	OpenThreads    int          `db:"open_threads"`
+	LastAssignedAt sql.NullTime `db:"last_assigned_at"`
+}
+
+// PickMailboxAssignee chooses who gets the next thread under mode, skipping
+// exclude. Round robin takes the member assigned least recently (never
+// assigned first); load based takes the member with the fewest open threads
+// and breaks ties the round-robin way.
+func PickMailboxAssignee(mode string, candidates []MailboxAssignee, exclude string) *MailboxAssignee {
+	var best *MailboxAssignee
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.AdminID == exclude {
+			continue
+		}
+		if best == nil {
+			best = candidate
+			continue
+		}
+		if mode == MailboxAutoAssignLoadBased && candidate.OpenThreads != best.OpenThreads {
+			if candidate.OpenThreads < best.OpenThreads {
+				best = candidate
+			}
+			continue
+		}
+		if assignedEarlier(candidate, best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func assignedEarlier(a, b *MailboxAssignee) bool {
+	switch {
+	case !a.LastAssignedAt.Valid && !b.LastAssignedAt.Valid:
+		return a.AdminID < b.AdminID
+	case !a.LastAssignedAt.Valid:
+		return true
+	case !b.LastAssignedAt.Valid:
+		return false
+	case a.LastAssignedAt.Time.Equal(b.LastAssignedAt.Time):
+		return a.AdminID < b.AdminID
+	}
+	return a.LastAssignedAt.Time.Before(b.LastAssignedAt.Time)
+}
+
+// MailboxSLABreach is a thread that just missed one of its SLA targets
+type MailboxSLABreach struct {
+	ThreadID          string         `db:"thread_id"`
+	MailboxID         string         `db:"mailbox_id"`
+	MailboxAddress    string         `db:"mailbox_address"`
+	Subject           string         `db:"subject"`
+	ParticipantEmail  string         `db:"participant_email"`
+	AssignedAdminID   sql.NullString `db:"assigned_admin_id"`
+	Target            string         `db:"target"`
+	DueAt             time.Time      `db:"due_at"`
+	EscalationAction  string         `db:"escalation_action"`
+	EscalationAdminID sql.NullString `db:"escalation_admin_id"`
+	AutoAssignMode    string         `db:"auto_assign_mode"`
+}
+
+// AdminMailboxSLAMetric is one agent's SLA performance over the dashboard
+// window
+type AdminMailboxSLAMetric struct {
+	AdminID                 string  `db:"admin_id" json:"adminId"`
+	AdminName               string  `db:"admin_name" json:"adminName"`
+	OpenThreads             int     `db:"open_threads" json:"openThreads"`
+	OpenBreached            int     `db:"open_breached" json:"openBreached"`
+	FirstResponses          int     `db:"first_responses" json:"firstResponses"`
+	FirstResponseBreached   int     `db:"first_response_breached" json:"firstResponseBreached"`
+	AvgFirstResponseMinutes float64 `db:"avg_first_response_minutes" json:"avgFirstResponseMinutes"`
+	Resolved                int     `db:"resolved" json:"resolved"`
+	ResolutionBreached      int     `db:"resolution_breached" json:"resolutionBreached"`
+	AvgResolutionMinutes    float64 `db:"avg_resolution_minutes" json:"avgResolutionMinutes"`
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestBusinessHoursAddBusinessMinutes(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*3600)
+	weekdays := BusinessHours{}
+	for day := 1; day <= 5; day++ {
+		weekdays = append(weekdays, BusinessWindow{Weekday: day, Start: "08:00", End: "17:00"})
+	}
+
+	tests := []struct {
+		name    string
+		hours   BusinessHours
+		from    time.Time
+		minutes int
+		want    time.Time
+	}{
+		{
+			name:    "within the same window",
+			hours:   weekdays,
+			from:    time.Date(2026, 10, 14, 9, 0, 0, 0, loc), // Wednesday
+			minutes: 120,
+			want:    time.Date(2026, 10, 14, 11, 0, 0, 0, loc),
+		},
+		{
+			name:    "carries over to the next morning",
+			hours:   weekdays,
+			from:    time.Date(2026, 10, 14, 16, 0, 0, 0, loc),
+			minutes: 120,
+			want:    time.Date(2026, 10, 15, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "received over the weekend starts on Monday",
+			hours:   weekdays,
+			from:    time.Date(2026, 10, 17, 10, 0, 0, 0, loc), // Saturday
+			minutes: 60,
+			want:    time.Date(2026, 10, 19, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "no windows runs around the clock",
+			hours:   nil,
+			from:    time.Date(2026, 10, 17, 23, 30, 0, 0, loc),
+			minutes: 60,
+			want:    time.Date(2026, 10, 18, 0, 30, 0, 0, loc),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.hours.AddBusinessMinutes(tt.from, tt.minutes, loc)
+			if !got.Equal(tt.want) {
+				t.Errorf("AddBusinessMinutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessHoursValidate(t *testing.T) {
+	if err := (BusinessHours{{Weekday: 1, Start: "17:00", End: "08:00"}}).Validate(); err == nil {
+		t.Error("expected an error for a window ending before it starts")
+	}
+	if err := (BusinessHours{{Weekday: 7, Start: "08:00", End: "17:00"}}).Validate(); err == nil {
+		t.Error("expected an error for weekday 7")
+	}
+}
+
+func TestPickMailboxAssignee(t *testing.T) {
+	earlier := sql.NullTime{Time: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC), Valid: true}
+	later := sql.NullTime{Time: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC), Valid: true}
+	candidates := []MailboxAssignee{
+		{AdminID: "adm_a", OpenThreads: 1, LastAssignedAt: later},
+		{AdminID: "adm_b", OpenThreads: 4, LastAssignedAt: earlier},
+		{AdminID: "adm_c", OpenThreads: 1, LastAssignedAt: earlier},
+	}
+
+	if got := PickMailboxAssignee(MailboxAutoAssignRoundRobin, candidates, ""); got.AdminID != "adm_b" {
+		t.Errorf("round robin picked %s, want adm_b", got.AdminID)
+	}
+	if got := PickMailboxAssignee(MailboxAutoAssignLoadBased, candidates, ""); got.AdminID != "adm_c" {
+		t.Errorf("load based picked %s, want adm_c", got.AdminID)
+	}
+	if got := PickMailboxAssignee(MailboxAutoAssignLoadBased, candidates, "adm_c"); got.AdminID != "adm_a" {
+		t.Errorf("load based without adm_c picked %s, want adm_a", got.AdminID)
+	}
+	if got := PickMailboxAssignee(MailboxAutoAssignRoundRobin, candidates[:1], "adm_a"); got != nil {
+		t.Errorf("expected nobody when the only member is excluded, got %s", got.AdminID)
+	}
+}
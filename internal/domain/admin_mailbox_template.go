@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Variables a canned response can use, written as {{customer.name}}
+const (
+	MailboxVarCustomerName      = "customer.name"
+	MailboxVarCustomerPhone     = "customer.phone" // Masked: 0812****7890
+	MailboxVarLastTransactionID = "customer.last_transaction_id"
+	MailboxVarDepositStatus     = "customer.deposit_status"
+	MailboxVarAgentName         = "agent.name"
+)
+
+// MailboxTemplateVariables lists the supported variables with their
+// description for the template editor
+var MailboxTemplateVariables = []struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}{
+	{MailboxVarCustomerName, "Nama customer"},
+	{MailboxVarCustomerPhone, "Nomor HP customer (disamarkan)"},
+	{MailboxVarLastTransactionID, "ID transaksi terakhir customer"},
+	{MailboxVarDepositStatus, "Status deposit terakhir customer"},
+	{MailboxVarAgentName, "Nama agen yang membalas"},
+}
+
+var mailboxTemplateVarPattern = regexp.MustCompile(`\{\{\s*([a-z_.]+)\s*\}\}`)
+
+// AdminMailboxTemplate is a saved reply, global or scoped to one mailbox
+type AdminMailboxTemplate struct {
+	ID         string         `db:"id" json:"id"`
+	MailboxID  sql.NullString `db:"mailbox_id" json:"mailboxId"` // NULL: available in every mailbox
+	Name       string         `db:"name" json:"name"`
+	Shortcut   sql.NullString `db:"shortcut" json:"shortcut"`
+	Subject    sql.NullString `db:"subject" json:"subject"`
+	Body       string         `db:"body" json:"body"`
+	UsageCount int            `db:"usage_count" json:"usageCount"`
+	LastUsedAt sql.NullTime   `db:"last_used_at" json:"lastUsedAt"`
+	IsActive   bool           `db:"is_active" json:"isActive"`
+	CreatedBy  sql.NullString `db:"created_by" json:"createdBy"`
+	UpdatedBy  sql.NullString `db:"updated_by" json:"updatedBy"`
+	CreatedAt  time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// MailboxCustomer is the customer a thread is about, as far as templates
+// need to know
+type MailboxCustomer struct {
+	UserID            string         `db:"user_id" json:"userId"`
+	FullName          string         `db:"full_name" json:"fullName"`
+	Phone             string         `db:"phone" json:"phone"`
+	LastTransactionID sql.NullString `db:"last_transaction_id" json:"lastTransactionId"`
+	LastDepositStatus sql.NullString `db:"last_deposit_status" json:"lastDepositStatus"`
+}
+
+// TemplateValues returns the customer variables that have a value
+func (c *MailboxCustomer) TemplateValues() map[string]string {
+	values := make(map[string]string)
+	if c == nil {
+		return values
+	}
+	if name := strings.TrimSpace(c.FullName); name != "" {
+		values[MailboxVarCustomerName] = name
+	}
+	if c.Phone != "" {
+		values[MailboxVarCustomerPhone] = MaskMailboxPhone(c.Phone)
+	}
+	if c.LastTransactionID.Valid && c.LastTransactionID.String != "" {
+		values[MailboxVarLastTransactionID] = c.LastTransactionID.String
+	}
+	if c.LastDepositStatus.Valid && c.LastDepositStatus.String != "" {
+		values[MailboxVarDepositStatus] = DepositStatusLabel(c.LastDepositStatus.String)
+	}
+	return values
+}
+
+// UnknownMailboxTemplateVariables returns the variables in text that are not
+// supported
+func UnknownMailboxTemplateVariables(text string) []string {
+	known := make(map[string]bool, len(MailboxTemplateVariables))
+	for _, variable := range MailboxTemplateVariables {
+		known[variable.Key] = true
+	}
+	var unknown []string
+	for _, match := range mailboxTemplateVarPattern.FindAllStringSubmatch(text, -1) {
+		if !known[match[1]] {
+			unknown = append(unknown, match[1])
+		}
+	}
+	return unknown
+}
+
+// RenderMailboxTemplate fills the variables of text from values. Variables
+// without a value are left in place for the agent to complete, and returned.
+func RenderMailboxTemplate(text string, values map[string]string) (string, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+	rendered := mailboxTemplateVarPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		key := mailboxTemplateVarPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[key]; ok {
+			return value
+		}
+		if !seen[key] {
+			seen[key] = true
+			missing = append(missing, key)
+		}
+		return placeholder
+	})
+	return rendered, missing
+}
+
+// MaskMailboxPhone keeps the prefix and last four digits of a phone number
+func MaskMailboxPhone(phone string) string {
+	if len(phone) < 8 {
+		return "****"
+	}
+	return phone[:4] + "****" + phone[len(phone)-4:]
+}
+
+// DepositStatusLabel is how a deposit status is worded to customers
+func DepositStatusLabel(status string) string {
+	switch status {
+	case DepositStatusPending:
+		return "Menunggu Pembayaran"
+	case DepositStatusSuccess:
+		return "Berhasil"
+	case DepositStatusExpired:
+		return "Kedaluwarsa"
+	case DepositStatusFailed:
+		return "Gagal"
+	case DepositStatusReview:
+		return "Dalam Peninjauan"
+	}
+	return status
+}
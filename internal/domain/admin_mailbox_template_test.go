@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestRenderMailboxTemplate(t *testing.T) {
+	customer := &MailboxCustomer{
+		FullName:          "Budi Santoso",
+		Phone:             "081234567890",
+		LastDepositStatus: sql.NullString{String: DepositStatusPending, Valid: true},
+	}
+	values := customer.TemplateValues()
+	values[MailboxVarAgentName] = "Sari"
+
+	text := "Halo {{ customer.name }}, deposit kamu {{customer.deposit_status}} untuk nomor {{customer.phone}}. " +
+		"Transaksi {{customer.last_transaction_id}} / {{customer.last_transaction_id}}. Salam, {{agent.name}}"
+	rendered, missing := RenderMailboxTemplate(text, values)
+
+	want := "Halo Budi Santoso, deposit kamu Menunggu Pembayaran untuk nomor 0812****7890. " +
+		"Transaksi {{customer.last_transaction_id}} / {{customer.last_transaction_id}}. Salam, Sari"
+	if rendered != want {
+		t.Errorf("rendered = %q\nwant %q", rendered, want)
+	}
+	if !reflect.DeepEqual(missing, []string{MailboxVarLastTransactionID}) {
+		t.Errorf("missing = %v, want the transaction ID once", missing)
+	}
+
+	var noCustomer *MailboxCustomer
+	if len(noCustomer.TemplateValues()) != 0 {
+		t.Errorf("a thread without customer must have no customer values")
+	}
+}
+
+func TestUnknownMailboxTemplateVariables(t *testing.T) {
+	unknown := UnknownMailboxTemplateVariables("Halo {{customer.name}}, saldo {{customer.balance}} {{ agent.email }}")
+	if !reflect.DeepEqual(unknown, []string{"customer.balance", "agent.email"}) {
+		t.Errorf("unknown = %v", unknown)
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestSelectApprovalPolicy(t *testing.T) {
+	policies := []*AdminApprovalPolicy{
+		{ID: "base", RequestType: "balance_adjustment", MinAmount: 0, RequiredApprovals: 1, IsActive: true},
+		{ID: "large", RequestType: "balance_adjustment", MinAmount: 10000000, RequiredApprovals: 2, IsActive: true},
+		{ID: "huge", RequestType: "balance_adjustment", MinAmount: 100000000, RequiredApprovals: 3, IsActive: false},
+		{ID: "price", RequestType: "price_change", MinAmount: 0, RequiredApprovals: 1, IsActive: true},
+	}
+
+	cases := []struct {
+		requestType string
+		amount      int64
+		want        string
+	}{
+		{"balance_adjustment", 50000, "base"},
+		{"balance_adjustment", 10000000, "large"},
+		{"balance_adjustment", 500000000, "large"}, // inactive policy is skipped
+		{"price_change", 0, "price"},
+		{"refund", 50000, ""},
+	}
+	for _, tc := range cases {
+		got := SelectApprovalPolicy(policies, tc.requestType, tc.amount)
+		gotID := ""
+		if got != nil {
+			gotID = got.ID
+		}
+		if gotID != tc.want {
+			t.Errorf("SelectApprovalPolicy(%s, %d) = %q; want %q", tc.requestType, tc.amount, gotID, tc.want)
+		}
+	}
+}
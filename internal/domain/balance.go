@@ -77,3 +77,27 @@ const (
 	BalanceCategoryPoints      = "points"
 	BalanceCategoryFee         = "fee"
 )
+
+// BalanceReservation holds funds moved from amount to pending_amount while a
+// provider call is in flight. It is settled when the provider accepts the
+// transaction and released when it is rejected.
+type BalanceReservation struct {
+	ID               string     `db:"id" json:"id"`
+	UserID           string     `db:"user_id" json:"userId"`
+	Amount           int64      `db:"amount" json:"amount"`
+	ReferenceType    string     `db:"reference_type" json:"referenceType"` // prepaid, postpaid, transfer
+	ReferenceID      string     `db:"reference_id" json:"referenceId"`     // Prepaid order ID, postpaid inquiry ID, transfer transaction ID
+	Status           string     `db:"status" json:"status"`
+	ExpiresAt        time.Time  `db:"expires_at" json:"expiresAt"`
+	ProviderCalledAt *time.Time `db:"provider_called_at" json:"providerCalledAt"` // Nil: the provider never saw the payment
+	ResolvedAt       *time.Time `db:"resolved_at" json:"resolvedAt"`
+	CreatedAt        time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// Balance reservation statuses
+const (
+	ReservationHeld     = "held"
+	ReservationSettled  = "settled"
+	ReservationReleased = "released"
+)
@@ -25,3 +25,65 @@ const (
 	ContactTypeTelkom = "telkom"
 	ContactTypeBank   = "bank"
 )
+
+// ContactTypeForService maps a transaction service type to the contact type
+// its target is saved under. Returns an empty string for services whose
+// targets are not remembered as contacts (game, PGN, PBB, TV cable).
+func ContactTypeForService(serviceType string) string {
+	switch serviceType {
+	case ServicePulsa, ServiceData, ServiceEwallet, ServicePhonePostpaid:
+		return ContactTypePhone
+	case ServicePLNPrepaid, ServicePLNPostpaid:
+		return ContactTypePLN
+	case ServicePDAM:
+		return ContactTypePDAM
+	case ServiceBPJS:
+		return ContactTypeBPJS
+	case ServiceTelkom:
+		return ContactTypeTelkom
+	case TransactionTypeTransfer:
+		return ContactTypeBank
+	default:
+		return ""
+	}
+}
+
+// ContactImportJob tracks a bulk contact import processed in the background
+type ContactImportJob struct {
+	ID             string     `db:"id" json:"id"`
+	UserID         string     `db:"user_id" json:"userId"`
+	Status         string     `db:"status" json:"status"`
+	Format         string     `db:"format" json:"format"`
+	FileName       *string    `db:"file_name" json:"fileName"`
+	DryRun         bool       `db:"dry_run" json:"dryRun"`
+	TotalRows      int        `db:"total_rows" json:"totalRows"`
+	CreatedCount   int        `db:"created_count" json:"createdCount"`
+	DuplicateCount int        `db:"duplicate_count" json:"duplicateCount"`
+	InvalidCount   int        `db:"invalid_count" json:"invalidCount"`
+	Results        *string    `db:"results" json:"results"` // JSON array of ContactImportRowResult
+	ErrorMessage   *string    `db:"error_message" json:"errorMessage"`
+	CompletedAt    *time.Time `db:"completed_at" json:"completedAt"`
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// Contact import job statuses
+const (
+	ContactImportProcessing = "processing"
+	ContactImportCompleted  = "completed"
+	ContactImportFailed     = "failed"
+)
+
+// Contact import row statuses
+const (
+	ContactImportRowCreated   = "created"
+	ContactImportRowValid     = "valid" // Dry run: row would be created
+	ContactImportRowDuplicate = "duplicate"
+	ContactImportRowInvalid   = "invalid"
+)
+
+// Contact import/export file formats
+const (
+	ContactFileCSV  = "csv"
+	ContactFileXLSX = "xlsx"
+)
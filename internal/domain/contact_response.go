@@ -14,6 +14,7 @@ type ContactDetail struct {
 	Value        string        `json:"value"`
 	Operator     *OperatorInfo `json:"operator,omitempty"`
 	Bank         *BankInfo     `json:"bank,omitempty"`
+	Region       *RegionInfo   `json:"region,omitempty"`
 	CustomerName *string       `json:"customerName,omitempty"`
 	AccountName  *string       `json:"accountName,omitempty"`
 	LastUsedAt   *string       `json:"lastUsedAt,omitempty"`
@@ -30,6 +31,18 @@ type BankInfo struct {
 	Name string `json:"name"`
 }
 
+// RegionInfo represents PDAM region information (for PDAM contacts)
+type RegionInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ContactSuggestions represents recent and frequently used targets for a service type
+type ContactSuggestions struct {
+	Recent   []*ContactDetail `json:"recent"`
+	Frequent []*ContactDetail `json:"frequent"`
+}
+
 // ContactResponse represents single contact response
 type ContactResponse struct {
 	Contact *ContactDetail `json:"contact"`
@@ -40,3 +53,29 @@ type DeleteContactResponse struct {
 	Deleted   bool   `json:"deleted"`
 	ContactID string `json:"contactId"`
 }
+
+// ContactImportRowResult represents the outcome of a single imported row
+type ContactImportRowResult struct {
+	Row     int    `json:"row"` // 1-based row number in the file, header included
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+	Status  string `json:"status"` // created, valid, duplicate, invalid
+	Message string `json:"message,omitempty"`
+}
+
+// ContactImportResponse represents a contact import result or job status
+type ContactImportResponse struct {
+	JobID          *string                   `json:"jobId,omitempty"`
+	Status         string                    `json:"status"`
+	DryRun         bool                      `json:"dryRun"`
+	Format         string                    `json:"format"`
+	TotalRows      int                       `json:"totalRows"`
+	CreatedCount   int                       `json:"createdCount"`
+	DuplicateCount int                       `json:"duplicateCount"`
+	InvalidCount   int                       `json:"invalidCount"`
+	Rows           []*ContactImportRowResult `json:"rows,omitempty"`
+	ErrorMessage   *string                   `json:"errorMessage,omitempty"`
+	CreatedAt      *string                   `json:"createdAt,omitempty"`
+	CompletedAt    *string                   `json:"completedAt,omitempty"`
+}
@@ -93,6 +93,9 @@ const (
 	DepositStatusSuccess = "success"
 	DepositStatusExpired = "expired"
 	DepositStatusFailed  = "failed"
+	// DepositStatusReview is a paid deposit held for admin review because
+	// crediting it would exceed the user's maximum balance
+	DepositStatusReview = "review"
 )
 
 // Retail providers
@@ -86,12 +86,22 @@ const (
 	CodeInsufficientBalance = "INSUFFICIENT_BALANCE"
 
 	// Transaction Errors - 409 Conflict
-	CodeDuplicateTransaction = "DUPLICATE_TRANSACTION"
+	CodeDuplicateTransaction  = "DUPLICATE_TRANSACTION"
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
 
 	// Transaction Errors - 422 Unprocessable Entity
-	CodeProductUnavailable = "PRODUCT_UNAVAILABLE"
-	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
-	CodeNoBill             = "NO_BILL"
+	CodeProductUnavailable    = "PRODUCT_UNAVAILABLE"
+	CodeServiceUnavailable    = "SERVICE_UNAVAILABLE"
+	CodeNoBill                = "NO_BILL"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInvalid = "IDEMPOTENCY_KEY_INVALID"
+	CodeTransferLimitExceeded = "TRANSFER_LIMIT_EXCEEDED"
+	CodeAccountLimitExceeded  = "ACCOUNT_LIMIT_EXCEEDED"
+	CodeBalanceLimitExceeded  = "BALANCE_LIMIT_EXCEEDED"
+
+	// Provider Errors - 503 Service Unavailable
+	CodeProviderUnavailable = "PROVIDER_UNAVAILABLE"
+	CodeServiceMaintenance  = "SERVICE_MAINTENANCE"
 
 	// KYC Errors - 400 Bad Request
 	CodeKYCInvalidFile     = "KYC_INVALID_FILE"
@@ -110,6 +120,13 @@ const (
 	// KYC Errors - 409 Conflict
 	CodeKYCNIKAlreadyUsed = "KYC_NIK_ALREADY_USED"
 
+	// KYB Errors - 409 Conflict
+	CodeKYBNotEditable = "KYB_NOT_EDITABLE"
+
+	// KYC artefact link errors - 404 Not Found / 410 Gone
+	CodeArtefactLinkInvalid = "ARTEFACT_LINK_INVALID"
+	CodeArtefactLinkExpired = "ARTEFACT_LINK_EXPIRED"
+
 	// Deposit Errors - 400 Bad Request
 	CodeInvalidAmount   = "INVALID_AMOUNT"
 	CodeAmountTooLow    = "AMOUNT_TOO_LOW"
@@ -361,6 +378,24 @@ var (
 		HTTPStatus: http.StatusConflict,
 	}
 
+	ErrIdempotencyInProgress = &AppError{
+		Code:       CodeIdempotencyInProgress,
+		Message:    "Request dengan Idempotency-Key yang sama sedang diproses",
+		HTTPStatus: http.StatusConflict,
+	}
+
+	ErrIdempotencyKeyReused = &AppError{
+		Code:       CodeIdempotencyKeyReused,
+		Message:    "Idempotency-Key sudah dipakai untuk request yang berbeda",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+
+	ErrIdempotencyKeyInvalid = &AppError{
+		Code:       CodeIdempotencyKeyInvalid,
+		Message:    "Idempotency-Key tidak valid",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+
 	ErrProductUnavailable = &AppError{
 		Code:       CodeProductUnavailable,
 		Message:    "Produk tidak tersedia",
@@ -373,6 +408,12 @@ var (
 		HTTPStatus: http.StatusUnprocessableEntity,
 	}
 
+	ErrProviderUnavailable = &AppError{
+		Code:       CodeProviderUnavailable,
+		Message:    "Layanan mitra sedang tidak tersedia, silakan coba beberapa saat lagi",
+		HTTPStatus: http.StatusServiceUnavailable,
+	}
+
 	ErrNoBill = &AppError{
 		Code:       CodeNoBill,
 		Message:    "Tidak ada tagihan",
@@ -525,6 +566,46 @@ func ErrNotFound(resource string) *AppError {
 	}
 }
 
+// ErrServiceMaintenance creates an error for a sale blocked by a maintenance
+// window, carrying the window's user-facing message
+func ErrServiceMaintenance(message string) *AppError {
+	return &AppError{
+		Code:       CodeServiceMaintenance,
+		Message:    message,
+		HTTPStatus: http.StatusServiceUnavailable,
+	}
+}
+
+// ErrTransferLimitExceeded creates an error for a transfer above the user's
+// remaining daily or monthly limit
+func ErrTransferLimitExceeded(message string) *AppError {
+	return &AppError{
+		Code:       CodeTransferLimitExceeded,
+		Message:    message,
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+}
+
+// ErrAccountLimitExceeded creates an error for a payment above the account's
+// per-transaction, daily or monthly outgoing limit
+func ErrAccountLimitExceeded(message string) *AppError {
+	return &AppError{
+		Code:       CodeAccountLimitExceeded,
+		Message:    message,
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+}
+
+// ErrBalanceLimitExceeded creates an error for a top up that would exceed the
+// account's maximum balance
+func ErrBalanceLimitExceeded(message string) *AppError {
+	return &AppError{
+		Code:       CodeBalanceLimitExceeded,
+		Message:    message,
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+}
+
 // ErrWithRemainingAttempts creates an error with remaining attempts
 func ErrWithRemainingAttempts(baseErr *AppError, remaining int) *AppError {
 	return &AppError{
@@ -39,10 +39,12 @@ type ServiceMenu struct {
 	Icon     string  `json:"icon"`
 	IconURL  string  `json:"iconUrl"`
 	Route    string  `json:"route"`
-	Status   string  `json:"status"`          // active, maintenance, coming_soon, hidden
+	Status   string  `json:"status"`          // active, maintenance, coming_soon, hidden, unavailable
 	Badge    *string `json:"badge,omitempty"` // PROMO, NEW, HOT
 	Category string  `json:"category,omitempty"`
 	Position int     `json:"position"`
+
+	Notice *NoticeInfo `json:"notice,omitempty"` // Set while the service is under maintenance
 }
 
 // ServiceCategory represents service category
@@ -67,6 +69,7 @@ const (
 	ServiceStatusMaintenance = "maintenance"
 	ServiceStatusComingSoon  = "coming_soon"
 	ServiceStatusHidden      = "hidden"
+	ServiceStatusUnavailable = "unavailable" // Provider temporarily unreachable (circuit open)
 )
 
 // Service badges
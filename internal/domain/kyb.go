@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// KYB (business verification) status
+const (
+	KYBStatusNone     = "none" // No submission yet
+	KYBStatusDraft    = "draft"
+	KYBStatusPending  = "pending"
+	KYBStatusApproved = "approved"
+	KYBStatusRejected = "rejected"
+)
+
+// KYB documents uploaded by a merchant
+const (
+	KYBDocumentLicense    = "license"    // NIB, or SIUP for businesses licensed before OSS
+	KYBDocumentNPWP       = "npwp"       // Business or owner tax ID card
+	KYBDocumentStorefront = "storefront" // Photo of the shop front with its signage
+)
+
+// Business license types
+const (
+	KYBLicenseNIB  = "NIB"
+	KYBLicenseSIUP = "SIUP"
+)
+
+// QRIS limits the merchant name (tag 59) to 25 characters and the merchant
+// city (tag 60) to 15
+const (
+	QRISMerchantNameMaxLength = 25
+	QRISMerchantCityMaxLength = 15
+)
+
+// KYBSubmission is a merchant's business verification, one per user
+type KYBSubmission struct {
+	ID              string     `json:"id" db:"id"`
+	UserID          string     `json:"userId" db:"user_id"`
+	Status          string     `json:"status" db:"status"`
+	BusinessName    string     `json:"businessName" db:"business_name"`
+	LicenseType     string     `json:"licenseType" db:"license_type"`
+	LicenseNumber   string     `json:"licenseNumber" db:"license_number"`
+	LicenseURL      *string    `json:"licenseUrl" db:"license_url"`
+	NPWPNumber      string     `json:"npwpNumber" db:"npwp_number"`
+	NPWPURL         *string    `json:"npwpUrl" db:"npwp_url"`
+	StorefrontURL   *string    `json:"storefrontUrl" db:"storefront_url"`
+	AddressStreet   string     `json:"addressStreet" db:"address_street"`
+	ProvinceCode    string     `json:"provinceCode" db:"province_code"`
+	ProvinceName    string     `json:"provinceName" db:"province_name"`
+	CityCode        string     `json:"cityCode" db:"city_code"`
+	CityName        string     `json:"cityName" db:"city_name"`
+	DistrictCode    string     `json:"districtCode" db:"district_code"`
+	DistrictName    string     `json:"districtName" db:"district_name"`
+	SubDistrictCode string     `json:"subDistrictCode" db:"sub_district_code"`
+	SubDistrictName string     `json:"subDistrictName" db:"sub_district_name"`
+	PostalCode      string     `json:"postalCode" db:"postal_code"`
+	RejectionReason *string    `json:"rejectionReason" db:"rejection_reason"`
+	SubmittedAt     *time.Time `json:"submittedAt" db:"submitted_at"`
+	ReviewedBy      *string    `json:"-" db:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewedAt" db:"reviewed_at"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// Editable reports whether the merchant may still change the submission
+func (k *KYBSubmission) Editable() bool {
+	return k.Status == KYBStatusDraft || k.Status == KYBStatusRejected
+}
+
+// MissingDocuments lists the documents not uploaded yet
+func (k *KYBSubmission) MissingDocuments() []string {
+	missing := []string{}
+	if k.LicenseURL == nil {
+		missing = append(missing, KYBDocumentLicense)
+	}
+	if k.NPWPURL == nil {
+		missing = append(missing, KYBDocumentNPWP)
+	}
+	if k.StorefrontURL == nil {
+		missing = append(missing, KYBDocumentStorefront)
+	}
+	return missing
+}
+
+// QRISMerchantName is the name printed on the merchant's static QRIS, only
+// available once the business is approved
+func (k *KYBSubmission) QRISMerchantName() string {
+	if k == nil || k.Status != KYBStatusApproved {
+		return ""
+	}
+	return truncateRunes(strings.ToUpper(strings.TrimSpace(k.BusinessName)), QRISMerchantNameMaxLength)
+}
+
+// QRISMerchantCity is the city printed on the merchant's static QRIS, without
+// the KOTA/KABUPATEN prefix of the territory name
+func (k *KYBSubmission) QRISMerchantCity() string {
+	if k == nil || k.Status != KYBStatusApproved {
+		return ""
+	}
+	city := strings.ToUpper(strings.TrimSpace(k.CityName))
+	for _, prefix := range []string{"KOTA ADM. ", "KOTA ", "KABUPATEN ", "KAB. "} {
+		city = strings.TrimPrefix(city, prefix)
+	}
+	return truncateRunes(city, QRISMerchantCityMaxLength)
+}
+
+func truncateRunes(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return strings.TrimSpace(string([]rune(value)[:max]))
+}
+
+// ValidateKYBLicense checks a license number: a NIB has 13 digits, a SIUP
+// number is free-form as each region issued its own format
+func ValidateKYBLicense(licenseType, number string) error {
+	number = strings.TrimSpace(number)
+	switch licenseType {
+	case KYBLicenseNIB:
+		if len(number) != 13 || !isDigits(number) {
+			return ErrValidationFailed("NIB harus 13 digit angka")
+		}
+	case KYBLicenseSIUP:
+		if len(number) < 5 || len(number) > 50 {
+			return ErrValidationFailed("Nomor SIUP tidak valid")
+		}
+	default:
+		return ErrValidationFailed("Jenis izin usaha harus NIB atau SIUP")
+	}
+	return nil
+}
+
+// NormalizeNPWP strips the punctuation of an NPWP and checks its length: 15
+// digits for the legacy format, 16 since the NIK-based format
+func NormalizeNPWP(npwp string) (string, error) {
+	normalized := strings.NewReplacer(".", "", "-", "", " ", "").Replace(strings.TrimSpace(npwp))
+	if (len(normalized) != 15 && len(normalized) != 16) || !isDigits(normalized) {
+		return "", ErrValidationFailed("NPWP harus 15 atau 16 digit angka")
+	}
+	return normalized, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// KYBStatusResponse is returned by GET /v1/kyb/status
+type KYBStatusResponse struct {
+	Status           string         `json:"status"`
+	KYCVerified      bool           `json:"kycVerified"`
+	MissingDocuments []string       `json:"missingDocuments"`
+	QRISMerchantName string         `json:"qrisMerchantName,omitempty"`
+	Submission       *KYBSubmission `json:"submission"`
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "testing"
+
+func TestKYBLicenseAndNPWP(t *testing.T) {
+	if err := ValidateKYBLicense(KYBLicenseNIB, "9120001234567"); err != nil {
+		t.Errorf("valid NIB rejected: %v", err)
+	}
+	if err := ValidateKYBLicense(KYBLicenseNIB, "91200012345"); err == nil {
+		t.Errorf("expected short NIB to be rejected")
+	}
+	if err := ValidateKYBLicense(KYBLicenseSIUP, "503/123/SIUP-K/2015"); err != nil {
+		t.Errorf("valid SIUP rejected: %v", err)
+	}
+	if err := ValidateKYBLicense("TDP", "123456"); err == nil {
+		t.Errorf("expected unknown license type to be rejected")
+	}
+
+	npwp, err := NormalizeNPWP("01.234.567.8-901.000")
+	if err != nil || npwp != "012345678901000" {
+		t.Errorf("NormalizeNPWP = %q, %v; want 012345678901000", npwp, err)
+	}
+	if _, err := NormalizeNPWP("3171010101900001"); err != nil {
+		t.Errorf("16-digit NPWP rejected: %v", err)
+	}
+	if _, err := NormalizeNPWP("12.345"); err == nil {
+		t.Errorf("expected short NPWP to be rejected")
+	}
+}
+
+func TestKYBQRISMerchant(t *testing.T) {
+	submission := &KYBSubmission{
+		Status:       KYBStatusPending,
+		BusinessName: "Toko Sembako Berkah Jaya Abadi Makmur",
+		CityName:     "KOTA ADM. JAKARTA SELATAN",
+	}
+	if submission.QRISMerchantName() != "" {
+		t.Errorf("merchant name must stay locked until approval")
+	}
+
+	submission.Status = KYBStatusApproved
+	if got := submission.QRISMerchantName(); got != "TOKO SEMBAKO BERKAH JAYA" {
+		t.Errorf("QRISMerchantName = %q", got)
+	}
+	if got := submission.QRISMerchantCity(); got != "JAKARTA SELATAN" {
+		t.Errorf("QRISMerchantCity = %q", got)
+	}
+
+	submission.CityName = "KABUPATEN BANDUNG BARAT"
+	if got := submission.QRISMerchantCity(); got != "BANDUNG BARAT" {
+		t.Errorf("QRISMerchantCity = %q", got)
+	}
+}
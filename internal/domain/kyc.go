@@ -40,6 +40,7 @@ type KYCSession struct {
 	FaceUrls       map[string]string      `json:"faceUrls" db:"face_urls"`
 	LivenessData   map[string]interface{} `json:"livenessData" db:"liveness_data"`
 	FaceComparison map[string]interface{} `json:"faceComparison" db:"face_comparison"`
+	NIKValidation  *NIKValidation         `json:"nikValidation" db:"nik_validation"`
 	ErrorMessage   *string                `json:"errorMessage" db:"error_message"`
 	ExpiresAt      time.Time              `json:"expiresAt" db:"expires_at"`
 	CreatedAt      time.Time              `json:"createdAt" db:"created_at"`
@@ -70,6 +71,7 @@ type KYCVerification struct {
 	LivenessUrl        *string           `json:"livenessUrl" db:"liveness_url"`
 	FaceSimilarity     *float64          `json:"faceSimilarity" db:"face_similarity"`
 	LivenessConfidence *float64          `json:"livenessConfidence" db:"liveness_confidence"`
+	NIKValidation      *NIKValidation    `json:"nikValidation" db:"nik_validation"`
 	VerifiedAt         time.Time         `json:"verifiedAt" db:"verified_at"`
 	CreatedAt          time.Time         `json:"createdAt" db:"created_at"`
 }
@@ -133,3 +135,26 @@ const (
 	KYCActionVerificationApproved = "verification_approved"
 	KYCActionVerificationRejected = "verification_rejected"
 )
+
+// KYC identity match types
+const (
+	KYCMatchNIK  = "nik"
+	KYCMatchFace = "face"
+)
+
+// KYCDuplicateFaceThreshold is the similarity from which two selfies of
+// different accounts are treated as the same person. It is well above the
+// KTP-to-selfie threshold because both photos are live captures.
+const KYCDuplicateFaceThreshold = 90.0
+
+// KYCIdentityMatch links an account under verification to an already verified
+// account with the same NIK or face. Open matches block automatic approval.
+type KYCIdentityMatch struct {
+	ID            string     `json:"id" db:"id"`
+	UserID        string     `json:"userId" db:"user_id"`
+	MatchedUserID string     `json:"matchedUserId" db:"matched_user_id"`
+	MatchType     string     `json:"matchType" db:"match_type"`
+	Similarity    *float64   `json:"similarity" db:"similarity"`
+	DetectedAt    time.Time  `json:"detectedAt" db:"detected_at"`
+	ResolvedAt    *time.Time `json:"resolvedAt" db:"resolved_at"`
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArtefactGrantSignAndVerify(t *testing.T) {
+	secret := []byte("artefact-secret")
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	grant := ArtefactGrant{
+		Key:       "kyc/usr_1/ktp.jpg",
+		UserID:    "usr_1",
+		Artefact:  KYCArtefactKTP,
+		Purpose:   ArtefactPurposeAdminReview,
+		AdminID:   "adm_1",
+		ExpiresAt: now.Add(5 * time.Minute).Unix(),
+	}
+	token := SignArtefactGrant(secret, grant)
+
+	got, err := VerifyArtefactGrant(secret, token, now)
+	if err != nil || *got != grant {
+		t.Fatalf("VerifyArtefactGrant = %+v, %v; want original grant", got, err)
+	}
+
+	if _, err := VerifyArtefactGrant([]byte("other-secret"), token, now); !hasCode(err, CodeArtefactLinkInvalid) {
+		t.Errorf("expected wrong secret to be rejected, got %v", err)
+	}
+
+	// Swapping the payload must break the signature
+	other := SignArtefactGrant(secret, ArtefactGrant{Key: "kyc/usr_2/ktp.jpg", ExpiresAt: grant.ExpiresAt})
+	forged := strings.SplitN(other, ".", 2)[0] + "." + strings.SplitN(token, ".", 2)[1]
+	if _, err := VerifyArtefactGrant(secret, forged, now); !hasCode(err, CodeArtefactLinkInvalid) {
+		t.Errorf("expected forged payload to be rejected, got %v", err)
+	}
+
+	if _, err := VerifyArtefactGrant(secret, token, now.Add(6*time.Minute)); !hasCode(err, CodeArtefactLinkExpired) {
+		t.Errorf("expected expired link to be rejected, got %v", err)
+	}
+}
+
+func TestArtefactObjectKey(t *testing.T) {
+	baseURL := "https://ppob-id-kyc.s3.ap-southeast-3.amazonaws.com"
+	cases := []struct {
+		ref  string
+		key  string
+		want bool
+	}{
+		{"kyc/usr_1/a.jpg", "kyc/usr_1/a.jpg", true},
+		{baseURL + "/kyc/usr_1/a.jpg", "kyc/usr_1/a.jpg", true},
+		{"dummy://kyc/usr_1/selfie", "", false},
+		{"https://gerbang.example/liveness/1.jpg", "", false},
+		{"", "", false},
+	}
+	for _, tc := range cases {
+		key, ok := ArtefactObjectKey(tc.ref, baseURL)
+		if key != tc.key || ok != tc.want {
+			t.Errorf("ArtefactObjectKey(%q) = %q, %v; want %q, %v", tc.ref, key, ok, tc.key, tc.want)
+		}
+	}
+}
+
+func hasCode(err error, code string) bool {
+	appErr, ok := err.(*AppError)
+	return ok && appErr.Code == code
+}
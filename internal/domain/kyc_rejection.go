@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// KYC verification steps, in the order the user completes them
+const (
+	KYCStepKTP      = 1
+	KYCStepFace     = 2
+	KYCStepLiveness = 3
+)
+
+// KYC artefacts a rejection can point at
+const (
+	KYCArtefactKTP      = "ktp"
+	KYCArtefactFace     = "face"
+	KYCArtefactLiveness = "liveness"
+)
+
+// KYC rejection codes
+const (
+	KYCRejectKTPBlurry      = "ktp_blurry"
+	KYCRejectDataMismatch   = "data_mismatch"
+	KYCRejectFaceMismatch   = "face_mismatch"
+	KYCRejectLivenessFailed = "liveness_failed"
+)
+
+// kycRejectionCode describes which artefact a rejection code refers to
+type kycRejectionCode struct {
+	Artefact string
+	Step     int
+	Message  string
+}
+
+var kycRejectionCodes = map[string]kycRejectionCode{
+	KYCRejectKTPBlurry:      {KYCArtefactKTP, KYCStepKTP, "Foto KTP buram atau tidak terbaca"},
+	KYCRejectDataMismatch:   {KYCArtefactKTP, KYCStepKTP, "Data KTP tidak sesuai dengan data akun"},
+	KYCRejectFaceMismatch:   {KYCArtefactFace, KYCStepFace, "Wajah pada foto selfie tidak cocok dengan foto KTP"},
+	KYCRejectLivenessFailed: {KYCArtefactLiveness, KYCStepLiveness, "Verifikasi liveness tidak valid"},
+}
+
+// IsValidKYCRejectionCode checks if a KYC rejection code is known
+func IsValidKYCRejectionCode(code string) bool {
+	_, ok := kycRejectionCodes[code]
+	return ok
+}
+
+// KYCRejectionReason is the feedback on one artefact of a rejected verification
+type KYCRejectionReason struct {
+	Code     string `json:"code"`
+	Artefact string `json:"artefact"`
+	Step     int    `json:"step"`
+	Message  string `json:"message"`
+	Note     string `json:"note,omitempty"` // Free text from the reviewer
+}
+
+// KYCRejection is the outcome of a rejected verification as shown to the user.
+// Steps before ResubmitFromStep passed review and are reused on resubmission.
+type KYCRejection struct {
+	Reasons          []KYCRejectionReason `json:"reasons"`
+	ResubmitFromStep int                  `json:"resubmitFromStep"`
+	ReusableSteps    []int                `json:"reusableSteps"`
+	RejectedAt       time.Time            `json:"rejectedAt"`
+}
+
+// NewKYCRejection builds a rejection from reviewer input. Each code is listed
+// once; notes of duplicate codes are dropped.
+func NewKYCRejection(reasons []KYCRejectionReason, rejectedAt time.Time) (*KYCRejection, error) {
+	if len(reasons) == 0 {
+		return nil, ErrValidationFailed("Alasan penolakan wajib diisi")
+	}
+
+	seen := make(map[string]bool, len(reasons))
+	result := make([]KYCRejectionReason, 0, len(reasons))
+	for _, reason := range reasons {
+		code := strings.TrimSpace(reason.Code)
+		meta, ok := kycRejectionCodes[code]
+		if !ok {
+			return nil, ErrValidationFailed("Kode alasan penolakan tidak valid: " + code)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		result = append(result, KYCRejectionReason{
+			Code:     code,
+			Artefact: meta.Artefact,
+			Step:     meta.Step,
+			Message:  meta.Message,
+			Note:     strings.TrimSpace(reason.Note),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Step < result[j].Step })
+
+	rejection := &KYCRejection{Reasons: result, RejectedAt: rejectedAt}
+	rejection.ResubmitFromStep = KYCResubmitStep(result)
+	rejection.ReusableSteps = kycStepsBefore(rejection.ResubmitFromStep)
+	return rejection, nil
+}
+
+// KYCResubmitStep returns the first step the user has to redo. Later steps
+// depend on earlier artefacts (the selfie is compared against the KTP photo),
+// so everything from the earliest rejected step onwards is redone.
+func KYCResubmitStep(reasons []KYCRejectionReason) int {
+	step := KYCStepLiveness
+	for _, reason := range reasons {
+		if meta, ok := kycRejectionCodes[reason.Code]; ok && meta.Step < step {
+			step = meta.Step
+		}
+	}
+	return step
+}
+
+// Summary joins the reason messages for notifications
+func (r *KYCRejection) Summary() string {
+	messages := make([]string, 0, len(r.Reasons))
+	for _, reason := range r.Reasons {
+		messages = append(messages, reason.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func kycStepsBefore(step int) []int {
+	steps := []int{}
+	for s := KYCStepKTP; s < step; s++ {
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+// KYCStatusInfo is the KYC state of a user
+type KYCStatusInfo struct {
+	Status       string           `json:"status"`
+	Verification *KYCVerification `json:"verification"`
+	Rejection    *KYCRejection    `json:"rejection"`
+}
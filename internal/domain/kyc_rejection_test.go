@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewKYCRejection(t *testing.T) {
+	cases := []struct {
+		name      string
+		codes     []string
+		wantStep  int
+		wantReuse []int
+		wantErr   bool
+	}{
+		{"liveness only keeps ktp and face", []string{KYCRejectLivenessFailed}, KYCStepLiveness, []int{KYCStepKTP, KYCStepFace}, false},
+		{"face mismatch keeps ktp", []string{KYCRejectFaceMismatch}, KYCStepFace, []int{KYCStepKTP}, false},
+		{"earliest step wins", []string{KYCRejectLivenessFailed, KYCRejectKTPBlurry}, KYCStepKTP, []int{}, false},
+		{"duplicate codes", []string{KYCRejectDataMismatch, KYCRejectDataMismatch}, KYCStepKTP, []int{}, false},
+		{"unknown code", []string{"bad_lighting"}, 0, nil, true},
+		{"no reasons", nil, 0, nil, true},
+	}
+	for _, tc := range cases {
+		reasons := make([]KYCRejectionReason, 0, len(tc.codes))
+		for _, code := range tc.codes {
+			reasons = append(reasons, KYCRejectionReason{Code: code})
+		}
+		got, err := NewKYCRejection(reasons, time.Now())
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if got.ResubmitFromStep != tc.wantStep {
+			t.Errorf("%s: ResubmitFromStep = %d; want %d", tc.name, got.ResubmitFromStep, tc.wantStep)
+		}
+		if !reflect.DeepEqual(got.ReusableSteps, tc.wantReuse) {
+			t.Errorf("%s: ReusableSteps = %v; want %v", tc.name, got.ReusableSteps, tc.wantReuse)
+		}
+		if len(got.Reasons) > 1 && got.Reasons[0].Step > got.Reasons[1].Step {
+			t.Errorf("%s: reasons not ordered by step", tc.name)
+		}
+	}
+}
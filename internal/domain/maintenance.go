@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Maintenance scopes
+const (
+	MaintenanceScopeService  = "service"  // A whole service type, e.g. pln_prepaid or transfer
+	MaintenanceScopeOperator = "operator" // An operator ID or product brand
+	MaintenanceScopeBank     = "bank"     // A bank code, for transfers
+)
+
+// NoticeTypeMaintenance marks a notice raised by an active maintenance window
+const NoticeTypeMaintenance = "maintenance"
+
+// MaintenanceServiceTiles maps service types to the home tile that sells them
+var MaintenanceServiceTiles = map[string]string{
+	ServicePulsa:            "pulsa",
+	ServiceData:             "paket_data",
+	ServicePLNPrepaid:       "token_pln",
+	ServiceGame:             "voucher_game",
+	ServiceEwallet:          "ewallet",
+	ServicePLNPostpaid:      "tagihan_pln",
+	ServicePhonePostpaid:    "pulsa_pascabayar",
+	ServicePDAM:             "pdam",
+	ServiceBPJS:             "bpjs",
+	ServiceTelkom:           "telkom",
+	ServicePGN:              "tagihan_gas",
+	ServicePBB:              "pbb",
+	ServiceTVCable:          "tv_kabel",
+	TransactionTypeTransfer: "transfer_bank",
+}
+
+// MaintenanceWindow blocks sales of a scope target between StartsAt and EndsAt
+type MaintenanceWindow struct {
+	ID        string         `db:"id" json:"id"`
+	Scope     string         `db:"scope" json:"scope"`
+	Target    string         `db:"target" json:"target"`
+	Message   string         `db:"message" json:"message"`
+	StartsAt  time.Time      `db:"starts_at" json:"startsAt"`
+	EndsAt    sql.NullTime   `db:"ends_at" json:"endsAt"`
+	IsActive  bool           `db:"is_active" json:"isActive"`
+	CreatedBy sql.NullString `db:"created_by" json:"createdBy"`
+	CreatedAt time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// MaintenanceTarget identifies what a request is about to sell
+type MaintenanceTarget struct {
+	Scope  string
+	Target string
+}
+
+// InEffect reports whether the window blocks sales at now
+func (w *MaintenanceWindow) InEffect(now time.Time) bool {
+	if !w.IsActive || now.Before(w.StartsAt) {
+		return false
+	}
+	return !w.EndsAt.Valid || now.Before(w.EndsAt.Time)
+}
+
+// Notice builds the user-facing notice of the window
+func (w *MaintenanceWindow) Notice() *NoticeInfo {
+	info := &MaintenanceNotice{
+		ID:       w.ID,
+		Scope:    w.Scope,
+		Target:   w.Target,
+		StartsAt: w.StartsAt.Format(time.RFC3339),
+	}
+	if w.EndsAt.Valid {
+		eta := w.EndsAt.Time.Format(time.RFC3339)
+		info.ETA = &eta
+	}
+	return &NoticeInfo{
+		Type:        NoticeTypeMaintenance,
+		Message:     w.Message,
+		Maintenance: info,
+	}
+}
+
+// MatchMaintenance returns the window in effect for any of the targets,
+// preferring the one expected to last longest. Targets match case-insensitively.
+func MatchMaintenance(windows []*MaintenanceWindow, now time.Time, targets ...MaintenanceTarget) *MaintenanceWindow {
+	var match *MaintenanceWindow
+	for _, window := range windows {
+		if !window.InEffect(now) || !window.matches(targets) {
+			continue
+		}
+		if match == nil || lastsLonger(window, match) {
+			match = window
+		}
+	}
+	return match
+}
+
+func (w *MaintenanceWindow) matches(targets []MaintenanceTarget) bool {
+	for _, target := range targets {
+		if target.Target != "" && w.Scope == target.Scope && strings.EqualFold(w.Target, target.Target) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastsLonger reports whether a ends later than b; an open-ended window lasts longest
+func lastsLonger(a, b *MaintenanceWindow) bool {
+	if !a.EndsAt.Valid {
+		return b.EndsAt.Valid
+	}
+	return b.EndsAt.Valid && a.EndsAt.Time.After(b.EndsAt.Time)
+}
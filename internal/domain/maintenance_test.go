@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestMatchMaintenance(t *testing.T) {
+	now := time.Now()
+	windows := []*MaintenanceWindow{
+		{ID: "pln", Scope: MaintenanceScopeService, Target: "pln_prepaid", StartsAt: now.Add(-time.Hour), EndsAt: sql.NullTime{Time: now.Add(time.Hour), Valid: true}, IsActive: true},
+		{ID: "pln_open", Scope: MaintenanceScopeService, Target: "pln_prepaid", StartsAt: now.Add(-time.Minute), IsActive: true},
+		{ID: "tsel_future", Scope: MaintenanceScopeOperator, Target: "telkomsel", StartsAt: now.Add(time.Hour), IsActive: true},
+		{ID: "bca_ended", Scope: MaintenanceScopeBank, Target: "014", StartsAt: now.Add(-2 * time.Hour), EndsAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true}, IsActive: true},
+		{ID: "bri_off", Scope: MaintenanceScopeBank, Target: "002", StartsAt: now.Add(-time.Hour), IsActive: false},
+		{ID: "xl", Scope: MaintenanceScopeOperator, Target: "XL", StartsAt: now.Add(-time.Hour), IsActive: true},
+	}
+
+	cases := []struct {
+		name    string
+		targets []MaintenanceTarget
+		want    string
+	}{
+		{"open-ended window wins", []MaintenanceTarget{{MaintenanceScopeService, "pln_prepaid"}}, "pln_open"},
+		{"not started", []MaintenanceTarget{{MaintenanceScopeOperator, "telkomsel"}}, ""},
+		{"already ended", []MaintenanceTarget{{MaintenanceScopeBank, "014"}}, ""},
+		{"inactive", []MaintenanceTarget{{MaintenanceScopeBank, "002"}}, ""},
+		{"case-insensitive operator", []MaintenanceTarget{{MaintenanceScopeService, "pulsa"}, {MaintenanceScopeOperator, "xl"}}, "xl"},
+		{"scope must match", []MaintenanceTarget{{MaintenanceScopeBank, "pln_prepaid"}}, ""},
+	}
+	for _, tc := range cases {
+		got := MatchMaintenance(windows, now, tc.targets...)
+		gotID := ""
+		if got != nil {
+			gotID = got.ID
+		}
+		if gotID != tc.want {
+			t.Errorf("%s: got %q; want %q", tc.name, gotID, tc.want)
+		}
+	}
+}
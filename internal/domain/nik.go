@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NIK mismatch fields
+const (
+	NIKFieldFormat    = "format"
+	NIKFieldProvince  = "province"
+	NIKFieldCity      = "city"
+	NIKFieldDistrict  = "district"
+	NIKFieldBirthDate = "birthDate"
+	NIKFieldGender    = "gender"
+)
+
+// NIKInfo is the data encoded in a 16-digit NIK: PPKKCC DDMMYY SSSS, where
+// PPKKCC is the district of issue and DD is the birth day plus 40 for women
+type NIKInfo struct {
+	ProvinceCode string    `json:"provinceCode"`
+	CityCode     string    `json:"cityCode"`
+	DistrictCode string    `json:"districtCode"`
+	BirthDate    time.Time `json:"birthDate"`
+	Gender       string    `json:"gender"`
+	Serial       string    `json:"serial"`
+}
+
+// ParseNIK decodes a NIK. A two-digit birth year is placed in the most recent
+// century that does not put it after now.
+func ParseNIK(nik string, now time.Time) (*NIKInfo, error) {
+	nik = strings.TrimSpace(nik)
+	if len(nik) != 16 {
+		return nil, fmt.Errorf("NIK harus 16 digit")
+	}
+	for _, r := range nik {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("NIK hanya boleh berisi angka")
+		}
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+	year, _ := strconv.Atoi(nik[10:12])
+
+	gender := GenderMale
+	if day > 40 {
+		gender = GenderFemale
+		day -= 40
+	}
+	year += 2000
+	if year > now.Year() {
+		year -= 100
+	}
+
+	birthDate := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if month < 1 || month > 12 || day < 1 || birthDate.Day() != day {
+		return nil, fmt.Errorf("tanggal lahir pada NIK tidak valid")
+	}
+	if nik[12:] == "0000" {
+		return nil, fmt.Errorf("nomor urut NIK tidak valid")
+	}
+
+	return &NIKInfo{
+		ProvinceCode: nik[0:2],
+		CityCode:     nik[0:4],
+		DistrictCode: nik[0:6],
+		BirthDate:    birthDate,
+		Gender:       gender,
+		Serial:       nik[12:],
+	}, nil
+}
+
+// NIKMismatch is one inconsistency found while validating a NIK
+type NIKMismatch struct {
+	Field   string `json:"field"`
+	NIK     string `json:"nik,omitempty"`   // Value decoded from the NIK
+	Other   string `json:"other,omitempty"` // Value from OCR or the territory tables
+	Message string `json:"message"`
+}
+
+// NIKValidation is the result of validating a NIK, kept for manual review.
+// Mismatches are advisory: a district split after the KTP was issued also
+// shows up as an unknown region.
+type NIKValidation struct {
+	Valid      bool          `json:"valid"`
+	Info       *NIKInfo      `json:"info,omitempty"`
+	Mismatches []NIKMismatch `json:"mismatches"`
+	CheckedAt  time.Time     `json:"checkedAt"`
+}
+
+// AddMismatch records a mismatch and marks the validation as failed
+func (v *NIKValidation) AddMismatch(field, nikValue, other, message string) {
+	v.Valid = false
+	v.Mismatches = append(v.Mismatches, NIKMismatch{Field: field, NIK: nikValue, Other: other, Message: message})
+}
+
+// KTPOCRFields are the OCR fields a NIK is cross-checked against
+type KTPOCRFields struct {
+	DateOfBirth        string
+	Gender             string
+	AdministrativeCode map[string]string
+}
+
+// ValidateNIK decodes the NIK and compares it with the OCR fields of the same
+// KTP. Region codes are checked against the territory tables by the caller.
+func ValidateNIK(nik string, ocr KTPOCRFields, now time.Time) *NIKValidation {
+	result := &NIKValidation{Valid: true, Mismatches: []NIKMismatch{}, CheckedAt: now}
+
+	info, err := ParseNIK(nik, now)
+	if err != nil {
+		result.AddMismatch(NIKFieldFormat, nik, "", err.Error())
+		return result
+	}
+	result.Info = info
+
+	if ocrDate, ok := parseKTPDate(ocr.DateOfBirth); ok && !ocrDate.Equal(info.BirthDate) {
+		result.AddMismatch(NIKFieldBirthDate, info.BirthDate.Format("2006-01-02"), ocrDate.Format("2006-01-02"),
+			"Tanggal lahir pada NIK berbeda dengan hasil OCR")
+	}
+	if ocrGender := NormalizeKTPGender(ocr.Gender); ocrGender != "" && ocrGender != info.Gender {
+		result.AddMismatch(NIKFieldGender, info.Gender, ocrGender, "Jenis kelamin pada NIK berbeda dengan hasil OCR")
+	}
+
+	regions := []struct {
+		field, key, code, message string
+	}{
+		{NIKFieldProvince, "province", info.ProvinceCode, "Kode provinsi pada NIK berbeda dengan alamat KTP"},
+		{NIKFieldCity, "city", info.CityCode, "Kode kota/kabupaten pada NIK berbeda dengan alamat KTP"},
+		{NIKFieldDistrict, "district", info.DistrictCode, "Kode kecamatan pada NIK berbeda dengan alamat KTP"},
+	}
+	for _, region := range regions {
+		ocrCode := normalizeRegionCode(ocr.AdministrativeCode[region.key])
+		if ocrCode != "" && ocrCode != region.code {
+			result.AddMismatch(region.field, region.code, ocrCode, region.message)
+		}
+	}
+
+	return result
+}
+
+// NormalizeKTPGender maps the gender printed on a KTP to GenderMale or
+// GenderFemale, or "" when it cannot be read
+func NormalizeKTPGender(gender string) string {
+	switch strings.ToUpper(strings.TrimSpace(gender)) {
+	case GenderMale, "LAKI-LAKI", "LAKI LAKI", "L", "PRIA":
+		return GenderMale
+	case GenderFemale, "PEREMPUAN", "P", "WANITA":
+		return GenderFemale
+	}
+	return ""
+}
+
+func parseKTPDate(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range []string{"2006-01-02", "02-01-2006", "02/01/2006", time.RFC3339} {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// normalizeRegionCode strips the dots of codes written as 31.71.01
+func normalizeRegionCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), ".", "")
+}
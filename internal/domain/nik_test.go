@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNIK(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		nik        string
+		wantDate   string
+		wantGender string
+		wantErr    bool
+	}{
+		{"3171011708900001", "1990-08-17", GenderMale, false},
+		{"3171015708900001", "1990-08-17", GenderFemale, false},
+		{"3273024101050002", "2005-01-01", GenderFemale, false},
+		{"3171013102900001", "", "", true}, // 31 February
+		{"3171011708900000", "", "", true}, // serial 0000
+		{"317101170890000", "", "", true},
+		{"31710117089000A1", "", "", true},
+	}
+	for _, tc := range cases {
+		info, err := ParseNIK(tc.nik, now)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseNIK(%s): expected error", tc.nik)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseNIK(%s): unexpected error %v", tc.nik, err)
+			continue
+		}
+		if got := info.BirthDate.Format("2006-01-02"); got != tc.wantDate {
+			t.Errorf("ParseNIK(%s) birth date = %s; want %s", tc.nik, got, tc.wantDate)
+		}
+		if info.Gender != tc.wantGender {
+			t.Errorf("ParseNIK(%s) gender = %s; want %s", tc.nik, info.Gender, tc.wantGender)
+		}
+		if info.DistrictCode != tc.nik[:6] {
+			t.Errorf("ParseNIK(%s) district = %s", tc.nik, info.DistrictCode)
+		}
+	}
+}
+
+func TestValidateNIK(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	adminCode := map[string]string{"province": "31", "city": "3171", "district": "317101"}
+
+	consistent := ValidateNIK("3171011708900001", KTPOCRFields{
+		DateOfBirth:        "17-08-1990",
+		Gender:             "LAKI-LAKI",
+		AdministrativeCode: adminCode,
+	}, now)
+	if !consistent.Valid || len(consistent.Mismatches) != 0 {
+		t.Fatalf("expected consistent NIK, got %+v", consistent.Mismatches)
+	}
+
+	mismatched := ValidateNIK("3171011708900001", KTPOCRFields{
+		DateOfBirth:        "1991-08-17",
+		Gender:             "PEREMPUAN",
+		AdministrativeCode: map[string]string{"province": "32", "city": "32.73", "district": ""},
+	}, now)
+	fields := map[string]bool{}
+	for _, m := range mismatched.Mismatches {
+		fields[m.Field] = true
+	}
+	for _, field := range []string{NIKFieldBirthDate, NIKFieldGender, NIKFieldProvince, NIKFieldCity} {
+		if !fields[field] {
+			t.Errorf("expected mismatch on %s, got %+v", field, mismatched.Mismatches)
+		}
+	}
+	if fields[NIKFieldDistrict] {
+		t.Errorf("empty OCR district must not be reported")
+	}
+	if mismatched.Valid {
+		t.Errorf("expected invalid result")
+	}
+
+	malformed := ValidateNIK("12345", KTPOCRFields{}, now)
+	if malformed.Valid || len(malformed.Mismatches) != 1 || malformed.Mismatches[0].Field != NIKFieldFormat {
+		t.Errorf("expected a single format mismatch, got %+v", malformed.Mismatches)
+	}
+}
@@ -18,10 +18,11 @@ type PostpaidInquiry struct {
 	Penalty      int64  `db:"penalty" json:"penalty"`
 	TotalPayment int64  `db:"total_payment" json:"totalPayment"`
 	// Status
-	HasBill    bool      `db:"has_bill" json:"hasBill"`
-	ExternalID *string   `db:"external_id" json:"externalId,omitempty"` // Gerbang inquiry ID
-	ExpiresAt  time.Time `db:"expires_at" json:"expiresAt"`
-	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	HasBill      bool      `db:"has_bill" json:"hasBill"`
+	ExternalID   *string   `db:"external_id" json:"externalId,omitempty"`     // Supplier inquiry ID
+	SupplierCode *string   `db:"supplier_code" json:"supplierCode,omitempty"` // Supplier that answered the inquiry; payment must use it too
+	ExpiresAt    time.Time `db:"expires_at" json:"expiresAt"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
 }
 
 // PostpaidTransaction represents completed bill payment
@@ -48,7 +49,8 @@ type PostpaidTransaction struct {
 	// Receipt
 	ReferenceNumber string  `db:"reference_number" json:"referenceNumber"`
 	SerialNumber    *string `db:"serial_number" json:"serialNumber,omitempty"`
-	ExternalID      *string `db:"external_id" json:"externalId,omitempty"` // Gerbang transaction ID
+	ExternalID      *string `db:"external_id" json:"externalId,omitempty"`     // Supplier transaction ID
+	SupplierCode    *string `db:"supplier_code" json:"supplierCode,omitempty"` // Supplier that processed the payment
 	// Status
 	Status       string     `db:"status" json:"status"`
 	FailedReason *string    `db:"failed_reason" json:"failedReason,omitempty"`
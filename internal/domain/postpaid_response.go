@@ -7,8 +7,9 @@ type PostpaidInquiryResponse struct {
 	Bill        *BillInfo             `json:"bill,omitempty"`
 	Payment     *PostpaidPaymentInfo  `json:"payment,omitempty"`
 	PinRequired bool                  `json:"pinRequired"`
-	Notices     []string              `json:"notices"`
+	Notices     []*NoticeInfo         `json:"notices"`
 	Message     *string               `json:"message,omitempty"` // For no bill case
+	Suggestions *ContactSuggestions   `json:"suggestions,omitempty"`
 }
 
 // PostpaidPayResponse represents payment response
@@ -53,6 +53,7 @@ type PrepaidTransaction struct {
 	ReferenceNumber *string    `db:"reference_number" json:"referenceNumber"`
 	Token           *string    `db:"token" json:"token"`
 	KWH             *string    `db:"kwh" json:"kwh"`
+	SupplierCode    *string    `db:"supplier_code" json:"supplierCode,omitempty"` // Supplier that fulfilled the purchase
 	CompletedAt     *time.Time `db:"completed_at" json:"completedAt"`
 	CreatedAt       time.Time  `db:"created_at" json:"createdAt"`
 	UpdatedAt       time.Time  `db:"updated_at" json:"updatedAt"`
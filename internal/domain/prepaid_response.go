@@ -2,21 +2,25 @@ package domain
 
 // PrepaidInquiryResponse represents the inquiry response
 type PrepaidInquiryResponse struct {
-	Inquiry  *InquiryInfo   `json:"inquiry"`
-	Products []*ProductInfo `json:"products"`
-	Notices  []*NoticeInfo  `json:"notices"`
+	Inquiry     *InquiryInfo        `json:"inquiry"`
+	Products    []*ProductInfo      `json:"products"`
+	Notices     []*NoticeInfo       `json:"notices"`
+	Suggestions *ContactSuggestions `json:"suggestions,omitempty"`
 }
 
 // InquiryInfo represents inquiry information
 type InquiryInfo struct {
-	InquiryID    *string       `json:"inquiryId"`
-	ServiceType  string        `json:"serviceType"`
-	Target       string        `json:"target"`
-	TargetValid  bool          `json:"targetValid"`
-	Operator     *OperatorInfo `json:"operator,omitempty"`
-	Customer     *CustomerInfo `json:"customer,omitempty"`
-	ErrorMessage *string       `json:"errorMessage,omitempty"`
-	ExpiresAt    *string       `json:"expiresAt,omitempty"`
+	InquiryID          *string         `json:"inquiryId"`
+	ServiceType        string          `json:"serviceType"`
+	Target             string          `json:"target"`
+	TargetValid        bool            `json:"targetValid"`
+	Operator           *OperatorInfo   `json:"operator,omitempty"`
+	OperatorConfidence *float64        `json:"operatorConfidence,omitempty"` // 1 = unique prefix, 1/n = shared by n operators, 0 = unknown
+	OperatorOverridden bool            `json:"operatorOverridden,omitempty"`
+	OperatorCandidates []*OperatorInfo `json:"operatorCandidates,omitempty"`
+	Customer           *CustomerInfo   `json:"customer,omitempty"`
+	ErrorMessage       *string         `json:"errorMessage,omitempty"`
+	ExpiresAt          *string         `json:"expiresAt,omitempty"`
 }
 
 // OperatorInfo represents operator information
@@ -65,8 +69,18 @@ type DiscountInfo struct {
 
 // NoticeInfo represents notice information
 type NoticeInfo struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
+	Type        string             `json:"type"`
+	Message     string             `json:"message"`
+	Maintenance *MaintenanceNotice `json:"maintenance,omitempty"` // Set when Type is maintenance
+}
+
+// MaintenanceNotice describes the maintenance window behind a notice
+type MaintenanceNotice struct {
+	ID       string  `json:"id"`
+	Scope    string  `json:"scope"`
+	Target   string  `json:"target"`
+	StartsAt string  `json:"startsAt"`
+	ETA      *string `json:"eta,omitempty"` // Expected end, null while unknown
 }
 
 // PrepaidOrderResponse represents the order response
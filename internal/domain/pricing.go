@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Pricing rule markup types
+const (
+	PricingMarkupFixed      = "fixed"
+	PricingMarkupPercentage = "percentage"
+)
+
+// Pricing rule rounding modes
+const (
+	PricingRoundUp      = "up"
+	PricingRoundNearest = "nearest"
+	PricingRoundDown    = "down"
+)
+
+// PricingRule derives a selling price from the supplier cost of the products
+// it matches. Empty match fields are wildcards; when several rules match, the
+// most specific one wins (SKU > brand > category > tier), then the highest
+// priority, then the most recently updated.
+type PricingRule struct {
+	ID           string    `db:"id" json:"id"`
+	Name         string    `db:"name" json:"name"`
+	Category     string    `db:"category" json:"category"`
+	Brand        string    `db:"brand" json:"brand"`
+	SKUCode      string    `db:"sku_code" json:"skuCode"`
+	UserTier     string    `db:"user_tier" json:"userTier"` // Empty for the base price
+	MarkupType   string    `db:"markup_type" json:"markupType"`
+	MarkupValue  float64   `db:"markup_value" json:"markupValue"` // Rupiah for fixed, percent for percentage
+	AdminMarkup  int64     `db:"admin_markup" json:"adminMarkup"`
+	RoundingStep int64     `db:"rounding_step" json:"roundingStep"`
+	RoundingMode string    `db:"rounding_mode" json:"roundingMode"`
+	MinPrice     int64     `db:"min_price" json:"minPrice"` // Floor, 0 when unset
+	MaxPrice     int64     `db:"max_price" json:"maxPrice"` // Ceiling, 0 when unset
+	Priority     int       `db:"priority" json:"priority"`
+	IsActive     bool      `db:"is_active" json:"isActive"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Matches reports whether the rule applies to the product for the given tier.
+// Tier-less rules apply to every tier.
+func (r *PricingRule) Matches(product *Product, tier string) bool {
+	if r == nil || product == nil || !r.IsActive {
+		return false
+	}
+	if r.SKUCode != "" && r.SKUCode != product.SKUCode {
+		return false
+	}
+	if r.Brand != "" && !strings.EqualFold(r.Brand, product.Brand) {
+		return false
+	}
+	if r.Category != "" && !strings.EqualFold(r.Category, product.Category) {
+		return false
+	}
+	return r.UserTier == "" || strings.EqualFold(r.UserTier, tier)
+}
+
+// Specificity ranks how narrowly the rule targets products
+func (r *PricingRule) Specificity() int {
+	score := 0
+	if r.SKUCode != "" {
+		score += 8
+	}
+	if r.Brand != "" {
+		score += 4
+	}
+	if r.Category != "" {
+		score += 2
+	}
+	if r.UserTier != "" {
+		score++
+	}
+	return score
+}
+
+// Apply marks up a supplier cost, then rounds and clamps it to the rule's
+// floor and ceiling
+func (r *PricingRule) Apply(cost int64) int64 {
+	price := float64(cost)
+	switch r.MarkupType {
+	case PricingMarkupPercentage:
+		price += price * r.MarkupValue / 100
+	default:
+		price += r.MarkupValue
+	}
+	result := int64(math.Round(price))
+
+	if r.RoundingStep > 0 {
+		step := r.RoundingStep
+		switch r.RoundingMode {
+		case PricingRoundDown:
+			result = result / step * step
+		case PricingRoundNearest:
+			result = (result + step/2) / step * step
+		default:
+			result = (result + step - 1) / step * step
+		}
+	}
+
+	if r.MinPrice > 0 && result < r.MinPrice {
+		result = r.MinPrice
+	}
+	if r.MaxPrice > 0 && result > r.MaxPrice {
+		result = r.MaxPrice
+	}
+	return result
+}
+
+// PriceQuote is the selling price computed for a product
+type PriceQuote struct {
+	Price  int64
+	Admin  int64
+	RuleID string // Empty when no rule matched and the supplier cost is passed through
+}
+
+// SelectPricingRule returns the rule that prices the product for the tier, or
+// nil when none matches
+func SelectPricingRule(rules []*PricingRule, product *Product, tier string) *PricingRule {
+	var best *PricingRule
+	for _, rule := range rules {
+		if !rule.Matches(product, tier) {
+			continue
+		}
+		if best == nil || pricingRuleBeats(rule, best) {
+			best = rule
+		}
+	}
+	return best
+}
+
+func pricingRuleBeats(rule, other *PricingRule) bool {
+	if a, b := rule.Specificity(), other.Specificity(); a != b {
+		return a > b
+	}
+	if rule.Priority != other.Priority {
+		return rule.Priority > other.Priority
+	}
+	return rule.UpdatedAt.After(other.UpdatedAt)
+}
+
+// QuotePrice computes the selling price of a product from its supplier cost.
+// Without a matching rule the cost is passed through. The price never drops
+// below cost, and products without a supplier price (billed by amount) keep
+// a zero price so only their admin fee is marked up.
+func QuotePrice(rules []*PricingRule, product *Product, tier string) PriceQuote {
+	quote := PriceQuote{Price: product.SupplierPrice, Admin: product.SupplierAdmin}
+	rule := SelectPricingRule(rules, product, tier)
+	if rule == nil {
+		return quote
+	}
+	quote.RuleID = rule.ID
+
+	if product.SupplierPrice > 0 {
+		quote.Price = rule.Apply(product.SupplierPrice)
+		if quote.Price < product.SupplierPrice {
+			quote.Price = product.SupplierPrice
+		}
+	}
+	quote.Admin = product.SupplierAdmin + rule.AdminMarkup
+	if quote.Admin < 0 {
+		quote.Admin = 0
+	}
+	return quote
+}
+
+// PricingPreview summarizes how a pricing rule change moves selling prices
+type PricingPreview struct {
+	UserTier      string                `json:"userTier"`
+	TotalProducts int                   `json:"totalProducts"`
+	AffectedCount int                   `json:"affectedCount"`
+	Increased     int                   `json:"increased"`
+	Decreased     int                   `json:"decreased"`
+	Items         []*PricingPreviewItem `json:"items"`
+	Truncated     bool                  `json:"truncated"` // Items is capped; counts always cover every product
+}
+
+// PricingPreviewItem is the before/after price of one affected product
+type PricingPreviewItem struct {
+	ProductID     string `json:"productId"`
+	SKUCode       string `json:"skuCode"`
+	Name          string `json:"name"`
+	Category      string `json:"category"`
+	Brand         string `json:"brand"`
+	SupplierPrice int64  `json:"supplierPrice"`
+	SupplierAdmin int64  `json:"supplierAdmin"`
+	OldPrice      int64  `json:"oldPrice"`
+	NewPrice      int64  `json:"newPrice"`
+	OldAdmin      int64  `json:"oldAdmin"`
+	NewAdmin      int64  `json:"newAdmin"`
+	OldRuleID     string `json:"oldRuleId"`
+	NewRuleID     string `json:"newRuleId"`
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPricingRuleApply(t *testing.T) {
+	cases := []struct {
+		name string
+		rule PricingRule
+		cost int64
+		want int64
+	}{
+		{"fixed", PricingRule{MarkupType: PricingMarkupFixed, MarkupValue: 1500}, 10000, 11500},
+		{"percentage rounded up", PricingRule{MarkupType: PricingMarkupPercentage, MarkupValue: 2.5, RoundingStep: 100}, 10150, 10500},
+		{"rounded nearest", PricingRule{MarkupType: PricingMarkupFixed, MarkupValue: 30, RoundingStep: 50, RoundingMode: PricingRoundNearest}, 10000, 10050},
+		{"rounded down", PricingRule{MarkupType: PricingMarkupFixed, MarkupValue: 480, RoundingStep: 500, RoundingMode: PricingRoundDown}, 10000, 10000},
+		{"floor", PricingRule{MarkupType: PricingMarkupFixed, MarkupValue: 100, MinPrice: 5500}, 5000, 5500},
+		{"ceiling", PricingRule{MarkupType: PricingMarkupPercentage, MarkupValue: 10, MaxPrice: 105000}, 100000, 105000},
+	}
+	for _, tc := range cases {
+		if got := tc.rule.Apply(tc.cost); got != tc.want {
+			t.Errorf("%s: Apply(%d) = %d; want %d", tc.name, tc.cost, got, tc.want)
+		}
+	}
+}
+
+func TestQuotePrice(t *testing.T) {
+	now := time.Now()
+	rules := []*PricingRule{
+		{ID: "all", IsActive: true, MarkupType: PricingMarkupFixed, MarkupValue: 500},
+		{ID: "pulsa", IsActive: true, Category: "Pulsa", MarkupType: PricingMarkupFixed, MarkupValue: 1000},
+		{ID: "tsel", IsActive: true, Category: "Pulsa", Brand: "TELKOMSEL", MarkupType: PricingMarkupFixed, MarkupValue: 1500},
+		{ID: "tsel-gold", IsActive: true, Brand: "TELKOMSEL", UserTier: "GOLD", MarkupType: PricingMarkupFixed, MarkupValue: 200},
+		{ID: "sku", IsActive: true, SKUCode: "TSEL10", MarkupType: PricingMarkupFixed, MarkupValue: 2000},
+		{ID: "sku-newer", IsActive: true, SKUCode: "TSEL10", MarkupType: PricingMarkupFixed, MarkupValue: 2500, UpdatedAt: now},
+		{ID: "inactive", IsActive: false, SKUCode: "TSEL5", MarkupType: PricingMarkupFixed, MarkupValue: 9000},
+		{ID: "discount", IsActive: true, SKUCode: "XL5", MarkupType: PricingMarkupFixed, MarkupValue: -1000},
+	}
+
+	cases := []struct {
+		name    string
+		product Product
+		tier    string
+		price   int64
+		ruleID  string
+	}{
+		{"sku beats brand, newest wins tie", Product{SKUCode: "TSEL10", Category: "Pulsa", Brand: "TELKOMSEL", SupplierPrice: 10000}, "", 12500, "sku-newer"},
+		{"brand and category", Product{SKUCode: "TSEL5", Category: "pulsa", Brand: "Telkomsel", SupplierPrice: 5000}, "", 6500, "tsel"},
+		{"tier rule only for its tier", Product{SKUCode: "TSEL5", Category: "Data", Brand: "TELKOMSEL", SupplierPrice: 5000}, "GOLD", 5200, "tsel-gold"},
+		{"category", Product{SKUCode: "ISAT5", Category: "Pulsa", Brand: "INDOSAT", SupplierPrice: 5000}, "GOLD", 6000, "pulsa"},
+		{"global fallback", Product{SKUCode: "PLN20", Category: "PLN", Brand: "PLN", SupplierPrice: 20000}, "", 20500, "all"},
+		{"never below cost", Product{SKUCode: "XL5", Category: "Pulsa", Brand: "XL", SupplierPrice: 5000}, "", 5000, "discount"},
+		{"billed products keep zero price", Product{SKUCode: "BPJS", Category: "BPJS", SupplierAdmin: 2500}, "", 0, "all"},
+	}
+	for _, tc := range cases {
+		quote := QuotePrice(rules, &tc.product, tc.tier)
+		if quote.Price != tc.price || quote.RuleID != tc.ruleID {
+			t.Errorf("%s: got price %d rule %q; want %d rule %q", tc.name, quote.Price, quote.RuleID, tc.price, tc.ruleID)
+		}
+	}
+
+	if quote := QuotePrice(nil, &Product{SupplierPrice: 7000, SupplierAdmin: 100}, ""); quote.Price != 7000 || quote.Admin != 100 || quote.RuleID != "" {
+		t.Errorf("no rules: got %+v; want supplier cost passed through", quote)
+	}
+}
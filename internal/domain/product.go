@@ -4,20 +4,22 @@ import "time"
 
 // Product represents synced product from GTD API
 type Product struct {
-	ID           string    `db:"id" json:"id"`
-	SKUCode      string    `db:"sku_code" json:"skuCode"` // Primary identifier from GTD
-	Name         string    `db:"name" json:"name"`
-	Category     string    `db:"category" json:"category"`     // Pulsa, Data, PLN, etc
-	Brand        string    `db:"brand" json:"brand"`           // TELKOMSEL, INDOSAT, PLN, etc
-	Type         string    `db:"type" json:"type"`             // prepaid, postpaid
-	Price        int64     `db:"price" json:"price"`           // Selling price (from GTD, includes profit)
-	Admin        int64     `db:"admin" json:"admin"`           // Admin fee (for postpaid)
-	Commission   int64     `db:"commission" json:"commission"` // Commission (info only)
-	IsActive     bool      `db:"is_active" json:"isActive"`
-	Description  string    `db:"description" json:"description"`
-	GTDUpdatedAt time.Time `db:"gtd_updated_at" json:"gtdUpdatedAt"` // Timestamp from GTD
-	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+	ID            string    `db:"id" json:"id"`
+	SKUCode       string    `db:"sku_code" json:"skuCode"` // Primary identifier from GTD
+	Name          string    `db:"name" json:"name"`
+	Category      string    `db:"category" json:"category"`     // Pulsa, Data, PLN, etc
+	Brand         string    `db:"brand" json:"brand"`           // TELKOMSEL, INDOSAT, PLN, etc
+	Type          string    `db:"type" json:"type"`             // prepaid, postpaid
+	Price         int64     `db:"price" json:"price"`           // Selling price derived from pricing rules
+	Admin         int64     `db:"admin" json:"admin"`           // Selling admin fee (for postpaid)
+	SupplierPrice int64     `db:"supplier_price" json:"-"`      // Cost price from GTD
+	SupplierAdmin int64     `db:"supplier_admin" json:"-"`      // Admin fee charged by GTD
+	Commission    int64     `db:"commission" json:"commission"` // Commission (info only)
+	IsActive      bool      `db:"is_active" json:"isActive"`
+	Description   string    `db:"description" json:"description"`
+	GTDUpdatedAt  time.Time `db:"gtd_updated_at" json:"gtdUpdatedAt"` // Timestamp from GTD
+	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updatedAt"`
 }
 
 // Product types
@@ -52,6 +54,14 @@ type Operator struct {
 	SortOrder  int      `db:"sort_order" json:"-"`
 }
 
+// OperatorDetection is the result of resolving a phone number to its operator
+type OperatorDetection struct {
+	Operator      *Operator   `json:"operator"`   // Best match; nil when no prefix matched
+	Candidates    []*Operator `json:"candidates"` // All operators sharing the matched prefix
+	MatchedPrefix string      `json:"matchedPrefix"`
+	Confidence    float64     `json:"confidence"` // 1 for a unique match, 1/n for ranges shared by n operators, 0 when unknown
+}
+
 // EwalletProvider represents e-wallet provider (GoPay, OVO, etc)
 type EwalletProvider struct {
 	ID               string `db:"id" json:"id"`
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Product change sources
+const (
+	ProductChangeSourceSync    = "sync"
+	ProductChangeSourcePricing = "pricing_rule"
+)
+
+// Product change types
+const (
+	ProductChangeCreated = "created"
+	ProductChangeUpdated = "updated"
+)
+
+// ProductChange records how a product's cost, selling price or availability
+// changed. Old values are nil for newly created products.
+type ProductChange struct {
+	ID               int64     `db:"id" json:"id"`
+	ProductID        *string   `db:"product_id" json:"productId"`
+	SKUCode          string    `db:"sku_code" json:"skuCode"`
+	Source           string    `db:"source" json:"source"`
+	ChangeType       string    `db:"change_type" json:"changeType"`
+	ChangedFieldsDB  string    `db:"changed_fields" json:"-"`
+	ChangedFields    []string  `db:"-" json:"changedFields"`
+	OldSupplierPrice *int64    `db:"old_supplier_price" json:"oldSupplierPrice"`
+	NewSupplierPrice int64     `db:"new_supplier_price" json:"newSupplierPrice"`
+	OldSupplierAdmin *int64    `db:"old_supplier_admin" json:"oldSupplierAdmin"`
+	NewSupplierAdmin int64     `db:"new_supplier_admin" json:"newSupplierAdmin"`
+	OldPrice         *int64    `db:"old_price" json:"oldPrice"`
+	NewPrice         int64     `db:"new_price" json:"newPrice"`
+	OldAdmin         *int64    `db:"old_admin" json:"oldAdmin"`
+	NewAdmin         int64     `db:"new_admin" json:"newAdmin"`
+	OldCommission    *int64    `db:"old_commission" json:"oldCommission"`
+	NewCommission    int64     `db:"new_commission" json:"newCommission"`
+	OldIsActive      *bool     `db:"old_is_active" json:"oldIsActive"`
+	NewIsActive      bool      `db:"new_is_active" json:"newIsActive"`
+	Alerted          bool      `db:"alerted" json:"alerted"`
+	AlertReason      *string   `db:"alert_reason" json:"alertReason"`
+	CreatedAt        time.Time `db:"created_at" json:"createdAt"`
+}
+
+// NewProductChange diffs a product against its stored row and returns the
+// change, or nil when nothing tracked changed. A nil old marks a new product.
+func NewProductChange(old, current *Product, source string) *ProductChange {
+	change := &ProductChange{
+		SKUCode:          current.SKUCode,
+		Source:           source,
+		ChangeType:       ProductChangeCreated,
+		NewSupplierPrice: current.SupplierPrice,
+		NewSupplierAdmin: current.SupplierAdmin,
+		NewPrice:         current.Price,
+		NewAdmin:         current.Admin,
+		NewCommission:    current.Commission,
+		NewIsActive:      current.IsActive,
+		CreatedAt:        time.Now(),
+	}
+	if current.ID != "" {
+		change.ProductID = &current.ID
+	}
+	if old == nil {
+		return change
+	}
+
+	change.ChangeType = ProductChangeUpdated
+	change.ProductID = &old.ID
+	trackInt := func(field string, oldValue, newValue int64, target **int64) {
+		if oldValue != newValue {
+			value := oldValue
+			*target = &value
+			change.ChangedFields = append(change.ChangedFields, field)
+		}
+	}
+	trackInt("supplier_price", old.SupplierPrice, current.SupplierPrice, &change.OldSupplierPrice)
+	trackInt("supplier_admin", old.SupplierAdmin, current.SupplierAdmin, &change.OldSupplierAdmin)
+	trackInt("price", old.Price, current.Price, &change.OldPrice)
+	trackInt("admin", old.Admin, current.Admin, &change.OldAdmin)
+	trackInt("commission", old.Commission, current.Commission, &change.OldCommission)
+	if old.IsActive != current.IsActive {
+		value := old.IsActive
+		change.OldIsActive = &value
+		change.ChangedFields = append(change.ChangedFields, "is_active")
+	}
+
+	if len(change.ChangedFields) == 0 {
+		return nil
+	}
+	change.ChangedFieldsDB = strings.Join(change.ChangedFields, ",")
+	return change
+}
+
+// SupplierPriceChangePercent returns the relative change in supplier price,
+// or 0 when the price did not change or there is no previous price
+func (c *ProductChange) SupplierPriceChangePercent() float64 {
+	if c.OldSupplierPrice == nil || *c.OldSupplierPrice == 0 {
+		return 0
+	}
+	return float64(c.NewSupplierPrice-*c.OldSupplierPrice) / float64(*c.OldSupplierPrice) * 100
+}
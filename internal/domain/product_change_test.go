@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewProductChange(t *testing.T) {
+	old := &Product{ID: "p1", SKUCode: "TSEL10", SupplierPrice: 10000, SupplierAdmin: 0, Price: 10500, Commission: 50, IsActive: true}
+
+	if change := NewProductChange(old, &Product{ID: "new", SKUCode: "TSEL10", SupplierPrice: 10000, Price: 10500, Commission: 50, IsActive: true}, ProductChangeSourceSync); change != nil {
+		t.Fatalf("unchanged product produced a change: %+v", change)
+	}
+
+	current := &Product{ID: "p1", SKUCode: "TSEL10", SupplierPrice: 20000, Price: 20500, Commission: 50, IsActive: false}
+	change := NewProductChange(old, current, ProductChangeSourceSync)
+	if change == nil {
+		t.Fatal("expected a change")
+	}
+	if want := []string{"supplier_price", "price", "is_active"}; !reflect.DeepEqual(change.ChangedFields, want) {
+		t.Errorf("ChangedFields = %v; want %v", change.ChangedFields, want)
+	}
+	if change.ChangeType != ProductChangeUpdated || *change.ProductID != "p1" {
+		t.Errorf("got type %q product %q", change.ChangeType, *change.ProductID)
+	}
+	if change.OldCommission != nil || change.OldIsActive == nil || !*change.OldIsActive {
+		t.Errorf("unexpected old values: commission %v, active %v", change.OldCommission, change.OldIsActive)
+	}
+	if pct := change.SupplierPriceChangePercent(); pct != 100 {
+		t.Errorf("SupplierPriceChangePercent = %v; want 100", pct)
+	}
+
+	created := NewProductChange(nil, current, ProductChangeSourceSync)
+	if created == nil || created.ChangeType != ProductChangeCreated || created.SupplierPriceChangePercent() != 0 {
+		t.Errorf("new product: got %+v", created)
+	}
+}
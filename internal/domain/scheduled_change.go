@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Scheduled change statuses
+const (
+	ScheduledChangeScheduled = "scheduled"
+	ScheduledChangeApplied   = "applied"
+	ScheduledChangeReverted  = "reverted"
+	ScheduledChangeCancelled = "cancelled"
+	ScheduledChangeFailed    = "failed"
+)
+
+// Resources a scheduled change can target
+const (
+	ScheduledResourceService = "service"
+	ScheduledResourceBanner  = "banner"
+	ScheduledResourceVoucher = "voucher"
+)
+
+// ScheduledChange is a catalog edit queued by an admin to take effect at
+// EffectiveAt and, when RevertAt is set, to be rolled back at RevertAt
+type ScheduledChange struct {
+	ID              string                 `db:"id" json:"id"`
+	ResourceType    string                 `db:"resource_type" json:"resourceType"`
+	ResourceID      string                 `db:"resource_id" json:"resourceId"`
+	Payload         map[string]interface{} `db:"-" json:"payload"`
+	PreviousPayload map[string]interface{} `db:"-" json:"previousPayload,omitempty"`
+	AppliedPayload  map[string]interface{} `db:"-" json:"appliedPayload,omitempty"`
+	EffectiveAt     time.Time              `db:"effective_at" json:"effectiveAt"`
+	RevertAt        sql.NullTime           `db:"revert_at" json:"revertAt"`
+	Status          string                 `db:"status" json:"status"`
+	Notes           sql.NullString         `db:"notes" json:"notes"`
+	ErrorMessage    sql.NullString         `db:"error_message" json:"errorMessage"`
+	AppliedAt       sql.NullTime           `db:"applied_at" json:"appliedAt"`
+	RevertedAt      sql.NullTime           `db:"reverted_at" json:"revertedAt"`
+	CreatedBy       sql.NullString         `db:"created_by" json:"createdBy"`
+	CreatedAt       time.Time              `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time              `db:"updated_at" json:"updatedAt"`
+}
+
+// MergeChangePayload overlays the scheduled fields on a snapshot of the
+// resource, producing the full payload its update expects
+func MergeChangePayload(snapshot, changes map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(snapshot)+len(changes))
+	for key, value := range snapshot {
+		merged[key] = value
+	}
+	for key, value := range changes {
+		merged[key] = value
+	}
+	return merged
+}
+
+// RevertChangePayload undoes a scheduled change on the current state of the
+// resource. Only the fields the change set are restored from previous, and
+// only while they still hold the applied values; fields edited since are
+// kept. It returns the full payload for the update and the fields reverted
+// and kept.
+func RevertChangePayload(current, applied, previous map[string]interface{}) (payload map[string]interface{}, reverted, kept []string) {
+	payload = MergeChangePayload(current, nil)
+	for key, value := range applied {
+		before, ok := previous[key]
+		if !ok || !SameChangeValue(current[key], value) {
+			kept = append(kept, key)
+			continue
+		}
+		payload[key] = before
+		reverted = append(reverted, key)
+	}
+	sort.Strings(reverted)
+	sort.Strings(kept)
+	return payload, reverted, kept
+}
+
+// SameChangeValue compares two payload values by their JSON form, so a value
+// read from the database matches the same value decoded from a payload
+func SameChangeValue(a, b interface{}) bool {
+	return reflect.DeepEqual(jsonValue(a), jsonValue(b))
+}
+
+func jsonValue(value interface{}) interface{} {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
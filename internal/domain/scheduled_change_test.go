@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRevertChangePayload(t *testing.T) {
+	previous := map[string]interface{}{"name": "Pulsa", "badge": "", "sort_order": float64(1), "status": "active"}
+	applied := map[string]interface{}{"badge": "PROMO", "sort_order": float64(5)}
+	// An admin renamed the service and moved it after the change applied
+	current := map[string]interface{}{"name": "Pulsa & Data", "badge": "PROMO", "sort_order": int64(9), "status": "active"}
+
+	payload, reverted, kept := RevertChangePayload(current, applied, previous)
+	if want := []string{"badge"}; !reflect.DeepEqual(reverted, want) {
+		t.Errorf("reverted = %v; want %v", reverted, want)
+	}
+	if want := []string{"sort_order"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %v; want %v", kept, want)
+	}
+	want := map[string]interface{}{"name": "Pulsa & Data", "badge": "", "sort_order": int64(9), "status": "active"}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v; want %v", payload, want)
+	}
+	if current["badge"] != "PROMO" {
+		t.Error("current snapshot was modified")
+	}
+}
+
+func TestSameChangeValue(t *testing.T) {
+	if !SameChangeValue(int64(5), float64(5)) {
+		t.Error("database integer should match a decoded JSON number")
+	}
+	if !SameChangeValue([]interface{}{"GOLD"}, []string{"GOLD"}) {
+		t.Error("equal lists should match")
+	}
+	if SameChangeValue(nil, "") {
+		t.Error("NULL should not match an empty string")
+	}
+}
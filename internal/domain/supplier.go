@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Supplier is a registered PPOB product supplier
+type Supplier struct {
+	Code      string    `db:"code" json:"code"`
+	Name      string    `db:"name" json:"name"`
+	IsActive  bool      `db:"is_active" json:"isActive"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// SupplierRoute maps one of our SKUs to a supplier's SKU and cost
+type SupplierRoute struct {
+	ID              string    `db:"id" json:"id"`
+	SupplierCode    string    `db:"supplier_code" json:"supplierCode"`
+	SKUCode         string    `db:"sku_code" json:"skuCode"`
+	SupplierSKUCode string    `db:"supplier_sku_code" json:"supplierSkuCode"`
+	CostPrice       int64     `db:"cost_price" json:"costPrice"`
+	Priority        int       `db:"priority" json:"priority"` // Tie-breaker between equally priced suppliers, higher first
+	IsActive        bool      `db:"is_active" json:"isActive"`
+	SupplierActive  bool      `db:"supplier_active" json:"supplierActive"` // The supplier itself is enabled
+	CreatedAt       time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updatedAt"`
+}
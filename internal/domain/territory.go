@@ -54,22 +54,49 @@ type PostalCodeSearchResult struct {
 	Province    Location `json:"province"`
 }
 
+// TerritorySearchResult is a typeahead match at any level, with its full hierarchy
+type TerritorySearchResult struct {
+	Level       string    `json:"level"` // province, city, district, sub_district
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Label       string    `json:"label"` // e.g. "Kebayoran Baru, Jakarta Selatan, DKI Jakarta"
+	Province    Location  `json:"province"`
+	City        *Location `json:"city,omitempty"`
+	District    *Location `json:"district,omitempty"`
+	SubDistrict *Location `json:"subDistrict,omitempty"`
+	PostalCodes []string  `json:"postalCodes,omitempty"`
+	Score       float64   `json:"score"`
+}
+
+// Territory levels returned by search
+const (
+	TerritoryLevelProvince    = "province"
+	TerritoryLevelCity        = "city"
+	TerritoryLevelDistrict    = "district"
+	TerritoryLevelSubDistrict = "sub_district"
+)
+
 // Location represents a generic location (code + name)
 type Location struct {
 	Code string `json:"code"`
 	Name string `json:"name"`
 }
 
-// TerritorySyncLog represents territory sync metadata
+// TerritorySyncLog represents territory sync metadata for one level of a sync run
 type TerritorySyncLog struct {
-	ID           int        `db:"id"`
-	SyncType     string     `db:"sync_type"`
-	TotalRecords int        `db:"total_records"`
-	Status       string     `db:"status"`
-	ErrorMessage *string    `db:"error_message"`
-	StartedAt    time.Time  `db:"started_at"`
-	CompletedAt  *time.Time `db:"completed_at"`
-	CreatedAt    time.Time  `db:"created_at"`
+	ID            int        `db:"id"`
+	RunID         *string    `db:"run_id"`
+	SyncType      string     `db:"sync_type"`
+	TotalRecords  int        `db:"total_records"` // Records returned by Gerbang for the level
+	InsertedCount int        `db:"inserted_count"`
+	UpdatedCount  int        `db:"updated_count"`
+	DeletedCount  int        `db:"deleted_count"`
+	Checkpoint    *string    `db:"checkpoint"` // Last parent code fully synced
+	Status        string     `db:"status"`
+	ErrorMessage  *string    `db:"error_message"`
+	StartedAt     time.Time  `db:"started_at"`
+	CompletedAt   *time.Time `db:"completed_at"`
+	CreatedAt     time.Time  `db:"created_at"`
 }
 
 // Territory sync status constants
@@ -87,3 +114,13 @@ const (
 	TerritorySyncTypeSubDistricts = "sub_districts"
 	TerritorySyncTypePostalCodes  = "postal_codes"
 )
+
+// TerritorySyncLevels lists the sync types from the top of the hierarchy down;
+// each level is synced from the codes stored by the level above it
+var TerritorySyncLevels = []string{
+	TerritorySyncTypeProvinces,
+	TerritorySyncTypeCities,
+	TerritorySyncTypeDistricts,
+	TerritorySyncTypeSubDistricts,
+	TerritorySyncTypePostalCodes,
+}
@@ -15,6 +15,8 @@ type TransferInquiry struct {
 	TotalPayment     int64     `db:"total_payment" json:"totalPayment"`
 	GerbangInquiryID *string   `db:"gerbang_inquiry_id" json:"gerbangInquiryId"` // ID dari Gerbang
 	Fee              int64     `db:"fee" json:"fee"`                               // Fee dari Gerbang response
+	Method           *string   `db:"method" json:"method"`                         // bifast, online, rtgs
+	FeeRuleID        *string   `db:"fee_rule_id" json:"feeRuleId"`                 // Fee rule that priced AdminFee
 	ExpiresAt        time.Time `db:"expires_at" json:"expiresAt"`
 	CreatedAt        time.Time `db:"created_at" json:"createdAt"`
 }
@@ -40,6 +42,7 @@ type TransferTransaction struct {
 	GerbangTransferID *string    `db:"gerbang_transfer_id" json:"gerbangTransferId"` // ID dari Gerbang
 	Purpose           *string    `db:"purpose" json:"purpose"`                        // Purpose code (01, 02, 03, 99)
 	Fee               int64      `db:"fee" json:"fee"`                                // Fee dari Gerbang (bukan admin fee hardcoded)
+	Method            *string    `db:"method" json:"method"`                          // bifast, online, rtgs
 	CompletedAt       *time.Time `db:"completed_at" json:"completedAt"`
 	CreatedAt         time.Time  `db:"created_at" json:"createdAt"`
 	UpdatedAt         time.Time  `db:"updated_at" json:"updatedAt"`
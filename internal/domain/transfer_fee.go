@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Transfer methods
+const (
+	TransferMethodBIFast = "bifast"
+	TransferMethodOnline = "online"
+	TransferMethodRTGS   = "rtgs"
+)
+
+// IsValidTransferMethod reports whether method is a known transfer method
+func IsValidTransferMethod(method string) bool {
+	switch method {
+	case TransferMethodBIFast, TransferMethodOnline, TransferMethodRTGS:
+		return true
+	}
+	return false
+}
+
+// TransferFeeRule prices transfers of one method within an amount band.
+// BankCode and UserTier narrow the rule; NULL matches any bank or tier.
+type TransferFeeRule struct {
+	ID        string         `db:"id" json:"id"`
+	BankCode  sql.NullString `db:"bank_code" json:"bankCode"`
+	UserTier  sql.NullString `db:"user_tier" json:"userTier"`
+	Method    string         `db:"method" json:"method"`
+	MinAmount int64          `db:"min_amount" json:"minAmount"`
+	MaxAmount sql.NullInt64  `db:"max_amount" json:"maxAmount"` // NULL: no upper bound
+	Fee       int64          `db:"fee" json:"fee"`
+	Priority  int            `db:"priority" json:"priority"`
+	IsActive  bool           `db:"is_active" json:"isActive"`
+	CreatedAt time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// Matches reports whether the rule prices a transfer of amount to bankCode by a user of tier
+func (r *TransferFeeRule) Matches(bankCode, tier string, amount int64) bool {
+	if !r.IsActive || amount < r.MinAmount || (r.MaxAmount.Valid && amount > r.MaxAmount.Int64) {
+		return false
+	}
+	if r.BankCode.Valid && r.BankCode.String != bankCode {
+		return false
+	}
+	return !r.UserTier.Valid || r.UserTier.String == tier
+}
+
+// specificity ranks bank-specific rules above tier-specific ones above generic ones
+func (r *TransferFeeRule) specificity() int {
+	score := 0
+	if r.BankCode.Valid {
+		score += 2
+	}
+	if r.UserTier.Valid {
+		score++
+	}
+	return score
+}
+
+// SelectTransferFee returns the rule pricing the transfer. For each method the
+// most specific matching rule applies, higher priority breaking ties. When
+// method is empty the cheapest method is chosen. It returns nil when no rule
+// matches, i.e. the method does not carry that amount.
+func SelectTransferFee(rules []*TransferFeeRule, bankCode, tier string, amount int64, method string) *TransferFeeRule {
+	best := make(map[string]*TransferFeeRule)
+	for _, rule := range rules {
+		if (method != "" && rule.Method != method) || !rule.Matches(bankCode, tier, amount) {
+			continue
+		}
+		current := best[rule.Method]
+		if current == nil || rule.specificity() > current.specificity() ||
+			(rule.specificity() == current.specificity() && rule.Priority > current.Priority) {
+			best[rule.Method] = rule
+		}
+	}
+
+	var selected *TransferFeeRule
+	for _, candidate := range []string{TransferMethodBIFast, TransferMethodOnline, TransferMethodRTGS} {
+		rule := best[candidate]
+		if rule != nil && (selected == nil || rule.Fee < selected.Fee) {
+			selected = rule
+		}
+	}
+	return selected
+}
+
+// TransferLimit caps the outgoing transfer amount of users with a KYC status.
+// Zero limits mean the status may not transfer at all.
+type TransferLimit struct {
+	KYCStatus    string    `db:"kyc_status" json:"kycStatus"`
+	DailyLimit   int64     `db:"daily_limit" json:"dailyLimit"`
+	MonthlyLimit int64     `db:"monthly_limit" json:"monthlyLimit"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// TransferUsage is the amount a user already transferred in the current day and month
+type TransferUsage struct {
+	Daily   int64 `db:"daily"`
+	Monthly int64 `db:"monthly"`
+}
+
+// Allowed reports whether the limit lets the user transfer at all
+func (l *TransferLimit) Allowed() bool {
+	return l != nil && l.DailyLimit > 0 && l.MonthlyLimit > 0
+}
+
+// Remaining returns the daily and monthly headroom left after usage
+func (l *TransferLimit) Remaining(usage TransferUsage) (daily, monthly int64) {
+	return max(l.DailyLimit-usage.Daily, 0), max(l.MonthlyLimit-usage.Monthly, 0)
+}
+
+// TransferPeriodStart returns the start of the WIB day and month containing now,
+// the periods the transfer limits reset on
+func TransferPeriodStart(now time.Time) (day, month time.Time) {
+	wib := now.In(time.FixedZone("WIB", 7*60*60))
+	day = time.Date(wib.Year(), wib.Month(), wib.Day(), 0, 0, 0, 0, wib.Location())
+	month = time.Date(wib.Year(), wib.Month(), 1, 0, 0, 0, 0, wib.Location())
+	return day, month
+}
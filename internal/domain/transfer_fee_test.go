@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestSelectTransferFee(t *testing.T) {
+	rules := []*TransferFeeRule{
+		{ID: "bifast", Method: TransferMethodBIFast, MinAmount: 10000, MaxAmount: sql.NullInt64{Int64: 250000000, Valid: true}, Fee: 2500, IsActive: true},
+		{ID: "online", Method: TransferMethodOnline, MinAmount: 10000, MaxAmount: sql.NullInt64{Int64: 50000000, Valid: true}, Fee: 6500, IsActive: true},
+		{ID: "online_bca", BankCode: sql.NullString{String: "014", Valid: true}, Method: TransferMethodOnline, MinAmount: 10000, MaxAmount: sql.NullInt64{Int64: 50000000, Valid: true}, Fee: 0, IsActive: true},
+		{ID: "bifast_gold", UserTier: sql.NullString{String: "GOLD", Valid: true}, Method: TransferMethodBIFast, MinAmount: 10000, MaxAmount: sql.NullInt64{Int64: 250000000, Valid: true}, Fee: 1000, IsActive: true},
+		{ID: "rtgs", Method: TransferMethodRTGS, MinAmount: 100000000, Fee: 30000, IsActive: true},
+		{ID: "rtgs_off", Method: TransferMethodRTGS, MinAmount: 100000000, Fee: 0, Priority: 10, IsActive: false},
+	}
+
+	cases := []struct {
+		name     string
+		bankCode string
+		tier     string
+		amount   int64
+		method   string
+		want     string
+	}{
+		{"cheapest method", "002", "BRONZE", 100000, "", "bifast"},
+		{"bank rule beats generic", "014", "BRONZE", 100000, "", "online_bca"},
+		{"tier rule", "002", "GOLD", 100000, "", "bifast_gold"},
+		{"explicit method", "002", "BRONZE", 100000, TransferMethodOnline, "online"},
+		{"above band", "002", "BRONZE", 300000000, "", "rtgs"},
+		{"method out of band", "002", "BRONZE", 100000, TransferMethodRTGS, ""},
+		{"below minimum", "002", "BRONZE", 5000, "", ""},
+	}
+	for _, tc := range cases {
+		got := SelectTransferFee(rules, tc.bankCode, tc.tier, tc.amount, tc.method)
+		gotID := ""
+		if got != nil {
+			gotID = got.ID
+		}
+		if gotID != tc.want {
+			t.Errorf("%s: SelectTransferFee = %q; want %q", tc.name, gotID, tc.want)
+		}
+	}
+}
+
+func TestTransferLimitRemaining(t *testing.T) {
+	limit := &TransferLimit{DailyLimit: 1000000, MonthlyLimit: 5000000}
+	daily, monthly := limit.Remaining(TransferUsage{Daily: 400000, Monthly: 5200000})
+	if daily != 600000 || monthly != 0 {
+		t.Errorf("Remaining = (%d, %d); want (600000, 0)", daily, monthly)
+	}
+	if (&TransferLimit{DailyLimit: 0, MonthlyLimit: 5000000}).Allowed() {
+		t.Error("zero daily limit should not allow transfers")
+	}
+}
+
+func TestTransferPeriodStart(t *testing.T) {
+	// 2026-03-31 20:00 UTC is already 1 April in WIB
+	day, month := TransferPeriodStart(time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC))
+	if got := day.UTC(); !got.Equal(time.Date(2026, 3, 31, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("day start = %s", got)
+	}
+	if got := month.UTC(); !got.Equal(time.Date(2026, 3, 31, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("month start = %s", got)
+	}
+}
@@ -7,7 +7,9 @@ type TransferInquiryResponse struct {
 	Transfer    *TransferAmountInfo      `json:"transfer"`
 	Payment     *PaymentInfo             `json:"payment,omitempty"`
 	PINRequired bool                     `json:"pinRequired"`
+	Limits      *TransferLimitInfo       `json:"limits,omitempty"`
 	Notices     []*NoticeInfo            `json:"notices"`
+	Suggestions *ContactSuggestions      `json:"suggestions,omitempty"`
 }
 
 // TransferInquiryInfo represents inquiry information
@@ -34,6 +36,18 @@ type TransferAmountInfo struct {
 	AdminFeeFormatted     string `json:"adminFeeFormatted"`
 	TotalPayment          int64  `json:"totalPayment"`
 	TotalPaymentFormatted string `json:"totalPaymentFormatted"`
+	Method                string `json:"method,omitempty"` // bifast, online, rtgs
+}
+
+// TransferLimitInfo shows the user's transfer limits and what is left of them,
+// before the quoted transfer
+type TransferLimitInfo struct {
+	DailyLimit                int64  `json:"dailyLimit"`
+	DailyRemaining            int64  `json:"dailyRemaining"`
+	DailyRemainingFormatted   string `json:"dailyRemainingFormatted"`
+	MonthlyLimit              int64  `json:"monthlyLimit"`
+	MonthlyRemaining          int64  `json:"monthlyRemaining"`
+	MonthlyRemainingFormatted string `json:"monthlyRemainingFormatted"`
 }
 
 // TransferExecuteResponse represents the execute response
@@ -0,0 +1,322 @@
+package gerbang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint groups, each guarded by its own circuit breaker
+const (
+	EndpointPayment   = "payment"
+	EndpointPPOB      = "ppob"
+	EndpointTransfer  = "transfer"
+	EndpointKYC       = "kyc"
+	EndpointReference = "reference" // Bank codes, territory
+)
+
+// Circuit states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// ErrProviderUnavailable is matched by errors returned while a circuit is open
+var ErrProviderUnavailable = errors.New("gerbang provider unavailable")
+
+// UnavailableError is returned without calling Gerbang while an endpoint's circuit is open
+type UnavailableError struct {
+	Endpoint   string
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("gerbang %s unavailable, retry after %s", e.Endpoint, e.RetryAfter.Round(time.Second))
+}
+
+// Is makes errors.Is(err, ErrProviderUnavailable) match
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrProviderUnavailable
+}
+
+// IsProviderUnavailable checks if err was caused by an open circuit
+func IsProviderUnavailable(err error) bool {
+	return errors.Is(err, ErrProviderUnavailable)
+}
+
+// BreakerConfig configures the per-endpoint circuit breakers
+type BreakerConfig struct {
+	FailureThreshold   int           // Consecutive failures that open the circuit (default 5)
+	ErrorRateThreshold float64       // Failure ratio over the window that opens the circuit (default 0.5)
+	Window             int           // Number of recent calls used for the error rate (default 20)
+	MinRequests        int           // Calls in the window before the error rate applies (default 10)
+	OpenTimeout        time.Duration // How long the circuit stays open before probing (default 30s)
+	HalfOpenProbes     int           // Concurrent probe calls allowed while half-open (default 1)
+}
+
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.ErrorRateThreshold <= 0 || c.ErrorRateThreshold > 1 {
+		c.ErrorRateThreshold = 0.5
+	}
+	if c.Window <= 0 {
+		c.Window = 20
+	}
+	if c.MinRequests <= 0 || c.MinRequests > c.Window {
+		c.MinRequests = c.Window / 2
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = 30 * time.Second
+	}
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = 1
+	}
+	return c
+}
+
+// EndpointHealth is a snapshot of an endpoint's circuit and call metrics
+type EndpointHealth struct {
+	Endpoint            string     `json:"endpoint"`
+	State               string     `json:"state"`
+	Score               int        `json:"score"` // 0 (down) to 100 (healthy)
+	Requests            int64      `json:"requests"`
+	Failures            int64      `json:"failures"`
+	Rejected            int64      `json:"rejected"` // Calls short-circuited while open
+	ErrorRate           float64    `json:"errorRate"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LatencyAvgMs        int64      `json:"latencyAvgMs"`
+	LatencyLastMs       int64      `json:"latencyLastMs"`
+	LastError           string     `json:"lastError,omitempty"`
+	OpenedAt            *time.Time `json:"openedAt"`
+	RetryAt             *time.Time `json:"retryAt"`
+}
+
+// circuitBreaker guards one endpoint group
+type circuitBreaker struct {
+	endpoint string
+	config   BreakerConfig
+	now      func() time.Time
+
+	mu                  sync.Mutex
+	state               string
+	openedAt            time.Time
+	probes              int
+	window              []bool // Ring buffer of recent outcomes, true = failure
+	windowPos           int
+	windowLen           int
+	consecutiveFailures int
+	requests            int64
+	failures            int64
+	rejected            int64
+	latencyAvg          time.Duration
+	latencyLast         time.Duration
+	lastError           string
+}
+
+func newCircuitBreaker(endpoint string, config BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		endpoint: endpoint,
+		config:   config,
+		now:      time.Now,
+		state:    CircuitClosed,
+		window:   make([]bool, config.Window),
+	}
+}
+
+// allow reserves a call, or returns an UnavailableError while the circuit is open
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if wait := b.openedAt.Add(b.config.OpenTimeout).Sub(b.now()); wait > 0 {
+			b.rejected++
+			return &UnavailableError{Endpoint: b.endpoint, RetryAfter: wait}
+		}
+		b.state = CircuitHalfOpen
+		b.probes = 0
+	}
+	if b.state == CircuitHalfOpen {
+		if b.probes >= b.config.HalfOpenProbes {
+			b.rejected++
+			return &UnavailableError{Endpoint: b.endpoint, RetryAfter: time.Second}
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// record stores the outcome of a call reserved with allow. Only provider
+// failures (5xx, timeouts, connection errors) count against the circuit.
+func (b *circuitBreaker) record(err error, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := err != nil
+	b.requests++
+	b.latencyLast = latency
+	if b.latencyAvg == 0 {
+		b.latencyAvg = latency
+	} else {
+		// Exponentially weighted moving average
+		b.latencyAvg = time.Duration(0.8*float64(b.latencyAvg) + 0.2*float64(latency))
+	}
+
+	b.window[b.windowPos] = failed
+	b.windowPos = (b.windowPos + 1) % len(b.window)
+	if b.windowLen < len(b.window) {
+		b.windowLen++
+	}
+
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	if !failed {
+		b.consecutiveFailures = 0
+		if b.state == CircuitHalfOpen {
+			b.close()
+		}
+		return
+	}
+
+	b.failures++
+	b.consecutiveFailures++
+	b.lastError = err.Error()
+	switch {
+	case b.state == CircuitHalfOpen:
+		b.open()
+	case b.state == CircuitClosed && (b.consecutiveFailures >= b.config.FailureThreshold ||
+		(b.windowLen >= b.config.MinRequests && b.errorRate() >= b.config.ErrorRateThreshold)):
+		b.open()
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.state = CircuitOpen
+	b.openedAt = b.now()
+	b.probes = 0
+}
+
+func (b *circuitBreaker) close() {
+	b.state = CircuitClosed
+	b.probes = 0
+	b.windowPos, b.windowLen = 0, 0
+}
+
+func (b *circuitBreaker) errorRate() float64 {
+	if b.windowLen == 0 {
+		return 0
+	}
+	failures := 0
+	for i := 0; i < b.windowLen; i++ {
+		if b.window[i] {
+			failures++
+		}
+	}
+	return float64(failures) / float64(b.windowLen)
+}
+
+// available reports whether a call would currently be let through
+func (b *circuitBreaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != CircuitOpen || !b.now().Before(b.openedAt.Add(b.config.OpenTimeout))
+}
+
+func (b *circuitBreaker) snapshot() EndpointHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	errorRate := b.errorRate()
+	health := EndpointHealth{
+		Endpoint:            b.endpoint,
+		State:               b.state,
+		Requests:            b.requests,
+		Failures:            b.failures,
+		Rejected:            b.rejected,
+		ErrorRate:           math.Round(errorRate*1000) / 1000,
+		ConsecutiveFailures: b.consecutiveFailures,
+		LatencyAvgMs:        b.latencyAvg.Milliseconds(),
+		LatencyLastMs:       b.latencyLast.Milliseconds(),
+		LastError:           b.lastError,
+		Score:               healthScore(b.state, errorRate, b.latencyAvg),
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.config.OpenTimeout)
+		health.OpenedAt = &openedAt
+		health.RetryAt = &retryAt
+	}
+	return health
+}
+
+// healthScore rates an endpoint from its error rate, with up to 20 points
+// off for average latency above one second; an open circuit scores 0 and a
+// half-open one at most 50
+func healthScore(state string, errorRate float64, latencyAvg time.Duration) int {
+	if state == CircuitOpen {
+		return 0
+	}
+	score := (1 - errorRate) * 100
+	if latencyAvg > time.Second {
+		score -= math.Min(20, (latencyAvg-time.Second).Seconds()*5)
+	}
+	if state == CircuitHalfOpen {
+		score = math.Min(score, 50)
+	}
+	return int(math.Max(0, math.Round(score)))
+}
+
+// breakerSet holds the circuit breakers of all endpoint groups
+type breakerSet struct {
+	breakers map[string]*circuitBreaker
+}
+
+func newBreakerSet(config BreakerConfig) *breakerSet {
+	config = config.withDefaults()
+	set := &breakerSet{breakers: make(map[string]*circuitBreaker)}
+	for _, endpoint := range []string{EndpointPayment, EndpointPPOB, EndpointTransfer, EndpointKYC, EndpointReference} {
+		set.breakers[endpoint] = newCircuitBreaker(endpoint, config)
+	}
+	return set
+}
+
+func (s *breakerSet) get(endpoint string) *circuitBreaker {
+	if breaker, ok := s.breakers[endpoint]; ok {
+		return breaker
+	}
+	return s.breakers[EndpointReference]
+}
+
+func (s *breakerSet) snapshot() []EndpointHealth {
+	items := make([]EndpointHealth, 0, len(s.breakers))
+	for _, breaker := range s.breakers {
+		items = append(items, breaker.snapshot())
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Endpoint < items[j].Endpoint })
+	return items
+}
+
+// endpointForPath maps an API path to its endpoint group
+func endpointForPath(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/v1/payment"):
+		return EndpointPayment
+	case strings.HasPrefix(path, "/v1/ppob"):
+		return EndpointPPOB
+	case strings.HasPrefix(path, "/v1/transfer"):
+		return EndpointTransfer
+	case strings.HasPrefix(path, "/v1/verify"), strings.HasPrefix(path, "/v1/identity"):
+		return EndpointKYC
+	default:
+		return EndpointReference
+	}
+}
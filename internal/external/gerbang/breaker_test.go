@@ -0,0 +1,130 @@
+package gerbang
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(EndpointPPOB, BreakerConfig{FailureThreshold: 3, OpenTimeout: 30 * time.Second}.withDefaults())
+	breaker.now = func() time.Time { return now }
+	failure := errors.New("server error")
+
+	for i := 0; i < 3; i++ {
+		if err := breaker.allow(); err != nil {
+			t.Fatalf("call %d rejected while closed: %v", i, err)
+		}
+		breaker.record(failure, 10*time.Millisecond)
+	}
+	if breaker.state != CircuitOpen {
+		t.Fatalf("state after 3 failures = %s; want open", breaker.state)
+	}
+	if err := breaker.allow(); !IsProviderUnavailable(err) {
+		t.Fatalf("allow while open = %v; want ErrProviderUnavailable", err)
+	}
+
+	// After the timeout a single probe is let through
+	now = now.Add(31 * time.Second)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if err := breaker.allow(); err == nil {
+		t.Fatal("second concurrent probe was allowed")
+	}
+	breaker.record(failure, time.Millisecond)
+	if breaker.state != CircuitOpen {
+		t.Fatalf("state after failed probe = %s; want open", breaker.state)
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	breaker.record(nil, time.Millisecond)
+	if breaker.state != CircuitClosed {
+		t.Fatalf("state after successful probe = %s; want closed", breaker.state)
+	}
+	if health := breaker.snapshot(); health.Rejected != 2 || health.Failures != 4 || health.Score != 100 {
+		t.Errorf("snapshot = %+v", health)
+	}
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	breaker := newCircuitBreaker(EndpointTransfer, BreakerConfig{FailureThreshold: 100, Window: 10, MinRequests: 10}.withDefaults())
+	for i := 0; i < 10; i++ {
+		var err error
+		if i%2 == 1 {
+			err = errors.New("timeout")
+		}
+		breaker.record(err, time.Millisecond)
+	}
+	if breaker.state != CircuitOpen {
+		t.Fatalf("state at 50%% errors = %s; want open", breaker.state)
+	}
+}
+
+func TestClientShortCircuitsOpenEndpoint(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Breaker: BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.doRequest(ctx, http.MethodPost, "/v1/ppob/transaction", nil); err == nil {
+			t.Fatal("expected server error")
+		}
+	}
+	_, err := client.doRequestWithRetry(ctx, http.MethodPost, "/v1/ppob/transaction", nil)
+	if !IsProviderUnavailable(err) {
+		t.Fatalf("error with open circuit = %v; want ErrProviderUnavailable", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("server calls = %d; want 2 (no calls while open)", calls.Load())
+	}
+	if client.Available(EndpointPPOB) || !client.Available(EndpointTransfer) {
+		t.Error("only the PPOB endpoint should be unavailable")
+	}
+}
+
+func TestClientTimeoutsTripBreaker(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Timeout: 50 * time.Millisecond,
+		Breaker: BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.doRequest(ctx, http.MethodGet, "/v1/ppob/transaction/trx-1", nil)
+		if err == nil {
+			t.Fatal("expected client timeout")
+		}
+		if !client.isRetryableError(err) {
+			t.Fatalf("client timeout %q is not retryable", err)
+		}
+	}
+	if client.Available(EndpointPPOB) {
+		t.Fatal("PPOB circuit still closed after repeated client timeouts")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -18,11 +20,13 @@ type Config struct {
 	ClientID     string
 	ClientSecret string
 	Timeout      time.Duration
+	Breaker      BreakerConfig
 }
 
 type Client struct {
 	config     Config
 	httpClient *http.Client
+	breakers   *breakerSet
 }
 
 // NewClient creates a new Gerbang API client
@@ -37,11 +41,24 @@ func NewClient(config Config) *Client {
 			Timeout:   config.Timeout,
 			Transport: httplog.NewTransport(nil, nil),
 		},
+		breakers: newBreakerSet(config.Breaker),
 	}
 }
 
-// doRequest performs HTTP request with authentication headers
-func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*Response, error) {
+// Available reports whether calls to an endpoint group are currently let
+// through, so callers can fail fast before starting expensive work
+func (c *Client) Available(endpoint string) bool {
+	return c.breakers.get(endpoint).available()
+}
+
+// Health returns circuit state, latency and error metrics per endpoint group
+func (c *Client) Health() []EndpointHealth {
+	return c.breakers.snapshot()
+}
+
+// doRequest performs HTTP request with authentication headers, guarded by
+// the circuit breaker of the path's endpoint group
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (resp *Response, err error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -63,22 +80,36 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	req.Header.Set("X-Client-Id", c.config.ClientID)
 	req.Header.Set("User-Agent", "PPOB.ID/1.0")
 
-	resp, err := c.httpClient.Do(req)
+	breaker := c.breakers.get(endpointForPath(path))
+	if err := breaker.allow(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer func() {
+		// Business errors (4xx) mean Gerbang is up; only provider failures count
+		var providerErr error
+		if err != nil && c.isRetryableError(err) {
+			providerErr = err
+		}
+		breaker.record(providerErr, time.Since(start))
+	}()
+
+	httpResp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
-	defer resp.Body.Close()
+	defer httpResp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Handle 5xx errors BEFORE parsing JSON (server may return HTML error page)
-	if resp.StatusCode >= 500 {
+	if httpResp.StatusCode >= 500 {
 		return nil, &Error{
-			Code:    resp.StatusCode,
-			Message: fmt.Sprintf("server error: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			Code:    httpResp.StatusCode,
+			Message: fmt.Sprintf("server error: %d %s", httpResp.StatusCode, http.StatusText(httpResp.StatusCode)),
 		}
 	}
 
@@ -86,7 +117,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	if err := json.Unmarshal(respBody, &apiResp); err != nil {
 		// Include status code and body snippet in error for debugging
 		return nil, fmt.Errorf("failed to unmarshal response (status=%d): %w, body: %s",
-			resp.StatusCode, err, truncateBody(respBody, 200))
+			httpResp.StatusCode, err, truncateBody(respBody, 200))
 	}
 
 	// Check for API errors
@@ -119,8 +150,9 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, path string, bo
 			return resp, nil
 		}
 
-		// Only retry on retryable errors
-		if c.isRetryableError(err) {
+		// Only retry on retryable errors. A write that may have reached
+		// Gerbang is not sent again: it could create a second transaction.
+		if c.isRetryableError(err) && (method == http.MethodGet || requestNotSent(err)) {
 			lastErr = err
 			continue
 		}
@@ -132,25 +164,51 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, path string, bo
 	return nil, fmt.Errorf("max retries (%d) exceeded: %w", maxRetries, lastErr)
 }
 
-// isRetryableError checks if error is transient and worth retrying
+// isRetryableError checks if error is transient and worth retrying. The same
+// errors count as provider failures for the circuit breaker.
 func (c *Client) isRetryableError(err error) bool {
-	if err == nil {
+	if err == nil || IsProviderUnavailable(err) {
 		return false
 	}
 
 	// Check for Gerbang API error with 5xx status
-	if gerr, ok := err.(*Error); ok {
+	var gerr *Error
+	if errors.As(err, &gerr) {
 		return gerr.Code >= 500
 	}
 
-	// Check for network/timeout errors
-	errMsg := err.Error()
+	// Timeouts, including http.Client's "Client.Timeout exceeded" errors
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// Connection refused/reset and DNS failures
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	errMsg := strings.ToLower(err.Error())
 	return strings.Contains(errMsg, "timeout") ||
 		strings.Contains(errMsg, "connection refused") ||
 		strings.Contains(errMsg, "connection reset") ||
 		strings.Contains(errMsg, "no such host")
 }
 
+// requestNotSent reports whether err proves the request never reached Gerbang
+func requestNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
 // truncateBody truncates body for error messages
 func truncateBody(body []byte, maxLen int) string {
 	if len(body) <= maxLen {
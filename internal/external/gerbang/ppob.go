@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // ========== PPOB Methods ==========
@@ -81,6 +82,24 @@ func (c *Client) GetTransactionStatus(ctx context.Context, transactionID string)
 	return &txResp, nil
 }
 
+// GetTransactionStatusByReference gets transaction status by our reference ID,
+// for transactions whose creation call returned no transaction ID
+func (c *Client) GetTransactionStatusByReference(ctx context.Context, referenceID string) (*TransactionResponse, error) {
+	path := fmt.Sprintf("/v1/ppob/transaction?referenceId=%s", url.QueryEscape(referenceID))
+
+	resp, err := c.doRequestWithRetry(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction status: %w", err)
+	}
+
+	var txResp TransactionResponse
+	if err := c.parseData(resp, &txResp); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction status: %w", err)
+	}
+
+	return &txResp, nil
+}
+
 // ========== Helper Methods for Transaction Types ==========
 
 // CreatePrepaidTransaction creates a prepaid transaction
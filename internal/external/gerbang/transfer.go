@@ -44,3 +44,18 @@ func (c *Client) TransferExecute(ctx context.Context, req GerbangTransferExecute
 
 	return &executeResp, nil
 }
+
+// GetTransferStatus gets transfer status by Gerbang transfer ID
+func (c *Client) GetTransferStatus(ctx context.Context, transferID string) (*GerbangTransferExecuteResponse, error) {
+	resp, err := c.doRequestWithRetry(ctx, "GET", "/v1/transfer/"+transferID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statusResp GerbangTransferExecuteResponse
+	if err := c.parseData(resp, &statusResp); err != nil {
+		return nil, fmt.Errorf("failed to parse transfer status response: %w", err)
+	}
+
+	return &statusResp, nil
+}
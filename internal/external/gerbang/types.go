@@ -182,6 +182,13 @@ type GerbangTransferInquiryResponse struct {
 	ExpiredAt     string `json:"expiredAt"`
 }
 
+// Transfer types (rails) Gerbang can send a transfer over
+const (
+	TransferTypeBIFast = "BIFAST"
+	TransferTypeOnline = "ONLINE"
+	TransferTypeRTGS   = "RTGS"
+)
+
 // GerbangTransferExecuteRequest untuk Gerbang API
 type GerbangTransferExecuteRequest struct {
 	ReferenceID   string `json:"referenceId"`
@@ -190,8 +197,9 @@ type GerbangTransferExecuteRequest struct {
 	AccountNumber string `json:"accountNumber"`
 	AccountName   string `json:"accountName"`
 	Amount        int64  `json:"amount"`
-	Purpose       string `json:"purpose"`         // WAJIB, default "99" jika kosong
-	Remark        string `json:"remark,omitempty"` // Optional
+	TransferType  string `json:"transferType,omitempty"` // BIFAST, ONLINE, RTGS; kosong: dipilih Gerbang
+	Purpose       string `json:"purpose"`                // WAJIB, default "99" jika kosong
+	Remark        string `json:"remark,omitempty"`       // Optional
 }
 
 // GerbangTransferExecuteResponse dari Gerbang API
@@ -23,6 +23,7 @@ type Config struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	Endpoint        string // Optional for S3-compatible services
+	UsePathStyle    bool   // Address buckets by path, as MinIO expects
 	PublicURL       string // Base URL for public access
 }
 
@@ -56,6 +57,7 @@ func NewClient(cfg Config) (*Client, error) {
 		if cfg.Endpoint != "" {
 			o.BaseEndpoint = aws.String(cfg.Endpoint)
 		}
+		o.UsePathStyle = cfg.UsePathStyle
 	})
 
 	return &Client{
@@ -0,0 +1,201 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// envelopeMagic starts every sealed object. Objects without it were stored
+// before encryption and are read as plaintext.
+var envelopeMagic = []byte("PPOBENV1")
+
+const dataKeySize = 32
+
+// ErrUnknownMasterKey is returned when an object was sealed with a master key
+// that is no longer in the keyring
+var ErrUnknownMasterKey = errors.New("envelope: unknown master key")
+
+// Keyring holds the master keys that wrap per-object data keys. New objects
+// are sealed with the active key; older keys stay to open objects sealed
+// before a rotation.
+type Keyring struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyring creates a keyring with activeID as the key for new objects.
+// Every key must be 32 bytes (AES-256).
+func NewKeyring(activeID string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("envelope: active master key %q not in keyring", activeID)
+	}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("envelope: invalid master key id %q", id)
+		}
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("envelope: master key %q must be %d bytes", id, dataKeySize)
+		}
+	}
+	return &Keyring{activeID: activeID, keys: keys}, nil
+}
+
+// ParseKeyring reads keys written as "id:base64,id:base64". The first key is
+// the active one. An empty spec returns a nil keyring.
+func ParseKeyring(spec string) (*Keyring, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte)
+	activeID := ""
+	for _, entry := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("envelope: master key entry must be id:base64")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: master key %q is not valid base64: %w", id, err)
+		}
+		if activeID == "" {
+			activeID = id
+		}
+		keys[id] = key
+	}
+	return NewKeyring(activeID, keys)
+}
+
+// IsSealed reports whether data is an envelope written by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, envelopeMagic)
+}
+
+// Seal encrypts plaintext with a fresh data key and wraps the data key with
+// the active master key. The envelope is
+//
+//	magic | keyID | contentType | wrapped data key | nonce | ciphertext
+//
+// with each variable field prefixed by its length; the header is
+// authenticated as additional data of the ciphertext.
+func (k *Keyring) Seal(plaintext []byte, contentType string) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("envelope: failed to generate data key: %w", err)
+	}
+
+	wrappedKey, err := gcmSeal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.Write(envelopeMagic)
+	writeField(&header, []byte(k.activeID))
+	writeField(&header, []byte(contentType))
+	writeField(&header, wrappedKey)
+
+	ciphertext, err := gcmSeal(dataKey, plaintext, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return append(header.Bytes(), ciphertext...), nil
+}
+
+// Open decrypts an envelope and returns the plaintext with the content type
+// it was sealed with
+func (k *Keyring) Open(envelope []byte) ([]byte, string, error) {
+	if !IsSealed(envelope) {
+		return nil, "", errors.New("envelope: data is not sealed")
+	}
+
+	reader := bytes.NewReader(envelope[len(envelopeMagic):])
+	keyID, err := readField(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType, err := readField(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	wrappedKey, err := readField(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	headerLen := len(envelope) - reader.Len()
+
+	masterKey, ok := k.keys[string(keyID)]
+	if !ok {
+		return nil, "", ErrUnknownMasterKey
+	}
+	dataKey, err := gcmOpen(masterKey, wrappedKey, keyID)
+	if err != nil {
+		return nil, "", fmt.Errorf("envelope: failed to unwrap data key: %w", err)
+	}
+	plaintext, err := gcmOpen(dataKey, envelope[headerLen:], envelope[:headerLen])
+	if err != nil {
+		return nil, "", fmt.Errorf("envelope: failed to decrypt object: %w", err)
+	}
+	return plaintext, string(contentType), nil
+}
+
+// gcmSeal encrypts with AES-GCM and prepends the random nonce
+func gcmSeal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("envelope: failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func gcmOpen(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("envelope: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeField(buf *bytes.Buffer, field []byte) {
+	var length [2]byte
+	binary.BigEndian.PutUint16(length[:], uint16(len(field)))
+	buf.Write(length[:])
+	buf.Write(field)
+}
+
+func readField(reader *bytes.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return nil, errors.New("envelope: truncated header")
+	}
+	field := make([]byte, length)
+	if _, err := io.ReadFull(reader, field); err != nil {
+		return nil, errors.New("envelope: truncated header")
+	}
+	return field, nil
+}
@@ -0,0 +1,84 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func testKey(fill byte) []byte {
+	return bytes.Repeat([]byte{fill}, dataKeySize)
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+
+	plaintext := []byte("\xff\xd8\xff ktp image bytes")
+	sealed, err := keyring.Seal(plaintext, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, plaintext) {
+		t.Fatalf("sealed object must be an envelope without the plaintext")
+	}
+
+	opened, contentType, err := keyring.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) || contentType != "image/jpeg" {
+		t.Errorf("Open = %q, %q; want original bytes and image/jpeg", opened, contentType)
+	}
+
+	again, _ := keyring.Seal(plaintext, "image/jpeg")
+	if bytes.Equal(again, sealed) {
+		t.Errorf("each object must get its own data key and nonce")
+	}
+}
+
+func TestEnvelopeTamperAndRotation(t *testing.T) {
+	oldRing, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	sealed, _ := oldRing.Seal([]byte("selfie"), "image/png")
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0x01
+	if _, _, err := oldRing.Open(tampered); err == nil {
+		t.Errorf("expected tampered ciphertext to fail")
+	}
+
+	// The content type is part of the authenticated header
+	retyped := bytes.Replace(sealed, []byte("image/png"), []byte("image/gif"), 1)
+	if _, _, err := oldRing.Open(retyped); err == nil {
+		t.Errorf("expected tampered header to fail")
+	}
+
+	spec := "k2:" + base64.StdEncoding.EncodeToString(testKey(2)) + ",k1:" + base64.StdEncoding.EncodeToString(testKey(1))
+	rotated, err := ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	if opened, _, err := rotated.Open(sealed); err != nil || string(opened) != "selfie" {
+		t.Errorf("rotated keyring must open objects sealed with the old key: %q, %v", opened, err)
+	}
+
+	newSealed, _ := rotated.Seal([]byte("selfie"), "image/png")
+	if _, _, err := oldRing.Open(newSealed); !errors.Is(err, ErrUnknownMasterKey) {
+		t.Errorf("expected ErrUnknownMasterKey, got %v", err)
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	if ring, err := ParseKeyring(""); ring != nil || err != nil {
+		t.Errorf("empty spec must return no keyring, got %v, %v", ring, err)
+	}
+	if _, err := ParseKeyring("k1:" + base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Errorf("expected short master key to be rejected")
+	}
+	if _, err := ParseKeyring("k1"); err == nil {
+		t.Errorf("expected entry without key to be rejected")
+	}
+}
@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sealedContentType is stored on S3 for sealed objects; the real content type
+// is kept inside the envelope
+const sealedContentType = "application/octet-stream"
+
+// SealedStore stores private objects envelope-encrypted. Without a keyring
+// objects are stored as plaintext, which is only meant for local development.
+type SealedStore struct {
+	client  *Client
+	keyring *Keyring
+}
+
+// NewSealedStore creates a store on client; keyring may be nil
+func NewSealedStore(client *Client, keyring *Keyring) *SealedStore {
+	return &SealedStore{client: client, keyring: keyring}
+}
+
+// Encrypted reports whether new objects are sealed
+func (s *SealedStore) Encrypted() bool {
+	return s.keyring != nil
+}
+
+// Put seals data and uploads it under folder with a unique name, returning
+// the object key
+func (s *SealedStore) Put(ctx context.Context, data []byte, folder, filename, contentType string) (string, error) {
+	key := fmt.Sprintf("%s/%s-%d%s", folder, uuid.New().String(), time.Now().Unix(), filepath.Ext(filename))
+
+	body, storedType := data, contentType
+	if s.keyring != nil {
+		sealed, err := s.keyring.Seal(data, contentType)
+		if err != nil {
+			return "", err
+		}
+		body, storedType = sealed, sealedContentType
+	}
+
+	if err := s.client.PutBytes(ctx, body, key, storedType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Get downloads and opens an object. Objects stored before encryption are
+// returned as they are.
+func (s *SealedStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	data, contentType, err := s.client.GetObjectBytes(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	if !IsSealed(data) {
+		return data, contentType, nil
+	}
+	if s.keyring == nil {
+		return nil, "", fmt.Errorf("object %s is sealed but no master key is configured", key)
+	}
+	return s.keyring.Open(data)
+}
+
+// Delete removes an object
+func (s *SealedStore) Delete(ctx context.Context, key string) error {
+	return s.client.DeleteFile(ctx, key)
+}
+
+// List returns the object keys under prefix
+func (s *SealedStore) List(ctx context.Context, prefix string) ([]string, error) {
+	return s.client.ListObjectKeys(ctx, prefix)
+}
@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+)
+
+// TestSealedStoreMinIO runs against a local S3 stand-in:
+//
+//	docker compose --profile minio up -d
+//	S3_TEST_ENDPOINT=http://localhost:9000 go test ./internal/external/s3/
+func TestSealedStoreMinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+	bucket := os.Getenv("S3_TEST_BUCKET")
+	if bucket == "" {
+		bucket = "ppob-id-kyc"
+	}
+	client, err := NewClient(Config{
+		Region:          "us-east-1",
+		Bucket:          bucket,
+		AccessKeyID:     envOr("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretAccessKey: envOr("S3_TEST_SECRET_KEY", "minioadmin"),
+		Endpoint:        endpoint,
+		UsePathStyle:    true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	keyring, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	store := NewSealedStore(client, keyring)
+	ctx := context.Background()
+
+	plaintext := []byte("\xff\xd8\xff ktp image bytes")
+	key, err := store.Put(ctx, plaintext, "kyc/usr_test", "ktp.jpg", "image/jpeg")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	defer store.Delete(ctx, key)
+
+	raw, _, err := client.GetObjectBytes(ctx, key)
+	if err != nil || !IsSealed(raw) || bytes.Contains(raw, plaintext) {
+		t.Fatalf("stored object must be sealed: %v", err)
+	}
+	opened, contentType, err := store.Get(ctx, key)
+	if err != nil || !bytes.Equal(opened, plaintext) || contentType != "image/jpeg" {
+		t.Fatalf("Get = %q, %q, %v; want original bytes", opened, contentType, err)
+	}
+
+	keys, err := store.List(ctx, "kyc/usr_test/")
+	if err != nil || len(keys) == 0 {
+		t.Fatalf("List = %v, %v; want the stored key", keys, err)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
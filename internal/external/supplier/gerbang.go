@@ -0,0 +1,128 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
+)
+
+// GerbangCode is the supplier code of the Gerbang aggregator
+const GerbangCode = "gerbang"
+
+// GerbangSupplier adapts the Gerbang client to the Supplier interface
+type GerbangSupplier struct {
+	client *gerbang.Client
+}
+
+// NewGerbangSupplier creates a supplier backed by Gerbang
+func NewGerbangSupplier(client *gerbang.Client) *GerbangSupplier {
+	return &GerbangSupplier{client: client}
+}
+
+// Code returns the supplier code
+func (s *GerbangSupplier) Code() string {
+	return GerbangCode
+}
+
+// Available reports whether Gerbang's PPOB circuit lets calls through
+func (s *GerbangSupplier) Available() bool {
+	return s.client.Available(gerbang.EndpointPPOB)
+}
+
+// Purchase buys a prepaid product
+func (s *GerbangSupplier) Purchase(ctx context.Context, req Request) (*Transaction, error) {
+	resp, err := s.client.CreatePrepaidTransaction(ctx, req.ReferenceID, req.SKUCode, req.CustomerNo)
+	return s.result(resp, err)
+}
+
+// Inquiry looks up a postpaid bill
+func (s *GerbangSupplier) Inquiry(ctx context.Context, req Request) (*Transaction, error) {
+	resp, err := s.client.CreateInquiry(ctx, req.ReferenceID, req.SKUCode, req.CustomerNo)
+	return s.result(resp, err)
+}
+
+// Pay pays a postpaid bill
+func (s *GerbangSupplier) Pay(ctx context.Context, req Request) (*Transaction, error) {
+	resp, err := s.client.CreatePostpaidPayment(ctx, req.ReferenceID, req.InquiryID, req.SKUCode, req.CustomerNo)
+	return s.result(resp, err)
+}
+
+// Status looks up a transaction by its Gerbang transaction ID
+func (s *GerbangSupplier) Status(ctx context.Context, transactionID string) (*Transaction, error) {
+	resp, err := s.client.GetTransactionStatus(ctx, transactionID)
+	return s.result(resp, err)
+}
+
+// StatusByReference looks up a transaction by our reference ID
+func (s *GerbangSupplier) StatusByReference(ctx context.Context, referenceID string) (*Transaction, error) {
+	resp, err := s.client.GetTransactionStatusByReference(ctx, referenceID)
+	return s.result(resp, err)
+}
+
+func (s *GerbangSupplier) result(resp *gerbang.TransactionResponse, err error) (*Transaction, error) {
+	if err != nil {
+		return nil, gerbangError(err)
+	}
+	return &Transaction{
+		Supplier:      GerbangCode,
+		TransactionID: resp.TransactionID,
+		ReferenceID:   resp.ReferenceID,
+		SKUCode:       resp.SKUCode,
+		CustomerNo:    resp.CustomerNo,
+		CustomerName:  resp.CustomerName,
+		Status:        resp.Status,
+		SerialNumber:  resp.SerialNumber,
+		Price:         resp.Price,
+		Admin:         resp.Admin,
+		Amount:        resp.Amount,
+		TotalAmount:   resp.TotalAmount,
+		Period:        resp.Period,
+		Description:   resp.Description,
+	}, nil
+}
+
+// gerbangError maps a Gerbang client error to a normalized supplier error.
+// Only errors that prove Gerbang did not take the request map to
+// CodeUnavailable; 5xx, 408, 409 and 429 answers and broken connections map
+// to CodeTimeout because the transaction may already be processing.
+func gerbangError(err error) error {
+	code := CodeUnknown
+	message := err.Error()
+
+	var gerr *gerbang.Error
+	switch {
+	case gerbang.IsProviderUnavailable(err):
+		code = CodeUnavailable
+	case errors.As(err, &gerr):
+		message = gerr.Message
+		msg := strings.ToLower(gerr.Message)
+		switch {
+		case strings.Contains(msg, "stok") || strings.Contains(msg, "stock") || strings.Contains(msg, "gangguan"):
+			code = CodeOutOfStock
+		case gerr.Code == gerbang.ErrCodeInsufficientFunds:
+			code = CodeInsufficientDeposit
+		case gerr.Code == gerbang.ErrCodeNotFound:
+			code = CodeNotFound
+		case gerr.Code >= 500 || gerr.Code == http.StatusRequestTimeout || gerr.Code == gerbang.ErrCodeConflict ||
+			gerr.Code == http.StatusTooManyRequests:
+			code = CodeTimeout
+		case gerr.Code == gerbang.ErrCodeUnauthorized || gerr.Code == gerbang.ErrCodeForbidden:
+			code = CodeUnavailable
+		case gerr.Code >= 400:
+			code = CodeInvalidRequest
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		code = CodeTimeout
+	default:
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			code = transportCode(err)
+		}
+	}
+
+	return &Error{Supplier: GerbangCode, Code: code, Message: message, Err: err}
+}
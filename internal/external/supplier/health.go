@@ -0,0 +1,147 @@
+package supplier
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthTracker tracks supplier health in memory. A supplier becomes
+// unhealthy after a number of consecutive supplier-side failures and stays
+// so for a cooldown; once the cooldown passes it is tried again, and a
+// single success makes it healthy.
+type HealthTracker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	states    map[string]*healthState
+}
+
+type healthState struct {
+	consecutiveFailures int
+	successes           int64
+	failures            int64
+	lastError           string
+	lastErrorCode       string
+	lastFailureAt       *time.Time
+	lastSuccessAt       *time.Time
+	unhealthyUntil      *time.Time
+}
+
+// HealthStatus is a snapshot of a supplier's health
+type HealthStatus struct {
+	Supplier            string     `json:"supplier"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	Successes           int64      `json:"successes"`
+	Failures            int64      `json:"failures"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastErrorCode       string     `json:"lastErrorCode,omitempty"`
+	LastFailureAt       *time.Time `json:"lastFailureAt"`
+	LastSuccessAt       *time.Time `json:"lastSuccessAt"`
+	UnhealthyUntil      *time.Time `json:"unhealthyUntil"`
+}
+
+// NewHealthTracker creates a tracker marking a supplier unhealthy after
+// threshold consecutive failures, for the given cooldown
+func NewHealthTracker(threshold int, cooldown time.Duration) *HealthTracker {
+	if threshold <= 0 {
+		threshold = 3
+	}
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+	return &HealthTracker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		states:    make(map[string]*healthState),
+	}
+}
+
+// Record records the outcome of a supplier call. Errors caused by the
+// request itself (invalid customer number, no bill) do not count against
+// the supplier.
+func (h *HealthTracker) Record(supplierCode string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state := h.state(supplierCode)
+	now := h.now()
+	if err == nil {
+		state.successes++
+		state.consecutiveFailures = 0
+		state.unhealthyUntil = nil
+		state.lastSuccessAt = &now
+		return
+	}
+
+	code := CodeOf(err)
+	if !countsAgainstHealth(code) {
+		return
+	}
+	state.failures++
+	state.consecutiveFailures++
+	state.lastError = err.Error()
+	state.lastErrorCode = code
+	state.lastFailureAt = &now
+	if state.consecutiveFailures >= h.threshold {
+		until := now.Add(h.cooldown)
+		state.unhealthyUntil = &until
+	}
+}
+
+// IsHealthy reports whether a supplier is currently usable
+func (h *HealthTracker) IsHealthy(supplierCode string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state, ok := h.states[supplierCode]
+	return !ok || state.unhealthyUntil == nil || !h.now().Before(*state.unhealthyUntil)
+}
+
+// Snapshot returns the health of every supplier seen so far, by code
+func (h *HealthTracker) Snapshot() []HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	statuses := make([]HealthStatus, 0, len(h.states))
+	for code, state := range h.states {
+		statuses = append(statuses, HealthStatus{
+			Supplier:            code,
+			Healthy:             state.unhealthyUntil == nil || !now.Before(*state.unhealthyUntil),
+			ConsecutiveFailures: state.consecutiveFailures,
+			Successes:           state.successes,
+			Failures:            state.failures,
+			LastError:           state.lastError,
+			LastErrorCode:       state.lastErrorCode,
+			LastFailureAt:       state.lastFailureAt,
+			LastSuccessAt:       state.lastSuccessAt,
+			UnhealthyUntil:      state.unhealthyUntil,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Supplier < statuses[j].Supplier })
+	return statuses
+}
+
+func (h *HealthTracker) state(supplierCode string) *healthState {
+	state, ok := h.states[supplierCode]
+	if !ok {
+		state = &healthState{}
+		h.states[supplierCode] = state
+	}
+	return state
+}
+
+// countsAgainstHealth reports whether an error code indicates a problem
+// with the supplier rather than with the request
+func countsAgainstHealth(code string) bool {
+	switch code {
+	case CodeInvalidRequest, CodeNotFound, CodeOutOfStock:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,204 @@
+package supplier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/pkg/httplog"
+)
+
+// HTTPConfig configures a supplier speaking the generic HTTP protocol
+type HTTPConfig struct {
+	Code    string
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// HTTPSupplier is a supplier speaking a small JSON protocol:
+//
+//	POST {baseURL}/v1/transactions
+//	{"type":"prepaid|inquiry|payment","referenceId":"...","skuCode":"...","customerNo":"...","inquiryId":"..."}
+//	GET  {baseURL}/v1/transactions/{transactionId}
+//	GET  {baseURL}/v1/transactions?referenceId={referenceId}
+//
+// Successful calls answer {"data":{...transaction...}}; failures answer a
+// non-2xx status with {"error":{"code":"out_of_stock","message":"..."}} using
+// the normalized error codes of this package.
+type HTTPSupplier struct {
+	config     HTTPConfig
+	httpClient *http.Client
+}
+
+// NewHTTPSupplier creates a new HTTP supplier
+func NewHTTPSupplier(config HTTPConfig) *HTTPSupplier {
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &HTTPSupplier{
+		config: config,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: httplog.NewTransport(nil, nil),
+		},
+	}
+}
+
+// httpRequest is the wire format of a transaction request
+type httpRequest struct {
+	Type        string `json:"type"`
+	ReferenceID string `json:"referenceId"`
+	SKUCode     string `json:"skuCode"`
+	CustomerNo  string `json:"customerNo"`
+	InquiryID   string `json:"inquiryId,omitempty"`
+}
+
+// httpTransaction is the wire format of a transaction result
+type httpTransaction struct {
+	TransactionID string                 `json:"transactionId"`
+	ReferenceID   string                 `json:"referenceId"`
+	SKUCode       string                 `json:"skuCode"`
+	CustomerNo    string                 `json:"customerNo"`
+	CustomerName  string                 `json:"customerName,omitempty"`
+	Status        string                 `json:"status"`
+	SerialNumber  *string                `json:"serialNumber,omitempty"`
+	Price         int64                  `json:"price,omitempty"`
+	Admin         int64                  `json:"admin,omitempty"`
+	Amount        int64                  `json:"amount,omitempty"`
+	TotalAmount   int64                  `json:"totalAmount,omitempty"`
+	Period        string                 `json:"period,omitempty"`
+	Description   map[string]interface{} `json:"description,omitempty"`
+}
+
+// httpResponse is the wire envelope of every response
+type httpResponse struct {
+	Data  *httpTransaction `json:"data,omitempty"`
+	Error *httpError       `json:"error,omitempty"`
+}
+
+type httpError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Code returns the supplier code
+func (s *HTTPSupplier) Code() string {
+	return s.config.Code
+}
+
+// Purchase buys a prepaid product
+func (s *HTTPSupplier) Purchase(ctx context.Context, req Request) (*Transaction, error) {
+	return s.do(ctx, "prepaid", req)
+}
+
+// Inquiry looks up a postpaid bill
+func (s *HTTPSupplier) Inquiry(ctx context.Context, req Request) (*Transaction, error) {
+	return s.do(ctx, "inquiry", req)
+}
+
+// Pay pays a postpaid bill
+func (s *HTTPSupplier) Pay(ctx context.Context, req Request) (*Transaction, error) {
+	return s.do(ctx, "payment", req)
+}
+
+// Status looks up a transaction by the supplier's transaction ID
+func (s *HTTPSupplier) Status(ctx context.Context, transactionID string) (*Transaction, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.BaseURL+"/v1/transactions/"+url.PathEscape(transactionID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return s.send(httpReq)
+}
+
+// StatusByReference looks up a transaction by our reference ID
+func (s *HTTPSupplier) StatusByReference(ctx context.Context, referenceID string) (*Transaction, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.BaseURL+"/v1/transactions?referenceId="+url.QueryEscape(referenceID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return s.send(httpReq)
+}
+
+func (s *HTTPSupplier) do(ctx context.Context, txType string, req Request) (*Transaction, error) {
+	body, err := json.Marshal(httpRequest{
+		Type:        txType,
+		ReferenceID: req.ReferenceID,
+		SKUCode:     req.SKUCode,
+		CustomerNo:  req.CustomerNo,
+		InquiryID:   req.InquiryID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.BaseURL+"/v1/transactions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	return s.send(httpReq)
+}
+
+// send performs a request and decodes the transaction envelope
+func (s *HTTPSupplier) send(httpReq *http.Request) (*Transaction, error) {
+	httpReq.Header.Set("X-Api-Key", s.config.APIKey)
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, s.error(transportCode(err), err.Error(), err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, s.error(CodeTimeout, "failed to read response", err)
+	}
+
+	var envelope httpResponse
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		if resp.StatusCode >= 500 {
+			// The supplier may have taken the order before failing
+			return nil, s.error(CodeTimeout, fmt.Sprintf("server error: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode)), nil)
+		}
+		return nil, s.error(CodeUnknown, fmt.Sprintf("failed to unmarshal response (status=%d)", resp.StatusCode), err)
+	}
+
+	if resp.StatusCode >= 300 || envelope.Error != nil || envelope.Data == nil {
+		code, message := CodeUnknown, http.StatusText(resp.StatusCode)
+		if envelope.Error != nil {
+			code, message = envelope.Error.Code, envelope.Error.Message
+		} else if resp.StatusCode >= 500 {
+			code = CodeTimeout
+		}
+		return nil, s.error(code, message, nil)
+	}
+
+	data := envelope.Data
+	return &Transaction{
+		Supplier:      s.config.Code,
+		TransactionID: data.TransactionID,
+		ReferenceID:   data.ReferenceID,
+		SKUCode:       data.SKUCode,
+		CustomerNo:    data.CustomerNo,
+		CustomerName:  data.CustomerName,
+		Status:        data.Status,
+		SerialNumber:  data.SerialNumber,
+		Price:         data.Price,
+		Admin:         data.Admin,
+		Amount:        data.Amount,
+		TotalAmount:   data.TotalAmount,
+		Period:        data.Period,
+		Description:   data.Description,
+	}, nil
+}
+
+func (s *HTTPSupplier) error(code, message string, err error) *Error {
+	return &Error{Supplier: s.config.Code, Code: code, Message: message, Err: err}
+}
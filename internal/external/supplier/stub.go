@@ -0,0 +1,189 @@
+package supplier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StubConfig scripts the behaviour of the local stub supplier
+type StubConfig struct {
+	APIKey   string            // Required X-Api-Key (empty accepts any key)
+	Failures map[string]string // SKU code (or "*" for all) → error code to answer with
+	Latency  time.Duration     // Delay before every answer
+}
+
+// StubHandler is an in-process supplier speaking the HTTPSupplier protocol.
+// It is served by cmd/supplierstub for local development and by httptest
+// servers in tests to exercise failover paths. Customer numbers ending in
+// "0000" have no outstanding bill.
+type StubHandler struct {
+	config   StubConfig
+	mu       sync.RWMutex
+	failures map[string]string
+	calls    atomic.Int64
+	issued   sync.Map // Transaction ID → httpTransaction, for status lookups
+	byRef    sync.Map // Reference ID → transaction ID
+}
+
+// NewStubHandler creates a new stub supplier handler
+func NewStubHandler(config StubConfig) *StubHandler {
+	failures := make(map[string]string, len(config.Failures))
+	for sku, code := range config.Failures {
+		failures[sku] = code
+	}
+	return &StubHandler{config: config, failures: failures}
+}
+
+// SetFailure makes the stub fail requests for a SKU ("*" for all) with the
+// given code; an empty code clears the failure
+func (h *StubHandler) SetFailure(skuCode, code string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if code == "" {
+		delete(h.failures, skuCode)
+		return
+	}
+	h.failures[skuCode] = code
+}
+
+// Calls returns the number of transaction requests received
+func (h *StubHandler) Calls() int64 {
+	return h.calls.Load()
+}
+
+// ServeHTTP implements http.Handler
+func (h *StubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.config.APIKey != "" && r.Header.Get("X-Api-Key") != h.config.APIKey {
+		writeStubError(w, http.StatusUnauthorized, CodeUnavailable, "invalid api key")
+		return
+	}
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/transactions/") {
+		h.serveStatus(w, strings.TrimPrefix(r.URL.Path, "/v1/transactions/"))
+		return
+	}
+	if r.Method == http.MethodGet && r.URL.Path == "/v1/transactions" {
+		h.serveStatusByReference(w, r.URL.Query().Get("referenceId"))
+		return
+	}
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/transactions" {
+		writeStubError(w, http.StatusNotFound, CodeInvalidRequest, "unknown endpoint")
+		return
+	}
+	h.calls.Add(1)
+
+	if h.config.Latency > 0 {
+		select {
+		case <-time.After(h.config.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var req httpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SKUCode == "" || req.CustomerNo == "" {
+		writeStubError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	h.mu.RLock()
+	code, ok := h.failures[req.SKUCode]
+	if !ok {
+		code, ok = h.failures["*"]
+	}
+	h.mu.RUnlock()
+	if ok {
+		writeStubError(w, stubStatus(code), code, fmt.Sprintf("stub failure for %s", req.SKUCode))
+		return
+	}
+
+	now := time.Now()
+	tx := httpTransaction{
+		TransactionID: fmt.Sprintf("STUB%d", now.UnixNano()),
+		ReferenceID:   req.ReferenceID,
+		SKUCode:       req.SKUCode,
+		CustomerNo:    req.CustomerNo,
+		Status:        StatusSuccess,
+	}
+
+	switch req.Type {
+	case "prepaid":
+		serial := fmt.Sprintf("SN%d", now.Unix())
+		tx.SerialNumber = &serial
+	case "inquiry":
+		if strings.HasSuffix(req.CustomerNo, "0000") {
+			writeStubError(w, http.StatusNotFound, CodeNotFound, "tagihan tidak ditemukan")
+			return
+		}
+		tx.CustomerName = "PELANGGAN STUB"
+		tx.Amount = 150000
+		tx.Admin = 2500
+		tx.TotalAmount = tx.Amount + tx.Admin
+		tx.Period = now.Format("200601")
+	case "payment":
+		if req.InquiryID == "" {
+			writeStubError(w, http.StatusBadRequest, CodeInvalidRequest, "inquiryId is required")
+			return
+		}
+		serial := fmt.Sprintf("REF%d", now.Unix())
+		tx.SerialNumber = &serial
+	default:
+		writeStubError(w, http.StatusBadRequest, CodeInvalidRequest, "unknown transaction type")
+		return
+	}
+
+	h.issued.Store(tx.TransactionID, tx)
+	h.byRef.Store(tx.ReferenceID, tx.TransactionID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(httpResponse{Data: &tx})
+}
+
+// serveStatus answers a status lookup of a transaction issued earlier
+func (h *StubHandler) serveStatus(w http.ResponseWriter, transactionID string) {
+	value, ok := h.issued.Load(transactionID)
+	if !ok {
+		writeStubError(w, http.StatusNotFound, CodeNotFound, "transaksi tidak ditemukan")
+		return
+	}
+	tx := value.(httpTransaction)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(httpResponse{Data: &tx})
+}
+
+// serveStatusByReference answers a status lookup by our reference ID
+func (h *StubHandler) serveStatusByReference(w http.ResponseWriter, referenceID string) {
+	transactionID, ok := h.byRef.Load(referenceID)
+	if !ok {
+		writeStubError(w, http.StatusNotFound, CodeNotFound, "transaksi tidak ditemukan")
+		return
+	}
+	h.serveStatus(w, transactionID.(string))
+}
+
+// stubStatus maps a normalized error code to the HTTP status the stub answers with
+func stubStatus(code string) int {
+	switch code {
+	case CodeInvalidRequest:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeInsufficientDeposit:
+		return http.StatusPaymentRequired
+	case CodeOutOfStock:
+		return http.StatusConflict
+	case CodeTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+func writeStubError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(httpResponse{Error: &httpError{Code: code, Message: message}})
+}
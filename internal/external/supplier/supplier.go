@@ -0,0 +1,154 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Supplier is a PPOB product provider that can sell prepaid products and
+// inquire and pay postpaid bills
+type Supplier interface {
+	// Code returns the unique supplier code used in routing tables
+	Code() string
+	// Purchase buys a prepaid product
+	Purchase(ctx context.Context, req Request) (*Transaction, error)
+	// Inquiry looks up a postpaid bill
+	Inquiry(ctx context.Context, req Request) (*Transaction, error)
+	// Pay pays a postpaid bill previously returned by Inquiry
+	Pay(ctx context.Context, req Request) (*Transaction, error)
+}
+
+// AvailabilityReporter is implemented by suppliers that can tell without a
+// call that they are currently unreachable
+type AvailabilityReporter interface {
+	Available() bool
+}
+
+// StatusChecker is implemented by suppliers that can look up the current
+// status of a transaction they accepted earlier
+type StatusChecker interface {
+	// Status returns the transaction with the supplier's transaction ID
+	Status(ctx context.Context, transactionID string) (*Transaction, error)
+}
+
+// ReferenceStatusChecker is implemented by suppliers that can look up a
+// transaction by our reference ID, for calls that returned no answer
+type ReferenceStatusChecker interface {
+	// StatusByReference returns the transaction created for our reference ID.
+	// It fails with CodeNotFound when the supplier never took the request.
+	StatusByReference(ctx context.Context, referenceID string) (*Transaction, error)
+}
+
+// Request is a supplier transaction request
+type Request struct {
+	ReferenceID string // Our transaction/order ID
+	SKUCode     string // The supplier's own SKU code
+	CustomerNo  string
+	InquiryID   string // Supplier transaction ID of the inquiry (postpaid payment only)
+}
+
+// Transaction is the normalized supplier transaction result
+type Transaction struct {
+	Supplier      string
+	TransactionID string
+	ReferenceID   string
+	SKUCode       string
+	CustomerNo    string
+	CustomerName  string
+	Status        string
+	SerialNumber  *string
+	Price         int64
+	Admin         int64
+	Amount        int64
+	TotalAmount   int64
+	Period        string
+	Description   map[string]interface{}
+}
+
+// Transaction statuses, shared with the Gerbang API so webhooks and
+// synchronous results can be handled the same way
+const (
+	StatusPending    = "Pending"
+	StatusProcessing = "Processing"
+	StatusSuccess    = "Success"
+	StatusFailed     = "Failed"
+)
+
+// Normalized error codes
+const (
+	CodeOutOfStock          = "out_of_stock"         // Product is empty or disrupted at the supplier
+	CodeUnavailable         = "unavailable"          // Supplier is down or unreachable; the request was never accepted
+	CodeInsufficientDeposit = "insufficient_deposit" // Our deposit at the supplier is exhausted
+	CodeInvalidRequest      = "invalid_request"      // Bad customer number, unknown SKU, etc.
+	CodeNotFound            = "not_found"            // No bill / transaction not found
+	CodeTimeout             = "timeout"              // No definite answer (timeout, reset, 5xx); the transaction may still go through
+	CodeUnknown             = "unknown"
+)
+
+// Error is a supplier error with a normalized code
+type Error struct {
+	Supplier string
+	Code     string
+	Message  string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("supplier %s error [%s]: %s", e.Supplier, e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// AsError returns the supplier error wrapped in err, if any
+func AsError(err error) (*Error, bool) {
+	var serr *Error
+	if errors.As(err, &serr) {
+		return serr, true
+	}
+	return nil, false
+}
+
+// CodeOf returns the normalized code of err, or CodeUnknown
+func CodeOf(err error) string {
+	if serr, ok := AsError(err); ok {
+		return serr.Code
+	}
+	return CodeUnknown
+}
+
+// IsCode checks whether err is a supplier error with the given code
+func IsCode(err error, code string) bool {
+	serr, ok := AsError(err)
+	return ok && serr.Code == code
+}
+
+// Rejected reports whether err means the supplier definitely did not accept
+// the transaction. Timeouts and unknown errors are not rejections: the
+// transaction may still go through and be reported by webhook.
+func Rejected(err error) bool {
+	switch CodeOf(err) {
+	case CodeOutOfStock, CodeUnavailable, CodeInsufficientDeposit, CodeInvalidRequest, CodeNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// transportCode classifies a transport error. Only a failure to connect
+// means the request never reached the supplier; a reset or timeout after
+// that leaves the transaction's fate unknown.
+func transportCode(err error) string {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return CodeUnavailable
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return CodeUnavailable
+	}
+	return CodeTimeout
+}
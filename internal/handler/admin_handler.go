@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -66,6 +67,10 @@ type approvalRejectRequest struct {
 	Reason string `json:"reason" binding:"required"`
 }
 
+type approvalCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
 type upsertSettingRequest struct {
 	Key         string      `json:"key" binding:"required"`
 	Description string      `json:"description"`
@@ -460,22 +465,94 @@ func (h *AdminHandler) GetCatalog(c *gin.Context) {
 	respondWithSuccess(c, http.StatusOK, resp)
 }
 
+func (h *AdminHandler) ListMaintenanceWindows(c *gin.Context) {
+	windows, err := h.adminService.ListMaintenanceWindows(c.Request.Context(), c.Query("includeEnded") == "true")
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"windows": windows})
+}
+
+func (h *AdminHandler) CreateMaintenanceWindow(c *gin.Context) {
+	var req service.MaintenanceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request maintenance tidak valid"))
+		return
+	}
+	window, err := h.adminService.CreateMaintenanceWindow(c.Request.Context(), middleware.GetAdminID(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, window)
+}
+
+func (h *AdminHandler) UpdateMaintenanceWindow(c *gin.Context) {
+	var req service.MaintenanceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request maintenance tidak valid"))
+		return
+	}
+	window, err := h.adminService.UpdateMaintenanceWindow(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, window)
+}
+
+func (h *AdminHandler) EndMaintenanceWindow(c *gin.Context) {
+	window, err := h.adminService.EndMaintenanceWindow(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, window)
+}
+
+func (h *AdminHandler) ListPricingRules(c *gin.Context) {
+	rules, err := h.adminService.ListPricingRules(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"rules": rules})
+}
+
+func (h *AdminHandler) PreviewPricingChange(c *gin.Context) {
+	var payload service.PricingChangeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request pricing tidak valid"))
+		return
+	}
+	resp, err := h.adminService.PreviewPricingChange(c.Request.Context(), payload)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) PreviewPricingRequest(c *gin.Context) {
+	resp, err := h.adminService.PreviewPricingRequest(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
 func (h *AdminHandler) CreatePricingRequest(c *gin.Context) {
 	var payload struct {
-		ProductID   string `json:"productId" binding:"required"`
-		NewPrice    int64  `json:"newPrice" binding:"required"`
-		NewAdminFee int64  `json:"newAdminFee"`
-		Reason      string `json:"reason" binding:"required"`
+		service.PricingChangeRequest
+		Reason string `json:"reason" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		respondWithError(c, domain.ErrValidationFailed("Body request pricing tidak valid"))
 		return
 	}
-	err := h.adminService.CreatePricingRequest(c.Request.Context(), middleware.GetAdminID(c), map[string]interface{}{
-		"productId":   payload.ProductID,
-		"newPrice":    payload.NewPrice,
-		"newAdminFee": payload.NewAdminFee,
-	}, payload.Reason)
+	err := h.adminService.CreatePricingRequest(c.Request.Context(), middleware.GetAdminID(c), payload.PricingChangeRequest, payload.Reason)
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -507,7 +584,7 @@ func (h *AdminHandler) CreateBalanceAdjustmentRequest(c *gin.Context) {
 }
 
 func (h *AdminHandler) ListKYC(c *gin.Context) {
-	resp, err := h.adminService.ListKYC(c.Request.Context(), c.Query("search"), c.Query("status"), queryInt(c, "page", 1), queryInt(c, "perPage", 20))
+	resp, err := h.adminService.ListKYC(c.Request.Context(), c.Query("search"), c.Query("status"), c.Query("flagged") == "true", queryInt(c, "page", 1), queryInt(c, "perPage", 20))
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -533,13 +610,77 @@ func (h *AdminHandler) ApproveKYC(c *gin.Context) {
 }
 
 func (h *AdminHandler) RejectKYC(c *gin.Context) {
-	if err := h.adminService.RejectKYC(c.Request.Context(), middleware.GetAdminID(c), c.Param("userId")); err != nil {
+	var req service.RejectKYCRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Alasan penolakan KYC tidak valid"))
+		return
+	}
+	if err := h.adminService.RejectKYC(c.Request.Context(), middleware.GetAdminID(c), c.Param("userId"), req); err != nil {
 		handleServiceError(c, err)
 		return
 	}
 	respondWithSuccess(c, http.StatusOK, gin.H{"message": "KYC berhasil di-reject"})
 }
 
+// ViewKYCArtefact handles POST /v1/admin/kyc/:userId/artefacts/:artefact/view
+func (h *AdminHandler) ViewKYCArtefact(c *gin.Context) {
+	link, err := h.adminService.ViewKYCArtefact(c.Request.Context(), middleware.GetAdminID(c), c.Param("userId"), c.Param("artefact"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, link)
+}
+
+func (h *AdminHandler) ListKYB(c *gin.Context) {
+	resp, err := h.adminService.ListKYB(c.Request.Context(), c.Query("search"), c.Query("status"), queryInt(c, "page", 1), queryInt(c, "perPage", 20))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) GetKYBDetail(c *gin.Context) {
+	resp, err := h.adminService.GetKYBDetail(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+// ViewKYBArtefact handles POST /v1/admin/kyb/:userId/artefacts/:artefact/view
+func (h *AdminHandler) ViewKYBArtefact(c *gin.Context) {
+	link, err := h.adminService.ViewKYBArtefact(c.Request.Context(), middleware.GetAdminID(c), c.Param("userId"), c.Param("artefact"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, link)
+}
+
+func (h *AdminHandler) ApproveKYB(c *gin.Context) {
+	if err := h.adminService.ApproveKYB(c.Request.Context(), middleware.GetAdminID(c), c.Param("userId")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "KYB berhasil di-approve"})
+}
+
+func (h *AdminHandler) RejectKYB(c *gin.Context) {
+	var req service.RejectKYBRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Alasan penolakan KYB tidak valid"))
+		return
+	}
+	if err := h.adminService.RejectKYB(c.Request.Context(), middleware.GetAdminID(c), c.Param("userId"), req); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "KYB berhasil di-reject"})
+}
+
 func (h *AdminHandler) ListBanners(c *gin.Context) {
 	resp, err := h.adminService.ListBanners(c.Request.Context())
 	if err != nil {
@@ -615,11 +756,21 @@ func (h *AdminHandler) ListApprovals(c *gin.Context) {
 }
 
 func (h *AdminHandler) ApproveApproval(c *gin.Context) {
-	if err := h.adminService.ApproveApproval(c.Request.Context(), middleware.GetAdminID(c), c.Param("id")); err != nil {
+	req, err := h.adminService.ApproveApproval(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"))
+	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
-	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Approval berhasil diproses"})
+	message := "Approval berhasil diproses"
+	if req.Status == domain.ApprovalStatusPending {
+		message = fmt.Sprintf("Approval dicatat (%d/%d), menunggu approver lain", req.Approvals, req.RequiredApprovals)
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{
+		"message":           message,
+		"status":            req.Status,
+		"approvals":         req.Approvals,
+		"requiredApprovals": req.RequiredApprovals,
+	})
 }
 
 func (h *AdminHandler) RejectApproval(c *gin.Context) {
@@ -635,6 +786,96 @@ func (h *AdminHandler) RejectApproval(c *gin.Context) {
 	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Approval berhasil ditolak"})
 }
 
+func (h *AdminHandler) CancelApproval(c *gin.Context) {
+	var req approvalCancelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, domain.ErrValidationFailed("Body request batal approval tidak valid"))
+			return
+		}
+	}
+	if err := h.adminService.CancelApproval(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req.Reason); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Approval berhasil dibatalkan"})
+}
+
+func (h *AdminHandler) ListApprovalPolicies(c *gin.Context) {
+	resp, err := h.adminService.ListApprovalPolicies(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) UpsertApprovalPolicy(c *gin.Context) {
+	var req service.ApprovalPolicyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request approval policy tidak valid"))
+		return
+	}
+	policy, err := h.adminService.UpsertApprovalPolicy(c.Request.Context(), middleware.GetAdminID(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, policy)
+}
+
+// scheduledChangePermissions maps a scheduled change resource to the permission that manages it
+var scheduledChangePermissions = map[string]string{
+	domain.ScheduledResourceService: "catalog.manage",
+	domain.ScheduledResourceBanner:  "banners.manage",
+	domain.ScheduledResourceVoucher: "vouchers.manage",
+}
+
+func hasAdminPermission(c *gin.Context, permission string) bool {
+	for _, owned := range middleware.GetAdminPermissions(c) {
+		if owned == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *AdminHandler) ListScheduledChanges(c *gin.Context) {
+	resp, err := h.adminService.ListScheduledChanges(c.Request.Context(), c.Query("resourceType"), c.Query("status"), queryInt(c, "page", 1), queryInt(c, "perPage", 20))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) ScheduleChange(c *gin.Context) {
+	var req service.ScheduleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request perubahan terjadwal tidak valid"))
+		return
+	}
+	// The route admits any of the manage permissions; the change needs the one of its resource
+	if permission, ok := scheduledChangePermissions[req.ResourceType]; ok && !hasAdminPermission(c, permission) {
+		respondWithError(c, domain.NewError("ADMIN_FORBIDDEN", "Anda tidak memiliki akses ke fitur ini", 403))
+		return
+	}
+	change, err := h.adminService.ScheduleChange(c.Request.Context(), middleware.GetAdminID(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, change)
+}
+
+func (h *AdminHandler) CancelScheduledChange(c *gin.Context) {
+	if err := h.adminService.CancelScheduledChange(c.Request.Context(), middleware.GetAdminID(c), c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Perubahan terjadwal berhasil dibatalkan"})
+}
+
 func (h *AdminHandler) ListAuditLogs(c *gin.Context) {
 	resp, err := h.adminService.ListAuditLogs(c.Request.Context(), queryInt(c, "page", 1), queryInt(c, "perPage", 20))
 	if err != nil {
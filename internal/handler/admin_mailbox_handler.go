@@ -76,6 +76,24 @@ func (h *AdminMailboxHandler) ListMailboxThreads(c *gin.Context) {
 	respondWithSuccess(c, http.StatusOK, resp)
 }
 
+// SearchMailbox searches message bodies, senders, subjects and attachment
+// text. q supports from:, has:attachment, before:, after: and status:.
+func (h *AdminMailboxHandler) SearchMailbox(c *gin.Context) {
+	resp, err := h.mailboxService.SearchMailbox(
+		c.Request.Context(),
+		middleware.GetAdminID(c),
+		c.Query("q"),
+		c.Query("mailboxId"),
+		queryInt(c, "page", 1),
+		queryInt(c, "perPage", 20),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
 func (h *AdminMailboxHandler) GetThreadDetail(c *gin.Context) {
 	resp, err := h.mailboxService.GetThreadDetail(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"))
 	if err != nil {
@@ -288,3 +306,120 @@ func (h *AdminMailboxHandler) DeliverySNS(c *gin.Context) {
 	}
 	respondWithSuccess(c, http.StatusOK, resp)
 }
+
+func (h *AdminMailboxHandler) ListMailboxTemplates(c *gin.Context) {
+	resp, err := h.mailboxService.ListMailboxTemplates(c.Request.Context(), middleware.GetAdminID(c), c.Query("mailboxId"), c.Query("search"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminMailboxHandler) CreateMailboxTemplate(c *gin.Context) {
+	var req service.MailboxTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request template tidak valid"))
+		return
+	}
+	resp, err := h.mailboxService.CreateMailboxTemplate(c.Request.Context(), middleware.GetAdminID(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminMailboxHandler) UpdateMailboxTemplate(c *gin.Context) {
+	var req service.MailboxTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request template tidak valid"))
+		return
+	}
+	resp, err := h.mailboxService.UpdateMailboxTemplate(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminMailboxHandler) DeleteMailboxTemplate(c *gin.Context) {
+	if err := h.mailboxService.DeleteMailboxTemplate(c.Request.Context(), middleware.GetAdminID(c), c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Template balasan berhasil dihapus"})
+}
+
+func (h *AdminMailboxHandler) RenderMailboxTemplate(c *gin.Context) {
+	var req service.MailboxTemplateRenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request template tidak valid"))
+		return
+	}
+	resp, err := h.mailboxService.RenderMailboxTemplate(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminMailboxHandler) LinkThreadCustomer(c *gin.Context) {
+	var req service.ThreadCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request customer tidak valid"))
+		return
+	}
+	if err := h.mailboxService.LinkThreadCustomer(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Customer thread berhasil diperbarui"})
+}
+
+func (h *AdminMailboxHandler) AddThreadLink(c *gin.Context) {
+	var req service.ThreadLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request tautan tidak valid"))
+		return
+	}
+	resp, err := h.mailboxService.AddThreadLink(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminMailboxHandler) RemoveThreadLink(c *gin.Context) {
+	if err := h.mailboxService.RemoveThreadLink(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), c.Param("linkId")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Tautan berhasil dilepas"})
+}
+
+func (h *AdminMailboxHandler) GetMailboxSLAPolicy(c *gin.Context) {
+	resp, err := h.mailboxService.GetMailboxSLAPolicy(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+func (h *AdminMailboxHandler) UpdateMailboxSLAPolicy(c *gin.Context) {
+	var req service.MailboxSLAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request SLA tidak valid"))
+		return
+	}
+	resp, err := h.mailboxService.UpdateMailboxSLAPolicy(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
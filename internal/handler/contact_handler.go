@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -60,6 +62,31 @@ func (h *ContactHandler) List(c *gin.Context) {
 	respondWithSuccess(c, http.StatusOK, response)
 }
 
+// Suggestions handles GET /v1/contacts/suggestions
+func (h *ContactHandler) Suggestions(c *gin.Context) {
+	// Get user ID from JWT
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		respondWithError(c, domain.ErrUnauthorizedError)
+		return
+	}
+
+	serviceType := c.Query("serviceType")
+	if domain.ContactTypeForService(serviceType) == "" {
+		respondWithError(c, domain.ErrValidationFailed("serviceType tidak valid"))
+		return
+	}
+
+	// Call service
+	response, err := h.contactService.Suggest(c.Request.Context(), userID, serviceType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response)
+}
+
 // CreateContactRequest represents create contact request body
 type CreateContactRequest struct {
 	Name     string                 `json:"name" binding:"required,min=1,max=50"`
@@ -155,3 +182,94 @@ func (h *ContactHandler) Delete(c *gin.Context) {
 
 	respondWithSuccess(c, http.StatusOK, response)
 }
+
+// Import handles POST /v1/contacts/import
+func (h *ContactHandler) Import(c *gin.Context) {
+	// Get user ID from JWT
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		respondWithError(c, domain.ErrUnauthorizedError)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondWithError(c, domain.ErrValidationFailed("File kontak wajib diunggah"))
+		return
+	}
+	if fileHeader.Size > service.ContactImportMaxFileSize {
+		respondWithError(c, domain.ErrValidationFailed("Ukuran file kontak maksimal 5MB"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondWithError(c, domain.ErrValidationFailed("File kontak tidak valid"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, service.ContactImportMaxFileSize+1))
+	if err != nil {
+		respondWithError(c, domain.ErrValidationFailed("File kontak tidak valid"))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dryRun", c.DefaultQuery("dryRun", "false")))
+
+	// Call service
+	response, err := h.contactService.Import(c.Request.Context(), service.ContactImportRequest{
+		UserID:   userID,
+		FileName: fileHeader.Filename,
+		Format:   c.DefaultPostForm("format", c.Query("format")),
+		Data:     data,
+		DryRun:   dryRun,
+	})
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if response.JobID != nil && response.Status == domain.ContactImportProcessing {
+		status = http.StatusAccepted
+	}
+	respondWithSuccess(c, status, response)
+}
+
+// GetImportJob handles GET /v1/contacts/import/:jobId
+func (h *ContactHandler) GetImportJob(c *gin.Context) {
+	// Get user ID from JWT
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		respondWithError(c, domain.ErrUnauthorizedError)
+		return
+	}
+
+	response, err := h.contactService.GetImportJob(c.Request.Context(), userID, c.Param("jobId"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response)
+}
+
+// Export handles GET /v1/contacts/export
+func (h *ContactHandler) Export(c *gin.Context) {
+	// Get user ID from JWT
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		respondWithError(c, domain.ErrUnauthorizedError)
+		return
+	}
+
+	export, err := h.contactService.Export(c.Request.Context(), userID, c.DefaultQuery("format", "csv"), c.Query("type"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName))
+	c.Data(http.StatusOK, export.ContentType, export.Data)
+}
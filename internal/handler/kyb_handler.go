@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/middleware"
+	"github.com/GTDGit/PPOB_BE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// KYBHandler handles merchant business verification HTTP requests
+type KYBHandler struct {
+	service *service.KYBService
+}
+
+// NewKYBHandler creates a new KYB handler
+func NewKYBHandler(service *service.KYBService) *KYBHandler {
+	return &KYBHandler{service: service}
+}
+
+// GetStatus handles GET /v1/kyb/status
+func (h *KYBHandler) GetStatus(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	response, err := h.service.GetStatus(c.Request.Context(), userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response)
+}
+
+// UploadDocument handles POST /v1/kyb/documents
+// Form fields: type (license, npwp, storefront) and file
+func (h *KYBHandler) UploadDocument(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	documentType := c.PostForm("type")
+	if documentType == "" {
+		respondWithError(c, domain.ErrValidationFailed("Jenis dokumen wajib diisi"))
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		respondWithError(c, domain.ErrValidationFailed("File dokumen wajib diupload"))
+		return
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		respondWithError(c, domain.ErrInvalidRequestError)
+		return
+	}
+	defer fileContent.Close()
+
+	fileBytes, err := io.ReadAll(fileContent)
+	if err != nil {
+		respondWithError(c, domain.ErrInvalidRequestError)
+		return
+	}
+
+	submission, err := h.service.UploadDocument(c.Request.Context(), userID, documentType, fileBytes, file.Filename)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, gin.H{
+		"message":          "Dokumen usaha berhasil diupload",
+		"missingDocuments": submission.MissingDocuments(),
+		"submission":       submission,
+	})
+}
+
+// Submit handles POST /v1/kyb/submit
+func (h *KYBHandler) Submit(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req service.KYBSubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Data usaha tidak lengkap"))
+		return
+	}
+
+	submission, err := h.service.Submit(c.Request.Context(), userID, req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, gin.H{
+		"message":    "Verifikasi usaha berhasil disubmit",
+		"submission": submission,
+	})
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/GTDGit/PPOB_BE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// KYCArtefactHandler serves KYC and KYB files through signed links
+type KYCArtefactHandler struct {
+	service *service.KYCArtefactService
+}
+
+// NewKYCArtefactHandler creates a new KYC artefact handler
+func NewKYCArtefactHandler(service *service.KYCArtefactService) *KYCArtefactHandler {
+	return &KYCArtefactHandler{service: service}
+}
+
+// Open handles GET /v1/kyc/artefacts/:token
+// The token is the only credential, so the file must never be cached
+func (h *KYCArtefactHandler) Open(c *gin.Context) {
+	data, contentType, err := h.service.Open(c.Request.Context(), c.Param("token"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store, private")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, contentType, data)
+}
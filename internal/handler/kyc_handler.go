@@ -24,16 +24,28 @@ func NewKYCHandler(service *service.KYCService) *KYCHandler {
 func (h *KYCHandler) GetStatus(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
-	verification, err := h.service.GetStatus(c.Request.Context(), userID)
+	info, err := h.service.GetStatus(c.Request.Context(), userID)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	if verification == nil {
+	if info.Rejection != nil {
+		respondWithSuccess(c, http.StatusOK, gin.H{
+			"message":   "Verifikasi KYC ditolak",
+			"verified":  false,
+			"status":    info.Status,
+			"data":      info.Verification,
+			"rejection": info.Rejection,
+		})
+		return
+	}
+
+	if info.Verification == nil {
 		respondWithSuccess(c, http.StatusOK, gin.H{
 			"message":  "Belum ada verifikasi KYC",
 			"verified": false,
+			"status":   info.Status,
 			"data":     nil,
 		})
 		return
@@ -41,8 +53,9 @@ func (h *KYCHandler) GetStatus(c *gin.Context) {
 
 	respondWithSuccess(c, http.StatusOK, gin.H{
 		"message":  "Berhasil mengambil status KYC",
-		"verified": true,
-		"data":     verification,
+		"verified": info.Status == domain.KYCStatusVerified,
+		"status":   info.Status,
+		"data":     info.Verification,
 	})
 }
 
@@ -86,6 +99,22 @@ func (h *KYCHandler) StartVerification(c *gin.Context) {
 	})
 }
 
+// Resubmit handles POST /v1/kyc/resubmit
+func (h *KYCHandler) Resubmit(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	session, err := h.service.Resubmit(c.Request.Context(), userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, gin.H{
+		"message": "Sesi pengiriman ulang KYC berhasil dibuat",
+		"data":    session,
+	})
+}
+
 // CancelVerification handles POST /v1/kyc/cancel
 func (h *KYCHandler) CancelVerification(c *gin.Context) {
 	userID := middleware.GetUserID(c)
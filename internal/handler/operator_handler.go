@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/middleware"
+	"github.com/GTDGit/PPOB_BE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OperatorHandler handles admin management of mobile operators and prefixes
+type OperatorHandler struct {
+	operatorService *service.OperatorService
+}
+
+// NewOperatorHandler creates a new operator handler
+func NewOperatorHandler(operatorService *service.OperatorService) *OperatorHandler {
+	return &OperatorHandler{operatorService: operatorService}
+}
+
+// ListOperators handles GET /v1/admin/operators
+func (h *OperatorHandler) ListOperators(c *gin.Context) {
+	items, err := h.operatorService.List(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"items": items})
+}
+
+// DetectOperator handles GET /v1/admin/operators/detect?phone=
+func (h *OperatorHandler) DetectOperator(c *gin.Context) {
+	phone := c.Query("phone")
+	if phone == "" {
+		respondWithError(c, domain.ErrValidationFailed("Nomor telepon wajib diisi"))
+		return
+	}
+	detection, err := h.operatorService.Detect(c.Request.Context(), phone)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, detection)
+}
+
+// CreateOperator handles POST /v1/admin/operators
+func (h *OperatorHandler) CreateOperator(c *gin.Context) {
+	var req service.OperatorInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request tidak valid"))
+		return
+	}
+	operator, err := h.operatorService.Create(c.Request.Context(), middleware.GetAdminID(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusCreated, operator)
+}
+
+// UpdateOperator handles PUT /v1/admin/operators/:id
+func (h *OperatorHandler) UpdateOperator(c *gin.Context) {
+	var req service.OperatorInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request tidak valid"))
+		return
+	}
+	operator, err := h.operatorService.Update(c.Request.Context(), middleware.GetAdminID(c), c.Param("id"), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, operator)
+}
+
+// DeleteOperator handles DELETE /v1/admin/operators/:id
+func (h *OperatorHandler) DeleteOperator(c *gin.Context) {
+	if err := h.operatorService.Delete(c.Request.Context(), middleware.GetAdminID(c), c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Operator berhasil dihapus"})
+}
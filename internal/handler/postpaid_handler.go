@@ -93,6 +93,11 @@ func (h *PostpaidHandler) Pay(c *gin.Context) {
 		return
 	}
 
+	var saveContact *service.ContactSaveRequest
+	if req.Contact != nil && req.Contact.SaveAsContact {
+		saveContact = &service.ContactSaveRequest{Name: req.Contact.ContactName}
+	}
+
 	// Call service
 	response, err := h.postpaidService.Pay(
 		c.Request.Context(),
@@ -100,17 +105,13 @@ func (h *PostpaidHandler) Pay(c *gin.Context) {
 		req.InquiryID,
 		req.VoucherCodes,
 		req.PIN,
+		saveContact,
 	)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	// TODO: Save contact if requested
-	// if req.Contact != nil && req.Contact.SaveAsContact {
-	//     go h.saveContact(userID.(string), req.Contact, response)
-	// }
-
 	respondWithSuccess(c, http.StatusOK, response)
 }
 
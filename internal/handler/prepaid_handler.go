@@ -31,6 +31,7 @@ type InquiryRequest struct {
 	ServiceType string  `json:"serviceType" binding:"required,oneof=pulsa data pln_prepaid ewallet game"`
 	Target      string  `json:"target" binding:"required"`
 	ProviderID  *string `json:"providerId"`
+	OperatorID  *string `json:"operatorId"` // Overrides prefix detection for pulsa/data
 }
 
 // Inquiry handles POST /v1/prepaid/inquiry
@@ -54,6 +55,7 @@ func (h *PrepaidHandler) Inquiry(c *gin.Context) {
 		ServiceType: req.ServiceType,
 		Target:      req.Target,
 		ProviderID:  req.ProviderID,
+		OperatorID:  req.OperatorID,
 	})
 
 	if err != nil {
@@ -94,12 +96,17 @@ func (h *PrepaidHandler) CreateOrder(c *gin.Context) {
 	}
 
 	// Call service
-	resp, err := h.prepaidService.CreateOrder(c.Request.Context(), service.CreateOrderRequest{
+	orderReq := service.CreateOrderRequest{
 		UserID:       userID,
 		InquiryID:    req.InquiryID,
 		ProductID:    req.ProductID,
 		VoucherCodes: req.VoucherCodes,
-	})
+	}
+	if req.Contact != nil && req.Contact.SaveAsContact {
+		orderReq.Contact = &service.ContactSaveRequest{Name: req.Contact.ContactName}
+	}
+
+	resp, err := h.prepaidService.CreateOrder(c.Request.Context(), orderReq)
 
 	if err != nil {
 		handleServiceError(c, err)
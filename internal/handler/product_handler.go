@@ -114,3 +114,24 @@ func (h *ProductHandler) GetTVProviders(c *gin.Context) {
 
 	respondWithSuccess(c, http.StatusOK, response)
 }
+
+// GetProductHistory handles GET /v1/admin/catalog/products/:sku/history
+func (h *ProductHandler) GetProductHistory(c *gin.Context) {
+	resp, err := h.productService.GetProductHistory(c.Request.Context(), c.Param("sku"), queryInt(c, "page", 1), queryInt(c, "perPage", 20))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
+
+// ListProductChanges handles GET /v1/admin/catalog/product-changes
+func (h *ProductHandler) ListProductChanges(c *gin.Context) {
+	alertsOnly := c.Query("alertsOnly") == "true"
+	resp, err := h.productService.ListProductChanges(c.Request.Context(), alertsOnly, queryInt(c, "page", 1), queryInt(c, "perPage", 20))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, resp)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderHandler exposes provider circuit breaker state to admins
+type ProviderHandler struct {
+	gerbangClient *gerbang.Client
+}
+
+// NewProviderHandler creates a new provider handler
+func NewProviderHandler(gerbangClient *gerbang.Client) *ProviderHandler {
+	return &ProviderHandler{gerbangClient: gerbangClient}
+}
+
+// GetHealth handles GET /v1/admin/providers/health
+func (h *ProviderHandler) GetHealth(c *gin.Context) {
+	respondWithSuccess(c, http.StatusOK, gin.H{"gerbang": h.gerbangClient.Health()})
+}
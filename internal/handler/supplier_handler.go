@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/middleware"
+	"github.com/GTDGit/PPOB_BE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler handles admin management of suppliers and SKU routing
+type SupplierHandler struct {
+	supplierService *service.SupplierService
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(supplierService *service.SupplierService) *SupplierHandler {
+	return &SupplierHandler{supplierService: supplierService}
+}
+
+// ListSuppliers handles GET /v1/admin/catalog/suppliers
+func (h *SupplierHandler) ListSuppliers(c *gin.Context) {
+	items, err := h.supplierService.ListSuppliers(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"items": items})
+}
+
+// GetRouting handles GET /v1/admin/catalog/products/:sku/suppliers
+func (h *SupplierHandler) GetRouting(c *gin.Context) {
+	routing, err := h.supplierService.GetRouting(c.Request.Context(), c.Param("sku"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, routing)
+}
+
+// UpsertRoute handles PUT /v1/admin/catalog/supplier-routes
+func (h *SupplierHandler) UpsertRoute(c *gin.Context) {
+	var req service.SupplierRouteInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, domain.ErrValidationFailed("Body request tidak valid"))
+		return
+	}
+	route, err := h.supplierService.UpsertRoute(c.Request.Context(), middleware.GetAdminID(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, route)
+}
+
+// DeleteRoute handles DELETE /v1/admin/catalog/supplier-routes/:id
+func (h *SupplierHandler) DeleteRoute(c *gin.Context) {
+	if err := h.supplierService.DeleteRoute(c.Request.Context(), middleware.GetAdminID(c), c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	respondWithSuccess(c, http.StatusOK, gin.H{"message": "Rute supplier berhasil dihapus"})
+}
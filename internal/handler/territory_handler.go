@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/GTDGit/PPOB_BE/internal/service"
@@ -133,3 +134,24 @@ func (h *TerritoryHandler) SearchByPostalCode(c *gin.Context) {
 		},
 	})
 }
+
+// Search handles GET /v1/territory/search?q=&limit=
+func (h *TerritoryHandler) Search(c *gin.Context) {
+	query := c.Query("q")
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	results, err := h.service.Search(c.Request.Context(), query, limit)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, gin.H{
+		"message": "Berhasil mencari data wilayah",
+		"data":    results,
+		"meta": gin.H{
+			"total": len(results),
+			"query": query,
+		},
+	})
+}
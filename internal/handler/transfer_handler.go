@@ -31,6 +31,7 @@ type TransferInquiryRequest struct {
 	BankCode      string `json:"bankCode" binding:"required"`
 	AccountNumber string `json:"accountNumber" binding:"required"`
 	Amount        int64  `json:"amount" binding:"required,min=10000"`
+	Method        string `json:"method"` // Optional: bifast, online, rtgs; cheapest when empty
 }
 
 // Inquiry handles POST /v1/transfer/inquiry
@@ -54,6 +55,7 @@ func (h *TransferHandler) Inquiry(c *gin.Context) {
 		BankCode:      req.BankCode,
 		AccountNumber: req.AccountNumber,
 		Amount:        req.Amount,
+		Method:        req.Method,
 	})
 
 	if err != nil {
@@ -89,13 +91,18 @@ func (h *TransferHandler) Execute(c *gin.Context) {
 	}
 
 	// Call service
-	resp, err := h.transferService.Execute(c.Request.Context(), service.TransferExecuteRequest{
+	executeReq := service.TransferExecuteRequest{
 		UserID:    userID,
 		InquiryID: req.InquiryID,
 		Purpose:   req.Purpose,
 		Note:      req.Note,
 		PIN:       req.PIN,
-	})
+	}
+	if req.Contact != nil && req.Contact.SaveAsContact {
+		executeReq.Contact = &service.ContactSaveRequest{Name: req.Contact.ContactName}
+	}
+
+	resp, err := h.transferService.Execute(c.Request.Context(), executeReq)
 
 	if err != nil {
 		handleServiceError(c, err)
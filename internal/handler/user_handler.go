@@ -11,13 +11,15 @@ import (
 
 // UserHandler handles user profile and settings requests
 type UserHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	limitService *service.AccountLimitService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, limitService *service.AccountLimitService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:  userService,
+		limitService: limitService,
 	}
 }
 
@@ -204,3 +206,21 @@ func (h *UserHandler) GetReferralHistory(c *gin.Context) {
 
 	respondWithSuccess(c, http.StatusOK, response)
 }
+
+// GetLimits handles GET /v1/user/limits
+// Returns the account limits of the user's KYC level and tier with the remaining headroom
+func (h *UserHandler) GetLimits(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		respondWithError(c, domain.ErrUnauthorizedError)
+		return
+	}
+
+	response, err := h.limitService.GetLimits(c.Request.Context(), userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response)
+}
@@ -0,0 +1,63 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/service"
+)
+
+// ApprovalExpiryJob closes admin approval requests whose policy expiry passed
+// without enough approvals, so they drop out of the approval queue
+type ApprovalExpiryJob struct {
+	adminService *service.AdminService
+	logger       *slog.Logger
+	interval     time.Duration
+}
+
+// NewApprovalExpiryJob creates a new approval expiry job
+func NewApprovalExpiryJob(
+	adminService *service.AdminService,
+	logger *slog.Logger,
+	interval time.Duration,
+) *ApprovalExpiryJob {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	return &ApprovalExpiryJob{
+		adminService: adminService,
+		logger:       logger,
+		interval:     interval,
+	}
+}
+
+// Start runs the expiry every interval until ctx is done (call in main.go)
+func (j *ApprovalExpiryJob) Start(ctx context.Context) {
+	j.logger.Info("approval expiry job started", "interval", j.interval.String())
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("approval expiry job stopped")
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce expires the pending requests currently past their expiry
+func (j *ApprovalExpiryJob) RunOnce(ctx context.Context) {
+	expired, err := j.adminService.ExpireApprovals(ctx)
+	if err != nil {
+		j.logger.Error("approval expiry failed", "error", err)
+		return
+	}
+	if expired > 0 {
+		j.logger.Info("expired admin approval requests", slog.Int("count", expired))
+	}
+}
@@ -0,0 +1,68 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/service"
+)
+
+// KYCRetentionJob deletes KYC files of abandoned verification sessions and
+// of closed accounts past the regulatory retention period
+type KYCRetentionJob struct {
+	artefactService *service.KYCArtefactService
+	logger          *slog.Logger
+	interval        time.Duration
+}
+
+// NewKYCRetentionJob creates a new KYC retention job
+func NewKYCRetentionJob(
+	artefactService *service.KYCArtefactService,
+	logger *slog.Logger,
+	interval time.Duration,
+) *KYCRetentionJob {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return &KYCRetentionJob{
+		artefactService: artefactService,
+		logger:          logger,
+		interval:        interval,
+	}
+}
+
+// Start runs the purge once, then every interval until ctx is done (call in main.go)
+func (j *KYCRetentionJob) Start(ctx context.Context) {
+	j.logger.Info("kyc retention job started", "interval", j.interval.String())
+
+	j.RunOnce(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("kyc retention job stopped")
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce purges the artefacts currently past their retention
+func (j *KYCRetentionJob) RunOnce(ctx context.Context) {
+	result, err := j.artefactService.PurgeExpired(ctx)
+	if err != nil {
+		j.logger.Error("kyc retention purge failed", "error", err)
+	}
+	if result != nil && (result.AbandonedUsers > 0 || result.ExpiredUsers > 0 || result.ObjectsDeleted > 0) {
+		j.logger.Info("purged kyc artefacts",
+			slog.Int("abandoned_users", result.AbandonedUsers),
+			slog.Int("expired_users", result.ExpiredUsers),
+			slog.Int("objects", result.ObjectsDeleted),
+		)
+	}
+}
@@ -0,0 +1,63 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/service"
+)
+
+// MailboxSLAJob escalates support threads that missed their mailbox SLA,
+// reassigning or notifying per the mailbox policy
+type MailboxSLAJob struct {
+	mailboxService *service.AdminMailboxService
+	logger         *slog.Logger
+	interval       time.Duration
+}
+
+// NewMailboxSLAJob creates a new mailbox SLA job
+func NewMailboxSLAJob(
+	mailboxService *service.AdminMailboxService,
+	logger *slog.Logger,
+	interval time.Duration,
+) *MailboxSLAJob {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &MailboxSLAJob{
+		mailboxService: mailboxService,
+		logger:         logger,
+		interval:       interval,
+	}
+}
+
+// Start runs the escalation every interval until ctx is done (call in main.go)
+func (j *MailboxSLAJob) Start(ctx context.Context) {
+	j.logger.Info("mailbox sla job started", "interval", j.interval.String())
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("mailbox sla job stopped")
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce escalates the threads currently past an SLA target
+func (j *MailboxSLAJob) RunOnce(ctx context.Context) {
+	escalated, err := j.mailboxService.EscalateSLABreaches(ctx)
+	if err != nil {
+		j.logger.Error("mailbox sla escalation failed", "error", err)
+		return
+	}
+	if escalated > 0 {
+		j.logger.Info("escalated mailbox sla breaches", slog.Int("count", escalated))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,11 +15,26 @@ import (
 	"github.com/GTDGit/PPOB_BE/pkg/redis"
 )
 
+// ProductChangeAlerter delivers alerts about product changes to admins
+type ProductChangeAlerter interface {
+	SendProductChangeAlert(ctx context.Context, email string, changes []*domain.ProductChange) error
+}
+
+// ProductAlertConfig holds the thresholds that turn a product change into an alert
+type ProductAlertConfig struct {
+	PriceChangePercent int    // Supplier price movement, in percent, that raises an alert (0 disables)
+	StatusChange       bool   // Alert when a product is activated or deactivated
+	Recipient          string // Admin mailbox address; alerts are only recorded when empty
+}
+
 // ProductSyncJob handles product synchronization from Gerbang API
 type ProductSyncJob struct {
 	gerbangClient *gerbang.Client
 	productRepo   repository.ProductRepository
+	pricingRepo   repository.PricingRuleRepository
 	redisClient   *redis.Client
+	alerter       ProductChangeAlerter
+	alerts        ProductAlertConfig
 	logger        *slog.Logger
 	interval      time.Duration
 }
@@ -26,14 +43,20 @@ type ProductSyncJob struct {
 func NewProductSyncJob(
 	gerbangClient *gerbang.Client,
 	productRepo repository.ProductRepository,
+	pricingRepo repository.PricingRuleRepository,
 	redisClient *redis.Client,
+	alerter ProductChangeAlerter,
+	alerts ProductAlertConfig,
 	logger *slog.Logger,
 	interval time.Duration,
 ) *ProductSyncJob {
 	return &ProductSyncJob{
 		gerbangClient: gerbangClient,
 		productRepo:   productRepo,
+		pricingRepo:   pricingRepo,
 		redisClient:   redisClient,
+		alerter:       alerter,
+		alerts:        alerts,
 		logger:        logger,
 		interval:      interval,
 	}
@@ -111,8 +134,26 @@ func (j *ProductSyncJob) RunOnce(ctx context.Context) error {
 		return nil
 	}
 
+	// Selling prices are derived from supplier cost, so approved markups
+	// survive every sync
+	rules, err := j.pricingRepo.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+
+	// Stored rows to diff against, keyed by SKU
+	stored, err := j.productRepo.FindAllForPricing(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load stored products: %w", err)
+	}
+	storedBySKU := make(map[string]*domain.Product, len(stored))
+	for _, product := range stored {
+		storedBySKU[product.SKUCode] = product
+	}
+
 	// Convert to domain products
 	var domainProducts []*domain.Product
+	var changes, alerts []*domain.ProductChange
 	for _, p := range allProducts {
 		// Parse GTD updated_at timestamp
 		gtdUpdatedAt := time.Now()
@@ -122,20 +163,38 @@ func (j *ProductSyncJob) RunOnce(ctx context.Context) error {
 			}
 		}
 
-		domainProducts = append(domainProducts, &domain.Product{
-			ID:           uuid.New().String(),
-			SKUCode:      p.SKUCode,
-			Name:         p.Name,
-			Category:     p.Category,
-			Brand:        p.Brand,
-			Type:         p.Type,
-			Price:        p.Price,
-			Admin:        p.Admin,
-			Commission:   p.Commission,
-			IsActive:     p.IsActive,
-			Description:  p.Description,
-			GTDUpdatedAt: gtdUpdatedAt,
-		})
+		product := &domain.Product{
+			ID:            uuid.New().String(),
+			SKUCode:       p.SKUCode,
+			Name:          p.Name,
+			Category:      p.Category,
+			Brand:         p.Brand,
+			Type:          p.Type,
+			SupplierPrice: p.Price,
+			SupplierAdmin: p.Admin,
+			Commission:    p.Commission,
+			IsActive:      p.IsActive,
+			Description:   p.Description,
+			GTDUpdatedAt:  gtdUpdatedAt,
+		}
+		quote := domain.QuotePrice(rules, product, "")
+		product.Price = quote.Price
+		product.Admin = quote.Admin
+
+		existing := storedBySKU[p.SKUCode]
+		if existing != nil {
+			// Keep the ID stable so orders keep pointing at the same product
+			product.ID = existing.ID
+		}
+		if change := domain.NewProductChange(existing, product, domain.ProductChangeSourceSync); change != nil {
+			if reason := productChangeAlertReason(change, j.alerts); reason != "" {
+				change.Alerted = true
+				change.AlertReason = &reason
+				alerts = append(alerts, change)
+			}
+			changes = append(changes, change)
+		}
+		domainProducts = append(domainProducts, product)
 	}
 
 	// Bulk upsert to database
@@ -143,6 +202,16 @@ func (j *ProductSyncJob) RunOnce(ctx context.Context) error {
 		return fmt.Errorf("failed to upsert products: %w", err)
 	}
 
+	// History is best effort; a failed write must not undo a successful sync
+	if err := j.productRepo.CreateChanges(ctx, changes); err != nil {
+		j.logger.Error("failed to record product changes", "error", err, "count", len(changes))
+	}
+	if len(alerts) > 0 && j.alerts.Recipient != "" && j.alerter != nil {
+		if err := j.alerter.SendProductChangeAlert(ctx, j.alerts.Recipient, alerts); err != nil {
+			j.logger.Error("failed to send product change alert", "error", err, "count", len(alerts))
+		}
+	}
+
 	// Invalidate all product caches
 	keys, err := j.redisClient.Keys(ctx, "products:*").Result()
 	if err == nil && len(keys) > 0 {
@@ -156,12 +225,39 @@ func (j *ProductSyncJob) RunOnce(ctx context.Context) error {
 	duration := time.Since(startTime)
 	j.logger.Info("product sync completed",
 		"products_synced", len(domainProducts),
+		"changes", len(changes),
+		"alerts", len(alerts),
 		"duration", duration.String(),
 	)
 
 	return nil
 }
 
+// productChangeAlertReason describes why a change crosses the alert thresholds,
+// or returns "" when it does not. New products never alert.
+func productChangeAlertReason(change *domain.ProductChange, cfg ProductAlertConfig) string {
+	if change.ChangeType != domain.ProductChangeUpdated {
+		return ""
+	}
+
+	var reasons []string
+	if cfg.StatusChange && change.OldIsActive != nil {
+		if change.NewIsActive {
+			reasons = append(reasons, "produk diaktifkan kembali")
+		} else {
+			reasons = append(reasons, "produk dinonaktifkan")
+		}
+	}
+	if cfg.PriceChangePercent > 0 && change.OldSupplierPrice != nil {
+		percent := change.SupplierPriceChangePercent()
+		if math.Abs(percent) >= float64(cfg.PriceChangePercent) {
+			reasons = append(reasons, fmt.Sprintf("harga modal berubah dari %d ke %d (%+.1f%%)",
+				*change.OldSupplierPrice, change.NewSupplierPrice, percent))
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
 // Stop gracefully stops the sync job
 func (j *ProductSyncJob) Stop() {
 	j.logger.Info("stopping product sync job")
@@ -0,0 +1,66 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/service"
+)
+
+// reservationRecoveryBatch is the maximum number of reservations resolved per run
+const reservationRecoveryBatch = 100
+
+// ReservationRecoveryJob resolves balance reservations left held by payments
+// that were interrupted between reserving funds and recording the provider result
+type ReservationRecoveryJob struct {
+	reservationService *service.ReservationService
+	logger             *slog.Logger
+	interval           time.Duration
+}
+
+// NewReservationRecoveryJob creates a new reservation recovery job
+func NewReservationRecoveryJob(
+	reservationService *service.ReservationService,
+	logger *slog.Logger,
+	interval time.Duration,
+) *ReservationRecoveryJob {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ReservationRecoveryJob{
+		reservationService: reservationService,
+		logger:             logger,
+		interval:           interval,
+	}
+}
+
+// Start runs the recovery every interval until ctx is done (call in main.go)
+func (j *ReservationRecoveryJob) Start(ctx context.Context) {
+	j.logger.Info("reservation recovery job started", "interval", j.interval.String())
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("reservation recovery job stopped")
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce resolves the currently expired reservations
+func (j *ReservationRecoveryJob) RunOnce(ctx context.Context) {
+	recovered, err := j.reservationService.RecoverExpired(ctx, reservationRecoveryBatch)
+	if err != nil {
+		j.logger.Error("reservation recovery failed", "error", err)
+		return
+	}
+	if recovered > 0 {
+		j.logger.Warn("recovered orphaned balance reservations", slog.Int("count", recovered))
+	}
+}
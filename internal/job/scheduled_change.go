@@ -0,0 +1,62 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/service"
+)
+
+// ScheduledChangeJob applies queued catalog changes at their effective time
+// and reverts them at their revert time
+type ScheduledChangeJob struct {
+	adminService *service.AdminService
+	logger       *slog.Logger
+	interval     time.Duration
+}
+
+// NewScheduledChangeJob creates a new scheduled change job
+func NewScheduledChangeJob(
+	adminService *service.AdminService,
+	logger *slog.Logger,
+	interval time.Duration,
+) *ScheduledChangeJob {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &ScheduledChangeJob{
+		adminService: adminService,
+		logger:       logger,
+		interval:     interval,
+	}
+}
+
+// Start runs due changes every interval until ctx is done (call in main.go)
+func (j *ScheduledChangeJob) Start(ctx context.Context) {
+	j.logger.Info("scheduled change job started", "interval", j.interval.String())
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("scheduled change job stopped")
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce applies and reverts the changes currently due
+func (j *ScheduledChangeJob) RunOnce(ctx context.Context) {
+	applied, reverted, err := j.adminService.RunScheduledChanges(ctx)
+	if err != nil {
+		j.logger.Error("scheduled change run failed", "error", err)
+	}
+	if applied > 0 || reverted > 0 {
+		j.logger.Info("scheduled changes processed", slog.Int("applied", applied), slog.Int("reverted", reverted))
+	}
+}
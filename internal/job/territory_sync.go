@@ -2,33 +2,49 @@ package job
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
 )
 
-// TerritorySyncJob handles territory data synchronization from Gerbang API
+// territoryCheckpointEvery is how many parents are synced between progress saves
+const territoryCheckpointEvery = 50
+
+// TerritorySyncJob handles territory data synchronization from Gerbang API.
+// Levels are synced top-down, each one walking the codes stored by the level
+// above, so a failed run can resume from the level (and parent) it stopped at.
 type TerritorySyncJob struct {
-	repo     repository.TerritoryRepository
-	logger   *slog.Logger
-	interval time.Duration
-	enabled  bool
+	gerbangClient *gerbang.Client
+	repo          repository.TerritoryRepository
+	logger        *slog.Logger
+	interval      time.Duration
+	enabled       bool
+	enableOnStart bool
 }
 
 // NewTerritorySyncJob creates a new territory sync job
 func NewTerritorySyncJob(
+	gerbangClient *gerbang.Client,
 	repo repository.TerritoryRepository,
 	logger *slog.Logger,
 	interval time.Duration,
 	enabled bool,
+	enableOnStart bool,
 ) *TerritorySyncJob {
 	return &TerritorySyncJob{
-		repo:     repo,
-		logger:   logger,
-		interval: interval,
-		enabled:  enabled,
+		gerbangClient: gerbangClient,
+		repo:          repo,
+		logger:        logger,
+		interval:      interval,
+		enabled:       enabled,
+		enableOnStart: enableOnStart,
 	}
 }
 
@@ -41,6 +57,13 @@ func (j *TerritorySyncJob) Start(ctx context.Context) {
 
 	j.logger.Info("territory sync job started", "interval", j.interval.String())
 
+	// Run immediately on startup if enabled
+	if j.enableOnStart {
+		if err := j.RunOnce(ctx); err != nil {
+			j.logger.Error("initial territory sync failed", slog.String("error", err.Error()))
+		}
+	}
+
 	ticker := time.NewTicker(j.interval)
 	defer ticker.Stop()
 
@@ -57,40 +80,420 @@ func (j *TerritorySyncJob) Start(ctx context.Context) {
 	}
 }
 
-// RunOnce runs sync once
+// RunOnce runs sync once. If the previous run did not finish, it is resumed
+// instead of starting over.
 func (j *TerritorySyncJob) RunOnce(ctx context.Context) error {
-	j.logger.Info("starting territory sync")
 	startTime := time.Now()
 
-	// Log sync start
+	runID, previous, err := j.resumeState(ctx)
+	if err != nil {
+		return err
+	}
+	if len(previous) > 0 {
+		j.logger.Info("resuming territory sync", slog.String("run_id", runID))
+	} else {
+		j.logger.Info("starting territory sync", slog.String("run_id", runID))
+	}
+
+	for _, level := range domain.TerritorySyncLevels {
+		prev := previous[level]
+		if prev != nil && prev.Status == domain.TerritorySyncStatusSuccess {
+			continue
+		}
+		if err := j.syncLevel(ctx, runID, level, prev); err != nil {
+			return fmt.Errorf("territory sync failed at %s: %w", level, err)
+		}
+	}
+
+	if err := j.repo.RefreshSearchIndex(ctx); err != nil {
+		j.logger.Warn("failed to refresh territory search index", slog.String("error", err.Error()))
+	}
+
+	j.logger.Info("territory sync completed",
+		slog.String("run_id", runID),
+		"duration", time.Since(startTime).String(),
+	)
+
+	return nil
+}
+
+// Stop gracefully stops the sync job
+func (j *TerritorySyncJob) Stop() {
+	j.logger.Info("stopping territory sync job")
+}
+
+// resumeState returns the run to continue with the latest log of each of its
+// levels, or a fresh run ID when the last run completed
+func (j *TerritorySyncJob) resumeState(ctx context.Context) (string, map[string]*domain.TerritorySyncLog, error) {
+	logs, err := j.repo.GetLatestSyncRun(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load last territory sync run: %w", err)
+	}
+
+	latest := make(map[string]*domain.TerritorySyncLog, len(logs))
+	for _, log := range logs {
+		latest[log.SyncType] = log
+	}
+
+	for _, level := range domain.TerritorySyncLevels {
+		if log := latest[level]; log == nil || log.Status != domain.TerritorySyncStatusSuccess {
+			if len(logs) > 0 && logs[0].RunID != nil {
+				return *logs[0].RunID, latest, nil
+			}
+			break
+		}
+	}
+
+	return uuid.New().String(), nil, nil
+}
+
+// syncLevel syncs one level and records its outcome. A previous unfinished
+// attempt's checkpoint and counts are carried over.
+func (j *TerritorySyncJob) syncLevel(ctx context.Context, runID, level string, prev *domain.TerritorySyncLog) error {
 	syncLog := &domain.TerritorySyncLog{
-		SyncType:     domain.TerritorySyncTypeProvinces,
-		TotalRecords: 0,
-		Status:       domain.TerritorySyncStatusRunning,
-		StartedAt:    startTime,
+		RunID:     &runID,
+		SyncType:  level,
+		Status:    domain.TerritorySyncStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if prev != nil {
+		syncLog.TotalRecords = prev.TotalRecords
+		syncLog.InsertedCount = prev.InsertedCount
+		syncLog.UpdatedCount = prev.UpdatedCount
+		syncLog.DeletedCount = prev.DeletedCount
+		syncLog.Checkpoint = prev.Checkpoint
+	}
+	if err := j.repo.LogSync(ctx, syncLog); err != nil {
+		return fmt.Errorf("failed to log sync: %w", err)
 	}
 
-	// TODO: Implement actual sync with Gerbang API
-	// For now, just log that sync would run here
+	var err error
+	if level == domain.TerritorySyncTypeProvinces {
+		err = j.syncProvinces(ctx, syncLog)
+	} else {
+		err = j.syncChildren(ctx, level, syncLog)
+	}
 
-	// Log sync completion
 	completedAt := time.Now()
 	syncLog.CompletedAt = &completedAt
 	syncLog.Status = domain.TerritorySyncStatusSuccess
+	if err != nil {
+		message := err.Error()
+		syncLog.Status = domain.TerritorySyncStatusFailed
+		syncLog.ErrorMessage = &message
+	}
 
-	if err := j.repo.LogSync(ctx, syncLog); err != nil {
-		j.logger.Warn("failed to log sync", slog.String("error", err.Error()))
+	// The run context may already be cancelled; the outcome must still be recorded
+	logCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if updateErr := j.repo.UpdateSyncLog(logCtx, syncLog); updateErr != nil {
+		j.logger.Warn("failed to log sync", slog.String("error", updateErr.Error()))
 	}
 
-	duration := time.Since(startTime)
-	j.logger.Info("territory sync completed",
-		"duration", duration.String(),
+	if err != nil {
+		return err
+	}
+
+	j.logger.Info("territory level synced",
+		slog.String("level", level),
+		slog.Int("total", syncLog.TotalRecords),
+		slog.Int("inserted", syncLog.InsertedCount),
+		slog.Int("updated", syncLog.UpdatedCount),
+		slog.Int("deleted", syncLog.DeletedCount),
 	)
+	return nil
+}
+
+func (j *TerritorySyncJob) syncProvinces(ctx context.Context, syncLog *domain.TerritorySyncLog) error {
+	remote, err := j.fetchRemote(ctx, domain.TerritorySyncTypeProvinces, "")
+	if err != nil {
+		return err
+	}
+	// An empty list means an upstream problem, never that every province is gone
+	if len(remote) == 0 {
+		return errors.New("gerbang returned no provinces")
+	}
+
+	local, err := j.fetchLocal(ctx, domain.TerritorySyncTypeProvinces, "")
+	if err != nil {
+		return err
+	}
+
+	return j.applyDiff(ctx, domain.TerritorySyncTypeProvinces, "", local, remote, syncLog)
+}
+
+// syncChildren syncs a level parent by parent, in code order, saving a
+// checkpoint as it goes so an interrupted level resumes after the last parent
+func (j *TerritorySyncJob) syncChildren(ctx context.Context, level string, syncLog *domain.TerritorySyncLog) error {
+	parentLevel := territoryParentLevel(level)
+	parents, err := j.repo.ListActiveCodes(ctx, parentLevel)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", parentLevel, err)
+	}
+
+	processed := 0
+	for _, parent := range parents {
+		if syncLog.Checkpoint != nil && parent <= *syncLog.Checkpoint {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		remote, err := j.fetchRemote(ctx, level, parent)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", parentLevel, parent, err)
+		}
+		local, err := j.fetchLocal(ctx, level, parent)
+		if err != nil {
+			return err
+		}
+		if err := j.applyDiff(ctx, level, parent, local, remote, syncLog); err != nil {
+			return err
+		}
+
+		checkpoint := parent
+		syncLog.Checkpoint = &checkpoint
+		processed++
+		if processed%territoryCheckpointEvery == 0 {
+			if err := j.repo.UpdateSyncLog(ctx, syncLog); err != nil {
+				j.logger.Warn("failed to save territory sync checkpoint", slog.String("error", err.Error()))
+			}
+		}
+	}
 
 	return nil
 }
 
-// Stop gracefully stops the sync job
-func (j *TerritorySyncJob) Stop() {
-	j.logger.Info("stopping territory sync job")
+// applyDiff upserts new and renamed entries and soft-deletes entries missing upstream
+func (j *TerritorySyncJob) applyDiff(ctx context.Context, level, parent string, local, remote []territoryEntry, syncLog *domain.TerritorySyncLog) error {
+	diff := diffTerritory(local, remote)
+	syncLog.TotalRecords += len(remote)
+
+	if len(diff.changed) > 0 {
+		if err := j.upsert(ctx, level, parent, diff.changed); err != nil {
+			return err
+		}
+		syncLog.InsertedCount += diff.inserted
+		syncLog.UpdatedCount += diff.updated
+	}
+
+	if len(diff.removed) == 0 {
+		return nil
+	}
+	// An empty child list with existing rows is more likely an upstream gap
+	// than a real removal of every child; keep the rows and report it
+	if len(remote) == 0 {
+		j.logger.Warn("gerbang returned no territory children, skipping removal",
+			slog.String("level", level),
+			slog.String("parent", parent),
+			slog.Int("existing", len(local)),
+		)
+		return nil
+	}
+
+	var deleted int
+	var err error
+	if level == domain.TerritorySyncTypePostalCodes {
+		deleted, err = j.repo.SoftDeletePostalCodes(ctx, parent, diff.removed)
+	} else {
+		deleted, err = j.repo.SoftDeleteTerritories(ctx, level, diff.removed)
+	}
+	if err != nil {
+		return err
+	}
+	syncLog.DeletedCount += deleted
+	return nil
+}
+
+// territoryEntry is a level-agnostic view of a territory row for diffing;
+// postal codes have no name
+type territoryEntry struct {
+	code string
+	name string
+}
+
+type territoryDiff struct {
+	changed  []territoryEntry
+	inserted int
+	updated  int
+	removed  []string
+}
+
+// diffTerritory compares stored entries with the upstream ones
+func diffTerritory(local, remote []territoryEntry) territoryDiff {
+	existing := make(map[string]string, len(local))
+	for _, e := range local {
+		existing[e.code] = e.name
+	}
+
+	var diff territoryDiff
+	seen := make(map[string]bool, len(remote))
+	for _, e := range remote {
+		if e.code == "" || seen[e.code] {
+			continue
+		}
+		seen[e.code] = true
+
+		name, ok := existing[e.code]
+		switch {
+		case !ok:
+			diff.inserted++
+			diff.changed = append(diff.changed, e)
+		case name != e.name:
+			diff.updated++
+			diff.changed = append(diff.changed, e)
+		}
+	}
+
+	for _, e := range local {
+		if !seen[e.code] {
+			diff.removed = append(diff.removed, e.code)
+		}
+	}
+
+	return diff
+}
+
+func territoryParentLevel(level string) string {
+	for i, l := range domain.TerritorySyncLevels {
+		if l == level && i > 0 {
+			return domain.TerritorySyncLevels[i-1]
+		}
+	}
+	return ""
+}
+
+func (j *TerritorySyncJob) fetchRemote(ctx context.Context, level, parent string) ([]territoryEntry, error) {
+	var entries []territoryEntry
+	switch level {
+	case domain.TerritorySyncTypeProvinces:
+		items, err := j.gerbangClient.GetProvinces(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch provinces from Gerbang API: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, newTerritoryEntry(item.Code, item.Name))
+		}
+	case domain.TerritorySyncTypeCities:
+		items, err := j.gerbangClient.GetCities(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch cities from Gerbang API: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, newTerritoryEntry(item.Code, item.Name))
+		}
+	case domain.TerritorySyncTypeDistricts:
+		items, err := j.gerbangClient.GetDistricts(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch districts from Gerbang API: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, newTerritoryEntry(item.Code, item.Name))
+		}
+	case domain.TerritorySyncTypeSubDistricts:
+		items, err := j.gerbangClient.GetSubDistricts(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch sub-districts from Gerbang API: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, newTerritoryEntry(item.Code, item.Name))
+		}
+	case domain.TerritorySyncTypePostalCodes:
+		items, err := j.gerbangClient.GetPostalCodes(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch postal codes from Gerbang API: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, newTerritoryEntry(item.PostalCode, ""))
+		}
+	}
+	return entries, nil
+}
+
+func (j *TerritorySyncJob) fetchLocal(ctx context.Context, level, parent string) ([]territoryEntry, error) {
+	var entries []territoryEntry
+	switch level {
+	case domain.TerritorySyncTypeProvinces:
+		items, err := j.repo.GetAllProvinces(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get provinces: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, territoryEntry{code: item.Code, name: item.Name})
+		}
+	case domain.TerritorySyncTypeCities:
+		items, err := j.repo.GetCitiesByProvince(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cities: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, territoryEntry{code: item.Code, name: item.Name})
+		}
+	case domain.TerritorySyncTypeDistricts:
+		items, err := j.repo.GetDistrictsByCity(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get districts: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, territoryEntry{code: item.Code, name: item.Name})
+		}
+	case domain.TerritorySyncTypeSubDistricts:
+		items, err := j.repo.GetSubDistrictsByDistrict(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sub-districts: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, territoryEntry{code: item.Code, name: item.Name})
+		}
+	case domain.TerritorySyncTypePostalCodes:
+		items, err := j.repo.GetPostalCodesBySubDistrict(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get postal codes: %w", err)
+		}
+		for _, item := range items {
+			entries = append(entries, territoryEntry{code: item.PostalCode})
+		}
+	}
+	return entries, nil
+}
+
+func (j *TerritorySyncJob) upsert(ctx context.Context, level, parent string, entries []territoryEntry) error {
+	switch level {
+	case domain.TerritorySyncTypeProvinces:
+		items := make([]*domain.Province, len(entries))
+		for i, e := range entries {
+			items[i] = &domain.Province{Code: e.code, Name: e.name}
+		}
+		return j.repo.UpsertProvinces(ctx, items)
+	case domain.TerritorySyncTypeCities:
+		items := make([]*domain.City, len(entries))
+		for i, e := range entries {
+			items[i] = &domain.City{Code: e.code, ProvinceCode: parent, Name: e.name}
+		}
+		return j.repo.UpsertCities(ctx, items)
+	case domain.TerritorySyncTypeDistricts:
+		items := make([]*domain.District, len(entries))
+		for i, e := range entries {
+			items[i] = &domain.District{Code: e.code, CityCode: parent, Name: e.name}
+		}
+		return j.repo.UpsertDistricts(ctx, items)
+	case domain.TerritorySyncTypeSubDistricts:
+		items := make([]*domain.SubDistrict, len(entries))
+		for i, e := range entries {
+			items[i] = &domain.SubDistrict{Code: e.code, DistrictCode: parent, Name: e.name}
+		}
+		return j.repo.UpsertSubDistricts(ctx, items)
+	case domain.TerritorySyncTypePostalCodes:
+		items := make([]*domain.PostalCode, len(entries))
+		for i, e := range entries {
+			items[i] = &domain.PostalCode{SubDistrictCode: parent, PostalCode: e.code}
+		}
+		return j.repo.UpsertPostalCodes(ctx, items)
+	}
+	return fmt.Errorf("unknown territory level %q", level)
+}
+
+func newTerritoryEntry(code, name string) territoryEntry {
+	return territoryEntry{code: strings.TrimSpace(code), name: strings.TrimSpace(name)}
 }
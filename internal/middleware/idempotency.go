@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency record states
+const (
+	idempotencyProcessing = "processing"
+	idempotencyCompleted  = "completed"
+)
+
+// IdempotencyConfig holds idempotency configuration
+type IdempotencyConfig struct {
+	TTL     time.Duration // How long a completed response is replayed
+	LockTTL time.Duration // How long an unfinished request blocks its key (crash safety)
+}
+
+// DefaultIdempotencyConfig returns default idempotency config. The lock
+// outlives the slowest payment: three 30s Gerbang attempts with backoff, a
+// failover to a second supplier and the database work around them.
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:     24 * time.Hour,
+		LockTTL: 10 * time.Minute,
+	}
+}
+
+// idempotencyRecord is the state of a key stored in Redis
+type idempotencyRecord struct {
+	Status      string `json:"status"`
+	RequestHash string `json:"requestHash"`
+	Token       string `json:"token,omitempty"` // Identifies the request holding the lock
+	StatusCode  int    `json:"statusCode,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotencyStore keeps idempotency records
+type idempotencyStore interface {
+	// Acquire stores lock under key unless the key exists
+	Acquire(ctx context.Context, key string, lock []byte, ttl time.Duration) (bool, error)
+	// Get returns the record stored under key
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Replace stores value under key only while key still holds lock
+	Replace(ctx context.Context, key string, lock, value []byte, ttl time.Duration) (bool, error)
+}
+
+// replaceIfLockedScript sets KEYS[1] to ARGV[2] for ARGV[3] milliseconds if it
+// still holds ARGV[1]
+var replaceIfLockedScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// redisIdempotencyStore keeps idempotency records in Redis
+type redisIdempotencyStore struct {
+	client *redis.Client
+}
+
+func (s *redisIdempotencyStore) Acquire(ctx context.Context, key string, lock []byte, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, lock, ttl).Result()
+}
+
+func (s *redisIdempotencyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.client.Get(ctx, key).Bytes()
+}
+
+func (s *redisIdempotencyStore) Replace(ctx context.Context, key string, lock, value []byte, ttl time.Duration) (bool, error) {
+	replaced, err := replaceIfLockedScript.Run(ctx, s.client, []string{key}, lock, value, ttl.Milliseconds()).Int()
+	return replaced == 1, err
+}
+
+// Idempotency returns a middleware that honours the Idempotency-Key header on
+// authenticated money-moving endpoints. Keys are scoped to user and route:
+// a retry with the same key and body replays the stored response, the same key
+// with a different body is rejected. Requests without the header pass through.
+// Every answer is stored, including 5xx ones: the request may have moved
+// money before it failed, so a retry must use a new key. Must be registered
+// after JWTAuth.
+func Idempotency(redisClient *redis.Client, config IdempotencyConfig) gin.HandlerFunc {
+	return idempotency(&redisIdempotencyStore{client: redisClient}, config)
+}
+
+func idempotency(store idempotencyStore, config IdempotencyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondError(c, domain.ErrIdempotencyKeyInvalid)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondError(c, domain.ErrValidationFailed("Body request tidak valid"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+		redisKey := redis.IdempotencyKey(GetUserID(c), c.FullPath(), key)
+		ctx := context.Background()
+
+		lock, err := json.Marshal(idempotencyRecord{Status: idempotencyProcessing, RequestHash: requestHash, Token: uuid.NewString()})
+		if err != nil {
+			c.Next()
+			return
+		}
+		acquired, err := store.Acquire(ctx, redisKey, lock, config.LockTTL)
+		if err != nil {
+			// If Redis fails, allow request; the services keep their own duplicate checks
+			slog.Warn("idempotency store unavailable", slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			var record idempotencyRecord
+			stored, err := store.Get(ctx, redisKey)
+			if err == nil {
+				err = json.Unmarshal(stored, &record)
+			}
+			if err != nil {
+				// Expired between Acquire and Get; let the client retry
+				respondError(c, domain.ErrIdempotencyInProgress)
+				c.Abort()
+				return
+			}
+			switch {
+			case record.RequestHash != requestHash:
+				respondError(c, domain.ErrIdempotencyKeyReused)
+			case record.Status != idempotencyCompleted:
+				respondError(c, domain.ErrIdempotencyInProgress)
+			default:
+				c.Header(IdempotencyReplayedHeader, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		record, err := json.Marshal(idempotencyRecord{
+			Status:      idempotencyCompleted,
+			RequestHash: requestHash,
+			StatusCode:  writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err == nil {
+			var replaced bool
+			replaced, err = store.Replace(ctx, redisKey, lock, record, config.TTL)
+			if err == nil && !replaced {
+				// The lock expired and another request may own the key now
+				slog.Warn("idempotency lock lost before the response was stored", slog.String("key", redisKey))
+				return
+			}
+		}
+		if err != nil {
+			slog.Warn("failed to store idempotent response",
+				slog.String("key", redisKey),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// capturingWriter keeps a copy of the response body for replay
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeIdempotencyStore keeps records in memory; down makes every call fail
+// like an unreachable Redis
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string][]byte
+	down    bool
+}
+
+var errStoreDown = errors.New("connection refused")
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: map[string][]byte{}}
+}
+
+func (s *fakeIdempotencyStore) Acquire(ctx context.Context, key string, lock []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return false, errStoreDown
+	}
+	if _, ok := s.records[key]; ok {
+		return false, nil
+	}
+	s.records[key] = lock
+	return true, nil
+}
+
+func (s *fakeIdempotencyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, errStoreDown
+	}
+	record, ok := s.records[key]
+	if !ok {
+		return nil, errors.New("redis: nil")
+	}
+	return record, nil
+}
+
+func (s *fakeIdempotencyStore) Replace(ctx context.Context, key string, lock, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return false, errStoreDown
+	}
+	if !bytes.Equal(s.records[key], lock) {
+		return false, nil
+	}
+	s.records[key] = value
+	return true, nil
+}
+
+// set overwrites the record of every key, e.g. to simulate another request
+func (s *fakeIdempotencyStore) set(record idempotencyRecord) {
+	data, _ := json.Marshal(record)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.records {
+		s.records[key] = data
+	}
+}
+
+// newIdempotencyRouter serves POST /pay behind the middleware; the handler
+// answers status and counts its calls
+func newIdempotencyRouter(store idempotencyStore, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/pay", func(c *gin.Context) {
+		c.Set(UserIDKey, "usr_1")
+	}, idempotency(store, DefaultIdempotencyConfig()), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func postPay(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/pay", bytes.NewBufferString(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode error response %q: %v", w.Body.String(), err)
+	}
+	return resp.Error.Code
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(newFakeIdempotencyStore(), http.StatusCreated, &calls)
+
+	first := postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	second := postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	if calls != 1 {
+		t.Fatalf("handler called %d times; want 1", calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s; want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Error("replay is missing the replayed header")
+	}
+	if first.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Error("first response has the replayed header")
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(newFakeIdempotencyStore(), http.StatusOK, &calls)
+
+	postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	w := postPay(router, "key-1", `{"orderId":"ord_2"}`)
+	if w.Code != domain.ErrIdempotencyKeyReused.HTTPStatus || errorCode(t, w) != domain.CodeIdempotencyKeyReused {
+		t.Errorf("reused key = %d %s; want %s", w.Code, w.Body, domain.CodeIdempotencyKeyReused)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times; want 1", calls)
+	}
+}
+
+func TestIdempotencyRejectsRequestInProgress(t *testing.T) {
+	calls := 0
+	store := newFakeIdempotencyStore()
+	router := newIdempotencyRouter(store, http.StatusOK, &calls)
+
+	postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	var record idempotencyRecord
+	stored, _ := store.Get(context.Background(), firstKey(store))
+	json.Unmarshal(stored, &record)
+	store.set(idempotencyRecord{Status: idempotencyProcessing, RequestHash: record.RequestHash, Token: "other"})
+
+	w := postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	if w.Code != domain.ErrIdempotencyInProgress.HTTPStatus || errorCode(t, w) != domain.CodeIdempotencyInProgress {
+		t.Errorf("request in progress = %d %s; want %s", w.Code, w.Body, domain.CodeIdempotencyInProgress)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times; want 1", calls)
+	}
+}
+
+func TestIdempotencyKeepsServerErrors(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(newFakeIdempotencyStore(), http.StatusInternalServerError, &calls)
+
+	postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	w := postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	if calls != 1 {
+		t.Fatalf("handler called %d times after a 5xx; want 1", calls)
+	}
+	if w.Code != http.StatusInternalServerError || w.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Errorf("retry after 5xx = %d replayed=%q; want replayed 500", w.Code, w.Header().Get(IdempotencyReplayedHeader))
+	}
+}
+
+func TestIdempotencyDoesNotOverwriteLostLock(t *testing.T) {
+	store := newFakeIdempotencyStore()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/pay", func(c *gin.Context) {
+		c.Set(UserIDKey, "usr_1")
+	}, idempotency(store, DefaultIdempotencyConfig()), func(c *gin.Context) {
+		// The lock expired mid-request and a retry took the key
+		store.set(idempotencyRecord{Status: idempotencyProcessing, RequestHash: "retry", Token: "retry"})
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	postPay(router, "key-1", `{"orderId":"ord_1"}`)
+	var record idempotencyRecord
+	stored, _ := store.Get(context.Background(), firstKey(store))
+	if err := json.Unmarshal(stored, &record); err != nil || record.Token != "retry" {
+		t.Errorf("record = %+v, %v; want the retry's lock kept", record, err)
+	}
+}
+
+func TestIdempotencyPassesThroughWhenStoreIsDown(t *testing.T) {
+	calls := 0
+	store := newFakeIdempotencyStore()
+	store.down = true
+	router := newIdempotencyRouter(store, http.StatusOK, &calls)
+
+	for i := 0; i < 2; i++ {
+		if w := postPay(router, "key-1", `{"orderId":"ord_1"}`); w.Code != http.StatusOK {
+			t.Fatalf("request %d = %d %s; want 200", i, w.Code, w.Body)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times; want 2", calls)
+	}
+}
+
+func firstKey(store *fakeIdempotencyStore) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for key := range store.records {
+		return key
+	}
+	return ""
+}
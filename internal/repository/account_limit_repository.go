@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// AccountLimitRepository defines the interface for account limit data operations
+type AccountLimitRepository interface {
+	FindConfig(ctx context.Context) (*domain.AccountLimitConfig, error)
+	SumOutgoing(ctx context.Context, userID string, dayStart, monthStart time.Time) (domain.OutgoingUsage, error)
+	SumOutgoingWithTx(ctx context.Context, tx *sqlx.Tx, userID string, dayStart, monthStart time.Time) (domain.OutgoingUsage, error)
+	SumPendingDeposits(ctx context.Context, userID string) (int64, error)
+}
+
+// accountLimitRepository implements AccountLimitRepository
+type accountLimitRepository struct {
+	db *sqlx.DB
+}
+
+// NewAccountLimitRepository creates a new account limit repository
+func NewAccountLimitRepository(db *sqlx.DB) AccountLimitRepository {
+	return &accountLimitRepository{db: db}
+}
+
+// FindConfig reads the account_limits admin setting. It returns nil when the
+// setting has not been created.
+func (r *accountLimitRepository) FindConfig(ctx context.Context) (*domain.AccountLimitConfig, error) {
+	var raw []byte
+	err := r.db.GetContext(ctx, &raw, `SELECT value FROM admin_settings WHERE key = $1`, domain.AccountLimitsSettingKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config domain.AccountLimitConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// outgoingUsageQuery sums what a user paid out since the start of the day and
+// month across prepaid, postpaid and transfer payments. Payments that failed
+// or were refunded do not count.
+const outgoingUsageQuery = `
+	SELECT
+		COALESCE(SUM(total_payment) FILTER (WHERE created_at >= $2), 0) AS daily,
+		COALESCE(SUM(total_payment), 0) AS monthly
+	FROM (
+		SELECT total_payment, created_at FROM prepaid_transactions
+		WHERE user_id = $1 AND created_at >= $3 AND status NOT IN ('failed', 'refunded')
+		UNION ALL
+		SELECT total_payment, created_at FROM postpaid_transactions
+		WHERE user_id = $1 AND created_at >= $3 AND status NOT IN ('failed', 'refunded')
+		UNION ALL
+		SELECT total_payment, created_at FROM transfer_transactions
+		WHERE user_id = $1 AND created_at >= $3 AND status NOT IN ('failed', 'refunded')
+	) outgoing
+`
+
+// SumOutgoing returns the user's outgoing payments in the current day and month
+func (r *accountLimitRepository) SumOutgoing(ctx context.Context, userID string, dayStart, monthStart time.Time) (domain.OutgoingUsage, error) {
+	var usage domain.OutgoingUsage
+	err := r.db.GetContext(ctx, &usage, outgoingUsageQuery, userID, dayStart, monthStart)
+	return usage, err
+}
+
+// SumOutgoingWithTx returns the user's outgoing payments within a database transaction
+func (r *accountLimitRepository) SumOutgoingWithTx(ctx context.Context, tx *sqlx.Tx, userID string, dayStart, monthStart time.Time) (domain.OutgoingUsage, error) {
+	var usage domain.OutgoingUsage
+	err := tx.GetContext(ctx, &usage, outgoingUsageQuery, userID, dayStart, monthStart)
+	return usage, err
+}
+
+// SumPendingDeposits returns the amount of the user's deposits that may still
+// be credited: unexpired deposits awaiting payment and paid deposits held for
+// review
+func (r *accountLimitRepository) SumPendingDeposits(ctx context.Context, userID string) (int64, error) {
+	var total int64
+	err := r.db.GetContext(ctx, &total, `
+		SELECT COALESCE(SUM(amount), 0) FROM deposits
+		WHERE user_id = $1
+		  AND ((status = $2 AND expires_at > NOW()) OR status = $3)
+	`, userID, domain.DepositStatusPending, domain.DepositStatusReview)
+	return total, err
+}
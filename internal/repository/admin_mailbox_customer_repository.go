@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
+)
+
+// FindCustomerIDByEmail returns the customer registered with an email address.
+// An address shared by several accounts links to none of them.
+func (r *AdminRepository) FindCustomerIDByEmail(ctx context.Context, email string) (string, error) {
+	var ids []string
+	if err := r.db.SelectContext(ctx, &ids, `
+		SELECT id FROM users
+		WHERE LOWER(email::text) = LOWER($1)
+		LIMIT 2
+	`, email); err != nil {
+		return "", err
+	}
+	if len(ids) != 1 {
+		return "", nil
+	}
+	return ids[0], nil
+}
+
+func (r *AdminRepository) FindCustomerIDByPhone(ctx context.Context, phone string) (string, error) {
+	var id string
+	err := r.db.GetContext(ctx, &id, `SELECT id FROM users WHERE phone = $1 LIMIT 1`, phone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
+
+// LinkThreadCustomer sets the customer of a thread; a nil userID unlinks it
+func (r *AdminRepository) LinkThreadCustomer(ctx context.Context, threadID string, userID *string, source string) error {
+	var linkedAt interface{}
+	if userID != nil {
+		linkedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE admin_email_threads
+		SET customer_user_id = $2, customer_link_source = $3, customer_linked_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`, threadID, nullableStringPointer(userID), nullableString(source), linkedAt)
+	return err
+}
+
+// FindThreadLinkResource resolves a transaction, deposit or KYC reference to
+// the ID the admin console opens it by, and its owner
+func (r *AdminRepository) FindThreadLinkResource(ctx context.Context, resourceType, resourceID string) (string, string, error) {
+	var query string
+	switch resourceType {
+	case domain.ThreadLinkTransaction:
+		query = `SELECT COALESCE(public_id, id) AS id, user_id FROM transactions WHERE id = $1 OR public_id = $1 LIMIT 1`
+	case domain.ThreadLinkDeposit:
+		query = `SELECT COALESCE(public_id, id) AS id, user_id FROM deposits WHERE id = $1 OR public_id = $1 LIMIT 1`
+	case domain.ThreadLinkKYC:
+		query = `SELECT user_id AS id, user_id FROM kyc_verifications WHERE id = $1 OR user_id = $1 LIMIT 1`
+	default:
+		return "", "", nil
+	}
+
+	var resource struct {
+		ID     string `db:"id"`
+		UserID string `db:"user_id"`
+	}
+	err := r.db.GetContext(ctx, &resource, query, resourceID)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return resource.ID, resource.UserID, nil
+}
+
+// CreateThreadLink attaches a record to a thread; attaching it twice is a
+// no-op
+func (r *AdminRepository) CreateThreadLink(ctx context.Context, link *domain.AdminEmailThreadLink) error {
+	if link.ID == "" {
+		link.ID = "aetl_" + uuid.New().String()[:8]
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO admin_email_thread_links (id, thread_id, resource_type, resource_id, user_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (thread_id, resource_type, resource_id) DO NOTHING
+	`, link.ID, link.ThreadID, link.ResourceType, link.ResourceID, nullableStringPointer(link.UserID), nullableStringPointer(link.CreatedBy))
+	return err
+}
+
+func (r *AdminRepository) DeleteThreadLink(ctx context.Context, threadID, linkID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM admin_email_thread_links WHERE id = $1 AND thread_id = $2`, linkID, threadID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ListThreadLinks returns the records attached to a thread with a short
+// summary of each
+func (r *AdminRepository) ListThreadLinks(ctx context.Context, threadID string) ([]map[string]interface{}, error) {
+	return r.selectMaps(ctx, `
+		SELECT
+			l.id,
+			l.resource_type,
+			l.resource_id,
+			l.user_id,
+			l.created_at,
+			COALESCE(creator.full_name, creator.email, '') AS created_by_name,
+			CASE l.resource_type
+				WHEN 'transaction' THEN COALESCE(t.status, '')
+				WHEN 'deposit' THEN COALESCE(d.status, '')
+				WHEN 'kyc' THEN COALESCE(u.kyc_status, '')
+				ELSE ''
+			END AS status,
+			CASE l.resource_type
+				WHEN 'transaction' THEN COALESCE(t.product_name, t.target, '')
+				WHEN 'deposit' THEN COALESCE(d.method, '')
+				ELSE ''
+			END AS description,
+			CASE l.resource_type
+				WHEN 'transaction' THEN t.total_payment
+				WHEN 'deposit' THEN d.amount
+			END AS amount
+		FROM admin_email_thread_links l
+		LEFT JOIN admin_users creator ON creator.id = l.created_by
+		LEFT JOIN transactions t ON l.resource_type = 'transaction' AND COALESCE(t.public_id, t.id) = l.resource_id
+		LEFT JOIN deposits d ON l.resource_type = 'deposit' AND COALESCE(d.public_id, d.id) = l.resource_id
+		LEFT JOIN users u ON l.resource_type = 'kyc' AND u.id = l.resource_id
+		WHERE l.thread_id = $1
+		ORDER BY l.created_at DESC
+	`, threadID)
+}
+
+// GetThreadCustomerPanel returns what an agent needs to see about the
+// customer of a thread: account, balance, KYC state and recent transactions
+func (r *AdminRepository) GetThreadCustomerPanel(ctx context.Context, userID string) (map[string]interface{}, error) {
+	items, err := r.selectMaps(ctx, `
+		SELECT
+			u.id,
+			u.mic,
+			u.phone,
+			COALESCE(u.full_name, '') AS full_name,
+			COALESCE(u.email::text, '') AS email,
+			u.tier,
+			u.kyc_status,
+			u.is_active,
+			u.created_at,
+			COALESCE(b.amount, 0) AS balance,
+			kv.verified_at AS kyc_verified_at
+		FROM users u
+		LEFT JOIN balances b ON b.user_id = u.id
+		LEFT JOIN kyc_verifications kv ON kv.user_id = u.id
+		WHERE u.id = $1
+		LIMIT 1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	panel := items[0]
+
+	transactions, err := r.selectMaps(ctx, `
+		SELECT
+			COALESCE(t.public_id, t.id) AS id,
+			t.type,
+			t.service_type,
+			t.target,
+			COALESCE(t.product_name, '') AS product_name,
+			t.total_payment,
+			t.status,
+			t.created_at
+		FROM transactions t
+		WHERE t.user_id = $1
+		ORDER BY t.created_at DESC
+		LIMIT 5
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	panel["recent_transactions"] = transactions
+	return panel, nil
+}
+
+// ListCustomerEmailThreads returns the mailbox threads linked to a customer,
+// newest first
+func (r *AdminRepository) ListCustomerEmailThreads(ctx context.Context, userID string, limit int) ([]map[string]interface{}, error) {
+	return r.selectMaps(ctx, `
+		SELECT
+			aet.id,
+			aet.mailbox_id,
+			am.address AS mailbox_address,
+			am.display_name AS mailbox_name,
+			aet.participant_email,
+			aet.subject,
+			aet.status,
+			COALESCE(aet.last_message_preview, '') AS last_message_preview,
+			aet.latest_message_at,
+			aet.customer_link_source,
+			aet.created_at
+		FROM admin_email_threads aet
+		INNER JOIN admin_mailboxes am ON am.id = aet.mailbox_id
+		WHERE aet.customer_user_id = $1
+		ORDER BY aet.latest_message_at DESC NULLS LAST, aet.created_at DESC
+		LIMIT $2
+	`, userID, sanitizePageSize(limit))
+}
@@ -336,7 +336,9 @@ func (r *AdminRepository) FindThreadByID(ctx context.Context, threadID string) (
 	err := r.db.GetContext(ctx, &thread, `
 		SELECT id, mailbox_id, participant_name, participant_email, subject, normalized_subject, status,
 		       assigned_admin_id, unread_count, last_direction, last_message_preview, latest_message_at,
-		       last_inbound_at, last_outbound_at, meta, created_at, updated_at
+		       last_inbound_at, last_outbound_at, customer_user_id, customer_link_source, customer_linked_at,
+		       sla_started_at, first_response_due_at, resolution_due_at, first_responded_at, resolved_at,
+		       meta, created_at, updated_at
 		FROM admin_email_threads
 		WHERE id = $1
 		LIMIT 1
@@ -367,7 +369,9 @@ func (r *AdminRepository) FindThreadByMessageReferences(ctx context.Context, mai
 		SELECT DISTINCT
 			t.id, t.mailbox_id, t.participant_name, t.participant_email, t.subject, t.normalized_subject, t.status,
 			t.assigned_admin_id, t.unread_count, t.last_direction, t.last_message_preview, t.latest_message_at,
-			t.last_inbound_at, t.last_outbound_at, t.meta, t.created_at, t.updated_at
+			t.last_inbound_at, t.last_outbound_at, t.customer_user_id, t.customer_link_source, t.customer_linked_at,
+			t.sla_started_at, t.first_response_due_at, t.resolution_due_at, t.first_responded_at, t.resolved_at,
+			t.meta, t.created_at, t.updated_at
 		FROM admin_email_threads t
 		INNER JOIN admin_email_messages m ON m.thread_id = t.id
 		WHERE t.mailbox_id = ? AND m.message_id_header IN (?)
@@ -395,7 +399,9 @@ func (r *AdminRepository) FindThreadByParticipant(ctx context.Context, mailboxID
 	err := r.db.GetContext(ctx, &thread, `
 		SELECT id, mailbox_id, participant_name, participant_email, subject, normalized_subject, status,
 		       assigned_admin_id, unread_count, last_direction, last_message_preview, latest_message_at,
-		       last_inbound_at, last_outbound_at, meta, created_at, updated_at
+		       last_inbound_at, last_outbound_at, customer_user_id, customer_link_source, customer_linked_at,
+		       sla_started_at, first_response_due_at, resolution_due_at, first_responded_at, resolved_at,
+		       meta, created_at, updated_at
 		FROM admin_email_threads
 		WHERE mailbox_id = $1
 		  AND normalized_subject = $2
@@ -470,6 +476,7 @@ func (r *AdminRepository) UpdateThreadAfterOutbound(ctx context.Context, threadI
 			last_message_preview = $4,
 			latest_message_at = $5,
 			last_outbound_at = $5,
+			first_responded_at = CASE WHEN sla_started_at IS NULL THEN first_responded_at ELSE COALESCE(first_responded_at, $5) END,
 			updated_at = NOW()
 		WHERE id = $1
 	`, threadID, domain.AdminEmailThreadStatusDibalas, domain.AdminEmailDirectionOutbound, preview, sentAt)
@@ -479,9 +486,14 @@ func (r *AdminRepository) UpdateThreadAfterOutbound(ctx context.Context, threadI
 func (r *AdminRepository) UpdateThreadStatus(ctx context.Context, threadID, status string) error {
 	_, err := r.db.ExecContext(ctx, `
 		UPDATE admin_email_threads
-		SET status = $2, updated_at = NOW()
+		SET status = $2,
+			resolved_at = CASE
+				WHEN $2 IN ($3, $4) THEN COALESCE(resolved_at, NOW())
+				ELSE NULL
+			END,
+			updated_at = NOW()
 		WHERE id = $1
-	`, threadID, status)
+	`, threadID, status, domain.AdminEmailThreadStatusSelesai, domain.AdminEmailThreadStatusArsip)
 	return err
 }
 
@@ -551,8 +563,8 @@ func (r *AdminRepository) ExistsMessageByHeader(ctx context.Context, messageIDHe
 func (r *AdminRepository) CreateEmailAttachment(ctx context.Context, payload map[string]interface{}) error {
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO admin_email_attachments (
-			id, message_id, file_name, content_type, size_bytes, storage_key, created_at
-		) VALUES ($1,$2,$3,$4,$5,$6,$7)
+			id, message_id, file_name, content_type, size_bytes, storage_key, extracted_text, created_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
 	`,
 		payload["id"],
 		payload["messageId"],
@@ -560,6 +572,7 @@ func (r *AdminRepository) CreateEmailAttachment(ctx context.Context, payload map
 		payload["contentType"],
 		payload["sizeBytes"],
 		payload["storageKey"],
+		payload["extractedText"],
 		payload["createdAt"],
 	)
 	return err
@@ -639,7 +652,11 @@ func (r *AdminRepository) ListThreadsByMailbox(ctx context.Context, mailboxID, s
 			aet.updated_at,
 			COALESCE(aet.assigned_admin_id, '') AS assigned_admin_id,
 			COALESCE(assigned_admin.full_name, assigned_admin.email, '') AS assigned_admin_name,
-			aet.is_important
+			aet.is_important,
+			aet.first_response_due_at,
+			aet.resolution_due_at,
+			aet.first_responded_at,
+			(aet.first_response_breached_at IS NOT NULL OR aet.resolution_breached_at IS NOT NULL) AS sla_breached
 	` + base + where + `
 		ORDER BY aet.latest_message_at DESC NULLS LAST, aet.created_at DESC
 		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
@@ -672,7 +689,18 @@ func (r *AdminRepository) GetThreadDetail(ctx context.Context, threadID string)
 			am.type AS mailbox_type,
 			am.address AS mailbox_address,
 			am.display_name AS mailbox_name,
-			aet.is_important
+			aet.is_important,
+			aet.customer_user_id,
+			aet.customer_link_source,
+			aet.customer_linked_at,
+			aet.sla_started_at,
+			aet.first_response_due_at,
+			aet.resolution_due_at,
+			aet.first_responded_at,
+			aet.resolved_at,
+			aet.first_response_breached_at,
+			aet.resolution_breached_at,
+			aet.escalation_count
 		FROM admin_email_threads aet
 		INNER JOIN admin_mailboxes am ON am.id = aet.mailbox_id
 		LEFT JOIN admin_users assigned_admin ON assigned_admin.id = aet.assigned_admin_id
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+// mailboxSearchHeadline marks the matched words in search snippets
+const mailboxSearchHeadline = "MaxWords=35, MinWords=15, MaxFragments=2, StartSel=<mark>, StopSel=</mark>"
+
+// SearchMailboxMessages runs a full-text search over the messages of the
+// given mailboxes and the extracted text of their attachments
+func (r *AdminRepository) SearchMailboxMessages(ctx context.Context, query *domain.MailboxSearchQuery, mailboxIDs []string, page, perPage int) ([]map[string]interface{}, int, error) {
+	if len(mailboxIDs) == 0 {
+		return []map[string]interface{}{}, 0, nil
+	}
+
+	base := `
+		FROM admin_email_messages aem
+		INNER JOIN admin_email_threads aet ON aet.id = aem.thread_id
+		INNER JOIN admin_mailboxes am ON am.id = aem.mailbox_id
+	`
+	args := make([]interface{}, 0, len(mailboxIDs)+8)
+	argIdx := 1
+
+	if query.Text != "" {
+		base += fmt.Sprintf(" CROSS JOIN websearch_to_tsquery('simple', $%d) AS q", argIdx)
+		args = append(args, query.Text)
+		argIdx++
+	}
+
+	placeholders := make([]string, 0, len(mailboxIDs))
+	for _, mailboxID := range mailboxIDs {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", argIdx))
+		args = append(args, mailboxID)
+		argIdx++
+	}
+	whereClauses := []string{"aem.mailbox_id IN (" + strings.Join(placeholders, ",") + ")"}
+
+	if query.Text != "" {
+		whereClauses = append(whereClauses, `(
+			aem.search_vector @@ q OR
+			EXISTS (SELECT 1 FROM admin_email_attachments aea WHERE aea.message_id = aem.id AND aea.search_vector @@ q)
+		)`)
+	}
+	if len(query.From) > 0 {
+		fromClauses := make([]string, 0, len(query.From))
+		for _, from := range query.From {
+			fromClauses = append(fromClauses, fmt.Sprintf(
+				"(LOWER(aem.sender_address) LIKE $%d OR LOWER(COALESCE(aem.sender_name, '')) LIKE $%d)", argIdx, argIdx))
+			args = append(args, "%"+from+"%")
+			argIdx++
+		}
+		whereClauses = append(whereClauses, "("+strings.Join(fromClauses, " OR ")+")")
+	}
+	if query.HasAttachment {
+		whereClauses = append(whereClauses, "EXISTS (SELECT 1 FROM admin_email_attachments aea WHERE aea.message_id = aem.id)")
+	}
+	if query.After != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("COALESCE(aem.received_at, aem.sent_at, aem.created_at) >= $%d", argIdx))
+		args = append(args, *query.After)
+		argIdx++
+	}
+	if query.Before != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("COALESCE(aem.received_at, aem.sent_at, aem.created_at) < $%d", argIdx))
+		args = append(args, *query.Before)
+		argIdx++
+	}
+	if query.Status != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("aet.status = $%d", argIdx))
+		args = append(args, query.Status)
+		argIdx++
+	}
+
+	where := " WHERE " + strings.Join(whereClauses, " AND ")
+	total, err := r.count(ctx, `SELECT COUNT(*) `+base+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Bodies are escaped so the snippet is safe to render as HTML with its marks
+	body := `replace(replace(replace(
+		COALESCE(NULLIF(aem.text_body, ''), regexp_replace(COALESCE(aem.html_body, ''), '<[^>]*>', ' ', 'g')),
+		'&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+	snippet := `LEFT(` + body + `, 300)`
+	attachmentMatch := "false"
+	orderBy := "message_at DESC, aem.id DESC"
+	if query.Text != "" {
+		snippet = `ts_headline('simple', ` + body + `, q, '` + mailboxSearchHeadline + `')`
+		attachmentMatch = "aea.search_vector @@ q"
+		orderBy = "ts_rank(aem.search_vector, q) DESC, message_at DESC, aem.id DESC"
+	}
+
+	items, err := r.selectMaps(ctx, `
+		SELECT
+			aem.id,
+			aem.thread_id,
+			aem.mailbox_id,
+			am.address AS mailbox_address,
+			am.display_name AS mailbox_name,
+			aem.direction,
+			COALESCE(aem.sender_name, '') AS sender_name,
+			aem.sender_address,
+			aem.subject,
+			aet.status AS thread_status,
+			COALESCE(aet.assigned_admin_id, '') AS assigned_admin_id,
+			COALESCE(aem.received_at, aem.sent_at, aem.created_at) AS message_at,
+			`+snippet+` AS snippet,
+			(
+				SELECT COALESCE(json_agg(json_build_object(
+					'id', aea.id,
+					'fileName', aea.file_name,
+					'contentType', aea.content_type,
+					'sizeBytes', aea.size_bytes,
+					'matched', `+attachmentMatch+`
+				) ORDER BY aea.created_at), '[]'::json)
+				FROM admin_email_attachments aea
+				WHERE aea.message_id = aem.id
+			) AS attachments
+	`+base+where+`
+		ORDER BY `+orderBy+`
+		LIMIT $`+fmt.Sprintf("%d", argIdx)+` OFFSET $`+fmt.Sprintf("%d", argIdx+1),
+		append(args, sanitizePageSize(perPage), calculateOffset(page, perPage))...)
+	return items, total, err
+}
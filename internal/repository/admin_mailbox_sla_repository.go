@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+func (r *AdminRepository) FindMailboxSLAPolicy(ctx context.Context, mailboxID string) (*domain.AdminMailboxSLAPolicy, error) {
+	var policy domain.AdminMailboxSLAPolicy
+	err := r.db.GetContext(ctx, &policy, `
+		SELECT mailbox_id, first_response_minutes, resolution_minutes, business_hours, timezone,
+		       auto_assign_mode, escalation_action, escalation_admin_id, is_active, updated_by, created_at, updated_at
+		FROM admin_mailbox_sla_policies
+		WHERE mailbox_id = $1
+	`, mailboxID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *AdminRepository) UpsertMailboxSLAPolicy(ctx context.Context, policy *domain.AdminMailboxSLAPolicy) error {
+	hours := policy.BusinessHours
+	if hours == nil {
+		hours = domain.BusinessHours{}
+	}
+	businessHours, err := json.Marshal(hours)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowxContext(ctx, `
+		INSERT INTO admin_mailbox_sla_policies (
+			mailbox_id, first_response_minutes, resolution_minutes, business_hours, timezone,
+			auto_assign_mode, escalation_action, escalation_admin_id, is_active, updated_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		ON CONFLICT (mailbox_id) DO UPDATE SET
+			first_response_minutes = EXCLUDED.first_response_minutes,
+			resolution_minutes = EXCLUDED.resolution_minutes,
+			business_hours = EXCLUDED.business_hours,
+			timezone = EXCLUDED.timezone,
+			auto_assign_mode = EXCLUDED.auto_assign_mode,
+			escalation_action = EXCLUDED.escalation_action,
+			escalation_admin_id = EXCLUDED.escalation_admin_id,
+			is_active = EXCLUDED.is_active,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`,
+		policy.MailboxID,
+		policy.FirstResponseMinutes,
+		policy.ResolutionMinutes,
+		businessHours,
+		policy.Timezone,
+		policy.AutoAssignMode,
+		policy.EscalationAction,
+		policy.EscalationAdminID,
+		policy.IsActive,
+		policy.UpdatedBy,
+	).Scan(&policy.CreatedAt, &policy.UpdatedAt)
+}
+
+// StartThreadSLA (re)starts the SLA timers of a thread from startedAt
+func (r *AdminRepository) StartThreadSLA(ctx context.Context, threadID string, startedAt time.Time, firstResponseDue, resolutionDue *time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE admin_email_threads
+		SET sla_started_at = $2,
+			first_response_due_at = $3,
+			resolution_due_at = $4,
+			first_responded_at = NULL,
+			resolved_at = NULL,
+			first_response_breached_at = NULL,
+			resolution_breached_at = NULL,
+			updated_at = NOW()
+		WHERE id = $1
+	`, threadID, startedAt, firstResponseDue, resolutionDue)
+	return err
+}
+
+// ListMailboxAssignees returns the active members of a mailbox that can
+// reply, with their open thread count there
+func (r *AdminRepository) ListMailboxAssignees(ctx context.Context, mailboxID string) ([]domain.MailboxAssignee, error) {
+	assignees := make([]domain.MailboxAssignee, 0)
+	err := r.db.SelectContext(ctx, &assignees, `
+		SELECT
+			amm.admin_user_id,
+			au.email,
+			COALESCE(au.full_name, '') AS full_name,
+			amm.last_assigned_at,
+			(
+				SELECT COUNT(*) FROM admin_email_threads aet
+				WHERE aet.mailbox_id = amm.mailbox_id
+				  AND aet.assigned_admin_id = amm.admin_user_id
+				  AND aet.status IN ($2, $3)
+			) AS open_threads
+		FROM admin_mailbox_members amm
+		INNER JOIN admin_users au ON au.id = amm.admin_user_id
+		WHERE amm.mailbox_id = $1
+		  AND amm.can_reply
+		  AND au.status = 'active' AND au.is_active = true
+	`, mailboxID, domain.AdminEmailThreadStatusBelumDibalas, domain.AdminEmailThreadStatusDibalas)
+	return assignees, err
+}
+
+// AssignThreadToMember assigns a thread and moves the member to the back of
+// the round-robin order
+func (r *AdminRepository) AssignThreadToMember(ctx context.Context, threadID, mailboxID, adminID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE admin_email_threads
+		SET assigned_admin_id = $2, updated_at = NOW()
+		WHERE id = $1
+	`, threadID, adminID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE admin_mailbox_members
+		SET last_assigned_at = NOW()
+		WHERE mailbox_id = $1 AND admin_user_id = $2
+	`, mailboxID, adminID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListSLABreaches returns open threads past a target that have not been
+// escalated for it yet, under an active policy
+func (r *AdminRepository) ListSLABreaches(ctx context.Context, now time.Time, limit int) ([]domain.MailboxSLABreach, error) {
+	breaches := make([]domain.MailboxSLABreach, 0)
+	err := r.db.SelectContext(ctx, &breaches, `
+		SELECT * FROM (
+			SELECT
+				aet.id AS thread_id, aet.mailbox_id, am.address AS mailbox_address, aet.subject,
+				aet.participant_email, aet.assigned_admin_id, $2::text AS target, aet.first_response_due_at AS due_at,
+				p.escalation_action, p.escalation_admin_id, p.auto_assign_mode
+			FROM admin_email_threads aet
+			INNER JOIN admin_mailboxes am ON am.id = aet.mailbox_id
+			INNER JOIN admin_mailbox_sla_policies p ON p.mailbox_id = aet.mailbox_id AND p.is_active
+			WHERE aet.first_response_due_at < $1
+			  AND aet.first_responded_at IS NULL
+			  AND aet.resolved_at IS NULL
+			  AND aet.first_response_breached_at IS NULL
+			UNION ALL
+			SELECT
+				aet.id, aet.mailbox_id, am.address, aet.subject,
+				aet.participant_email, aet.assigned_admin_id, $3::text, aet.resolution_due_at,
+				p.escalation_action, p.escalation_admin_id, p.auto_assign_mode
+			FROM admin_email_threads aet
+			INNER JOIN admin_mailboxes am ON am.id = aet.mailbox_id
+			INNER JOIN admin_mailbox_sla_policies p ON p.mailbox_id = aet.mailbox_id AND p.is_active
+			WHERE aet.resolution_due_at < $1
+			  AND aet.resolved_at IS NULL
+			  AND aet.resolution_breached_at IS NULL
+		) breaches
+		ORDER BY due_at ASC
+		LIMIT $4
+	`, now, domain.MailboxSLAFirstResponse, domain.MailboxSLAResolution, limit)
+	return breaches, err
+}
+
+// MarkThreadSLABreached records a breach once; false means another run
+// already handled it
+func (r *AdminRepository) MarkThreadSLABreached(ctx context.Context, threadID, target string) (bool, error) {
+	column := "first_response_breached_at"
+	if target == domain.MailboxSLAResolution {
+		column = "resolution_breached_at"
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE admin_email_threads
+		SET `+column+` = NOW(), escalation_count = escalation_count + 1, updated_at = NOW()
+		WHERE id = $1 AND `+column+` IS NULL
+	`, threadID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetMailboxSLAMetrics returns each agent's SLA performance on threads
+// started since since, with their current open load
+func (r *AdminRepository) GetMailboxSLAMetrics(ctx context.Context, since time.Time) ([]domain.AdminMailboxSLAMetric, error) {
+	metrics := make([]domain.AdminMailboxSLAMetric, 0)
+	err := r.db.SelectContext(ctx, &metrics, `
+		SELECT
+			au.id AS admin_id,
+			COALESCE(au.full_name, au.email, '') AS admin_name,
+			COUNT(*) FILTER (WHERE aet.resolved_at IS NULL AND aet.status IN ($2, $3)) AS open_threads,
+			COUNT(*) FILTER (
+				WHERE aet.resolved_at IS NULL AND aet.status IN ($2, $3)
+				  AND (aet.first_response_breached_at IS NOT NULL OR aet.resolution_breached_at IS NOT NULL)
+			) AS open_breached,
+			COUNT(*) FILTER (WHERE aet.sla_started_at >= $1 AND aet.first_responded_at IS NOT NULL) AS first_responses,
+			COUNT(*) FILTER (WHERE aet.sla_started_at >= $1 AND aet.first_response_breached_at IS NOT NULL) AS first_response_breached,
+			COALESCE(AVG(EXTRACT(EPOCH FROM aet.first_responded_at - aet.sla_started_at) / 60)
+				FILTER (WHERE aet.sla_started_at >= $1 AND aet.first_responded_at IS NOT NULL), 0) AS avg_first_response_minutes,
+			COUNT(*) FILTER (WHERE aet.sla_started_at >= $1 AND aet.resolved_at IS NOT NULL) AS resolved,
+			COUNT(*) FILTER (WHERE aet.sla_started_at >= $1 AND aet.resolution_breached_at IS NOT NULL) AS resolution_breached,
+			COALESCE(AVG(EXTRACT(EPOCH FROM aet.resolved_at - aet.sla_started_at) / 60)
+				FILTER (WHERE aet.sla_started_at >= $1 AND aet.resolved_at IS NOT NULL), 0) AS avg_resolution_minutes
+		FROM admin_email_threads aet
+		INNER JOIN admin_users au ON au.id = aet.assigned_admin_id
+		WHERE aet.sla_started_at IS NOT NULL
+		  AND (aet.sla_started_at >= $1 OR (aet.resolved_at IS NULL AND aet.status IN ($2, $3)))
+		GROUP BY au.id, au.full_name, au.email
+		ORDER BY open_breached DESC, admin_name ASC
+	`, since, domain.AdminEmailThreadStatusBelumDibalas, domain.AdminEmailThreadStatusDibalas)
+	return metrics, err
+}
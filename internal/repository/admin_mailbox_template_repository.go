@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
+)
+
+const mailboxTemplateColumns = `id, mailbox_id, name, shortcut, subject, body, usage_count, last_used_at,
+	is_active, created_by, updated_by, created_at, updated_at`
+
+// ListMailboxTemplates returns the active templates usable in a mailbox:
+// the global ones and the mailbox's own, most used first. Without a mailbox
+// every active template is returned.
+func (r *AdminRepository) ListMailboxTemplates(ctx context.Context, mailboxID, search string) ([]*domain.AdminMailboxTemplate, error) {
+	query := `SELECT ` + mailboxTemplateColumns + ` FROM admin_mailbox_templates WHERE is_active`
+	args := make([]interface{}, 0, 2)
+	if mailboxID != "" {
+		args = append(args, mailboxID)
+		query += ` AND (mailbox_id IS NULL OR mailbox_id = $1)`
+	}
+	if search = strings.TrimSpace(search); search != "" {
+		args = append(args, "%"+strings.ToLower(search)+"%")
+		placeholder := fmt.Sprintf("$%d", len(args))
+		query += ` AND (LOWER(name) LIKE ` + placeholder + ` OR LOWER(COALESCE(shortcut, '')) LIKE ` + placeholder + ` OR LOWER(body) LIKE ` + placeholder + `)`
+	}
+	query += ` ORDER BY usage_count DESC, name ASC`
+
+	templates := make([]*domain.AdminMailboxTemplate, 0)
+	if err := r.db.SelectContext(ctx, &templates, query, args...); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *AdminRepository) FindMailboxTemplateByID(ctx context.Context, templateID string) (*domain.AdminMailboxTemplate, error) {
+	var template domain.AdminMailboxTemplate
+	err := r.db.GetContext(ctx, &template, `SELECT `+mailboxTemplateColumns+` FROM admin_mailbox_templates WHERE id = $1`, templateID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// FindMailboxTemplateByShortcut returns the active template using a shortcut
+// in the same scope (one mailbox, or global)
+func (r *AdminRepository) FindMailboxTemplateByShortcut(ctx context.Context, mailboxID, shortcut string) (*domain.AdminMailboxTemplate, error) {
+	var template domain.AdminMailboxTemplate
+	err := r.db.GetContext(ctx, &template, `
+		SELECT `+mailboxTemplateColumns+` FROM admin_mailbox_templates
+		WHERE is_active AND COALESCE(mailbox_id, '') = $1 AND shortcut = $2
+		LIMIT 1
+	`, mailboxID, shortcut)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *AdminRepository) CreateMailboxTemplate(ctx context.Context, t *domain.AdminMailboxTemplate) error {
+	if t.ID == "" {
+		t.ID = "amt_" + uuid.New().String()[:8]
+	}
+	return r.db.QueryRowxContext(ctx, `
+		INSERT INTO admin_mailbox_templates (
+			id, mailbox_id, name, shortcut, subject, body, is_active, created_by, updated_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, TRUE, $7, $7, NOW(), NOW())
+		RETURNING is_active, created_at, updated_at
+	`, t.ID, t.MailboxID, t.Name, t.Shortcut, t.Subject, t.Body, t.CreatedBy).Scan(&t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// UpdateMailboxTemplate saves the editable fields; deleting a template
+// deactivates it so its usage history stays
+func (r *AdminRepository) UpdateMailboxTemplate(ctx context.Context, t *domain.AdminMailboxTemplate) error {
+	return r.db.QueryRowxContext(ctx, `
+		UPDATE admin_mailbox_templates
+		SET name = $2, shortcut = $3, subject = $4, body = $5, is_active = $6, updated_by = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, t.ID, t.Name, t.Shortcut, t.Subject, t.Body, t.IsActive, t.UpdatedBy).Scan(&t.UpdatedAt)
+}
+
+// RecordMailboxTemplateUsage counts one insertion of a template
+func (r *AdminRepository) RecordMailboxTemplateUsage(ctx context.Context, templateID, threadID, adminID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO admin_mailbox_template_usages (id, template_id, thread_id, admin_user_id, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, "amtu_"+uuid.New().String()[:8], templateID, nullableString(threadID), nullableString(adminID)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE admin_mailbox_templates
+		SET usage_count = usage_count + 1, last_used_at = NOW()
+		WHERE id = $1
+	`, templateID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindMailboxCustomerByEmail returns the customer registered with an email
+// address, with their latest transaction and deposit
+func (r *AdminRepository) FindMailboxCustomerByEmail(ctx context.Context, email string) (*domain.MailboxCustomer, error) {
+	return r.findMailboxCustomer(ctx, `LOWER(u.email::text) = LOWER($1)`, email)
+}
+
+// FindMailboxCustomerByID is FindMailboxCustomerByEmail for a thread already
+// linked to its customer
+func (r *AdminRepository) FindMailboxCustomerByID(ctx context.Context, userID string) (*domain.MailboxCustomer, error) {
+	return r.findMailboxCustomer(ctx, `u.id = $1`, userID)
+}
+
+func (r *AdminRepository) findMailboxCustomer(ctx context.Context, condition string, arg string) (*domain.MailboxCustomer, error) {
+	var customer domain.MailboxCustomer
+	err := r.db.GetContext(ctx, &customer, `
+		SELECT
+			u.id AS user_id,
+			COALESCE(u.full_name, '') AS full_name,
+			u.phone,
+			(
+				SELECT COALESCE(t.public_id, t.id) FROM transactions t
+				WHERE t.user_id = u.id ORDER BY t.created_at DESC LIMIT 1
+			) AS last_transaction_id,
+			(
+				SELECT d.status FROM deposits d
+				WHERE d.user_id = u.id ORDER BY d.created_at DESC LIMIT 1
+			) AS last_deposit_status
+		FROM users u
+		WHERE `+condition+`
+		LIMIT 1
+	`, arg)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
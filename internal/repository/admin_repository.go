@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/pkg/qris"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
@@ -568,7 +569,11 @@ func (r *AdminRepository) count(ctx context.Context, query string, args ...inter
 }
 
 func (r *AdminRepository) selectMaps(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	return queryMaps(ctx, r.db, query, args...)
+}
+
+func queryMaps(ctx context.Context, q sqlx.QueryerContext, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := q.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -673,6 +678,11 @@ func (r *AdminRepository) GetDashboardSummary(ctx context.Context) (*domain.Admi
 	`); err != nil {
 		return nil, err
 	}
+	mailboxSLA, err := r.GetMailboxSLAMetrics(ctx, time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		return nil, err
+	}
+	resp.MailboxSLA = mailboxSLA
 
 	return &resp, nil
 }
@@ -1151,8 +1161,17 @@ func (r *AdminRepository) CreateVoucher(ctx context.Context, payload map[string]
 }
 
 func (r *AdminRepository) UpdateVoucher(ctx context.Context, voucherID string, payload map[string]interface{}) error {
+	return updateVoucher(ctx, r.db, voucherID, payload)
+}
+
+// UpdateVoucherWithTx updates a voucher within a database transaction
+func (r *AdminRepository) UpdateVoucherWithTx(ctx context.Context, tx *sqlx.Tx, voucherID string, payload map[string]interface{}) error {
+	return updateVoucher(ctx, tx, voucherID, payload)
+}
+
+func updateVoucher(ctx context.Context, exec sqlx.ExecerContext, voucherID string, payload map[string]interface{}) error {
 	applicableServices, _ := json.Marshal(payload["applicableServices"])
-	_, err := r.db.ExecContext(ctx, `
+	_, err := exec.ExecContext(ctx, `
 		UPDATE vouchers
 		SET code = $2, name = $3, description = $4, discount_type = $5, discount_value = $6,
 			min_transaction = $7, max_discount = $8, applicable_services = $9, max_usage = $10,
@@ -1186,7 +1205,16 @@ func (r *AdminRepository) ListServices(ctx context.Context) ([]map[string]interf
 }
 
 func (r *AdminRepository) UpdateService(ctx context.Context, serviceID string, payload map[string]interface{}) error {
-	_, err := r.db.ExecContext(ctx, `
+	return updateService(ctx, r.db, serviceID, payload)
+}
+
+// UpdateServiceWithTx updates a service within a database transaction
+func (r *AdminRepository) UpdateServiceWithTx(ctx context.Context, tx *sqlx.Tx, serviceID string, payload map[string]interface{}) error {
+	return updateService(ctx, tx, serviceID, payload)
+}
+
+func updateService(ctx context.Context, exec sqlx.ExecerContext, serviceID string, payload map[string]interface{}) error {
+	_, err := exec.ExecContext(ctx, `
 		UPDATE services
 		SET name = $2, icon = $3, icon_url = $4, route = $5, status = $6,
 		    badge = $7, sort_order = $8, is_featured = $9, updated_at = NOW()
@@ -1216,7 +1244,7 @@ func (r *AdminRepository) ListProducts(ctx context.Context, search string, page,
 			p.id, p.sku_code, p.name, COALESCE(p.description, '') AS description,
 			COALESCE(p.type, '') AS type, COALESCE(p.category, '') AS category,
 			COALESCE(p.brand, '') AS brand, p.price, p.admin AS admin_fee,
-			p.commission, p.is_active, p.created_at, p.updated_at
+			p.supplier_price, p.supplier_admin, p.commission, p.is_active, p.created_at, p.updated_at
 	` + base + where + `
 		ORDER BY p.updated_at DESC
 		LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2)
@@ -1226,7 +1254,8 @@ func (r *AdminRepository) ListProducts(ctx context.Context, search string, page,
 
 func (r *AdminRepository) FindProductByID(ctx context.Context, productID string) (map[string]interface{}, error) {
 	items, err := r.selectMaps(ctx, `
-		SELECT id, sku_code, name, COALESCE(type, '') AS type, price, admin AS admin_fee, is_active
+		SELECT id, sku_code, name, COALESCE(type, '') AS type, price, admin AS admin_fee,
+		       supplier_price, supplier_admin, is_active
 		FROM products
 		WHERE id = $1 LIMIT 1
 	`, productID)
@@ -1239,16 +1268,9 @@ func (r *AdminRepository) FindProductByID(ctx context.Context, productID string)
 	return items[0], nil
 }
 
-func (r *AdminRepository) UpdateProductPricing(ctx context.Context, productID string, price, adminFee int64) error {
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE products
-		SET price = $2, admin_fee = $3, updated_at = NOW()
-		WHERE id = $1
-	`, productID, price, adminFee)
-	return err
-}
-
-func (r *AdminRepository) ListKYC(ctx context.Context, search, status string, page, perPage int) ([]map[string]interface{}, int, error) {
+// ListKYC lists users for KYC review. Open duplicate identity matches are
+// returned with each row; flaggedOnly keeps only users that have any.
+func (r *AdminRepository) ListKYC(ctx context.Context, search, status string, flaggedOnly bool, page, perPage int) ([]map[string]interface{}, int, error) {
 	base := `
 		FROM users u
 		LEFT JOIN kyc_verifications kv ON kv.user_id = u.id
@@ -1271,6 +1293,11 @@ func (r *AdminRepository) ListKYC(ctx context.Context, search, status string, pa
 		args = append(args, status)
 		argIdx++
 	}
+	if flaggedOnly {
+		whereClauses = append(whereClauses, `EXISTS (
+			SELECT 1 FROM kyc_identity_matches m WHERE m.user_id = u.id AND m.resolved_at IS NULL
+		)`)
+	}
 	where := " WHERE " + strings.Join(whereClauses, " AND ")
 	total, err := r.count(ctx, `SELECT COUNT(*) `+base+where, args...)
 	if err != nil {
@@ -1290,7 +1317,16 @@ func (r *AdminRepository) ListKYC(ctx context.Context, search, status string, pa
 			kv.face_with_ktp_url,
 			kv.liveness_url,
 			kv.verified_at,
-			u.updated_at
+			u.updated_at,
+			COALESCE((
+				SELECT jsonb_agg(jsonb_build_object(
+					'matchedUserId', m.matched_user_id,
+					'matchType', m.match_type,
+					'similarity', m.similarity
+				) ORDER BY m.detected_at DESC)
+				FROM kyc_identity_matches m
+				WHERE m.user_id = u.id AND m.resolved_at IS NULL
+			), '[]'::jsonb) AS duplicate_matches
 	` + base + where + `
 		ORDER BY u.updated_at DESC
 		LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2)
@@ -1328,7 +1364,12 @@ func (r *AdminRepository) GetKYCDetail(ctx context.Context, userID string) (map[
 			COALESCE(kv.liveness_url, '') AS liveness_url,
 			kv.face_similarity,
 			kv.liveness_confidence,
-			kv.verified_at
+			kv.verified_at,
+			COALESCE((
+				SELECT ks.nik_validation FROM kyc_sessions ks
+				WHERE ks.user_id = u.id AND ks.nik_validation IS NOT NULL AND ks.nik_validation <> 'null'::jsonb
+				ORDER BY ks.created_at DESC LIMIT 1
+			), NULLIF(kv.nik_validation, 'null'::jsonb)) AS nik_validation
 		FROM users u
 		LEFT JOIN kyc_verifications kv ON kv.user_id = u.id
 		WHERE u.id = $1
@@ -1350,6 +1391,89 @@ func (r *AdminRepository) UpdateUserKYCStatus(ctx context.Context, userID, statu
 	return err
 }
 
+// RecordKYCDecision sets the user's KYC status, resolves open duplicate
+// identity matches and writes the decision to kyc_history in one transaction
+func (r *AdminRepository) RecordKYCDecision(ctx context.Context, userID, status string, history *domain.KYCHistory) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET kyc_status = $2, updated_at = NOW() WHERE id = $1
+	`, userID, status)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrNotFound("User")
+	}
+
+	// The decision covers any duplicate identity flags raised for the user
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE kyc_identity_matches SET resolved_at = NOW() WHERE user_id = $1 AND resolved_at IS NULL
+	`, userID); err != nil {
+		return err
+	}
+
+	metadata, _ := json.Marshal(history.Metadata)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO kyc_history (user_id, session_id, action, status, metadata, error_message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`, userID, history.SessionID, history.Action, history.Status, metadata, history.ErrorMessage); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListKYCIdentityMatches returns the duplicate identity matches of a user with
+// the colliding accounts, open matches first
+func (r *AdminRepository) ListKYCIdentityMatches(ctx context.Context, userID string) ([]map[string]interface{}, error) {
+	return r.selectMaps(ctx, `
+		SELECT
+			m.id,
+			m.matched_user_id,
+			COALESCE(mu.full_name, '') AS matched_full_name,
+			mu.phone AS matched_phone,
+			mu.kyc_status AS matched_kyc_status,
+			m.match_type,
+			m.similarity,
+			m.detected_at,
+			m.resolved_at
+		FROM kyc_identity_matches m
+		JOIN users mu ON mu.id = m.matched_user_id
+		WHERE m.user_id = $1
+		ORDER BY (m.resolved_at IS NULL) DESC, m.detected_at DESC
+	`, userID)
+}
+
+// ListKYCDecisions returns the approve/reject history of a user, newest first
+func (r *AdminRepository) ListKYCDecisions(ctx context.Context, userID string) ([]map[string]interface{}, error) {
+	return r.selectMaps(ctx, `
+		SELECT id, action, status, COALESCE(metadata, '{}'::jsonb) AS metadata, created_at
+		FROM kyc_history
+		WHERE user_id = $1 AND action IN ($2, $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT 20
+	`, userID, domain.KYCActionVerificationApproved, domain.KYCActionVerificationRejected)
+}
+
+// ListKYCArtefactAccess returns the latest issued and opened artefact links
+// of a user, KYC and KYB alike
+func (r *AdminRepository) ListKYCArtefactAccess(ctx context.Context, userID string) ([]map[string]interface{}, error) {
+	return r.selectMaps(ctx, `
+		SELECT l.id, l.artefact, l.purpose, l.action, COALESCE(l.ip_address, '') AS ip_address,
+			l.admin_user_id, COALESCE(au.full_name, '') AS admin_name, l.created_at
+		FROM kyc_artefact_access_logs l
+		LEFT JOIN admin_users au ON au.id = l.admin_user_id
+		WHERE l.user_id = $1
+		ORDER BY l.created_at DESC
+		LIMIT 50
+	`, userID)
+}
+
 func (r *AdminRepository) HasKYCVerification(ctx context.Context, userID string) (bool, error) {
 	var count int
 	if err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM kyc_verifications WHERE user_id = $1`, userID); err != nil {
@@ -1358,6 +1482,182 @@ func (r *AdminRepository) HasKYCVerification(ctx context.Context, userID string)
 	return count > 0, nil
 }
 
+// ListKYB returns the merchant business verification queue, oldest
+// submission first so reviewers work through it in order
+func (r *AdminRepository) ListKYB(ctx context.Context, search, status string, page, perPage int) ([]map[string]interface{}, int, error) {
+	base := `
+		FROM kyb_submissions k
+		JOIN users u ON u.id = k.user_id
+	`
+	whereClauses := []string{"k.status <> 'draft'"}
+	args := []interface{}{}
+	argIdx := 1
+	if search != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf(`(
+			LOWER(k.business_name) LIKE $%d OR
+			LOWER(COALESCE(u.full_name, '')) LIKE $%d OR
+			LOWER(u.phone) LIKE $%d OR
+			k.license_number LIKE $%d OR
+			k.npwp_number LIKE $%d
+		)`, argIdx, argIdx, argIdx, argIdx, argIdx))
+		args = append(args, "%"+strings.ToLower(strings.TrimSpace(search))+"%")
+		argIdx++
+	}
+	if status != "" && status != "all" {
+		whereClauses = append(whereClauses, fmt.Sprintf("k.status = $%d", argIdx))
+		args = append(args, status)
+		argIdx++
+	}
+	where := " WHERE " + strings.Join(whereClauses, " AND ")
+	total, err := r.count(ctx, `SELECT COUNT(*) `+base+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := `
+		SELECT
+			k.id,
+			k.user_id,
+			COALESCE(u.full_name, '') AS full_name,
+			u.phone,
+			u.kyc_status,
+			COALESCE(u.business_type, '') AS business_type,
+			k.status,
+			k.business_name,
+			k.license_type,
+			k.license_number,
+			k.city_name,
+			k.province_name,
+			k.submitted_at,
+			k.reviewed_at
+	` + base + where + `
+		ORDER BY (k.status = 'pending') DESC, k.submitted_at ASC NULLS LAST
+		LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2)
+	items, err := r.selectMaps(ctx, query, append(args, sanitizePageSize(perPage), calculateOffset(page, perPage))...)
+	return items, total, err
+}
+
+// GetKYBDetail returns a merchant's KYB submission with the owner's KYC data
+func (r *AdminRepository) GetKYBDetail(ctx context.Context, userID string) (map[string]interface{}, error) {
+	items, err := r.selectMaps(ctx, `
+		SELECT
+			k.*,
+			COALESCE(u.full_name, '') AS full_name,
+			u.phone,
+			COALESCE(u.email::text, '') AS email,
+			u.kyc_status,
+			COALESCE(u.business_type, '') AS business_type,
+			COALESCE(kv.nik, '') AS owner_nik,
+			COALESCE(kv.full_name, '') AS owner_kyc_full_name,
+			COALESCE(au.full_name, '') AS reviewed_by_name,
+			qs.merchant_name AS qris_merchant_name
+		FROM kyb_submissions k
+		JOIN users u ON u.id = k.user_id
+		LEFT JOIN kyc_verifications kv ON kv.user_id = k.user_id
+		LEFT JOIN admin_users au ON au.id = k.reviewed_by
+		LEFT JOIN qris_statics qs ON qs.user_id = k.user_id
+		WHERE k.user_id = $1
+		LIMIT 1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items[0], nil
+}
+
+// RecordKYBDecision approves or rejects a pending KYB submission. Approval
+// also puts the business name on the merchant's static QRIS, in the same
+// transaction: the QRIS payload is rewritten and its now stale image URL
+// cleared. It returns nil when the user has no pending submission.
+func (r *AdminRepository) RecordKYBDecision(ctx context.Context, userID, actorID, status string, reason *string) (*domain.KYBSubmission, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var submission domain.KYBSubmission
+	err = tx.GetContext(ctx, &submission, `
+		UPDATE kyb_submissions
+		SET status = $2, rejection_reason = $3, reviewed_by = NULLIF($4, ''), reviewed_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND status = 'pending'
+		RETURNING `+kybColumns, userID, status, reason, actorID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == domain.KYBStatusApproved {
+		if err := renameQRISStatic(ctx, tx, userID, submission.QRISMerchantName(), submission.QRISMerchantCity()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// renameQRISStatic puts a merchant name and city on the user's static QRIS,
+// if the user has one and it carries a different name
+func renameQRISStatic(ctx context.Context, tx *sqlx.Tx, userID, name, city string) error {
+	var static struct {
+		Code string         `db:"qris_code"`
+		Name string         `db:"merchant_name"`
+		City sql.NullString `db:"merchant_city"`
+	}
+	err := tx.GetContext(ctx, &static, `
+		SELECT qris_code, merchant_name, merchant_city FROM qris_statics WHERE user_id = $1 FOR UPDATE
+	`, userID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if static.Name == name && static.City.String == city {
+		return nil
+	}
+
+	code, err := qris.SetMerchant(static.Code, name, city)
+	if err != nil {
+		return fmt.Errorf("failed to rename static qris: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE qris_statics
+		SET merchant_name = $2, merchant_city = $3, qris_code = $4, qris_image_url = NULL, updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, name, city, code)
+	return err
+}
+
+// FindQRISStaticWithoutImage returns the payload of the user's static QRIS
+// when it has no image, or "" when there is nothing to render
+func (r *AdminRepository) FindQRISStaticWithoutImage(ctx context.Context, userID string) (string, error) {
+	var code string
+	err := r.db.GetContext(ctx, &code, `
+		SELECT qris_code FROM qris_statics WHERE user_id = $1 AND qris_image_url IS NULL
+	`, userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return code, err
+}
+
+// SetQRISStaticImage stores the image rendered from a static QRIS payload,
+// unless the payload changed again meanwhile
+func (r *AdminRepository) SetQRISStaticImage(ctx context.Context, userID, code, imageURL string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE qris_statics SET qris_image_url = $3, updated_at = NOW() WHERE user_id = $1 AND qris_code = $2
+	`, userID, code, imageURL)
+	return err
+}
+
 func (r *AdminRepository) ListBanners(ctx context.Context) ([]map[string]interface{}, error) {
 	return r.selectMaps(ctx, `
 		SELECT id, title, subtitle, image_url, thumbnail_url, action_type, action_value,
@@ -1385,8 +1685,17 @@ func (r *AdminRepository) CreateBanner(ctx context.Context, payload map[string]i
 }
 
 func (r *AdminRepository) UpdateBanner(ctx context.Context, bannerID string, payload map[string]interface{}) error {
+	return updateBanner(ctx, r.db, bannerID, payload)
+}
+
+// UpdateBannerWithTx updates a banner within a database transaction
+func (r *AdminRepository) UpdateBannerWithTx(ctx context.Context, tx *sqlx.Tx, bannerID string, payload map[string]interface{}) error {
+	return updateBanner(ctx, tx, bannerID, payload)
+}
+
+func updateBanner(ctx context.Context, exec sqlx.ExecerContext, bannerID string, payload map[string]interface{}) error {
 	targetTiers, _ := json.Marshal(payload["targetTiers"])
-	_, err := r.db.ExecContext(ctx, `
+	_, err := exec.ExecContext(ctx, `
 		UPDATE banners
 		SET title = $2, subtitle = $3, image_url = $4, thumbnail_url = $5, action_type = $6,
 			action_value = $7, background_color = $8, text_color = $9, placement = $10,
@@ -1407,6 +1716,235 @@ func (r *AdminRepository) DeleteBanner(ctx context.Context, bannerID string) err
 	return err
 }
 
+const servicePayloadQuery = `
+	SELECT name, icon, icon_url, route, status, badge, sort_order, is_featured
+	FROM services
+	WHERE id = $1
+`
+
+const bannerPayloadQuery = `
+	SELECT title, subtitle, image_url AS "imageUrl", thumbnail_url AS "thumbnailUrl",
+	       action_type AS "actionType", action_value AS "actionValue",
+	       background_color AS "backgroundColor", text_color AS "textColor", placement,
+	       start_date AS "startDate", end_date AS "endDate", priority, target_tiers AS "targetTiers",
+	       is_new_user_only AS "isNewUserOnly", is_active AS "isActive"
+	FROM banners
+	WHERE id = $1
+`
+
+const voucherPayloadQuery = `
+	SELECT code, name, description, discount_type AS "discountType", discount_value AS "discountValue",
+	       min_transaction AS "minTransaction", max_discount AS "maxDiscount",
+	       applicable_services AS "applicableServices", max_usage AS "maxUsage",
+	       max_usage_per_user AS "maxUsagePerUser", terms_url AS "termsUrl",
+	       starts_at AS "startsAt", expires_at AS "expiresAt", is_active AS "isActive"
+	FROM vouchers
+	WHERE id = $1
+`
+
+// GetServicePayload returns a service in the shape UpdateService expects
+func (r *AdminRepository) GetServicePayload(ctx context.Context, serviceID string) (map[string]interface{}, error) {
+	return firstMap(ctx, r.db, servicePayloadQuery, serviceID)
+}
+
+// GetServicePayloadForUpdate returns a service like GetServicePayload and
+// locks it until the transaction ends
+func (r *AdminRepository) GetServicePayloadForUpdate(ctx context.Context, tx *sqlx.Tx, serviceID string) (map[string]interface{}, error) {
+	return firstMap(ctx, tx, servicePayloadQuery+` FOR UPDATE`, serviceID)
+}
+
+// GetBannerPayload returns a banner in the shape UpdateBanner expects
+func (r *AdminRepository) GetBannerPayload(ctx context.Context, bannerID string) (map[string]interface{}, error) {
+	return firstMap(ctx, r.db, bannerPayloadQuery, bannerID)
+}
+
+// GetBannerPayloadForUpdate returns a banner like GetBannerPayload and locks
+// it until the transaction ends
+func (r *AdminRepository) GetBannerPayloadForUpdate(ctx context.Context, tx *sqlx.Tx, bannerID string) (map[string]interface{}, error) {
+	return firstMap(ctx, tx, bannerPayloadQuery+` FOR UPDATE`, bannerID)
+}
+
+// GetVoucherPayload returns a voucher in the shape UpdateVoucher expects
+func (r *AdminRepository) GetVoucherPayload(ctx context.Context, voucherID string) (map[string]interface{}, error) {
+	return firstMap(ctx, r.db, voucherPayloadQuery, voucherID)
+}
+
+// GetVoucherPayloadForUpdate returns a voucher like GetVoucherPayload and
+// locks it until the transaction ends
+func (r *AdminRepository) GetVoucherPayloadForUpdate(ctx context.Context, tx *sqlx.Tx, voucherID string) (map[string]interface{}, error) {
+	return firstMap(ctx, tx, voucherPayloadQuery+` FOR UPDATE`, voucherID)
+}
+
+func firstMap(ctx context.Context, q sqlx.QueryerContext, query string, args ...interface{}) (map[string]interface{}, error) {
+	items, err := queryMaps(ctx, q, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items[0], nil
+}
+
+const scheduledChangeColumns = `
+	id, resource_type, resource_id, payload, previous_payload, applied_payload, effective_at, revert_at, status,
+	notes, error_message, applied_at, reverted_at, created_by, created_at, updated_at
+`
+
+func (r *AdminRepository) CreateScheduledChange(ctx context.Context, change *domain.ScheduledChange) error {
+	payload, _ := json.Marshal(change.Payload)
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO scheduled_changes (
+			id, resource_type, resource_id, payload, effective_at, revert_at, status, notes, created_by, created_at, updated_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NOW(),NOW())
+	`,
+		change.ID, change.ResourceType, change.ResourceID, payload, change.EffectiveAt, change.RevertAt,
+		change.Status, change.Notes, change.CreatedBy,
+	)
+	return err
+}
+
+func (r *AdminRepository) ListScheduledChanges(ctx context.Context, resourceType, status string, page, perPage int) ([]*domain.ScheduledChange, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if resourceType != "" {
+		args = append(args, resourceType)
+		where += fmt.Sprintf(" AND resource_type = $%d", len(args))
+	}
+	if status != "" && status != "all" {
+		args = append(args, status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	total, err := r.count(ctx, `SELECT COUNT(*) FROM scheduled_changes`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := `SELECT ` + scheduledChangeColumns + ` FROM scheduled_changes` + where +
+		fmt.Sprintf(" ORDER BY effective_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	changes, err := r.queryScheduledChanges(ctx, query, append(args, sanitizePageSize(perPage), calculateOffset(page, perPage))...)
+	return changes, total, err
+}
+
+func (r *AdminRepository) FindScheduledChangeByID(ctx context.Context, id string) (*domain.ScheduledChange, error) {
+	changes, err := r.queryScheduledChanges(ctx, `SELECT `+scheduledChangeColumns+` FROM scheduled_changes WHERE id = $1`, id)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	return changes[0], nil
+}
+
+// FindDueScheduledChanges returns scheduled changes whose effective_at passed, oldest first
+func (r *AdminRepository) FindDueScheduledChanges(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledChange, error) {
+	return r.queryScheduledChanges(ctx, `
+		SELECT `+scheduledChangeColumns+` FROM scheduled_changes
+		WHERE status = $1 AND effective_at <= $2
+		ORDER BY effective_at ASC
+		LIMIT $3
+	`, domain.ScheduledChangeScheduled, now, limit)
+}
+
+// FindDueScheduledReverts returns applied changes whose revert_at passed, oldest first
+func (r *AdminRepository) FindDueScheduledReverts(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledChange, error) {
+	return r.queryScheduledChanges(ctx, `
+		SELECT `+scheduledChangeColumns+` FROM scheduled_changes
+		WHERE status = $1 AND revert_at IS NOT NULL AND revert_at <= $2
+		ORDER BY revert_at ASC
+		LIMIT $3
+	`, domain.ScheduledChangeApplied, now, limit)
+}
+
+// ClaimScheduledChangeApplyWithTx marks a scheduled change applied and stores
+// the snapshot to revert to. It returns false when another worker claimed it first.
+func (r *AdminRepository) ClaimScheduledChangeApplyWithTx(ctx context.Context, tx *sqlx.Tx, id string, previousPayload map[string]interface{}) (bool, error) {
+	previous, _ := json.Marshal(previousPayload)
+	result, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_changes
+		SET status = $2, previous_payload = $3, applied_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $4
+	`, id, domain.ScheduledChangeApplied, previous, domain.ScheduledChangeScheduled)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// SetScheduledChangeAppliedPayloadWithTx stores the changed fields as written
+// by the apply, for the revert to compare against
+func (r *AdminRepository) SetScheduledChangeAppliedPayloadWithTx(ctx context.Context, tx *sqlx.Tx, id string, appliedPayload map[string]interface{}) error {
+	applied, _ := json.Marshal(appliedPayload)
+	_, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_changes SET applied_payload = $2, updated_at = NOW() WHERE id = $1
+	`, id, applied)
+	return err
+}
+
+// ClaimScheduledChangeRevertWithTx marks an applied change reverted. It
+// returns false when another worker claimed it first.
+func (r *AdminRepository) ClaimScheduledChangeRevertWithTx(ctx context.Context, tx *sqlx.Tx, id string) (bool, error) {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_changes
+		SET status = $2, reverted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $3
+	`, id, domain.ScheduledChangeReverted, domain.ScheduledChangeApplied)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// CancelScheduledChange cancels a change that has not been applied yet
+func (r *AdminRepository) CancelScheduledChange(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE scheduled_changes SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3
+	`, id, domain.ScheduledChangeCancelled, domain.ScheduledChangeScheduled)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+func (r *AdminRepository) MarkScheduledChangeFailed(ctx context.Context, id, message string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE scheduled_changes SET status = $2, error_message = $3, updated_at = NOW() WHERE id = $1
+	`, id, domain.ScheduledChangeFailed, message)
+	return err
+}
+
+func (r *AdminRepository) queryScheduledChanges(ctx context.Context, query string, args ...interface{}) ([]*domain.ScheduledChange, error) {
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]*domain.ScheduledChange, 0)
+	for rows.Next() {
+		var change domain.ScheduledChange
+		var payload, previous, applied []byte
+		if err := rows.Scan(
+			&change.ID, &change.ResourceType, &change.ResourceID, &payload, &previous, &applied, &change.EffectiveAt,
+			&change.RevertAt, &change.Status, &change.Notes, &change.ErrorMessage, &change.AppliedAt,
+			&change.RevertedAt, &change.CreatedBy, &change.CreatedAt, &change.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			_ = json.Unmarshal(payload, &change.Payload)
+		}
+		if len(previous) > 0 {
+			_ = json.Unmarshal(previous, &change.PreviousPayload)
+		}
+		if len(applied) > 0 {
+			_ = json.Unmarshal(applied, &change.AppliedPayload)
+		}
+		changes = append(changes, &change)
+	}
+	return changes, rows.Err()
+}
+
 func (r *AdminRepository) ListNotifications(ctx context.Context, search string, page, perPage int) ([]map[string]interface{}, int, error) {
 	base := `
 		FROM notifications n
@@ -1509,6 +2047,8 @@ func (r *AdminRepository) ListApprovalRequests(ctx context.Context, status strin
 		SELECT
 			aar.id, aar.request_type, aar.resource_type, aar.resource_id, aar.reason,
 			aar.payload, aar.status, aar.rejection_reason, aar.decided_at, aar.executed_at, aar.created_at,
+			aar.amount, aar.required_approvals, aar.approver_role_id, aar.expires_at,
+			(SELECT COUNT(*) FROM admin_approval_decisions aad WHERE aad.approval_request_id = aar.id) AS approvals,
 			COALESCE(requester.full_name, requester.email) AS requester_name,
 			COALESCE(approver.full_name, approver.email) AS approver_name
 	` + base + where + `
@@ -1523,27 +2063,52 @@ func (r *AdminRepository) CreateApprovalRequest(ctx context.Context, req *domain
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO admin_approval_requests (
 			id, requester_id, approver_id, request_type, resource_type, resource_id,
-			reason, payload, status, rejection_reason, decided_at, executed_at, created_at
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+			reason, payload, status, rejection_reason, decided_at, executed_at, created_at,
+			amount, policy_id, required_approvals, approver_role_id, expires_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)
 	`,
 		req.ID, req.RequesterID, req.ApproverID, req.RequestType, req.ResourceType, req.ResourceID,
 		req.Reason, payload, req.Status, req.RejectionReason, req.DecidedAt, req.ExecutedAt, req.CreatedAt,
+		req.Amount, req.PolicyID, req.RequiredApprovals, req.ApproverRoleID, req.ExpiresAt,
 	)
 	return err
 }
 
+const approvalRequestColumns = `
+	id, requester_id, approver_id, request_type, resource_type, resource_id,
+	reason, payload, status, rejection_reason, decided_at, executed_at, created_at,
+	amount, policy_id, required_approvals, approver_role_id, expires_at,
+	(SELECT COUNT(*) FROM admin_approval_decisions aad WHERE aad.approval_request_id = admin_approval_requests.id) AS approvals
+`
+
 func (r *AdminRepository) FindApprovalRequestByID(ctx context.Context, approvalID string) (*domain.AdminApprovalRequest, error) {
-	var result domain.AdminApprovalRequest
-	var payloadBytes []byte
-	err := r.db.QueryRowxContext(ctx, `
-		SELECT id, requester_id, approver_id, request_type, resource_type, resource_id,
-		       reason, payload, status, rejection_reason, decided_at, executed_at, created_at
+	return scanApprovalRequest(r.db.QueryRowxContext(ctx, `
+		SELECT `+approvalRequestColumns+`
+		FROM admin_approval_requests
+		WHERE id = $1
+	`, approvalID))
+}
+
+// FindApprovalRequestForUpdate locks an approval request so concurrent
+// decisions on it are serialized
+func (r *AdminRepository) FindApprovalRequestForUpdate(ctx context.Context, tx *sqlx.Tx, approvalID string) (*domain.AdminApprovalRequest, error) {
+	return scanApprovalRequest(tx.QueryRowxContext(ctx, `
+		SELECT `+approvalRequestColumns+`
 		FROM admin_approval_requests
 		WHERE id = $1
-	`, approvalID).Scan(
+		FOR UPDATE
+	`, approvalID))
+}
+
+func scanApprovalRequest(row *sqlx.Row) (*domain.AdminApprovalRequest, error) {
+	var result domain.AdminApprovalRequest
+	var payloadBytes []byte
+	err := row.Scan(
 		&result.ID, &result.RequesterID, &result.ApproverID, &result.RequestType, &result.ResourceType,
 		&result.ResourceID, &result.Reason, &payloadBytes, &result.Status, &result.RejectionReason,
 		&result.DecidedAt, &result.ExecutedAt, &result.CreatedAt,
+		&result.Amount, &result.PolicyID, &result.RequiredApprovals, &result.ApproverRoleID, &result.ExpiresAt,
+		&result.Approvals,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1559,8 +2124,10 @@ func (r *AdminRepository) FindApprovalRequestByID(ctx context.Context, approvalI
 	return &result, nil
 }
 
-func (r *AdminRepository) UpdateApprovalDecision(ctx context.Context, approvalID, approverID, status string, rejectionReason *string) error {
-	_, err := r.db.ExecContext(ctx, `
+// UpdateApprovalDecisionWithTx closes a pending request as approved, rejected,
+// cancelled or expired
+func (r *AdminRepository) UpdateApprovalDecisionWithTx(ctx context.Context, tx *sqlx.Tx, approvalID string, approverID *string, status string, rejectionReason *string) error {
+	_, err := tx.ExecContext(ctx, `
 		UPDATE admin_approval_requests
 		SET approver_id = $2, status = $3, rejection_reason = $4, decided_at = NOW()
 		WHERE id = $1
@@ -1568,8 +2135,8 @@ func (r *AdminRepository) UpdateApprovalDecision(ctx context.Context, approvalID
 	return err
 }
 
-func (r *AdminRepository) MarkApprovalApplied(ctx context.Context, approvalID string) error {
-	_, err := r.db.ExecContext(ctx, `
+func (r *AdminRepository) MarkApprovalAppliedWithTx(ctx context.Context, tx *sqlx.Tx, approvalID string) error {
+	_, err := tx.ExecContext(ctx, `
 		UPDATE admin_approval_requests
 		SET status = $2, executed_at = NOW()
 		WHERE id = $1
@@ -1585,6 +2152,97 @@ func (r *AdminRepository) AddApprovalEvent(ctx context.Context, approvalRequestI
 	return err
 }
 
+func (r *AdminRepository) AddApprovalEventWithTx(ctx context.Context, tx *sqlx.Tx, approvalRequestID string, actorID *string, action, notes string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO admin_approval_events (id, approval_request_id, actor_id, action, notes, created_at)
+		VALUES ($1,$2,$3,$4,$5,NOW())
+	`, "ape_"+uuid.New().String()[:8], approvalRequestID, actorID, action, notes)
+	return err
+}
+
+// AddApprovalDecisionWithTx records one approver's approval. An admin approving
+// the same request twice violates the primary key.
+func (r *AdminRepository) AddApprovalDecisionWithTx(ctx context.Context, tx *sqlx.Tx, approvalRequestID, approverID string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO admin_approval_decisions (approval_request_id, approver_id, created_at)
+		VALUES ($1,$2,NOW())
+	`, approvalRequestID, approverID)
+	return err
+}
+
+func (r *AdminRepository) CountApprovalDecisionsWithTx(ctx context.Context, tx *sqlx.Tx, approvalRequestID string) (int, error) {
+	var total int
+	if err := tx.GetContext(ctx, &total, `SELECT COUNT(*) FROM admin_approval_decisions WHERE approval_request_id = $1`, approvalRequestID); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ExpireApprovalRequests closes pending requests past their expiry and logs an
+// expired event for each. Returns the IDs of the expired requests.
+func (r *AdminRepository) ExpireApprovalRequests(ctx context.Context, now time.Time) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var ids []string
+	if err := tx.SelectContext(ctx, &ids, `
+		UPDATE admin_approval_requests
+		SET status = $1, decided_at = NOW()
+		WHERE status = $2 AND expires_at IS NOT NULL AND expires_at <= $3
+		RETURNING id
+	`, domain.ApprovalStatusExpired, domain.ApprovalStatusPending, now); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := r.AddApprovalEventWithTx(ctx, tx, id, nil, domain.ApprovalStatusExpired, ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AdminHasRole reports whether an admin holds a role
+func (r *AdminRepository) AdminHasRole(ctx context.Context, adminID, roleID string) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `
+		SELECT EXISTS (SELECT 1 FROM admin_user_roles WHERE admin_user_id = $1 AND role_id = $2)
+	`, adminID, roleID)
+	return exists, err
+}
+
+func (r *AdminRepository) ListApprovalPolicies(ctx context.Context) ([]*domain.AdminApprovalPolicy, error) {
+	policies := []*domain.AdminApprovalPolicy{}
+	err := r.db.SelectContext(ctx, &policies, `
+		SELECT id, request_type, min_amount, required_approvals, approver_role_id, expiry_hours, is_active, created_at, updated_at
+		FROM admin_approval_policies
+		ORDER BY request_type ASC, min_amount ASC
+	`)
+	return policies, err
+}
+
+func (r *AdminRepository) UpsertApprovalPolicy(ctx context.Context, policy *domain.AdminApprovalPolicy) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO admin_approval_policies (
+			id, request_type, min_amount, required_approvals, approver_role_id, expiry_hours, is_active, created_at, updated_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,NOW(),NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			request_type = EXCLUDED.request_type,
+			min_amount = EXCLUDED.min_amount,
+			required_approvals = EXCLUDED.required_approvals,
+			approver_role_id = EXCLUDED.approver_role_id,
+			expiry_hours = EXCLUDED.expiry_hours,
+			is_active = EXCLUDED.is_active,
+			updated_at = NOW()
+	`, policy.ID, policy.RequestType, policy.MinAmount, policy.RequiredApprovals, policy.ApproverRoleID, policy.ExpiryHours, policy.IsActive)
+	return err
+}
+
 func (r *AdminRepository) ListAuditLogs(ctx context.Context, page, perPage int) ([]map[string]interface{}, int, error) {
 	base := `
 		FROM admin_audit_logs aal
@@ -1643,7 +2301,7 @@ func (r *AdminRepository) ListReferenceData(ctx context.Context) (map[string]int
 	if err != nil {
 		return nil, err
 	}
-	provinces, err := r.selectMaps(ctx, `SELECT code, name FROM provinces ORDER BY name ASC LIMIT 100`)
+	provinces, err := r.selectMaps(ctx, `SELECT code, name FROM provinces WHERE deleted_at IS NULL ORDER BY name ASC LIMIT 100`)
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -20,6 +22,18 @@ type ContactRepository interface {
 	Update(ctx context.Context, contact *domain.Contact) error
 	Delete(ctx context.Context, id string) error
 	IncrementUsage(ctx context.Context, id string) error
+
+	// Transaction usage tracking
+	Upsert(ctx context.Context, contact *domain.Contact) error
+	FindRecentByType(ctx context.Context, userID, contactType string, limit int) ([]*domain.Contact, error)
+	FindFrequentByType(ctx context.Context, userID, contactType string, limit int) ([]*domain.Contact, error)
+
+	// Bulk import
+	BulkCreate(ctx context.Context, contacts []*domain.Contact) ([]bool, error)
+	CreateImportJob(ctx context.Context, job *domain.ContactImportJob) error
+	UpdateImportJob(ctx context.Context, job *domain.ContactImportJob) error
+	FindImportJobByUserAndID(ctx context.Context, userID, jobID string) (*domain.ContactImportJob, error)
+	FailStaleImportJobs(ctx context.Context, startedBefore time.Time, message string) (int64, error)
 }
 
 // ContactFilter represents filter options for listing contacts
@@ -39,181 +53,273 @@ func NewContactRepository(db *sqlx.DB) ContactRepository {
 	return &contactRepository{db: db}
 }
 
+// Column constants for explicit SELECT
+const contactColumns = `id, user_id, name, type, value, metadata, last_used_at,
+	COALESCE(usage_count, 0) AS usage_count, created_at, updated_at`
+
+const contactImportJobColumns = `id, user_id, status, format, file_name, dry_run, total_rows,
+	created_count, duplicate_count, invalid_count, results, error_message,
+	completed_at, created_at, updated_at`
+
 // FindByID finds a contact by ID
 func (r *contactRepository) FindByID(ctx context.Context, id string) (*domain.Contact, error) {
-	// For now, use mock data
-	// In production, query database
-	contacts := r.getMockContacts()
-	for _, c := range contacts {
-		if c.ID == id {
-			return c, nil
-		}
+	var contact domain.Contact
+	query := fmt.Sprintf(`SELECT %s FROM contacts WHERE id = $1`, contactColumns)
+	err := r.db.GetContext(ctx, &contact, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return &contact, nil
 }
 
 // FindByUserID finds contacts by user ID with optional filters
 func (r *contactRepository) FindByUserID(ctx context.Context, userID string, filter ContactFilter) ([]*domain.Contact, error) {
-	// For now, return mock contacts
-	// In production, query database with filters
-	contacts := r.getMockContacts()
-
-	// Apply filters
-	result := []*domain.Contact{}
-	for _, c := range contacts {
-		if c.UserID != userID {
-			continue
-		}
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+	argIndex := 2
 
-		// Filter by type
-		if filter.Type != "" && filter.Type != "all" && c.Type != filter.Type {
-			continue
-		}
+	if filter.Type != "" && filter.Type != "all" {
+		conditions = append(conditions, fmt.Sprintf("type = $%d", argIndex))
+		args = append(args, filter.Type)
+		argIndex++
+	}
 
-		// Filter by search (name or value)
-		if filter.Search != "" {
-			// Simple contains check
-			// In production, use proper SQL LIKE or full-text search
-			continue // Skip search for mock
-		}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR value ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+search+"%")
+		argIndex++
+	}
 
-		result = append(result, c)
+	query := fmt.Sprintf(`
+		SELECT %s FROM contacts
+		WHERE %s
+		ORDER BY last_used_at DESC NULLS LAST, created_at DESC
+	`, contactColumns, strings.Join(conditions, " AND "))
 
-		// Apply limit
-		if filter.Limit > 0 && len(result) >= filter.Limit {
-			break
-		}
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
 	}
 
-	return result, nil
+	var contacts []*domain.Contact
+	if err := r.db.SelectContext(ctx, &contacts, query, args...); err != nil {
+		return nil, err
+	}
+	return contacts, nil
 }
 
 // FindByUserAndID finds a contact by user ID and contact ID (ownership validation)
 func (r *contactRepository) FindByUserAndID(ctx context.Context, userID, contactID string) (*domain.Contact, error) {
-	contacts := r.getMockContacts()
-	for _, c := range contacts {
-		if c.ID == contactID && c.UserID == userID {
-			return c, nil
-		}
+	var contact domain.Contact
+	query := fmt.Sprintf(`SELECT %s FROM contacts WHERE id = $1 AND user_id = $2`, contactColumns)
+	err := r.db.GetContext(ctx, &contact, query, contactID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return &contact, nil
 }
 
 // FindByUserAndValue finds a contact by user ID, type, and value (for duplicate check)
 func (r *contactRepository) FindByUserAndValue(ctx context.Context, userID, contactType, value string) (*domain.Contact, error) {
-	contacts := r.getMockContacts()
-	for _, c := range contacts {
-		if c.UserID == userID && c.Type == contactType && c.Value == value {
-			return c, nil
-		}
+	var contact domain.Contact
+	query := fmt.Sprintf(`SELECT %s FROM contacts WHERE user_id = $1 AND type = $2 AND value = $3`, contactColumns)
+	err := r.db.GetContext(ctx, &contact, query, userID, contactType, value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return &contact, nil
 }
 
 // Create creates a new contact
 func (r *contactRepository) Create(ctx context.Context, contact *domain.Contact) error {
-	// In production, insert into database
-	// For now, just return success
-	return nil
+	query := `
+		INSERT INTO contacts (id, user_id, name, type, value, metadata, last_used_at, usage_count, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :type, :value, :metadata, :last_used_at, :usage_count, :created_at, :updated_at)
+	`
+	_, err := r.db.NamedExecContext(ctx, query, contact)
+	return err
 }
 
 // Update updates a contact
 func (r *contactRepository) Update(ctx context.Context, contact *domain.Contact) error {
-	// In production, update database
-	// For now, just return success
-	return nil
+	query := `
+		UPDATE contacts SET
+			name = :name,
+			metadata = :metadata,
+			updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id
+	`
+	_, err := r.db.NamedExecContext(ctx, query, contact)
+	return err
 }
 
 // Delete deletes a contact
 func (r *contactRepository) Delete(ctx context.Context, id string) error {
-	// In production, delete from database
-	// For now, just return success
-	return nil
+	_, err := r.db.ExecContext(ctx, `DELETE FROM contacts WHERE id = $1`, id)
+	return err
 }
 
 // IncrementUsage increments usage count and updates last used time
 func (r *contactRepository) IncrementUsage(ctx context.Context, id string) error {
-	// In production, update database
-	// For now, just return success
-	return nil
-}
-
-// getMockContacts returns mock contacts for testing
-func (r *contactRepository) getMockContacts() []*domain.Contact {
-	now := time.Now()
-	lastUsed1 := now.Add(-24 * time.Hour)
-	lastUsed2 := now.Add(-72 * time.Hour)
-	lastUsed3 := now.Add(-168 * time.Hour)
-	lastUsed4 := now.Add(-240 * time.Hour)
-
-	// Mock metadata
-	phoneMetadata, _ := json.Marshal(map[string]string{
-		"operator":     "telkomsel",
-		"operatorName": "Telkomsel",
-	})
-
-	plnMetadata, _ := json.Marshal(map[string]string{
-		"customerName": "BUDI SANTOSO",
-	})
-
-	bankMetadata, _ := json.Marshal(map[string]string{
-		"bankCode":    "014",
-		"bankName":    "BCA",
-		"accountName": "BUDI SANTOSO",
-	})
-
-	phoneMetadataStr := string(phoneMetadata)
-	plnMetadataStr := string(plnMetadata)
-	bankMetadataStr := string(bankMetadata)
-
-	return []*domain.Contact{
-		{
-			ID:         "cnt_abc123",
-			UserID:     "mock_user_id", // Will be replaced with actual user ID
-			Name:       "Ayah",
-			Type:       domain.ContactTypePhone,
-			Value:      "081234567890", // 12 digits - valid format
-			Metadata:   &phoneMetadataStr,
-			LastUsedAt: &lastUsed1,
-			UsageCount: 5,
-			CreatedAt:  now.AddDate(0, -1, 0),
-			UpdatedAt:  now,
-		},
-		{
-			ID:         "cnt_xyz789",
-			UserID:     "mock_user_id",
-			Name:       "Ibu",
-			Type:       domain.ContactTypePhone,
-			Value:      "081298765432", // 12 digits - valid format
-			Metadata:   &phoneMetadataStr,
-			LastUsedAt: &lastUsed2,
-			UsageCount: 3,
-			CreatedAt:  now.AddDate(0, -1, 0),
-			UpdatedAt:  now,
-		},
-		{
-			ID:         "cnt_pln123",
-			UserID:     "mock_user_id",
-			Name:       "Rumah",
-			Type:       domain.ContactTypePLN,
-			Value:      "123456789012", // 12 digits - valid PLN meter format
-			Metadata:   &plnMetadataStr,
-			LastUsedAt: &lastUsed3,
-			UsageCount: 2,
-			CreatedAt:  now.AddDate(0, 0, -15),
-			UpdatedAt:  now,
-		},
-		{
-			ID:         "cnt_bank123",
-			UserID:     "mock_user_id",
-			Name:       "Budi BCA",
-			Type:       domain.ContactTypeBank,
-			Value:      "1234567890", // 10 digits - valid bank account format
-			Metadata:   &bankMetadataStr,
-			LastUsedAt: &lastUsed4,
-			UsageCount: 1,
-			CreatedAt:  now.AddDate(0, 0, -10),
-			UpdatedAt:  now,
-		},
+	query := `
+		UPDATE contacts
+		SET usage_count = COALESCE(usage_count, 0) + 1, last_used_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// Upsert inserts a contact or, when the (user, type, value) target already exists,
+// bumps its usage and merges the new metadata over the stored one
+func (r *contactRepository) Upsert(ctx context.Context, contact *domain.Contact) error {
+	query := `
+		INSERT INTO contacts (id, user_id, name, type, value, metadata, last_used_at, usage_count, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :type, :value, :metadata, :last_used_at, :usage_count, :created_at, :updated_at)
+		ON CONFLICT (user_id, type, value) DO UPDATE SET
+			metadata = COALESCE(contacts.metadata, '{}'::jsonb) || COALESCE(EXCLUDED.metadata, '{}'::jsonb),
+			last_used_at = EXCLUDED.last_used_at,
+			usage_count = COALESCE(contacts.usage_count, 0) + 1,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.NamedExecContext(ctx, query, contact)
+	return err
+}
+
+// FindRecentByType finds the most recently used contacts of a type
+func (r *contactRepository) FindRecentByType(ctx context.Context, userID, contactType string, limit int) ([]*domain.Contact, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM contacts
+		WHERE user_id = $1 AND type = $2 AND last_used_at IS NOT NULL
+		ORDER BY last_used_at DESC
+		LIMIT $3
+	`, contactColumns)
+
+	var contacts []*domain.Contact
+	if err := r.db.SelectContext(ctx, &contacts, query, userID, contactType, limit); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// FindFrequentByType finds the most frequently used contacts of a type
+func (r *contactRepository) FindFrequentByType(ctx context.Context, userID, contactType string, limit int) ([]*domain.Contact, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM contacts
+		WHERE user_id = $1 AND type = $2 AND usage_count > 0
+		ORDER BY usage_count DESC, last_used_at DESC NULLS LAST
+		LIMIT $3
+	`, contactColumns)
+
+	var contacts []*domain.Contact
+	if err := r.db.SelectContext(ctx, &contacts, query, userID, contactType, limit); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// BulkCreate inserts contacts in a single transaction, skipping targets the user
+// already has. The returned slice reports, per contact, whether it was inserted.
+func (r *contactRepository) BulkCreate(ctx context.Context, contacts []*domain.Contact) ([]bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO contacts (id, user_id, name, type, value, metadata, last_used_at, usage_count, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :type, :value, :metadata, :last_used_at, :usage_count, :created_at, :updated_at)
+		ON CONFLICT (user_id, type, value) DO NOTHING
+	`
+	inserted := make([]bool, len(contacts))
+	for i, contact := range contacts {
+		result, err := tx.NamedExecContext(ctx, query, contact)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		inserted[i] = affected > 0
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+// CreateImportJob creates a contact import job record
+func (r *contactRepository) CreateImportJob(ctx context.Context, job *domain.ContactImportJob) error {
+	query := `
+		INSERT INTO contact_import_jobs (
+			id, user_id, status, format, file_name, dry_run, total_rows,
+			created_count, duplicate_count, invalid_count, results, error_message,
+			completed_at, created_at, updated_at
+		) VALUES (
+			:id, :user_id, :status, :format, :file_name, :dry_run, :total_rows,
+			:created_count, :duplicate_count, :invalid_count, :results, :error_message,
+			:completed_at, :created_at, :updated_at
+		)
+	`
+	_, err := r.db.NamedExecContext(ctx, query, job)
+	return err
+}
+
+// UpdateImportJob updates the progress and results of a contact import job
+func (r *contactRepository) UpdateImportJob(ctx context.Context, job *domain.ContactImportJob) error {
+	query := `
+		UPDATE contact_import_jobs SET
+			status = :status,
+			total_rows = :total_rows,
+			created_count = :created_count,
+			duplicate_count = :duplicate_count,
+			invalid_count = :invalid_count,
+			results = :results,
+			error_message = :error_message,
+			completed_at = :completed_at,
+			updated_at = :updated_at
+		WHERE id = :id
+	`
+	_, err := r.db.NamedExecContext(ctx, query, job)
+	return err
+}
+
+// FailStaleImportJobs marks processing import jobs started before startedBefore as failed
+func (r *contactRepository) FailStaleImportJobs(ctx context.Context, startedBefore time.Time, message string) (int64, error) {
+	query := `
+		UPDATE contact_import_jobs
+		SET status = $1, error_message = $2, completed_at = NOW(), updated_at = NOW()
+		WHERE status = $3 AND created_at < $4
+	`
+	result, err := r.db.ExecContext(ctx, query, domain.ContactImportFailed, message, domain.ContactImportProcessing, startedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FindImportJobByUserAndID finds a contact import job with ownership validation
+func (r *contactRepository) FindImportJobByUserAndID(ctx context.Context, userID, jobID string) (*domain.ContactImportJob, error) {
+	var job domain.ContactImportJob
+	query := fmt.Sprintf(`SELECT %s FROM contact_import_jobs WHERE id = $1 AND user_id = $2`, contactImportJobColumns)
+	err := r.db.GetContext(ctx, &job, query, jobID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &job, nil
 }
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// KYBRepository defines the interface for merchant business verification data
+type KYBRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*domain.KYBSubmission, error)
+	Save(ctx context.Context, submission *domain.KYBSubmission) error
+}
+
+// kybRepository implements KYBRepository
+type kybRepository struct {
+	db *sqlx.DB
+}
+
+// NewKYBRepository creates a new KYB repository
+func NewKYBRepository(db *sqlx.DB) KYBRepository {
+	return &kybRepository{db: db}
+}
+
+const kybColumns = `id, user_id, status, business_name, license_type, license_number, license_url,
+	npwp_number, npwp_url, storefront_url, address_street, province_code, province_name,
+	city_code, city_name, district_code, district_name, sub_district_code, sub_district_name,
+	postal_code, rejection_reason, submitted_at, reviewed_by, reviewed_at, created_at, updated_at`
+
+func (r *kybRepository) FindByUserID(ctx context.Context, userID string) (*domain.KYBSubmission, error) {
+	var submission domain.KYBSubmission
+	err := r.db.GetContext(ctx, &submission, `SELECT `+kybColumns+` FROM kyb_submissions WHERE user_id = $1`, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// Save creates the user's submission or updates the merchant-editable fields
+// of the existing one. Review fields are only written by the admin console.
+func (r *kybRepository) Save(ctx context.Context, s *domain.KYBSubmission) error {
+	if s.ID == "" {
+		s.ID = "kyb_" + uuid.New().String()[:8]
+	}
+	query := `
+		INSERT INTO kyb_submissions (
+			id, user_id, status, business_name, license_type, license_number, license_url,
+			npwp_number, npwp_url, storefront_url, address_street, province_code, province_name,
+			city_code, city_name, district_code, district_name, sub_district_code, sub_district_name,
+			postal_code, rejection_reason, submitted_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, NOW(), NOW()
+		)
+		ON CONFLICT (user_id) DO UPDATE SET
+			status = EXCLUDED.status, business_name = EXCLUDED.business_name,
+			license_type = EXCLUDED.license_type, license_number = EXCLUDED.license_number,
+			license_url = EXCLUDED.license_url, npwp_number = EXCLUDED.npwp_number,
+			npwp_url = EXCLUDED.npwp_url, storefront_url = EXCLUDED.storefront_url,
+			address_street = EXCLUDED.address_street, province_code = EXCLUDED.province_code,
+			province_name = EXCLUDED.province_name, city_code = EXCLUDED.city_code,
+			city_name = EXCLUDED.city_name, district_code = EXCLUDED.district_code,
+			district_name = EXCLUDED.district_name, sub_district_code = EXCLUDED.sub_district_code,
+			sub_district_name = EXCLUDED.sub_district_name, postal_code = EXCLUDED.postal_code,
+			rejection_reason = EXCLUDED.rejection_reason, submitted_at = EXCLUDED.submitted_at,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRowxContext(ctx, query,
+		s.ID, s.UserID, s.Status, s.BusinessName, s.LicenseType, s.LicenseNumber, s.LicenseURL,
+		s.NPWPNumber, s.NPWPURL, s.StorefrontURL, s.AddressStreet, s.ProvinceCode, s.ProvinceName,
+		s.CityCode, s.CityName, s.DistrictCode, s.DistrictName, s.SubDistrictCode, s.SubDistrictName,
+		s.PostalCode, s.RejectionReason, s.SubmittedAt,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// KYCArtefactRepository defines the interface for KYC artefact access logs
+// and retention bookkeeping
+type KYCArtefactRepository interface {
+	FindArtefactRef(ctx context.Context, userID, artefact string) (string, error)
+	CreateAccessLog(ctx context.Context, log *domain.KYCArtefactAccessLog) error
+
+	// Retention
+	FindAbandonedSessionUsers(ctx context.Context, cutoff time.Time, limit int) ([]string, error)
+	FindRetainedRefs(ctx context.Context, userID string) ([]string, error)
+	DeleteSessionsExpiredBefore(ctx context.Context, userID string, cutoff time.Time) error
+	FindExpiredVerifiedUsers(ctx context.Context, cutoff time.Time, limit int) ([]string, error)
+	MarkArtefactsPurged(ctx context.Context, userID string) error
+}
+
+// kycArtefactRepository implements KYCArtefactRepository
+type kycArtefactRepository struct {
+	db *sqlx.DB
+}
+
+// NewKYCArtefactRepository creates a new KYC artefact repository
+func NewKYCArtefactRepository(db *sqlx.DB) KYCArtefactRepository {
+	return &kycArtefactRepository{db: db}
+}
+
+// artefactColumns maps each artefact to the table and column holding its reference
+var artefactColumns = map[string][2]string{
+	domain.KYCArtefactKTP:         {"kyc_verifications", "ktp_url"},
+	domain.KYCArtefactFace:        {"kyc_verifications", "face_url"},
+	domain.KYCArtefactFaceWithKTP: {"kyc_verifications", "face_with_ktp_url"},
+	domain.KYBDocumentLicense:     {"kyb_submissions", "license_url"},
+	domain.KYBDocumentNPWP:        {"kyb_submissions", "npwp_url"},
+	domain.KYBDocumentStorefront:  {"kyb_submissions", "storefront_url"},
+}
+
+// FindArtefactRef returns the stored reference of one artefact, or "" when
+// the user has none
+func (r *kycArtefactRepository) FindArtefactRef(ctx context.Context, userID, artefact string) (string, error) {
+	column, ok := artefactColumns[artefact]
+	if !ok {
+		return "", nil
+	}
+	var ref sql.NullString
+	err := r.db.GetContext(ctx, &ref, `SELECT `+column[1]+` FROM `+column[0]+` WHERE user_id = $1`, userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return ref.String, nil
+}
+
+func (r *kycArtefactRepository) CreateAccessLog(ctx context.Context, log *domain.KYCArtefactAccessLog) error {
+	if log.ID == "" {
+		log.ID = "kal_" + uuid.New().String()[:8]
+	}
+	query := `
+		INSERT INTO kyc_artefact_access_logs (
+			id, admin_user_id, user_id, artefact, object_key, purpose, action, ip_address, user_agent, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowxContext(ctx, query,
+		log.ID, log.AdminUserID, log.UserID, log.Artefact, log.ObjectKey,
+		log.Purpose, log.Action, log.IPAddress, log.UserAgent,
+	).Scan(&log.CreatedAt)
+}
+
+// FindAbandonedSessionUsers returns users whose KYC sessions all expired
+// before cutoff. A user with a session still in progress is left alone.
+func (r *kycArtefactRepository) FindAbandonedSessionUsers(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	var userIDs []string
+	err := r.db.SelectContext(ctx, &userIDs, `
+		SELECT user_id FROM kyc_sessions
+		GROUP BY user_id
+		HAVING MAX(expires_at) < $1
+		ORDER BY MAX(expires_at)
+		LIMIT $2
+	`, cutoff, limit)
+	return userIDs, err
+}
+
+// FindRetainedRefs returns the artefact references a user's verification and
+// KYB submission still point at
+func (r *kycArtefactRepository) FindRetainedRefs(ctx context.Context, userID string) ([]string, error) {
+	var refs []string
+	err := r.db.SelectContext(ctx, &refs, `
+		SELECT ref FROM (
+			SELECT unnest(ARRAY[ktp_url, face_url, face_with_ktp_url]) AS ref
+			FROM kyc_verifications WHERE user_id = $1
+			UNION ALL
+			SELECT unnest(ARRAY[license_url, npwp_url, storefront_url])
+			FROM kyb_submissions WHERE user_id = $1
+		) refs
+		WHERE ref IS NOT NULL AND ref <> ''
+	`, userID)
+	return refs, err
+}
+
+func (r *kycArtefactRepository) DeleteSessionsExpiredBefore(ctx context.Context, userID string, cutoff time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM kyc_sessions WHERE user_id = $1 AND expires_at < $2`, userID, cutoff)
+	return err
+}
+
+// FindExpiredVerifiedUsers returns closed accounts whose last update is
+// older than cutoff and that still have KYC or KYB artefacts on file
+func (r *kycArtefactRepository) FindExpiredVerifiedUsers(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	var userIDs []string
+	err := r.db.SelectContext(ctx, &userIDs, `
+		SELECT u.id FROM users u
+		WHERE u.is_active = FALSE AND u.updated_at < $1
+		  AND (
+			EXISTS (SELECT 1 FROM kyc_verifications kv WHERE kv.user_id = u.id AND kv.artefacts_purged_at IS NULL)
+			OR EXISTS (SELECT 1 FROM kyb_submissions k WHERE k.user_id = u.id AND k.documents_purged_at IS NULL)
+		  )
+		ORDER BY u.updated_at
+		LIMIT $2
+	`, cutoff, limit)
+	return userIDs, err
+}
+
+// MarkArtefactsPurged clears the references to deleted artefacts. The
+// verified identity data itself is kept.
+func (r *kycArtefactRepository) MarkArtefactsPurged(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE kyc_verifications
+		SET ktp_url = NULL, face_url = NULL, face_with_ktp_url = NULL, artefacts_purged_at = NOW()
+		WHERE user_id = $1 AND artefacts_purged_at IS NULL
+	`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE kyb_submissions
+		SET license_url = NULL, npwp_url = NULL, storefront_url = NULL, documents_purged_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND documents_purged_at IS NULL
+	`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
 )
 
 // KYCRepository defines the interface for KYC data operations
@@ -22,7 +24,15 @@ type KYCRepository interface {
 	// Verification methods
 	CreateVerification(ctx context.Context, v *domain.KYCVerification) error
 	FindVerificationByUserID(ctx context.Context, userID string) (*domain.KYCVerification, error)
-	IsNIKVerified(ctx context.Context, nik string) (bool, error)
+	FindVerificationByNIK(ctx context.Context, nik string) (*domain.KYCVerification, error)
+	FindFaceCandidates(ctx context.Context, excludeUserID, gender string, dateOfBirth, since time.Time, limit int) ([]*domain.KYCVerification, error)
+
+	// Identity match methods
+	UpsertIdentityMatch(ctx context.Context, m *domain.KYCIdentityMatch) error
+
+	// History methods
+	CreateHistory(ctx context.Context, h *domain.KYCHistory) error
+	FindLatestHistory(ctx context.Context, userID, action string) (*domain.KYCHistory, error)
 }
 
 // kycRepository implements KYCRepository
@@ -40,18 +50,19 @@ func NewKYCRepository(db *sqlx.DB) KYCRepository {
 func (r *kycRepository) CreateSession(ctx context.Context, session *domain.KYCSession) error {
 	query := `
 		INSERT INTO kyc_sessions (id, user_id, nik, status, current_step, ocr_data, face_urls, 
-								 liveness_data, face_comparison, error_message, expires_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+								 liveness_data, face_comparison, nik_validation, error_message, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	ocrData, _ := json.Marshal(session.OCRData)
 	faceUrls, _ := json.Marshal(session.FaceUrls)
 	livenessData, _ := json.Marshal(session.LivenessData)
 	faceComparison, _ := json.Marshal(session.FaceComparison)
+	nikValidation, _ := json.Marshal(session.NIKValidation)
 
 	_, err := r.db.ExecContext(ctx, query,
 		session.ID, session.UserID, session.NIK, session.Status, session.CurrentStep,
-		ocrData, faceUrls, livenessData, faceComparison, session.ErrorMessage,
+		ocrData, faceUrls, livenessData, faceComparison, nikValidation, session.ErrorMessage,
 		session.ExpiresAt, session.CreatedAt, session.UpdatedAt,
 	)
 	return err
@@ -59,15 +70,15 @@ func (r *kycRepository) CreateSession(ctx context.Context, session *domain.KYCSe
 
 func (r *kycRepository) FindSessionByID(ctx context.Context, id string) (*domain.KYCSession, error) {
 	query := `SELECT id, user_id, nik, status, current_step, ocr_data, face_urls, liveness_data, 
-					 face_comparison, error_message, expires_at, created_at, updated_at 
+					 face_comparison, nik_validation, error_message, expires_at, created_at, updated_at 
 			  FROM kyc_sessions WHERE id = $1`
 
 	var session domain.KYCSession
-	var ocrData, faceUrls, livenessData, faceComparison []byte
+	var ocrData, faceUrls, livenessData, faceComparison, nikValidation []byte
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&session.ID, &session.UserID, &session.NIK, &session.Status, &session.CurrentStep,
-		&ocrData, &faceUrls, &livenessData, &faceComparison, &session.ErrorMessage,
+		&ocrData, &faceUrls, &livenessData, &faceComparison, &nikValidation, &session.ErrorMessage,
 		&session.ExpiresAt, &session.CreatedAt, &session.UpdatedAt,
 	)
 
@@ -82,23 +93,24 @@ func (r *kycRepository) FindSessionByID(ctx context.Context, id string) (*domain
 	json.Unmarshal(faceUrls, &session.FaceUrls)
 	json.Unmarshal(livenessData, &session.LivenessData)
 	json.Unmarshal(faceComparison, &session.FaceComparison)
+	json.Unmarshal(nikValidation, &session.NIKValidation)
 
 	return &session, nil
 }
 
 func (r *kycRepository) FindActiveSessionByUserID(ctx context.Context, userID string) (*domain.KYCSession, error) {
 	query := `SELECT id, user_id, nik, status, current_step, ocr_data, face_urls, liveness_data, 
-					 face_comparison, error_message, expires_at, created_at, updated_at 
+					 face_comparison, nik_validation, error_message, expires_at, created_at, updated_at 
 			  FROM kyc_sessions 
 			  WHERE user_id = $1 AND expires_at > NOW() 
 			  ORDER BY created_at DESC LIMIT 1`
 
 	var session domain.KYCSession
-	var ocrData, faceUrls, livenessData, faceComparison []byte
+	var ocrData, faceUrls, livenessData, faceComparison, nikValidation []byte
 
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&session.ID, &session.UserID, &session.NIK, &session.Status, &session.CurrentStep,
-		&ocrData, &faceUrls, &livenessData, &faceComparison, &session.ErrorMessage,
+		&ocrData, &faceUrls, &livenessData, &faceComparison, &nikValidation, &session.ErrorMessage,
 		&session.ExpiresAt, &session.CreatedAt, &session.UpdatedAt,
 	)
 
@@ -113,6 +125,7 @@ func (r *kycRepository) FindActiveSessionByUserID(ctx context.Context, userID st
 	json.Unmarshal(faceUrls, &session.FaceUrls)
 	json.Unmarshal(livenessData, &session.LivenessData)
 	json.Unmarshal(faceComparison, &session.FaceComparison)
+	json.Unmarshal(nikValidation, &session.NIKValidation)
 
 	return &session, nil
 }
@@ -121,18 +134,19 @@ func (r *kycRepository) UpdateSession(ctx context.Context, session *domain.KYCSe
 	query := `
 		UPDATE kyc_sessions 
 		SET nik = $1, status = $2, current_step = $3, ocr_data = $4, face_urls = $5,
-			liveness_data = $6, face_comparison = $7, error_message = $8, updated_at = $9
-		WHERE id = $10
+			liveness_data = $6, face_comparison = $7, nik_validation = $8, error_message = $9, updated_at = $10
+		WHERE id = $11
 	`
 
 	ocrData, _ := json.Marshal(session.OCRData)
 	faceUrls, _ := json.Marshal(session.FaceUrls)
 	livenessData, _ := json.Marshal(session.LivenessData)
 	faceComparison, _ := json.Marshal(session.FaceComparison)
+	nikValidation, _ := json.Marshal(session.NIKValidation)
 
 	_, err := r.db.ExecContext(ctx, query,
 		session.NIK, session.Status, session.CurrentStep,
-		ocrData, faceUrls, livenessData, faceComparison,
+		ocrData, faceUrls, livenessData, faceComparison, nikValidation,
 		session.ErrorMessage, session.UpdatedAt, session.ID,
 	)
 	return err
@@ -152,49 +166,70 @@ func (r *kycRepository) DeleteSessionByUserID(ctx context.Context, userID string
 
 // ========== Verification Methods ==========
 
+// CreateVerification stores the verified data of a user, replacing the record
+// of an earlier verification when the user resubmits after a rejection
 func (r *kycRepository) CreateVerification(ctx context.Context, v *domain.KYCVerification) error {
 	query := `
 		INSERT INTO kyc_verifications (
 			id, user_id, nik, full_name, place_of_birth, date_of_birth, gender, religion,
 			address_street, address_rt, address_rw, address_sub_district, address_district, 
 			address_city, address_province, administrative_code, ktp_url, face_url, 
-			face_with_ktp_url, liveness_url, face_similarity, liveness_confidence, verified_at
+			face_with_ktp_url, liveness_url, face_similarity, liveness_confidence, nik_validation, verified_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
 		)
+		ON CONFLICT (user_id) DO UPDATE SET
+			nik = EXCLUDED.nik, full_name = EXCLUDED.full_name, place_of_birth = EXCLUDED.place_of_birth,
+			date_of_birth = EXCLUDED.date_of_birth, gender = EXCLUDED.gender, religion = EXCLUDED.religion,
+			address_street = EXCLUDED.address_street, address_rt = EXCLUDED.address_rt, address_rw = EXCLUDED.address_rw,
+			address_sub_district = EXCLUDED.address_sub_district, address_district = EXCLUDED.address_district,
+			address_city = EXCLUDED.address_city, address_province = EXCLUDED.address_province,
+			administrative_code = EXCLUDED.administrative_code, ktp_url = EXCLUDED.ktp_url, face_url = EXCLUDED.face_url,
+			face_with_ktp_url = EXCLUDED.face_with_ktp_url, liveness_url = EXCLUDED.liveness_url,
+			face_similarity = EXCLUDED.face_similarity, liveness_confidence = EXCLUDED.liveness_confidence,
+			nik_validation = EXCLUDED.nik_validation, verified_at = EXCLUDED.verified_at
 	`
 
 	adminCode, _ := json.Marshal(v.AdministrativeCode)
+	nikValidation, _ := json.Marshal(v.NIKValidation)
 
 	_, err := r.db.ExecContext(ctx, query,
 		v.ID, v.UserID, v.NIK, v.FullName, v.PlaceOfBirth, v.DateOfBirth, v.Gender, v.Religion,
 		v.AddressStreet, v.AddressRT, v.AddressRW, v.AddressSubDistrict, v.AddressDistrict,
 		v.AddressCity, v.AddressProvince, adminCode, v.KTPUrl, v.FaceUrl,
-		v.FaceWithKTPUrl, v.LivenessUrl, v.FaceSimilarity, v.LivenessConfidence, v.VerifiedAt,
+		v.FaceWithKTPUrl, v.LivenessUrl, v.FaceSimilarity, v.LivenessConfidence, nikValidation, v.VerifiedAt,
 	)
 	return err
 }
 
 func (r *kycRepository) FindVerificationByUserID(ctx context.Context, userID string) (*domain.KYCVerification, error) {
+	return r.findVerification(ctx, "user_id", userID)
+}
+
+func (r *kycRepository) FindVerificationByNIK(ctx context.Context, nik string) (*domain.KYCVerification, error) {
+	return r.findVerification(ctx, "nik", nik)
+}
+
+func (r *kycRepository) findVerification(ctx context.Context, column, value string) (*domain.KYCVerification, error) {
 	query := `
 		SELECT id, user_id, nik, full_name, place_of_birth, date_of_birth, gender, religion,
 			   address_street, address_rt, address_rw, address_sub_district, address_district,
 			   address_city, address_province, administrative_code, ktp_url, face_url,
 			   face_with_ktp_url, liveness_url, face_similarity, liveness_confidence, 
-			   verified_at, created_at
+			   nik_validation, verified_at, created_at
 		FROM kyc_verifications
-		WHERE user_id = $1
+		WHERE ` + column + ` = $1
 	`
 
 	var v domain.KYCVerification
-	var adminCode []byte
+	var adminCode, nikValidation []byte
 
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+	err := r.db.QueryRowContext(ctx, query, value).Scan(
 		&v.ID, &v.UserID, &v.NIK, &v.FullName, &v.PlaceOfBirth, &v.DateOfBirth, &v.Gender, &v.Religion,
 		&v.AddressStreet, &v.AddressRT, &v.AddressRW, &v.AddressSubDistrict, &v.AddressDistrict,
 		&v.AddressCity, &v.AddressProvince, &adminCode, &v.KTPUrl, &v.FaceUrl,
 		&v.FaceWithKTPUrl, &v.LivenessUrl, &v.FaceSimilarity, &v.LivenessConfidence,
-		&v.VerifiedAt, &v.CreatedAt,
+		&nikValidation, &v.VerifiedAt, &v.CreatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -205,12 +240,92 @@ func (r *kycRepository) FindVerificationByUserID(ctx context.Context, userID str
 	}
 
 	json.Unmarshal(adminCode, &v.AdministrativeCode)
+	json.Unmarshal(nikValidation, &v.NIKValidation)
 	return &v, nil
 }
 
-func (r *kycRepository) IsNIKVerified(ctx context.Context, nik string) (bool, error) {
-	query := `SELECT COUNT(*) FROM kyc_verifications WHERE nik = $1`
-	var count int
-	err := r.db.QueryRowContext(ctx, query, nik).Scan(&count)
-	return count > 0, err
+// FindFaceCandidates returns verified selfies of other users to compare a new
+// selfie against: those sharing the date of birth plus the most recent ones,
+// of the same gender when it is known. Dummy selfies are skipped. Only id,
+// user_id, face_url and verified_at are loaded.
+func (r *kycRepository) FindFaceCandidates(ctx context.Context, excludeUserID, gender string, dateOfBirth, since time.Time, limit int) ([]*domain.KYCVerification, error) {
+	query := `SELECT id, user_id, face_url, verified_at
+			  FROM kyc_verifications
+			  WHERE user_id <> $1 AND face_url IS NOT NULL AND face_url <> '' AND face_url NOT LIKE 'dummy://%'
+			    AND (date_of_birth = $2 OR verified_at >= $3)
+			    AND ($5 = '' OR gender = $5)
+			  ORDER BY (date_of_birth = $2) DESC, verified_at DESC
+			  LIMIT $4`
+
+	candidates := []*domain.KYCVerification{}
+	if err := r.db.SelectContext(ctx, &candidates, query, excludeUserID, dateOfBirth, since, limit, gender); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// ========== Identity Match Methods ==========
+
+// UpsertIdentityMatch records a duplicate identity match, reopening it when
+// the same pair was matched and resolved before
+func (r *kycRepository) UpsertIdentityMatch(ctx context.Context, m *domain.KYCIdentityMatch) error {
+	if m.ID == "" {
+		m.ID = "kim_" + uuid.New().String()[:8]
+	}
+	if m.DetectedAt.IsZero() {
+		m.DetectedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO kyc_identity_matches (id, user_id, matched_user_id, match_type, similarity, detected_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, matched_user_id, match_type) DO UPDATE SET
+			similarity = EXCLUDED.similarity, detected_at = EXCLUDED.detected_at, resolved_at = NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, m.ID, m.UserID, m.MatchedUserID, m.MatchType, m.Similarity, m.DetectedAt)
+	return err
+}
+
+// ========== History Methods ==========
+
+func (r *kycRepository) CreateHistory(ctx context.Context, h *domain.KYCHistory) error {
+	query := `
+		INSERT INTO kyc_history (user_id, session_id, action, status, metadata, error_message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now()
+	}
+	metadata, _ := json.Marshal(h.Metadata)
+
+	return r.db.QueryRowContext(ctx, query,
+		h.UserID, h.SessionID, h.Action, h.Status, metadata, h.ErrorMessage, h.CreatedAt,
+	).Scan(&h.ID)
+}
+
+// FindLatestHistory returns the most recent history entry of an action for a user
+func (r *kycRepository) FindLatestHistory(ctx context.Context, userID, action string) (*domain.KYCHistory, error) {
+	query := `SELECT id, user_id, session_id, action, status, metadata, error_message, created_at
+			  FROM kyc_history
+			  WHERE user_id = $1 AND action = $2
+			  ORDER BY created_at DESC, id DESC LIMIT 1`
+
+	var h domain.KYCHistory
+	var metadata []byte
+
+	err := r.db.QueryRowContext(ctx, query, userID, action).Scan(
+		&h.ID, &h.UserID, &h.SessionID, &h.Action, &h.Status, &metadata, &h.ErrorMessage, &h.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(metadata, &h.Metadata)
+	return &h, nil
 }
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// MaintenanceRepository defines the interface for maintenance window data operations
+type MaintenanceRepository interface {
+	FindCurrent(ctx context.Context, now time.Time) ([]*domain.MaintenanceWindow, error)
+	FindAll(ctx context.Context, includeEnded bool) ([]*domain.MaintenanceWindow, error)
+	FindByID(ctx context.Context, id string) (*domain.MaintenanceWindow, error)
+	Create(ctx context.Context, window *domain.MaintenanceWindow) error
+	Update(ctx context.Context, window *domain.MaintenanceWindow) error
+}
+
+// maintenanceRepository implements MaintenanceRepository
+type maintenanceRepository struct {
+	db *sqlx.DB
+}
+
+// NewMaintenanceRepository creates a new maintenance repository
+func NewMaintenanceRepository(db *sqlx.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: db}
+}
+
+const maintenanceColumns = `id, scope, target, message, starts_at, ends_at, is_active,
+	created_by, created_at, updated_at`
+
+// FindCurrent returns active windows that have not ended, including ones
+// starting later, so callers can cache them and evaluate start times locally
+func (r *maintenanceRepository) FindCurrent(ctx context.Context, now time.Time) ([]*domain.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows
+		WHERE is_active = true AND (ends_at IS NULL OR ends_at > $1)`
+
+	windows := []*domain.MaintenanceWindow{}
+	if err := r.db.SelectContext(ctx, &windows, query, now); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// FindAll returns windows for the admin console, newest first
+func (r *maintenanceRepository) FindAll(ctx context.Context, includeEnded bool) ([]*domain.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows`
+	if !includeEnded {
+		query += ` WHERE is_active = true AND (ends_at IS NULL OR ends_at > NOW())`
+	}
+	query += ` ORDER BY starts_at DESC LIMIT 200`
+
+	windows := []*domain.MaintenanceWindow{}
+	if err := r.db.SelectContext(ctx, &windows, query); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// FindByID finds a maintenance window by ID
+func (r *maintenanceRepository) FindByID(ctx context.Context, id string) (*domain.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE id = $1`
+
+	var window domain.MaintenanceWindow
+	if err := r.db.GetContext(ctx, &window, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &window, nil
+}
+
+// Create inserts a maintenance window
+func (r *maintenanceRepository) Create(ctx context.Context, window *domain.MaintenanceWindow) error {
+	if window.ID == "" {
+		window.ID = "mnt_" + uuid.New().String()[:8]
+	}
+	now := time.Now()
+	window.CreatedAt = now
+	window.UpdatedAt = now
+
+	query := `
+		INSERT INTO maintenance_windows (
+			id, scope, target, message, starts_at, ends_at, is_active, created_by, created_at, updated_at
+		) VALUES (
+			:id, :scope, :target, :message, :starts_at, :ends_at, :is_active, :created_by, :created_at, :updated_at
+		)
+	`
+	_, err := r.db.NamedExecContext(ctx, query, window)
+	return err
+}
+
+// Update replaces a maintenance window's attributes
+func (r *maintenanceRepository) Update(ctx context.Context, window *domain.MaintenanceWindow) error {
+	window.UpdatedAt = time.Now()
+	query := `
+		UPDATE maintenance_windows
+		SET
+			scope = :scope,
+			target = :target,
+			message = :message,
+			starts_at = :starts_at,
+			ends_at = :ends_at,
+			is_active = :is_active,
+			updated_at = :updated_at
+		WHERE id = :id
+	`
+	_, err := r.db.NamedExecContext(ctx, query, window)
+	return err
+}
@@ -21,6 +21,8 @@ type PostpaidRepository interface {
 	FindTransactionByInquiryID(ctx context.Context, inquiryID string) (*domain.PostpaidTransaction, error)
 	UpdateTransactionStatus(ctx context.Context, id, status string) error
 	UpdateTransactionStatusWithTx(ctx context.Context, tx *sqlx.Tx, id, status string) error
+	UpdateTransactionResultWithTx(ctx context.Context, dbtx *sqlx.Tx, tx *domain.PostpaidTransaction) error
+	DeleteTransactionWithTx(ctx context.Context, dbtx *sqlx.Tx, id string) error
 
 	// Transaction methods for atomic operations
 	BeginTx(ctx context.Context) (*sqlx.Tx, error)
@@ -32,12 +34,12 @@ type postpaidRepository struct {
 
 const postpaidInquiryColumns = `id, user_id, service_type, target, provider_id, customer_id,
 	customer_name, period, bill_amount, admin_fee, penalty, total_payment, has_bill,
-	external_id, expires_at, created_at`
+	external_id, supplier_code, expires_at, created_at`
 
 const postpaidTransactionColumns = `id, public_id, user_id, inquiry_id, service_type, target, provider_id,
 	customer_id, customer_name, period, bill_amount, admin_fee, penalty, voucher_discount,
 	total_payment, balance_before, balance_after, reference_number, serial_number, external_id,
-	status, failed_reason, completed_at, created_at, updated_at`
+	supplier_code, status, failed_reason, completed_at, created_at, updated_at`
 
 // NewPostpaidRepository creates a new postpaid repository
 func NewPostpaidRepository(db *sqlx.DB) PostpaidRepository {
@@ -50,11 +52,11 @@ func (r *postpaidRepository) CreateInquiry(ctx context.Context, inquiry *domain.
 		INSERT INTO postpaid_inquiries (
 			id, user_id, service_type, target, provider_id, customer_id, customer_name,
 			period, bill_amount, admin_fee, penalty, total_payment, has_bill,
-			external_id, created_at, expires_at
+			external_id, supplier_code, created_at, expires_at
 		) VALUES (
 			:id, :user_id, :service_type, :target, :provider_id, :customer_id, :customer_name,
 			:period, :bill_amount, :admin_fee, :penalty, :total_payment, :has_bill,
-			:external_id, :created_at, :expires_at
+			:external_id, :supplier_code, :created_at, :expires_at
 		)
 	`
 	_, err := r.db.NamedExecContext(ctx, query, inquiry)
@@ -126,12 +128,12 @@ func (r *postpaidRepository) createTransaction(ctx context.Context, exec sqlx.Ex
 			id, public_id, user_id, inquiry_id, service_type, target, provider_id, customer_id,
 			customer_name, period, bill_amount, admin_fee, penalty, voucher_discount,
 			total_payment, balance_before, balance_after, reference_number, serial_number,
-			external_id, status, failed_reason, completed_at, created_at, updated_at
+			external_id, supplier_code, status, failed_reason, completed_at, created_at, updated_at
 		) VALUES (
 			:id, :public_id, :user_id, :inquiry_id, :service_type, :target, :provider_id, :customer_id,
 			:customer_name, :period, :bill_amount, :admin_fee, :penalty, :voucher_discount,
 			:total_payment, :balance_before, :balance_after, :reference_number, :serial_number,
-			:external_id, :status, :failed_reason, :completed_at, :created_at, :updated_at
+			:external_id, :supplier_code, :status, :failed_reason, :completed_at, :created_at, :updated_at
 		)
 	`
 	_, err := sqlx.NamedExecContext(ctx, exec, query, tx)
@@ -177,6 +179,25 @@ func (r *postpaidRepository) UpdateTransactionStatusWithTx(ctx context.Context,
 	return err
 }
 
+// UpdateTransactionResultWithTx stores the supplier result of a transaction within a database transaction
+func (r *postpaidRepository) UpdateTransactionResultWithTx(ctx context.Context, dbtx *sqlx.Tx, tx *domain.PostpaidTransaction) error {
+	query := `
+		UPDATE postpaid_transactions SET
+			status = :status, reference_number = :reference_number, serial_number = :serial_number,
+			external_id = :external_id, supplier_code = :supplier_code, completed_at = :completed_at,
+			updated_at = NOW()
+		WHERE id = :id
+	`
+	_, err := dbtx.NamedExecContext(ctx, query, tx)
+	return err
+}
+
+// DeleteTransactionWithTx removes a transaction whose supplier call was rejected within a database transaction
+func (r *postpaidRepository) DeleteTransactionWithTx(ctx context.Context, dbtx *sqlx.Tx, id string) error {
+	_, err := dbtx.ExecContext(ctx, `DELETE FROM postpaid_transactions WHERE id = $1`, id)
+	return err
+}
+
 // BeginTx begins a database transaction
 func (r *postpaidRepository) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
 	return r.db.BeginTxx(ctx, nil)
@@ -29,6 +29,7 @@ type PrepaidRepository interface {
 	FindTransactionByOrderID(ctx context.Context, orderID string) (*domain.PrepaidTransaction, error)
 	UpdateTransactionStatus(ctx context.Context, id, status string) error
 	UpdateTransactionStatusWithTx(ctx context.Context, tx *sqlx.Tx, id, status string) error
+	UpdateTransactionResultWithTx(ctx context.Context, dbtx *sqlx.Tx, tx *domain.PrepaidTransaction) error
 
 	// Transaction management
 	BeginTx(ctx context.Context) (*sqlx.Tx, error)
@@ -54,7 +55,7 @@ const prepaidOrderColumns = `id, public_id, user_id, inquiry_id, product_id, sta
 
 const prepaidTransactionColumns = `id, public_id, user_id, order_id, status, service_type, target,
 	product_id, total_payment, balance_before, balance_after, serial_number,
-	reference_number, token, kwh, completed_at, created_at, updated_at`
+	reference_number, token, kwh, supplier_code, completed_at, created_at, updated_at`
 
 // BeginTx begins a new database transaction
 func (r *prepaidRepository) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
@@ -172,12 +173,12 @@ func (r *prepaidRepository) CreateTransaction(ctx context.Context, tx *domain.Pr
 		INSERT INTO prepaid_transactions (
 			id, public_id, user_id, order_id, status, service_type, target, product_id,
 			total_payment, balance_before, balance_after,
-			serial_number, reference_number, token, kwh,
+			serial_number, reference_number, token, kwh, supplier_code,
 			completed_at, created_at, updated_at
 		) VALUES (
 			:id, :public_id, :user_id, :order_id, :status, :service_type, :target, :product_id,
 			:total_payment, :balance_before, :balance_after,
-			:serial_number, :reference_number, :token, :kwh,
+			:serial_number, :reference_number, :token, :kwh, :supplier_code,
 			:completed_at, :created_at, :updated_at
 		)
 	`
@@ -202,12 +203,12 @@ func (r *prepaidRepository) CreateTransactionWithTx(ctx context.Context, dbtx *s
 		INSERT INTO prepaid_transactions (
 			id, public_id, user_id, order_id, status, service_type, target, product_id,
 			total_payment, balance_before, balance_after,
-			serial_number, reference_number, token, kwh,
+			serial_number, reference_number, token, kwh, supplier_code,
 			completed_at, created_at, updated_at
 		) VALUES (
 			:id, :public_id, :user_id, :order_id, :status, :service_type, :target, :product_id,
 			:total_payment, :balance_before, :balance_after,
-			:serial_number, :reference_number, :token, :kwh,
+			:serial_number, :reference_number, :token, :kwh, :supplier_code,
 			:completed_at, :created_at, :updated_at
 		)
 	`
@@ -250,3 +251,16 @@ func (r *prepaidRepository) UpdateTransactionStatusWithTx(ctx context.Context, t
 	_, err := tx.ExecContext(ctx, query, status, id)
 	return err
 }
+
+// UpdateTransactionResultWithTx stores the provider result of a transaction within a database transaction
+func (r *prepaidRepository) UpdateTransactionResultWithTx(ctx context.Context, dbtx *sqlx.Tx, tx *domain.PrepaidTransaction) error {
+	query := `
+		UPDATE prepaid_transactions SET
+			status = :status, serial_number = :serial_number, reference_number = :reference_number,
+			token = :token, kwh = :kwh, supplier_code = :supplier_code,
+			completed_at = :completed_at, updated_at = NOW()
+		WHERE id = :id
+	`
+	_, err := dbtx.NamedExecContext(ctx, query, tx)
+	return err
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// PricingRuleRepository defines the interface for pricing rule data operations
+type PricingRuleRepository interface {
+	FindAll(ctx context.Context) ([]*domain.PricingRule, error)
+	FindActive(ctx context.Context) ([]*domain.PricingRule, error)
+	FindByID(ctx context.Context, id string) (*domain.PricingRule, error)
+	Create(ctx context.Context, rule *domain.PricingRule) error
+	Update(ctx context.Context, rule *domain.PricingRule) error
+	Delete(ctx context.Context, id string) error
+
+	// Transaction methods
+	FindActiveWithTx(ctx context.Context, tx *sqlx.Tx) ([]*domain.PricingRule, error)
+	CreateWithTx(ctx context.Context, tx *sqlx.Tx, rule *domain.PricingRule) error
+	UpdateWithTx(ctx context.Context, tx *sqlx.Tx, rule *domain.PricingRule) error
+	DeleteWithTx(ctx context.Context, tx *sqlx.Tx, id string) error
+}
+
+// pricingRuleRepository implements PricingRuleRepository
+type pricingRuleRepository struct {
+	db *sqlx.DB
+}
+
+// NewPricingRuleRepository creates a new pricing rule repository
+func NewPricingRuleRepository(db *sqlx.DB) PricingRuleRepository {
+	return &pricingRuleRepository{db: db}
+}
+
+const pricingRuleColumns = `id, name, category, brand, sku_code, user_tier, markup_type,
+	markup_value, admin_markup, rounding_step, rounding_mode, min_price, max_price,
+	priority, is_active, created_at, updated_at`
+
+// FindAll returns every pricing rule, most specific targets first
+func (r *pricingRuleRepository) FindAll(ctx context.Context) ([]*domain.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + ` FROM pricing_rules
+		ORDER BY sku_code DESC, brand DESC, category DESC, user_tier ASC, priority DESC, name ASC`
+
+	rules := []*domain.PricingRule{}
+	if err := r.db.SelectContext(ctx, &rules, query); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+const findActivePricingRulesQuery = `SELECT ` + pricingRuleColumns + ` FROM pricing_rules WHERE is_active = true`
+
+// FindActive returns the rules used to compute selling prices
+func (r *pricingRuleRepository) FindActive(ctx context.Context) ([]*domain.PricingRule, error) {
+	rules := []*domain.PricingRule{}
+	if err := r.db.SelectContext(ctx, &rules, findActivePricingRulesQuery); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindActiveWithTx returns the active rules as seen by a transaction,
+// including its own uncommitted changes
+func (r *pricingRuleRepository) FindActiveWithTx(ctx context.Context, tx *sqlx.Tx) ([]*domain.PricingRule, error) {
+	rules := []*domain.PricingRule{}
+	if err := tx.SelectContext(ctx, &rules, findActivePricingRulesQuery); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindByID finds a pricing rule by ID
+func (r *pricingRuleRepository) FindByID(ctx context.Context, id string) (*domain.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + ` FROM pricing_rules WHERE id = $1`
+
+	var rule domain.PricingRule
+	if err := r.db.GetContext(ctx, &rule, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+const insertPricingRuleQuery = `
+	INSERT INTO pricing_rules (
+		id, name, category, brand, sku_code, user_tier, markup_type,
+		markup_value, admin_markup, rounding_step, rounding_mode, min_price, max_price,
+		priority, is_active, created_at, updated_at
+	) VALUES (
+		:id, :name, :category, :brand, :sku_code, :user_tier, :markup_type,
+		:markup_value, :admin_markup, :rounding_step, :rounding_mode, :min_price, :max_price,
+		:priority, :is_active, NOW(), NOW()
+	)
+`
+
+// Create inserts a pricing rule
+func (r *pricingRuleRepository) Create(ctx context.Context, rule *domain.PricingRule) error {
+	_, err := r.db.NamedExecContext(ctx, insertPricingRuleQuery, rule)
+	return err
+}
+
+// CreateWithTx inserts a pricing rule within a transaction
+func (r *pricingRuleRepository) CreateWithTx(ctx context.Context, tx *sqlx.Tx, rule *domain.PricingRule) error {
+	_, err := tx.NamedExecContext(ctx, insertPricingRuleQuery, rule)
+	return err
+}
+
+const updatePricingRuleQuery = `
+	UPDATE pricing_rules
+	SET
+		name = :name,
+		category = :category,
+		brand = :brand,
+		sku_code = :sku_code,
+		user_tier = :user_tier,
+		markup_type = :markup_type,
+		markup_value = :markup_value,
+		admin_markup = :admin_markup,
+		rounding_step = :rounding_step,
+		rounding_mode = :rounding_mode,
+		min_price = :min_price,
+		max_price = :max_price,
+		priority = :priority,
+		is_active = :is_active,
+		updated_at = NOW()
+	WHERE id = :id
+`
+
+// Update replaces a pricing rule's attributes
+func (r *pricingRuleRepository) Update(ctx context.Context, rule *domain.PricingRule) error {
+	_, err := r.db.NamedExecContext(ctx, updatePricingRuleQuery, rule)
+	return err
+}
+
+// UpdateWithTx replaces a pricing rule's attributes within a transaction
+func (r *pricingRuleRepository) UpdateWithTx(ctx context.Context, tx *sqlx.Tx, rule *domain.PricingRule) error {
+	_, err := tx.NamedExecContext(ctx, updatePricingRuleQuery, rule)
+	return err
+}
+
+// Delete removes a pricing rule
+func (r *pricingRuleRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM pricing_rules WHERE id = $1`, id)
+	return err
+}
+
+// DeleteWithTx removes a pricing rule within a transaction
+func (r *pricingRuleRepository) DeleteWithTx(ctx context.Context, tx *sqlx.Tx, id string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM pricing_rules WHERE id = $1`, id)
+	return err
+}
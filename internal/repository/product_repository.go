@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -23,6 +24,14 @@ type ProductFilter struct {
 	PerPage  int
 }
 
+// ProductChangeFilter for filtering product change history
+type ProductChangeFilter struct {
+	SKUCode    string
+	AlertsOnly bool
+	Page       int
+	PerPage    int
+}
+
 // ProductRepository defines the interface for product data operations
 type ProductRepository interface {
 	// GTD Product Sync methods
@@ -31,6 +40,16 @@ type ProductRepository interface {
 	Update(ctx context.Context, product *domain.Product) error
 	BulkUpsert(ctx context.Context, products []*domain.Product) error
 
+	// Pricing methods
+	FindAllForPricing(ctx context.Context) ([]*domain.Product, error)
+	UpdateSellingPrices(ctx context.Context, products []*domain.Product) (int, error)
+	UpdateSellingPricesWithTx(ctx context.Context, tx *sqlx.Tx, products []*domain.Product) (int, error)
+
+	// Change history methods
+	CreateChanges(ctx context.Context, changes []*domain.ProductChange) error
+	CreateChangesWithTx(ctx context.Context, tx *sqlx.Tx, changes []*domain.ProductChange) error
+	FindChanges(ctx context.Context, filter ProductChangeFilter) ([]*domain.ProductChange, int, error)
+
 	// Query methods for user API
 	FindAll(ctx context.Context, filter ProductFilter) ([]*domain.Product, error)
 	FindByID(ctx context.Context, id string) (*domain.Product, error)
@@ -43,7 +62,11 @@ type ProductRepository interface {
 
 	// Static provider data (Operators, E-wallet, PDAM, Banks, TV)
 	FindAllOperators(ctx context.Context) ([]*domain.Operator, error)
-	FindOperatorByPrefix(ctx context.Context, prefix string) (*domain.Operator, error)
+	ListOperators(ctx context.Context) ([]*domain.Operator, error)
+	FindOperatorByID(ctx context.Context, id string) (*domain.Operator, error)
+	CreateOperator(ctx context.Context, operator *domain.Operator) error
+	UpdateOperator(ctx context.Context, operator *domain.Operator) error
+	DeleteOperator(ctx context.Context, id string) (bool, error)
 	FindAllEwalletProviders(ctx context.Context) ([]*domain.EwalletProvider, error)
 	FindAllPDAMRegions(ctx context.Context) ([]*domain.PDAMRegion, error)
 	FindAllBanks(ctx context.Context, filterType string) ([]*domain.Bank, error)
@@ -78,40 +101,110 @@ func (r *productRepository) FindAllOperators(ctx context.Context) ([]*domain.Ope
 
 	// Parse JSON prefixes from DB text to []string
 	for _, op := range operators {
-		if op.PrefixesDB != "" {
-			json.Unmarshal([]byte(op.PrefixesDB), &op.Prefixes)
-		}
-		if op.Prefixes == nil {
-			op.Prefixes = []string{}
-		}
+		parseOperatorPrefixes(op)
 	}
 
 	return operators, nil
 }
 
-// FindOperatorByPrefix finds operator by phone prefix
-func (r *productRepository) FindOperatorByPrefix(ctx context.Context, prefix string) (*domain.Operator, error) {
-	if len(prefix) < 4 {
-		return nil, nil
+// ListOperators returns all mobile operators regardless of status (admin use)
+func (r *productRepository) ListOperators(ctx context.Context) ([]*domain.Operator, error) {
+	query := `
+		SELECT id, name, prefixes, icon, COALESCE(icon_url, '') AS icon_url, COALESCE(status, 'active') AS status, COALESCE(sort_order, 0) AS sort_order
+		FROM operators
+		ORDER BY sort_order ASC, name ASC
+	`
+
+	var operators []*domain.Operator
+	if err := r.db.SelectContext(ctx, &operators, query); err != nil {
+		return nil, err
 	}
+	for _, op := range operators {
+		parseOperatorPrefixes(op)
+	}
+	return operators, nil
+}
 
-	checkPrefix := prefix[:4]
+// FindOperatorByID finds an operator by ID regardless of status
+func (r *productRepository) FindOperatorByID(ctx context.Context, id string) (*domain.Operator, error) {
+	query := `
+		SELECT id, name, prefixes, icon, COALESCE(icon_url, '') AS icon_url, COALESCE(status, 'active') AS status, COALESCE(sort_order, 0) AS sort_order
+		FROM operators
+		WHERE id = $1
+	`
 
-	// Query all active operators and check prefix in Go
-	// (prefixes is stored as JSON text, checked after parsing)
-	operators, err := r.FindAllOperators(ctx)
+	var operator domain.Operator
+	err := r.db.GetContext(ctx, &operator, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	parseOperatorPrefixes(&operator)
+	return &operator, nil
+}
 
-	for _, op := range operators {
-		for _, p := range op.Prefixes {
-			if p == checkPrefix {
-				return op, nil
-			}
-		}
+// CreateOperator inserts a new operator with its prefixes
+func (r *productRepository) CreateOperator(ctx context.Context, operator *domain.Operator) error {
+	prefixes, err := json.Marshal(operator.Prefixes)
+	if err != nil {
+		return err
+	}
+	operator.PrefixesDB = string(prefixes)
+
+	query := `
+		INSERT INTO operators (id, name, prefixes, icon, icon_url, status, sort_order, created_at, updated_at)
+		VALUES (:id, :name, :prefixes, :icon, :icon_url, :status, :sort_order, NOW(), NOW())
+	`
+	_, err = r.db.NamedExecContext(ctx, query, operator)
+	return err
+}
+
+// UpdateOperator updates an operator and its prefixes
+func (r *productRepository) UpdateOperator(ctx context.Context, operator *domain.Operator) error {
+	prefixes, err := json.Marshal(operator.Prefixes)
+	if err != nil {
+		return err
+	}
+	operator.PrefixesDB = string(prefixes)
+
+	query := `
+		UPDATE operators SET
+			name = :name,
+			prefixes = :prefixes,
+			icon = :icon,
+			icon_url = :icon_url,
+			status = :status,
+			sort_order = :sort_order,
+			updated_at = NOW()
+		WHERE id = :id
+	`
+	_, err = r.db.NamedExecContext(ctx, query, operator)
+	return err
+}
+
+// DeleteOperator deletes an operator, reporting whether it existed
+func (r *productRepository) DeleteOperator(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM operators WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// parseOperatorPrefixes parses the JSON prefixes column into Prefixes
+func parseOperatorPrefixes(op *domain.Operator) {
+	if op.PrefixesDB != "" {
+		json.Unmarshal([]byte(op.PrefixesDB), &op.Prefixes)
+	}
+	if op.Prefixes == nil {
+		op.Prefixes = []string{}
 	}
-	return nil, nil
 }
 
 // FindAllEwalletProviders returns all e-wallet providers from database
@@ -300,7 +393,8 @@ func (r *productRepository) FindAllTVProviders(ctx context.Context) ([]*domain.T
 // ========== GTD Product Sync Methods ==========
 
 const productColumns = `id, sku_code, name, category, brand, type, price, admin,
-	commission, is_active, description, gtd_updated_at, created_at, updated_at`
+	supplier_price, supplier_admin, commission, is_active, description, gtd_updated_at,
+	created_at, updated_at`
 
 // FindBySKU finds product by SKU code
 func (r *productRepository) FindBySKU(ctx context.Context, skuCode string) (*domain.Product, error) {
@@ -321,10 +415,10 @@ func (r *productRepository) FindBySKU(ctx context.Context, skuCode string) (*dom
 func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
 	query := `
 		INSERT INTO products (
-			id, sku_code, name, category, brand, type, price, admin,
+			id, sku_code, name, category, brand, type, price, admin, supplier_price, supplier_admin,
 			commission, is_active, description, gtd_updated_at, created_at, updated_at
 		) VALUES (
-			:id, :sku_code, :name, :category, :brand, :type, :price, :admin,
+			:id, :sku_code, :name, :category, :brand, :type, :price, :admin, :supplier_price, :supplier_admin,
 			:commission, :is_active, :description, :gtd_updated_at, NOW(), NOW()
 		)
 	`
@@ -344,6 +438,8 @@ func (r *productRepository) Update(ctx context.Context, product *domain.Product)
 			type = :type,
 			price = :price,
 			admin = :admin,
+			supplier_price = :supplier_price,
+			supplier_admin = :supplier_admin,
 			commission = :commission,
 			is_active = :is_active,
 			description = :description,
@@ -369,10 +465,10 @@ func (r *productRepository) BulkUpsert(ctx context.Context, products []*domain.P
 
 	query := `
 		INSERT INTO products (
-			id, sku_code, name, category, brand, type, price, admin,
+			id, sku_code, name, category, brand, type, price, admin, supplier_price, supplier_admin,
 			commission, is_active, description, gtd_updated_at, created_at, updated_at
 		) VALUES (
-			:id, :sku_code, :name, :category, :brand, :type, :price, :admin,
+			:id, :sku_code, :name, :category, :brand, :type, :price, :admin, :supplier_price, :supplier_admin,
 			:commission, :is_active, :description, :gtd_updated_at, NOW(), NOW()
 		)
 		ON CONFLICT (sku_code) DO UPDATE SET
@@ -383,6 +479,8 @@ func (r *productRepository) BulkUpsert(ctx context.Context, products []*domain.P
 			type = EXCLUDED.type,
 			price = EXCLUDED.price,
 			admin = EXCLUDED.admin,
+			supplier_price = EXCLUDED.supplier_price,
+			supplier_admin = EXCLUDED.supplier_admin,
 			commission = EXCLUDED.commission,
 			is_active = EXCLUDED.is_active,
 			description = EXCLUDED.description,
@@ -399,6 +497,138 @@ func (r *productRepository) BulkUpsert(ctx context.Context, products []*domain.P
 	return tx.Commit()
 }
 
+// FindAllForPricing returns every product, active or not, for repricing
+func (r *productRepository) FindAllForPricing(ctx context.Context) ([]*domain.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products ORDER BY category ASC, brand ASC, price ASC`
+
+	products := []*domain.Product{}
+	if err := r.db.SelectContext(ctx, &products, query); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// UpdateSellingPrices writes the price and admin of each product, skipping rows
+// that already hold those values. Returns the number of rows changed.
+func (r *productRepository) UpdateSellingPrices(ctx context.Context, products []*domain.Product) (int, error) {
+	if len(products) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin product price transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := r.UpdateSellingPricesWithTx(ctx, tx, products)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// UpdateSellingPricesWithTx is UpdateSellingPrices within a caller's transaction
+func (r *productRepository) UpdateSellingPricesWithTx(ctx context.Context, tx *sqlx.Tx, products []*domain.Product) (int, error) {
+	updated := 0
+	for _, product := range products {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE products
+			SET price = $2, admin = $3, updated_at = NOW()
+			WHERE id = $1 AND (price <> $2 OR admin <> $3)
+		`, product.ID, product.Price, product.Admin)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update price of product %s: %w", product.SKUCode, err)
+		}
+		rows, _ := result.RowsAffected()
+		updated += int(rows)
+	}
+	return updated, nil
+}
+
+const productChangeColumns = `id, product_id, sku_code, source, change_type, changed_fields,
+	old_supplier_price, new_supplier_price, old_supplier_admin, new_supplier_admin,
+	old_price, new_price, old_admin, new_admin, old_commission, new_commission,
+	old_is_active, new_is_active, alerted, alert_reason, created_at`
+
+// CreateChanges inserts product change records
+func (r *productRepository) CreateChanges(ctx context.Context, changes []*domain.ProductChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin product change transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.CreateChangesWithTx(ctx, tx, changes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateChangesWithTx inserts product change records within a caller's transaction
+func (r *productRepository) CreateChangesWithTx(ctx context.Context, tx *sqlx.Tx, changes []*domain.ProductChange) error {
+	query := `
+		INSERT INTO product_change_history (
+			product_id, sku_code, source, change_type, changed_fields,
+			old_supplier_price, new_supplier_price, old_supplier_admin, new_supplier_admin,
+			old_price, new_price, old_admin, new_admin, old_commission, new_commission,
+			old_is_active, new_is_active, alerted, alert_reason, created_at
+		) VALUES (
+			:product_id, :sku_code, :source, :change_type, :changed_fields,
+			:old_supplier_price, :new_supplier_price, :old_supplier_admin, :new_supplier_admin,
+			:old_price, :new_price, :old_admin, :new_admin, :old_commission, :new_commission,
+			:old_is_active, :new_is_active, :alerted, :alert_reason, :created_at
+		)
+	`
+	for _, change := range changes {
+		if _, err := tx.NamedExecContext(ctx, query, change); err != nil {
+			return fmt.Errorf("failed to record change of product %s: %w", change.SKUCode, err)
+		}
+	}
+	return nil
+}
+
+// FindChanges returns product change records, newest first
+func (r *productRepository) FindChanges(ctx context.Context, filter ProductChangeFilter) ([]*domain.ProductChange, int, error) {
+	where := ` WHERE 1=1`
+	args := make([]interface{}, 0, 3)
+	if filter.SKUCode != "" {
+		args = append(args, filter.SKUCode)
+		where += fmt.Sprintf(" AND sku_code = $%d", len(args))
+	}
+	if filter.AlertsOnly {
+		where += " AND alerted = true"
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM product_change_history`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + productChangeColumns + ` FROM product_change_history` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+
+	changes := []*domain.ProductChange{}
+	if err := r.db.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, 0, err
+	}
+	for _, change := range changes {
+		change.ChangedFields = []string{}
+		if change.ChangedFieldsDB != "" {
+			change.ChangedFields = strings.Split(change.ChangedFieldsDB, ",")
+		}
+	}
+	return changes, total, nil
+}
+
 // FindAll returns products with filters
 func (r *productRepository) FindAll(ctx context.Context, filter ProductFilter) ([]*domain.Product, error) {
 	query := `SELECT ` + productColumns + ` FROM products WHERE 1=1`
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReservationRepository defines the interface for balance reservation data operations
+type ReservationRepository interface {
+	CreateWithTx(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) error
+	FindByIDForUpdate(ctx context.Context, tx *sqlx.Tx, id string) (*domain.BalanceReservation, error)
+	FindHeldByReferenceForUpdate(ctx context.Context, tx *sqlx.Tx, referenceType, referenceID string) (*domain.BalanceReservation, error)
+	FindLatestByReference(ctx context.Context, referenceType, referenceID string) (*domain.BalanceReservation, error)
+	FindExpiredHeld(ctx context.Context, now time.Time, limit int) ([]*domain.BalanceReservation, error)
+	UpdateStatusWithTx(ctx context.Context, tx *sqlx.Tx, id, status string) error
+	MarkProviderCalled(ctx context.Context, id string) error
+	ExtendWithTx(ctx context.Context, tx *sqlx.Tx, id string, expiresAt time.Time) error
+	BeginTx(ctx context.Context) (*sqlx.Tx, error)
+}
+
+// reservationRepository implements ReservationRepository
+type reservationRepository struct {
+	db *sqlx.DB
+}
+
+// NewReservationRepository creates a new balance reservation repository
+func NewReservationRepository(db *sqlx.DB) ReservationRepository {
+	return &reservationRepository{db: db}
+}
+
+const reservationColumns = `id, user_id, amount, reference_type, reference_id, status,
+	expires_at, provider_called_at, resolved_at, created_at, updated_at`
+
+// BeginTx begins a new database transaction
+func (r *reservationRepository) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
+	return r.db.BeginTxx(ctx, nil)
+}
+
+// CreateWithTx records a held reservation within a transaction
+func (r *reservationRepository) CreateWithTx(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) error {
+	if reservation.ID == "" {
+		reservation.ID = "res_" + uuid.New().String()[:8]
+	}
+	if reservation.Status == "" {
+		reservation.Status = domain.ReservationHeld
+	}
+	now := time.Now()
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	query := `
+		INSERT INTO balance_reservations (
+			id, user_id, amount, reference_type, reference_id, status,
+			expires_at, resolved_at, created_at, updated_at
+		) VALUES (
+			:id, :user_id, :amount, :reference_type, :reference_id, :status,
+			:expires_at, :resolved_at, :created_at, :updated_at
+		)
+	`
+	_, err := tx.NamedExecContext(ctx, query, reservation)
+	return err
+}
+
+// FindByIDForUpdate gets a reservation with row lock
+func (r *reservationRepository) FindByIDForUpdate(ctx context.Context, tx *sqlx.Tx, id string) (*domain.BalanceReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM balance_reservations WHERE id = $1 FOR UPDATE`
+
+	var reservation domain.BalanceReservation
+	if err := tx.GetContext(ctx, &reservation, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// FindHeldByReferenceForUpdate gets the held reservation of a reference with row lock
+func (r *reservationRepository) FindHeldByReferenceForUpdate(ctx context.Context, tx *sqlx.Tx, referenceType, referenceID string) (*domain.BalanceReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM balance_reservations
+		WHERE reference_type = $1 AND reference_id = $2 AND status = $3
+		FOR UPDATE`
+
+	var reservation domain.BalanceReservation
+	if err := tx.GetContext(ctx, &reservation, query, referenceType, referenceID, domain.ReservationHeld); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// FindLatestByReference gets the most recent reservation of a reference in any status
+func (r *reservationRepository) FindLatestByReference(ctx context.Context, referenceType, referenceID string) (*domain.BalanceReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM balance_reservations
+		WHERE reference_type = $1 AND reference_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	var reservation domain.BalanceReservation
+	if err := r.db.GetContext(ctx, &reservation, query, referenceType, referenceID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// FindExpiredHeld returns held reservations past their expiry, oldest first
+func (r *reservationRepository) FindExpiredHeld(ctx context.Context, now time.Time, limit int) ([]*domain.BalanceReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM balance_reservations
+		WHERE status = $1 AND expires_at <= $2
+		ORDER BY expires_at ASC
+		LIMIT $3`
+
+	reservations := []*domain.BalanceReservation{}
+	if err := r.db.SelectContext(ctx, &reservations, query, domain.ReservationHeld, now, limit); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// UpdateStatusWithTx settles or releases a reservation within a transaction
+func (r *reservationRepository) UpdateStatusWithTx(ctx context.Context, tx *sqlx.Tx, id, status string) error {
+	query := `UPDATE balance_reservations SET status = $1, resolved_at = NOW(), updated_at = NOW() WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, status, id)
+	return err
+}
+
+// MarkProviderCalled records that the provider call of a held reservation is
+// about to go out. It fails when the reservation is no longer held.
+func (r *reservationRepository) MarkProviderCalled(ctx context.Context, id string) error {
+	query := `UPDATE balance_reservations SET provider_called_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $2`
+	result, err := r.db.ExecContext(ctx, query, id, domain.ReservationHeld)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ExtendWithTx moves the expiry of a held reservation within a transaction
+func (r *reservationRepository) ExtendWithTx(ctx context.Context, tx *sqlx.Tx, id string, expiresAt time.Time) error {
+	query := `UPDATE balance_reservations SET expires_at = $1, updated_at = NOW() WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, expiresAt, id)
+	return err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// SupplierRepository defines the interface for supplier and routing data operations
+type SupplierRepository interface {
+	FindAll(ctx context.Context) ([]*domain.Supplier, error)
+	FindRoutesBySKU(ctx context.Context, skuCode string) ([]*domain.SupplierRoute, error)
+	FindRouteByID(ctx context.Context, id string) (*domain.SupplierRoute, error)
+	UpsertRoute(ctx context.Context, route *domain.SupplierRoute) error
+	DeleteRoute(ctx context.Context, id string) error
+}
+
+// supplierRepository implements SupplierRepository
+type supplierRepository struct {
+	db *sqlx.DB
+}
+
+// NewSupplierRepository creates a new supplier repository
+func NewSupplierRepository(db *sqlx.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+const supplierRouteColumns = `sp.id, sp.supplier_code, sp.sku_code, sp.supplier_sku_code, sp.cost_price,
+	sp.priority, sp.is_active, s.is_active AS supplier_active, sp.created_at, sp.updated_at`
+
+// FindAll returns every registered supplier
+func (r *supplierRepository) FindAll(ctx context.Context) ([]*domain.Supplier, error) {
+	query := `SELECT code, name, is_active, created_at, updated_at FROM suppliers ORDER BY code ASC`
+
+	suppliers := []*domain.Supplier{}
+	if err := r.db.SelectContext(ctx, &suppliers, query); err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+// FindRoutesBySKU returns every route configured for a SKU, cheapest first
+func (r *supplierRepository) FindRoutesBySKU(ctx context.Context, skuCode string) ([]*domain.SupplierRoute, error) {
+	query := `SELECT ` + supplierRouteColumns + ` FROM supplier_products sp
+		JOIN suppliers s ON s.code = sp.supplier_code
+		WHERE sp.sku_code = $1
+		ORDER BY sp.cost_price ASC, sp.priority DESC`
+
+	routes := []*domain.SupplierRoute{}
+	if err := r.db.SelectContext(ctx, &routes, query, skuCode); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// FindRouteByID finds a route by ID
+func (r *supplierRepository) FindRouteByID(ctx context.Context, id string) (*domain.SupplierRoute, error) {
+	query := `SELECT ` + supplierRouteColumns + ` FROM supplier_products sp
+		JOIN suppliers s ON s.code = sp.supplier_code
+		WHERE sp.id = $1`
+
+	var route domain.SupplierRoute
+	if err := r.db.GetContext(ctx, &route, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &route, nil
+}
+
+// UpsertRoute creates or replaces the route of a supplier for a SKU
+func (r *supplierRepository) UpsertRoute(ctx context.Context, route *domain.SupplierRoute) error {
+	if route.ID == "" {
+		route.ID = NewUUID()
+	}
+
+	query := `
+		INSERT INTO supplier_products (
+			id, supplier_code, sku_code, supplier_sku_code, cost_price, priority, is_active, created_at, updated_at
+		) VALUES (
+			:id, :supplier_code, :sku_code, :supplier_sku_code, :cost_price, :priority, :is_active, NOW(), NOW()
+		)
+		ON CONFLICT (supplier_code, sku_code) DO UPDATE SET
+			supplier_sku_code = EXCLUDED.supplier_sku_code,
+			cost_price = EXCLUDED.cost_price,
+			priority = EXCLUDED.priority,
+			is_active = EXCLUDED.is_active,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	rows, err := r.db.NamedQueryContext(ctx, query, route)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return rows.Scan(&route.ID, &route.CreatedAt, &route.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+// DeleteRoute removes a route
+func (r *supplierRepository) DeleteRoute(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM supplier_products WHERE id = $1`, id)
+	return err
+}
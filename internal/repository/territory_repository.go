@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/GTDGit/PPOB_BE/internal/domain"
@@ -36,8 +37,41 @@ type TerritoryRepository interface {
 	SearchByPostalCode(ctx context.Context, postalCode string) ([]*domain.PostalCodeSearchResult, error)
 	UpsertPostalCodes(ctx context.Context, postalCodes []*domain.PostalCode) error
 
+	// Search
+	Search(ctx context.Context, text, prefixQuery string, limit int) ([]*domain.TerritorySearchResult, error)
+	RefreshSearchIndex(ctx context.Context) error
+
 	// Sync
+	ListActiveCodes(ctx context.Context, syncType string) ([]string, error)
+	SoftDeleteTerritories(ctx context.Context, syncType string, codes []string) (int, error)
+	SoftDeletePostalCodes(ctx context.Context, subDistrictCode string, postalCodes []string) (int, error)
 	LogSync(ctx context.Context, log *domain.TerritorySyncLog) error
+	UpdateSyncLog(ctx context.Context, log *domain.TerritorySyncLog) error
+	GetLatestSyncRun(ctx context.Context) ([]*domain.TerritorySyncLog, error)
+}
+
+// territoryTable maps a sync type to its table and the column referencing the level above
+type territoryTable struct {
+	syncType     string
+	table        string
+	parentColumn string
+}
+
+// territoryTables lists the coded territory tables from the top of the hierarchy down
+var territoryTables = []territoryTable{
+	{domain.TerritorySyncTypeProvinces, "provinces", ""},
+	{domain.TerritorySyncTypeCities, "cities", "province_code"},
+	{domain.TerritorySyncTypeDistricts, "districts", "city_code"},
+	{domain.TerritorySyncTypeSubDistricts, "sub_districts", "district_code"},
+}
+
+func territoryTableIndex(syncType string) int {
+	for i, t := range territoryTables {
+		if t.syncType == syncType {
+			return i
+		}
+	}
+	return -1
 }
 
 // territoryRepository implements TerritoryRepository
@@ -53,14 +87,14 @@ func NewTerritoryRepository(db *sqlx.DB) TerritoryRepository {
 // ========== Province Methods ==========
 
 func (r *territoryRepository) GetAllProvinces(ctx context.Context) ([]*domain.Province, error) {
-	query := `SELECT code, name, created_at, updated_at FROM provinces ORDER BY name`
+	query := `SELECT code, name, created_at, updated_at FROM provinces WHERE deleted_at IS NULL ORDER BY name`
 	var provinces []*domain.Province
 	err := r.db.SelectContext(ctx, &provinces, query)
 	return provinces, err
 }
 
 func (r *territoryRepository) GetProvinceByCode(ctx context.Context, code string) (*domain.Province, error) {
-	query := `SELECT code, name, created_at, updated_at FROM provinces WHERE code = $1`
+	query := `SELECT code, name, created_at, updated_at FROM provinces WHERE code = $1 AND deleted_at IS NULL`
 	var province domain.Province
 	err := r.db.GetContext(ctx, &province, query, code)
 	if err == sql.ErrNoRows {
@@ -79,6 +113,7 @@ func (r *territoryRepository) UpsertProvinces(ctx context.Context, provinces []*
 		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		ON CONFLICT (code) DO UPDATE SET
 			name = EXCLUDED.name,
+			deleted_at = NULL,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
@@ -95,14 +130,14 @@ func (r *territoryRepository) UpsertProvinces(ctx context.Context, provinces []*
 // ========== City Methods ==========
 
 func (r *territoryRepository) GetCitiesByProvince(ctx context.Context, provinceCode string) ([]*domain.City, error) {
-	query := `SELECT code, province_code, name, created_at, updated_at FROM cities WHERE province_code = $1 ORDER BY name`
+	query := `SELECT code, province_code, name, created_at, updated_at FROM cities WHERE province_code = $1 AND deleted_at IS NULL ORDER BY name`
 	var cities []*domain.City
 	err := r.db.SelectContext(ctx, &cities, query, provinceCode)
 	return cities, err
 }
 
 func (r *territoryRepository) GetCityByCode(ctx context.Context, code string) (*domain.City, error) {
-	query := `SELECT code, province_code, name, created_at, updated_at FROM cities WHERE code = $1`
+	query := `SELECT code, province_code, name, created_at, updated_at FROM cities WHERE code = $1 AND deleted_at IS NULL`
 	var city domain.City
 	err := r.db.GetContext(ctx, &city, query, code)
 	if err == sql.ErrNoRows {
@@ -122,6 +157,7 @@ func (r *territoryRepository) UpsertCities(ctx context.Context, cities []*domain
 		ON CONFLICT (code) DO UPDATE SET
 			province_code = EXCLUDED.province_code,
 			name = EXCLUDED.name,
+			deleted_at = NULL,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
@@ -138,14 +174,14 @@ func (r *territoryRepository) UpsertCities(ctx context.Context, cities []*domain
 // ========== District Methods ==========
 
 func (r *territoryRepository) GetDistrictsByCity(ctx context.Context, cityCode string) ([]*domain.District, error) {
-	query := `SELECT code, city_code, name, created_at, updated_at FROM districts WHERE city_code = $1 ORDER BY name`
+	query := `SELECT code, city_code, name, created_at, updated_at FROM districts WHERE city_code = $1 AND deleted_at IS NULL ORDER BY name`
 	var districts []*domain.District
 	err := r.db.SelectContext(ctx, &districts, query, cityCode)
 	return districts, err
 }
 
 func (r *territoryRepository) GetDistrictByCode(ctx context.Context, code string) (*domain.District, error) {
-	query := `SELECT code, city_code, name, created_at, updated_at FROM districts WHERE code = $1`
+	query := `SELECT code, city_code, name, created_at, updated_at FROM districts WHERE code = $1 AND deleted_at IS NULL`
 	var district domain.District
 	err := r.db.GetContext(ctx, &district, query, code)
 	if err == sql.ErrNoRows {
@@ -165,6 +201,7 @@ func (r *territoryRepository) UpsertDistricts(ctx context.Context, districts []*
 		ON CONFLICT (code) DO UPDATE SET
 			city_code = EXCLUDED.city_code,
 			name = EXCLUDED.name,
+			deleted_at = NULL,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
@@ -181,14 +218,14 @@ func (r *territoryRepository) UpsertDistricts(ctx context.Context, districts []*
 // ========== SubDistrict Methods ==========
 
 func (r *territoryRepository) GetSubDistrictsByDistrict(ctx context.Context, districtCode string) ([]*domain.SubDistrict, error) {
-	query := `SELECT code, district_code, name, created_at, updated_at FROM sub_districts WHERE district_code = $1 ORDER BY name`
+	query := `SELECT code, district_code, name, created_at, updated_at FROM sub_districts WHERE district_code = $1 AND deleted_at IS NULL ORDER BY name`
 	var subDistricts []*domain.SubDistrict
 	err := r.db.SelectContext(ctx, &subDistricts, query, districtCode)
 	return subDistricts, err
 }
 
 func (r *territoryRepository) GetSubDistrictByCode(ctx context.Context, code string) (*domain.SubDistrict, error) {
-	query := `SELECT code, district_code, name, created_at, updated_at FROM sub_districts WHERE code = $1`
+	query := `SELECT code, district_code, name, created_at, updated_at FROM sub_districts WHERE code = $1 AND deleted_at IS NULL`
 	var subDistrict domain.SubDistrict
 	err := r.db.GetContext(ctx, &subDistrict, query, code)
 	if err == sql.ErrNoRows {
@@ -208,6 +245,7 @@ func (r *territoryRepository) UpsertSubDistricts(ctx context.Context, subDistric
 		ON CONFLICT (code) DO UPDATE SET
 			district_code = EXCLUDED.district_code,
 			name = EXCLUDED.name,
+			deleted_at = NULL,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
@@ -224,7 +262,7 @@ func (r *territoryRepository) UpsertSubDistricts(ctx context.Context, subDistric
 // ========== PostalCode Methods ==========
 
 func (r *territoryRepository) GetPostalCodesBySubDistrict(ctx context.Context, subDistrictCode string) ([]*domain.PostalCode, error) {
-	query := `SELECT id, sub_district_code, postal_code, created_at FROM postal_codes WHERE sub_district_code = $1`
+	query := `SELECT id, sub_district_code, postal_code, created_at FROM postal_codes WHERE sub_district_code = $1 AND deleted_at IS NULL`
 	var postalCodes []*domain.PostalCode
 	err := r.db.SelectContext(ctx, &postalCodes, query, subDistrictCode)
 	return postalCodes, err
@@ -244,6 +282,8 @@ func (r *territoryRepository) SearchByPostalCode(ctx context.Context, postalCode
 		JOIN cities c ON d.city_code = c.code
 		JOIN provinces p ON c.province_code = p.code
 		WHERE pc.postal_code = $1
+			AND pc.deleted_at IS NULL
+			AND sd.deleted_at IS NULL
 		ORDER BY sd.name
 	`
 
@@ -291,7 +331,8 @@ func (r *territoryRepository) UpsertPostalCodes(ctx context.Context, postalCodes
 	query := `
 		INSERT INTO postal_codes (sub_district_code, postal_code, created_at)
 		VALUES ($1, $2, CURRENT_TIMESTAMP)
-		ON CONFLICT (sub_district_code, postal_code) DO NOTHING
+		ON CONFLICT (sub_district_code, postal_code) DO UPDATE SET
+			deleted_at = NULL
 	`
 
 	for _, pc := range postalCodes {
@@ -304,23 +345,267 @@ func (r *territoryRepository) UpsertPostalCodes(ctx context.Context, postalCodes
 	return nil
 }
 
+// ========== Search Methods ==========
+
+// Search matches the territory_search index. text is the normalized query,
+// used for fuzzy (trigram) matching and name bonuses; prefixQuery is a
+// tsquery where every term must prefix-match a name in the row's hierarchy.
+func (r *territoryRepository) Search(ctx context.Context, text, prefixQuery string, limit int) ([]*domain.TerritorySearchResult, error) {
+	query := `
+		SELECT
+			level, code, name,
+			province_code, province_name,
+			COALESCE(city_code, ''), COALESCE(city_name, ''),
+			COALESCE(district_code, ''), COALESCE(district_name, ''),
+			COALESCE(postal_codes, ''),
+			(
+				CASE
+					WHEN search_name = $1 THEN 1.0
+					WHEN search_name LIKE $1 || '%' THEN 0.5
+					ELSE 0
+				END
+				+ word_similarity($1, search_path)
+				+ ts_rank(to_tsvector('simple', search_path), to_tsquery('simple', $2))
+				+ (4 - level_rank) * 0.05
+			) AS score
+		FROM territory_search
+		WHERE to_tsvector('simple', search_path) @@ to_tsquery('simple', $2)
+			OR $1 <% search_path
+		ORDER BY score DESC, level_rank ASC, name ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, text, prefixQuery, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*domain.TerritorySearchResult{}
+	for rows.Next() {
+		var result domain.TerritorySearchResult
+		var cityCode, cityName, districtCode, districtName, postalCodes string
+
+		if err := rows.Scan(
+			&result.Level, &result.Code, &result.Name,
+			&result.Province.Code, &result.Province.Name,
+			&cityCode, &cityName,
+			&districtCode, &districtName,
+			&postalCodes,
+			&result.Score,
+		); err != nil {
+			return nil, err
+		}
+
+		if cityCode != "" {
+			result.City = &domain.Location{Code: cityCode, Name: cityName}
+		}
+		if districtCode != "" {
+			result.District = &domain.Location{Code: districtCode, Name: districtName}
+		}
+		if result.Level == domain.TerritoryLevelSubDistrict {
+			result.SubDistrict = &domain.Location{Code: result.Code, Name: result.Name}
+		}
+		if postalCodes != "" {
+			result.PostalCodes = strings.Fields(postalCodes)
+		}
+
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
+
+// RefreshSearchIndex rebuilds the territory_search materialized view without
+// blocking readers
+func (r *territoryRepository) RefreshSearchIndex(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY territory_search`)
+	return err
+}
+
+// ========== Sync Methods ==========
+
+// ListActiveCodes returns the codes of all non-deleted rows of a level, in code order
+func (r *territoryRepository) ListActiveCodes(ctx context.Context, syncType string) ([]string, error) {
+	i := territoryTableIndex(syncType)
+	if i < 0 {
+		return nil, fmt.Errorf("unknown territory level %q", syncType)
+	}
+
+	query := fmt.Sprintf(`SELECT code FROM %s WHERE deleted_at IS NULL ORDER BY code`, territoryTables[i].table)
+	var codes []string
+	err := r.db.SelectContext(ctx, &codes, query)
+	return codes, err
+}
+
+// SoftDeleteTerritories marks codes of a level as deleted together with every
+// row below them in the hierarchy, returning the number of rows marked at the
+// given level
+func (r *territoryRepository) SoftDeleteTerritories(ctx context.Context, syncType string, codes []string) (int, error) {
+	start := territoryTableIndex(syncType)
+	if start < 0 {
+		return 0, fmt.Errorf("unknown territory level %q", syncType)
+	}
+	if len(codes) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query, args, err := sqlx.In(fmt.Sprintf(`
+		UPDATE %s SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE code IN (?) AND deleted_at IS NULL
+		RETURNING code
+	`, territoryTables[start].table), codes)
+	if err != nil {
+		return 0, err
+	}
+	var deleted []string
+	if err := tx.SelectContext(ctx, &deleted, tx.Rebind(query), args...); err != nil {
+		return 0, fmt.Errorf("failed to soft delete %s: %w", territoryTables[start].table, err)
+	}
+	count := len(deleted)
+
+	// Cascade down the hierarchy using the codes removed at the level above
+	parents := deleted
+	for _, child := range territoryTables[start+1:] {
+		if len(parents) == 0 {
+			break
+		}
+		query, args, err := sqlx.In(fmt.Sprintf(`
+			UPDATE %s SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE %s IN (?) AND deleted_at IS NULL
+			RETURNING code
+		`, child.table, child.parentColumn), parents)
+		if err != nil {
+			return 0, err
+		}
+		var next []string
+		if err := tx.SelectContext(ctx, &next, tx.Rebind(query), args...); err != nil {
+			return 0, fmt.Errorf("failed to soft delete %s: %w", child.table, err)
+		}
+		parents = next
+	}
+	// Whatever remains are sub-district codes, whose postal codes go too
+	if len(parents) > 0 {
+		query, args, err := sqlx.In(`
+			UPDATE postal_codes SET deleted_at = CURRENT_TIMESTAMP
+			WHERE sub_district_code IN (?) AND deleted_at IS NULL
+		`, parents)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			return 0, fmt.Errorf("failed to soft delete postal_codes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SoftDeletePostalCodes marks postal codes of a sub-district as deleted
+func (r *territoryRepository) SoftDeletePostalCodes(ctx context.Context, subDistrictCode string, postalCodes []string) (int, error) {
+	if len(postalCodes) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(`
+		UPDATE postal_codes SET deleted_at = CURRENT_TIMESTAMP
+		WHERE sub_district_code = ? AND postal_code IN (?) AND deleted_at IS NULL
+	`, subDistrictCode, postalCodes)
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to soft delete postal_codes of %s: %w", subDistrictCode, err)
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
 // ========== Sync Log Methods ==========
 
 func (r *territoryRepository) LogSync(ctx context.Context, log *domain.TerritorySyncLog) error {
 	query := `
-		INSERT INTO territory_sync_log (sync_type, total_records, status, error_message, started_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO territory_sync_log (
+			run_id, sync_type, total_records, inserted_count, updated_count, deleted_count,
+			checkpoint, status, error_message, started_at, completed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 
 	return r.db.QueryRowContext(
 		ctx,
 		query,
+		log.RunID,
 		log.SyncType,
 		log.TotalRecords,
+		log.InsertedCount,
+		log.UpdatedCount,
+		log.DeletedCount,
+		log.Checkpoint,
 		log.Status,
 		log.ErrorMessage,
 		log.StartedAt,
 		log.CompletedAt,
 	).Scan(&log.ID)
 }
+
+func (r *territoryRepository) UpdateSyncLog(ctx context.Context, log *domain.TerritorySyncLog) error {
+	query := `
+		UPDATE territory_sync_log SET
+			total_records = $2,
+			inserted_count = $3,
+			updated_count = $4,
+			deleted_count = $5,
+			checkpoint = $6,
+			status = $7,
+			error_message = $8,
+			completed_at = $9
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		log.ID,
+		log.TotalRecords,
+		log.InsertedCount,
+		log.UpdatedCount,
+		log.DeletedCount,
+		log.Checkpoint,
+		log.Status,
+		log.ErrorMessage,
+		log.CompletedAt,
+	)
+	return err
+}
+
+// GetLatestSyncRun returns the level logs of the most recent sync run, oldest first
+func (r *territoryRepository) GetLatestSyncRun(ctx context.Context) ([]*domain.TerritorySyncLog, error) {
+	query := `
+		SELECT id, run_id, sync_type, total_records, inserted_count, updated_count, deleted_count,
+			checkpoint, status, error_message, started_at, completed_at, created_at
+		FROM territory_sync_log
+		WHERE run_id = (
+			SELECT run_id FROM territory_sync_log
+			WHERE run_id IS NOT NULL
+			ORDER BY started_at DESC, id DESC
+			LIMIT 1
+		)
+		ORDER BY id ASC
+	`
+
+	var logs []*domain.TerritorySyncLog
+	err := r.db.SelectContext(ctx, &logs, query)
+	return logs, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/GTDGit/PPOB_BE/internal/domain"
@@ -23,6 +24,14 @@ type TransferRepository interface {
 	FindTransactionByInquiryID(ctx context.Context, inquiryID string) (*domain.TransferTransaction, error)
 	UpdateTransactionStatus(ctx context.Context, id, status string) error
 	UpdateTransactionStatusWithTx(ctx context.Context, tx *sqlx.Tx, id, status string) error
+	UpdateTransactionResultWithTx(ctx context.Context, dbtx *sqlx.Tx, tx *domain.TransferTransaction) error
+	DeleteTransactionWithTx(ctx context.Context, dbtx *sqlx.Tx, id string) error
+
+	// Fee schedule and limits
+	FindActiveFeeRules(ctx context.Context) ([]*domain.TransferFeeRule, error)
+	FindLimit(ctx context.Context, kycStatus string) (*domain.TransferLimit, error)
+	SumUsage(ctx context.Context, userID string, dayStart, monthStart time.Time) (domain.TransferUsage, error)
+	SumUsageWithTx(ctx context.Context, tx *sqlx.Tx, userID string, dayStart, monthStart time.Time) (domain.TransferUsage, error)
 
 	// Transaction management
 	BeginTx(ctx context.Context) (*sqlx.Tx, error)
@@ -40,11 +49,11 @@ func NewTransferRepository(db *sqlx.DB) TransferRepository {
 
 // Column constants for explicit SELECT
 const transferInquiryColumns = `id, user_id, bank_code, bank_name, account_number, account_name,
-	amount, admin_fee, total_payment, gerbang_inquiry_id, fee, expires_at, created_at`
+	amount, admin_fee, total_payment, gerbang_inquiry_id, fee, method, fee_rule_id, expires_at, created_at`
 
 const transferTransactionColumns = `id, public_id, user_id, inquiry_id, status, bank_code, bank_name,
 	account_number, account_name, amount, admin_fee, total_payment, note, balance_before,
-	balance_after, reference_number, gerbang_transfer_id, purpose, fee, completed_at,
+	balance_after, reference_number, gerbang_transfer_id, purpose, fee, method, completed_at,
 	created_at, updated_at`
 
 // BeginTx begins a new database transaction
@@ -57,10 +66,10 @@ func (r *transferRepository) CreateInquiry(ctx context.Context, inquiry *domain.
 	query := `
 		INSERT INTO transfer_inquiries (
 			id, user_id, bank_code, bank_name, account_number, account_name,
-			amount, admin_fee, total_payment, gerbang_inquiry_id, fee, expires_at, created_at
+			amount, admin_fee, total_payment, gerbang_inquiry_id, fee, method, fee_rule_id, expires_at, created_at
 		) VALUES (
 			:id, :user_id, :bank_code, :bank_name, :account_number, :account_name,
-			:amount, :admin_fee, :total_payment, :gerbang_inquiry_id, :fee, :expires_at, :created_at
+			:amount, :admin_fee, :total_payment, :gerbang_inquiry_id, :fee, :method, :fee_rule_id, :expires_at, :created_at
 		)
 		ON CONFLICT (id) DO UPDATE SET
 			user_id = EXCLUDED.user_id,
@@ -73,6 +82,8 @@ func (r *transferRepository) CreateInquiry(ctx context.Context, inquiry *domain.
 			total_payment = EXCLUDED.total_payment,
 			gerbang_inquiry_id = EXCLUDED.gerbang_inquiry_id,
 			fee = EXCLUDED.fee,
+			method = EXCLUDED.method,
+			fee_rule_id = EXCLUDED.fee_rule_id,
 			expires_at = EXCLUDED.expires_at
 	`
 	_, err := r.db.NamedExecContext(ctx, query, inquiry)
@@ -118,11 +129,11 @@ func (r *transferRepository) CreateTransaction(ctx context.Context, tx *domain.T
 		INSERT INTO transfer_transactions (
 			id, public_id, user_id, inquiry_id, status, bank_code, bank_name, account_number, account_name,
 			amount, admin_fee, total_payment, note, balance_before, balance_after,
-			reference_number, gerbang_transfer_id, purpose, fee, completed_at, created_at, updated_at
+			reference_number, gerbang_transfer_id, purpose, fee, method, completed_at, created_at, updated_at
 		) VALUES (
 			:id, :public_id, :user_id, :inquiry_id, :status, :bank_code, :bank_name, :account_number, :account_name,
 			:amount, :admin_fee, :total_payment, :note, :balance_before, :balance_after,
-			:reference_number, :gerbang_transfer_id, :purpose, :fee, :completed_at, :created_at, :updated_at
+			:reference_number, :gerbang_transfer_id, :purpose, :fee, :method, :completed_at, :created_at, :updated_at
 		)
 	`
 	_, err := r.db.NamedExecContext(ctx, query, tx)
@@ -146,11 +157,11 @@ func (r *transferRepository) CreateTransactionWithTx(ctx context.Context, dbtx *
 		INSERT INTO transfer_transactions (
 			id, public_id, user_id, inquiry_id, status, bank_code, bank_name, account_number, account_name,
 			amount, admin_fee, total_payment, note, balance_before, balance_after,
-			reference_number, gerbang_transfer_id, purpose, fee, completed_at, created_at, updated_at
+			reference_number, gerbang_transfer_id, purpose, fee, method, completed_at, created_at, updated_at
 		) VALUES (
 			:id, :public_id, :user_id, :inquiry_id, :status, :bank_code, :bank_name, :account_number, :account_name,
 			:amount, :admin_fee, :total_payment, :note, :balance_before, :balance_after,
-			:reference_number, :gerbang_transfer_id, :purpose, :fee, :completed_at, :created_at, :updated_at
+			:reference_number, :gerbang_transfer_id, :purpose, :fee, :method, :completed_at, :created_at, :updated_at
 		)
 	`
 	_, err := dbtx.NamedExecContext(ctx, query, tx)
@@ -192,3 +203,71 @@ func (r *transferRepository) UpdateTransactionStatusWithTx(ctx context.Context,
 	_, err := tx.ExecContext(ctx, query, status, id)
 	return err
 }
+
+// UpdateTransactionResultWithTx stores the provider result of a transaction within a database transaction
+func (r *transferRepository) UpdateTransactionResultWithTx(ctx context.Context, dbtx *sqlx.Tx, tx *domain.TransferTransaction) error {
+	query := `
+		UPDATE transfer_transactions SET
+			status = :status, total_payment = :total_payment, balance_after = :balance_after,
+			reference_number = :reference_number, gerbang_transfer_id = :gerbang_transfer_id,
+			fee = :fee, completed_at = :completed_at, updated_at = NOW()
+		WHERE id = :id
+	`
+	_, err := dbtx.NamedExecContext(ctx, query, tx)
+	return err
+}
+
+// DeleteTransactionWithTx removes a transaction whose provider call was rejected within a database transaction
+func (r *transferRepository) DeleteTransactionWithTx(ctx context.Context, dbtx *sqlx.Tx, id string) error {
+	_, err := dbtx.ExecContext(ctx, `DELETE FROM transfer_transactions WHERE id = $1`, id)
+	return err
+}
+
+// FindActiveFeeRules returns the active transfer fee rules
+func (r *transferRepository) FindActiveFeeRules(ctx context.Context) ([]*domain.TransferFeeRule, error) {
+	query := `
+		SELECT id, bank_code, user_tier, method, min_amount, max_amount, fee, priority, is_active, created_at, updated_at
+		FROM transfer_fee_rules
+		WHERE is_active = true
+	`
+	rules := []*domain.TransferFeeRule{}
+	if err := r.db.SelectContext(ctx, &rules, query); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindLimit finds the transfer limit of a KYC status
+func (r *transferRepository) FindLimit(ctx context.Context, kycStatus string) (*domain.TransferLimit, error) {
+	var limit domain.TransferLimit
+	query := `SELECT kyc_status, daily_limit, monthly_limit, updated_at FROM transfer_limits WHERE kyc_status = $1`
+	err := r.db.GetContext(ctx, &limit, query, kycStatus)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &limit, err
+}
+
+// transferUsageQuery sums the amount a user transferred since the start of the
+// day and month. Failed transfers do not count; rejected ones are deleted.
+const transferUsageQuery = `
+	SELECT
+		COALESCE(SUM(amount) FILTER (WHERE created_at >= $2), 0) AS daily,
+		COALESCE(SUM(amount), 0) AS monthly
+	FROM transfer_transactions
+	WHERE user_id = $1 AND created_at >= $3 AND status <> 'failed'
+`
+
+// SumUsage returns the user's transferred amount in the current day and month
+func (r *transferRepository) SumUsage(ctx context.Context, userID string, dayStart, monthStart time.Time) (domain.TransferUsage, error) {
+	var usage domain.TransferUsage
+	err := r.db.GetContext(ctx, &usage, transferUsageQuery, userID, dayStart, monthStart)
+	return usage, err
+}
+
+// SumUsageWithTx returns the user's transferred amount within a database transaction
+func (r *transferRepository) SumUsageWithTx(ctx context.Context, tx *sqlx.Tx, userID string, dayStart, monthStart time.Time) (domain.TransferUsage, error) {
+	var usage domain.TransferUsage
+	err := tx.GetContext(ctx, &usage, transferUsageQuery, userID, dayStart, monthStart)
+	return usage, err
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// accountLimitLocalTTL bounds how long an instance keeps enforcing limits
+// after admins change them on another instance
+const accountLimitLocalTTL = 30 * time.Second
+
+// AccountLimitService enforces the balance and outgoing caps of each KYC
+// level (including KYB-approved merchants) and tier, configured in the
+// account_limits admin setting
+type AccountLimitService struct {
+	repo        repository.AccountLimitRepository
+	userRepo    repository.UserRepository
+	balanceRepo repository.BalanceRepository
+	kybRepo     repository.KYBRepository
+
+	mu       sync.RWMutex
+	config   *domain.AccountLimitConfig
+	loadedAt time.Time
+}
+
+// NewAccountLimitService creates a new account limit service
+func NewAccountLimitService(
+	repo repository.AccountLimitRepository,
+	userRepo repository.UserRepository,
+	balanceRepo repository.BalanceRepository,
+	kybRepo repository.KYBRepository,
+) *AccountLimitService {
+	return &AccountLimitService{
+		repo:        repo,
+		userRepo:    userRepo,
+		balanceRepo: balanceRepo,
+		kybRepo:     kybRepo,
+	}
+}
+
+// CheckOutgoing rejects a payment of amount that exceeds the user's
+// per-transaction cap or remaining daily or monthly outgoing limit. Pass the
+// transaction that locked the user's balance so concurrent payments are
+// counted; tx may be nil for an early check before the payment starts.
+func (s *AccountLimitService) CheckOutgoing(ctx context.Context, tx *sqlx.Tx, userID string, amount int64) error {
+	if s == nil {
+		return nil
+	}
+	user, _, rule, err := s.ruleForUser(ctx, userID)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	usage, err := s.usage(ctx, tx, user.ID)
+	if err != nil {
+		return err
+	}
+	if violation := rule.OutgoingViolation(usage, amount); violation != nil {
+		return limitError(violation)
+	}
+	return nil
+}
+
+// CheckTopUp rejects a deposit of amount that would take the user's balance
+// above the maximum balance of their KYC level. Deposits still awaiting
+// payment or review count as if they were already credited.
+func (s *AccountLimitService) CheckTopUp(ctx context.Context, userID string, amount int64) error {
+	if s == nil {
+		return nil
+	}
+	_, _, rule, err := s.ruleForUser(ctx, userID)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	balance, err := s.balanceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	var current int64
+	if balance != nil {
+		current = balance.Amount + balance.PendingAmount
+	}
+	pending, err := s.repo.SumPendingDeposits(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get pending deposits: %w", err)
+	}
+	if violation := rule.BalanceViolation(current+pending, amount); violation != nil {
+		return limitError(violation)
+	}
+	return nil
+}
+
+// CreditViolation reports whether crediting a paid deposit of amount would
+// take the balance, locked by the caller at current, above the maximum
+// balance of the user's KYC level. It returns nil when the credit fits.
+func (s *AccountLimitService) CreditViolation(ctx context.Context, userID string, current, amount int64) (*domain.LimitViolation, error) {
+	if s == nil {
+		return nil, nil
+	}
+	_, _, rule, err := s.ruleForUser(ctx, userID)
+	if err != nil || rule == nil {
+		return nil, err
+	}
+	return rule.BalanceViolation(current, amount), nil
+}
+
+// GetLimits returns the user's caps and the headroom left on each
+func (s *AccountLimitService) GetLimits(ctx context.Context, userID string) (*domain.AccountLimitsResponse, error) {
+	user, kybStatus, rule, err := s.ruleForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrNotFound("User")
+	}
+	level := domain.AccountLevel(user.KYCStatus, kybStatus)
+	if rule == nil {
+		rule = &domain.AccountLimitRule{KYCLevel: level}
+	}
+
+	balance, err := s.balanceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	var current int64
+	if balance != nil {
+		current = balance.Amount + balance.PendingAmount
+	}
+	usage, err := s.usage(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AccountLimitsResponse{
+		KYCStatus:      user.KYCStatus,
+		KYBStatus:      kybStatus,
+		KYCLevel:       level,
+		Tier:           user.Tier,
+		Balance:        formatHeadroom(domain.NewLimitHeadroom(rule.MaxBalance, current)),
+		PerTransaction: formatHeadroom(domain.NewLimitHeadroom(rule.PerTransaction, 0)),
+		Daily:          formatHeadroom(domain.NewLimitHeadroom(rule.DailyOutgoing, usage.Daily)),
+		Monthly:        formatHeadroom(domain.NewLimitHeadroom(rule.MonthlyOutgoing, usage.Monthly)),
+	}, nil
+}
+
+// Invalidate drops the cached limits so the next check reloads them
+func (s *AccountLimitService) Invalidate() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// ruleForUser returns the user, their KYB status and the rule of their level.
+// The KYB submission is only looked up for verified users, as KYB approval
+// does not lift an unverified account.
+func (s *AccountLimitService) ruleForUser(ctx context.Context, userID string) (*domain.User, string, *domain.AccountLimitRule, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, "", nil, nil
+	}
+
+	kybStatus := domain.KYBStatusNone
+	if user.KYCStatus == domain.KYCStatusVerified && s.kybRepo != nil {
+		submission, err := s.kybRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to get kyb submission: %w", err)
+		}
+		if submission != nil {
+			kybStatus = submission.Status
+		}
+	}
+
+	level := domain.AccountLevel(user.KYCStatus, kybStatus)
+	return user, kybStatus, s.current(ctx).SelectLevel(level, user.Tier), nil
+}
+
+func (s *AccountLimitService) usage(ctx context.Context, tx *sqlx.Tx, userID string) (domain.OutgoingUsage, error) {
+	dayStart, monthStart := domain.TransferPeriodStart(time.Now())
+	var usage domain.OutgoingUsage
+	var err error
+	if tx != nil {
+		usage, err = s.repo.SumOutgoingWithTx(ctx, tx, userID, dayStart.Local(), monthStart.Local())
+	} else {
+		usage, err = s.repo.SumOutgoing(ctx, userID, dayStart.Local(), monthStart.Local())
+	}
+	if err != nil {
+		return usage, fmt.Errorf("failed to get outgoing usage: %w", err)
+	}
+	return usage, nil
+}
+
+// current returns the configured limits, or the defaults when the setting is
+// missing or invalid. A failed reload keeps the limits loaded before.
+func (s *AccountLimitService) current(ctx context.Context) *domain.AccountLimitConfig {
+	s.mu.RLock()
+	config, loadedAt := s.config, s.loadedAt
+	s.mu.RUnlock()
+	if config != nil && time.Since(loadedAt) < accountLimitLocalTTL {
+		return config
+	}
+
+	loaded, err := s.repo.FindConfig(ctx)
+	if err != nil {
+		slog.Warn("failed to load account limits", slog.String("error", err.Error()))
+		if config != nil {
+			return config
+		}
+		return domain.DefaultAccountLimits()
+	}
+	if loaded == nil {
+		loaded = domain.DefaultAccountLimits()
+	} else if err := loaded.Validate(); err != nil {
+		slog.Warn("invalid account limits setting, using defaults", slog.String("error", err.Error()))
+		loaded = domain.DefaultAccountLimits()
+	}
+
+	s.mu.Lock()
+	s.config = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return loaded
+}
+
+func limitError(violation *domain.LimitViolation) error {
+	remaining := formatCurrency(violation.Remaining)
+	switch violation.Kind {
+	case domain.LimitPerTransaction:
+		return domain.ErrAccountLimitExceeded(fmt.Sprintf("Nominal melebihi limit per transaksi (%s)", formatCurrency(violation.Limit)))
+	case domain.LimitDaily:
+		return domain.ErrAccountLimitExceeded(fmt.Sprintf("Nominal melebihi sisa limit transaksi harian (%s)", remaining))
+	case domain.LimitMonthly:
+		return domain.ErrAccountLimitExceeded(fmt.Sprintf("Nominal melebihi sisa limit transaksi bulanan (%s)", remaining))
+	default:
+		return domain.ErrBalanceLimitExceeded(fmt.Sprintf("Saldo maksimal akun %s, sisa ruang saldo %s", formatCurrency(violation.Limit), remaining))
+	}
+}
+
+func formatHeadroom(headroom domain.LimitHeadroom) domain.LimitHeadroom {
+	if headroom.Unlimited {
+		headroom.RemainingFormatted = "Tanpa limit"
+	} else {
+		headroom.RemainingFormatted = formatCurrency(headroom.Remaining)
+	}
+	return headroom
+}
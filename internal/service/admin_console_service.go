@@ -1,16 +1,22 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"mime/multipart"
 	"strings"
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/pkg/qris"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 func (s *AdminService) DashboardSummary(ctx context.Context) (*domain.AdminDashboardResponse, error) {
@@ -63,6 +69,11 @@ func (s *AdminService) GetCustomerDetail(ctx context.Context, userID string) (ma
 	if item == nil {
 		return nil, domain.ErrNotFound("Pelanggan")
 	}
+	threads, err := s.repo.ListCustomerEmailThreads(ctx, userID, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer email threads: %w", err)
+	}
+	item["email_threads"] = threads
 	return item, nil
 }
 
@@ -132,21 +143,30 @@ func (s *AdminService) ApproveDeposit(ctx context.Context, actorID, depositID st
 	if deposit.Status == domain.DepositStatusSuccess {
 		return domain.NewError("DEPOSIT_ALREADY_APPROVED", "Deposit sudah berhasil", 409)
 	}
-	if deposit.Status != domain.DepositStatusPending {
-		return domain.NewError("DEPOSIT_INVALID_STATUS", "Hanya deposit pending yang dapat di-approve", 409)
+	if deposit.Status != domain.DepositStatusPending && deposit.Status != domain.DepositStatusReview {
+		return domain.NewError("DEPOSIT_INVALID_STATUS", "Hanya deposit pending atau dalam peninjauan yang dapat di-approve", 409)
 	}
 
 	var balance struct {
-		ID     string `db:"id"`
-		UserID string `db:"user_id"`
-		Amount int64  `db:"amount"`
+		ID            string `db:"id"`
+		UserID        string `db:"user_id"`
+		Amount        int64  `db:"amount"`
+		PendingAmount int64  `db:"pending_amount"`
 	}
 	if err := tx.GetContext(ctx, &balance, `
-		SELECT id, user_id, amount FROM balances WHERE user_id = $1 FOR UPDATE
+		SELECT id, user_id, amount, pending_amount FROM balances WHERE user_id = $1 FOR UPDATE
 	`, deposit.UserID); err != nil {
 		return fmt.Errorf("failed to load balance: %w", err)
 	}
 
+	violation, err := s.limits.CreditViolation(ctx, deposit.UserID, balance.Amount+balance.PendingAmount, deposit.Amount)
+	if err != nil {
+		return err
+	}
+	if violation != nil {
+		return limitError(violation)
+	}
+
 	before := balance.Amount
 	after := before + deposit.Amount
 
@@ -175,7 +195,7 @@ func (s *AdminService) ApproveDeposit(ctx context.Context, actorID, depositID st
 	}
 
 	_ = s.logAudit(ctx, actorID, "deposit.approve", "deposit", depositID, map[string]interface{}{
-		"status": domain.DepositStatusPending,
+		"status": deposit.Status,
 	}, map[string]interface{}{
 		"status": domain.DepositStatusSuccess,
 	}, "", "", "success", nil)
@@ -184,7 +204,7 @@ func (s *AdminService) ApproveDeposit(ctx context.Context, actorID, depositID st
 
 func (s *AdminService) RejectDeposit(ctx context.Context, actorID, depositID string) error {
 	if _, err := s.repo.DB().ExecContext(ctx, `
-		UPDATE deposits SET status = 'failed', updated_at = NOW() WHERE id = $1 AND status = 'pending'
+		UPDATE deposits SET status = 'failed', updated_at = NOW() WHERE id = $1 AND status IN ('pending', 'review')
 	`, depositID); err != nil {
 		return fmt.Errorf("failed to reject deposit: %w", err)
 	}
@@ -274,36 +294,94 @@ func (s *AdminService) ListCatalog(ctx context.Context, search string, page, per
 	}, nil
 }
 
-func (s *AdminService) CreatePricingRequest(ctx context.Context, actorID string, payload map[string]interface{}, reason string) error {
-	productID := stringValue(payload["productId"])
-	if productID == "" {
-		return domain.ErrValidationFailed("productId wajib diisi")
+func (s *AdminService) ListMaintenanceWindows(ctx context.Context, includeEnded bool) ([]*domain.MaintenanceWindow, error) {
+	return s.maintenance.List(ctx, includeEnded)
+}
+
+func (s *AdminService) CreateMaintenanceWindow(ctx context.Context, actorID string, input MaintenanceInput) (*domain.MaintenanceWindow, error) {
+	window, err := s.maintenance.Create(ctx, actorID, input)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.logAudit(ctx, actorID, "maintenance.create", "maintenance_window", window.ID, nil, window, "", "", "success", nil)
+	return window, nil
+}
+
+func (s *AdminService) UpdateMaintenanceWindow(ctx context.Context, actorID, id string, input MaintenanceInput) (*domain.MaintenanceWindow, error) {
+	existing, window, err := s.maintenance.Update(ctx, id, input)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.logAudit(ctx, actorID, "maintenance.update", "maintenance_window", window.ID, existing, window, "", "", "success", nil)
+	return window, nil
+}
+
+func (s *AdminService) EndMaintenanceWindow(ctx context.Context, actorID, id string) (*domain.MaintenanceWindow, error) {
+	window, err := s.maintenance.End(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.logAudit(ctx, actorID, "maintenance.end", "maintenance_window", window.ID, nil, window, "", "", "success", nil)
+	return window, nil
+}
+
+func (s *AdminService) ListPricingRules(ctx context.Context) ([]*domain.PricingRule, error) {
+	return s.pricing.ListRules(ctx)
+}
+
+func (s *AdminService) PreviewPricingChange(ctx context.Context, input PricingChangeRequest) (*domain.PricingPreview, error) {
+	change, err := s.pricing.ResolveChange(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricing.Preview(ctx, change)
+}
+
+func (s *AdminService) PreviewPricingRequest(ctx context.Context, approvalID string) (*domain.PricingPreview, error) {
+	req, err := s.repo.FindApprovalRequestByID(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || req.RequestType != "price_change" {
+		return nil, domain.ErrNotFound("Approval")
 	}
-	product, err := s.repo.FindProductByID(ctx, productID)
+	input, err := pricingChangeFromPayload(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return s.PreviewPricingChange(ctx, input)
+}
+
+func (s *AdminService) CreatePricingRequest(ctx context.Context, actorID string, input PricingChangeRequest, reason string) error {
+	change, err := s.pricing.ResolveChange(ctx, input)
 	if err != nil {
 		return err
 	}
-	if product == nil {
-		return domain.ErrNotFound("Produk")
+	preview, err := s.pricing.Preview(ctx, change)
+	if err != nil {
+		return err
 	}
 
-	req := &domain.AdminApprovalRequest{
-		ID:           "apr_" + uuid.New().String()[:8],
-		RequesterID:  actorID,
-		RequestType:  "price_change",
-		ResourceType: "product",
-		ResourceID:   sqlNullString(productID),
-		Reason:       sqlNullString(reason),
-		Payload: map[string]interface{}{
-			"productId":   productID,
-			"productName": product["name"],
-			"oldPrice":    product["price"],
-			"oldAdminFee": product["admin_fee"],
-			"newPrice":    payload["newPrice"],
-			"newAdminFee": payload["newAdminFee"],
+	payload := map[string]interface{}{
+		"action": change.Action,
+		"ruleId": change.RuleID,
+		"rule":   change.Rule,
+		"impact": map[string]interface{}{
+			"userTier":      preview.UserTier,
+			"affectedCount": preview.AffectedCount,
+			"increased":     preview.Increased,
+			"decreased":     preview.Decreased,
 		},
-		Status:    domain.ApprovalStatusPending,
-		CreatedAt: time.Now(),
+	}
+	if input.ProductID != "" {
+		payload["productId"] = input.ProductID
+		payload["newPrice"] = input.NewPrice
+		payload["newAdminFee"] = input.NewAdminFee
+	}
+
+	req, err := s.newApprovalRequest(ctx, actorID, "price_change", "pricing_rule", change.RuleID, reason, payload)
+	if err != nil {
+		return err
 	}
 	if err := s.repo.CreateApprovalRequest(ctx, req); err != nil {
 		return err
@@ -318,16 +396,12 @@ func (s *AdminService) CreateBalanceAdjustmentRequest(ctx context.Context, actor
 	if userID == "" {
 		return domain.ErrValidationFailed("userId wajib diisi")
 	}
-	req := &domain.AdminApprovalRequest{
-		ID:           "apr_" + uuid.New().String()[:8],
-		RequesterID:  actorID,
-		RequestType:  "balance_adjustment",
-		ResourceType: "balance",
-		ResourceID:   sqlNullString(userID),
-		Reason:       sqlNullString(reason),
-		Payload:      payload,
-		Status:       domain.ApprovalStatusPending,
-		CreatedAt:    time.Now(),
+	if int64Value(payload["amountDelta"]) == 0 {
+		return domain.ErrValidationFailed("amountDelta wajib diisi")
+	}
+	req, err := s.newApprovalRequest(ctx, actorID, "balance_adjustment", "balance", userID, reason, payload)
+	if err != nil {
+		return err
 	}
 	if err := s.repo.CreateApprovalRequest(ctx, req); err != nil {
 		return err
@@ -337,8 +411,50 @@ func (s *AdminService) CreateBalanceAdjustmentRequest(ctx context.Context, actor
 	return nil
 }
 
-func (s *AdminService) ListKYC(ctx context.Context, search, status string, page, perPage int) (*domain.AdminListResponse, error) {
-	items, total, err := s.repo.ListKYC(ctx, search, status, page, perPage)
+// newApprovalRequest builds a pending request and copies onto it the policy
+// that applies to its type and amount. Without a policy a single approval
+// from any approver suffices and the request does not expire.
+func (s *AdminService) newApprovalRequest(ctx context.Context, actorID, requestType, resourceType, resourceID, reason string, payload map[string]interface{}) (*domain.AdminApprovalRequest, error) {
+	policies, err := s.repo.ListApprovalPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approval policies: %w", err)
+	}
+
+	now := time.Now()
+	req := &domain.AdminApprovalRequest{
+		ID:                "apr_" + uuid.New().String()[:8],
+		RequesterID:       actorID,
+		RequestType:       requestType,
+		ResourceType:      resourceType,
+		ResourceID:        sqlNullString(resourceID),
+		Reason:            sqlNullString(reason),
+		Payload:           payload,
+		Status:            domain.ApprovalStatusPending,
+		CreatedAt:         now,
+		Amount:            approvalAmount(requestType, payload),
+		RequiredApprovals: 1,
+	}
+	if policy := domain.SelectApprovalPolicy(policies, requestType, req.Amount); policy != nil {
+		req.PolicyID = sqlNullString(policy.ID)
+		req.RequiredApprovals = policy.RequiredApprovals
+		req.ApproverRoleID = policy.ApproverRoleID
+		req.ExpiresAt = sql.NullTime{Time: now.Add(time.Duration(policy.ExpiryHours) * time.Hour), Valid: true}
+	}
+	return req, nil
+}
+
+// approvalAmount is the value policy thresholds are compared against
+func approvalAmount(requestType string, payload map[string]interface{}) int64 {
+	switch requestType {
+	case "balance_adjustment":
+		return absInt64(int64Value(payload["amountDelta"]))
+	default:
+		return 0
+	}
+}
+
+func (s *AdminService) ListKYC(ctx context.Context, search, status string, flaggedOnly bool, page, perPage int) (*domain.AdminListResponse, error) {
+	items, total, err := s.repo.ListKYC(ctx, search, status, flaggedOnly, page, perPage)
 	if err != nil {
 		return nil, err
 	}
@@ -353,9 +469,40 @@ func (s *AdminService) GetKYCDetail(ctx context.Context, userID string) (map[str
 	if item == nil {
 		return nil, domain.ErrNotFound("KYC")
 	}
+	matches, err := s.repo.ListKYCIdentityMatches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	decisions, err := s.repo.ListKYCDecisions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.repo.ListKYCArtefactAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	item["identity_matches"] = matches
+	item["decisions"] = decisions
+	item["artefact_access"] = access
 	return item, nil
 }
 
+// ViewKYCArtefact issues a short-lived link to a user's KTP or face photo.
+// Every link is recorded in the artefact access log and the audit log.
+func (s *AdminService) ViewKYCArtefact(ctx context.Context, actorID, userID, artefact, ipAddress, userAgent string) (*ArtefactLink, error) {
+	switch artefact {
+	case domain.KYCArtefactKTP, domain.KYCArtefactFace, domain.KYCArtefactFaceWithKTP:
+	default:
+		return nil, domain.ErrValidationFailed("Dokumen KYC harus ktp, face atau face_with_ktp")
+	}
+	return s.issueArtefactLink(ctx, actorID, userID, artefact, "kyc.artefact_view", ipAddress, userAgent)
+}
+
+// RejectKYCRequest carries the per-artefact reasons of a KYC rejection
+type RejectKYCRequest struct {
+	Reasons []domain.KYCRejectionReason `json:"reasons"`
+}
+
 func (s *AdminService) ApproveKYC(ctx context.Context, actorID, userID string) error {
 	ok, err := s.repo.HasKYCVerification(ctx, userID)
 	if err != nil {
@@ -364,21 +511,204 @@ func (s *AdminService) ApproveKYC(ctx context.Context, actorID, userID string) e
 	if !ok {
 		return domain.ErrValidationFailed("Data verifikasi KYC belum tersedia")
 	}
-	if err := s.repo.UpdateUserKYCStatus(ctx, userID, domain.KYCStatusVerified); err != nil {
+	history := &domain.KYCHistory{
+		Action:   domain.KYCActionVerificationApproved,
+		Status:   "success",
+		Metadata: map[string]interface{}{"adminId": actorID},
+	}
+	if err := s.repo.RecordKYCDecision(ctx, userID, domain.KYCStatusVerified, history); err != nil {
 		return fmt.Errorf("failed to approve kyc: %w", err)
 	}
 	_ = s.logAudit(ctx, actorID, "kyc.approve", "user", userID, nil, map[string]interface{}{"kycStatus": domain.KYCStatusVerified}, "", "", "success", nil)
 	return nil
 }
 
-func (s *AdminService) RejectKYC(ctx context.Context, actorID, userID string) error {
-	if err := s.repo.UpdateUserKYCStatus(ctx, userID, domain.KYCStatusRejected); err != nil {
+// RejectKYC rejects a verification with structured reasons per artefact. The
+// reasons are kept in kyc_history so the user can see them and resubmit only
+// the steps that failed review.
+func (s *AdminService) RejectKYC(ctx context.Context, actorID, userID string, req RejectKYCRequest) error {
+	rejection, err := domain.NewKYCRejection(req.Reasons, time.Now())
+	if err != nil {
+		return err
+	}
+	history := &domain.KYCHistory{
+		Action: domain.KYCActionVerificationRejected,
+		Status: "success",
+		Metadata: map[string]interface{}{
+			"adminId":          actorID,
+			"reasons":          rejection.Reasons,
+			"resubmitFromStep": rejection.ResubmitFromStep,
+		},
+	}
+	if err := s.repo.RecordKYCDecision(ctx, userID, domain.KYCStatusRejected, history); err != nil {
 		return fmt.Errorf("failed to reject kyc: %w", err)
 	}
-	_ = s.logAudit(ctx, actorID, "kyc.reject", "user", userID, nil, map[string]interface{}{"kycStatus": domain.KYCStatusRejected}, "", "", "success", nil)
+
+	if err := s.repo.BroadcastNotification(ctx, []string{userID}, map[string]interface{}{
+		"category":  domain.NotificationCategoryInfo,
+		"title":     "Verifikasi KYC Ditolak",
+		"body":      "Verifikasi identitas kamu belum dapat disetujui: " + rejection.Summary() + ". Silakan kirim ulang data yang diminta.",
+		"shortBody": rejection.Summary(),
+		"metadata": map[string]interface{}{
+			"type":             "kyc_rejected",
+			"resubmitFromStep": rejection.ResubmitFromStep,
+		},
+	}); err != nil {
+		slog.Warn("failed to notify kyc rejection",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	_ = s.logAudit(ctx, actorID, "kyc.reject", "user", userID, nil, map[string]interface{}{
+		"kycStatus": domain.KYCStatusRejected,
+		"reasons":   rejection.Reasons,
+	}, "", "", "success", nil)
+	return nil
+}
+
+func (s *AdminService) ListKYB(ctx context.Context, search, status string, page, perPage int) (*domain.AdminListResponse, error) {
+	items, total, err := s.repo.ListKYB(ctx, search, status, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	return paginated(items, total, page, perPage), nil
+}
+
+func (s *AdminService) GetKYBDetail(ctx context.Context, userID string) (map[string]interface{}, error) {
+	item, err := s.repo.GetKYBDetail(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, domain.ErrNotFound("KYB")
+	}
+	access, err := s.repo.ListKYCArtefactAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	item["artefact_access"] = access
+	return item, nil
+}
+
+// ViewKYBArtefact issues a short-lived link to one of a merchant's business
+// documents
+func (s *AdminService) ViewKYBArtefact(ctx context.Context, actorID, userID, document, ipAddress, userAgent string) (*ArtefactLink, error) {
+	switch document {
+	case domain.KYBDocumentLicense, domain.KYBDocumentNPWP, domain.KYBDocumentStorefront:
+	default:
+		return nil, domain.ErrValidationFailed("Dokumen KYB harus license, npwp atau storefront")
+	}
+	return s.issueArtefactLink(ctx, actorID, userID, document, "kyb.artefact_view", ipAddress, userAgent)
+}
+
+func (s *AdminService) issueArtefactLink(ctx context.Context, actorID, userID, artefact, action, ipAddress, userAgent string) (*ArtefactLink, error) {
+	link, err := s.artefacts.IssueViewLink(ctx, actorID, userID, artefact, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.logAudit(ctx, actorID, action, "user", userID, nil, map[string]interface{}{
+		"artefact":  artefact,
+		"expiresAt": link.ExpiresAt,
+	}, ipAddress, userAgent, "success", nil)
+	return link, nil
+}
+
+// RejectKYBRequest carries the reason shown to the merchant
+type RejectKYBRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ApproveKYB verifies a merchant's business, which moves the account to the
+// merchant limits and names the static QRIS after the business
+func (s *AdminService) ApproveKYB(ctx context.Context, actorID, userID string) error {
+	submission, err := s.repo.RecordKYBDecision(ctx, userID, actorID, domain.KYBStatusApproved, nil)
+	if err != nil {
+		return fmt.Errorf("failed to approve kyb: %w", err)
+	}
+	if submission == nil {
+		return domain.ErrValidationFailed("Tidak ada pengajuan KYB yang menunggu review")
+	}
+	s.renderStaticQRIS(ctx, userID)
+
+	s.notifyKYBDecision(ctx, userID, "Verifikasi Usaha Disetujui",
+		"Usaha "+submission.BusinessName+" sudah terverifikasi. Limit akun merchant dan nama QRIS usaha kamu sudah aktif.",
+		"kyb_approved")
+	_ = s.logAudit(ctx, actorID, "kyb.approve", "user", userID, nil, map[string]interface{}{
+		"kybStatus":        domain.KYBStatusApproved,
+		"businessName":     submission.BusinessName,
+		"qrisMerchantName": submission.QRISMerchantName(),
+	}, "", "", "success", nil)
+	return nil
+}
+
+// qrisImageSize is the width and height in pixels of rendered QRIS images
+const qrisImageSize = 512
+
+// renderStaticQRIS uploads a fresh image for a static QRIS whose payload was
+// rewritten. Failures only leave the image empty, which clients render from
+// the payload instead.
+func (s *AdminService) renderStaticQRIS(ctx context.Context, userID string) {
+	code, err := s.repo.FindQRISStaticWithoutImage(ctx, userID)
+	if err != nil || code == "" || s.publicS3 == nil {
+		return
+	}
+	if err := s.uploadStaticQRIS(ctx, userID, code); err != nil {
+		slog.Warn("failed to render static qris image", slog.String("user_id", userID), slog.String("error", err.Error()))
+	}
+}
+
+func (s *AdminService) uploadStaticQRIS(ctx context.Context, userID, code string) error {
+	image, err := qris.PNG(code, qrisImageSize)
+	if err != nil {
+		return err
+	}
+	imageURL, err := s.publicS3.UploadBytes(ctx, image, "qris", "static.png", "image/png")
+	if err != nil {
+		return err
+	}
+	return s.repo.SetQRISStaticImage(ctx, userID, code, imageURL)
+}
+
+// RejectKYB sends a submission back to the merchant with the reviewer's reason
+func (s *AdminService) RejectKYB(ctx context.Context, actorID, userID string, req RejectKYBRequest) error {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return domain.ErrValidationFailed("Alasan penolakan KYB wajib diisi")
+	}
+	submission, err := s.repo.RecordKYBDecision(ctx, userID, actorID, domain.KYBStatusRejected, &reason)
+	if err != nil {
+		return fmt.Errorf("failed to reject kyb: %w", err)
+	}
+	if submission == nil {
+		return domain.ErrValidationFailed("Tidak ada pengajuan KYB yang menunggu review")
+	}
+
+	s.notifyKYBDecision(ctx, userID, "Verifikasi Usaha Ditolak",
+		"Verifikasi usaha kamu belum dapat disetujui: "+reason+". Silakan perbaiki data lalu kirim ulang.",
+		"kyb_rejected")
+	_ = s.logAudit(ctx, actorID, "kyb.reject", "user", userID, nil, map[string]interface{}{
+		"kybStatus": domain.KYBStatusRejected,
+		"reason":    reason,
+	}, "", "", "success", nil)
 	return nil
 }
 
+func (s *AdminService) notifyKYBDecision(ctx context.Context, userID, title, body, notificationType string) {
+	if err := s.repo.BroadcastNotification(ctx, []string{userID}, map[string]interface{}{
+		"category":  domain.NotificationCategoryInfo,
+		"title":     title,
+		"body":      body,
+		"shortBody": title,
+		"metadata":  map[string]interface{}{"type": notificationType},
+	}); err != nil {
+		slog.Warn("failed to notify kyb decision",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
 func (s *AdminService) ListBanners(ctx context.Context) ([]map[string]interface{}, error) {
 	return s.repo.ListBanners(ctx)
 }
@@ -472,73 +802,289 @@ func (s *AdminService) ListApprovals(ctx context.Context, status string, page, p
 	return paginated(items, total, page, perPage), nil
 }
 
-func (s *AdminService) ApproveApproval(ctx context.Context, actorID, approvalID string) error {
-	req, err := s.repo.FindApprovalRequestByID(ctx, approvalID)
+// ApproveApproval records the actor's approval. Once the request has the
+// approvals its policy requires, the change is applied in the same database
+// transaction as the decision and its events, so a failed apply leaves the
+// request pending with nothing recorded.
+func (s *AdminService) ApproveApproval(ctx context.Context, actorID, approvalID string) (*domain.AdminApprovalRequest, error) {
+	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
-		return err
-	}
-	if req == nil {
-		return domain.ErrNotFound("Approval")
+		return nil, err
 	}
-	if req.Status != domain.ApprovalStatusPending {
-		return domain.ErrValidationFailed("Request approval tidak lagi pending")
+	defer tx.Rollback()
+
+	req, err := s.lockPendingApproval(ctx, tx, approvalID)
+	if err != nil {
+		return nil, err
 	}
 	if req.RequesterID == actorID {
-		return domain.NewError("APPROVAL_SELF_FORBIDDEN", "Maker tidak boleh approve request sendiri", 403)
+		return nil, domain.NewError("APPROVAL_SELF_FORBIDDEN", "Maker tidak boleh approve request sendiri", 403)
+	}
+	if err := s.checkApprover(ctx, actorID, req); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.UpdateApprovalDecision(ctx, approvalID, actorID, domain.ApprovalStatusApproved, nil); err != nil {
-		return err
+	if err := s.repo.AddApprovalDecisionWithTx(ctx, tx, approvalID, actorID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, domain.NewError("APPROVAL_ALREADY_GIVEN", "Anda sudah approve request ini", 409)
+		}
+		return nil, err
+	}
+	if err := s.repo.AddApprovalEventWithTx(ctx, tx, approvalID, &actorID, "approved", ""); err != nil {
+		return nil, err
+	}
+	approvals, err := s.repo.CountApprovalDecisionsWithTx(ctx, tx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	req.Approvals = approvals
+
+	if approvals < req.RequiredApprovals {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		_ = s.logAudit(ctx, actorID, "approval.approve", "approval_request", approvalID, nil, map[string]interface{}{
+			"requestType":       req.RequestType,
+			"approvals":         approvals,
+			"requiredApprovals": req.RequiredApprovals,
+		}, "", "", "success", nil)
+		return req, nil
 	}
-	_ = s.repo.AddApprovalEvent(ctx, approvalID, actorID, "approved", "")
 
+	if err := s.repo.UpdateApprovalDecisionWithTx(ctx, tx, approvalID, &actorID, domain.ApprovalStatusApproved, nil); err != nil {
+		return nil, err
+	}
 	switch req.RequestType {
 	case "price_change":
-		payload := mapValue(req.Payload)
-		productID := stringValue(payload["productId"])
-		if err := s.repo.UpdateProductPricing(ctx, productID, int64Value(payload["newPrice"]), int64Value(payload["newAdminFee"])); err != nil {
-			return err
+		if err := s.applyPricingChange(ctx, tx, req.Payload); err != nil {
+			return nil, err
 		}
 	case "balance_adjustment":
-		if err := s.applyBalanceAdjustment(ctx, approvalID, actorID, mapValue(req.Payload)); err != nil {
-			return err
+		if err := s.applyBalanceAdjustment(ctx, tx, approvalID, mapValue(req.Payload)); err != nil {
+			return nil, err
 		}
 	}
+	if err := s.repo.MarkApprovalAppliedWithTx(ctx, tx, approvalID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddApprovalEventWithTx(ctx, tx, approvalID, &actorID, "applied", ""); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	req.Status = domain.ApprovalStatusApplied
 
-	if err := s.repo.MarkApprovalApplied(ctx, approvalID); err != nil {
-		return err
+	switch req.RequestType {
+	case "price_change":
+		s.pricing.Refresh(ctx)
+	case "balance_adjustment":
+		_ = s.logAudit(ctx, actorID, "balance.adjustment.applied", "approval_request", approvalID, nil, req.Payload, "", "", "success", nil)
 	}
-	_ = s.repo.AddApprovalEvent(ctx, approvalID, actorID, "applied", "")
 	_ = s.logAudit(ctx, actorID, "approval.approve", "approval_request", approvalID, nil, map[string]interface{}{
-		"requestType": req.RequestType,
+		"requestType":       req.RequestType,
+		"approvals":         approvals,
+		"requiredApprovals": req.RequiredApprovals,
 	}, "", "", "success", nil)
-	return nil
+	return req, nil
 }
 
-func (s *AdminService) RejectApproval(ctx context.Context, actorID, approvalID, reason string) error {
-	req, err := s.repo.FindApprovalRequestByID(ctx, approvalID)
+// applyPricingChange re-validates the stored rule change against the current
+// rules, since they may have changed while the request was pending
+func (s *AdminService) applyPricingChange(ctx context.Context, tx *sqlx.Tx, payload interface{}) error {
+	input, err := pricingChangeFromPayload(payload)
 	if err != nil {
 		return err
 	}
-	if req == nil {
-		return domain.ErrNotFound("Approval")
+	change, err := s.pricing.ResolveChange(ctx, input)
+	if err != nil {
+		return err
 	}
-	if req.Status != domain.ApprovalStatusPending {
-		return domain.ErrValidationFailed("Request approval tidak lagi pending")
+	_, err = s.pricing.Apply(ctx, tx, change)
+	return err
+}
+
+func (s *AdminService) RejectApproval(ctx context.Context, actorID, approvalID, reason string) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	req, err := s.lockPendingApproval(ctx, tx, approvalID)
+	if err != nil {
+		return err
 	}
 	if req.RequesterID == actorID {
 		return domain.NewError("APPROVAL_SELF_FORBIDDEN", "Maker tidak boleh reject request sendiri", 403)
 	}
-	if err := s.repo.UpdateApprovalDecision(ctx, approvalID, actorID, domain.ApprovalStatusRejected, &reason); err != nil {
+	if err := s.checkApprover(ctx, actorID, req); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateApprovalDecisionWithTx(ctx, tx, approvalID, &actorID, domain.ApprovalStatusRejected, &reason); err != nil {
+		return err
+	}
+	if err := s.repo.AddApprovalEventWithTx(ctx, tx, approvalID, &actorID, "rejected", reason); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
 		return err
 	}
-	_ = s.repo.AddApprovalEvent(ctx, approvalID, actorID, "rejected", reason)
 	_ = s.logAudit(ctx, actorID, "approval.reject", "approval_request", approvalID, nil, map[string]interface{}{
 		"reason": reason,
 	}, "", "", "success", nil)
 	return nil
 }
 
+// CancelApproval lets the requester withdraw a request that is still pending
+func (s *AdminService) CancelApproval(ctx context.Context, actorID, approvalID, reason string) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	req, err := s.lockPendingApproval(ctx, tx, approvalID)
+	if err != nil {
+		return err
+	}
+	if req.RequesterID != actorID {
+		return domain.NewError("APPROVAL_CANCEL_FORBIDDEN", "Hanya maker yang boleh membatalkan request", 403)
+	}
+	var cancelReason *string
+	if reason = strings.TrimSpace(reason); reason != "" {
+		cancelReason = &reason
+	}
+	if err := s.repo.UpdateApprovalDecisionWithTx(ctx, tx, approvalID, nil, domain.ApprovalStatusCancelled, cancelReason); err != nil {
+		return err
+	}
+	if err := s.repo.AddApprovalEventWithTx(ctx, tx, approvalID, &actorID, "cancelled", reason); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	_ = s.logAudit(ctx, actorID, "approval.cancel", "approval_request", approvalID, nil, map[string]interface{}{
+		"reason": reason,
+	}, "", "", "success", nil)
+	return nil
+}
+
+// ExpireApprovals closes pending requests past their expiry and returns how
+// many were expired
+func (s *AdminService) ExpireApprovals(ctx context.Context) (int, error) {
+	ids, err := s.repo.ExpireApprovalRequests(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		_ = s.logAudit(ctx, "", "approval.expire", "approval_request", id, nil, nil, "", "", "success", nil)
+	}
+	return len(ids), nil
+}
+
+// lockPendingApproval locks a request for a decision. A request found past its
+// expiry is marked expired and committed, and an error is returned.
+func (s *AdminService) lockPendingApproval(ctx context.Context, tx *sqlx.Tx, approvalID string) (*domain.AdminApprovalRequest, error) {
+	req, err := s.repo.FindApprovalRequestForUpdate(ctx, tx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, domain.ErrNotFound("Approval")
+	}
+	if req.Status != domain.ApprovalStatusPending {
+		return nil, domain.ErrValidationFailed("Request approval tidak lagi pending")
+	}
+	if req.Expired(time.Now()) {
+		if err := s.repo.UpdateApprovalDecisionWithTx(ctx, tx, approvalID, nil, domain.ApprovalStatusExpired, nil); err != nil {
+			return nil, err
+		}
+		if err := s.repo.AddApprovalEventWithTx(ctx, tx, approvalID, nil, "expired", ""); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, domain.NewError("APPROVAL_EXPIRED", "Request approval sudah kedaluwarsa", 409)
+	}
+	return req, nil
+}
+
+// checkApprover enforces the approver role the request's policy requires
+func (s *AdminService) checkApprover(ctx context.Context, actorID string, req *domain.AdminApprovalRequest) error {
+	if !req.ApproverRoleID.Valid {
+		return nil
+	}
+	allowed, err := s.repo.AdminHasRole(ctx, actorID, req.ApproverRoleID.String)
+	if err != nil {
+		return fmt.Errorf("failed to check approver role: %w", err)
+	}
+	if !allowed {
+		return domain.NewError("APPROVAL_ROLE_REQUIRED", "Request ini hanya bisa diputuskan oleh role "+req.ApproverRoleID.String, 403)
+	}
+	return nil
+}
+
+// ApprovalPolicyInput is an admin-submitted approval policy
+type ApprovalPolicyInput struct {
+	ID                string `json:"id"`
+	RequestType       string `json:"requestType"`
+	MinAmount         int64  `json:"minAmount"`
+	RequiredApprovals int    `json:"requiredApprovals"`
+	ApproverRoleID    string `json:"approverRoleId"`
+	ExpiryHours       int    `json:"expiryHours"`
+	IsActive          bool   `json:"isActive"`
+}
+
+func (s *AdminService) ListApprovalPolicies(ctx context.Context) ([]*domain.AdminApprovalPolicy, error) {
+	return s.repo.ListApprovalPolicies(ctx)
+}
+
+// UpsertApprovalPolicy creates or replaces a policy. Requests already in the
+// queue keep the policy they were created with.
+func (s *AdminService) UpsertApprovalPolicy(ctx context.Context, actorID string, input ApprovalPolicyInput) (*domain.AdminApprovalPolicy, error) {
+	switch input.RequestType {
+	case "price_change", "balance_adjustment":
+	default:
+		return nil, domain.ErrValidationFailed("requestType harus price_change atau balance_adjustment")
+	}
+	if input.MinAmount < 0 {
+		return nil, domain.ErrValidationFailed("minAmount tidak boleh negatif")
+	}
+	if input.RequiredApprovals < 1 {
+		return nil, domain.ErrValidationFailed("requiredApprovals minimal 1")
+	}
+	if input.ExpiryHours < 1 {
+		return nil, domain.ErrValidationFailed("expiryHours minimal 1")
+	}
+
+	policy := &domain.AdminApprovalPolicy{
+		ID:                firstNonEmpty(strings.TrimSpace(input.ID), "apl_"+uuid.New().String()[:8]),
+		RequestType:       input.RequestType,
+		MinAmount:         input.MinAmount,
+		RequiredApprovals: input.RequiredApprovals,
+		ApproverRoleID:    sqlNullString(input.ApproverRoleID),
+		ExpiryHours:       input.ExpiryHours,
+		IsActive:          input.IsActive,
+	}
+	if err := s.repo.UpsertApprovalPolicy(ctx, policy); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "23505":
+				return nil, domain.ErrValidationFailed("Policy dengan requestType dan minAmount yang sama sudah ada")
+			case "23503":
+				return nil, domain.ErrValidationFailed("approverRoleId tidak ditemukan")
+			}
+		}
+		return nil, err
+	}
+	_ = s.logAudit(ctx, actorID, "approval.policy.upsert", "approval_policy", policy.ID, nil, input, "", "", "success", nil)
+	return policy, nil
+}
+
 func (s *AdminService) ListAuditLogs(ctx context.Context, page, perPage int) (*domain.AdminListResponse, error) {
 	items, total, err := s.repo.ListAuditLogs(ctx, page, perPage)
 	if err != nil {
@@ -556,18 +1102,47 @@ func (s *AdminService) UpsertSetting(ctx context.Context, actorID, key, descript
 	if actorID != "" {
 		updatedBy = &actorID
 	}
+	if key == domain.AccountLimitsSettingKey {
+		if err := validateAccountLimits(value); err != nil {
+			return err
+		}
+	}
 	if err := s.repo.UpsertSetting(ctx, key, value, description, updatedBy); err != nil {
 		return err
 	}
+	if key == domain.AccountLimitsSettingKey {
+		s.limits.Invalidate()
+	}
 	_ = s.logAudit(ctx, actorID, "setting.upsert", "admin_setting", key, nil, value, "", "", "success", nil)
 	return nil
 }
 
+// validateAccountLimits rejects an account_limits value the limit service
+// could not enforce
+func validateAccountLimits(value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return domain.ErrValidationFailed("Format limit akun tidak valid")
+	}
+	var config domain.AccountLimitConfig
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return domain.ErrValidationFailed("Format limit akun tidak valid")
+	}
+	if len(config.Rules) == 0 {
+		return domain.ErrValidationFailed("Limit akun minimal memiliki satu aturan")
+	}
+	return config.Validate()
+}
+
 func (s *AdminService) ListReferenceData(ctx context.Context) (map[string]interface{}, error) {
 	return s.repo.ListReferenceData(ctx)
 }
 
-func (s *AdminService) applyBalanceAdjustment(ctx context.Context, approvalID, actorID string, payload map[string]interface{}) error {
+// applyBalanceAdjustment credits or debits the user's balance within the
+// approval's transaction
+func (s *AdminService) applyBalanceAdjustment(ctx context.Context, tx *sqlx.Tx, approvalID string, payload map[string]interface{}) error {
 	userID := stringValue(payload["userId"])
 	amountDelta := int64Value(payload["amountDelta"])
 	description := firstNonEmpty(stringValue(payload["description"]), "Manual balance adjustment")
@@ -575,12 +1150,6 @@ func (s *AdminService) applyBalanceAdjustment(ctx context.Context, approvalID, a
 		return domain.ErrValidationFailed("Payload koreksi saldo tidak valid")
 	}
 
-	tx, err := s.repo.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
 	var balance struct {
 		ID     string `db:"id"`
 		UserID string `db:"user_id"`
@@ -611,12 +1180,6 @@ func (s *AdminService) applyBalanceAdjustment(ctx context.Context, approvalID, a
 	`, "bh_"+uuid.New().String()[:8], userID, entryType, absInt64(amountDelta), before, after, approvalID, description); err != nil {
 		return err
 	}
-
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-
-	_ = s.logAudit(ctx, actorID, "balance.adjustment.applied", "approval_request", approvalID, nil, payload, "", "", "success", nil)
 	return nil
 }
 
@@ -693,6 +1256,20 @@ func stringSliceValue(value interface{}) []string {
 	}
 }
 
+// pricingChangeFromPayload decodes a price_change approval payload. Requests
+// created before pricing rules only carry productId/newPrice/newAdminFee.
+func pricingChangeFromPayload(payload interface{}) (PricingChangeRequest, error) {
+	var input PricingChangeRequest
+	raw, err := json.Marshal(mapValue(payload))
+	if err != nil {
+		return input, err
+	}
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return input, domain.ErrValidationFailed("Payload request pricing tidak valid")
+	}
+	return input, nil
+}
+
 func mapValue(value interface{}) map[string]interface{} {
 	switch v := value.(type) {
 	case map[string]interface{}:
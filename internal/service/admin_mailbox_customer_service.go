@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+// ThreadCustomerRequest links a thread to a customer; an empty UserID
+// unlinks it
+type ThreadCustomerRequest struct {
+	UserID string `json:"userId"`
+}
+
+// ThreadLinkRequest attaches a transaction, deposit or KYC record to a thread
+type ThreadLinkRequest struct {
+	ResourceType string `json:"resourceType"`
+	ResourceID   string `json:"resourceId"`
+}
+
+// autoLinkThreadCustomer links an inbound thread to the customer who sent it,
+// by sender address first and then by a phone number in the signature. Phone
+// links are unverified: they help agents find the account but do not feed
+// its data into replies. Failures only cost the link, never the inbound email.
+func (s *AdminMailboxService) autoLinkThreadCustomer(ctx context.Context, thread *domain.AdminEmailThread, parsed *parsedInboundEmail) {
+	if thread.CustomerUserID.Valid {
+		return
+	}
+
+	source := domain.ThreadCustomerLinkEmail
+	userID, err := s.repo.FindCustomerIDByEmail(ctx, parsed.SenderAddress)
+	if err != nil {
+		log.Printf("[MAILBOX] failed to match customer by email for thread %s: %v", thread.ID, err)
+		return
+	}
+	if userID == "" {
+		source = domain.ThreadCustomerLinkPhone
+		for _, phone := range domain.SignaturePhoneNumbers(parsed.TextBody) {
+			if userID, err = s.repo.FindCustomerIDByPhone(ctx, phone); err != nil {
+				log.Printf("[MAILBOX] failed to match customer by phone for thread %s: %v", thread.ID, err)
+				return
+			}
+			if userID != "" {
+				break
+			}
+		}
+	}
+	if userID == "" {
+		return
+	}
+
+	if err := s.repo.LinkThreadCustomer(ctx, thread.ID, &userID, source); err != nil {
+		log.Printf("[MAILBOX] failed to link thread %s to customer %s: %v", thread.ID, userID, err)
+		return
+	}
+	thread.CustomerUserID.String, thread.CustomerUserID.Valid = userID, true
+	thread.CustomerLinkSource.String, thread.CustomerLinkSource.Valid = source, true
+	if err := s.repo.AddEmailThreadEvent(ctx, thread.ID, "", "customer_linked", "Thread terhubung otomatis ke customer", map[string]interface{}{
+		"userId": userID,
+		"source": source,
+	}); err != nil {
+		log.Printf("[MAILBOX] failed to record customer link for thread %s: %v", thread.ID, err)
+	}
+}
+
+func (s *AdminMailboxService) LinkThreadCustomer(ctx context.Context, adminID, threadID string, req ThreadCustomerRequest) error {
+	admin, thread, err := s.requireThreadReply(ctx, adminID, threadID)
+	if err != nil {
+		return err
+	}
+
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		if err := s.repo.LinkThreadCustomer(ctx, thread.ID, nil, ""); err != nil {
+			return fmt.Errorf("failed to unlink thread customer: %w", err)
+		}
+		return s.repo.AddEmailThreadEvent(ctx, thread.ID, admin.ID, "customer_unlinked", "Customer dilepas dari thread", map[string]interface{}{
+			"previousUserId": thread.CustomerUserID.String,
+		})
+	}
+
+	customer, err := s.repo.FindMailboxCustomerByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer == nil {
+		return domain.ErrNotFound("Pelanggan")
+	}
+	if err := s.repo.LinkThreadCustomer(ctx, thread.ID, &customer.UserID, domain.ThreadCustomerLinkManual); err != nil {
+		return fmt.Errorf("failed to link thread customer: %w", err)
+	}
+	return s.repo.AddEmailThreadEvent(ctx, thread.ID, admin.ID, "customer_linked", "Thread dihubungkan ke customer", map[string]interface{}{
+		"userId": customer.UserID,
+		"source": domain.ThreadCustomerLinkManual,
+	})
+}
+
+// AddThreadLink attaches a record to a thread. A thread without a customer
+// takes the record's owner as its customer.
+func (s *AdminMailboxService) AddThreadLink(ctx context.Context, adminID, threadID string, req ThreadLinkRequest) (*domain.AdminEmailThreadLink, error) {
+	admin, thread, err := s.requireThreadReply(ctx, adminID, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceType := strings.ToLower(strings.TrimSpace(req.ResourceType))
+	if !domain.IsThreadLinkType(resourceType) {
+		return nil, domain.ErrValidationFailed("Tipe data harus transaction, deposit, atau kyc")
+	}
+	resourceID := strings.TrimSpace(req.ResourceID)
+	if resourceID == "" {
+		return nil, domain.ErrValidationFailed("ID data wajib diisi")
+	}
+
+	resourceID, ownerID, err := s.repo.FindThreadLinkResource(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", resourceType, err)
+	}
+	if resourceID == "" {
+		return nil, domain.ErrNotFound(threadLinkLabel(resourceType))
+	}
+	if thread.CustomerUserID.Valid && thread.CustomerUserID.String != ownerID {
+		return nil, domain.NewError("THREAD_LINK_CUSTOMER_MISMATCH", threadLinkLabel(resourceType)+" milik customer lain", 400)
+	}
+	if !thread.CustomerUserID.Valid {
+		if err := s.repo.LinkThreadCustomer(ctx, thread.ID, &ownerID, domain.ThreadCustomerLinkManual); err != nil {
+			return nil, fmt.Errorf("failed to link thread customer: %w", err)
+		}
+	}
+
+	link := &domain.AdminEmailThreadLink{
+		ThreadID:     thread.ID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		UserID:       &ownerID,
+		CreatedBy:    &admin.ID,
+	}
+	if err := s.repo.CreateThreadLink(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to link %s: %w", resourceType, err)
+	}
+	if err := s.repo.AddEmailThreadEvent(ctx, thread.ID, admin.ID, "resource_linked", threadLinkLabel(resourceType)+" dihubungkan ke thread", map[string]interface{}{
+		"resourceType": resourceType,
+		"resourceId":   resourceID,
+	}); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *AdminMailboxService) RemoveThreadLink(ctx context.Context, adminID, threadID, linkID string) error {
+	admin, thread, err := s.requireThreadReply(ctx, adminID, threadID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteThreadLink(ctx, thread.ID, strings.TrimSpace(linkID))
+	if err != nil {
+		return fmt.Errorf("failed to unlink thread resource: %w", err)
+	}
+	if !deleted {
+		return domain.ErrNotFound("Tautan")
+	}
+	return s.repo.AddEmailThreadEvent(ctx, thread.ID, admin.ID, "resource_unlinked", "Tautan data dilepas dari thread", map[string]interface{}{
+		"linkId": linkID,
+	})
+}
+
+// threadCustomerPanel returns the customer side panel of a thread detail,
+// or nil when the thread has no customer or the admin may not see customers
+func (s *AdminMailboxService) threadCustomerPanel(ctx context.Context, admin *domain.AdminUser, customerUserID string) (map[string]interface{}, error) {
+	if customerUserID == "" || !hasPermission(admin, "customers.view") {
+		return nil, nil
+	}
+	return s.repo.GetThreadCustomerPanel(ctx, customerUserID)
+}
+
+func (s *AdminMailboxService) requireThreadReply(ctx context.Context, adminID, threadID string) (*domain.AdminUser, *domain.AdminEmailThread, error) {
+	admin, err := s.requireAdmin(ctx, adminID)
+	if err != nil {
+		return nil, nil, err
+	}
+	thread, err := s.repo.FindThreadByID(ctx, strings.TrimSpace(threadID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get thread: %w", err)
+	}
+	if thread == nil {
+		return nil, nil, domain.NewError("THREAD_NOT_FOUND", "Thread email tidak ditemukan", 404)
+	}
+	mailbox, err := s.repo.FindMailboxByID(ctx, thread.MailboxID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mailbox: %w", err)
+	}
+	if mailbox == nil || !s.canReplyFromMailbox(ctx, admin, mailbox) {
+		return nil, nil, domain.NewError("MAILBOX_FORBIDDEN", "Anda tidak memiliki akses ke thread ini", 403)
+	}
+	return admin, thread, nil
+}
+
+func threadLinkLabel(resourceType string) string {
+	switch resourceType {
+	case domain.ThreadLinkTransaction:
+		return "Transaksi"
+	case domain.ThreadLinkDeposit:
+		return "Deposit"
+	default:
+		return "Data KYC"
+	}
+}
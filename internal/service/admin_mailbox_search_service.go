@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+// SearchMailbox runs a full-text search over the messages of one mailbox, or
+// of every mailbox the admin can view when mailboxID is empty
+func (s *AdminMailboxService) SearchMailbox(ctx context.Context, adminID, rawQuery, mailboxID string, page, perPage int) (map[string]interface{}, error) {
+	admin, err := s.requireAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Message timestamps are stored in server local time
+	query, err := domain.ParseMailboxSearchQuery(rawQuery, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	mailboxIDs := make([]string, 0)
+	if mailboxID = strings.TrimSpace(mailboxID); mailboxID != "" {
+		if _, _, err := s.requireMailboxAccess(ctx, adminID, mailboxID); err != nil {
+			return nil, err
+		}
+		mailboxIDs = append(mailboxIDs, mailboxID)
+	} else {
+		items, err := s.repo.ListMailboxes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list mailboxes: %w", err)
+		}
+		for _, item := range items {
+			mailbox, err := s.repo.FindMailboxByID(ctx, stringValue(item["id"]))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get mailbox: %w", err)
+			}
+			if mailbox != nil && s.canViewMailbox(ctx, admin, mailbox) {
+				mailboxIDs = append(mailboxIDs, mailbox.ID)
+			}
+		}
+	}
+
+	items, total, err := s.repo.SearchMailboxMessages(ctx, query, mailboxIDs, page, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search mailbox: %w", err)
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"text":          query.Text,
+			"from":          query.From,
+			"hasAttachment": query.HasAttachment,
+			"after":         query.After,
+			"before":        query.Before,
+			"status":        query.Status,
+		},
+		"list": domain.AdminListResponse{
+			Items:   items,
+			Page:    page,
+			PerPage: perPage,
+			Total:   total,
+			HasNext: mailboxOffset(page, perPage)+len(items) < total,
+		},
+	}, nil
+}
@@ -389,16 +389,28 @@ func (s *AdminMailboxService) GetThreadDetail(ctx context.Context, adminID, thre
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mailbox members: %w", err)
 	}
+	links, err := s.repo.ListThreadLinks(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread links: %w", err)
+	}
+	customerUserID, _ := thread["customer_user_id"].(string)
+	customer, err := s.threadCustomerPanel(ctx, admin, customerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread customer: %w", err)
+	}
 
 	return map[string]interface{}{
-		"thread":       thread,
-		"mailbox":      mailbox,
-		"messages":     messages,
-		"attachments":  attachments,
-		"members":      members,
-		"canReply":     hasPermission(admin, "mailboxes.reply") && s.canReplyFromMailbox(ctx, admin, mailbox),
-		"canAssign":    hasPermission(admin, "mailboxes.assign"),
-		"canSetStatus": hasPermission(admin, "mailboxes.status.manage"),
+		"thread":          thread,
+		"mailbox":         mailbox,
+		"messages":        messages,
+		"attachments":     attachments,
+		"members":         members,
+		"links":           links,
+		"customer":        customer,
+		"canReply":        hasPermission(admin, "mailboxes.reply") && s.canReplyFromMailbox(ctx, admin, mailbox),
+		"canAssign":       hasPermission(admin, "mailboxes.assign"),
+		"canSetStatus":    hasPermission(admin, "mailboxes.status.manage"),
+		"canViewCustomer": hasPermission(admin, "customers.view"),
 	}, nil
 }
 
@@ -950,6 +962,7 @@ func (s *AdminMailboxService) processInboundS3Object(ctx context.Context, object
 	if err != nil {
 		return nil, err
 	}
+	s.autoLinkThreadCustomer(ctx, thread, parsed)
 
 	messageID := "aem_" + uuid.New().String()[:8]
 	if err := s.repo.CreateEmailMessage(ctx, map[string]interface{}{
@@ -983,15 +996,24 @@ func (s *AdminMailboxService) processInboundS3Object(ctx context.Context, object
 		if err := s.emailStorage.PutBytes(ctx, attachment.Data, storageKey, attachment.ContentType); err != nil {
 			return nil, fmt.Errorf("failed to store attachment: %w", err)
 		}
-		if err := s.repo.CreateEmailAttachment(ctx, map[string]interface{}{
-			"id":          "aea_" + uuid.New().String()[:8],
-			"messageId":   messageID,
-			"fileName":    attachment.FileName,
-			"contentType": attachment.ContentType,
-			"sizeBytes":   len(attachment.Data),
-			"storageKey":  storageKey,
-			"createdAt":   time.Now(),
-		}); err != nil {
+		record := map[string]interface{}{
+			"id":            "aea_" + uuid.New().String()[:8],
+			"messageId":     messageID,
+			"fileName":      attachment.FileName,
+			"contentType":   attachment.ContentType,
+			"sizeBytes":     len(attachment.Data),
+			"storageKey":    storageKey,
+			"extractedText": nullableString(extractAttachmentText(attachment.FileName, attachment.ContentType, attachment.Data)),
+			"createdAt":     time.Now(),
+		}
+		err := s.repo.CreateEmailAttachment(ctx, record)
+		if err != nil && record["extractedText"] != nil {
+			// The extracted text is only a search aid; keep the attachment without it
+			log.Printf("[MAILBOX] failed to save attachment %s with extracted text, retrying without: %v", attachment.FileName, err)
+			record["extractedText"] = nil
+			err = s.repo.CreateEmailAttachment(ctx, record)
+		}
+		if err != nil {
 			return nil, fmt.Errorf("failed to save attachment metadata: %w", err)
 		}
 	}
@@ -999,6 +1021,7 @@ func (s *AdminMailboxService) processInboundS3Object(ctx context.Context, object
 	if err := s.repo.UpdateThreadAfterInbound(ctx, thread.ID, parsed.SenderName, parsed.SenderAddress, parsed.Subject, parsed.Normalized, parsed.Preview, parsed.ReceivedAt); err != nil {
 		return nil, fmt.Errorf("failed to update inbox thread: %w", err)
 	}
+	s.startThreadSLA(ctx, thread, parsed.ReceivedAt)
 
 	eventType := "inbound_received"
 	if thread.Status == domain.AdminEmailThreadStatusSelesai {
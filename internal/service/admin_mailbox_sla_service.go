@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+// mailboxSLABatch bounds how many breaches one escalation run handles
+const mailboxSLABatch = 100
+
+// MailboxSLAPolicyRequest sets the SLA policy of a mailbox
+type MailboxSLAPolicyRequest struct {
+	FirstResponseMinutes int                  `json:"firstResponseMinutes"`
+	ResolutionMinutes    int                  `json:"resolutionMinutes"`
+	BusinessHours        domain.BusinessHours `json:"businessHours"`
+	Timezone             string               `json:"timezone"`
+	AutoAssignMode       string               `json:"autoAssignMode"`
+	EscalationAction     string               `json:"escalationAction"`
+	EscalationAdminID    string               `json:"escalationAdminId"`
+	IsActive             bool                 `json:"isActive"`
+}
+
+// GetMailboxSLAPolicy returns the policy of a mailbox, or the inactive
+// defaults when none was set
+func (s *AdminMailboxService) GetMailboxSLAPolicy(ctx context.Context, adminID, mailboxID string) (*domain.AdminMailboxSLAPolicy, error) {
+	if _, _, err := s.requireMailboxAccess(ctx, adminID, mailboxID); err != nil {
+		return nil, err
+	}
+	policy, err := s.repo.FindMailboxSLAPolicy(ctx, mailboxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox sla policy: %w", err)
+	}
+	if policy == nil {
+		policy = &domain.AdminMailboxSLAPolicy{
+			MailboxID:        mailboxID,
+			BusinessHours:    domain.BusinessHours{},
+			Timezone:         domain.DefaultMailboxSLATimezone,
+			AutoAssignMode:   domain.MailboxAutoAssignNone,
+			EscalationAction: domain.MailboxEscalationNotify,
+		}
+	}
+	return policy, nil
+}
+
+func (s *AdminMailboxService) UpdateMailboxSLAPolicy(ctx context.Context, adminID, mailboxID string, req MailboxSLAPolicyRequest) (*domain.AdminMailboxSLAPolicy, error) {
+	admin, mailbox, err := s.requireMailboxAccess(ctx, adminID, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(admin, "mailboxes.manage") {
+		return nil, domain.NewError("ADMIN_FORBIDDEN", "Anda tidak memiliki akses untuk mengatur SLA mailbox", 403)
+	}
+
+	if req.FirstResponseMinutes < 0 || req.ResolutionMinutes < 0 {
+		return nil, domain.ErrValidationFailed("Target SLA tidak boleh negatif")
+	}
+	if req.FirstResponseMinutes > 0 && req.ResolutionMinutes > 0 && req.ResolutionMinutes < req.FirstResponseMinutes {
+		return nil, domain.ErrValidationFailed("Target penyelesaian tidak boleh lebih cepat dari target balasan pertama")
+	}
+	if err := req.BusinessHours.Validate(); err != nil {
+		return nil, err
+	}
+	timezone := firstNonEmpty(strings.TrimSpace(req.Timezone), domain.DefaultMailboxSLATimezone)
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, domain.ErrValidationFailed("Zona waktu tidak valid")
+	}
+	autoAssign := firstNonEmpty(strings.TrimSpace(req.AutoAssignMode), domain.MailboxAutoAssignNone)
+	switch autoAssign {
+	case domain.MailboxAutoAssignNone, domain.MailboxAutoAssignRoundRobin, domain.MailboxAutoAssignLoadBased:
+	default:
+		return nil, domain.ErrValidationFailed("Mode auto-assign harus none, round_robin, atau load_based")
+	}
+	escalation := firstNonEmpty(strings.TrimSpace(req.EscalationAction), domain.MailboxEscalationNotify)
+	if escalation != domain.MailboxEscalationNotify && escalation != domain.MailboxEscalationReassign {
+		return nil, domain.ErrValidationFailed("Aksi eskalasi harus notify atau reassign")
+	}
+
+	escalationAdminID := strings.TrimSpace(req.EscalationAdminID)
+	if escalationAdminID != "" {
+		escalationAdmin, err := s.repo.FindAdminByID(ctx, escalationAdminID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get escalation admin: %w", err)
+		}
+		if escalationAdmin == nil {
+			return nil, domain.NewError("ADMIN_NOT_FOUND", "Admin eskalasi tidak ditemukan", 404)
+		}
+	}
+
+	policy := &domain.AdminMailboxSLAPolicy{
+		MailboxID:            mailbox.ID,
+		FirstResponseMinutes: req.FirstResponseMinutes,
+		ResolutionMinutes:    req.ResolutionMinutes,
+		BusinessHours:        req.BusinessHours,
+		Timezone:             timezone,
+		AutoAssignMode:       autoAssign,
+		EscalationAction:     escalation,
+		EscalationAdminID:    sql.NullString{String: escalationAdminID, Valid: escalationAdminID != ""},
+		IsActive:             req.IsActive,
+		UpdatedBy:            sql.NullString{String: admin.ID, Valid: true},
+	}
+	if policy.BusinessHours == nil {
+		policy.BusinessHours = domain.BusinessHours{}
+	}
+	if err := s.repo.UpsertMailboxSLAPolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save mailbox sla policy: %w", err)
+	}
+	return policy, nil
+}
+
+// startThreadSLA starts the SLA timers of an inbound thread that is new or
+// was closed, and auto-assigns it when the mailbox asks for it. Like the
+// customer link it never fails the inbound email.
+func (s *AdminMailboxService) startThreadSLA(ctx context.Context, thread *domain.AdminEmailThread, receivedAt time.Time) {
+	closed := thread.Status == domain.AdminEmailThreadStatusSelesai || thread.Status == domain.AdminEmailThreadStatusArsip
+	if thread.SLAStartedAt.Valid && !thread.ResolvedAt.Valid && !closed {
+		return
+	}
+
+	policy, err := s.repo.FindMailboxSLAPolicy(ctx, thread.MailboxID)
+	if err != nil {
+		log.Printf("[MAILBOX] failed to get sla policy for thread %s: %v", thread.ID, err)
+		return
+	}
+	if policy == nil || !policy.IsActive {
+		return
+	}
+
+	firstResponseDue, resolutionDue := policy.DueTimes(receivedAt)
+	if err := s.repo.StartThreadSLA(ctx, thread.ID, receivedAt, firstResponseDue, resolutionDue); err != nil {
+		log.Printf("[MAILBOX] failed to start sla for thread %s: %v", thread.ID, err)
+		return
+	}
+
+	if policy.AutoAssignMode == domain.MailboxAutoAssignNone || thread.AssignedAdminID.Valid {
+		return
+	}
+	assignee, err := s.autoAssignThread(ctx, thread.ID, thread.MailboxID, policy.AutoAssignMode, "")
+	if err != nil {
+		log.Printf("[MAILBOX] failed to auto-assign thread %s: %v", thread.ID, err)
+		return
+	}
+	if assignee != nil {
+		thread.AssignedAdminID = sql.NullString{String: assignee.AdminID, Valid: true}
+	}
+}
+
+// autoAssignThread gives a thread to the next member under mode, skipping
+// exclude. Nil means no member can take it.
+func (s *AdminMailboxService) autoAssignThread(ctx context.Context, threadID, mailboxID, mode, exclude string) (*domain.MailboxAssignee, error) {
+	candidates, err := s.repo.ListMailboxAssignees(ctx, mailboxID)
+	if err != nil {
+		return nil, err
+	}
+	assignee := domain.PickMailboxAssignee(mode, candidates, exclude)
+	if assignee == nil {
+		return nil, nil
+	}
+	if err := s.repo.AssignThreadToMember(ctx, threadID, mailboxID, assignee.AdminID); err != nil {
+		return nil, err
+	}
+	return assignee, s.repo.AddEmailThreadEvent(ctx, threadID, "", "auto_assigned", "Thread di-assign otomatis", map[string]interface{}{
+		"assignedAdminId": assignee.AdminID,
+		"mode":            mode,
+	})
+}
+
+// EscalateSLABreaches records threads that just missed an SLA target and
+// escalates them per their mailbox policy. It returns how many were handled.
+func (s *AdminMailboxService) EscalateSLABreaches(ctx context.Context) (int, error) {
+	breaches, err := s.repo.ListSLABreaches(ctx, time.Now(), mailboxSLABatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sla breaches: %w", err)
+	}
+
+	handled := 0
+	for _, breach := range breaches {
+		marked, err := s.repo.MarkThreadSLABreached(ctx, breach.ThreadID, breach.Target)
+		if err != nil {
+			log.Printf("[MAILBOX-SLA] failed to mark thread %s breached: %v", breach.ThreadID, err)
+			continue
+		}
+		if !marked {
+			continue
+		}
+		s.escalateBreach(ctx, breach)
+		handled++
+	}
+	return handled, nil
+}
+
+func (s *AdminMailboxService) escalateBreach(ctx context.Context, breach domain.MailboxSLABreach) {
+	previousAssignee := breach.AssignedAdminID.String
+	alert := MailboxSLAAlert{
+		ThreadID:         breach.ThreadID,
+		Subject:          breach.Subject,
+		MailboxAddress:   breach.MailboxAddress,
+		ParticipantEmail: breach.ParticipantEmail,
+		Target:           breach.Target,
+		DueAt:            breach.DueAt,
+	}
+	if policy, err := s.repo.FindMailboxSLAPolicy(ctx, breach.MailboxID); err == nil && policy != nil {
+		alert.DueAt = breach.DueAt.In(policy.Location())
+	}
+
+	recipients := []string{previousAssignee, breach.EscalationAdminID.String}
+	if breach.EscalationAction == domain.MailboxEscalationReassign {
+		mode := breach.AutoAssignMode
+		if mode == domain.MailboxAutoAssignNone {
+			mode = domain.MailboxAutoAssignLoadBased
+		}
+		assignee, err := s.autoAssignThread(ctx, breach.ThreadID, breach.MailboxID, mode, previousAssignee)
+		if err != nil {
+			log.Printf("[MAILBOX-SLA] failed to reassign thread %s: %v", breach.ThreadID, err)
+		}
+		if assignee != nil {
+			alert.ReassignedTo = firstNonEmpty(assignee.FullName, assignee.Email)
+			recipients = append(recipients, assignee.AdminID)
+		}
+	}
+
+	if err := s.repo.AddEmailThreadEvent(ctx, breach.ThreadID, "", "sla_breached", "Target SLA terlewat", map[string]interface{}{
+		"target":           breach.Target,
+		"dueAt":            breach.DueAt,
+		"escalationAction": breach.EscalationAction,
+		"previousAdminId":  previousAssignee,
+		"reassignedTo":     alert.ReassignedTo,
+	}); err != nil {
+		log.Printf("[MAILBOX-SLA] failed to record breach of thread %s: %v", breach.ThreadID, err)
+	}
+
+	notified := make(map[string]bool)
+	for _, adminID := range recipients {
+		if adminID == "" || notified[adminID] {
+			continue
+		}
+		notified[adminID] = true
+		recipient, err := s.repo.FindAdminByID(ctx, adminID)
+		if err != nil || recipient == nil {
+			continue
+		}
+		if err := s.emailService.SendMailboxSLAAlert(ctx, recipient.Email, recipient.DisplayName(), alert); err != nil {
+			log.Printf("[MAILBOX-SLA] failed to notify %s about thread %s: %v", recipient.Email, breach.ThreadID, err)
+		}
+	}
+}
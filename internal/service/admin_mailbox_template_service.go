@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+var mailboxShortcutPattern = regexp.MustCompile(`^[a-z0-9_-]{1,40}$`)
+
+// MailboxTemplateRequest creates or edits a canned response. An empty
+// MailboxID makes the template available in every mailbox.
+type MailboxTemplateRequest struct {
+	MailboxID string `json:"mailboxId"`
+	Name      string `json:"name"`
+	Shortcut  string `json:"shortcut"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+}
+
+// MailboxTemplateRenderRequest tells which thread, or for a new email which
+// recipient, a template is inserted for
+type MailboxTemplateRenderRequest struct {
+	ThreadID       string `json:"threadId"`
+	RecipientEmail string `json:"recipientEmail"`
+}
+
+func (s *AdminMailboxService) ListMailboxTemplates(ctx context.Context, adminID, mailboxID, search string) (map[string]interface{}, error) {
+	mailboxID = strings.TrimSpace(mailboxID)
+	if mailboxID != "" {
+		if _, _, err := s.requireMailboxAccess(ctx, adminID, mailboxID); err != nil {
+			return nil, err
+		}
+	} else {
+		admin, err := s.requireAdmin(ctx, adminID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasPermission(admin, "mailboxes.templates.manage") {
+			return nil, domain.ErrValidationFailed("Mailbox wajib dipilih")
+		}
+	}
+
+	templates, err := s.repo.ListMailboxTemplates(ctx, mailboxID, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mailbox templates: %w", err)
+	}
+	return map[string]interface{}{
+		"items":     templates,
+		"variables": domain.MailboxTemplateVariables,
+	}, nil
+}
+
+func (s *AdminMailboxService) CreateMailboxTemplate(ctx context.Context, adminID string, req MailboxTemplateRequest) (*domain.AdminMailboxTemplate, error) {
+	admin, err := s.requireTemplateManager(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	mailboxID := strings.TrimSpace(req.MailboxID)
+	if err := s.requireTemplateScope(ctx, admin, mailboxID); err != nil {
+		return nil, err
+	}
+
+	template := &domain.AdminMailboxTemplate{
+		MailboxID: sql.NullString{String: mailboxID, Valid: mailboxID != ""},
+		CreatedBy: sql.NullString{String: admin.ID, Valid: true},
+	}
+	if err := s.applyMailboxTemplate(ctx, template, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateMailboxTemplate(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create mailbox template: %w", err)
+	}
+	template.UpdatedBy = template.CreatedBy
+	return template, nil
+}
+
+// UpdateMailboxTemplate edits a template; its mailbox scope cannot change
+func (s *AdminMailboxService) UpdateMailboxTemplate(ctx context.Context, adminID, templateID string, req MailboxTemplateRequest) (*domain.AdminMailboxTemplate, error) {
+	admin, template, err := s.requireManagedTemplate(ctx, adminID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyMailboxTemplate(ctx, template, req); err != nil {
+		return nil, err
+	}
+	template.UpdatedBy = sql.NullString{String: admin.ID, Valid: true}
+	if err := s.repo.UpdateMailboxTemplate(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to update mailbox template: %w", err)
+	}
+	return template, nil
+}
+
+func (s *AdminMailboxService) DeleteMailboxTemplate(ctx context.Context, adminID, templateID string) error {
+	admin, template, err := s.requireManagedTemplate(ctx, adminID, templateID)
+	if err != nil {
+		return err
+	}
+	template.IsActive = false
+	template.UpdatedBy = sql.NullString{String: admin.ID, Valid: true}
+	if err := s.repo.UpdateMailboxTemplate(ctx, template); err != nil {
+		return fmt.Errorf("failed to delete mailbox template: %w", err)
+	}
+	return nil
+}
+
+// RenderMailboxTemplate fills a template for the customer of a thread (or of
+// a new email's recipient) and counts the insertion. Customer variables of a
+// thread linked only by a signature phone number are left empty and
+// customerLinkUnverified is set until an agent confirms the customer.
+func (s *AdminMailboxService) RenderMailboxTemplate(ctx context.Context, adminID, templateID string, req MailboxTemplateRenderRequest) (map[string]interface{}, error) {
+	admin, err := s.requireAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(admin, "mailboxes.reply") {
+		return nil, domain.NewError("ADMIN_FORBIDDEN", "Anda tidak memiliki akses untuk membalas email", 403)
+	}
+
+	template, err := s.repo.FindMailboxTemplateByID(ctx, strings.TrimSpace(templateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox template: %w", err)
+	}
+	if template == nil || !template.IsActive {
+		return nil, domain.NewError("MAILBOX_TEMPLATE_NOT_FOUND", "Template balasan tidak ditemukan", 404)
+	}
+
+	threadID := strings.TrimSpace(req.ThreadID)
+	email := strings.TrimSpace(req.RecipientEmail)
+	customerUserID := ""
+	unverifiedLink := false
+	if threadID != "" {
+		thread, err := s.repo.FindThreadByID(ctx, threadID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get thread: %w", err)
+		}
+		if thread == nil {
+			return nil, domain.NewError("THREAD_NOT_FOUND", "Thread email tidak ditemukan", 404)
+		}
+		if template.MailboxID.Valid && template.MailboxID.String != thread.MailboxID {
+			return nil, domain.NewError("MAILBOX_TEMPLATE_SCOPE", "Template tidak tersedia untuk mailbox ini", 400)
+		}
+		if err := s.requireTemplateReply(ctx, admin, thread.MailboxID); err != nil {
+			return nil, err
+		}
+		email = thread.ParticipantEmail
+		// Account data only goes to senders proven to be the customer; a
+		// phone-matched link waits for an agent to confirm it
+		if domain.ThreadCustomerLinkVerified(thread.CustomerLinkSource.String) {
+			customerUserID = thread.CustomerUserID.String
+		} else if thread.CustomerUserID.Valid {
+			unverifiedLink = true
+		}
+	} else if template.MailboxID.Valid {
+		if err := s.requireTemplateReply(ctx, admin, template.MailboxID.String); err != nil {
+			return nil, err
+		}
+	}
+
+	var customer *domain.MailboxCustomer
+	switch {
+	case customerUserID != "":
+		customer, err = s.repo.FindMailboxCustomerByID(ctx, customerUserID)
+	case email != "":
+		customer, err = s.repo.FindMailboxCustomerByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	values := customer.TemplateValues()
+	values[domain.MailboxVarAgentName] = admin.DisplayName()
+
+	subject, missingSubject := domain.RenderMailboxTemplate(template.Subject.String, values)
+	body, missing := domain.RenderMailboxTemplate(template.Body, values)
+	for _, key := range missingSubject {
+		if !slices.Contains(missing, key) {
+			missing = append(missing, key)
+		}
+	}
+
+	if err := s.repo.RecordMailboxTemplateUsage(ctx, template.ID, threadID, admin.ID); err != nil {
+		// The agent still gets the text; only the counter is off
+		log.Printf("[MAILBOX] failed to record template usage %s: %v", template.ID, err)
+	}
+
+	if missing == nil {
+		missing = []string{}
+	}
+	return map[string]interface{}{
+		"templateId":             template.ID,
+		"subject":                subject,
+		"body":                   body,
+		"missingVariables":       missing,
+		"customer":               customer,
+		"customerLinkUnverified": unverifiedLink,
+	}, nil
+}
+
+func (s *AdminMailboxService) requireTemplateManager(ctx context.Context, adminID string) (*domain.AdminUser, error) {
+	admin, err := s.requireAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(admin, "mailboxes.templates.manage") {
+		return nil, domain.NewError("ADMIN_FORBIDDEN", "Anda tidak memiliki akses untuk mengelola template balasan", 403)
+	}
+	return admin, nil
+}
+
+func (s *AdminMailboxService) requireManagedTemplate(ctx context.Context, adminID, templateID string) (*domain.AdminUser, *domain.AdminMailboxTemplate, error) {
+	admin, err := s.requireTemplateManager(ctx, adminID)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := s.repo.FindMailboxTemplateByID(ctx, strings.TrimSpace(templateID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mailbox template: %w", err)
+	}
+	if template == nil || !template.IsActive {
+		return nil, nil, domain.NewError("MAILBOX_TEMPLATE_NOT_FOUND", "Template balasan tidak ditemukan", 404)
+	}
+	if err := s.requireTemplateScope(ctx, admin, template.MailboxID.String); err != nil {
+		return nil, nil, err
+	}
+	return admin, template, nil
+}
+
+// requireTemplateScope checks a manager may edit templates of a mailbox;
+// global templates only need the permission
+func (s *AdminMailboxService) requireTemplateScope(ctx context.Context, admin *domain.AdminUser, mailboxID string) error {
+	if mailboxID == "" {
+		return nil
+	}
+	mailbox, err := s.repo.FindMailboxByID(ctx, mailboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get mailbox: %w", err)
+	}
+	if mailbox == nil {
+		return domain.NewError("MAILBOX_NOT_FOUND", "Mailbox tidak ditemukan", 404)
+	}
+	if !s.canViewMailbox(ctx, admin, mailbox) {
+		return domain.NewError("MAILBOX_FORBIDDEN", "Anda tidak memiliki akses ke mailbox ini", 403)
+	}
+	return nil
+}
+
+func (s *AdminMailboxService) requireTemplateReply(ctx context.Context, admin *domain.AdminUser, mailboxID string) error {
+	mailbox, err := s.repo.FindMailboxByID(ctx, mailboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get mailbox: %w", err)
+	}
+	if mailbox == nil || !s.canReplyFromMailbox(ctx, admin, mailbox) {
+		return domain.NewError("MAILBOX_FORBIDDEN", "Anda tidak memiliki akses untuk membalas dari mailbox ini", 403)
+	}
+	return nil
+}
+
+// applyMailboxTemplate validates the editable fields and copies them onto
+// the template
+func (s *AdminMailboxService) applyMailboxTemplate(ctx context.Context, template *domain.AdminMailboxTemplate, req MailboxTemplateRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return domain.ErrValidationFailed("Nama template wajib diisi")
+	}
+	if len(name) > 100 {
+		return domain.ErrValidationFailed("Nama template maksimal 100 karakter")
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return domain.ErrValidationFailed("Isi template wajib diisi")
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if len(subject) > 255 {
+		return domain.ErrValidationFailed("Subjek template maksimal 255 karakter")
+	}
+	if unknown := domain.UnknownMailboxTemplateVariables(subject + "\n" + body); len(unknown) > 0 {
+		return domain.ErrValidationFailed("Variabel tidak dikenal: " + strings.Join(unknown, ", "))
+	}
+
+	shortcut := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.Shortcut), "/"))
+	if shortcut != "" {
+		if !mailboxShortcutPattern.MatchString(shortcut) {
+			return domain.ErrValidationFailed("Shortcut hanya boleh huruf kecil, angka, - dan _ (maksimal 40 karakter)")
+		}
+		existing, err := s.repo.FindMailboxTemplateByShortcut(ctx, template.MailboxID.String, shortcut)
+		if err != nil {
+			return fmt.Errorf("failed to check template shortcut: %w", err)
+		}
+		if existing != nil && existing.ID != template.ID {
+			return domain.NewError("MAILBOX_TEMPLATE_SHORTCUT_TAKEN", "Shortcut sudah dipakai template lain", 409)
+		}
+	}
+
+	template.Name = name
+	template.Body = body
+	template.Subject = sql.NullString{String: subject, Valid: subject != ""}
+	template.Shortcut = sql.NullString{String: shortcut, Valid: shortcut != ""}
+	return nil
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// scheduledChangeBatch is the maximum number of applies or reverts per run
+const scheduledChangeBatch = 50
+
+// ScheduleChangeRequest queues an edit of a service, banner or voucher
+type ScheduleChangeRequest struct {
+	ResourceType string                 `json:"resourceType"`
+	ResourceID   string                 `json:"resourceId"`
+	Payload      map[string]interface{} `json:"payload"`
+	EffectiveAt  time.Time              `json:"effectiveAt"`
+	RevertAt     *time.Time             `json:"revertAt"`
+	Notes        string                 `json:"notes"`
+}
+
+// ScheduleChange queues a catalog edit. Payload only needs the fields that
+// change; the other fields keep the value they have when the change applies.
+func (s *AdminService) ScheduleChange(ctx context.Context, actorID string, req ScheduleChangeRequest) (*domain.ScheduledChange, error) {
+	if len(req.Payload) == 0 {
+		return nil, domain.ErrValidationFailed("Payload perubahan wajib diisi")
+	}
+	if req.EffectiveAt.IsZero() || req.EffectiveAt.Before(time.Now()) {
+		return nil, domain.ErrValidationFailed("effectiveAt harus di masa depan")
+	}
+	if req.RevertAt != nil && !req.RevertAt.After(req.EffectiveAt) {
+		return nil, domain.ErrValidationFailed("revertAt harus setelah effectiveAt")
+	}
+
+	snapshot, err := s.scheduledResourceSnapshot(ctx, req.ResourceType, req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for key := range req.Payload {
+		if _, ok := snapshot[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, domain.ErrValidationFailed("Field tidak dikenal: " + strings.Join(unknown, ", "))
+	}
+
+	change := &domain.ScheduledChange{
+		ID:           "sch_" + uuid.New().String()[:8],
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Payload:      req.Payload,
+		EffectiveAt:  req.EffectiveAt,
+		Status:       domain.ScheduledChangeScheduled,
+		Notes:        sqlNullString(req.Notes),
+		CreatedBy:    sqlNullString(actorID),
+	}
+	if req.RevertAt != nil {
+		change.RevertAt = sql.NullTime{Time: *req.RevertAt, Valid: true}
+	}
+	if err := s.repo.CreateScheduledChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to schedule change: %w", err)
+	}
+	_ = s.logAudit(ctx, actorID, "scheduled_change.create", req.ResourceType, req.ResourceID, nil, map[string]interface{}{
+		"scheduledChangeId": change.ID,
+		"payload":           req.Payload,
+		"effectiveAt":       req.EffectiveAt,
+		"revertAt":          req.RevertAt,
+	}, "", "", "success", nil)
+	return change, nil
+}
+
+func (s *AdminService) ListScheduledChanges(ctx context.Context, resourceType, status string, page, perPage int) (*domain.AdminListResponse, error) {
+	items, total, err := s.repo.ListScheduledChanges(ctx, resourceType, status, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	return paginated(items, total, page, perPage), nil
+}
+
+// CancelScheduledChange drops a change that has not taken effect yet
+func (s *AdminService) CancelScheduledChange(ctx context.Context, actorID, changeID string) error {
+	change, err := s.repo.FindScheduledChangeByID(ctx, changeID)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return domain.ErrNotFound("Perubahan terjadwal")
+	}
+	cancelled, err := s.repo.CancelScheduledChange(ctx, changeID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return domain.ErrValidationFailed("Perubahan terjadwal sudah diterapkan atau dibatalkan")
+	}
+	_ = s.logAudit(ctx, actorID, "scheduled_change.cancel", change.ResourceType, change.ResourceID, nil, map[string]interface{}{
+		"scheduledChangeId": changeID,
+	}, "", "", "success", nil)
+	return nil
+}
+
+// RunScheduledChanges applies the changes whose effective_at passed and
+// reverts the ones whose revert_at passed
+func (s *AdminService) RunScheduledChanges(ctx context.Context) (applied, reverted int, err error) {
+	now := time.Now()
+	due, err := s.repo.FindDueScheduledChanges(ctx, now, scheduledChangeBatch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find due scheduled changes: %w", err)
+	}
+	for _, change := range due {
+		ok, err := s.applyScheduledChange(ctx, change)
+		if err != nil {
+			s.failScheduledChange(ctx, change, "apply", err)
+			continue
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	reverts, err := s.repo.FindDueScheduledReverts(ctx, now, scheduledChangeBatch)
+	if err != nil {
+		return applied, 0, fmt.Errorf("failed to find due scheduled reverts: %w", err)
+	}
+	for _, change := range reverts {
+		ok, err := s.revertScheduledChange(ctx, change)
+		if err != nil {
+			s.failScheduledChange(ctx, change, "revert", err)
+			continue
+		}
+		if ok {
+			reverted++
+		}
+	}
+	return applied, reverted, nil
+}
+
+// applyScheduledChange writes the change and claims it in one transaction,
+// with the resource locked so the snapshot to revert to stays accurate
+func (s *AdminService) applyScheduledChange(ctx context.Context, change *domain.ScheduledChange) (bool, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot, err := s.lockScheduledResource(ctx, tx, change.ResourceType, change.ResourceID)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := s.repo.ClaimScheduledChangeApplyWithTx(ctx, tx, change.ID, snapshot)
+	if err != nil || !claimed {
+		return false, err
+	}
+	payload := domain.MergeChangePayload(snapshot, change.Payload)
+	if err := s.updateScheduledResource(ctx, tx, change.ResourceType, change.ResourceID, payload); err != nil {
+		return false, err
+	}
+
+	// Read the fields back so the revert compares against what the database
+	// holds rather than the submitted JSON
+	written, err := s.lockScheduledResource(ctx, tx, change.ResourceType, change.ResourceID)
+	if err != nil {
+		return false, err
+	}
+	applied := make(map[string]interface{}, len(change.Payload))
+	for key := range change.Payload {
+		applied[key] = written[key]
+	}
+	if err := s.repo.SetScheduledChangeAppliedPayloadWithTx(ctx, tx, change.ID, applied); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.invalidateScheduledResource(ctx, change.ResourceType)
+	_ = s.logAudit(ctx, change.CreatedBy.String, "scheduled_change.apply", change.ResourceType, change.ResourceID, snapshot, payload, "", "", "success", nil)
+	return true, nil
+}
+
+// revertScheduledChange restores the fields the change set, except those
+// edited since it applied, and claims the revert in the same transaction
+func (s *AdminService) revertScheduledChange(ctx context.Context, change *domain.ScheduledChange) (bool, error) {
+	if len(change.PreviousPayload) == 0 {
+		return false, fmt.Errorf("scheduled change %s has no snapshot to revert to", change.ID)
+	}
+	applied := change.AppliedPayload
+	if len(applied) == 0 {
+		// Applied before the written values were recorded
+		applied = change.Payload
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := s.lockScheduledResource(ctx, tx, change.ResourceType, change.ResourceID)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := s.repo.ClaimScheduledChangeRevertWithTx(ctx, tx, change.ID)
+	if err != nil || !claimed {
+		return false, err
+	}
+	payload, reverted, kept := domain.RevertChangePayload(current, applied, change.PreviousPayload)
+	if len(reverted) > 0 {
+		if err := s.updateScheduledResource(ctx, tx, change.ResourceType, change.ResourceID, payload); err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(kept) > 0 {
+		slog.Info("scheduled change revert kept edited fields",
+			slog.String("scheduled_change_id", change.ID),
+			slog.String("fields", strings.Join(kept, ",")),
+		)
+	}
+	if len(reverted) > 0 {
+		s.invalidateScheduledResource(ctx, change.ResourceType)
+	}
+	_ = s.logAudit(ctx, change.CreatedBy.String, "scheduled_change.revert", change.ResourceType, change.ResourceID, current, map[string]interface{}{
+		"payload":  payload,
+		"reverted": reverted,
+		"kept":     kept,
+	}, "", "", "success", nil)
+	return true, nil
+}
+
+func (s *AdminService) failScheduledChange(ctx context.Context, change *domain.ScheduledChange, step string, cause error) {
+	message := cause.Error()
+	slog.Error("scheduled change failed",
+		slog.String("scheduled_change_id", change.ID),
+		slog.String("step", step),
+		slog.String("error", message),
+	)
+	if err := s.repo.MarkScheduledChangeFailed(ctx, change.ID, message); err != nil {
+		slog.Error("failed to mark scheduled change failed", slog.String("scheduled_change_id", change.ID), slog.String("error", err.Error()))
+	}
+	_ = s.logAudit(ctx, change.CreatedBy.String, "scheduled_change."+step, change.ResourceType, change.ResourceID, nil, change.Payload, "", "", "failed", &message)
+}
+
+// scheduledResourceSnapshot loads the resource in the payload shape of its update
+func (s *AdminService) scheduledResourceSnapshot(ctx context.Context, resourceType, resourceID string) (map[string]interface{}, error) {
+	var snapshot map[string]interface{}
+	var label string
+	var err error
+	switch resourceType {
+	case domain.ScheduledResourceService:
+		snapshot, err = s.repo.GetServicePayload(ctx, resourceID)
+		label = "Layanan"
+	case domain.ScheduledResourceBanner:
+		snapshot, err = s.repo.GetBannerPayload(ctx, resourceID)
+		label = "Banner"
+	case domain.ScheduledResourceVoucher:
+		snapshot, err = s.repo.GetVoucherPayload(ctx, resourceID)
+		label = "Voucher"
+	default:
+		return nil, domain.ErrValidationFailed("resourceType harus service, banner, atau voucher")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", resourceType, err)
+	}
+	if snapshot == nil {
+		return nil, domain.ErrNotFound(label)
+	}
+	return snapshot, nil
+}
+
+func (s *AdminService) updateScheduledResource(ctx context.Context, tx *sqlx.Tx, resourceType, resourceID string, payload map[string]interface{}) error {
+	switch resourceType {
+	case domain.ScheduledResourceService:
+		return s.repo.UpdateServiceWithTx(ctx, tx, resourceID, payload)
+	case domain.ScheduledResourceBanner:
+		return s.repo.UpdateBannerWithTx(ctx, tx, resourceID, payload)
+	case domain.ScheduledResourceVoucher:
+		return s.repo.UpdateVoucherWithTx(ctx, tx, resourceID, payload)
+	default:
+		return fmt.Errorf("unknown scheduled resource type %s", resourceType)
+	}
+}
+
+// lockScheduledResource loads the resource like scheduledResourceSnapshot and
+// locks it until the transaction ends
+func (s *AdminService) lockScheduledResource(ctx context.Context, tx *sqlx.Tx, resourceType, resourceID string) (map[string]interface{}, error) {
+	var snapshot map[string]interface{}
+	var err error
+	switch resourceType {
+	case domain.ScheduledResourceService:
+		snapshot, err = s.repo.GetServicePayloadForUpdate(ctx, tx, resourceID)
+	case domain.ScheduledResourceBanner:
+		snapshot, err = s.repo.GetBannerPayloadForUpdate(ctx, tx, resourceID)
+	case domain.ScheduledResourceVoucher:
+		snapshot, err = s.repo.GetVoucherPayloadForUpdate(ctx, tx, resourceID)
+	default:
+		return nil, fmt.Errorf("unknown scheduled resource type %s", resourceType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", resourceType, err)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("%s %s no longer exists", resourceType, resourceID)
+	}
+	return snapshot, nil
+}
+
+// invalidateScheduledResource drops the home caches a changed resource feeds
+func (s *AdminService) invalidateScheduledResource(ctx context.Context, resourceType string) {
+	if s.redisClient == nil {
+		return
+	}
+	switch resourceType {
+	case domain.ScheduledResourceService:
+		if err := s.redisClient.Del(ctx, redis.HomeServicesKey()).Err(); err != nil {
+			slog.Warn("failed to invalidate home services cache", slog.String("error", err.Error()))
+		}
+	case domain.ScheduledResourceBanner:
+		keys, err := s.redisClient.Keys(ctx, redis.HomeBannersKey("*", "*")).Result()
+		if err != nil || len(keys) == 0 {
+			return
+		}
+		if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+			slog.Warn("failed to invalidate home banners cache", slog.String("error", err.Error()))
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/GTDGit/PPOB_BE/internal/repository"
 	"github.com/GTDGit/PPOB_BE/pkg/hash"
 	"github.com/GTDGit/PPOB_BE/pkg/jwt"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
 	"github.com/GTDGit/PPOB_BE/pkg/validator"
 	"github.com/google/uuid"
 	"github.com/pquerna/otp"
@@ -31,6 +32,11 @@ type AdminService struct {
 	publicS3     *internals3.Client
 	cfg          config.AdminConfig
 	jwtGen       *jwt.Generator
+	pricing      *PricingService
+	redisClient  *redis.Client
+	maintenance  *MaintenanceService
+	limits       *AccountLimitService
+	artefacts    *KYCArtefactService
 }
 
 type CreateAdminInviteRequest struct {
@@ -40,7 +46,7 @@ type CreateAdminInviteRequest struct {
 	RoleID   string
 }
 
-func NewAdminService(repo *repository.AdminRepository, emailService *EmailService, s3Client, publicS3 *internals3.Client, cfg config.AdminConfig) *AdminService {
+func NewAdminService(repo *repository.AdminRepository, emailService *EmailService, s3Client, publicS3 *internals3.Client, cfg config.AdminConfig, pricing *PricingService, redisClient *redis.Client, maintenance *MaintenanceService, limits *AccountLimitService, artefacts *KYCArtefactService) *AdminService {
 	return &AdminService{
 		repo:         repo,
 		emailService: emailService,
@@ -48,6 +54,11 @@ func NewAdminService(repo *repository.AdminRepository, emailService *EmailServic
 		publicS3:     publicS3,
 		cfg:          cfg,
 		jwtGen:       jwt.NewGenerator(cfg.JWTSecret, cfg.AccessTTL, cfg.RefreshTTL),
+		pricing:      pricing,
+		redisClient:  redisClient,
+		maintenance:  maintenance,
+		limits:       limits,
+		artefacts:    artefacts,
 	}
 }
 
@@ -772,6 +783,10 @@ func (s *AdminService) GetS3File(ctx context.Context, key string) ([]byte, strin
 	if s.s3Client == nil {
 		return nil, "", fmt.Errorf("s3 client not available")
 	}
+	// KYC and KYB files are only reachable through logged, expiring links
+	if strings.HasPrefix(key, "kyc/") || strings.HasPrefix(key, "kyb/") {
+		return nil, "", fmt.Errorf("private object %s", key)
+	}
 	return s.s3Client.GetObjectBytes(ctx, key)
 }
 
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// maxAttachmentTextBytes caps the text kept per attachment for search
+	maxAttachmentTextBytes = 100 * 1024
+	// maxPDFStreamBytes caps how much one decompressed PDF stream may grow
+	maxPDFStreamBytes = 4 * 1024 * 1024
+)
+
+var pdfStreamPattern = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+
+// extractAttachmentText returns the searchable text of a PDF or plain-text
+// attachment, or "" for anything else. PDF extraction is best effort: text
+// drawn with embedded CID fonts comes out unreadable and is dropped. NUL and
+// other control characters are stripped because Postgres rejects them in text
+// columns, and a malformed file yields "" rather than an error.
+func extractAttachmentText(fileName, contentType string, data []byte) (text string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[MAILBOX] failed to extract text from attachment %s: %v", fileName, r)
+			text = ""
+		}
+	}()
+
+	contentType = strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	ext := strings.ToLower(filepath.Ext(fileName))
+
+	switch {
+	case contentType == "application/pdf" || ext == ".pdf":
+		text = extractPDFText(data)
+	case strings.HasPrefix(contentType, "text/plain") || contentType == "text/csv" || ext == ".txt" || ext == ".csv":
+		text = decodePlainText(data)
+	default:
+		return ""
+	}
+
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxAttachmentTextBytes {
+		text = text[:maxAttachmentTextBytes]
+		for !utf8.ValidString(text) {
+			text = text[:len(text)-1]
+		}
+	}
+	return text
+}
+
+// decodePlainText decodes a text attachment as UTF-16 when it starts with a
+// UTF-16 byte order mark, otherwise as UTF-8 with invalid bytes replaced
+func decodePlainText(data []byte) string {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+	default:
+		return strings.ToValidUTF8(string(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))), " ")
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for n := 2; n+1 < len(data); n += 2 {
+		units = append(units, order.Uint16(data[n:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// extractPDFText pulls the strings shown by text operators out of the
+// content streams of a PDF
+func extractPDFText(data []byte) string {
+	var out strings.Builder
+	for _, loc := range pdfStreamPattern.FindAllSubmatchIndex(data, -1) {
+		dict := data[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := data[start : start+end]
+
+		var content []byte
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			reader, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			content, _ = io.ReadAll(io.LimitReader(reader, maxPDFStreamBytes))
+			reader.Close()
+		case bytes.Contains(dict, []byte("/Filter")):
+			// Images and other encodings carry no text we can read
+			continue
+		default:
+			content = raw
+		}
+
+		if text := pdfContentText(content); text != "" {
+			out.WriteString(text)
+			out.WriteByte('\n')
+		}
+		if out.Len() > maxAttachmentTextBytes {
+			break
+		}
+	}
+	return out.String()
+}
+
+// pdfContentText reads one content stream and returns the text of its
+// Tj, TJ, ' and " operators, or "" when it does not look like readable text
+func pdfContentText(content []byte) string {
+	var out strings.Builder
+	var pending []string
+	inText, inArray := false, false
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			value, next := readPDFLiteral(content, i)
+			if inText {
+				pending = append(pending, value)
+			}
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			value, next := readPDFHex(content, i)
+			if inText {
+				pending = append(pending, value)
+			}
+			i = next
+		case c == '[' || c == ']':
+			inArray = c == '['
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case isPDFRegular(c):
+			start := i
+			for i < len(content) && isPDFRegular(content[i]) {
+				i++
+			}
+			switch string(content[start:i]) {
+			case "BT":
+				inText = true
+				pending = pending[:0]
+			case "ET":
+				inText = false
+				out.WriteByte('\n')
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(pending, ""))
+				out.WriteByte(' ')
+				pending = pending[:0]
+			case "'", "\"":
+				out.WriteByte('\n')
+				out.WriteString(strings.Join(pending, ""))
+				pending = pending[:0]
+			case "T*", "Td", "TD":
+				out.WriteByte('\n')
+			default:
+				// A wide negative kerning inside a TJ array is a word gap
+				if inText && inArray {
+					if shift, err := strconv.ParseFloat(string(content[start:i]), 64); err == nil && shift <= -200 {
+						pending = append(pending, " ")
+					}
+				}
+			}
+		default:
+			i++
+		}
+	}
+
+	text := out.String()
+	if !mostlyReadable(text) {
+		return ""
+	}
+	return text
+}
+
+func readPDFLiteral(content []byte, i int) (string, int) {
+	var value []byte
+	depth := 0
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					octal := 0
+					for n := 0; n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; n++ {
+						octal = octal*8 + int(content[i]-'0')
+						i++
+					}
+					value = append(value, byte(octal))
+					continue
+				}
+				value = append(value, e)
+			}
+		case c == '(':
+			if depth > 0 {
+				value = append(value, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return pdfBytesToString(value), i + 1
+			}
+			value = append(value, c)
+		default:
+			value = append(value, c)
+		}
+		i++
+	}
+	return pdfBytesToString(value), i
+}
+
+func readPDFHex(content []byte, i int) (string, int) {
+	end := bytes.IndexByte(content[i:], '>')
+	if end < 0 {
+		return "", len(content)
+	}
+	digits := make([]byte, 0, end)
+	for _, c := range content[i+1 : i+end] {
+		if unicode.Is(unicode.ASCII_Hex_Digit, rune(c)) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	value := make([]byte, len(digits)/2)
+	for n := range value {
+		value[n] = hexNibble(digits[2*n])<<4 | hexNibble(digits[2*n+1])
+	}
+	return pdfBytesToString(value), i + end + 1
+}
+
+// pdfBytesToString decodes a PDF string: UTF-16BE with a byte order mark,
+// otherwise treated as Latin-1
+func pdfBytesToString(value []byte) string {
+	if len(value) >= 2 && value[0] == 0xFE && value[1] == 0xFF {
+		runes := make([]rune, 0, len(value)/2)
+		for n := 2; n+1 < len(value); n += 2 {
+			runes = append(runes, rune(value[n])<<8|rune(value[n+1]))
+		}
+		return string(runes)
+	}
+	runes := make([]rune, len(value))
+	for n, b := range value {
+		runes[n] = rune(b)
+	}
+	return string(runes)
+}
+
+func hexNibble(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isPDFRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
+
+// mostlyReadable rejects text that is mostly glyph IDs rendered as
+// control or symbol characters
+func mostlyReadable(text string) bool {
+	total, readable := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsPunct(r) {
+			readable++
+		}
+	}
+	return total > 0 && readable*10 >= total*8
+}
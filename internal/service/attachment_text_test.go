@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func buildTestPDF(t *testing.T, content string, compress bool) []byte {
+	t.Helper()
+	stream := []byte(content)
+	dict := fmt.Sprintf("<< /Length %d >>", len(stream))
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write(stream)
+		w.Close()
+		stream = buf.Bytes()
+		dict = fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(stream))
+	}
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	pdf.WriteString("4 0 obj\n" + dict + "\nstream\n")
+	pdf.Write(stream)
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj\n<< /Length 3 /Filter /DCTDecode >>\nstream\n\xff\xd8\xff\nendstream\nendobj\n%%EOF")
+	return pdf.Bytes()
+}
+
+func TestExtractAttachmentTextPDF(t *testing.T) {
+	content := "BT /F1 12 Tf 72 712 Td (Bukti Transfer) Tj T* [(Virtual)-250(Account)] TJ ET\n" +
+		"BT <4E6F6D6F72> Tj (: 8808 \\(BCA\\)) Tj ET"
+
+	for _, compress := range []bool{false, true} {
+		text := extractAttachmentText("bukti.pdf", "application/pdf", buildTestPDF(t, content, compress))
+		for _, want := range []string{"Bukti Transfer", "Virtual Account", "Nomor", "8808 (BCA)"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("compress=%v: text %q does not contain %q", compress, text, want)
+			}
+		}
+	}
+}
+
+func TestExtractAttachmentTextOtherTypes(t *testing.T) {
+	if got := extractAttachmentText("catatan.txt", "text/plain; charset=utf-8", []byte("Saldo  belum\nmasuk")); got != "Saldo belum masuk" {
+		t.Errorf("text attachment = %q", got)
+	}
+	if got := extractAttachmentText("screenshot.png", "image/png", []byte{0x89, 'P', 'N', 'G'}); got != "" {
+		t.Errorf("image attachment = %q, want no text", got)
+	}
+}
+
+func TestExtractAttachmentTextSanitizes(t *testing.T) {
+	if got := extractAttachmentText("log.txt", "text/plain", []byte("Ref\x00 123\x07\x1b45")); got != "Ref 12345" {
+		t.Errorf("control characters kept: %q", got)
+	}
+
+	utf16le := []byte{0xFF, 0xFE}
+	for _, r := range "Saldo ✓" {
+		utf16le = append(utf16le, byte(r), byte(r>>8))
+	}
+	if got := extractAttachmentText("export.csv", "text/csv", utf16le); got != "Saldo ✓" {
+		t.Errorf("UTF-16LE attachment = %q", got)
+	}
+
+	utf16be := []byte{0xFE, 0xFF, 0, 'O', 0, 'K'}
+	if got := extractAttachmentText("ok.txt", "text/plain", utf16be); got != "OK" {
+		t.Errorf("UTF-16BE attachment = %q", got)
+	}
+}
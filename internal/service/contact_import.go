@@ -0,0 +1,590 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/GTDGit/PPOB_BE/pkg/xlsx"
+)
+
+const (
+	// ContactImportMaxFileSize is the largest contact file accepted for import
+	ContactImportMaxFileSize = 5 << 20
+	// contactImportMaxRows caps the number of data rows per import
+	contactImportMaxRows = 10000
+	// contactImportAsyncThreshold is the row count above which imports run in the background
+	contactImportAsyncThreshold = 200
+	// contactImportBatchSize is the number of contacts inserted per transaction
+	contactImportBatchSize = 500
+	// contactImportTimeout bounds a background import
+	contactImportTimeout = 10 * time.Minute
+	// contactImportStaleAfter is how long a job may stay processing before it
+	// is considered lost, e.g. to a restart, and marked failed
+	contactImportStaleAfter = contactImportTimeout + time.Minute
+)
+
+// contactImportLostMessage is shown for background imports that never finished
+const contactImportLostMessage = "Import kontak terhenti, silakan unggah ulang file"
+
+// contactFileColumns is the column layout shared by import and export
+var contactFileColumns = []string{
+	"name", "type", "value", "bank_code", "bank_name", "account_name", "customer_name", "region_id", "region_name",
+}
+
+// contactColumnAliases maps normalized header names to contactFileColumns
+var contactColumnAliases = map[string]string{
+	"name": "name", "nama": "name",
+	"type": "type", "tipe": "type", "jenis": "type",
+	"value": "value", "nomor": "value", "number": "value", "target": "value",
+	"bankcode": "bank_code", "kodebank": "bank_code",
+	"bankname": "bank_name", "namabank": "bank_name",
+	"accountname": "account_name", "namarekening": "account_name",
+	"customername": "customer_name", "namapelanggan": "customer_name",
+	"regionid": "region_id", "wilayah": "region_id",
+	"regionname": "region_name", "namawilayah": "region_name",
+}
+
+// ContactImportRequest represents a bulk contact import request
+type ContactImportRequest struct {
+	UserID   string
+	FileName string
+	Format   string // csv or xlsx; detected from the file name when empty
+	Data     []byte
+	DryRun   bool
+}
+
+// ContactExport represents an exported contact file
+type ContactExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// contactImportRow is a parsed data row with its 1-based position in the file
+type contactImportRow struct {
+	Row    int
+	Fields map[string]string
+}
+
+// Import validates and saves contacts from a CSV or XLSX file. Small files are
+// processed inline; larger ones are queued as a background job whose progress
+// is available through GetImportJob.
+func (s *ContactService) Import(ctx context.Context, req ContactImportRequest) (*domain.ContactImportResponse, error) {
+	format, err := detectContactFileFormat(req.Format, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Data) == 0 {
+		return nil, domain.ErrValidationFailed("File kontak kosong")
+	}
+	if len(req.Data) > ContactImportMaxFileSize {
+		return nil, domain.ErrValidationFailed("Ukuran file kontak maksimal 5MB")
+	}
+
+	rows, err := parseContactFile(format, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, domain.ErrValidationFailed("File kontak tidak memiliki baris data")
+	}
+	if len(rows) > contactImportMaxRows {
+		return nil, domain.ErrValidationFailed(fmt.Sprintf("Maksimal %d kontak per file", contactImportMaxRows))
+	}
+
+	if len(rows) <= contactImportAsyncThreshold {
+		results, err := s.processImport(ctx, req.UserID, rows, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		response := &domain.ContactImportResponse{
+			Status: domain.ContactImportCompleted,
+			DryRun: req.DryRun,
+			Format: format,
+			Rows:   results,
+		}
+		countImportResults(response, results)
+		return response, nil
+	}
+
+	now := time.Now()
+	job := &domain.ContactImportJob{
+		ID:        "cij_" + uuid.New().String()[:8],
+		UserID:    req.UserID,
+		Status:    domain.ContactImportProcessing,
+		Format:    format,
+		DryRun:    req.DryRun,
+		TotalRows: len(rows),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.FileName != "" {
+		fileName := truncateRunes(filepath.Base(req.FileName), 255)
+		job.FileName = &fileName
+	}
+	if err := s.contactRepo.CreateImportJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	go s.runImportJob(job, rows)
+
+	return toContactImportResponse(job), nil
+}
+
+// GetImportJob returns the status and results of a background contact import
+func (s *ContactService) GetImportJob(ctx context.Context, userID, jobID string) (*domain.ContactImportResponse, error) {
+	job, err := s.contactRepo.FindImportJobByUserAndID(ctx, userID, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	if job == nil {
+		return nil, domain.ErrNotFound("Import kontak")
+	}
+	if job.Status == domain.ContactImportProcessing && time.Since(job.CreatedAt) > contactImportStaleAfter {
+		s.FailStaleImportJobs(ctx)
+		message := contactImportLostMessage
+		job.Status = domain.ContactImportFailed
+		job.ErrorMessage = &message
+	}
+	return toContactImportResponse(job), nil
+}
+
+// FailStaleImportJobs marks background imports that outlived their timeout,
+// e.g. because the process restarted mid-import, as failed. The rows are not
+// kept, so such jobs cannot be resumed.
+func (s *ContactService) FailStaleImportJobs(ctx context.Context) int64 {
+	failed, err := s.contactRepo.FailStaleImportJobs(ctx, time.Now().Add(-contactImportStaleAfter), contactImportLostMessage)
+	if err != nil {
+		slog.Error("failed to fail stale contact import jobs", slog.String("error", err.Error()))
+		return 0
+	}
+	if failed > 0 {
+		slog.Warn("failed stale contact import jobs", slog.Int64("count", failed))
+	}
+	return failed
+}
+
+// Export renders the user's contacts in the import file layout
+func (s *ContactService) Export(ctx context.Context, userID, format, contactType string) (*ContactExport, error) {
+	format, err := detectContactFileFormat(format, "")
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := s.contactRepo.FindByUserID(ctx, userID, repository.ContactFilter{Type: contactType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+
+	rows := make([][]string, 0, len(contacts)+1)
+	rows = append(rows, contactFileColumns)
+	for _, c := range contacts {
+		metadata := map[string]interface{}{}
+		if c.Metadata != nil {
+			_ = json.Unmarshal([]byte(*c.Metadata), &metadata)
+		}
+		field := func(key string) string {
+			value, _ := metadata[key].(string)
+			return value
+		}
+		rows = append(rows, []string{
+			c.Name, c.Type, c.Value,
+			field("bankCode"), field("bankName"), field("accountName"),
+			field("customerName"), field("regionId"), field("regionName"),
+		})
+	}
+
+	var buf bytes.Buffer
+	export := &ContactExport{
+		FileName: fmt.Sprintf("kontak-%s.%s", time.Now().Format("20060102"), format),
+	}
+	switch format {
+	case domain.ContactFileXLSX:
+		if err := xlsx.Write(&buf, "Kontak", rows); err != nil {
+			return nil, fmt.Errorf("failed to write xlsx: %w", err)
+		}
+		export.ContentType = xlsx.ContentType
+	default:
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(rows); err != nil {
+			return nil, fmt.Errorf("failed to write csv: %w", err)
+		}
+		export.ContentType = "text/csv; charset=utf-8"
+	}
+	export.Data = buf.Bytes()
+
+	return export, nil
+}
+
+// runImportJob processes a queued import outside the request lifecycle
+func (s *ContactService) runImportJob(job *domain.ContactImportJob, rows []contactImportRow) {
+	ctx, cancel := context.WithTimeout(context.Background(), contactImportTimeout)
+	defer cancel()
+
+	results, err := s.processImport(ctx, job.UserID, rows, job.DryRun)
+
+	now := time.Now()
+	job.UpdatedAt = now
+	job.CompletedAt = &now
+	if err != nil {
+		slog.Error("contact import job failed",
+			slog.String("job_id", job.ID),
+			slog.String("user_id", job.UserID),
+			slog.String("error", err.Error()),
+		)
+		message := "Import kontak gagal diproses"
+		job.Status = domain.ContactImportFailed
+		job.ErrorMessage = &message
+	} else {
+		response := &domain.ContactImportResponse{}
+		countImportResults(response, results)
+		job.Status = domain.ContactImportCompleted
+		job.CreatedCount = response.CreatedCount
+		job.DuplicateCount = response.DuplicateCount
+		job.InvalidCount = response.InvalidCount
+		if data, err := json.Marshal(results); err == nil {
+			str := string(data)
+			job.Results = &str
+		}
+	}
+
+	// The import context may have run out; the final status must still be saved
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer saveCancel()
+	if err := s.contactRepo.UpdateImportJob(saveCtx, job); err != nil {
+		slog.Error("failed to update contact import job",
+			slog.String("job_id", job.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// processImport validates rows, dedups them against the file and the user's
+// existing contacts on (type, value), and saves them unless dryRun is set
+func (s *ContactService) processImport(ctx context.Context, userID string, rows []contactImportRow, dryRun bool) ([]*domain.ContactImportRowResult, error) {
+	existing, err := s.contactRepo.FindByUserID(ctx, userID, repository.ContactFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+	existingKeys := make(map[string]bool, len(existing))
+	for _, c := range existing {
+		existingKeys[c.Type+"|"+c.Value] = true
+	}
+
+	results := make([]*domain.ContactImportRowResult, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+	pending := make([]*domain.Contact, 0, len(rows))
+	pendingResults := make([]*domain.ContactImportRowResult, 0, len(rows))
+	now := time.Now()
+
+	for _, row := range rows {
+		name := strings.TrimSpace(row.Fields["name"])
+		contactType := strings.ToLower(strings.TrimSpace(row.Fields["type"]))
+		value := normalizeContactValue(contactType, row.Fields["value"])
+
+		result := &domain.ContactImportRowResult{
+			Row:   row.Row,
+			Name:  name,
+			Type:  contactType,
+			Value: value,
+		}
+		results = append(results, result)
+
+		if message := s.validateImportRow(name, contactType, value); message != "" {
+			result.Status = domain.ContactImportRowInvalid
+			result.Message = message
+			continue
+		}
+
+		key := contactType + "|" + value
+		if existingKeys[key] {
+			result.Status = domain.ContactImportRowDuplicate
+			result.Message = "Kontak sudah tersimpan"
+			continue
+		}
+		if firstRow, ok := seen[key]; ok {
+			result.Status = domain.ContactImportRowDuplicate
+			result.Message = fmt.Sprintf("Duplikat dengan baris %d", firstRow)
+			continue
+		}
+		seen[key] = row.Row
+
+		if dryRun {
+			result.Status = domain.ContactImportRowValid
+			continue
+		}
+
+		contact := &domain.Contact{
+			ID:        "cnt_" + uuid.New().String()[:8],
+			UserID:    userID,
+			Name:      name,
+			Type:      contactType,
+			Value:     value,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		var operator *domain.Operator
+		if contactType == domain.ContactTypePhone {
+			operator = s.detectOperator(ctx, value)
+		}
+		if metadata := importRowMetadata(contactType, row.Fields, operator); len(metadata) > 0 {
+			if data, err := json.Marshal(metadata); err == nil {
+				str := string(data)
+				contact.Metadata = &str
+			}
+		}
+		pending = append(pending, contact)
+		pendingResults = append(pendingResults, result)
+	}
+
+	for start := 0; start < len(pending); start += contactImportBatchSize {
+		end := start + contactImportBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		inserted, err := s.contactRepo.BulkCreate(ctx, pending[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to save contacts: %w", err)
+		}
+		for i, ok := range inserted {
+			result := pendingResults[start+i]
+			if ok {
+				result.Status = domain.ContactImportRowCreated
+			} else {
+				// Saved concurrently by another request since the duplicate check
+				result.Status = domain.ContactImportRowDuplicate
+				result.Message = "Kontak sudah tersimpan"
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// validateImportRow returns a user-facing message for an invalid row, or "" when valid
+func (s *ContactService) validateImportRow(name, contactType, value string) string {
+	if name == "" {
+		return "Nama wajib diisi"
+	}
+	if utf8.RuneCountInString(name) > 50 {
+		return "Nama maksimal 50 karakter"
+	}
+	if !s.isValidContactType(contactType) {
+		return "Tipe kontak tidak valid"
+	}
+	if err := s.validateContactValue(contactType, value); err != nil {
+		if appErr, ok := err.(*domain.AppError); ok {
+			return appErr.Message
+		}
+		return err.Error()
+	}
+	return ""
+}
+
+// importRowMetadata builds contact metadata from the optional file columns
+func importRowMetadata(contactType string, fields map[string]string, operator *domain.Operator) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	set := func(key, column string) {
+		if v := strings.TrimSpace(fields[column]); v != "" {
+			metadata[key] = v
+		}
+	}
+
+	switch contactType {
+	case domain.ContactTypePhone:
+		if operator != nil {
+			metadata["operator"] = operator.ID
+			metadata["operatorName"] = operator.Name
+		}
+	case domain.ContactTypeBank:
+		set("bankCode", "bank_code")
+		set("bankName", "bank_name")
+		set("accountName", "account_name")
+	case domain.ContactTypePDAM:
+		set("regionId", "region_id")
+		set("regionName", "region_name")
+		set("customerName", "customer_name")
+	default:
+		set("customerName", "customer_name")
+	}
+
+	return metadata
+}
+
+// normalizeContactValue strips spreadsheet formatting from a value and restores
+// the leading zero of phone numbers that spreadsheets tend to drop
+func normalizeContactValue(contactType, value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.NewReplacer(" ", "", "-", "", ".", "", "'", "").Replace(value)
+
+	if contactType == domain.ContactTypePhone {
+		switch {
+		case strings.HasPrefix(value, "+62"):
+			value = "0" + value[3:]
+		case strings.HasPrefix(value, "62"):
+			value = "0" + value[2:]
+		case strings.HasPrefix(value, "8"):
+			value = "0" + value
+		}
+	}
+	return value
+}
+
+func detectContactFileFormat(format, fileName string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" && fileName != "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	}
+	if format == "" {
+		format = domain.ContactFileCSV
+	}
+	if format != domain.ContactFileCSV && format != domain.ContactFileXLSX {
+		return "", domain.ErrValidationFailed("Format file harus csv atau xlsx")
+	}
+	return format, nil
+}
+
+// parseContactFile reads the header row and returns the data rows keyed by column
+func parseContactFile(format string, data []byte) ([]contactImportRow, error) {
+	var records [][]string
+	switch format {
+	case domain.ContactFileXLSX:
+		// One header row plus one row past the cap so oversized files are reported as such
+		rows, err := xlsx.Read(bytes.NewReader(data), int64(len(data)), contactImportMaxRows+2)
+		if errors.Is(err, xlsx.ErrTooLarge) {
+			return nil, domain.ErrValidationFailed(fmt.Sprintf("Maksimal %d kontak per file", contactImportMaxRows))
+		}
+		if err != nil {
+			return nil, domain.ErrValidationFailed("File xlsx tidak dapat dibaca")
+		}
+		records = rows
+	default:
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		r.TrimLeadingSpace = true
+		// Spreadsheets in the Indonesian locale export with semicolons
+		if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+			r.Comma = ';'
+		}
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, domain.ErrValidationFailed("File csv tidak dapat dibaca")
+			}
+			records = append(records, record)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, domain.ErrValidationFailed("File kontak kosong")
+	}
+
+	columns := make(map[int]string, len(records[0]))
+	for i, header := range records[0] {
+		key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(header)))
+		if column, ok := contactColumnAliases[key]; ok {
+			columns[i] = column
+		}
+	}
+	for _, required := range []string{"name", "type", "value"} {
+		found := false
+		for _, column := range columns {
+			if column == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, domain.ErrValidationFailed(fmt.Sprintf("Kolom %s wajib ada di baris header", required))
+		}
+	}
+
+	rows := make([]contactImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		fields := make(map[string]string, len(columns))
+		empty := true
+		for idx, value := range record {
+			column, ok := columns[idx]
+			if !ok {
+				continue
+			}
+			fields[column] = value
+			if strings.TrimSpace(value) != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, contactImportRow{Row: i + 2, Fields: fields})
+	}
+
+	return rows, nil
+}
+
+func countImportResults(response *domain.ContactImportResponse, results []*domain.ContactImportRowResult) {
+	response.TotalRows = len(results)
+	for _, r := range results {
+		switch r.Status {
+		case domain.ContactImportRowCreated, domain.ContactImportRowValid:
+			response.CreatedCount++
+		case domain.ContactImportRowDuplicate:
+			response.DuplicateCount++
+		case domain.ContactImportRowInvalid:
+			response.InvalidCount++
+		}
+	}
+}
+
+func toContactImportResponse(job *domain.ContactImportJob) *domain.ContactImportResponse {
+	jobID := job.ID
+	createdAt := job.CreatedAt.Format(time.RFC3339)
+	response := &domain.ContactImportResponse{
+		JobID:          &jobID,
+		Status:         job.Status,
+		DryRun:         job.DryRun,
+		Format:         job.Format,
+		TotalRows:      job.TotalRows,
+		CreatedCount:   job.CreatedCount,
+		DuplicateCount: job.DuplicateCount,
+		InvalidCount:   job.InvalidCount,
+		ErrorMessage:   job.ErrorMessage,
+		CreatedAt:      &createdAt,
+	}
+	if job.CompletedAt != nil {
+		completedAt := job.CompletedAt.Format(time.RFC3339)
+		response.CompletedAt = &completedAt
+	}
+	if job.Results != nil {
+		var rows []*domain.ContactImportRowResult
+		if err := json.Unmarshal([]byte(*job.Results), &rows); err == nil {
+			response.Rows = rows
+		}
+	}
+	return response
+}
+
+func truncateRunes(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,21 +14,37 @@ import (
 
 // ContactService handles contact business logic
 type ContactService struct {
-	contactRepo repository.ContactRepository
-	productRepo repository.ProductRepository
+	contactRepo     repository.ContactRepository
+	operatorService *OperatorService
+	settingsRepo    repository.UserSettingsRepository
 }
 
 // NewContactService creates a new contact service
 func NewContactService(
 	contactRepo repository.ContactRepository,
-	productRepo repository.ProductRepository,
+	operatorService *OperatorService,
+	settingsRepo repository.UserSettingsRepository,
 ) *ContactService {
 	return &ContactService{
-		contactRepo: contactRepo,
-		productRepo: productRepo,
+		contactRepo:     contactRepo,
+		operatorService: operatorService,
+		settingsRepo:    settingsRepo,
 	}
 }
 
+// suggestionLimit is the number of recent and frequent targets returned per service type
+const suggestionLimit = 5
+
+// ContactTarget describes a transaction target that may be remembered as a contact
+type ContactTarget struct {
+	UserID      string
+	ServiceType string
+	Value       string
+	Name        string // Explicit contact name; falls back to customer/account name or the value
+	Save        bool   // User explicitly asked to save the target, bypassing the auto-save setting
+	Metadata    map[string]interface{}
+}
+
 // List returns user's contacts with optional filters
 func (s *ContactService) List(ctx context.Context, userID string, filter repository.ContactFilter) (*domain.ContactListResponse, error) {
 	// Set default limit
@@ -76,14 +93,7 @@ func (s *ContactService) Create(ctx context.Context, userID string, name, contac
 
 	// Enhance metadata for phone type (detect operator)
 	if contactType == domain.ContactTypePhone {
-		operator, err := s.productRepo.FindOperatorByPrefix(ctx, value)
-		if err == nil && operator != nil {
-			if metadata == nil {
-				metadata = make(map[string]interface{})
-			}
-			metadata["operator"] = operator.ID
-			metadata["operatorName"] = operator.Name
-		}
+		metadata = s.withOperatorMetadata(ctx, value, metadata)
 	}
 
 	// Marshal metadata to JSON
@@ -168,8 +178,265 @@ func (s *ContactService) Delete(ctx context.Context, userID, contactID string) (
 	}, nil
 }
 
+// RecordUsage tracks a successful transaction against its target. Existing contacts
+// get their usage bumped and metadata refreshed; unknown targets are only saved when
+// the user has auto-save enabled or explicitly asked to save them. Failures are logged
+// and never surface to the caller, since the transaction itself already succeeded.
+func (s *ContactService) RecordUsage(ctx context.Context, target ContactTarget) {
+	if err := s.recordUsage(ctx, target); err != nil {
+		slog.Warn("failed to record contact usage",
+			slog.String("user_id", target.UserID),
+			slog.String("service_type", target.ServiceType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// SaveTarget saves a transaction target as a contact on explicit user request
+// without counting it as a use. It is meant for transactions that have not
+// completed yet; usage is recorded once they succeed.
+func (s *ContactService) SaveTarget(ctx context.Context, target ContactTarget) {
+	if err := s.saveTarget(ctx, target); err != nil {
+		slog.Warn("failed to save transaction contact",
+			slog.String("user_id", target.UserID),
+			slog.String("service_type", target.ServiceType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// ContactSaveRequest carries a user's explicit request to save a transaction target
+type ContactSaveRequest struct {
+	Name string
+}
+
+// Suggest returns recently and frequently used contacts for a service type
+func (s *ContactService) Suggest(ctx context.Context, userID, serviceType string) (*domain.ContactSuggestions, error) {
+	contactType := domain.ContactTypeForService(serviceType)
+	if contactType == "" {
+		return nil, nil
+	}
+
+	recent, err := s.contactRepo.FindRecentByType(ctx, userID, contactType, suggestionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent contacts: %w", err)
+	}
+	frequent, err := s.contactRepo.FindFrequentByType(ctx, userID, contactType, suggestionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frequent contacts: %w", err)
+	}
+
+	suggestions := &domain.ContactSuggestions{
+		Recent:   make([]*domain.ContactDetail, 0, len(recent)),
+		Frequent: make([]*domain.ContactDetail, 0, len(frequent)),
+	}
+	for _, c := range recent {
+		suggestions.Recent = append(suggestions.Recent, s.toContactDetail(c))
+	}
+	for _, c := range frequent {
+		suggestions.Frequent = append(suggestions.Frequent, s.toContactDetail(c))
+	}
+
+	return suggestions, nil
+}
+
+// SuggestionsFor is the inquiry-side variant of Suggest: errors are logged and
+// an empty result is returned so inquiries never fail because of suggestions
+func (s *ContactService) SuggestionsFor(ctx context.Context, userID, serviceType string) *domain.ContactSuggestions {
+	suggestions, err := s.Suggest(ctx, userID, serviceType)
+	if err != nil {
+		slog.Warn("failed to load contact suggestions",
+			slog.String("user_id", userID),
+			slog.String("service_type", serviceType),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	return suggestions
+}
+
 // Helper functions
 
+func (s *ContactService) recordUsage(ctx context.Context, target ContactTarget) error {
+	contactType := domain.ContactTypeForService(target.ServiceType)
+	if contactType == "" || target.UserID == "" {
+		return nil
+	}
+	// Targets that would not pass manual contact validation are not remembered
+	if err := s.validateContactValue(contactType, target.Value); err != nil {
+		return nil
+	}
+
+	existing, err := s.contactRepo.FindByUserAndValue(ctx, target.UserID, contactType, target.Value)
+	if err != nil {
+		return fmt.Errorf("failed to find contact: %w", err)
+	}
+
+	if existing == nil && !target.Save {
+		autoSave, err := s.autoSaveEnabled(ctx, target.UserID)
+		if err != nil {
+			return err
+		}
+		if !autoSave {
+			return nil
+		}
+	}
+
+	contact, err := s.buildContact(ctx, contactType, target)
+	if err != nil {
+		return err
+	}
+	contact.LastUsedAt = &contact.CreatedAt
+	contact.UsageCount = 1
+
+	if err := s.contactRepo.Upsert(ctx, contact); err != nil {
+		return fmt.Errorf("failed to upsert contact: %w", err)
+	}
+
+	if existing != nil && target.Save && target.Name != "" && existing.Name != target.Name {
+		return s.renameContact(ctx, existing.ID, contact.Name)
+	}
+	return nil
+}
+
+func (s *ContactService) saveTarget(ctx context.Context, target ContactTarget) error {
+	contactType := domain.ContactTypeForService(target.ServiceType)
+	if contactType == "" || target.UserID == "" {
+		return nil
+	}
+	if err := s.validateContactValue(contactType, target.Value); err != nil {
+		return nil
+	}
+
+	existing, err := s.contactRepo.FindByUserAndValue(ctx, target.UserID, contactType, target.Value)
+	if err != nil {
+		return fmt.Errorf("failed to find contact: %w", err)
+	}
+	if existing != nil {
+		if target.Name != "" && existing.Name != target.Name {
+			return s.renameContact(ctx, existing.ID, s.contactName(target))
+		}
+		return nil
+	}
+
+	contact, err := s.buildContact(ctx, contactType, target)
+	if err != nil {
+		return err
+	}
+	if err := s.contactRepo.Create(ctx, contact); err != nil {
+		return fmt.Errorf("failed to create contact: %w", err)
+	}
+	return nil
+}
+
+// buildContact assembles a new contact for a transaction target, detecting the
+// operator for phone targets when the caller did not supply one
+func (s *ContactService) buildContact(ctx context.Context, contactType string, target ContactTarget) (*domain.Contact, error) {
+	metadata := target.Metadata
+	if contactType == domain.ContactTypePhone && metadata["operator"] == nil {
+		metadata = s.withOperatorMetadata(ctx, target.Value, metadata)
+	}
+
+	var metadataJSON *string
+	if len(metadata) > 0 {
+		bytes, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		str := string(bytes)
+		metadataJSON = &str
+	}
+
+	now := time.Now()
+	return &domain.Contact{
+		ID:        "cnt_" + uuid.New().String()[:8],
+		UserID:    target.UserID,
+		Name:      s.contactName(target),
+		Type:      contactType,
+		Value:     target.Value,
+		Metadata:  metadataJSON,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// withOperatorMetadata adds the detected operator of a phone number to metadata
+func (s *ContactService) withOperatorMetadata(ctx context.Context, phone string, metadata map[string]interface{}) map[string]interface{} {
+	operator := s.detectOperator(ctx, phone)
+	if operator == nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["operator"] = operator.ID
+	metadata["operatorName"] = operator.Name
+	return metadata
+}
+
+// detectOperator returns the best operator match for a phone number, or nil
+func (s *ContactService) detectOperator(ctx context.Context, phone string) *domain.Operator {
+	if s.operatorService == nil {
+		return nil
+	}
+	detection, err := s.operatorService.Detect(ctx, phone)
+	if err != nil {
+		return nil
+	}
+	return detection.Operator
+}
+
+func (s *ContactService) renameContact(ctx context.Context, contactID, name string) error {
+	contact, err := s.contactRepo.FindByID(ctx, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil
+	}
+	contact.Name = name
+	contact.UpdatedAt = time.Now()
+	if err := s.contactRepo.Update(ctx, contact); err != nil {
+		return fmt.Errorf("failed to rename contact: %w", err)
+	}
+	return nil
+}
+
+func (s *ContactService) autoSaveEnabled(ctx context.Context, userID string) (bool, error) {
+	if s.settingsRepo == nil {
+		return true, nil
+	}
+	settings, err := s.settingsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	if settings == nil {
+		// Default settings have auto-save enabled
+		return true, nil
+	}
+	return settings.AutoSaveContact, nil
+}
+
+func (s *ContactService) contactName(target ContactTarget) string {
+	name := target.Name
+	if name == "" {
+		for _, key := range []string{"accountName", "customerName"} {
+			if value, ok := target.Metadata[key].(string); ok && value != "" {
+				name = value
+				break
+			}
+		}
+	}
+	if name == "" {
+		name = target.Value
+	}
+	// contacts.name is VARCHAR(100)
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	return name
+}
+
 func (s *ContactService) toContactDetail(c *domain.Contact) *domain.ContactDetail {
 	detail := &domain.ContactDetail{
 		ID:         c.ID,
@@ -221,6 +488,17 @@ func (s *ContactService) toContactDetail(c *domain.Contact) *domain.ContactDetai
 				}
 			}
 
+			// Add region for PDAM contacts
+			if c.Type == domain.ContactTypePDAM {
+				if regionID, ok := metadata["regionId"].(string); ok && regionID != "" {
+					region := &domain.RegionInfo{ID: regionID}
+					if regionName, ok := metadata["regionName"].(string); ok {
+						region.Name = regionName
+					}
+					detail.Region = region
+				}
+			}
+
 			// Add customer name for PLN/PDAM/BPJS
 			if c.Type == domain.ContactTypePLN || c.Type == domain.ContactTypePDAM || c.Type == domain.ContactTypeBPJS {
 				if customerName, ok := metadata["customerName"].(string); ok {
@@ -21,6 +21,7 @@ type DepositService struct {
 	balanceRepo   repository.BalanceRepository
 	userRepo      repository.UserRepository
 	gerbangClient *gerbang.Client
+	limits        *AccountLimitService
 	allowDummy    bool
 }
 
@@ -30,6 +31,7 @@ func NewDepositService(
 	balanceRepo repository.BalanceRepository,
 	userRepo repository.UserRepository,
 	gerbangClient *gerbang.Client,
+	limits *AccountLimitService,
 	allowDummy bool,
 ) *DepositService {
 	return &DepositService{
@@ -37,6 +39,7 @@ func NewDepositService(
 		balanceRepo:   balanceRepo,
 		userRepo:      userRepo,
 		gerbangClient: gerbangClient,
+		limits:        limits,
 		allowDummy:    allowDummy,
 	}
 }
@@ -62,6 +65,9 @@ func (s *DepositService) CreateBankTransfer(ctx context.Context, userID string,
 	if amount > 50000000 {
 		return nil, domain.ErrAmountTooHigh
 	}
+	if err := s.limits.CheckTopUp(ctx, userID, amount); err != nil {
+		return nil, err
+	}
 
 	// Check pending deposits limit (max 3 pending)
 	pendingCount, err := s.depositRepo.CountPending(ctx, userID)
@@ -136,6 +142,9 @@ func (s *DepositService) CreateQRIS(ctx context.Context, userID string, amount i
 	if amount > 10000000 {
 		return nil, domain.ErrAmountTooHigh
 	}
+	if err := s.limits.CheckTopUp(ctx, userID, amount); err != nil {
+		return nil, err
+	}
 
 	// Check pending deposits limit (max 3 pending)
 	pendingCount, err := s.depositRepo.CountPending(ctx, userID)
@@ -172,6 +181,9 @@ func (s *DepositService) CreateQRIS(ctx context.Context, userID string, amount i
 
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return nil, domain.ErrProviderUnavailable
+			}
 			return nil, fmt.Errorf("failed to create QRIS payment via Gerbang: %w", err)
 		}
 
@@ -294,6 +306,9 @@ func (s *DepositService) CreateRetail(ctx context.Context, userID, providerCode
 	if amount > provider.MaxAmount {
 		return nil, domain.ErrAmountTooHigh
 	}
+	if err := s.limits.CheckTopUp(ctx, userID, amount); err != nil {
+		return nil, err
+	}
 
 	// Check pending deposits limit (max 3 pending)
 	pendingCount, err := s.depositRepo.CountPending(ctx, userID)
@@ -329,6 +344,9 @@ func (s *DepositService) CreateRetail(ctx context.Context, userID, providerCode
 
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return nil, domain.ErrProviderUnavailable
+			}
 			return nil, fmt.Errorf("failed to create retail payment via Gerbang: %w", err)
 		}
 
@@ -452,6 +470,9 @@ func (s *DepositService) CreateVA(ctx context.Context, userID, bankCode string,
 	if amount > bank.MaxAmount {
 		return nil, domain.ErrAmountTooHigh
 	}
+	if err := s.limits.CheckTopUp(ctx, userID, amount); err != nil {
+		return nil, err
+	}
 
 	// Check pending deposits limit (max 3 pending)
 	pendingCount, err := s.depositRepo.CountPending(ctx, userID)
@@ -487,6 +508,9 @@ func (s *DepositService) CreateVA(ctx context.Context, userID, bankCode string,
 
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return nil, domain.ErrProviderUnavailable
+			}
 			return nil, fmt.Errorf("failed to create VA payment via Gerbang: %w", err)
 		}
 
@@ -669,7 +693,7 @@ func (s *DepositService) HandleWebhook(ctx context.Context, referenceID string)
 
 	// Check if already paid - return nil for idempotency (not error!)
 	// This ensures webhook sender gets 200 OK and won't retry forever
-	if deposit.Status == domain.DepositStatusSuccess {
+	if deposit.Status == domain.DepositStatusSuccess || deposit.Status == domain.DepositStatusReview {
 		return nil // Idempotent: already processed, just return success
 	}
 
@@ -687,8 +711,29 @@ func (s *DepositService) HandleWebhook(ctx context.Context, referenceID string)
 		return domain.ErrNotFound("Balance")
 	}
 
-	// Update deposit status to success
+	// Hold the deposit for review instead of crediting it past the maximum balance
 	now := time.Now()
+	violation, err := s.limits.CreditViolation(ctx, deposit.UserID, balance.Amount+balance.PendingAmount, deposit.Amount)
+	if err != nil {
+		return err
+	}
+	if violation != nil {
+		if err := s.depositRepo.UpdateStatusWithTx(ctx, tx, deposit.ID, domain.DepositStatusReview, &now); err != nil {
+			return fmt.Errorf("failed to hold deposit: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		slog.Warn("deposit held for review, credit would exceed maximum balance",
+			slog.String("deposit_id", deposit.ID),
+			slog.String("user_id", deposit.UserID),
+			slog.Int64("amount", deposit.Amount),
+			slog.Int64("max_balance", violation.Limit),
+		)
+		return nil
+	}
+
+	// Update deposit status to success
 	if err := s.depositRepo.UpdateStatusWithTx(ctx, tx, deposit.ID, domain.DepositStatusSuccess, &now); err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
@@ -879,6 +924,7 @@ func getDepositStatusLabel(status string) string {
 		domain.DepositStatusSuccess: "Berhasil",
 		domain.DepositStatusExpired: "Expired",
 		domain.DepositStatusFailed:  "Gagal",
+		domain.DepositStatusReview:  "Dalam Peninjauan",
 	}
 	if label, ok := labels[status]; ok {
 		return label
@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/config"
+	"github.com/GTDGit/PPOB_BE/internal/domain"
 	internalbrevo "github.com/GTDGit/PPOB_BE/internal/external/brevo"
 	internalses "github.com/GTDGit/PPOB_BE/internal/external/ses"
 	internalsmtp "github.com/GTDGit/PPOB_BE/internal/external/smtp"
@@ -269,6 +271,101 @@ func (s *EmailService) SendAdminPasswordReset(ctx context.Context, email, name,
 	})
 }
 
+// SendProductChangeAlert notifies an admin mailbox about product changes that
+// crossed the sync alert thresholds
+func (s *EmailService) SendProductChangeAlert(ctx context.Context, email string, changes []*domain.ProductChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	const maxListed = 50
+	subject := fmt.Sprintf("[Produk] %d perubahan produk perlu dicek", len(changes))
+	lines := make([]string, 0, len(changes))
+	for i, change := range changes {
+		if i == maxListed {
+			lines = append(lines, fmt.Sprintf("... dan %d perubahan lainnya", len(changes)-maxListed))
+			break
+		}
+		reason := ""
+		if change.AlertReason != nil {
+			reason = *change.AlertReason
+		}
+		lines = append(lines, change.SKUCode+": "+reason)
+	}
+
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = html.EscapeString(line)
+	}
+	body := buildActionEmailHTML("Perubahan Produk Terdeteksi", "Tim Operasional",
+		"Sinkronisasi produk menemukan perubahan yang melewati ambang batas. Lihat riwayat lengkap per SKU di console admin.",
+		"", "", strings.Join(escaped, "<br>"),
+	)
+	text := strings.Join(append([]string{
+		"Sinkronisasi produk menemukan perubahan yang melewati ambang batas:",
+	}, lines...), "\n")
+
+	return s.sendCustomEmail(ctx, sendCustomEmailRequest{
+		Category:  "product_change_alert",
+		ToEmail:   email,
+		ToName:    "Tim Operasional",
+		Subject:   subject,
+		HTMLBody:  body,
+		TextBody:  text,
+		ReplyTo:   []string{s.emailCfg.ReplyToEmail},
+		ConfigSet: s.emailCfg.SES.ConfigurationSetTransactional,
+	})
+}
+
+// MailboxSLAAlert describes a support thread that missed an SLA target
+type MailboxSLAAlert struct {
+	ThreadID         string
+	Subject          string
+	MailboxAddress   string
+	ParticipantEmail string
+	Target           string // domain.MailboxSLAFirstResponse or domain.MailboxSLAResolution
+	DueAt            time.Time // In the mailbox's timezone
+	ReassignedTo     string // Name of the new assignee, if the thread was moved
+}
+
+// SendMailboxSLAAlert tells an admin a support thread breached its SLA
+func (s *EmailService) SendMailboxSLAAlert(ctx context.Context, email, name string, alert MailboxSLAAlert) error {
+	displayName := fallbackDisplayName(name, "Admin")
+	target := "balasan pertama"
+	if alert.Target == domain.MailboxSLAResolution {
+		target = "penyelesaian"
+	}
+	subject := fmt.Sprintf("[SLA] Target %s terlewat: %s", target, alert.Subject)
+	lines := []string{
+		"Thread: " + alert.Subject + " (" + alert.ThreadID + ")",
+		"Mailbox: " + alert.MailboxAddress,
+		"Pengirim: " + alert.ParticipantEmail,
+		"Batas waktu: " + alert.DueAt.Format("02 Jan 2006 15:04 MST"),
+	}
+	if alert.ReassignedTo != "" {
+		lines = append(lines, "Dialihkan ke: "+alert.ReassignedTo)
+	}
+
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = html.EscapeString(line)
+	}
+	intro := fmt.Sprintf("Thread berikut melewati target %s SLA mailbox. Segera tindak lanjuti di console admin.", target)
+	body := buildActionEmailHTML("Target SLA Terlewat", displayName, intro, "", "", strings.Join(escaped, "<br>"))
+	text := strings.Join(append([]string{"Halo " + displayName, intro}, lines...), "\n")
+
+	return s.sendCustomEmail(ctx, sendCustomEmailRequest{
+		Category:  "mailbox_sla_alert",
+		ToEmail:   email,
+		ToName:    displayName,
+		Subject:   subject,
+		HTMLBody:  body,
+		TextBody:  text,
+		ReplyTo:   []string{s.emailCfg.ReplyToEmail},
+		ConfigSet: s.emailCfg.SES.ConfigurationSetTransactional,
+	})
+}
+
 func (s *EmailService) SendEmailChangedAlert(ctx context.Context, oldEmail, name, newEmail string) error {
 	changeTime := time.Now().Format("02 Jan 2006 15:04 WIB")
 
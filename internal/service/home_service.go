@@ -3,15 +3,24 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
 	"github.com/GTDGit/PPOB_BE/pkg/redis"
 )
 
 const homeCacheTTL = 5 * time.Minute
 
+// ProviderAvailability reports whether the provider behind an endpoint group
+// currently accepts calls
+type ProviderAvailability interface {
+	Available(endpoint string) bool
+}
+
 // HomeService handles home screen business logic
 type HomeService struct {
 	homeRepo         repository.HomeRepository
@@ -19,6 +28,8 @@ type HomeService struct {
 	balanceRepo      repository.BalanceRepository
 	notificationRepo repository.NotificationRepository
 	redisClient      *redis.Client
+	providers        ProviderAvailability
+	maintenance      *MaintenanceService
 }
 
 // NewHomeService creates a new home service
@@ -28,6 +39,8 @@ func NewHomeService(
 	balanceRepo repository.BalanceRepository,
 	notificationRepo repository.NotificationRepository,
 	redisClient *redis.Client,
+	providers ProviderAvailability,
+	maintenance *MaintenanceService,
 ) *HomeService {
 	return &HomeService{
 		homeRepo:         homeRepo,
@@ -35,6 +48,8 @@ func NewHomeService(
 		balanceRepo:      balanceRepo,
 		notificationRepo: notificationRepo,
 		redisClient:      redisClient,
+		providers:        providers,
+		maintenance:      maintenance,
 	}
 }
 
@@ -80,13 +95,17 @@ func (s *HomeService) GetHome(ctx context.Context, userID string, servicesVersio
 
 	// Build services data (with version check)
 	var servicesData *domain.HomeServicesData
-	currentServicesVersion := s.homeRepo.GetServicesVersion(ctx)
+	unavailable := s.unavailableEndpoints()
+	maintenance := s.maintenance.ServiceWindows(ctx)
+	currentServicesVersion := servicesVersionWith(s.homeRepo.GetServicesVersion(ctx), unavailable) + maintenanceVersion(maintenance)
 	if servicesVersion != currentServicesVersion {
 		servicesData = &domain.HomeServicesData{
 			Version:    currentServicesVersion,
 			Featured:   s.homeRepo.GetFeaturedServices(ctx),
 			Categories: s.homeRepo.GetServiceCategories(ctx),
 		}
+		markUnavailableServices(servicesData.Featured, servicesData.Categories, unavailable)
+		markMaintenanceServices(servicesData.Featured, servicesData.Categories, maintenance)
 	}
 
 	// Build banners data (with version check)
@@ -143,7 +162,9 @@ func (s *HomeService) GetBalance(ctx context.Context, userID string) (*domain.Ba
 
 // GetServices returns services list with version
 func (s *HomeService) GetServices(ctx context.Context, version string) (*domain.ServicesResponse, bool, error) {
-	currentVersion := s.homeRepo.GetServicesVersion(ctx)
+	unavailable := s.unavailableEndpoints()
+	maintenance := s.maintenance.ServiceWindows(ctx)
+	currentVersion := servicesVersionWith(s.homeRepo.GetServicesVersion(ctx), unavailable) + maintenanceVersion(maintenance)
 
 	// Check if client version matches (304 Not Modified)
 	if version != "" && version == currentVersion {
@@ -155,6 +176,8 @@ func (s *HomeService) GetServices(ctx context.Context, version string) (*domain.
 	var cached domain.ServicesResponse
 	if err := s.redisClient.GetJSON(ctx, cacheKey, &cached); err == nil && len(cached.Featured) > 0 {
 		cached.Version = currentVersion
+		markUnavailableServices(cached.Featured, cached.Categories, unavailable)
+		markMaintenanceServices(cached.Featured, cached.Categories, maintenance)
 		return &cached, false, nil
 	}
 
@@ -167,11 +190,105 @@ func (s *HomeService) GetServices(ctx context.Context, version string) (*domain.
 	// Cache
 	if services != nil {
 		s.redisClient.SetJSON(ctx, cacheKey, services, homeCacheTTL)
+		services.Version = currentVersion
+		markUnavailableServices(services.Featured, services.Categories, unavailable)
+		markMaintenanceServices(services.Featured, services.Categories, maintenance)
 	}
 
 	return services, false, nil
 }
 
+// unavailableEndpoints returns the provider endpoint groups behind service
+// tiles whose circuit is currently open
+func (s *HomeService) unavailableEndpoints() []string {
+	if s.providers == nil {
+		return nil
+	}
+	var unavailable []string
+	for _, endpoint := range []string{gerbang.EndpointPPOB, gerbang.EndpointTransfer} {
+		if !s.providers.Available(endpoint) {
+			unavailable = append(unavailable, endpoint)
+		}
+	}
+	return unavailable
+}
+
+// servicesVersionWith changes the services version while providers are down,
+// so clients refetch the tiles both when an outage starts and when it ends
+func servicesVersionWith(version string, unavailable []string) string {
+	if len(unavailable) == 0 {
+		return version
+	}
+	return version + "-unavailable-" + strings.Join(unavailable, ".")
+}
+
+// serviceEndpoint returns the provider endpoint group a service tile depends on
+func serviceEndpoint(serviceID string) string {
+	if serviceID == "transfer_bank" {
+		return gerbang.EndpointTransfer
+	}
+	return gerbang.EndpointPPOB
+}
+
+// markUnavailableServices shows active tiles as temporarily unavailable while
+// their provider is down
+func markUnavailableServices(featured []*domain.ServiceMenu, categories []*domain.ServiceCategory, unavailable []string) {
+	if len(unavailable) == 0 {
+		return
+	}
+	down := make(map[string]bool, len(unavailable))
+	for _, endpoint := range unavailable {
+		down[endpoint] = true
+	}
+	mark := func(menus []*domain.ServiceMenu) {
+		for _, menu := range menus {
+			if menu.Status == domain.ServiceStatusActive && down[serviceEndpoint(menu.ID)] {
+				menu.Status = domain.ServiceStatusUnavailable
+			}
+		}
+	}
+	mark(featured)
+	for _, category := range categories {
+		mark(category.Services)
+	}
+}
+
+// maintenanceVersion changes the services version while tiles are under
+// maintenance, so clients refetch the tiles when a window opens or ends
+func maintenanceVersion(tiles map[string]*domain.MaintenanceWindow) string {
+	if len(tiles) == 0 {
+		return ""
+	}
+	ids := make([]string, 0, len(tiles))
+	for id := range tiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return "-maintenance-" + strings.Join(ids, ".")
+}
+
+// markMaintenanceServices shows active tiles under a maintenance window as
+// maintenance, with the window's message and ETA
+func markMaintenanceServices(featured []*domain.ServiceMenu, categories []*domain.ServiceCategory, tiles map[string]*domain.MaintenanceWindow) {
+	if len(tiles) == 0 {
+		return
+	}
+	mark := func(menus []*domain.ServiceMenu) {
+		for _, menu := range menus {
+			window, ok := tiles[menu.ID]
+			if !ok || (menu.Status != domain.ServiceStatusActive && menu.Status != domain.ServiceStatusUnavailable) {
+				continue
+			}
+			menu.Status = domain.ServiceStatusMaintenance
+			menu.Notice = window.Notice()
+		}
+	}
+	mark(featured)
+	for _, category := range categories {
+		mark(category.Services)
+	}
+}
+
 // GetBanners returns banners list with version
 func (s *HomeService) GetBanners(ctx context.Context, userID string, placement, version string) (*domain.BannersResponse, bool, error) {
 	// Get user for tier targeting
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+)
+
+// kybMaxFileSize bounds an uploaded KYB document
+const kybMaxFileSize = 10 << 20
+
+// KYBService handles merchant business verification
+type KYBService struct {
+	kybRepo       repository.KYBRepository
+	userRepo      repository.UserRepository
+	territoryRepo repository.TerritoryRepository
+	artefacts     *KYCArtefactService // Private KYC bucket: documents are shown to reviewers only
+	allowDummy    bool
+}
+
+// NewKYBService creates a new KYB service
+func NewKYBService(
+	kybRepo repository.KYBRepository,
+	userRepo repository.UserRepository,
+	territoryRepo repository.TerritoryRepository,
+	artefacts *KYCArtefactService,
+	allowDummy bool,
+) *KYBService {
+	return &KYBService{
+		kybRepo:       kybRepo,
+		userRepo:      userRepo,
+		territoryRepo: territoryRepo,
+		artefacts:     artefacts,
+		allowDummy:    allowDummy,
+	}
+}
+
+// KYBSubmitRequest is the business data sent with a KYB submission
+type KYBSubmitRequest struct {
+	BusinessName    string `json:"businessName" binding:"required"`
+	LicenseType     string `json:"licenseType" binding:"required"`
+	LicenseNumber   string `json:"licenseNumber" binding:"required"`
+	NPWPNumber      string `json:"npwpNumber" binding:"required"`
+	AddressStreet   string `json:"addressStreet" binding:"required"`
+	SubDistrictCode string `json:"subDistrictCode" binding:"required"`
+	PostalCode      string `json:"postalCode"`
+}
+
+// GetStatus returns the user's KYB submission and what is still missing
+func (s *KYBService) GetStatus(ctx context.Context, userID string) (*domain.KYBStatusResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, domain.ErrNotFound("User")
+	}
+
+	submission, err := s.kybRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kyb submission: %w", err)
+	}
+
+	response := &domain.KYBStatusResponse{
+		Status:           domain.KYBStatusNone,
+		KYCVerified:      user.KYCStatus == domain.KYCStatusVerified,
+		MissingDocuments: []string{domain.KYBDocumentLicense, domain.KYBDocumentNPWP, domain.KYBDocumentStorefront},
+	}
+	if submission != nil {
+		response.Status = submission.Status
+		response.MissingDocuments = submission.MissingDocuments()
+		response.QRISMerchantName = submission.QRISMerchantName()
+		response.Submission = submission
+	}
+	return response, nil
+}
+
+// UploadDocument stores one KYB document and attaches it to the user's draft
+// submission, creating the draft on the first upload
+func (s *KYBService) UploadDocument(ctx context.Context, userID, documentType string, fileBytes []byte, filename string) (*domain.KYBSubmission, error) {
+	if err := validateKYBFile(documentType, fileBytes); err != nil {
+		return nil, err
+	}
+
+	submission, err := s.editableSubmission(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileURL, err := s.artefacts.Store(ctx, fmt.Sprintf("kyb/%s", userID), fileBytes, filename)
+	if err != nil {
+		if !s.allowDummy {
+			return nil, fmt.Errorf("failed to upload to S3: %w", err)
+		}
+
+		slog.Warn("falling back to dummy KYB upload URL",
+			slog.String("user_id", userID),
+			slog.String("document", documentType),
+			slog.String("error", err.Error()),
+		)
+		fileURL = fmt.Sprintf("dummy://kyb/%s/%s", userID, sanitizeAlpha(filename, 8))
+	}
+
+	switch documentType {
+	case domain.KYBDocumentLicense:
+		submission.LicenseURL = &fileURL
+	case domain.KYBDocumentNPWP:
+		submission.NPWPURL = &fileURL
+	case domain.KYBDocumentStorefront:
+		submission.StorefrontURL = &fileURL
+	}
+
+	if err := s.kybRepo.Save(ctx, submission); err != nil {
+		return nil, fmt.Errorf("failed to save kyb submission: %w", err)
+	}
+	return submission, nil
+}
+
+// Submit validates the business data and documents and queues the submission
+// for admin review
+func (s *KYBService) Submit(ctx context.Context, userID string, req KYBSubmitRequest) (*domain.KYBSubmission, error) {
+	submission, err := s.editableSubmission(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if missing := submission.MissingDocuments(); len(missing) > 0 {
+		return nil, domain.ErrValidationFailed("Dokumen usaha belum lengkap: " + strings.Join(missing, ", "))
+	}
+
+	businessName := strings.Join(strings.Fields(req.BusinessName), " ")
+	if len(businessName) < 3 || len(businessName) > 100 {
+		return nil, domain.ErrValidationFailed("Nama usaha harus 3-100 karakter")
+	}
+	licenseType := strings.ToUpper(strings.TrimSpace(req.LicenseType))
+	if err := domain.ValidateKYBLicense(licenseType, req.LicenseNumber); err != nil {
+		return nil, err
+	}
+	npwp, err := domain.NormalizeNPWP(req.NPWPNumber)
+	if err != nil {
+		return nil, err
+	}
+	postalCode := strings.TrimSpace(req.PostalCode)
+	if postalCode != "" && (len(postalCode) != 5 || strings.Trim(postalCode, "0123456789") != "") {
+		return nil, domain.ErrValidationFailed("Kode pos harus 5 digit angka")
+	}
+	if strings.TrimSpace(req.AddressStreet) == "" {
+		return nil, domain.ErrValidationFailed("Alamat usaha wajib diisi")
+	}
+
+	if err := s.resolveAddress(ctx, submission, strings.TrimSpace(req.SubDistrictCode)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	submission.BusinessName = businessName
+	submission.LicenseType = licenseType
+	submission.LicenseNumber = strings.TrimSpace(req.LicenseNumber)
+	submission.NPWPNumber = npwp
+	submission.AddressStreet = strings.TrimSpace(req.AddressStreet)
+	submission.PostalCode = postalCode
+	submission.Status = domain.KYBStatusPending
+	submission.RejectionReason = nil
+	submission.SubmittedAt = &now
+
+	if err := s.kybRepo.Save(ctx, submission); err != nil {
+		return nil, fmt.Errorf("failed to save kyb submission: %w", err)
+	}
+	return submission, nil
+}
+
+// editableSubmission returns the user's draft or rejected submission, or a
+// new draft. KYB builds on the owner's identity, so KYC must be verified.
+func (s *KYBService) editableSubmission(ctx context.Context, userID string) (*domain.KYBSubmission, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, domain.ErrNotFound("User")
+	}
+	if user.KYCStatus != domain.KYCStatusVerified {
+		return nil, domain.NewError(domain.CodeKYCRequired, "Verifikasi identitas diperlukan sebelum verifikasi usaha", 403)
+	}
+
+	submission, err := s.kybRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kyb submission: %w", err)
+	}
+	if submission == nil {
+		return &domain.KYBSubmission{UserID: userID, Status: domain.KYBStatusDraft}, nil
+	}
+	if !submission.Editable() {
+		if submission.Status == domain.KYBStatusApproved {
+			return nil, domain.NewError(domain.CodeKYBNotEditable, "Usaha sudah terverifikasi", 409)
+		}
+		return nil, domain.NewError(domain.CodeKYBNotEditable, "Verifikasi usaha sedang direview", 409)
+	}
+	// A rejected submission is edited as a new draft
+	submission.Status = domain.KYBStatusDraft
+	return submission, nil
+}
+
+// resolveAddress fills the business address from the territory hierarchy,
+// checking each level belongs to the one above
+func (s *KYBService) resolveAddress(ctx context.Context, submission *domain.KYBSubmission, subDistrictCode string) error {
+	subDistrict, err := s.territoryRepo.GetSubDistrictByCode(ctx, subDistrictCode)
+	if err != nil {
+		return fmt.Errorf("failed to get sub district: %w", err)
+	}
+	if subDistrict == nil {
+		return domain.ErrValidationFailed("Kelurahan/desa tidak ditemukan")
+	}
+	district, err := s.territoryRepo.GetDistrictByCode(ctx, subDistrict.DistrictCode)
+	if err != nil {
+		return fmt.Errorf("failed to get district: %w", err)
+	}
+	if district == nil {
+		return domain.ErrValidationFailed("Kecamatan tidak ditemukan")
+	}
+	city, err := s.territoryRepo.GetCityByCode(ctx, district.CityCode)
+	if err != nil {
+		return fmt.Errorf("failed to get city: %w", err)
+	}
+	if city == nil {
+		return domain.ErrValidationFailed("Kota/kabupaten tidak ditemukan")
+	}
+	province, err := s.territoryRepo.GetProvinceByCode(ctx, city.ProvinceCode)
+	if err != nil {
+		return fmt.Errorf("failed to get province: %w", err)
+	}
+	if province == nil {
+		return domain.ErrValidationFailed("Provinsi tidak ditemukan")
+	}
+
+	submission.SubDistrictCode, submission.SubDistrictName = subDistrict.Code, subDistrict.Name
+	submission.DistrictCode, submission.DistrictName = district.Code, district.Name
+	submission.CityCode, submission.CityName = city.Code, city.Name
+	submission.ProvinceCode, submission.ProvinceName = province.Code, province.Name
+	return nil
+}
+
+// validateKYBFile checks the size and type of a document: licenses and NPWP
+// may be scanned PDFs, the storefront must be a photo
+func validateKYBFile(documentType string, fileBytes []byte) error {
+	switch documentType {
+	case domain.KYBDocumentLicense, domain.KYBDocumentNPWP, domain.KYBDocumentStorefront:
+	default:
+		return domain.ErrValidationFailed("Jenis dokumen harus license, npwp atau storefront")
+	}
+	if len(fileBytes) == 0 {
+		return domain.NewError(domain.CodeKYCInvalidFile, "File dokumen kosong", 400)
+	}
+	if len(fileBytes) > kybMaxFileSize {
+		return domain.NewError(domain.CodeKYCInvalidFileSize, "Ukuran file maksimal 10MB", 400)
+	}
+
+	switch http.DetectContentType(fileBytes) {
+	case "image/jpeg", "image/png":
+		return nil
+	case "application/pdf":
+		if documentType != domain.KYBDocumentStorefront {
+			return nil
+		}
+		return domain.NewError(domain.CodeKYCInvalidFileType, "Foto toko harus berupa gambar JPG atau PNG", 400)
+	}
+	return domain.NewError(domain.CodeKYCInvalidFileType, "Format file harus JPG, PNG atau PDF", 400)
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/config"
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/external/s3"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+)
+
+// kycRetentionBatch bounds how many users one retention run purges per policy
+const kycRetentionBatch = 200
+
+// KYCArtefactService stores KYC and KYB files envelope-encrypted and hands
+// them out only through signed, expiring links that are access-logged
+type KYCArtefactService struct {
+	repo          repository.KYCArtefactRepository
+	store         *s3.SealedStore
+	cfg           config.KYCArtefactConfig
+	apiURL        string // Public API URL the links point at
+	legacyBaseURL string // Bucket URL older references were stored under
+	secret        []byte
+}
+
+// NewKYCArtefactService creates a new KYC artefact service
+func NewKYCArtefactService(
+	repo repository.KYCArtefactRepository,
+	store *s3.SealedStore,
+	cfg config.KYCArtefactConfig,
+	apiURL string,
+	legacyBaseURL string,
+) *KYCArtefactService {
+	return &KYCArtefactService{
+		repo:          repo,
+		store:         store,
+		cfg:           cfg,
+		apiURL:        strings.TrimRight(apiURL, "/"),
+		legacyBaseURL: legacyBaseURL,
+		secret:        []byte(cfg.SigningSecret),
+	}
+}
+
+// ArtefactLink is a time-limited link to one artefact
+type ArtefactLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// KYCPurgeResult summarises one retention run
+type KYCPurgeResult struct {
+	AbandonedUsers int
+	ExpiredUsers   int
+	ObjectsDeleted int
+}
+
+// Store seals an uploaded file under folder and returns the reference to
+// keep in the database
+func (s *KYCArtefactService) Store(ctx context.Context, folder string, data []byte, filename string) (string, error) {
+	return s.store.Put(ctx, data, folder, filename, http.DetectContentType(data))
+}
+
+// ProviderURL returns a short-lived link Gerbang can fetch the plaintext
+// from. References without an object (dummy fallbacks, provider-hosted
+// files) are returned unchanged.
+func (s *KYCArtefactService) ProviderURL(userID, artefact, ref string) string {
+	key, ok := domain.ArtefactObjectKey(ref, s.legacyBaseURL)
+	if !ok {
+		return ref
+	}
+	return s.link(domain.ArtefactGrant{
+		Key:       key,
+		UserID:    userID,
+		Artefact:  artefact,
+		Purpose:   domain.ArtefactPurposeProvider,
+		ExpiresAt: time.Now().Add(s.cfg.ProviderTTL).Unix(),
+	})
+}
+
+// IssueViewLink gives an admin a link to one of a user's artefacts and logs
+// who asked for it
+func (s *KYCArtefactService) IssueViewLink(ctx context.Context, adminID, userID, artefact, ipAddress, userAgent string) (*ArtefactLink, error) {
+	ref, err := s.repo.FindArtefactRef(ctx, userID, artefact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artefact: %w", err)
+	}
+	key, ok := domain.ArtefactObjectKey(ref, s.legacyBaseURL)
+	if !ok {
+		return nil, domain.ErrNotFound("Dokumen")
+	}
+
+	expiresAt := time.Now().Add(s.cfg.ViewTTL)
+	if err := s.repo.CreateAccessLog(ctx, &domain.KYCArtefactAccessLog{
+		AdminUserID: &adminID,
+		UserID:      userID,
+		Artefact:    artefact,
+		ObjectKey:   key,
+		Purpose:     domain.ArtefactPurposeAdminReview,
+		Action:      domain.ArtefactActionIssued,
+		IPAddress:   pointerIfNotEmpty(ipAddress),
+		UserAgent:   pointerIfNotEmpty(userAgent),
+	}); err != nil {
+		// No link without an access record
+		return nil, fmt.Errorf("failed to log artefact access: %w", err)
+	}
+
+	return &ArtefactLink{
+		URL: s.link(domain.ArtefactGrant{
+			Key:       key,
+			UserID:    userID,
+			Artefact:  artefact,
+			Purpose:   domain.ArtefactPurposeAdminReview,
+			AdminID:   adminID,
+			ExpiresAt: expiresAt.Unix(),
+		}),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Open verifies a link token and returns the decrypted artefact
+func (s *KYCArtefactService) Open(ctx context.Context, token, ipAddress, userAgent string) ([]byte, string, error) {
+	grant, err := domain.VerifyArtefactGrant(s.secret, token, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, contentType, err := s.store.Get(ctx, grant.Key)
+	if err != nil {
+		slog.Warn("failed to open kyc artefact",
+			slog.String("user_id", grant.UserID),
+			slog.String("artefact", grant.Artefact),
+			slog.String("error", err.Error()),
+		)
+		return nil, "", domain.ErrNotFound("Dokumen")
+	}
+
+	if err := s.repo.CreateAccessLog(ctx, &domain.KYCArtefactAccessLog{
+		AdminUserID: pointerIfNotEmpty(grant.AdminID),
+		UserID:      grant.UserID,
+		Artefact:    grant.Artefact,
+		ObjectKey:   grant.Key,
+		Purpose:     grant.Purpose,
+		Action:      domain.ArtefactActionViewed,
+		IPAddress:   pointerIfNotEmpty(ipAddress),
+		UserAgent:   pointerIfNotEmpty(userAgent),
+	}); err != nil {
+		return nil, "", fmt.Errorf("failed to log artefact access: %w", err)
+	}
+	return data, contentType, nil
+}
+
+// PurgeExpired applies the retention policy: files of KYC sessions abandoned
+// for AbandonedSessionDays are deleted, except those a verification still
+// uses, and closed accounts lose all KYC and KYB files once
+// VerifiedRetentionDays have passed
+func (s *KYCArtefactService) PurgeExpired(ctx context.Context) (*KYCPurgeResult, error) {
+	result := &KYCPurgeResult{}
+	now := time.Now()
+
+	abandonedCutoff := now.AddDate(0, 0, -s.cfg.AbandonedSessionDays)
+	userIDs, err := s.repo.FindAbandonedSessionUsers(ctx, abandonedCutoff, kycRetentionBatch)
+	if err != nil {
+		return result, fmt.Errorf("failed to find abandoned kyc sessions: %w", err)
+	}
+	for _, userID := range userIDs {
+		deleted, err := s.purgeAbandoned(ctx, userID, abandonedCutoff)
+		result.ObjectsDeleted += deleted
+		if err != nil {
+			slog.Warn("failed to purge abandoned kyc session",
+				slog.String("user_id", userID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		result.AbandonedUsers++
+	}
+
+	verifiedCutoff := now.AddDate(0, 0, -s.cfg.VerifiedRetentionDays)
+	userIDs, err = s.repo.FindExpiredVerifiedUsers(ctx, verifiedCutoff, kycRetentionBatch)
+	if err != nil {
+		return result, fmt.Errorf("failed to find expired kyc artefacts: %w", err)
+	}
+	for _, userID := range userIDs {
+		deleted, err := s.purgeVerified(ctx, userID)
+		result.ObjectsDeleted += deleted
+		if err != nil {
+			slog.Warn("failed to purge expired kyc artefacts",
+				slog.String("user_id", userID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		result.ExpiredUsers++
+	}
+	return result, nil
+}
+
+// purgeAbandoned deletes the user's KYC uploads that no verification or KYB
+// submission points at, then the expired sessions themselves
+func (s *KYCArtefactService) purgeAbandoned(ctx context.Context, userID string, cutoff time.Time) (int, error) {
+	refs, err := s.repo.FindRetainedRefs(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	retained := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if key, ok := domain.ArtefactObjectKey(ref, s.legacyBaseURL); ok {
+			retained[key] = true
+		}
+	}
+
+	keys, err := s.store.List(ctx, fmt.Sprintf("kyc/%s/", userID))
+	if err != nil {
+		return 0, err
+	}
+	var orphaned []string
+	for _, key := range keys {
+		if !retained[key] {
+			orphaned = append(orphaned, key)
+		}
+	}
+	deleted, err := s.deleteObjects(ctx, orphaned)
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, s.repo.DeleteSessionsExpiredBefore(ctx, userID, cutoff)
+}
+
+// purgeVerified deletes every KYC and KYB file of a closed account and clears
+// the references to them
+func (s *KYCArtefactService) purgeVerified(ctx context.Context, userID string) (int, error) {
+	refs, err := s.repo.FindRetainedRefs(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	for _, ref := range refs {
+		if key, ok := domain.ArtefactObjectKey(ref, s.legacyBaseURL); ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, prefix := range []string{"kyc/", "kyb/"} {
+		listed, err := s.store.List(ctx, prefix+userID+"/")
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range listed {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	deleted, err := s.deleteObjects(ctx, keys)
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, s.repo.MarkArtefactsPurged(ctx, userID)
+}
+
+func (s *KYCArtefactService) deleteObjects(ctx context.Context, keys []string) (int, error) {
+	for i, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			return i, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return len(keys), nil
+}
+
+func (s *KYCArtefactService) link(grant domain.ArtefactGrant) string {
+	return s.apiURL + "/v1/kyc/artefacts/" + url.PathEscape(domain.SignArtefactGrant(s.secret, grant))
+}
@@ -2,53 +2,115 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
 	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
-	"github.com/GTDGit/PPOB_BE/internal/external/s3"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
 	"github.com/google/uuid"
 )
 
+// Duplicate face detection: selfies of the same gender verified within the
+// lookback, plus those sharing the date of birth, are compared up to the
+// candidate limit, a few at a time and within a fixed budget so the liveness
+// request stays fast
+const (
+	kycFaceLookback       = 30 * 24 * time.Hour
+	kycFaceCandidateLimit = 10
+	kycFaceCompareWorkers = 4
+	kycFaceCheckTimeout   = 8 * time.Second
+)
+
+// faceComparer compares two face photos; implemented by the Gerbang client
+type faceComparer interface {
+	CompareFaces(ctx context.Context, ktpFaceURL, selfieFaceURL string) (*domain.FaceCompareResult, error)
+}
+
+// faceDuplicateCheck is the result of comparing a selfie with the selfies of
+// verified accounts
+type faceDuplicateCheck struct {
+	matches  int
+	complete bool // Every candidate was loaded and compared
+}
+
+// reviewRequired reports whether the account must wait for manual review:
+// its face matched another account or could not be checked against all
+func (c faceDuplicateCheck) reviewRequired() bool {
+	return c.matches > 0 || !c.complete
+}
+
 // KYCService handles KYC verification business logic
 type KYCService struct {
-	kycRepo       repository.KYCRepository
-	userRepo      repository.UserRepository
-	gerbangClient *gerbang.Client
-	s3Client      *s3.Client // HANYA untuk KTP + face photos, BUKAN liveness
-	allowDummy    bool
+	kycRepo          repository.KYCRepository
+	userRepo         repository.UserRepository
+	territoryRepo    repository.TerritoryRepository
+	gerbangClient    *gerbang.Client
+	faces            faceComparer
+	artefacts        *KYCArtefactService // HANYA untuk KTP + face photos, BUKAN liveness
+	faceCheckTimeout time.Duration
+	allowDummy       bool
 }
 
 // NewKYCService creates a new KYC service
 func NewKYCService(
 	kycRepo repository.KYCRepository,
 	userRepo repository.UserRepository,
+	territoryRepo repository.TerritoryRepository,
 	gerbangClient *gerbang.Client,
-	s3Client *s3.Client,
+	artefacts *KYCArtefactService,
 	allowDummy bool,
 ) *KYCService {
 	return &KYCService{
-		kycRepo:       kycRepo,
-		userRepo:      userRepo,
-		gerbangClient: gerbangClient,
-		s3Client:      s3Client,
-		allowDummy:    allowDummy,
+		kycRepo:          kycRepo,
+		userRepo:         userRepo,
+		territoryRepo:    territoryRepo,
+		gerbangClient:    gerbangClient,
+		faces:            gerbangClient,
+		artefacts:        artefacts,
+		faceCheckTimeout: kycFaceCheckTimeout,
+		allowDummy:       allowDummy,
 	}
 }
 
-// GetStatus returns KYC verification status for a user
-func (s *KYCService) GetStatus(ctx context.Context, userID string) (*domain.KYCVerification, error) {
+// GetStatus returns KYC verification status for a user, with the reviewer's
+// feedback when the verification was rejected
+func (s *KYCService) GetStatus(ctx context.Context, userID string) (*domain.KYCStatusInfo, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, domain.ErrNotFound("User")
+	}
+
 	// Check if user has completed verification
 	verification, err := s.kycRepo.FindVerificationByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get verification: %w", err)
 	}
 
-	return verification, nil
+	info := &domain.KYCStatusInfo{
+		Status:       user.KYCStatus,
+		Verification: verification,
+	}
+	if user.KYCStatus == domain.KYCStatusRejected {
+		info.Rejection, err = s.latestRejection(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if info.Rejection != nil && verification == nil {
+			// Nothing to reuse without a stored verification
+			info.Rejection.ResubmitFromStep = domain.KYCStepKTP
+			info.Rejection.ReusableSteps = []int{}
+		}
+	}
+
+	return info, nil
 }
 
 // GetActiveSession returns active KYC session for a user
@@ -105,6 +167,123 @@ func (s *KYCService) StartVerification(ctx context.Context, userID string) (*dom
 	return session, nil
 }
 
+// Resubmit starts a session after a rejection that reuses the artefacts of
+// the steps that passed review, so the user only redoes the rejected steps
+func (s *KYCService) Resubmit(ctx context.Context, userID string) (*domain.KYCSession, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, domain.ErrNotFound("User")
+	}
+	if user.KYCStatus != domain.KYCStatusRejected {
+		return nil, domain.ErrValidationFailed("Verifikasi KYC tidak dalam status ditolak")
+	}
+
+	rejection, err := s.latestRejection(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	verification, err := s.kycRepo.FindVerificationByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification: %w", err)
+	}
+
+	fromStep := domain.KYCStepKTP
+	if rejection != nil && verification != nil && stringPointerValue(verification.KTPUrl) != "" {
+		fromStep = rejection.ResubmitFromStep
+	}
+
+	if err := s.kycRepo.DeleteSessionByUserID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete old session: %w", err)
+	}
+
+	now := time.Now()
+	session := &domain.KYCSession{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Status:      domain.KYCSessionPending,
+		CurrentStep: fromStep - 1,
+		ExpiresAt:   now.Add(24 * time.Hour),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if fromStep > domain.KYCStepKTP {
+		session.NIK = &verification.NIK
+		session.OCRData = ocrDataFromVerification(verification)
+		session.NIKValidation = verification.NIKValidation
+		session.FaceUrls = map[string]string{
+			"ktp": stringPointerValue(verification.KTPUrl),
+		}
+	}
+	if fromStep > domain.KYCStepFace {
+		session.FaceUrls["face"] = stringPointerValue(verification.FaceUrl)
+		session.FaceUrls["fullImage"] = stringPointerValue(verification.FaceWithKTPUrl)
+		if verification.FaceSimilarity != nil {
+			session.FaceComparison = map[string]interface{}{
+				"similarity": *verification.FaceSimilarity,
+				"matched":    true,
+				"threshold":  70.0,
+			}
+		}
+	}
+
+	if err := s.kycRepo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	user.KYCStatus = domain.KYCStatusPending
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	if err := s.kycRepo.CreateHistory(ctx, &domain.KYCHistory{
+		UserID:    userID,
+		SessionID: &session.ID,
+		Action:    domain.KYCActionSessionCreated,
+		Status:    "success",
+		Metadata: map[string]interface{}{
+			"resubmission": true,
+			"fromStep":     fromStep,
+		},
+	}); err != nil {
+		slog.Warn("failed to record kyc resubmission",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return session, nil
+}
+
+// latestRejection loads the feedback of the user's most recent rejection
+func (s *KYCService) latestRejection(ctx context.Context, userID string) (*domain.KYCRejection, error) {
+	history, err := s.kycRepo.FindLatestHistory(ctx, userID, domain.KYCActionVerificationRejected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kyc history: %w", err)
+	}
+	if history == nil {
+		return nil, nil
+	}
+
+	var reasons []domain.KYCRejectionReason
+	if raw, err := json.Marshal(history.Metadata["reasons"]); err == nil {
+		_ = json.Unmarshal(raw, &reasons)
+	}
+	rejection, err := domain.NewKYCRejection(reasons, history.CreatedAt)
+	if err != nil {
+		// Rejected without structured reasons; everything is redone
+		return &domain.KYCRejection{
+			Reasons:          []domain.KYCRejectionReason{},
+			ResubmitFromStep: domain.KYCStepKTP,
+			ReusableSteps:    []int{},
+			RejectedAt:       history.CreatedAt,
+		}, nil
+	}
+	return rejection, nil
+}
+
 // CancelVerification cancels an active KYC session
 func (s *KYCService) CancelVerification(ctx context.Context, userID string) error {
 	// Delete session
@@ -157,8 +336,8 @@ func (s *KYCService) UploadKTP(ctx context.Context, userID string, sessionID str
 		return domain.NewError(domain.CodeKYCAlreadyVerified, "Akun sudah terverifikasi", 400)
 	}
 
-	// 2. Upload to S3 (ap-southeast-3 Jakarta)
-	ktpURL, err := s.s3Client.UploadBytes(ctx, fileBytes, fmt.Sprintf("kyc/%s", userID), filename, "image/jpeg")
+	// 2. Upload to S3 (ap-southeast-3 Jakarta), envelope-encrypted
+	ktpURL, err := s.artefacts.Store(ctx, fmt.Sprintf("kyc/%s", userID), fileBytes, filename)
 	if err != nil {
 		if !s.allowDummy {
 			return fmt.Errorf("failed to upload to S3: %w", err)
@@ -172,9 +351,12 @@ func (s *KYCService) UploadKTP(ctx context.Context, userID string, sessionID str
 	}
 
 	// 3. Run OCR via Gerbang API
-	ocrResult, err := s.gerbangClient.KTPOCR(ctx, ktpURL)
+	ocrResult, err := s.gerbangClient.KTPOCR(ctx, s.artefacts.ProviderURL(userID, domain.KYCArtefactKTP, ktpURL))
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return domain.ErrProviderUnavailable
+			}
 			return domain.NewError(domain.CodeKYCOCRFailed, "Gagal membaca KTP", 400)
 		}
 
@@ -186,12 +368,8 @@ func (s *KYCService) UploadKTP(ctx context.Context, userID string, sessionID str
 	}
 
 	// 4. Check NIK uniqueness
-	isUsed, err := s.kycRepo.IsNIKVerified(ctx, ocrResult.NIK)
-	if err != nil {
-		return fmt.Errorf("failed to check NIK: %w", err)
-	}
-	if isUsed {
-		return domain.NewError(domain.CodeKYCNIKAlreadyUsed, "NIK sudah terdaftar", 409)
+	if err := s.checkNIKDuplicate(ctx, userID, ocrResult.NIK); err != nil {
+		return err
 	}
 
 	// 5. Update session with OCR results
@@ -214,6 +392,7 @@ func (s *KYCService) UploadKTP(ctx context.Context, userID string, sessionID str
 	session.FaceUrls = map[string]string{
 		"ktp": ktpURL,
 	}
+	session.NIKValidation = s.validateNIK(ctx, ocrResult)
 	session.CurrentStep = 1
 	session.UpdatedAt = time.Now()
 
@@ -255,7 +434,7 @@ func (s *KYCService) UploadFacePhotos(ctx context.Context, userID string, sessio
 	}
 
 	// 2. Upload face photo (selfie) to S3
-	faceURL, err := s.s3Client.UploadBytes(ctx, faceBytes, fmt.Sprintf("kyc/%s", userID), faceFilename, "image/jpeg")
+	faceURL, err := s.artefacts.Store(ctx, fmt.Sprintf("kyc/%s", userID), faceBytes, faceFilename)
 	if err != nil {
 		if !s.allowDummy {
 			return fmt.Errorf("failed to upload face photo: %w", err)
@@ -269,7 +448,7 @@ func (s *KYCService) UploadFacePhotos(ctx context.Context, userID string, sessio
 	}
 
 	// 3. Upload full image (face + KTP) to S3
-	fullImageURL, err := s.s3Client.UploadBytes(ctx, fullImageBytes, fmt.Sprintf("kyc/%s", userID), fullImageFilename, "image/jpeg")
+	fullImageURL, err := s.artefacts.Store(ctx, fmt.Sprintf("kyc/%s", userID), fullImageBytes, fullImageFilename)
 	if err != nil {
 		if !s.allowDummy {
 			return fmt.Errorf("failed to upload full image: %w", err)
@@ -289,9 +468,15 @@ func (s *KYCService) UploadFacePhotos(ctx context.Context, userID string, sessio
 	}
 
 	// 5. Compare faces (KTP photo vs selfie) via Gerbang API
-	compareResult, err := s.gerbangClient.CompareFaces(ctx, ktpFaceURL, faceURL)
+	compareResult, err := s.gerbangClient.CompareFaces(ctx,
+		s.artefacts.ProviderURL(userID, domain.KYCArtefactKTP, ktpFaceURL),
+		s.artefacts.ProviderURL(userID, domain.KYCArtefactFace, faceURL),
+	)
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return domain.ErrProviderUnavailable
+			}
 			return domain.NewError(domain.CodeKYCFaceNoMatch, "Gagal membandingkan wajah", 400)
 		}
 
@@ -365,6 +550,9 @@ func (s *KYCService) CreateLivenessSession(ctx context.Context, userID string, k
 	livenessResp, err := s.gerbangClient.CreateLivenessSession(ctx, *session.NIK)
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return nil, domain.ErrProviderUnavailable
+			}
 			return nil, fmt.Errorf("create liveness session: %w", err)
 		}
 
@@ -433,6 +621,9 @@ func (s *KYCService) VerifyLiveness(ctx context.Context, userID string, kycSessi
 	livenessResult, err := s.gerbangClient.VerifyLiveness(ctx, livenessSessionID)
 	if err != nil {
 		if !s.allowDummy {
+			if gerbang.IsProviderUnavailable(err) {
+				return nil, domain.ErrProviderUnavailable
+			}
 			return nil, fmt.Errorf("verify liveness: %w", err)
 		}
 
@@ -484,25 +675,40 @@ func (s *KYCService) VerifyLiveness(ctx context.Context, userID string, kycSessi
 		return nil, fmt.Errorf("update session: %w", err)
 	}
 
-	// 8. Update user KYC status to verified (simplified - no face comparison for now)
+	// 8. Check the identity against other verified accounts
+	dateOfBirth := parseFlexibleDate(s.ocrString(session, "dateOfBirth", "1990-01-01"))
+	if err := s.checkNIKDuplicate(ctx, userID, *session.NIK); err != nil {
+		errMsg := "NIK sudah terdaftar"
+		session.Status = domain.KYCSessionFailed
+		session.ErrorMessage = &errMsg
+		s.kycRepo.UpdateSession(ctx, session)
+		return nil, err
+	}
+	faceCheck := s.detectFaceDuplicates(ctx, userID, session.FaceUrls["face"], s.ocrString(session, "gender", ""), dateOfBirth)
+
+	// 9. Update user KYC status; a face shared with another account, or one
+	// that could not be checked, is held for manual review
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("find user: %w", err)
 	}
 
 	user.KYCStatus = domain.KYCStatusVerified
+	if faceCheck.reviewRequired() {
+		user.KYCStatus = domain.KYCStatusPending
+	}
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, fmt.Errorf("update user: %w", err)
 	}
 
-	// 9. Create verification record
+	// 10. Create verification record
 	verification := &domain.KYCVerification{
 		ID:                 uuid.New().String(),
 		UserID:             userID,
 		NIK:                *session.NIK,
 		FullName:           s.ocrString(session, "fullName", user.FullName),
 		PlaceOfBirth:       s.ocrString(session, "placeOfBirth", "JAKARTA"),
-		DateOfBirth:        parseFlexibleDate(s.ocrString(session, "dateOfBirth", "1990-01-01")),
+		DateOfBirth:        dateOfBirth,
 		Gender:             s.ocrString(session, "gender", domain.GenderMale),
 		Religion:           s.ocrString(session, "religion", domain.ReligionIslam),
 		AddressStreet:      s.ocrString(session, "addressStreet", "-"),
@@ -519,6 +725,7 @@ func (s *KYCService) VerifyLiveness(ctx context.Context, userID string, kycSessi
 		LivenessUrl:        pointerIfNotEmpty(livenessFaceURL),
 		FaceSimilarity:     s.faceSimilarity(session),
 		LivenessConfidence: &livenessResult.Confidence,
+		NIKValidation:      session.NIKValidation,
 		VerifiedAt:         time.Now(),
 	}
 
@@ -526,18 +733,194 @@ func (s *KYCService) VerifyLiveness(ctx context.Context, userID string, kycSessi
 		return nil, fmt.Errorf("create verification: %w", err)
 	}
 
-	// 10. Clean up session
+	// 11. Clean up session
 	s.kycRepo.DeleteSessionByUserID(ctx, userID)
 
-	// 11. Return result
+	// 12. Return result
 	return map[string]interface{}{
-		"status":             domain.KYCStatusVerified,
+		"status":             user.KYCStatus,
+		"reviewRequired":     faceCheck.reviewRequired(),
 		"nik":                maskNIK(*session.NIK),
 		"livenessConfidence": livenessResult.Confidence,
 		"verifiedAt":         time.Now(),
 	}, nil
 }
 
+// validateNIK decodes the NIK, cross-checks it with the OCR fields and looks
+// up its region codes in the territory tables. The result is advisory and
+// only shown to reviewers.
+func (s *KYCService) validateNIK(ctx context.Context, ocr *domain.KTPOCRResult) *domain.NIKValidation {
+	validation := domain.ValidateNIK(ocr.NIK, domain.KTPOCRFields{
+		DateOfBirth: ocr.DateOfBirth,
+		Gender:      ocr.Gender,
+		AdministrativeCode: map[string]string{
+			"province": ocr.AdministrativeCode.Province,
+			"city":     ocr.AdministrativeCode.City,
+			"district": ocr.AdministrativeCode.District,
+		},
+	}, time.Now())
+	if validation.Info == nil || s.territoryRepo == nil {
+		return validation
+	}
+
+	info := validation.Info
+	province, err := s.territoryRepo.GetProvinceByCode(ctx, info.ProvinceCode)
+	if err == nil && province == nil {
+		validation.AddMismatch(domain.NIKFieldProvince, info.ProvinceCode, "", "Kode provinsi pada NIK tidak terdaftar")
+		return validation
+	}
+	city, cityErr := s.territoryRepo.GetCityByCode(ctx, info.CityCode)
+	if cityErr == nil && city == nil {
+		validation.AddMismatch(domain.NIKFieldCity, info.CityCode, "", "Kode kota/kabupaten pada NIK tidak terdaftar")
+		return validation
+	}
+	district, districtErr := s.territoryRepo.GetDistrictByCode(ctx, info.DistrictCode)
+	if districtErr == nil && district == nil {
+		validation.AddMismatch(domain.NIKFieldDistrict, info.DistrictCode, "", "Kode kecamatan pada NIK tidak terdaftar")
+	}
+
+	for _, lookupErr := range []error{err, cityErr, districtErr} {
+		if lookupErr != nil {
+			slog.Warn("failed to look up NIK region",
+				slog.String("district_code", info.DistrictCode),
+				slog.String("error", lookupErr.Error()),
+			)
+			break
+		}
+	}
+	return validation
+}
+
+// checkNIKDuplicate rejects a NIK already verified by another account and
+// records the attempt so it shows up in the admin KYC queue
+func (s *KYCService) checkNIKDuplicate(ctx context.Context, userID, nik string) error {
+	existing, err := s.kycRepo.FindVerificationByNIK(ctx, nik)
+	if err != nil {
+		return fmt.Errorf("failed to check NIK: %w", err)
+	}
+	if existing == nil || existing.UserID == userID {
+		return nil
+	}
+
+	if err := s.kycRepo.UpsertIdentityMatch(ctx, &domain.KYCIdentityMatch{
+		UserID:        userID,
+		MatchedUserID: existing.UserID,
+		MatchType:     domain.KYCMatchNIK,
+	}); err != nil {
+		slog.Warn("failed to record duplicate NIK",
+			slog.String("user_id", userID),
+			slog.String("matched_user_id", existing.UserID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return domain.NewError(domain.CodeKYCNIKAlreadyUsed, "NIK sudah terdaftar", 409)
+}
+
+// detectFaceDuplicates compares the selfie against verified selfies of other
+// accounts and records every match. The check is incomplete when candidates
+// cannot be loaded or a comparison fails or does not finish within the face
+// check timeout.
+func (s *KYCService) detectFaceDuplicates(ctx context.Context, userID, faceURL, gender string, dateOfBirth time.Time) faceDuplicateCheck {
+	if strings.HasPrefix(faceURL, "dummy://") {
+		return faceDuplicateCheck{complete: true}
+	}
+	if faceURL == "" {
+		return faceDuplicateCheck{}
+	}
+
+	candidates, err := s.kycRepo.FindFaceCandidates(ctx, userID, gender, dateOfBirth, time.Now().Add(-kycFaceLookback), kycFaceCandidateLimit)
+	if err != nil {
+		slog.Warn("failed to load face candidates",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+		return faceDuplicateCheck{}
+	}
+
+	compareCtx, cancel := context.WithTimeout(ctx, s.faceCheckTimeout)
+	defer cancel()
+	selfieURL := s.artefacts.ProviderURL(userID, domain.KYCArtefactFace, faceURL)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		check = faceDuplicateCheck{complete: true}
+	)
+	slots := make(chan struct{}, kycFaceCompareWorkers)
+	for _, candidate := range candidates {
+		select {
+		case slots <- struct{}{}:
+		case <-compareCtx.Done():
+		}
+		if compareCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(candidate *domain.KYCVerification) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			matched, compared := s.compareFaceCandidate(ctx, compareCtx, userID, selfieURL, candidate)
+			mu.Lock()
+			defer mu.Unlock()
+			if matched {
+				check.matches++
+			}
+			if !compared {
+				check.complete = false
+			}
+		}(candidate)
+	}
+	wg.Wait()
+
+	if compareCtx.Err() != nil {
+		check.complete = false
+		slog.Warn("face duplicate check ran out of time",
+			slog.String("user_id", userID),
+			slog.Int("candidates", len(candidates)),
+		)
+	}
+	return check
+}
+
+// compareFaceCandidate compares the selfie with one verified selfie and
+// records a match. It reports whether the faces matched and whether the
+// comparison was made at all. The comparison runs under compareCtx; the
+// match is recorded under ctx so a late result is not lost to the check's
+// deadline.
+func (s *KYCService) compareFaceCandidate(ctx, compareCtx context.Context, userID, selfieURL string, candidate *domain.KYCVerification) (bool, bool) {
+	result, err := s.faces.CompareFaces(compareCtx,
+		s.artefacts.ProviderURL(candidate.UserID, domain.KYCArtefactFace, stringPointerValue(candidate.FaceUrl)),
+		selfieURL,
+	)
+	if err != nil {
+		slog.Warn("failed to compare face with verified account",
+			slog.String("user_id", userID),
+			slog.String("candidate_user_id", candidate.UserID),
+			slog.String("error", err.Error()),
+		)
+		return false, false
+	}
+	if result.Similarity < domain.KYCDuplicateFaceThreshold {
+		return false, true
+	}
+
+	similarity := result.Similarity
+	if err := s.kycRepo.UpsertIdentityMatch(ctx, &domain.KYCIdentityMatch{
+		UserID:        userID,
+		MatchedUserID: candidate.UserID,
+		MatchType:     domain.KYCMatchFace,
+		Similarity:    &similarity,
+	}); err != nil {
+		slog.Warn("failed to record duplicate face",
+			slog.String("user_id", userID),
+			slog.String("matched_user_id", candidate.UserID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return true, true
+}
+
 // Helper function to mask NIK
 func maskNIK(nik string) string {
 	if len(nik) < 16 {
@@ -592,6 +975,26 @@ func (s *KYCService) faceSimilarity(session *domain.KYCSession) *float64 {
 	return &value
 }
 
+// ocrDataFromVerification rebuilds the session OCR data of a stored
+// verification, using the keys UploadKTP writes
+func ocrDataFromVerification(v *domain.KYCVerification) map[string]interface{} {
+	return map[string]interface{}{
+		"fullName":           v.FullName,
+		"placeOfBirth":       v.PlaceOfBirth,
+		"dateOfBirth":        v.DateOfBirth.Format("2006-01-02"),
+		"gender":             v.Gender,
+		"religion":           v.Religion,
+		"addressStreet":      v.AddressStreet,
+		"addressRt":          stringPointerValue(v.AddressRT),
+		"addressRw":          stringPointerValue(v.AddressRW),
+		"addressSubDistrict": v.AddressSubDistrict,
+		"addressDistrict":    v.AddressDistrict,
+		"addressCity":        v.AddressCity,
+		"addressProvince":    v.AddressProvince,
+		"administrativeCode": v.AdministrativeCode,
+	}
+}
+
 // SubmitForReview submits KYC session for final review/approval
 func (s *KYCService) SubmitForReview(ctx context.Context, userID string, sessionID string) error {
 	session, err := s.kycRepo.FindSessionByID(ctx, sessionID)
@@ -600,12 +1003,13 @@ func (s *KYCService) SubmitForReview(ctx context.Context, userID string, session
 	}
 	if session == nil {
 		// VerifyLiveness finalizes the verification and removes the working session.
-		// Treat submit as idempotent when the user is already verified.
+		// Treat submit as idempotent when the user is already verified, or
+		// pending because the verification is held for duplicate review.
 		user, userErr := s.userRepo.FindByID(ctx, userID)
 		if userErr != nil {
 			return fmt.Errorf("failed to get user: %w", userErr)
 		}
-		if user != nil && user.KYCStatus == domain.KYCStatusVerified {
+		if user != nil && (user.KYCStatus == domain.KYCStatusVerified || user.KYCStatus == domain.KYCStatusPending) {
 			verification, verifyErr := s.kycRepo.FindVerificationByUserID(ctx, userID)
 			if verifyErr != nil {
 				return fmt.Errorf("failed to get verification: %w", verifyErr)
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+)
+
+// fakeKYCRepo serves fixed verifications and records identity matches
+type fakeKYCRepo struct {
+	repository.KYCRepository
+	byNIK      map[string]*domain.KYCVerification
+	candidates []*domain.KYCVerification
+
+	mu      sync.Mutex
+	matches []*domain.KYCIdentityMatch
+}
+
+func (r *fakeKYCRepo) FindVerificationByNIK(ctx context.Context, nik string) (*domain.KYCVerification, error) {
+	return r.byNIK[nik], nil
+}
+
+func (r *fakeKYCRepo) FindFaceCandidates(ctx context.Context, excludeUserID, gender string, dateOfBirth, since time.Time, limit int) ([]*domain.KYCVerification, error) {
+	return r.candidates, nil
+}
+
+func (r *fakeKYCRepo) UpsertIdentityMatch(ctx context.Context, m *domain.KYCIdentityMatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.matches = append(r.matches, m)
+	return nil
+}
+
+// fakeFaceComparer answers a fixed similarity per verified face URL. A URL
+// without one fails; a negative similarity blocks until the call times out.
+type fakeFaceComparer struct {
+	similarity map[string]float64
+}
+
+func (f *fakeFaceComparer) CompareFaces(ctx context.Context, ktpFaceURL, selfieFaceURL string) (*domain.FaceCompareResult, error) {
+	similarity, ok := f.similarity[ktpFaceURL]
+	if !ok {
+		return nil, errors.New("face not detected")
+	}
+	if similarity < 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &domain.FaceCompareResult{Similarity: similarity}, nil
+}
+
+func faceCandidate(userID string) *domain.KYCVerification {
+	url := "https://cdn.example.com/kyc/" + userID + "/face.jpg"
+	return &domain.KYCVerification{UserID: userID, FaceUrl: &url}
+}
+
+func newFaceCheckService(repo *fakeKYCRepo, similarity map[string]float64) *KYCService {
+	return &KYCService{
+		kycRepo:          repo,
+		faces:            &fakeFaceComparer{similarity: similarity},
+		artefacts:        &KYCArtefactService{},
+		faceCheckTimeout: time.Second,
+	}
+}
+
+func TestCheckNIKDuplicateRecordsMatch(t *testing.T) {
+	repo := &fakeKYCRepo{byNIK: map[string]*domain.KYCVerification{
+		"3171012345670001": {UserID: "usr_verified", NIK: "3171012345670001"},
+	}}
+	svc := &KYCService{kycRepo: repo}
+
+	var appErr *domain.AppError
+	err := svc.checkNIKDuplicate(context.Background(), "usr_new", "3171012345670001")
+	if !errors.As(err, &appErr) || appErr.Code != domain.CodeKYCNIKAlreadyUsed {
+		t.Fatalf("checkNIKDuplicate = %v; want %s", err, domain.CodeKYCNIKAlreadyUsed)
+	}
+	if len(repo.matches) != 1 {
+		t.Fatalf("recorded %d identity matches; want 1", len(repo.matches))
+	}
+	if m := repo.matches[0]; m.UserID != "usr_new" || m.MatchedUserID != "usr_verified" || m.MatchType != domain.KYCMatchNIK {
+		t.Errorf("identity match = %+v; want usr_new matching usr_verified by NIK", m)
+	}
+
+	// The owner of the NIK verifying again is not a duplicate
+	if err := svc.checkNIKDuplicate(context.Background(), "usr_verified", "3171012345670001"); err != nil {
+		t.Errorf("checkNIKDuplicate for the owner = %v; want nil", err)
+	}
+	if len(repo.matches) != 1 {
+		t.Error("owner recorded an identity match")
+	}
+}
+
+func TestDetectFaceDuplicates(t *testing.T) {
+	twin, stranger := faceCandidate("usr_twin"), faceCandidate("usr_stranger")
+	dateOfBirth := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		similarity map[string]float64
+		matches    int
+		complete   bool
+		review     bool
+	}{
+		{
+			name:       "no match",
+			similarity: map[string]float64{*twin.FaceUrl: 40, *stranger.FaceUrl: 12},
+			complete:   true,
+		},
+		{
+			name:       "match above threshold",
+			similarity: map[string]float64{*twin.FaceUrl: domain.KYCDuplicateFaceThreshold + 5, *stranger.FaceUrl: 12},
+			matches:    1,
+			complete:   true,
+			review:     true,
+		},
+		{
+			name:       "comparison failed",
+			similarity: map[string]float64{*stranger.FaceUrl: 12},
+			review:     true,
+		},
+		{
+			name:       "comparison timed out",
+			similarity: map[string]float64{*twin.FaceUrl: -1, *stranger.FaceUrl: 12},
+			review:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeKYCRepo{candidates: []*domain.KYCVerification{twin, stranger}}
+			svc := newFaceCheckService(repo, tt.similarity)
+			svc.faceCheckTimeout = 50 * time.Millisecond
+
+			check := svc.detectFaceDuplicates(context.Background(), "usr_new", "https://cdn.example.com/kyc/usr_new/face.jpg", domain.GenderMale, dateOfBirth)
+			if check.matches != tt.matches || check.complete != tt.complete {
+				t.Errorf("check = %+v; want %d matches, complete %v", check, tt.matches, tt.complete)
+			}
+			if check.reviewRequired() != tt.review {
+				t.Errorf("reviewRequired = %v; want %v", check.reviewRequired(), tt.review)
+			}
+			if len(repo.matches) != tt.matches {
+				t.Errorf("recorded %d identity matches; want %d", len(repo.matches), tt.matches)
+			}
+			for _, m := range repo.matches {
+				if m.MatchedUserID != "usr_twin" || m.MatchType != domain.KYCMatchFace {
+					t.Errorf("identity match = %+v; want face match with usr_twin", m)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
+)
+
+// maintenanceLocalTTL bounds how long an instance keeps selling after a
+// window is opened on another instance
+const maintenanceLocalTTL = 30 * time.Second
+
+// MaintenanceService keeps the registry of maintenance windows that block
+// sales of a service type, an operator or a transfer bank
+type MaintenanceService struct {
+	repo        repository.MaintenanceRepository
+	redisClient *redis.Client
+
+	mu       sync.RWMutex
+	windows  []*domain.MaintenanceWindow
+	loadedAt time.Time
+}
+
+// NewMaintenanceService creates a new maintenance service
+func NewMaintenanceService(repo repository.MaintenanceRepository, redisClient *redis.Client) *MaintenanceService {
+	return &MaintenanceService{
+		repo:        repo,
+		redisClient: redisClient,
+	}
+}
+
+// MaintenanceInput is the admin-editable part of a maintenance window
+type MaintenanceInput struct {
+	Scope    string     `json:"scope"`
+	Target   string     `json:"target"`
+	Message  string     `json:"message"`
+	StartsAt *time.Time `json:"startsAt"` // Defaults to now
+	EndsAt   *time.Time `json:"endsAt"`   // Expected end, shown as ETA; empty until ended manually
+	IsActive *bool      `json:"isActive"`
+}
+
+// Check returns the window currently blocking any of the targets, or nil.
+// A registry that cannot be loaded never blocks sales.
+func (s *MaintenanceService) Check(ctx context.Context, targets ...domain.MaintenanceTarget) *domain.MaintenanceWindow {
+	if s == nil {
+		return nil
+	}
+	windows, err := s.current(ctx)
+	if err != nil {
+		slog.Warn("failed to load maintenance windows", slog.String("error", err.Error()))
+		return nil
+	}
+	return domain.MatchMaintenance(windows, time.Now(), targets...)
+}
+
+// Guard returns a SERVICE_MAINTENANCE error while any of the targets is under maintenance
+func (s *MaintenanceService) Guard(ctx context.Context, targets ...domain.MaintenanceTarget) error {
+	if window := s.Check(ctx, targets...); window != nil {
+		return domain.ErrServiceMaintenance(window.Message)
+	}
+	return nil
+}
+
+// ServiceWindows returns the window in effect for each home tile, keyed by tile ID
+func (s *MaintenanceService) ServiceWindows(ctx context.Context) map[string]*domain.MaintenanceWindow {
+	if s == nil {
+		return nil
+	}
+	windows, err := s.current(ctx)
+	if err != nil {
+		slog.Warn("failed to load maintenance windows", slog.String("error", err.Error()))
+		return nil
+	}
+
+	now := time.Now()
+	tiles := make(map[string]*domain.MaintenanceWindow)
+	for serviceType, tileID := range domain.MaintenanceServiceTiles {
+		target := domain.MaintenanceTarget{Scope: domain.MaintenanceScopeService, Target: serviceType}
+		if window := domain.MatchMaintenance(windows, now, target); window != nil {
+			tiles[tileID] = window
+		}
+	}
+	return tiles
+}
+
+// List returns maintenance windows for the admin console
+func (s *MaintenanceService) List(ctx context.Context, includeEnded bool) ([]*domain.MaintenanceWindow, error) {
+	windows, err := s.repo.FindAll(ctx, includeEnded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return windows, nil
+}
+
+// Create opens a maintenance window
+func (s *MaintenanceService) Create(ctx context.Context, actorID string, input MaintenanceInput) (*domain.MaintenanceWindow, error) {
+	window := &domain.MaintenanceWindow{IsActive: true}
+	if actorID != "" {
+		window.CreatedBy = sql.NullString{String: actorID, Valid: true}
+	}
+	if err := applyMaintenanceInput(window, input, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, window); err != nil {
+		return nil, fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+	s.Refresh(ctx)
+	return window, nil
+}
+
+// Update changes a maintenance window, e.g. to move its ETA
+func (s *MaintenanceService) Update(ctx context.Context, id string, input MaintenanceInput) (*domain.MaintenanceWindow, *domain.MaintenanceWindow, error) {
+	existing, err := s.find(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated := *existing
+	if err := applyMaintenanceInput(&updated, input, existing.StartsAt); err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.Update(ctx, &updated); err != nil {
+		return nil, nil, fmt.Errorf("failed to update maintenance window: %w", err)
+	}
+	s.Refresh(ctx)
+	return existing, &updated, nil
+}
+
+// End closes a maintenance window now
+func (s *MaintenanceService) End(ctx context.Context, id string) (*domain.MaintenanceWindow, error) {
+	window, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !window.IsActive || (window.EndsAt.Valid && !window.EndsAt.Time.After(now)) {
+		return nil, domain.NewError("MAINTENANCE_ENDED", "Maintenance sudah berakhir", 409)
+	}
+	window.IsActive = false
+	if window.StartsAt.Before(now) {
+		// Record when a started window actually ended; one ended before it started keeps its planned times
+		window.EndsAt = sql.NullTime{Time: now, Valid: true}
+	}
+	if err := s.repo.Update(ctx, window); err != nil {
+		return nil, fmt.Errorf("failed to end maintenance window: %w", err)
+	}
+	s.Refresh(ctx)
+	return window, nil
+}
+
+// Refresh reloads the registry and drops the cached home tiles so their
+// status follows the change
+func (s *MaintenanceService) Refresh(ctx context.Context) {
+	windows, err := s.repo.FindCurrent(ctx, time.Now())
+	if err != nil {
+		// A nil registry makes the next check load from the database
+		slog.Warn("failed to reload maintenance windows", slog.String("error", err.Error()))
+		windows = nil
+	}
+	s.store(windows)
+	if s.redisClient != nil {
+		s.redisClient.Del(ctx, redis.HomeServicesKey())
+	}
+}
+
+func (s *MaintenanceService) find(ctx context.Context, id string) (*domain.MaintenanceWindow, error) {
+	window, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+	if window == nil {
+		return nil, domain.ErrNotFound("Maintenance")
+	}
+	return window, nil
+}
+
+// current returns the registry, reusing the in-memory copy while fresh
+func (s *MaintenanceService) current(ctx context.Context) ([]*domain.MaintenanceWindow, error) {
+	s.mu.RLock()
+	windows, loadedAt := s.windows, s.loadedAt
+	s.mu.RUnlock()
+	if windows != nil && time.Since(loadedAt) < maintenanceLocalTTL {
+		return windows, nil
+	}
+
+	windows, err := s.repo.FindCurrent(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.store(windows)
+	return windows, nil
+}
+
+func (s *MaintenanceService) store(windows []*domain.MaintenanceWindow) {
+	s.mu.Lock()
+	s.windows = windows
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// applyMaintenanceInput validates input onto window. defaultStart is used
+// when the input does not set a start time.
+func applyMaintenanceInput(window *domain.MaintenanceWindow, input MaintenanceInput, defaultStart time.Time) error {
+	scope := strings.ToLower(strings.TrimSpace(input.Scope))
+	if scope == "" {
+		scope = window.Scope
+	}
+	target := strings.TrimSpace(input.Target)
+	if target == "" {
+		target = window.Target
+	}
+	message := strings.TrimSpace(input.Message)
+	if message == "" {
+		message = window.Message
+	}
+
+	switch scope {
+	case domain.MaintenanceScopeService:
+		target = strings.ToLower(target)
+		if _, ok := domain.MaintenanceServiceTiles[target]; !ok {
+			return domain.ErrValidationFailed("Jenis layanan tidak dikenal")
+		}
+	case domain.MaintenanceScopeOperator:
+	case domain.MaintenanceScopeBank:
+		target = strings.ToUpper(target)
+	default:
+		return domain.ErrValidationFailed("Scope harus service, operator, atau bank")
+	}
+	if target == "" {
+		return domain.ErrValidationFailed("Target wajib diisi")
+	}
+	if message == "" {
+		return domain.ErrValidationFailed("Pesan maintenance wajib diisi")
+	}
+
+	startsAt := defaultStart
+	if input.StartsAt != nil {
+		startsAt = *input.StartsAt
+	}
+	endsAt := window.EndsAt
+	if input.EndsAt != nil {
+		endsAt = sql.NullTime{Time: *input.EndsAt, Valid: true}
+	}
+	if endsAt.Valid && !endsAt.Time.After(startsAt) {
+		return domain.ErrValidationFailed("Waktu selesai harus setelah waktu mulai")
+	}
+
+	window.Scope = scope
+	window.Target = target
+	window.Message = message
+	window.StartsAt = startsAt
+	window.EndsAt = endsAt
+	if input.IsActive != nil {
+		window.IsActive = *input.IsActive
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// operatorTrieCacheTTL is how long the serialized trie lives in Redis
+	operatorTrieCacheTTL = time.Hour
+	// operatorTrieLocalTTL is how long an instance reuses its in-memory trie,
+	// bounding how stale other instances are after an admin change
+	operatorTrieLocalTTL = time.Minute
+)
+
+var operatorIDPattern = regexp.MustCompile(`^[a-z0-9_]{2,36}$`)
+
+// OperatorService resolves phone numbers to mobile operators using the
+// prefixes stored in the operators table, and manages those prefixes
+type OperatorService struct {
+	productRepo repository.ProductRepository
+	adminRepo   *repository.AdminRepository
+	redisClient *redis.Client
+
+	mu       sync.RWMutex
+	trie     *operatorTrie
+	loadedAt time.Time
+}
+
+// NewOperatorService creates a new operator service
+func NewOperatorService(
+	productRepo repository.ProductRepository,
+	adminRepo *repository.AdminRepository,
+	redisClient *redis.Client,
+) *OperatorService {
+	return &OperatorService{
+		productRepo: productRepo,
+		adminRepo:   adminRepo,
+		redisClient: redisClient,
+	}
+}
+
+// OperatorInput represents admin input for creating or updating an operator
+type OperatorInput struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Prefixes  []string `json:"prefixes"`
+	Icon      string   `json:"icon"`
+	IconURL   string   `json:"iconUrl"`
+	Status    string   `json:"status"`
+	SortOrder int      `json:"sortOrder"`
+}
+
+// Detect resolves a phone number to its operator by longest prefix match
+func (s *OperatorService) Detect(ctx context.Context, msisdn string) (*domain.OperatorDetection, error) {
+	trie, err := s.loadTrie(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	detection := &domain.OperatorDetection{Candidates: []*domain.Operator{}}
+	match := trie.match(msisdn)
+	if match == nil {
+		return detection, nil
+	}
+
+	detection.MatchedPrefix = match.Prefix
+	detection.Candidates = match.Operators
+	detection.Operator = match.Operators[0]
+	detection.Confidence = 1 / float64(len(match.Operators))
+	return detection, nil
+}
+
+// FindActive returns an active operator by ID from the cached operator set
+func (s *OperatorService) FindActive(ctx context.Context, operatorID string) (*domain.Operator, error) {
+	trie, err := s.loadTrie(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return trie.Operators[operatorID], nil
+}
+
+// List returns all operators for the admin console
+func (s *OperatorService) List(ctx context.Context) ([]*domain.Operator, error) {
+	operators, err := s.productRepo.ListOperators(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list operators: %w", err)
+	}
+	return operators, nil
+}
+
+// Create adds a new operator
+func (s *OperatorService) Create(ctx context.Context, actorID string, input OperatorInput) (*domain.Operator, error) {
+	input.ID = strings.ToLower(strings.TrimSpace(input.ID))
+	if !operatorIDPattern.MatchString(input.ID) {
+		return nil, domain.ErrValidationFailed("ID operator hanya boleh huruf kecil, angka, dan underscore")
+	}
+
+	existing, err := s.productRepo.FindOperatorByID(ctx, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator: %w", err)
+	}
+	if existing != nil {
+		return nil, domain.ErrValidationFailed("ID operator sudah digunakan")
+	}
+
+	operator, err := buildOperator(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.productRepo.CreateOperator(ctx, operator); err != nil {
+		return nil, fmt.Errorf("failed to create operator: %w", err)
+	}
+
+	s.invalidate(ctx)
+	s.audit(ctx, actorID, "operator.create", operator.ID, nil, operator)
+	return operator, nil
+}
+
+// Update replaces an operator's attributes and prefixes
+func (s *OperatorService) Update(ctx context.Context, actorID, operatorID string, input OperatorInput) (*domain.Operator, error) {
+	existing, err := s.productRepo.FindOperatorByID(ctx, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator: %w", err)
+	}
+	if existing == nil {
+		return nil, domain.ErrNotFound("Operator")
+	}
+
+	input.ID = existing.ID
+	operator, err := buildOperator(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.productRepo.UpdateOperator(ctx, operator); err != nil {
+		return nil, fmt.Errorf("failed to update operator: %w", err)
+	}
+
+	s.invalidate(ctx)
+	s.audit(ctx, actorID, "operator.update", operator.ID, existing, operator)
+	return operator, nil
+}
+
+// Delete removes an operator and its prefixes
+func (s *OperatorService) Delete(ctx context.Context, actorID, operatorID string) error {
+	existing, err := s.productRepo.FindOperatorByID(ctx, operatorID)
+	if err != nil {
+		return fmt.Errorf("failed to get operator: %w", err)
+	}
+	if existing == nil {
+		return domain.ErrNotFound("Operator")
+	}
+
+	if _, err := s.productRepo.DeleteOperator(ctx, operatorID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return domain.ErrValidationFailed("Operator masih digunakan produk, ubah status menjadi inactive")
+		}
+		return fmt.Errorf("failed to delete operator: %w", err)
+	}
+
+	s.invalidate(ctx)
+	s.audit(ctx, actorID, "operator.delete", operatorID, existing, nil)
+	return nil
+}
+
+// loadTrie returns the prefix trie, preferring the in-memory copy, then Redis,
+// then rebuilding it from the database
+func (s *OperatorService) loadTrie(ctx context.Context) (*operatorTrie, error) {
+	s.mu.RLock()
+	trie, loadedAt := s.trie, s.loadedAt
+	s.mu.RUnlock()
+	if trie != nil && time.Since(loadedAt) < operatorTrieLocalTTL {
+		return trie, nil
+	}
+
+	cacheKey := redis.OperatorPrefixTrieKey()
+	if s.redisClient != nil {
+		var cached operatorTrie
+		if err := s.redisClient.GetJSON(ctx, cacheKey, &cached); err == nil && cached.Root != nil {
+			s.store(&cached)
+			return &cached, nil
+		}
+	}
+
+	operators, err := s.productRepo.FindAllOperators(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load operators: %w", err)
+	}
+	trie = newOperatorTrie(operators)
+
+	if s.redisClient != nil {
+		if err := s.redisClient.SetJSON(ctx, cacheKey, trie, operatorTrieCacheTTL); err != nil {
+			slog.Warn("failed to cache operator prefix trie", slog.String("error", err.Error()))
+		}
+	}
+	s.store(trie)
+	return trie, nil
+}
+
+func (s *OperatorService) store(trie *operatorTrie) {
+	s.mu.Lock()
+	s.trie = trie
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// invalidate drops the cached trie and operator list so the next lookup rebuilds them
+func (s *OperatorService) invalidate(ctx context.Context) {
+	s.mu.Lock()
+	s.trie = nil
+	s.mu.Unlock()
+
+	if s.redisClient != nil {
+		if err := s.redisClient.Del(ctx, redis.OperatorPrefixTrieKey(), redis.ProductListKey("operators")).Err(); err != nil {
+			slog.Warn("failed to invalidate operator prefix trie", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (s *OperatorService) audit(ctx context.Context, actorID, action, operatorID string, oldValue, newValue interface{}) {
+	if s.adminRepo == nil {
+		return
+	}
+	_ = s.adminRepo.CreateAuditLog(ctx, &domain.AdminAuditLog{
+		ID:           "aal_" + uuid.New().String()[:8],
+		AdminUserID:  sql.NullString{String: actorID, Valid: actorID != ""},
+		Action:       action,
+		ResourceType: sql.NullString{String: "operator", Valid: true},
+		ResourceID:   sql.NullString{String: operatorID, Valid: true},
+		OldValue:     oldValue,
+		NewValue:     newValue,
+		Status:       "success",
+		CreatedAt:    time.Now(),
+	})
+}
+
+// buildOperator validates admin input and normalizes its prefixes
+func buildOperator(input OperatorInput) (*domain.Operator, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 50 {
+		return nil, domain.ErrValidationFailed("Nama operator wajib diisi (maksimal 50 karakter)")
+	}
+
+	status := strings.TrimSpace(input.Status)
+	if status == "" {
+		status = "active"
+	}
+	if status != "active" && status != "maintenance" && status != "inactive" {
+		return nil, domain.ErrValidationFailed("Status operator harus active, maintenance, atau inactive")
+	}
+
+	seen := make(map[string]bool, len(input.Prefixes))
+	prefixes := make([]string, 0, len(input.Prefixes))
+	for _, raw := range input.Prefixes {
+		prefix := NormalizeMSISDN(raw)
+		if !strings.HasPrefix(prefix, "08") || len(prefix) < 4 || len(prefix) > 8 {
+			return nil, domain.ErrValidationFailed(fmt.Sprintf("Prefix %q tidak valid, gunakan format 08xx (4-8 digit)", raw))
+		}
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return nil, domain.ErrValidationFailed("Minimal satu prefix wajib diisi")
+	}
+	sort.Strings(prefixes)
+
+	icon := strings.TrimSpace(input.Icon)
+	if icon == "" {
+		icon = input.ID
+	}
+
+	return &domain.Operator{
+		ID:        input.ID,
+		Name:      name,
+		Prefixes:  prefixes,
+		Icon:      icon,
+		IconURL:   strings.TrimSpace(input.IconURL),
+		Status:    status,
+		SortOrder: input.SortOrder,
+	}, nil
+}
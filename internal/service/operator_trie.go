@@ -0,0 +1,130 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+// operatorTrieNode is a digit trie node; OperatorIDs is set on nodes that end
+// a configured prefix. More than one ID means the range is shared, in which
+// case IDs keep the operators' sort order so the preferred one comes first.
+type operatorTrieNode struct {
+	Children    map[string]*operatorTrieNode `json:"c,omitempty"`
+	OperatorIDs []string                     `json:"o,omitempty"`
+}
+
+// operatorTrie resolves normalized MSISDNs to operators by longest prefix.
+// It is JSON-serializable so a built trie can be shared through Redis.
+type operatorTrie struct {
+	Root      *operatorTrieNode           `json:"root"`
+	Operators map[string]*domain.Operator `json:"operators"`
+}
+
+// operatorMatch is the result of a trie lookup
+type operatorMatch struct {
+	Prefix    string
+	Operators []*domain.Operator
+}
+
+// newOperatorTrie builds a trie from the prefixes of the given operators.
+// Prefixes are normalized, so "62811", "+62811" and "0811" are equivalent.
+func newOperatorTrie(operators []*domain.Operator) *operatorTrie {
+	t := &operatorTrie{
+		Root:      &operatorTrieNode{},
+		Operators: make(map[string]*domain.Operator, len(operators)),
+	}
+	for _, op := range operators {
+		t.Operators[op.ID] = op
+		for _, prefix := range op.Prefixes {
+			t.insert(NormalizeMSISDN(prefix), op.ID)
+		}
+	}
+	return t
+}
+
+func (t *operatorTrie) insert(prefix, operatorID string) {
+	if prefix == "" {
+		return
+	}
+	node := t.Root
+	for _, digit := range prefix {
+		key := string(digit)
+		if node.Children == nil {
+			node.Children = make(map[string]*operatorTrieNode)
+		}
+		child, ok := node.Children[key]
+		if !ok {
+			child = &operatorTrieNode{}
+			node.Children[key] = child
+		}
+		node = child
+	}
+	for _, id := range node.OperatorIDs {
+		if id == operatorID {
+			return
+		}
+	}
+	node.OperatorIDs = append(node.OperatorIDs, operatorID)
+}
+
+// match returns the operators registered on the longest prefix of msisdn,
+// or nil when no configured prefix matches
+func (t *operatorTrie) match(msisdn string) *operatorMatch {
+	msisdn = NormalizeMSISDN(msisdn)
+	if t == nil || t.Root == nil || msisdn == "" {
+		return nil
+	}
+
+	var best *operatorTrieNode
+	bestLen := 0
+	node := t.Root
+	for i, digit := range msisdn {
+		child, ok := node.Children[string(digit)]
+		if !ok {
+			break
+		}
+		node = child
+		if len(node.OperatorIDs) > 0 {
+			best = node
+			bestLen = i + 1
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	result := &operatorMatch{Prefix: msisdn[:bestLen]}
+	for _, id := range best.OperatorIDs {
+		if op, ok := t.Operators[id]; ok {
+			result.Operators = append(result.Operators, op)
+		}
+	}
+	if len(result.Operators) == 0 {
+		return nil
+	}
+	return result
+}
+
+// NormalizeMSISDN converts an Indonesian mobile number or prefix to the
+// national "08..." form: separators are stripped and the +62, 62 and bare
+// 8 prefixes are rewritten. Returns "" when the input has non-digit characters.
+func NormalizeMSISDN(msisdn string) string {
+	msisdn = strings.TrimSpace(msisdn)
+	msisdn = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(msisdn)
+	msisdn = strings.TrimPrefix(msisdn, "+")
+
+	for _, c := range msisdn {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(msisdn, "62"):
+		msisdn = "0" + msisdn[2:]
+	case strings.HasPrefix(msisdn, "8"):
+		msisdn = "0" + msisdn
+	}
+	return msisdn
+}
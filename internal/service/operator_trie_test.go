@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+)
+
+func testOperators() []*domain.Operator {
+	return []*domain.Operator{
+		{ID: "telkomsel", Name: "Telkomsel", Prefixes: []string{"0811", "0812", "0851"}},
+		{ID: "byu", Name: "by.U", Prefixes: []string{"0851"}},
+		{ID: "special", Name: "Special", Prefixes: []string{"081299"}},
+	}
+}
+
+func TestOperatorTrieLongestPrefix(t *testing.T) {
+	trie := newOperatorTrie(testOperators())
+
+	cases := []struct {
+		msisdn    string
+		prefix    string
+		operators []string
+	}{
+		{"081234567890", "0812", []string{"telkomsel"}},
+		{"+6281299123456", "081299", []string{"special"}},
+		{"6281112345678", "0811", []string{"telkomsel"}},
+		{"85112345678", "0851", []string{"telkomsel", "byu"}},
+		{"0812-3456-7890", "0812", []string{"telkomsel"}},
+	}
+	for _, tc := range cases {
+		match := trie.match(tc.msisdn)
+		if match == nil {
+			t.Fatalf("match(%q) = nil", tc.msisdn)
+		}
+		if match.Prefix != tc.prefix {
+			t.Errorf("match(%q) prefix = %q; want %q", tc.msisdn, match.Prefix, tc.prefix)
+		}
+		if len(match.Operators) != len(tc.operators) {
+			t.Fatalf("match(%q) returned %d operators; want %d", tc.msisdn, len(match.Operators), len(tc.operators))
+		}
+		for i, id := range tc.operators {
+			if match.Operators[i].ID != id {
+				t.Errorf("match(%q) operator %d = %q; want %q", tc.msisdn, i, match.Operators[i].ID, id)
+			}
+		}
+	}
+
+	for _, msisdn := range []string{"0899123", "0812abc", ""} {
+		if match := trie.match(msisdn); match != nil {
+			t.Errorf("match(%q) = %+v; want nil", msisdn, match)
+		}
+	}
+}
+
+func TestOperatorTrieJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(newOperatorTrie(testOperators()))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var trie operatorTrie
+	if err := json.Unmarshal(data, &trie); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	match := trie.match("081299000")
+	if match == nil || match.Operators[0].ID != "special" {
+		t.Fatalf("match after round trip = %+v; want special", match)
+	}
+}
+
+func TestNormalizeMSISDN(t *testing.T) {
+	cases := map[string]string{
+		"+62 812-3456-7890": "081234567890",
+		"6281234567890":     "081234567890",
+		"81234567890":       "081234567890",
+		"081234567890":      "081234567890",
+		"0812a":             "",
+	}
+	for in, want := range cases {
+		if got := NormalizeMSISDN(in); got != want {
+			t.Errorf("NormalizeMSISDN(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
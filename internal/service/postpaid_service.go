@@ -9,7 +9,9 @@ import (
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
 	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
+	"github.com/GTDGit/PPOB_BE/internal/external/supplier"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/jmoiron/sqlx"
 )
 
 // PostpaidService handles postpaid business logic
@@ -19,9 +21,13 @@ type PostpaidService struct {
 	refundRepo    repository.RefundRepository
 	voucherRepo   repository.VoucherRepository
 	userRepo      repository.UserRepository
-	productRepo   repository.ProductRepository
-	gerbangClient *gerbang.Client
-	allowDummy    bool
+	productRepo    repository.ProductRepository
+	contactService  *ContactService
+	supplierService *SupplierService
+	reservationService *ReservationService
+	maintenance     *MaintenanceService
+	limits          *AccountLimitService
+	allowDummy      bool
 }
 
 // NewPostpaidService creates a new postpaid service
@@ -32,18 +38,26 @@ func NewPostpaidService(
 	voucherRepo repository.VoucherRepository,
 	userRepo repository.UserRepository,
 	productRepo repository.ProductRepository,
-	gerbangClient *gerbang.Client,
+	contactService *ContactService,
+	supplierService *SupplierService,
+	reservationService *ReservationService,
+	maintenance *MaintenanceService,
+	limits *AccountLimitService,
 	allowDummy bool,
 ) *PostpaidService {
 	return &PostpaidService{
-		postpaidRepo:  postpaidRepo,
-		balanceRepo:   balanceRepo,
-		refundRepo:    refundRepo,
-		voucherRepo:   voucherRepo,
-		userRepo:      userRepo,
-		productRepo:   productRepo,
-		gerbangClient: gerbangClient,
-		allowDummy:    allowDummy,
+		postpaidRepo:   postpaidRepo,
+		balanceRepo:    balanceRepo,
+		refundRepo:     refundRepo,
+		voucherRepo:    voucherRepo,
+		userRepo:       userRepo,
+		productRepo:    productRepo,
+		contactService: contactService,
+		supplierService: supplierService,
+		reservationService: reservationService,
+		maintenance:     maintenance,
+		limits:          limits,
+		allowDummy:     allowDummy,
 	}
 }
 
@@ -59,14 +73,28 @@ func (s *PostpaidService) Inquiry(ctx context.Context, userID, serviceType, targ
 		return nil, err
 	}
 
-	// Call Gerbang API for inquiry
+	// Ask the suppliers for the bill
 	inquiryID := repository.NewUUID()
 	product, err := s.findPostpaidProduct(ctx, serviceType)
 	if err != nil {
 		return nil, err
 	}
 
-	gerbangResp, err := s.gerbangClient.CreateInquiry(ctx, inquiryID, product.SKUCode, target)
+	// Under maintenance: show the notice without asking the supplier
+	if window := s.maintenance.Check(ctx, postpaidMaintenanceTargets(serviceType, providerID, product)...); window != nil {
+		return &domain.PostpaidInquiryResponse{
+			Inquiry: &domain.PostpaidInquiryInfo{
+				ServiceType: serviceType,
+				Target:      target,
+				TargetValid: true,
+			},
+			PinRequired: true,
+			Notices:     []*domain.NoticeInfo{window.Notice()},
+			Message:     &window.Message,
+		}, nil
+	}
+
+	supplierResp, err := s.supplierService.Inquiry(ctx, product, inquiryID, target)
 
 	var inquiry *domain.PostpaidInquiry
 	var customerName string
@@ -78,10 +106,12 @@ func (s *PostpaidService) Inquiry(ctx context.Context, userID, serviceType, targ
 
 	if err != nil {
 		// Check if it's a "no bill" error
-		if gerbangErr, ok := err.(*gerbang.Error); ok && gerbangErr.Code == 404 {
+		if supplier.IsCode(err, supplier.CodeNotFound) {
 			hasBill = false
 			customerName = ""
 			inquiryPeriod = defaultPostpaidPeriod(period)
+		} else if !s.allowDummy && supplier.IsCode(err, supplier.CodeUnavailable) {
+			return nil, domain.ErrProviderUnavailable
 		} else if s.allowDummy {
 			slog.Warn("falling back to dummy postpaid inquiry",
 				slog.String("service_type", serviceType),
@@ -99,16 +129,16 @@ func (s *PostpaidService) Inquiry(ctx context.Context, userID, serviceType, targ
 			return nil, fmt.Errorf("provider inquiry failed: %w", err)
 		}
 	} else {
-		// Use real Gerbang response
+		// Use real supplier response
 		hasBill = true
-		externalID = gerbangResp.TransactionID
-		customerName = gerbangResp.CustomerName
-		billAmount = gerbangResp.Amount
+		externalID = supplierResp.TransactionID
+		customerName = supplierResp.CustomerName
+		billAmount = supplierResp.Amount
 		if billAmount == 0 {
-			billAmount = gerbangResp.Price
+			billAmount = supplierResp.Price
 		}
-		adminFee = gerbangResp.Admin
-		inquiryPeriod = gerbangResp.Period
+		adminFee = supplierResp.Admin
+		inquiryPeriod = supplierResp.Period
 	}
 
 	// Create inquiry record
@@ -130,8 +160,11 @@ func (s *PostpaidService) Inquiry(ctx context.Context, userID, serviceType, targ
 		CreatedAt:    time.Now(),
 	}
 
-	if gerbangResp != nil && gerbangResp.TotalAmount > 0 {
-		inquiry.TotalPayment = gerbangResp.TotalAmount
+	if supplierResp != nil && supplierResp.TotalAmount > 0 {
+		inquiry.TotalPayment = supplierResp.TotalAmount
+	}
+	if supplierResp != nil && supplierResp.Supplier != "" {
+		inquiry.SupplierCode = &supplierResp.Supplier
 	}
 	if inquiry.Period == "" {
 		inquiry.Period = defaultPostpaidPeriod(period)
@@ -158,7 +191,7 @@ func (s *PostpaidService) Inquiry(ctx context.Context, userID, serviceType, targ
 	// Build response
 	response := &domain.PostpaidInquiryResponse{
 		PinRequired: true,
-		Notices:     []string{},
+		Notices:     []*domain.NoticeInfo{},
 	}
 
 	// Inquiry info
@@ -235,11 +268,15 @@ func (s *PostpaidService) Inquiry(ctx context.Context, userID, serviceType, targ
 		}
 	}
 
+	if s.contactService != nil {
+		response.Suggestions = s.contactService.SuggestionsFor(ctx, userID, serviceType)
+	}
+
 	return response, nil
 }
 
 // Pay processes bill payment
-func (s *PostpaidService) Pay(ctx context.Context, userID, inquiryID string, voucherCodes []string, pin *string) (*domain.PostpaidPayResponse, error) {
+func (s *PostpaidService) Pay(ctx context.Context, userID, inquiryID string, voucherCodes []string, pin *string, saveContact *ContactSaveRequest) (*domain.PostpaidPayResponse, error) {
 	// Get inquiry with ownership check
 	inquiry, err := s.postpaidRepo.FindInquiryByUserAndID(ctx, userID, inquiryID)
 	if err != nil {
@@ -290,43 +327,21 @@ func (s *PostpaidService) Pay(ctx context.Context, userID, inquiryID string, vou
 	//     totalPayment -= discount
 	// }
 
-	// Begin database transaction for atomic operation
-	tx, err := s.postpaidRepo.BeginTx(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Lock user balance (SELECT FOR UPDATE)
-	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to lock balance: %w", err)
-	}
-
-	// Check sufficient balance
-	if balance.Amount < totalPayment {
-		return nil, domain.ErrInsufficientBalance
+	if err := s.maintenance.Guard(ctx, postpaidMaintenanceTargets(inquiry.ServiceType, inquiry.ProviderID, nil)...); err != nil {
+		return nil, err
 	}
 
-	// Deduct balance
-	balanceBefore := balance.Amount
-	balance.Amount -= totalPayment
-	balanceAfter := balance.Amount
-	balance.UpdatedAt = time.Now()
-
-	// Update balance in transaction
-	if err := s.balanceRepo.UpdateWithTx(ctx, tx, balance); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+	// Fail fast while the inquiry's supplier is unreachable instead of reserving the balance
+	if !s.allowDummy && inquiry.SupplierCode != nil && !s.supplierService.SupplierAvailable(*inquiry.SupplierCode) {
+		return nil, domain.ErrProviderUnavailable
 	}
 
-	// Call Gerbang API to pay bill
-	transactionID := repository.NewUUID()
 	product, err := s.findPostpaidProduct(ctx, inquiry.ServiceType)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get inquiry external ID for payment (from Gerbang inquiry response)
+	// Get inquiry external ID for payment (from the supplier inquiry response)
 	var inquiryExternalID string
 	if inquiry.ExternalID != nil {
 		inquiryExternalID = *inquiry.ExternalID
@@ -334,67 +349,40 @@ func (s *PostpaidService) Pay(ctx context.Context, userID, inquiryID string, vou
 		return nil, domain.ErrServiceUnavailable
 	}
 
-	gerbangResp, err := s.gerbangClient.CreatePostpaidPayment(ctx, transactionID, inquiryExternalID, product.SKUCode, inquiry.Target)
-
-	var externalID string
-	var serialNumber *string
-	var status string
+	var supplierCode string
+	if inquiry.SupplierCode != nil {
+		supplierCode = *inquiry.SupplierCode
+	}
+	transactionID := repository.NewUUID()
 
+	// Reserve the payment and record the transaction as processing, then
+	// commit so no balance lock is held while the supplier is called
+	tx, err := s.postpaidRepo.BeginTx(ctx)
 	if err != nil {
-		if !s.allowDummy {
-			return nil, fmt.Errorf("provider call failed: %w", err)
-		}
-
-		slog.Warn("falling back to dummy postpaid payment",
-			slog.String("transaction_id", transactionID),
-			slog.String("inquiry_id", inquiryID),
-			slog.String("service_type", inquiry.ServiceType),
-			slog.String("error", err.Error()),
-		)
-
-		gerbangResp = &gerbang.TransactionResponse{
-			TransactionID: buildDummyReference("POST"),
-			ReferenceID:   transactionID,
-			SKUCode:       product.SKUCode,
-			CustomerNo:    inquiry.Target,
-			CustomerName:  inquiry.CustomerName,
-			Type:          "payment",
-			Status:        gerbang.StatusSuccess,
-			CreatedAt:     time.Now().Format(time.RFC3339),
-		}
-	} else {
-		// Use real Gerbang response
-		externalID = gerbangResp.TransactionID
-		if gerbangResp.SerialNumber != nil {
-			serialNumber = gerbangResp.SerialNumber
-		}
-		switch gerbangResp.Status {
-		case gerbang.StatusSuccess:
-			status = domain.PostpaidStatusSuccess
-		case gerbang.StatusPending, gerbang.StatusProcessing:
-			status = domain.PostpaidStatusProcessing
-		default:
-			if !s.allowDummy {
-				return nil, fmt.Errorf("provider returned unsupported postpaid status: %s", gerbangResp.Status)
-			}
-			status = domain.PostpaidStatusSuccess
-		}
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if status == "" {
-		status = domain.PostpaidStatusSuccess
+	reservation, balance, err := s.reservationService.Reserve(ctx, tx, userID, totalPayment, domain.TransactionTypePostpaid, inquiryID)
+	if err != nil {
+		return nil, err
+	}
+	// Reserve locked the balance row, so a concurrent payment of the same
+	// bill that already finished is visible here
+	existingTx, err = s.postpaidRepo.FindTransactionByInquiryID(ctx, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check duplicate: %w", err)
+	}
+	if existingTx != nil {
+		return nil, domain.ErrDuplicateTransaction
 	}
-	if externalID == "" {
-		externalID = gerbangResp.TransactionID
+	if err := s.limits.CheckOutgoing(ctx, tx, userID, totalPayment); err != nil {
+		return nil, err
 	}
-	referenceNumber := gerbangResp.TransactionID
+	balanceAfter := balance.Amount
+	balanceBefore := balanceAfter + totalPayment
 
-	// Create transaction record
-	var completedAt *time.Time
 	now := time.Now()
-	if status == domain.PostpaidStatusSuccess {
-		completedAt = &now
-	}
 	transaction := &domain.PostpaidTransaction{
 		ID:              transactionID,
 		UserID:          userID,
@@ -412,32 +400,262 @@ func (s *PostpaidService) Pay(ctx context.Context, userID, inquiryID string, vou
 		TotalPayment:    totalPayment,
 		BalanceBefore:   balanceBefore,
 		BalanceAfter:    balanceAfter,
-		ReferenceNumber: referenceNumber,
-		SerialNumber:    serialNumber,
-		Status:          status,
-		CompletedAt:     completedAt,
+		Status:          domain.PostpaidStatusProcessing,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
+	if supplierCode != "" {
+		transaction.SupplierCode = &supplierCode
+	}
+	if err := s.postpaidRepo.CreateTransactionWithTx(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	if externalID != "" {
+	// Pay the bill at the supplier that answered the inquiry
+	if err := s.reservationService.MarkProviderCalled(ctx, reservation); err != nil {
+		if abortErr := s.abortPayment(ctx, inquiryID, transactionID); abortErr != nil {
+			return nil, abortErr
+		}
+		return nil, err
+	}
+	supplierResp, err := s.supplierService.Pay(ctx, supplierCode, product, transactionID, inquiryExternalID, inquiry.Target)
+	if err != nil && s.allowDummy {
+		slog.Warn("falling back to dummy postpaid payment",
+			slog.String("transaction_id", transactionID),
+			slog.String("inquiry_id", inquiryID),
+			slog.String("service_type", inquiry.ServiceType),
+			slog.String("error", err.Error()),
+		)
+
+		supplierResp = &supplier.Transaction{
+			TransactionID: buildDummyReference("POST"),
+			ReferenceID:   transactionID,
+			SKUCode:       product.SKUCode,
+			CustomerNo:    inquiry.Target,
+			CustomerName:  inquiry.CustomerName,
+			Status:        supplier.StatusSuccess,
+		}
+		err = nil
+	}
+	if err != nil {
+		if supplier.Rejected(err) {
+			if abortErr := s.abortPayment(ctx, inquiryID, transactionID); abortErr != nil {
+				return nil, abortErr
+			}
+			if supplier.IsCode(err, supplier.CodeUnavailable) {
+				return nil, domain.ErrProviderUnavailable
+			}
+			return nil, fmt.Errorf("provider call failed: %w", err)
+		}
+
+		// No definite answer: the supplier may still pay the bill, so the
+		// funds stay charged and the webhook decides the final status
+		slog.Warn("postpaid payment outcome unknown, keeping transaction processing",
+			slog.String("transaction_id", transactionID),
+			slog.String("error", err.Error()),
+		)
+		supplierResp = &supplier.Transaction{
+			ReferenceID: transactionID,
+			SKUCode:     product.SKUCode,
+			CustomerNo:  inquiry.Target,
+			Status:      supplier.StatusPending,
+		}
+	}
+
+	status := domain.PostpaidStatusProcessing
+	switch supplierResp.Status {
+	case supplier.StatusSuccess:
+		status = domain.PostpaidStatusSuccess
+	case supplier.StatusPending, supplier.StatusProcessing:
+		status = domain.PostpaidStatusProcessing
+	case supplier.StatusFailed:
+		if err := s.abortPayment(ctx, inquiryID, transactionID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("provider rejected postpaid payment %s", supplierResp.TransactionID)
+	default:
+		slog.Warn("provider returned unsupported postpaid status, keeping transaction processing",
+			slog.String("transaction_id", transactionID),
+			slog.String("status", supplierResp.Status),
+		)
+	}
+
+	transaction.Status = status
+	transaction.ReferenceNumber = supplierResp.TransactionID
+	transaction.SerialNumber = supplierResp.SerialNumber
+	if supplierResp.TransactionID != "" {
+		externalID := supplierResp.TransactionID
 		transaction.ExternalID = &externalID
 	}
+	if supplierResp.Supplier != "" {
+		transaction.SupplierCode = &supplierResp.Supplier
+	}
+	if status == domain.PostpaidStatusSuccess {
+		completedAt := time.Now()
+		transaction.CompletedAt = &completedAt
+	}
 
-	// Save transaction inside the same DB transaction as balance deduction.
-	if err := s.postpaidRepo.CreateTransactionWithTx(ctx, tx, transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	// Settle the reservation and store the supplier result
+	tx, err = s.postpaidRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Commit transaction
+	reservation, err = s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePostpaid, inquiryID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation != nil {
+		if _, err := s.reservationService.Settle(ctx, tx, reservation, totalPayment); err != nil {
+			return nil, err
+		}
+	} else {
+		// A webhook resolved the payment first; keep the status it set
+		current, err := s.postpaidRepo.FindTransactionByID(ctx, transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if current != nil {
+			transaction.Status = current.Status
+			transaction.CompletedAt = current.CompletedAt
+			status = current.Status
+		}
+	}
+
+	if err := s.postpaidRepo.UpdateTransactionResultWithTx(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if s.contactService != nil {
+		target := s.contactTarget(ctx, transaction)
+		if saveContact != nil {
+			target.Name = saveContact.Name
+			target.Save = true
+		}
+		if status == domain.PostpaidStatusSuccess {
+			s.contactService.RecordUsage(ctx, target)
+		} else if target.Save {
+			s.contactService.SaveTarget(ctx, target)
+		}
+	}
+
 	// Build response
 	return s.buildPayResponse(transaction), nil
 }
 
+// abortPayment releases the reservation of a payment the supplier rejected
+// and removes its processing transaction so the bill can be paid again
+func (s *PostpaidService) abortPayment(ctx context.Context, inquiryID, transactionID string) error {
+	tx, err := s.postpaidRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePostpaid, inquiryID)
+	if err != nil {
+		return err
+	}
+	if reservation == nil {
+		return nil // Already resolved by a webhook
+	}
+
+	if _, err := s.reservationService.Release(ctx, tx, reservation); err != nil {
+		return err
+	}
+	if err := s.postpaidRepo.DeleteTransactionWithTx(ctx, tx, transactionID); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ReservationOutcome reports what the supplier did with the reserved bill
+// payment, from the recorded result or a status lookup at the supplier.
+// Implements ReservationResolver.
+func (s *PostpaidService) ReservationOutcome(ctx context.Context, reservation *domain.BalanceReservation) (string, error) {
+	transaction, err := s.postpaidRepo.FindTransactionByInquiryID(ctx, reservation.ReferenceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		return ReservationOutcomeFailed, nil
+	}
+	switch transaction.Status {
+	case domain.PostpaidStatusSuccess:
+		return ReservationOutcomeCharged, nil
+	case domain.PostpaidStatusFailed:
+		return ReservationOutcomeFailed, nil
+	}
+
+	// Without the supplier's transaction ID only the webhook can tell
+	if transaction.ExternalID == nil || *transaction.ExternalID == "" {
+		return ReservationOutcomeUnknown, nil
+	}
+	supplierCode := ""
+	if transaction.SupplierCode != nil {
+		supplierCode = *transaction.SupplierCode
+	}
+	result, err := s.supplierService.Status(ctx, supplierCode, *transaction.ExternalID)
+	if err != nil {
+		return "", err
+	}
+	return supplierOutcome(result.Status), nil
+}
+
+// AbortReservation removes the processing transaction of a released
+// reservation. Implements ReservationResolver.
+func (s *PostpaidService) AbortReservation(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) error {
+	transaction, err := s.postpaidRepo.FindTransactionByInquiryID(ctx, reservation.ReferenceID)
+	if err != nil {
+		return fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		return nil
+	}
+	if err := s.postpaidRepo.DeleteTransactionWithTx(ctx, tx, transaction.ID); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+	return nil
+}
+
+// contactTarget builds the contact target for a postpaid transaction,
+// including the PDAM region so the contact can be paid again directly
+func (s *PostpaidService) contactTarget(ctx context.Context, transaction *domain.PostpaidTransaction) ContactTarget {
+	metadata := map[string]interface{}{}
+	if transaction.CustomerName != "" {
+		metadata["customerName"] = transaction.CustomerName
+	}
+	if transaction.ServiceType == domain.ServicePDAM && transaction.ProviderID != nil && *transaction.ProviderID != "" {
+		metadata["regionId"] = *transaction.ProviderID
+		if regions, err := s.productRepo.FindAllPDAMRegions(ctx); err == nil {
+			for _, region := range regions {
+				if region.ID == *transaction.ProviderID {
+					metadata["regionName"] = region.Name
+					break
+				}
+			}
+		}
+	}
+
+	return ContactTarget{
+		UserID:      transaction.UserID,
+		ServiceType: transaction.ServiceType,
+		Value:       transaction.Target,
+		Metadata:    metadata,
+	}
+}
+
 // buildPayResponse builds payment response
 func (s *PostpaidService) buildPayResponse(tx *domain.PostpaidTransaction) *domain.PostpaidPayResponse {
 	completedAt := ""
@@ -590,6 +808,19 @@ func (s *PostpaidService) dummyPostpaidAmount(serviceType, target string, produc
 	return base + ((sum % 9) * 5000)
 }
 
+// postpaidMaintenanceTargets lists what a bill inquiry sells: the service
+// type, the selected provider and, when known, the product brand
+func postpaidMaintenanceTargets(serviceType string, providerID *string, product *domain.Product) []domain.MaintenanceTarget {
+	targets := []domain.MaintenanceTarget{{Scope: domain.MaintenanceScopeService, Target: serviceType}}
+	if providerID != nil {
+		targets = append(targets, domain.MaintenanceTarget{Scope: domain.MaintenanceScopeOperator, Target: *providerID})
+	}
+	if product != nil {
+		targets = append(targets, domain.MaintenanceTarget{Scope: domain.MaintenanceScopeOperator, Target: product.Brand})
+	}
+	return targets
+}
+
 func (s *PostpaidService) findPostpaidProduct(ctx context.Context, serviceType string) (*domain.Product, error) {
 	isActive := true
 	filter := repository.ProductFilter{
@@ -716,6 +947,26 @@ func (s *PostpaidService) Refund(ctx context.Context, transactionID, reason stri
 		return domain.ErrValidationFailed("Transaksi tidak dapat dikembalikan")
 	}
 
+	// Funds returned by releasing the reservation were never charged
+	released, err := s.reservationService.Released(ctx, domain.TransactionTypePostpaid, transaction.InquiryID)
+	if err != nil {
+		return err
+	}
+	if released {
+		return domain.ErrValidationFailed("Transaksi tidak dapat dikembalikan")
+	}
+
+	// Settle a payment still in flight so the webhook cannot release it again
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePostpaid, transaction.InquiryID)
+	if err != nil {
+		return err
+	}
+	if reservation != nil {
+		if _, err := s.reservationService.Settle(ctx, tx, reservation, reservation.Amount); err != nil {
+			return err
+		}
+	}
+
 	// Lock and restore balance
 	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, transaction.UserID)
 	if err != nil {
@@ -785,6 +1036,12 @@ func (s *PostpaidService) HandleWebhook(ctx context.Context, webhookData *gerban
 	}
 	defer tx.Rollback()
 
+	// Funds of a payment whose supplier call has not returned yet are still reserved
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePostpaid, transaction.InquiryID)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	newStatus := domain.PostpaidStatusProcessing
 	refundNeeded := false
@@ -805,6 +1062,21 @@ func (s *PostpaidService) HandleWebhook(ctx context.Context, webhookData *gerban
 		return fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
+	if reservation != nil {
+		switch newStatus {
+		case domain.PostpaidStatusSuccess:
+			if _, err := s.reservationService.Settle(ctx, tx, reservation, reservation.Amount); err != nil {
+				return err
+			}
+		case domain.PostpaidStatusFailed:
+			// The reserved funds were never charged, releasing them is the refund
+			if _, err := s.reservationService.Release(ctx, tx, reservation); err != nil {
+				return err
+			}
+			refundNeeded = false
+		}
+	}
+
 	if refundNeeded {
 		if s.refundRepo != nil {
 			existingRefund, err := s.refundRepo.FindBySourceTransactionID(ctx, transaction.ID)
@@ -850,5 +1122,13 @@ func (s *PostpaidService) HandleWebhook(ctx context.Context, webhookData *gerban
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if newStatus == domain.PostpaidStatusSuccess && s.contactService != nil {
+		s.contactService.RecordUsage(ctx, s.contactTarget(ctx, transaction))
+	}
+
+	return nil
 }
@@ -11,19 +11,31 @@ import (
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
 	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
+	"github.com/GTDGit/PPOB_BE/internal/external/supplier"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
 	"github.com/GTDGit/PPOB_BE/pkg/hash"
+	"github.com/jmoiron/sqlx"
 )
 
+// prepaidOutcomeWindow bounds how long the funds of a purchase stay reserved
+// while neither the supplier nor its webhook says what became of it
+const prepaidOutcomeWindow = 6 * time.Hour
+
 // PrepaidService handles prepaid transaction business logic
 type PrepaidService struct {
-	prepaidRepo   repository.PrepaidRepository
-	balanceRepo   repository.BalanceRepository
-	refundRepo    repository.RefundRepository
-	userRepo      repository.UserRepository
-	productRepo   repository.ProductRepository
-	gerbangClient *gerbang.Client
-	allowDummy    bool
+	prepaidRepo        repository.PrepaidRepository
+	balanceRepo        repository.BalanceRepository
+	refundRepo         repository.RefundRepository
+	userRepo           repository.UserRepository
+	productRepo        repository.ProductRepository
+	contactService     *ContactService
+	operatorService    *OperatorService
+	pricingService     *PricingService
+	supplierService    *SupplierService
+	reservationService *ReservationService
+	maintenance        *MaintenanceService
+	limits             *AccountLimitService
+	allowDummy         bool
 }
 
 // NewPrepaidService creates a new prepaid service
@@ -33,17 +45,29 @@ func NewPrepaidService(
 	refundRepo repository.RefundRepository,
 	userRepo repository.UserRepository,
 	productRepo repository.ProductRepository,
-	gerbangClient *gerbang.Client,
+	contactService *ContactService,
+	operatorService *OperatorService,
+	pricingService *PricingService,
+	supplierService *SupplierService,
+	reservationService *ReservationService,
+	maintenance *MaintenanceService,
+	limits *AccountLimitService,
 	allowDummy bool,
 ) *PrepaidService {
 	return &PrepaidService{
-		prepaidRepo:   prepaidRepo,
-		balanceRepo:   balanceRepo,
-		refundRepo:    refundRepo,
-		userRepo:      userRepo,
-		productRepo:   productRepo,
-		gerbangClient: gerbangClient,
-		allowDummy:    allowDummy,
+		prepaidRepo:        prepaidRepo,
+		balanceRepo:        balanceRepo,
+		refundRepo:         refundRepo,
+		userRepo:           userRepo,
+		productRepo:        productRepo,
+		contactService:     contactService,
+		operatorService:    operatorService,
+		pricingService:     pricingService,
+		supplierService:    supplierService,
+		reservationService: reservationService,
+		maintenance:        maintenance,
+		limits:             limits,
+		allowDummy:         allowDummy,
 	}
 }
 
@@ -53,6 +77,7 @@ type InquiryRequest struct {
 	ServiceType string
 	Target      string
 	ProviderID  *string
+	OperatorID  *string // User-selected operator overriding prefix detection (pulsa/data)
 }
 
 // Inquiry handles prepaid inquiry
@@ -67,14 +92,40 @@ func (s *PrepaidService) Inquiry(ctx context.Context, req InquiryRequest) (*doma
 		return nil, err
 	}
 
-	// TODO: Detect operator for pulsa/data
+	// Detect operator for pulsa/data, honouring an explicit user choice
 	var operatorID *string
+	var operator *domain.Operator
+	var detection *domain.OperatorDetection
+	overridden := false
 	if req.ServiceType == domain.ServicePulsa || req.ServiceType == domain.ServiceData {
-		operator := detectOperator(req.Target)
-		operatorID = &operator
+		var err error
+		operator, detection, overridden, err = s.resolveOperator(ctx, req.Target, req.OperatorID)
+		if err != nil {
+			return nil, err
+		}
+		if operator != nil {
+			operatorID = &operator.ID
+		}
+	}
+
+	// Under maintenance: show the notice instead of products, without opening an inquiry
+	if window := s.maintenance.Check(ctx, prepaidMaintenanceTargets(req.ServiceType, operatorID, req.ProviderID)...); window != nil {
+		response := &domain.PrepaidInquiryResponse{
+			Inquiry: &domain.InquiryInfo{
+				ServiceType: req.ServiceType,
+				Target:      req.Target,
+				TargetValid: true,
+			},
+			Products: []*domain.ProductInfo{},
+			Notices:  []*domain.NoticeInfo{window.Notice()},
+		}
+		if operator != nil {
+			response.Inquiry.Operator = toOperatorInfo(operator)
+		}
+		return response, nil
 	}
 
-	products, err := s.getProductsForService(ctx, req.ServiceType, operatorID)
+	products, err := s.getProductsForService(ctx, req.ServiceType, operatorID, s.userTier(ctx, req.UserID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load products: %w", err)
 	}
@@ -111,17 +162,31 @@ func (s *PrepaidService) Inquiry(ctx context.Context, req InquiryRequest) (*doma
 	}
 
 	// Add operator info if available
-	if operatorID != nil {
-		response.Inquiry.Operator = &domain.OperatorInfo{
-			ID:   *operatorID,
-			Name: getOperatorName(*operatorID),
-			Icon: *operatorID,
+	if operator != nil {
+		response.Inquiry.Operator = toOperatorInfo(operator)
+		response.Inquiry.OperatorOverridden = overridden
+	}
+	if detection != nil {
+		confidence := detection.Confidence
+		if overridden {
+			confidence = 1
+		}
+		response.Inquiry.OperatorConfidence = &confidence
+		if len(detection.Candidates) > 1 {
+			response.Inquiry.OperatorCandidates = make([]*domain.OperatorInfo, 0, len(detection.Candidates))
+			for _, candidate := range detection.Candidates {
+				response.Inquiry.OperatorCandidates = append(response.Inquiry.OperatorCandidates, toOperatorInfo(candidate))
+			}
 		}
 	}
 
 	expiresAt := inquiry.ExpiresAt.Format(time.RFC3339)
 	response.Inquiry.ExpiresAt = &expiresAt
 
+	if s.contactService != nil {
+		response.Suggestions = s.contactService.SuggestionsFor(ctx, req.UserID, req.ServiceType)
+	}
+
 	return response, nil
 }
 
@@ -131,6 +196,7 @@ type CreateOrderRequest struct {
 	InquiryID    string
 	ProductID    string
 	VoucherCodes []string
+	Contact      *ContactSaveRequest // Explicit "save as contact" request, nil when not asked
 }
 
 // CreateOrder handles order creation
@@ -164,6 +230,10 @@ func (s *PrepaidService) CreateOrder(ctx context.Context, req CreateOrderRequest
 	if !product.IsActive || product.Type != domain.ProductTypePrepaid || !prepaidProductMatchesService(product, inquiry.ServiceType) {
 		return nil, domain.ErrInvalidProduct
 	}
+	if err := s.maintenance.Guard(ctx, prepaidProductMaintenanceTargets(inquiry.ServiceType, inquiry.OperatorID, product)...); err != nil {
+		return nil, err
+	}
+	product = s.priceForTier(ctx, product, s.userTier(ctx, req.UserID))
 
 	// Calculate pricing
 	productPrice := product.Price
@@ -172,6 +242,10 @@ func (s *PrepaidService) CreateOrder(ctx context.Context, req CreateOrderRequest
 	totalDiscount := int64(0) // TODO: Apply vouchers
 	totalPayment := subtotal - totalDiscount
 
+	if err := s.limits.CheckOutgoing(ctx, nil, req.UserID, totalPayment); err != nil {
+		return nil, err
+	}
+
 	// Get user balance
 	balance, err := s.balanceRepo.FindByUserID(ctx, req.UserID)
 	if err != nil {
@@ -211,6 +285,14 @@ func (s *PrepaidService) CreateOrder(ctx context.Context, req CreateOrderRequest
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
 
+	// Explicit save requests are honoured at order time; usage is counted once paid
+	if req.Contact != nil && s.contactService != nil {
+		target := s.prepaidContactTarget(ctx, inquiry)
+		target.Name = req.Contact.Name
+		target.Save = true
+		s.contactService.SaveTarget(ctx, target)
+	}
+
 	// Build response
 	response := &domain.PrepaidOrderResponse{
 		Order: &domain.OrderInfo{
@@ -253,10 +335,7 @@ func (s *PrepaidService) CreateOrder(ctx context.Context, req CreateOrderRequest
 
 	// Add operator info
 	if inquiry.OperatorID != nil {
-		response.Target.Operator = &domain.OperatorInfo{
-			ID:   *inquiry.OperatorID,
-			Name: getOperatorName(*inquiry.OperatorID),
-		}
+		response.Target.Operator = s.operatorInfo(ctx, *inquiry.OperatorID)
 	}
 
 	// Add shortfall if balance insufficient
@@ -327,57 +406,77 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 		}
 	}
 
-	// Execute payment in database transaction
-	var balanceBefore, balanceAfter int64
+	product, err := s.productRepo.FindByID(ctx, order.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil || !product.IsActive {
+		return nil, domain.ErrInvalidProduct
+	}
+	if err := s.maintenance.Guard(ctx, prepaidProductMaintenanceTargets(order.ServiceType, nil, product)...); err != nil {
+		return nil, err
+	}
+
+	// Fail fast while every supplier is unreachable instead of reserving the balance
+	if !s.allowDummy && !s.supplierService.Available(ctx, product) {
+		return nil, domain.ErrProviderUnavailable
+	}
+
 	var serialNumber, referenceNumber string
 	var token, kwh *string
 	transactionID := repository.NewUUID()
 
-	// Begin transaction
+	// Reserve the payment and record the transaction as processing, then
+	// commit so no balance lock is held while the supplier is called
 	tx, err := s.prepaidRepo.BeginTx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback() // Will be no-op if transaction is committed
 
-	// Lock balance and deduct atomically
-	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, req.UserID)
+	reservation, balance, err := s.reservationService.Reserve(ctx, tx, req.UserID, order.TotalPayment, domain.TransactionTypePrepaid, order.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to lock balance: %w", err)
+		return nil, err
 	}
-	if balance == nil {
-		return nil, domain.ErrValidationFailed("Balance not found")
+	// Checked after the balance lock so concurrent payments are counted
+	if err := s.limits.CheckOutgoing(ctx, tx, req.UserID, order.TotalPayment); err != nil {
+		return nil, err
 	}
+	balanceAfter := balance.Amount
+	balanceBefore := balanceAfter + order.TotalPayment
 
-	// Check balance sufficiency
-	if balance.Amount < order.TotalPayment {
-		return nil, domain.ErrInsufficientBalance
+	transaction := &domain.PrepaidTransaction{
+		ID:            transactionID,
+		UserID:        req.UserID,
+		OrderID:       order.ID,
+		Status:        domain.TransactionProcessing,
+		ServiceType:   order.ServiceType,
+		Target:        order.Target,
+		ProductID:     order.ProductID,
+		TotalPayment:  order.TotalPayment,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balanceAfter,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
-
-	// Store balance before deduction
-	balanceBefore = balance.Amount
-	balance.Amount -= order.TotalPayment
-
-	// Update balance within transaction
-	if err := s.balanceRepo.UpdateWithTx(ctx, tx, balance); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+	if err := s.prepaidRepo.CreateTransactionWithTx(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
-	balanceAfter = balance.Amount
-
-	product, err := s.productRepo.FindByID(ctx, order.ProductID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get product: %w", err)
+	if err := s.prepaidRepo.UpdateOrderStatusWithTx(ctx, tx, order.ID, domain.OrderProcessing); err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
 	}
-	if product == nil || !product.IsActive {
-		return nil, domain.ErrInvalidProduct
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	gerbangResp, err := s.gerbangClient.CreatePrepaidTransaction(ctx, order.ID, product.SKUCode, order.Target)
-	if err != nil {
-		if !s.allowDummy {
-			return nil, fmt.Errorf("provider call failed: %w", err)
+	if err := s.reservationService.MarkProviderCalled(ctx, reservation); err != nil {
+		if abortErr := s.abortPayment(ctx, order.ID, transactionID); abortErr != nil {
+			return nil, abortErr
 		}
-
+		return nil, err
+	}
+	supplierResp, err := s.supplierService.Purchase(ctx, product, order.ID, order.Target)
+	if err != nil && s.allowDummy && !supplier.IsCode(err, supplier.CodeOutOfStock) {
 		slog.Warn("falling back to dummy prepaid transaction",
 			slog.String("order_id", order.ID),
 			slog.String("service_type", order.ServiceType),
@@ -385,33 +484,67 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 			slog.String("error", err.Error()),
 		)
 
-		gerbangResp = &gerbang.TransactionResponse{
+		supplierResp = &supplier.Transaction{
 			TransactionID: buildDummyReference("PPOB"),
 			ReferenceID:   order.ID,
 			SKUCode:       product.SKUCode,
 			CustomerNo:    order.Target,
-			Type:          "prepaid",
-			Status:        gerbang.StatusSuccess,
-			CreatedAt:     time.Now().Format(time.RFC3339),
+			Status:        supplier.StatusSuccess,
 		}
+		err = nil
+	}
+	if err != nil {
+		if supplier.Rejected(err) {
+			if abortErr := s.abortPayment(ctx, order.ID, transactionID); abortErr != nil {
+				return nil, abortErr
+			}
+			switch {
+			case supplier.IsCode(err, supplier.CodeOutOfStock):
+				return nil, domain.ErrProductUnavailable
+			case supplier.IsCode(err, supplier.CodeUnavailable):
+				return nil, domain.ErrProviderUnavailable
+			}
+			return nil, fmt.Errorf("provider call failed: %w", err)
+		}
+
+		// No definite answer: the supplier may still complete the purchase, so
+		// the funds stay reserved until the webhook or the recovery job's
+		// lookup by order ID decides the final status
+		slog.Warn("prepaid purchase outcome unknown, keeping reservation held",
+			slog.String("order_id", order.ID),
+			slog.String("error", err.Error()),
+		)
+		supplierResp = &supplier.Transaction{Status: supplier.StatusPending}
 	}
 
 	transactionStatus := domain.TransactionProcessing
 	orderStatus := domain.OrderProcessing
 	var completedAt *time.Time
 
-	switch gerbangResp.Status {
-	case gerbang.StatusSuccess:
+	switch supplierResp.Status {
+	case supplier.StatusSuccess:
 		transactionStatus = domain.TransactionSuccess
 		orderStatus = domain.OrderSuccess
 		now := time.Now()
 		completedAt = &now
-	case gerbang.StatusProcessing, gerbang.StatusPending:
+	case supplier.StatusProcessing, supplier.StatusPending:
 		transactionStatus = domain.TransactionProcessing
 		orderStatus = domain.OrderProcessing
+	case supplier.StatusFailed:
+		if !s.allowDummy {
+			if err := s.abortPayment(ctx, order.ID, transactionID); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("provider rejected prepaid purchase %s", supplierResp.TransactionID)
+		}
+		fallthrough
 	default:
 		if !s.allowDummy {
-			return nil, fmt.Errorf("provider returned unsupported prepaid status: %s", gerbangResp.Status)
+			slog.Warn("provider returned unsupported prepaid status, keeping transaction processing",
+				slog.String("order_id", order.ID),
+				slog.String("status", supplierResp.Status),
+			)
+			break
 		}
 
 		transactionStatus = domain.TransactionSuccess
@@ -420,21 +553,21 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 		completedAt = &now
 	}
 
-	if gerbangResp.SerialNumber != nil {
-		serialNumber = *gerbangResp.SerialNumber
+	if supplierResp.SerialNumber != nil {
+		serialNumber = *supplierResp.SerialNumber
 	}
-	referenceNumber = gerbangResp.TransactionID
+	referenceNumber = supplierResp.TransactionID
 
 	// Generate PLN-specific fields if applicable
 	if order.ServiceType == domain.ServicePLNPrepaid {
-		// Try to extract from Gerbang response description
-		if gerbangResp != nil && gerbangResp.Description != nil {
-			if tokenVal, ok := gerbangResp.Description["token"]; ok {
+		// Try to extract from the supplier response description
+		if supplierResp != nil && supplierResp.Description != nil {
+			if tokenVal, ok := supplierResp.Description["token"]; ok {
 				if tokenStr, ok := tokenVal.(string); ok {
 					token = &tokenStr
 				}
 			}
-			if kwhVal, ok := gerbangResp.Description["kwh"]; ok {
+			if kwhVal, ok := supplierResp.Description["kwh"]; ok {
 				if kwhStr, ok := kwhVal.(string); ok {
 					kwh = &kwhStr
 				}
@@ -455,34 +588,58 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 	if serialNumber != "" {
 		serialNumberPtr = &serialNumber
 	}
+	var referenceNumberPtr *string
+	if referenceNumber != "" {
+		referenceNumberPtr = &referenceNumber
+	}
 
-	transaction := &domain.PrepaidTransaction{
-		ID:              transactionID,
-		UserID:          req.UserID,
-		OrderID:         order.ID,
-		Status:          transactionStatus,
-		ServiceType:     order.ServiceType,
-		Target:          order.Target,
-		ProductID:       order.ProductID,
-		TotalPayment:    order.TotalPayment,
-		BalanceBefore:   balanceBefore,
-		BalanceAfter:    balanceAfter,
-		SerialNumber:    serialNumberPtr,
-		ReferenceNumber: &referenceNumber,
-		Token:           token,
-		KWH:             kwh,
-		CompletedAt:     completedAt,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+	transaction.Status = transactionStatus
+	transaction.SerialNumber = serialNumberPtr
+	transaction.ReferenceNumber = referenceNumberPtr
+	transaction.Token = token
+	transaction.KWH = kwh
+	transaction.CompletedAt = completedAt
+	if supplierResp.Supplier != "" {
+		transaction.SupplierCode = &supplierResp.Supplier
 	}
 
-	if err := s.prepaidRepo.CreateTransactionWithTx(ctx, tx, transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	// Settle the reservation of a completed purchase and store the supplier
+	// result. A purchase still processing keeps its funds reserved until the
+	// webhook or the recovery job learns its outcome.
+	tx, err = s.prepaidRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Update order status within transaction
-	if err := s.prepaidRepo.UpdateOrderStatusWithTx(ctx, tx, order.ID, orderStatus); err != nil {
-		return nil, fmt.Errorf("failed to update order status: %w", err)
+	reservation, err = s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePrepaid, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation != nil {
+		if transactionStatus == domain.TransactionSuccess {
+			if _, err := s.reservationService.Settle(ctx, tx, reservation, order.TotalPayment); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.prepaidRepo.UpdateOrderStatusWithTx(ctx, tx, order.ID, orderStatus); err != nil {
+			return nil, fmt.Errorf("failed to update order status: %w", err)
+		}
+	} else {
+		// A webhook resolved the payment first; keep the status it set
+		current, err := s.prepaidRepo.FindTransactionByID(ctx, transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if current != nil {
+			transaction.Status = current.Status
+			transaction.CompletedAt = current.CompletedAt
+			transactionStatus = current.Status
+		}
+	}
+
+	if err := s.prepaidRepo.UpdateTransactionResultWithTx(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
 
 	// Commit transaction
@@ -490,6 +647,10 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if transactionStatus == domain.TransactionSuccess {
+		s.recordContactUsage(ctx, order)
+	}
+
 	// Build response
 	productInfo := convertProductToInfo(product)
 	var completedAtStr *string
@@ -522,7 +683,7 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 		},
 		Receipt: &domain.ReceiptInfo{
 			SerialNumber:    serialNumberPtr,
-			ReferenceNumber: &referenceNumber,
+			ReferenceNumber: referenceNumberPtr,
 			Token:           token,
 			KWH:             kwh,
 		},
@@ -540,6 +701,123 @@ func (s *PrepaidService) Pay(ctx context.Context, req PayRequest) (*domain.Prepa
 	return response, nil
 }
 
+// abortPayment releases the reservation of a purchase the supplier rejected
+// and marks its transaction and order as failed
+func (s *PrepaidService) abortPayment(ctx context.Context, orderID, transactionID string) error {
+	tx, err := s.prepaidRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePrepaid, orderID)
+	if err != nil {
+		return err
+	}
+	if reservation == nil {
+		return nil // Already resolved by a webhook
+	}
+
+	if _, err := s.reservationService.Release(ctx, tx, reservation); err != nil {
+		return err
+	}
+	if err := s.failPaymentWithTx(ctx, tx, orderID, transactionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// failPaymentWithTx marks the processing transaction of a released payment
+// and its order as failed, keeping the record of the attempt
+func (s *PrepaidService) failPaymentWithTx(ctx context.Context, tx *sqlx.Tx, orderID, transactionID string) error {
+	if err := s.prepaidRepo.UpdateTransactionStatusWithTx(ctx, tx, transactionID, domain.TransactionFailed); err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	if err := s.prepaidRepo.UpdateOrderStatusWithTx(ctx, tx, orderID, domain.OrderFailed); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	return nil
+}
+
+// ReservationOutcome reports what the supplier did with the reserved order,
+// from the recorded result or a status lookup at the supplier. A purchase
+// the supplier has given no answer for within prepaidOutcomeWindow of the
+// call is reported failed so its funds are released. Implements
+// ReservationResolver.
+func (s *PrepaidService) ReservationOutcome(ctx context.Context, reservation *domain.BalanceReservation) (string, error) {
+	transaction, err := s.prepaidRepo.FindTransactionByOrderID(ctx, reservation.ReferenceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		return ReservationOutcomeFailed, nil
+	}
+	switch transaction.Status {
+	case domain.TransactionSuccess:
+		return ReservationOutcomeCharged, nil
+	case domain.TransactionFailed:
+		return ReservationOutcomeFailed, nil
+	}
+
+	supplierCode := ""
+	if transaction.SupplierCode != nil {
+		supplierCode = *transaction.SupplierCode
+	}
+	var result *supplier.Transaction
+	if transaction.ReferenceNumber != nil && *transaction.ReferenceNumber != "" {
+		result, err = s.supplierService.Status(ctx, supplierCode, *transaction.ReferenceNumber)
+	} else {
+		// The purchase call returned no supplier transaction ID; our order ID
+		// is the reference the supplier got
+		result, err = s.supplierService.StatusByReference(ctx, supplierCode, reservation.ReferenceID)
+	}
+	if err == nil {
+		return supplierOutcome(result.Status), nil
+	}
+
+	if reservation.ProviderCalledAt != nil && time.Since(*reservation.ProviderCalledAt) > prepaidOutcomeWindow {
+		slog.Warn("no supplier answer for prepaid order, releasing reservation",
+			slog.String("order_id", reservation.ReferenceID),
+			slog.Time("provider_called_at", *reservation.ProviderCalledAt),
+			slog.String("error", err.Error()),
+		)
+		return ReservationOutcomeFailed, nil
+	}
+	if supplier.IsCode(err, supplier.CodeNotFound) {
+		return ReservationOutcomeUnknown, nil
+	}
+	return "", err
+}
+
+// AbortReservation marks the processing transaction of a released
+// reservation as failed. Implements ReservationResolver.
+func (s *PrepaidService) AbortReservation(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) error {
+	transaction, err := s.prepaidRepo.FindTransactionByOrderID(ctx, reservation.ReferenceID)
+	if err != nil {
+		return fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		return nil
+	}
+	return s.failPaymentWithTx(ctx, tx, reservation.ReferenceID, transaction.ID)
+}
+
+// supplierOutcome maps a supplier transaction status to a reservation outcome
+func supplierOutcome(status string) string {
+	switch status {
+	case supplier.StatusSuccess:
+		return ReservationOutcomeCharged
+	case supplier.StatusFailed:
+		return ReservationOutcomeFailed
+	default:
+		return ReservationOutcomeUnknown
+	}
+}
+
 // Helper functions
 
 func isValidServiceType(serviceType string) bool {
@@ -613,53 +891,75 @@ func validateTarget(serviceType, target string) error {
 	return nil
 }
 
-func detectOperator(target string) string {
-	if len(target) < 4 {
-		return "unknown"
-	}
-	prefix := target[:4]
-	operatorMap := map[string]string{
-		// Telkomsel
-		"0811": "telkomsel", "0812": "telkomsel", "0813": "telkomsel",
-		"0821": "telkomsel", "0822": "telkomsel", "0823": "telkomsel",
-		"0852": "telkomsel", "0853": "telkomsel",
-		// Indosat
-		"0814": "indosat", "0815": "indosat", "0816": "indosat",
-		"0855": "indosat", "0856": "indosat", "0857": "indosat", "0858": "indosat",
-		// XL
-		"0817": "xl", "0818": "xl", "0819": "xl",
-		"0859": "xl", "0877": "xl", "0878": "xl",
-		// Axis
-		"0831": "axis", "0832": "axis", "0833": "axis", "0838": "axis",
-		// Three
-		"0895": "three", "0896": "three", "0897": "three", "0898": "three", "0899": "three",
-		// Smartfren
-		"0881": "smartfren", "0882": "smartfren", "0883": "smartfren",
-		"0884": "smartfren", "0885": "smartfren", "0886": "smartfren",
-		"0887": "smartfren", "0888": "smartfren", "0889": "smartfren",
-		// by.U
-		"0851": "byu",
-	}
-	if operator, ok := operatorMap[prefix]; ok {
-		return operator
-	}
-	return "unknown"
+// prepaidMaintenanceTargets lists what an inquiry sells: the service type and,
+// when known, the operator or provider brand
+func prepaidMaintenanceTargets(serviceType string, operatorID, providerID *string) []domain.MaintenanceTarget {
+	targets := []domain.MaintenanceTarget{{Scope: domain.MaintenanceScopeService, Target: serviceType}}
+	for _, id := range []*string{operatorID, providerID} {
+		if id != nil {
+			targets = append(targets, domain.MaintenanceTarget{Scope: domain.MaintenanceScopeOperator, Target: *id})
+		}
+	}
+	return targets
+}
+
+// prepaidProductMaintenanceTargets adds the product brand to the inquiry targets
+func prepaidProductMaintenanceTargets(serviceType string, operatorID *string, product *domain.Product) []domain.MaintenanceTarget {
+	targets := prepaidMaintenanceTargets(serviceType, operatorID, nil)
+	return append(targets, domain.MaintenanceTarget{Scope: domain.MaintenanceScopeOperator, Target: product.Brand})
+}
+
+// resolveOperator detects the operator of a phone number from the stored
+// prefixes. A user-selected operator takes precedence over the detection;
+// overridden reports whether it differs from the detected one.
+func (s *PrepaidService) resolveOperator(ctx context.Context, target string, selectedID *string) (*domain.Operator, *domain.OperatorDetection, bool, error) {
+	if s.operatorService == nil {
+		return nil, nil, false, nil
+	}
+
+	detection, err := s.operatorService.Detect(ctx, target)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to detect operator: %w", err)
+	}
+
+	if selectedID == nil || *selectedID == "" {
+		return detection.Operator, detection, false, nil
+	}
+
+	selected, err := s.operatorService.FindActive(ctx, *selectedID)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get operator: %w", err)
+	}
+	if selected == nil {
+		return nil, nil, false, domain.ErrValidationFailed("Operator tidak valid")
+	}
+
+	overridden := detection.Operator == nil || detection.Operator.ID != selected.ID
+	return selected, detection, overridden, nil
+}
+
+// operatorInfo returns display info for a stored operator ID, falling back to
+// the bare ID when the operator is no longer active
+func (s *PrepaidService) operatorInfo(ctx context.Context, operatorID string) *domain.OperatorInfo {
+	if s.operatorService != nil {
+		if operator, err := s.operatorService.FindActive(ctx, operatorID); err == nil && operator != nil {
+			return toOperatorInfo(operator)
+		}
+	}
+	return &domain.OperatorInfo{ID: operatorID, Name: operatorID, Icon: operatorID}
 }
 
-func getOperatorName(operatorID string) string {
-	names := map[string]string{
-		"telkomsel": "Telkomsel",
-		"indosat":   "Indosat Ooredoo",
-		"xl":        "XL Axiata",
-		"axis":      "Axis",
-		"three":     "Tri",
-		"smartfren": "Smartfren",
-		"byu":       "by.U",
+func toOperatorInfo(operator *domain.Operator) *domain.OperatorInfo {
+	info := &domain.OperatorInfo{
+		ID:   operator.ID,
+		Name: operator.Name,
+		Icon: operator.Icon,
 	}
-	if name, ok := names[operatorID]; ok {
-		return name
+	if operator.IconURL != "" {
+		iconURL := operator.IconURL
+		info.IconURL = &iconURL
 	}
-	return operatorID
+	return info
 }
 
 // generatePLNToken generates a mock PLN token
@@ -679,7 +979,7 @@ func calculateKWH(amount int64) string {
 	return fmt.Sprintf("%.1f kWh", kwh)
 }
 
-func (s *PrepaidService) getProductsForService(ctx context.Context, serviceType string, operatorID *string) ([]*domain.ProductInfo, error) {
+func (s *PrepaidService) getProductsForService(ctx context.Context, serviceType string, operatorID *string, tier string) ([]*domain.ProductInfo, error) {
 	isActive := true
 	filter := repository.ProductFilter{
 		Type:     domain.ProductTypePrepaid,
@@ -720,13 +1020,30 @@ func (s *PrepaidService) getProductsForService(ctx context.Context, serviceType
 	result := make([]*domain.ProductInfo, 0, len(products))
 	for _, product := range products {
 		if prepaidProductMatchesService(product, serviceType) {
-			result = append(result, convertProductToInfo(product))
+			result = append(result, convertProductToInfo(s.priceForTier(ctx, product, tier)))
 		}
 	}
 
 	return result, nil
 }
 
+// userTier returns the user's tier for tier pricing, or "" when unknown
+func (s *PrepaidService) userTier(ctx context.Context, userID string) string {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.Tier
+}
+
+// priceForTier applies tier pricing rules on top of the stored base price
+func (s *PrepaidService) priceForTier(ctx context.Context, product *domain.Product, tier string) *domain.Product {
+	if s.pricingService == nil {
+		return product
+	}
+	return s.pricingService.PriceForTier(ctx, product, tier)
+}
+
 func convertProductToInfo(product *domain.Product) *domain.ProductInfo {
 	nominal := inferNominalFromProduct(product)
 
@@ -795,7 +1112,8 @@ func operatorIDToBrand(operatorID string) string {
 	case "byu":
 		return "BYU"
 	default:
-		return ""
+		// Operators added through the admin console use their ID as brand
+		return strings.ToUpper(operatorID)
 	}
 }
 
@@ -935,6 +1253,26 @@ func (s *PrepaidService) Refund(ctx context.Context, transactionID, reason strin
 		return domain.ErrValidationFailed("Transaksi tidak dapat dikembalikan")
 	}
 
+	// Funds returned by releasing the reservation were never charged
+	released, err := s.reservationService.Released(ctx, domain.TransactionTypePrepaid, transaction.OrderID)
+	if err != nil {
+		return err
+	}
+	if released {
+		return domain.ErrValidationFailed("Transaksi tidak dapat dikembalikan")
+	}
+
+	// Settle a payment still in flight so the webhook cannot release it again
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePrepaid, transaction.OrderID)
+	if err != nil {
+		return err
+	}
+	if reservation != nil {
+		if _, err := s.reservationService.Settle(ctx, tx, reservation, reservation.Amount); err != nil {
+			return err
+		}
+	}
+
 	// Lock and restore balance
 	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, transaction.UserID)
 	if err != nil {
@@ -1018,6 +1356,12 @@ func (s *PrepaidService) HandleWebhook(ctx context.Context, webhookData *gerbang
 	}
 	defer tx.Rollback()
 
+	// Funds of a payment whose supplier call has not returned yet are still reserved
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypePrepaid, order.ID)
+	if err != nil {
+		return err
+	}
+
 	// Update order based on webhook event
 	now := time.Now()
 	var newStatus string
@@ -1059,6 +1403,21 @@ func (s *PrepaidService) HandleWebhook(ctx context.Context, webhookData *gerbang
 		return fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
+	if reservation != nil {
+		switch newStatus {
+		case domain.OrderSuccess:
+			if _, err := s.reservationService.Settle(ctx, tx, reservation, reservation.Amount); err != nil {
+				return err
+			}
+		case domain.OrderFailed:
+			// The reserved funds were never charged, releasing them is the refund
+			if _, err := s.reservationService.Release(ctx, tx, reservation); err != nil {
+				return err
+			}
+			refundNeeded = false
+		}
+	}
+
 	// If transaction failed, refund balance to user
 	if refundNeeded {
 		if s.refundRepo != nil {
@@ -1115,5 +1474,45 @@ func (s *PrepaidService) HandleWebhook(ctx context.Context, webhookData *gerbang
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if newStatus == domain.OrderSuccess {
+		s.recordContactUsage(ctx, order)
+	}
+
 	return nil
 }
+
+// recordContactUsage remembers the target of a successful prepaid order as a contact
+func (s *PrepaidService) recordContactUsage(ctx context.Context, order *domain.PrepaidOrder) {
+	if s.contactService == nil {
+		return
+	}
+
+	inquiry, err := s.prepaidRepo.FindInquiryByID(ctx, order.InquiryID)
+	if err != nil || inquiry == nil {
+		inquiry = &domain.PrepaidInquiry{
+			UserID:      order.UserID,
+			ServiceType: order.ServiceType,
+			Target:      order.Target,
+		}
+	}
+	s.contactService.RecordUsage(ctx, s.prepaidContactTarget(ctx, inquiry))
+}
+
+// prepaidContactTarget builds the contact target for a prepaid inquiry
+func (s *PrepaidService) prepaidContactTarget(ctx context.Context, inquiry *domain.PrepaidInquiry) ContactTarget {
+	metadata := map[string]interface{}{}
+	if inquiry.OperatorID != nil && *inquiry.OperatorID != "" && *inquiry.OperatorID != "unknown" {
+		metadata["operator"] = *inquiry.OperatorID
+		metadata["operatorName"] = s.operatorInfo(ctx, *inquiry.OperatorID).Name
+	}
+	if inquiry.CustomerName != nil && *inquiry.CustomerName != "" {
+		metadata["customerName"] = *inquiry.CustomerName
+	}
+
+	return ContactTarget{
+		UserID:      inquiry.UserID,
+		ServiceType: inquiry.ServiceType,
+		Value:       inquiry.Target,
+		Metadata:    metadata,
+	}
+}
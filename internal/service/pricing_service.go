@@ -0,0 +1,559 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// pricingRulesLocalTTL bounds how long an instance prices tiers with stale rules
+	pricingRulesLocalTTL = time.Minute
+	// pricingPreviewMaxItems caps the per-product rows returned by a preview
+	pricingPreviewMaxItems = 200
+)
+
+// Pricing rule change actions carried by price_change approvals
+const (
+	PricingChangeCreate = "create"
+	PricingChangeUpdate = "update"
+	PricingChangeDelete = "delete"
+)
+
+// PricingService computes selling prices from supplier cost using pricing
+// rules, and applies approved rule changes to the catalog
+type PricingService struct {
+	ruleRepo    repository.PricingRuleRepository
+	productRepo repository.ProductRepository
+	redisClient *redis.Client
+
+	mu       sync.RWMutex
+	rules    []*domain.PricingRule
+	loadedAt time.Time
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService(
+	ruleRepo repository.PricingRuleRepository,
+	productRepo repository.ProductRepository,
+	redisClient *redis.Client,
+) *PricingService {
+	return &PricingService{
+		ruleRepo:    ruleRepo,
+		productRepo: productRepo,
+		redisClient: redisClient,
+	}
+}
+
+// PricingRuleInput is the admin-editable part of a pricing rule
+type PricingRuleInput struct {
+	Name         string  `json:"name"`
+	Category     string  `json:"category"`
+	Brand        string  `json:"brand"`
+	SKUCode      string  `json:"skuCode"`
+	UserTier     string  `json:"userTier"`
+	MarkupType   string  `json:"markupType"`
+	MarkupValue  float64 `json:"markupValue"`
+	AdminMarkup  int64   `json:"adminMarkup"`
+	RoundingStep int64   `json:"roundingStep"`
+	RoundingMode string  `json:"roundingMode"`
+	MinPrice     int64   `json:"minPrice"`
+	MaxPrice     int64   `json:"maxPrice"`
+	Priority     int     `json:"priority"`
+	IsActive     *bool   `json:"isActive"`
+}
+
+// PricingRuleChange is a create, update or delete of one pricing rule
+type PricingRuleChange struct {
+	Action string            `json:"action"`
+	RuleID string            `json:"ruleId,omitempty"`
+	Rule   *PricingRuleInput `json:"rule,omitempty"`
+}
+
+// PricingChangeRequest is a maker's pricing change. It is either a rule change
+// or, as older clients send it, a target price for a single product, which is
+// translated into a SKU rule with a fixed markup over supplier cost.
+type PricingChangeRequest struct {
+	Action      string            `json:"action"`
+	RuleID      string            `json:"ruleId"`
+	Rule        *PricingRuleInput `json:"rule"`
+	ProductID   string            `json:"productId"`
+	NewPrice    int64             `json:"newPrice"`
+	NewAdminFee int64             `json:"newAdminFee"`
+}
+
+// ListRules returns every pricing rule for the admin console
+func (s *PricingService) ListRules(ctx context.Context) ([]*domain.PricingRule, error) {
+	rules, err := s.ruleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pricing rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ResolveChange validates a pricing change request and turns it into a rule change
+func (s *PricingService) ResolveChange(ctx context.Context, req PricingChangeRequest) (*PricingRuleChange, error) {
+	if req.Action == "" && req.ProductID != "" {
+		return s.changeForProductPrice(ctx, req.ProductID, req.NewPrice, req.NewAdminFee)
+	}
+
+	change := &PricingRuleChange{
+		Action: strings.ToLower(strings.TrimSpace(req.Action)),
+		RuleID: strings.TrimSpace(req.RuleID),
+		Rule:   req.Rule,
+	}
+	switch change.Action {
+	case PricingChangeCreate:
+		change.RuleID = ""
+	case PricingChangeUpdate, PricingChangeDelete:
+		if change.RuleID == "" {
+			return nil, domain.ErrValidationFailed("ruleId wajib diisi")
+		}
+		existing, err := s.ruleRepo.FindByID(ctx, change.RuleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pricing rule: %w", err)
+		}
+		if existing == nil {
+			return nil, domain.ErrNotFound("Aturan harga")
+		}
+	default:
+		return nil, domain.ErrValidationFailed("Action harus create, update, atau delete")
+	}
+
+	if change.Action == PricingChangeDelete {
+		change.Rule = nil
+		return change, nil
+	}
+	if change.Rule == nil {
+		return nil, domain.ErrValidationFailed("Aturan harga wajib diisi")
+	}
+	rule, err := buildPricingRule(change.RuleID, *change.Rule)
+	if err != nil {
+		return nil, err
+	}
+	change.Rule = pricingRuleToInput(rule)
+	return change, nil
+}
+
+// changeForProductPrice expresses a target selling price for one product as
+// an SKU rule, updating the product's existing base SKU rule when it has one
+func (s *PricingService) changeForProductPrice(ctx context.Context, productID string, newPrice, newAdminFee int64) (*PricingRuleChange, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, domain.ErrNotFound("Produk")
+	}
+	if newPrice < product.SupplierPrice {
+		return nil, domain.ErrValidationFailed(fmt.Sprintf("Harga jual tidak boleh di bawah harga modal (%d)", product.SupplierPrice))
+	}
+	if newAdminFee < 0 {
+		return nil, domain.ErrValidationFailed("Biaya admin tidak boleh negatif")
+	}
+
+	active := true
+	change := &PricingRuleChange{
+		Action: PricingChangeCreate,
+		Rule: &PricingRuleInput{
+			Name:        "Harga khusus " + product.SKUCode,
+			SKUCode:     product.SKUCode,
+			MarkupType:  domain.PricingMarkupFixed,
+			MarkupValue: float64(newPrice - product.SupplierPrice),
+			AdminMarkup: newAdminFee - product.SupplierAdmin,
+			IsActive:    &active,
+		},
+	}
+
+	rules, err := s.ruleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pricing rules: %w", err)
+	}
+	for _, rule := range rules {
+		if rule.SKUCode == product.SKUCode && rule.UserTier == "" && rule.Brand == "" && rule.Category == "" {
+			change.Action = PricingChangeUpdate
+			change.RuleID = rule.ID
+			change.Rule.Name = rule.Name
+			change.Rule.Priority = rule.Priority
+			break
+		}
+	}
+	return change, nil
+}
+
+// Preview computes the selling prices a change would produce without saving
+// it. Prices are compared for the tier the rule targets, so a tier rule
+// previews that tier's prices and a base rule previews the base price.
+func (s *PricingService) Preview(ctx context.Context, change *PricingRuleChange) (*domain.PricingPreview, error) {
+	current, err := s.ruleRepo.FindActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+	proposed, tier, err := s.proposedRules(ctx, current, change)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.FindAllForPricing(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+
+	preview := &domain.PricingPreview{
+		UserTier:      tier,
+		TotalProducts: len(products),
+		Items:         []*domain.PricingPreviewItem{},
+	}
+	for _, product := range products {
+		before := domain.QuotePrice(current, product, tier)
+		after := domain.QuotePrice(proposed, product, tier)
+		if before.Price == after.Price && before.Admin == after.Admin {
+			continue
+		}
+
+		preview.AffectedCount++
+		switch total := (after.Price + after.Admin) - (before.Price + before.Admin); {
+		case total > 0:
+			preview.Increased++
+		case total < 0:
+			preview.Decreased++
+		}
+		if len(preview.Items) >= pricingPreviewMaxItems {
+			preview.Truncated = true
+			continue
+		}
+		preview.Items = append(preview.Items, &domain.PricingPreviewItem{
+			ProductID:     product.ID,
+			SKUCode:       product.SKUCode,
+			Name:          product.Name,
+			Category:      product.Category,
+			Brand:         product.Brand,
+			SupplierPrice: product.SupplierPrice,
+			SupplierAdmin: product.SupplierAdmin,
+			OldPrice:      before.Price,
+			NewPrice:      after.Price,
+			OldAdmin:      before.Admin,
+			NewAdmin:      after.Admin,
+			OldRuleID:     before.RuleID,
+			NewRuleID:     after.RuleID,
+		})
+	}
+	return preview, nil
+}
+
+// Apply saves an approved rule change and reprices the catalog within tx, so
+// the change commits together with the approval that authorized it. Call
+// Refresh once tx is committed.
+func (s *PricingService) Apply(ctx context.Context, tx *sqlx.Tx, change *PricingRuleChange) (*domain.PricingRule, error) {
+	var rule *domain.PricingRule
+	switch change.Action {
+	case PricingChangeCreate, PricingChangeUpdate:
+		if change.Rule == nil {
+			return nil, domain.ErrValidationFailed("Aturan harga wajib diisi")
+		}
+		id := change.RuleID
+		if change.Action == PricingChangeCreate {
+			id = "prc_" + uuid.New().String()[:8]
+		}
+		built, err := buildPricingRule(id, *change.Rule)
+		if err != nil {
+			return nil, err
+		}
+		rule = built
+
+		if change.Action == PricingChangeCreate {
+			err = s.ruleRepo.CreateWithTx(ctx, tx, rule)
+		} else {
+			err = s.ruleRepo.UpdateWithTx(ctx, tx, rule)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save pricing rule: %w", err)
+		}
+	case PricingChangeDelete:
+		if err := s.ruleRepo.DeleteWithTx(ctx, tx, change.RuleID); err != nil {
+			return nil, fmt.Errorf("failed to delete pricing rule: %w", err)
+		}
+	default:
+		return nil, domain.ErrValidationFailed("Action harus create, update, atau delete")
+	}
+
+	rules, err := s.ruleRepo.FindActiveWithTx(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+	products, err := s.productRepo.FindAllForPricing(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+	changed, history := repricedProducts(rules, products)
+	if _, err := s.productRepo.UpdateSellingPricesWithTx(ctx, tx, changed); err != nil {
+		return nil, fmt.Errorf("failed to update selling prices: %w", err)
+	}
+	if err := s.productRepo.CreateChangesWithTx(ctx, tx, history); err != nil {
+		return nil, fmt.Errorf("failed to record repricing history: %w", err)
+	}
+	return rule, nil
+}
+
+// Refresh reloads the cached rules and drops cached product lists after an
+// applied change is committed
+func (s *PricingService) Refresh(ctx context.Context) {
+	rules, err := s.ruleRepo.FindActive(ctx)
+	if err != nil {
+		// A nil rule set makes the next lookup load from the database
+		slog.Warn("failed to reload pricing rules", slog.String("error", err.Error()))
+		rules = nil
+	}
+	s.store(rules)
+	s.invalidateProducts(ctx)
+}
+
+// Reprice recomputes the stored base price of every product from the active
+// rules and returns how many products changed
+func (s *PricingService) Reprice(ctx context.Context) (int, error) {
+	rules, err := s.ruleRepo.FindActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+	products, err := s.productRepo.FindAllForPricing(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load products: %w", err)
+	}
+
+	changed, history := repricedProducts(rules, products)
+	updated, err := s.productRepo.UpdateSellingPrices(ctx, changed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update selling prices: %w", err)
+	}
+	if err := s.productRepo.CreateChanges(ctx, history); err != nil {
+		slog.Warn("failed to record repricing history", slog.String("error", err.Error()))
+	}
+
+	s.store(rules)
+	s.invalidateProducts(ctx)
+	return updated, nil
+}
+
+// repricedProducts applies the rules to every product and returns the ones
+// whose price or admin changed, with their change history
+func repricedProducts(rules []*domain.PricingRule, products []*domain.Product) ([]*domain.Product, []*domain.ProductChange) {
+	changed := make([]*domain.Product, 0)
+	history := make([]*domain.ProductChange, 0)
+	for _, product := range products {
+		quote := domain.QuotePrice(rules, product, "")
+		if quote.Price != product.Price || quote.Admin != product.Admin {
+			previous := *product
+			product.Price = quote.Price
+			product.Admin = quote.Admin
+			changed = append(changed, product)
+			if change := domain.NewProductChange(&previous, product, domain.ProductChangeSourcePricing); change != nil {
+				history = append(history, change)
+			}
+		}
+	}
+	return changed, history
+}
+
+// PriceForTier returns the product priced for a user tier. The stored price is
+// the base price, so the product is returned unchanged unless a rule targets
+// the tier and yields a different price.
+func (s *PricingService) PriceForTier(ctx context.Context, product *domain.Product, tier string) *domain.Product {
+	if product == nil || tier == "" {
+		return product
+	}
+
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		slog.Warn("failed to load pricing rules, using base price",
+			slog.String("error", err.Error()),
+		)
+		return product
+	}
+
+	hasTierRule := false
+	for _, rule := range rules {
+		if rule.UserTier != "" && strings.EqualFold(rule.UserTier, tier) {
+			hasTierRule = true
+			break
+		}
+	}
+	if !hasTierRule {
+		return product
+	}
+
+	quote := domain.QuotePrice(rules, product, tier)
+	if quote.Price == product.Price && quote.Admin == product.Admin {
+		return product
+	}
+	priced := *product
+	priced.Price = quote.Price
+	priced.Admin = quote.Admin
+	return &priced
+}
+
+// proposedRules returns the active rule set as it would be after the change,
+// along with the tier the change targets
+func (s *PricingService) proposedRules(ctx context.Context, current []*domain.PricingRule, change *PricingRuleChange) ([]*domain.PricingRule, string, error) {
+	proposed := make([]*domain.PricingRule, 0, len(current)+1)
+	for _, rule := range current {
+		if change.RuleID == "" || rule.ID != change.RuleID {
+			proposed = append(proposed, rule)
+		}
+	}
+
+	switch change.Action {
+	case PricingChangeDelete:
+		existing, err := s.ruleRepo.FindByID(ctx, change.RuleID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get pricing rule: %w", err)
+		}
+		if existing == nil {
+			return nil, "", domain.ErrNotFound("Aturan harga")
+		}
+		return proposed, existing.UserTier, nil
+	default:
+		if change.Rule == nil {
+			return nil, "", domain.ErrValidationFailed("Aturan harga wajib diisi")
+		}
+		id := change.RuleID
+		if id == "" {
+			id = "preview"
+		}
+		rule, err := buildPricingRule(id, *change.Rule)
+		if err != nil {
+			return nil, "", err
+		}
+		rule.UpdatedAt = time.Now()
+		return append(proposed, rule), rule.UserTier, nil
+	}
+}
+
+// activeRules returns the active rules, reusing the in-memory copy while fresh
+func (s *PricingService) activeRules(ctx context.Context) ([]*domain.PricingRule, error) {
+	s.mu.RLock()
+	rules, loadedAt := s.rules, s.loadedAt
+	s.mu.RUnlock()
+	if rules != nil && time.Since(loadedAt) < pricingRulesLocalTTL {
+		return rules, nil
+	}
+
+	rules, err := s.ruleRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.store(rules)
+	return rules, nil
+}
+
+func (s *PricingService) store(rules []*domain.PricingRule) {
+	s.mu.Lock()
+	s.rules = rules
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// invalidateProducts drops cached product lists so they pick up new prices
+func (s *PricingService) invalidateProducts(ctx context.Context) {
+	if s.redisClient == nil {
+		return
+	}
+	keys, err := s.redisClient.Keys(ctx, "products:*").Result()
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		slog.Warn("failed to invalidate product cache", slog.String("error", err.Error()))
+	}
+}
+
+// buildPricingRule validates admin input and normalizes it into a rule
+func buildPricingRule(id string, input PricingRuleInput) (*domain.PricingRule, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return nil, domain.ErrValidationFailed("Nama aturan harga wajib diisi (maksimal 100 karakter)")
+	}
+
+	markupType := strings.ToLower(strings.TrimSpace(input.MarkupType))
+	if markupType == "" {
+		markupType = domain.PricingMarkupFixed
+	}
+	switch markupType {
+	case domain.PricingMarkupFixed:
+	case domain.PricingMarkupPercentage:
+		if input.MarkupValue <= -100 || input.MarkupValue > 1000 {
+			return nil, domain.ErrValidationFailed("Markup persentase harus di atas -100 dan maksimal 1000")
+		}
+	default:
+		return nil, domain.ErrValidationFailed("Tipe markup harus fixed atau percentage")
+	}
+
+	roundingMode := strings.ToLower(strings.TrimSpace(input.RoundingMode))
+	if roundingMode == "" {
+		roundingMode = domain.PricingRoundUp
+	}
+	if roundingMode != domain.PricingRoundUp && roundingMode != domain.PricingRoundNearest && roundingMode != domain.PricingRoundDown {
+		return nil, domain.ErrValidationFailed("Mode pembulatan harus up, nearest, atau down")
+	}
+	if input.RoundingStep < 0 {
+		return nil, domain.ErrValidationFailed("Kelipatan pembulatan tidak boleh negatif")
+	}
+
+	if input.MinPrice < 0 || input.MaxPrice < 0 {
+		return nil, domain.ErrValidationFailed("Harga minimum dan maksimum tidak boleh negatif")
+	}
+	if input.MinPrice > 0 && input.MaxPrice > 0 && input.MinPrice > input.MaxPrice {
+		return nil, domain.ErrValidationFailed("Harga minimum tidak boleh melebihi harga maksimum")
+	}
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	return &domain.PricingRule{
+		ID:           id,
+		Name:         name,
+		Category:     strings.TrimSpace(input.Category),
+		Brand:        strings.ToUpper(strings.TrimSpace(input.Brand)),
+		SKUCode:      strings.TrimSpace(input.SKUCode),
+		UserTier:     strings.ToUpper(strings.TrimSpace(input.UserTier)),
+		MarkupType:   markupType,
+		MarkupValue:  input.MarkupValue,
+		AdminMarkup:  input.AdminMarkup,
+		RoundingStep: input.RoundingStep,
+		RoundingMode: roundingMode,
+		MinPrice:     input.MinPrice,
+		MaxPrice:     input.MaxPrice,
+		Priority:     input.Priority,
+		IsActive:     isActive,
+	}, nil
+}
+
+func pricingRuleToInput(rule *domain.PricingRule) *PricingRuleInput {
+	isActive := rule.IsActive
+	return &PricingRuleInput{
+		Name:         rule.Name,
+		Category:     rule.Category,
+		Brand:        rule.Brand,
+		SKUCode:      rule.SKUCode,
+		UserTier:     rule.UserTier,
+		MarkupType:   rule.MarkupType,
+		MarkupValue:  rule.MarkupValue,
+		AdminMarkup:  rule.AdminMarkup,
+		RoundingStep: rule.RoundingStep,
+		RoundingMode: rule.RoundingMode,
+		MinPrice:     rule.MinPrice,
+		MaxPrice:     rule.MaxPrice,
+		Priority:     rule.Priority,
+		IsActive:     &isActive,
+	}
+}
@@ -140,3 +140,33 @@ func (s *ProductService) GetTVProviders(ctx context.Context) ([]*domain.TVProvid
 
 	return providers, nil
 }
+
+// GetProductHistory returns the change timeline of a SKU, newest first
+func (s *ProductService) GetProductHistory(ctx context.Context, skuCode string, page, perPage int) (*domain.AdminListResponse, error) {
+	product, err := s.productRepo.FindBySKU(ctx, skuCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, domain.ErrNotFound("Produk")
+	}
+	return s.listChanges(ctx, repository.ProductChangeFilter{SKUCode: skuCode, Page: page, PerPage: perPage})
+}
+
+// ListProductChanges returns recent product changes across the catalog
+func (s *ProductService) ListProductChanges(ctx context.Context, alertsOnly bool, page, perPage int) (*domain.AdminListResponse, error) {
+	return s.listChanges(ctx, repository.ProductChangeFilter{AlertsOnly: alertsOnly, Page: page, PerPage: perPage})
+}
+
+func (s *ProductService) listChanges(ctx context.Context, filter repository.ProductChangeFilter) (*domain.AdminListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	filter.PerPage = sanitizePerPage(filter.PerPage)
+
+	changes, total, err := s.productRepo.FindChanges(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product changes: %w", err)
+	}
+	return paginated(changes, total, filter.Page, filter.PerPage), nil
+}
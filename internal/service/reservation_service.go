@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ReservationResolver is implemented by services that reserve balance before
+// calling a provider. The recovery job uses it to resolve reservations that
+// were never settled or released.
+type ReservationResolver interface {
+	// ReservationOutcome asks the provider what happened to the call made for
+	// the reservation: ReservationOutcomeCharged, ReservationOutcomeFailed or
+	// ReservationOutcomeUnknown
+	ReservationOutcome(ctx context.Context, reservation *domain.BalanceReservation) (string, error)
+	// AbortReservation undoes the processing record of the reservation's
+	// reference within tx once its funds were released
+	AbortReservation(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) error
+}
+
+// Provider outcomes reported by a ReservationResolver
+const (
+	ReservationOutcomeCharged = "charged" // The provider accepted the payment
+	ReservationOutcomeFailed  = "failed"  // The provider rejected or failed the payment
+	ReservationOutcomeUnknown = "unknown" // No definite answer yet
+)
+
+// ReservationService moves funds into balances.pending_amount before a
+// provider call so the balance row is not locked while the call is in flight,
+// then settles or releases them once the outcome is known
+type ReservationService struct {
+	reservationRepo repository.ReservationRepository
+	balanceRepo     repository.BalanceRepository
+	ttl             time.Duration
+	resolvers       map[string]ReservationResolver
+}
+
+// NewReservationService creates a new reservation service
+func NewReservationService(
+	reservationRepo repository.ReservationRepository,
+	balanceRepo repository.BalanceRepository,
+	ttl time.Duration,
+) *ReservationService {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &ReservationService{
+		reservationRepo: reservationRepo,
+		balanceRepo:     balanceRepo,
+		ttl:             ttl,
+		resolvers:       make(map[string]ReservationResolver),
+	}
+}
+
+// RegisterResolver sets the resolver used to recover reservations of a reference type
+func (s *ReservationService) RegisterResolver(referenceType string, resolver ReservationResolver) {
+	s.resolvers[referenceType] = resolver
+}
+
+// Reserve moves amount from the user's available balance into pending_amount.
+// It returns the reservation and the balance after the move.
+func (s *ReservationService) Reserve(ctx context.Context, tx *sqlx.Tx, userID string, amount int64, referenceType, referenceID string) (*domain.BalanceReservation, *domain.Balance, error) {
+	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock balance: %w", err)
+	}
+	if balance == nil {
+		return nil, nil, domain.ErrValidationFailed("Balance not found")
+	}
+	if balance.Amount < amount {
+		return nil, nil, domain.ErrInsufficientBalance
+	}
+
+	balance.Amount -= amount
+	balance.PendingAmount += amount
+	if err := s.balanceRepo.UpdateWithTx(ctx, tx, balance); err != nil {
+		return nil, nil, fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	reservation := &domain.BalanceReservation{
+		UserID:        userID,
+		Amount:        amount,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		Status:        domain.ReservationHeld,
+		ExpiresAt:     time.Now().Add(s.ttl),
+	}
+	if err := s.reservationRepo.CreateWithTx(ctx, tx, reservation); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			// Another payment of the same reference is already in flight
+			return nil, nil, domain.ErrDuplicateTransaction
+		}
+		return nil, nil, fmt.Errorf("failed to create reservation: %w", err)
+	}
+
+	return reservation, balance, nil
+}
+
+// MarkProviderCalled records that the provider call of a reservation is about
+// to go out. Callers must not call the provider when it fails: recovery
+// releases reservations without the mark.
+func (s *ReservationService) MarkProviderCalled(ctx context.Context, reservation *domain.BalanceReservation) error {
+	if err := s.reservationRepo.MarkProviderCalled(ctx, reservation.ID); err != nil {
+		return fmt.Errorf("failed to mark reservation %s as called: %w", reservation.ID, err)
+	}
+	now := time.Now()
+	reservation.ProviderCalledAt = &now
+	return nil
+}
+
+// HeldForUpdate locks the held reservation of a reference. It returns nil when
+// the reservation was already settled or released, e.g. by a webhook that
+// arrived before the provider call returned.
+func (s *ReservationService) HeldForUpdate(ctx context.Context, tx *sqlx.Tx, referenceType, referenceID string) (*domain.BalanceReservation, error) {
+	reservation, err := s.reservationRepo.FindHeldByReferenceForUpdate(ctx, tx, referenceType, referenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock reservation: %w", err)
+	}
+	return reservation, nil
+}
+
+// Settle marks a held reservation as spent. finalAmount may differ from the
+// reserved amount, e.g. when the provider charges a different fee; the
+// difference is taken from or returned to the available balance.
+func (s *ReservationService) Settle(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation, finalAmount int64) (*domain.Balance, error) {
+	balance, err := s.lockBalance(ctx, tx, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	balance.PendingAmount -= reservation.Amount
+	balance.Amount -= finalAmount - reservation.Amount
+	if balance.Amount < 0 {
+		slog.Warn("balance negative after settling reservation",
+			slog.String("reservation_id", reservation.ID),
+			slog.String("user_id", reservation.UserID),
+			slog.Int64("reserved", reservation.Amount),
+			slog.Int64("final", finalAmount),
+		)
+	}
+	if err := s.balanceRepo.UpdateWithTx(ctx, tx, balance); err != nil {
+		return nil, fmt.Errorf("failed to update balance: %w", err)
+	}
+	if err := s.reservationRepo.UpdateStatusWithTx(ctx, tx, reservation.ID, domain.ReservationSettled); err != nil {
+		return nil, fmt.Errorf("failed to settle reservation: %w", err)
+	}
+
+	reservation.Status = domain.ReservationSettled
+	return balance, nil
+}
+
+// Release returns a held reservation to the available balance
+func (s *ReservationService) Release(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) (*domain.Balance, error) {
+	balance, err := s.lockBalance(ctx, tx, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	balance.PendingAmount -= reservation.Amount
+	balance.Amount += reservation.Amount
+	if err := s.balanceRepo.UpdateWithTx(ctx, tx, balance); err != nil {
+		return nil, fmt.Errorf("failed to update balance: %w", err)
+	}
+	if err := s.reservationRepo.UpdateStatusWithTx(ctx, tx, reservation.ID, domain.ReservationReleased); err != nil {
+		return nil, fmt.Errorf("failed to release reservation: %w", err)
+	}
+
+	reservation.Status = domain.ReservationReleased
+	return balance, nil
+}
+
+// Released reports whether the funds of a reference were already returned by
+// releasing its reservation, so they must not be refunded again
+func (s *ReservationService) Released(ctx context.Context, referenceType, referenceID string) (bool, error) {
+	reservation, err := s.reservationRepo.FindLatestByReference(ctx, referenceType, referenceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	return reservation != nil && reservation.Status == domain.ReservationReleased, nil
+}
+
+// RecoverExpired resolves held reservations whose owner never settled or
+// released them, e.g. because the process died around the provider call.
+// Reservations whose provider call never went out are released; the others
+// are settled or released by the provider's answer and left held while the
+// provider has none.
+func (s *ReservationService) RecoverExpired(ctx context.Context, limit int) (int, error) {
+	reservations, err := s.reservationRepo.FindExpiredHeld(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired reservations: %w", err)
+	}
+
+	recovered := 0
+	for _, reservation := range reservations {
+		ok, err := s.recover(ctx, reservation)
+		if err != nil {
+			slog.Error("failed to recover balance reservation",
+				slog.String("reservation_id", reservation.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if ok {
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+func (s *ReservationService) recover(ctx context.Context, listed *domain.BalanceReservation) (bool, error) {
+	resolver, ok := s.resolvers[listed.ReferenceType]
+	if !ok {
+		return false, fmt.Errorf("no resolver for reference type %s", listed.ReferenceType)
+	}
+
+	// Ask the provider before locking anything
+	outcome := ReservationOutcomeFailed
+	if listed.ProviderCalledAt != nil {
+		var err error
+		outcome, err = resolver.ReservationOutcome(ctx, listed)
+		if err != nil {
+			slog.Warn("failed to get provider outcome of reservation",
+				slog.String("reservation_id", listed.ID),
+				slog.String("error", err.Error()),
+			)
+			outcome = ReservationOutcomeUnknown
+		}
+	}
+
+	tx, err := s.reservationRepo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := s.reservationRepo.FindByIDForUpdate(ctx, tx, listed.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock reservation: %w", err)
+	}
+	if reservation == nil || reservation.Status != domain.ReservationHeld {
+		return false, nil // Resolved since it was listed
+	}
+	if reservation.ProviderCalledAt != nil && listed.ProviderCalledAt == nil {
+		return false, nil // The call went out since it was listed; check again next run
+	}
+
+	switch outcome {
+	case ReservationOutcomeCharged:
+		_, err = s.Settle(ctx, tx, reservation, reservation.Amount)
+	case ReservationOutcomeFailed:
+		if _, err = s.Release(ctx, tx, reservation); err == nil {
+			err = resolver.AbortReservation(ctx, tx, reservation)
+		}
+	default:
+		// Keep the funds held for the provider webhook and look again later
+		err = s.reservationRepo.ExtendWithTx(ctx, tx, reservation.ID, time.Now().Add(s.ttl))
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if outcome == ReservationOutcomeUnknown {
+		slog.Warn("balance reservation outcome still unknown, keeping it held",
+			slog.String("reservation_id", reservation.ID),
+			slog.String("reference_type", reservation.ReferenceType),
+			slog.String("reference_id", reservation.ReferenceID),
+		)
+		return false, nil
+	}
+
+	slog.Warn("recovered orphaned balance reservation",
+		slog.String("reservation_id", reservation.ID),
+		slog.String("reference_type", reservation.ReferenceType),
+		slog.String("reference_id", reservation.ReferenceID),
+		slog.String("status", reservation.Status),
+	)
+	return true, nil
+}
+
+func (s *ReservationService) lockBalance(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) (*domain.Balance, error) {
+	if reservation.Status != domain.ReservationHeld {
+		return nil, fmt.Errorf("reservation %s is already %s", reservation.ID, reservation.Status)
+	}
+	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, reservation.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock balance: %w", err)
+	}
+	if balance == nil {
+		return nil, domain.ErrNotFound("Saldo")
+	}
+	return balance, nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/external/supplier"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+)
+
+// SupplierService routes PPOB purchases to suppliers per SKU. Candidates are
+// the SKU's active routes plus an implicit route to the primary supplier at
+// the product's synced supplier price; healthy suppliers are tried first,
+// cheapest first, and a failure moves on to the next supplier only for the
+// configured error codes.
+type SupplierService struct {
+	supplierRepo repository.SupplierRepository
+	productRepo  repository.ProductRepository
+	adminRepo    *repository.AdminRepository
+	health       *supplier.HealthTracker
+	suppliers    map[string]supplier.Supplier
+	primary      string
+	failover     map[string]bool
+}
+
+// NewSupplierService creates a new supplier service. The primary supplier
+// serves every SKU without an explicit route.
+func NewSupplierService(
+	supplierRepo repository.SupplierRepository,
+	productRepo repository.ProductRepository,
+	adminRepo *repository.AdminRepository,
+	health *supplier.HealthTracker,
+	failoverCodes []string,
+	primary supplier.Supplier,
+	others ...supplier.Supplier,
+) *SupplierService {
+	suppliers := map[string]supplier.Supplier{primary.Code(): primary}
+	for _, s := range others {
+		suppliers[s.Code()] = s
+	}
+	failover := make(map[string]bool, len(failoverCodes))
+	for _, code := range failoverCodes {
+		if code = strings.TrimSpace(code); code != "" {
+			failover[code] = true
+		}
+	}
+
+	return &SupplierService{
+		supplierRepo: supplierRepo,
+		productRepo:  productRepo,
+		adminRepo:    adminRepo,
+		health:       health,
+		suppliers:    suppliers,
+		primary:      primary.Code(),
+		failover:     failover,
+	}
+}
+
+// SupplierStatus is a registered supplier with its current health
+type SupplierStatus struct {
+	*domain.Supplier
+	Configured bool                   `json:"configured"` // An implementation is wired in this deployment
+	Health     *supplier.HealthStatus `json:"health"`
+}
+
+// SupplierRouting lists a SKU's routes and the order suppliers would be tried now
+type SupplierRouting struct {
+	SKUCode string                  `json:"skuCode"`
+	Routes  []*domain.SupplierRoute `json:"routes"`
+	Order   []string                `json:"order"`
+}
+
+// SupplierRouteInput represents an admin route upsert request
+type SupplierRouteInput struct {
+	SupplierCode    string `json:"supplierCode" binding:"required"`
+	SKUCode         string `json:"skuCode" binding:"required"`
+	SupplierSKUCode string `json:"supplierSkuCode"`
+	CostPrice       int64  `json:"costPrice"`
+	Priority        int    `json:"priority"`
+	IsActive        *bool  `json:"isActive"`
+}
+
+// Purchase buys a prepaid product, failing over between suppliers
+func (s *SupplierService) Purchase(ctx context.Context, product *domain.Product, referenceID, customerNo string) (*supplier.Transaction, error) {
+	return s.route(ctx, product, func(sup supplier.Supplier, skuCode string) (*supplier.Transaction, error) {
+		return sup.Purchase(ctx, supplier.Request{ReferenceID: referenceID, SKUCode: skuCode, CustomerNo: customerNo})
+	})
+}
+
+// Inquiry looks up a postpaid bill, failing over between suppliers. The
+// returned transaction names the supplier that must also take the payment.
+func (s *SupplierService) Inquiry(ctx context.Context, product *domain.Product, referenceID, customerNo string) (*supplier.Transaction, error) {
+	return s.route(ctx, product, func(sup supplier.Supplier, skuCode string) (*supplier.Transaction, error) {
+		return sup.Inquiry(ctx, supplier.Request{ReferenceID: referenceID, SKUCode: skuCode, CustomerNo: customerNo})
+	})
+}
+
+// Pay pays a postpaid bill at the supplier that answered the inquiry. There
+// is no failover: the inquiry ID is only valid at that supplier.
+func (s *SupplierService) Pay(ctx context.Context, supplierCode string, product *domain.Product, referenceID, inquiryID, customerNo string) (*supplier.Transaction, error) {
+	if supplierCode == "" {
+		supplierCode = s.primary
+	}
+	sup, ok := s.suppliers[supplierCode]
+	if !ok {
+		return nil, &supplier.Error{Supplier: supplierCode, Code: supplier.CodeUnavailable, Message: "supplier is not configured"}
+	}
+
+	skuCode := product.SKUCode
+	routes, err := s.supplierRepo.FindRoutesBySKU(ctx, product.SKUCode)
+	if err != nil {
+		slog.Warn("failed to load supplier routes", slog.String("sku_code", product.SKUCode), slog.String("error", err.Error()))
+	}
+	for _, route := range routes {
+		if route.SupplierCode == supplierCode {
+			skuCode = route.SupplierSKUCode
+		}
+	}
+
+	tx, err := sup.Pay(ctx, supplier.Request{ReferenceID: referenceID, SKUCode: skuCode, CustomerNo: customerNo, InquiryID: inquiryID})
+	s.health.Record(supplierCode, err)
+	if err != nil {
+		return nil, err
+	}
+	tx.Supplier = supplierCode
+	return tx, nil
+}
+
+// Status looks up a transaction at the supplier that accepted it. Suppliers
+// without status lookups answer CodeUnknown.
+func (s *SupplierService) Status(ctx context.Context, supplierCode, transactionID string) (*supplier.Transaction, error) {
+	if supplierCode == "" {
+		supplierCode = s.primary
+	}
+	checker, ok := s.suppliers[supplierCode].(supplier.StatusChecker)
+	if !ok {
+		return nil, &supplier.Error{Supplier: supplierCode, Code: supplier.CodeUnknown, Message: "supplier has no status lookup"}
+	}
+
+	tx, err := checker.Status(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Supplier = supplierCode
+	return tx, nil
+}
+
+// StatusByReference looks up the transaction created for our reference ID,
+// for calls that returned no answer. Without a supplier code every supplier
+// with reference lookups is asked, as the call may have failed over. It fails
+// with CodeNotFound when every supplier asked says it never took the request.
+func (s *SupplierService) StatusByReference(ctx context.Context, supplierCode, referenceID string) (*supplier.Transaction, error) {
+	codes := []string{supplierCode}
+	if supplierCode == "" {
+		codes = make([]string, 0, len(s.suppliers))
+		for code := range s.suppliers {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+	}
+
+	asked := false
+	var lastErr error
+	for _, code := range codes {
+		checker, ok := s.suppliers[code].(supplier.ReferenceStatusChecker)
+		if !ok {
+			continue
+		}
+		asked = true
+		tx, err := checker.StatusByReference(ctx, referenceID)
+		if err == nil {
+			tx.Supplier = code
+			return tx, nil
+		}
+		if !supplier.IsCode(err, supplier.CodeNotFound) {
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	if !asked {
+		return nil, &supplier.Error{Supplier: supplierCode, Code: supplier.CodeUnknown, Message: "supplier has no reference lookup"}
+	}
+	return nil, &supplier.Error{Supplier: supplierCode, Code: supplier.CodeNotFound, Message: "no supplier took reference " + referenceID}
+}
+
+// route tries the SKU's candidate suppliers in order until one succeeds or
+// fails with an error that does not warrant failover
+func (s *SupplierService) route(ctx context.Context, product *domain.Product, call func(supplier.Supplier, string) (*supplier.Transaction, error)) (*supplier.Transaction, error) {
+	candidates := s.candidates(ctx, product)
+	if len(candidates) == 0 {
+		return nil, &supplier.Error{Code: supplier.CodeUnavailable, Message: "no active supplier route for " + product.SKUCode}
+	}
+
+	var lastErr error
+	for i, route := range candidates {
+		tx, err := call(s.suppliers[route.SupplierCode], route.SupplierSKUCode)
+		s.health.Record(route.SupplierCode, err)
+		if err == nil {
+			tx.Supplier = route.SupplierCode
+			return tx, nil
+		}
+
+		lastErr = err
+		code := supplier.CodeOf(err)
+		if !s.failover[code] || i == len(candidates)-1 {
+			break
+		}
+		slog.Warn("supplier failed, failing over",
+			slog.String("sku_code", product.SKUCode),
+			slog.String("supplier", route.SupplierCode),
+			slog.String("next_supplier", candidates[i+1].SupplierCode),
+			slog.String("code", code),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil, lastErr
+}
+
+// candidates returns the usable routes of a product in the order they should be tried
+func (s *SupplierService) candidates(ctx context.Context, product *domain.Product) []*domain.SupplierRoute {
+	routes, err := s.supplierRepo.FindRoutesBySKU(ctx, product.SKUCode)
+	if err != nil {
+		// Without the routing table, keep selling through the primary supplier
+		slog.Warn("failed to load supplier routes", slog.String("sku_code", product.SKUCode), slog.String("error", err.Error()))
+		routes = nil
+	}
+	routes = withPrimaryRoute(routes, product, s.primary)
+
+	candidates := make([]*domain.SupplierRoute, 0, len(routes))
+	for _, route := range routes {
+		if _, ok := s.suppliers[route.SupplierCode]; ok && route.IsActive && route.SupplierActive {
+			candidates = append(candidates, route)
+		}
+	}
+	orderSupplierRoutes(candidates, func(code string) bool {
+		return s.health.IsHealthy(code) && s.SupplierAvailable(code)
+	})
+	return candidates
+}
+
+// Available reports whether any supplier routed for the product can be
+// called right now, so callers can fail fast before locking balances
+func (s *SupplierService) Available(ctx context.Context, product *domain.Product) bool {
+	for _, route := range s.candidates(ctx, product) {
+		if s.SupplierAvailable(route.SupplierCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupplierAvailable reports whether a configured supplier is not known to be
+// unreachable (e.g. its circuit breaker is open)
+func (s *SupplierService) SupplierAvailable(code string) bool {
+	sup, ok := s.suppliers[code]
+	if !ok {
+		return false
+	}
+	if reporter, ok := sup.(supplier.AvailabilityReporter); ok {
+		return reporter.Available()
+	}
+	return true
+}
+
+// withPrimaryRoute adds the implicit primary supplier route when the SKU has
+// no explicit one, costed at the product's synced supplier price
+func withPrimaryRoute(routes []*domain.SupplierRoute, product *domain.Product, primary string) []*domain.SupplierRoute {
+	for _, route := range routes {
+		if route.SupplierCode == primary {
+			return routes
+		}
+	}
+	return append(routes, &domain.SupplierRoute{
+		SupplierCode:    primary,
+		SKUCode:         product.SKUCode,
+		SupplierSKUCode: product.SKUCode,
+		CostPrice:       product.SupplierPrice,
+		IsActive:        true,
+		SupplierActive:  true,
+	})
+}
+
+// orderSupplierRoutes sorts routes healthy first, then cheapest, then by priority
+func orderSupplierRoutes(routes []*domain.SupplierRoute, healthy func(string) bool) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		hi, hj := healthy(routes[i].SupplierCode), healthy(routes[j].SupplierCode)
+		if hi != hj {
+			return hi
+		}
+		if routes[i].CostPrice != routes[j].CostPrice {
+			return routes[i].CostPrice < routes[j].CostPrice
+		}
+		return routes[i].Priority > routes[j].Priority
+	})
+}
+
+// ListSuppliers returns every registered supplier with its health
+func (s *SupplierService) ListSuppliers(ctx context.Context) ([]*SupplierStatus, error) {
+	suppliers, err := s.supplierRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+
+	health := make(map[string]supplier.HealthStatus)
+	for _, status := range s.health.Snapshot() {
+		health[status.Supplier] = status
+	}
+
+	items := make([]*SupplierStatus, 0, len(suppliers))
+	for _, sup := range suppliers {
+		_, configured := s.suppliers[sup.Code]
+		status, ok := health[sup.Code]
+		if !ok {
+			status = supplier.HealthStatus{Supplier: sup.Code, Healthy: true}
+		}
+		items = append(items, &SupplierStatus{Supplier: sup, Configured: configured, Health: &status})
+	}
+	return items, nil
+}
+
+// GetRouting returns a SKU's routes and the current supplier order
+func (s *SupplierService) GetRouting(ctx context.Context, skuCode string) (*SupplierRouting, error) {
+	product, err := s.productRepo.FindBySKU(ctx, skuCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, domain.ErrNotFound("Produk")
+	}
+
+	routes, err := s.supplierRepo.FindRoutesBySKU(ctx, skuCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier routes: %w", err)
+	}
+
+	order := []string{}
+	for _, route := range s.candidates(ctx, product) {
+		order = append(order, route.SupplierCode)
+	}
+	return &SupplierRouting{SKUCode: skuCode, Routes: routes, Order: order}, nil
+}
+
+// UpsertRoute creates or replaces a supplier's route for a SKU
+func (s *SupplierService) UpsertRoute(ctx context.Context, actorID string, input SupplierRouteInput) (*domain.SupplierRoute, error) {
+	input.SupplierCode = strings.TrimSpace(input.SupplierCode)
+	input.SKUCode = strings.TrimSpace(input.SKUCode)
+	input.SupplierSKUCode = strings.TrimSpace(input.SupplierSKUCode)
+	if input.SupplierSKUCode == "" {
+		input.SupplierSKUCode = input.SKUCode
+	}
+	if input.CostPrice < 0 {
+		return nil, domain.ErrValidationFailed("Harga modal tidak boleh negatif")
+	}
+
+	suppliers, err := s.supplierRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+	known := false
+	for _, sup := range suppliers {
+		known = known || sup.Code == input.SupplierCode
+	}
+	if !known {
+		return nil, domain.ErrValidationFailed("Supplier tidak terdaftar")
+	}
+
+	product, err := s.productRepo.FindBySKU(ctx, input.SKUCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, domain.ErrNotFound("Produk")
+	}
+
+	route := &domain.SupplierRoute{
+		SupplierCode:    input.SupplierCode,
+		SKUCode:         input.SKUCode,
+		SupplierSKUCode: input.SupplierSKUCode,
+		CostPrice:       input.CostPrice,
+		Priority:        input.Priority,
+		IsActive:        input.IsActive == nil || *input.IsActive,
+	}
+	if err := s.supplierRepo.UpsertRoute(ctx, route); err != nil {
+		return nil, fmt.Errorf("failed to save supplier route: %w", err)
+	}
+
+	s.audit(ctx, actorID, "supplier_route.upsert", route.ID, nil, route)
+	return route, nil
+}
+
+// DeleteRoute removes a supplier route
+func (s *SupplierService) DeleteRoute(ctx context.Context, actorID, routeID string) error {
+	existing, err := s.supplierRepo.FindRouteByID(ctx, routeID)
+	if err != nil {
+		return fmt.Errorf("failed to get supplier route: %w", err)
+	}
+	if existing == nil {
+		return domain.ErrNotFound("Rute supplier")
+	}
+
+	if err := s.supplierRepo.DeleteRoute(ctx, routeID); err != nil {
+		return fmt.Errorf("failed to delete supplier route: %w", err)
+	}
+
+	s.audit(ctx, actorID, "supplier_route.delete", routeID, existing, nil)
+	return nil
+}
+
+func (s *SupplierService) audit(ctx context.Context, actorID, action, routeID string, oldValue, newValue interface{}) {
+	if s.adminRepo == nil {
+		return
+	}
+	_ = s.adminRepo.CreateAuditLog(ctx, &domain.AdminAuditLog{
+		ID:           "aal_" + uuid.New().String()[:8],
+		AdminUserID:  sql.NullString{String: actorID, Valid: actorID != ""},
+		Action:       action,
+		ResourceType: sql.NullString{String: "supplier_route", Valid: true},
+		ResourceID:   sql.NullString{String: routeID, Valid: true},
+		OldValue:     oldValue,
+		NewValue:     newValue,
+		Status:       "success",
+		CreatedAt:    time.Now(),
+	})
+}
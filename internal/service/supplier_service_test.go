@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/external/supplier"
+	"github.com/GTDGit/PPOB_BE/internal/repository"
+)
+
+// fakeSupplierRepo serves a fixed routing table
+type fakeSupplierRepo struct {
+	repository.SupplierRepository
+	routes []*domain.SupplierRoute
+}
+
+func (r *fakeSupplierRepo) FindRoutesBySKU(ctx context.Context, skuCode string) ([]*domain.SupplierRoute, error) {
+	routes := []*domain.SupplierRoute{}
+	for _, route := range r.routes {
+		if route.SKUCode == skuCode {
+			copied := *route
+			routes = append(routes, &copied)
+		}
+	}
+	return routes, nil
+}
+
+// newStubSupplier starts a local stub supplier and returns its handler and client
+func newStubSupplier(t *testing.T, code string) (*supplier.StubHandler, supplier.Supplier) {
+	t.Helper()
+	handler := supplier.NewStubHandler(supplier.StubConfig{APIKey: "key-" + code})
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return handler, supplier.NewHTTPSupplier(supplier.HTTPConfig{Code: code, BaseURL: server.URL, APIKey: "key-" + code, Timeout: 5 * time.Second})
+}
+
+func TestSupplierServiceFailover(t *testing.T) {
+	ctx := context.Background()
+	primaryStub, primary := newStubSupplier(t, "primary")
+	secondaryStub, secondary := newStubSupplier(t, "secondary")
+
+	product := &domain.Product{SKUCode: "TSEL10", SupplierPrice: 10000}
+	repo := &fakeSupplierRepo{routes: []*domain.SupplierRoute{
+		{SupplierCode: "secondary", SKUCode: "TSEL10", SupplierSKUCode: "S-TSEL10", CostPrice: 10100, IsActive: true, SupplierActive: true},
+	}}
+	svc := NewSupplierService(repo, nil, nil, supplier.NewHealthTracker(2, time.Minute),
+		[]string{supplier.CodeOutOfStock, supplier.CodeUnavailable}, primary, secondary)
+
+	// Cheapest supplier first
+	tx, err := svc.Purchase(ctx, product, "ord-1", "081234567890")
+	if err != nil || tx.Supplier != "primary" {
+		t.Fatalf("Purchase = %+v, %v; want primary", tx, err)
+	}
+
+	// Out of stock fails over to the next supplier with its own SKU code
+	primaryStub.SetFailure("TSEL10", supplier.CodeOutOfStock)
+	tx, err = svc.Purchase(ctx, product, "ord-2", "081234567890")
+	if err != nil || tx.Supplier != "secondary" || tx.SKUCode != "S-TSEL10" {
+		t.Fatalf("Purchase after out of stock = %+v, %v; want secondary S-TSEL10", tx, err)
+	}
+
+	// Request errors are not retried elsewhere
+	primaryStub.SetFailure("TSEL10", supplier.CodeInvalidRequest)
+	before := secondaryStub.Calls()
+	if _, err := svc.Purchase(ctx, product, "ord-3", "081234567890"); !supplier.IsCode(err, supplier.CodeInvalidRequest) {
+		t.Fatalf("Purchase with invalid request error = %v; want invalid_request", err)
+	}
+	if secondaryStub.Calls() != before {
+		t.Error("invalid request was failed over to the secondary supplier")
+	}
+
+	// Repeated outages move the primary supplier to the back of the queue
+	primaryStub.SetFailure("TSEL10", supplier.CodeUnavailable)
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Purchase(ctx, product, "ord-4", "081234567890"); err != nil {
+			t.Fatalf("Purchase during outage: %v", err)
+		}
+	}
+	before = primaryStub.Calls()
+	tx, err = svc.Purchase(ctx, product, "ord-5", "081234567890")
+	if err != nil || tx.Supplier != "secondary" || primaryStub.Calls() != before {
+		t.Fatalf("Purchase with unhealthy primary = %+v, %v (primary calls %d -> %d); want secondary only", tx, err, before, primaryStub.Calls())
+	}
+
+	// Every supplier failing returns the last error
+	secondaryStub.SetFailure("*", supplier.CodeOutOfStock)
+	if _, err := svc.Purchase(ctx, product, "ord-6", "081234567890"); !supplier.IsCode(err, supplier.CodeUnavailable) {
+		t.Fatalf("Purchase with every supplier failing = %v; want unavailable from primary", err)
+	}
+}
+
+func TestSupplierServicePostpaidPinnedToInquirySupplier(t *testing.T) {
+	ctx := context.Background()
+	primaryStub, primary := newStubSupplier(t, "primary")
+	_, secondary := newStubSupplier(t, "secondary")
+
+	product := &domain.Product{SKUCode: "PLNPOST"}
+	repo := &fakeSupplierRepo{routes: []*domain.SupplierRoute{
+		{SupplierCode: "secondary", SKUCode: "PLNPOST", SupplierSKUCode: "PLNPOST", IsActive: true, SupplierActive: true, Priority: -1},
+	}}
+	svc := NewSupplierService(repo, nil, nil, supplier.NewHealthTracker(3, time.Minute),
+		[]string{supplier.CodeUnavailable}, primary, secondary)
+
+	if _, err := svc.Inquiry(ctx, product, "inq-1", "5310000"); !supplier.IsCode(err, supplier.CodeNotFound) {
+		t.Fatalf("Inquiry without bill = %v; want not_found", err)
+	}
+
+	primaryStub.SetFailure("*", supplier.CodeUnavailable)
+	inquiry, err := svc.Inquiry(ctx, product, "inq-2", "531234567890")
+	if err != nil || inquiry.Supplier != "secondary" || inquiry.TotalAmount == 0 {
+		t.Fatalf("Inquiry = %+v, %v; want bill from secondary", inquiry, err)
+	}
+
+	payment, err := svc.Pay(ctx, inquiry.Supplier, product, "pay-1", inquiry.TransactionID, "531234567890")
+	if err != nil || payment.Supplier != "secondary" || payment.Status != supplier.StatusSuccess {
+		t.Fatalf("Pay = %+v, %v; want success at secondary", payment, err)
+	}
+}
+
+func TestSupplierServiceFailoverOnlyWhenNotAccepted(t *testing.T) {
+	ctx := context.Background()
+	secondaryStub, secondary := newStubSupplier(t, "secondary")
+
+	// A bare 502 may come after the supplier took the order
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>Bad Gateway</html>", http.StatusBadGateway)
+	}))
+	t.Cleanup(broken.Close)
+	// A closed server refuses the connection before anything is sent
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+
+	product := &domain.Product{SKUCode: "TSEL10", SupplierPrice: 10000}
+	repo := &fakeSupplierRepo{routes: []*domain.SupplierRoute{
+		{SupplierCode: "secondary", SKUCode: "TSEL10", SupplierSKUCode: "TSEL10", CostPrice: 10100, IsActive: true, SupplierActive: true},
+	}}
+	failover := []string{supplier.CodeOutOfStock, supplier.CodeUnavailable, supplier.CodeInsufficientDeposit}
+
+	brokenPrimary := supplier.NewHTTPSupplier(supplier.HTTPConfig{Code: "primary", BaseURL: broken.URL, Timeout: 5 * time.Second})
+	svc := NewSupplierService(repo, nil, nil, supplier.NewHealthTracker(5, time.Minute), failover, brokenPrimary, secondary)
+	before := secondaryStub.Calls()
+	if _, err := svc.Purchase(ctx, product, "ord-1", "081234567890"); !supplier.IsCode(err, supplier.CodeTimeout) {
+		t.Fatalf("Purchase after 502 = %v; want timeout", err)
+	}
+	if secondaryStub.Calls() != before {
+		t.Error("ambiguous 502 was failed over to the secondary supplier")
+	}
+
+	refusedPrimary := supplier.NewHTTPSupplier(supplier.HTTPConfig{Code: "primary", BaseURL: refused.URL, Timeout: 5 * time.Second})
+	svc = NewSupplierService(repo, nil, nil, supplier.NewHealthTracker(5, time.Minute), failover, refusedPrimary, secondary)
+	tx, err := svc.Purchase(ctx, product, "ord-2", "081234567890")
+	if err != nil || tx.Supplier != "secondary" {
+		t.Fatalf("Purchase after connection refused = %+v, %v; want secondary", tx, err)
+	}
+}
+
+func TestSupplierServiceStatusByReference(t *testing.T) {
+	ctx := context.Background()
+	primaryStub, primary := newStubSupplier(t, "primary")
+	_, secondary := newStubSupplier(t, "secondary")
+
+	product := &domain.Product{SKUCode: "TSEL10", SupplierPrice: 10000}
+	repo := &fakeSupplierRepo{routes: []*domain.SupplierRoute{
+		{SupplierCode: "secondary", SKUCode: "TSEL10", SupplierSKUCode: "TSEL10", CostPrice: 10100, IsActive: true, SupplierActive: true},
+	}}
+	svc := NewSupplierService(repo, nil, nil, supplier.NewHealthTracker(5, time.Minute),
+		[]string{supplier.CodeOutOfStock}, primary, secondary)
+
+	// The order failed over, so only the secondary supplier knows it
+	primaryStub.SetFailure("TSEL10", supplier.CodeOutOfStock)
+	if _, err := svc.Purchase(ctx, product, "ord-1", "081234567890"); err != nil {
+		t.Fatalf("Purchase: %v", err)
+	}
+
+	tx, err := svc.StatusByReference(ctx, "", "ord-1")
+	if err != nil || tx.Supplier != "secondary" || tx.ReferenceID != "ord-1" || tx.Status != supplier.StatusSuccess {
+		t.Fatalf("StatusByReference = %+v, %v; want success at secondary", tx, err)
+	}
+	if _, err := svc.StatusByReference(ctx, "primary", "ord-1"); !supplier.IsCode(err, supplier.CodeNotFound) {
+		t.Errorf("StatusByReference at primary = %v; want not_found", err)
+	}
+	if _, err := svc.StatusByReference(ctx, "", "ord-2"); !supplier.IsCode(err, supplier.CodeNotFound) {
+		t.Errorf("StatusByReference of unknown order = %v; want not_found", err)
+	}
+}
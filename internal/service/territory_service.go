@@ -2,19 +2,83 @@ package service
 
 import (
 	"context"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
+	"github.com/GTDGit/PPOB_BE/pkg/redis"
 )
 
+const (
+	territorySearchMinLength    = 2
+	territorySearchDefaultLimit = 10
+	territorySearchMaxLimit     = 50
+	// A query is cached once it is searched territorySearchHotThreshold times
+	// within territorySearchHotWindow
+	territorySearchHotThreshold = 3
+	territorySearchHotWindow    = 10 * time.Minute
+	territorySearchCacheTTL     = 6 * time.Hour
+)
+
+// territoryAbbreviations expands common shorthand in search queries
+var territoryAbbreviations = map[string][]string{
+	"kab":     {"kabupaten"},
+	"kt":      {"kota"},
+	"adm":     {"administrasi"},
+	"jkt":     {"jakarta"},
+	"jakpus":  {"jakarta", "pusat"},
+	"jaksel":  {"jakarta", "selatan"},
+	"jakbar":  {"jakarta", "barat"},
+	"jaktim":  {"jakarta", "timur"},
+	"jakut":   {"jakarta", "utara"},
+	"jabar":   {"jawa", "barat"},
+	"jateng":  {"jawa", "tengah"},
+	"jatim":   {"jawa", "timur"},
+	"sumut":   {"sumatera", "utara"},
+	"sumbar":  {"sumatera", "barat"},
+	"sumsel":  {"sumatera", "selatan"},
+	"kalbar":  {"kalimantan", "barat"},
+	"kalteng": {"kalimantan", "tengah"},
+	"kalsel":  {"kalimantan", "selatan"},
+	"kaltim":  {"kalimantan", "timur"},
+	"kaltara": {"kalimantan", "utara"},
+	"sulut":   {"sulawesi", "utara"},
+	"sulteng": {"sulawesi", "tengah"},
+	"sulsel":  {"sulawesi", "selatan"},
+	"sultra":  {"sulawesi", "tenggara"},
+	"sulbar":  {"sulawesi", "barat"},
+	"ntb":     {"nusa", "tenggara", "barat"},
+	"ntt":     {"nusa", "tenggara", "timur"},
+	"diy":     {"yogyakarta"},
+	"jogja":   {"yogyakarta"},
+	"yogya":   {"yogyakarta"},
+	"tangsel": {"tangerang", "selatan"},
+	"bdg":     {"bandung"},
+	"sby":     {"surabaya"},
+	"smg":     {"semarang"},
+	"bks":     {"bekasi"},
+	"bgr":     {"bogor"},
+}
+
+// territoryStopWords are level designations users type but that district and
+// village names do not contain
+var territoryStopWords = map[string]bool{
+	"kec": true, "kecamatan": true,
+	"kel": true, "kelurahan": true, "desa": true, "ds": true,
+	"prov": true, "provinsi": true, "propinsi": true,
+}
+
 // TerritoryService handles territory business logic
 type TerritoryService struct {
-	repo repository.TerritoryRepository
+	repo        repository.TerritoryRepository
+	redisClient *redis.Client
 }
 
 // NewTerritoryService creates a new territory service
-func NewTerritoryService(repo repository.TerritoryRepository) *TerritoryService {
-	return &TerritoryService{repo: repo}
+func NewTerritoryService(repo repository.TerritoryRepository, redisClient *redis.Client) *TerritoryService {
+	return &TerritoryService{repo: repo, redisClient: redisClient}
 }
 
 // GetProvinces returns all provinces
@@ -99,3 +163,115 @@ func (s *TerritoryService) SearchByPostalCode(ctx context.Context, postalCode st
 
 	return results, nil
 }
+
+// Search returns territories at any level matching a free-text query, best
+// match first, each with its full hierarchy
+func (s *TerritoryService) Search(ctx context.Context, query string, limit int) ([]*domain.TerritorySearchResult, error) {
+	terms := normalizeTerritoryQuery(query)
+	text := strings.Join(terms, " ")
+	if len(text) < territorySearchMinLength {
+		return nil, domain.ErrValidationFailed("Kata kunci minimal 2 karakter")
+	}
+
+	if limit <= 0 {
+		limit = territorySearchDefaultLimit
+	}
+	if limit > territorySearchMaxLimit {
+		limit = territorySearchMaxLimit
+	}
+
+	cacheKey := redis.TerritorySearchKey(text, limit)
+	if s.redisClient != nil {
+		var cached []*domain.TerritorySearchResult
+		if err := s.redisClient.GetJSON(ctx, cacheKey, &cached); err == nil {
+			return cached, nil
+		}
+	}
+
+	results, err := s.repo.Search(ctx, text, territoryPrefixQuery(terms), limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		result.Label = territoryLabel(result)
+	}
+
+	s.cacheIfHot(ctx, text, cacheKey, results)
+	return results, nil
+}
+
+// cacheIfHot counts searches per query and caches the results of popular ones
+func (s *TerritoryService) cacheIfHot(ctx context.Context, text, cacheKey string, results []*domain.TerritorySearchResult) {
+	if s.redisClient == nil {
+		return
+	}
+
+	hitsKey := redis.TerritorySearchHitsKey(text)
+	hits, err := s.redisClient.Incr(ctx, hitsKey).Result()
+	if err != nil {
+		return
+	}
+	if hits == 1 {
+		s.redisClient.Expire(ctx, hitsKey, territorySearchHotWindow)
+	}
+	if hits < territorySearchHotThreshold {
+		return
+	}
+
+	if err := s.redisClient.SetJSON(ctx, cacheKey, results, territorySearchCacheTTL); err != nil {
+		slog.Warn("failed to cache territory search", slog.String("error", err.Error()))
+	}
+}
+
+// normalizeTerritoryQuery lowercases a query, strips punctuation, expands
+// abbreviations and drops level designations
+func normalizeTerritoryQuery(query string) []string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return ' '
+		}
+	}, query)
+
+	terms := []string{}
+	for _, word := range strings.Fields(cleaned) {
+		if territoryStopWords[word] {
+			continue
+		}
+		if expanded, ok := territoryAbbreviations[word]; ok {
+			terms = append(terms, expanded...)
+			continue
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// territoryPrefixQuery builds a tsquery requiring every term as a word prefix,
+// so partially typed words still match
+func territoryPrefixQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// territoryLabel joins a result's name with its ancestors, nearest first
+func territoryLabel(result *domain.TerritorySearchResult) string {
+	parts := []string{result.Name}
+	if result.District != nil && result.Level != domain.TerritoryLevelDistrict {
+		parts = append(parts, result.District.Name)
+	}
+	if result.City != nil && result.Level != domain.TerritoryLevelCity {
+		parts = append(parts, result.City.Name)
+	}
+	if result.Level != domain.TerritoryLevelProvince {
+		parts = append(parts, result.Province.Name)
+	}
+	return strings.Join(parts, ", ")
+}
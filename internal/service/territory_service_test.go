@@ -0,0 +1,29 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTerritoryQuery(t *testing.T) {
+	cases := map[string][]string{
+		"Kebayoran":          {"kebayoran"},
+		"Kab. Bogor":         {"kabupaten", "bogor"},
+		"Kota Bandung":       {"kota", "bandung"},
+		"kec kebayoran, jkt": {"kebayoran", "jakarta"},
+		"Jaksel":             {"jakarta", "selatan"},
+		"  ":                 {},
+	}
+	for query, want := range cases {
+		if got := normalizeTerritoryQuery(query); !reflect.DeepEqual(got, want) {
+			t.Errorf("normalizeTerritoryQuery(%q) = %q; want %q", query, got, want)
+		}
+	}
+}
+
+func TestTerritoryPrefixQuery(t *testing.T) {
+	got := territoryPrefixQuery([]string{"kebay", "jakarta"})
+	if want := "kebay:* & jakarta:*"; got != want {
+		t.Errorf("territoryPrefixQuery = %q; want %q", got, want)
+	}
+}
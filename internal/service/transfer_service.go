@@ -2,23 +2,31 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/GTDGit/PPOB_BE/internal/domain"
 	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
 	"github.com/GTDGit/PPOB_BE/internal/repository"
 	"github.com/GTDGit/PPOB_BE/pkg/hash"
+	"github.com/jmoiron/sqlx"
 )
 
 // TransferService handles transfer transaction business logic
 type TransferService struct {
-	transferRepo  repository.TransferRepository
-	balanceRepo   repository.BalanceRepository
-	refundRepo    repository.RefundRepository
-	userRepo      repository.UserRepository
-	productRepo   repository.ProductRepository
-	gerbangClient *gerbang.Client
+	transferRepo       repository.TransferRepository
+	balanceRepo        repository.BalanceRepository
+	refundRepo         repository.RefundRepository
+	userRepo           repository.UserRepository
+	productRepo        repository.ProductRepository
+	contactService     *ContactService
+	gerbangClient      *gerbang.Client
+	reservationService *ReservationService
+	maintenance        *MaintenanceService
+	limits             *AccountLimitService
 }
 
 // NewTransferService creates a new transfer service
@@ -28,15 +36,23 @@ func NewTransferService(
 	refundRepo repository.RefundRepository,
 	userRepo repository.UserRepository,
 	productRepo repository.ProductRepository,
+	contactService *ContactService,
 	gerbangClient *gerbang.Client,
+	reservationService *ReservationService,
+	maintenance *MaintenanceService,
+	limits *AccountLimitService,
 ) *TransferService {
 	return &TransferService{
-		transferRepo:  transferRepo,
-		balanceRepo:   balanceRepo,
-		refundRepo:    refundRepo,
-		userRepo:      userRepo,
-		productRepo:   productRepo,
-		gerbangClient: gerbangClient,
+		transferRepo:       transferRepo,
+		balanceRepo:        balanceRepo,
+		refundRepo:         refundRepo,
+		userRepo:           userRepo,
+		productRepo:        productRepo,
+		contactService:     contactService,
+		gerbangClient:      gerbangClient,
+		reservationService: reservationService,
+		maintenance:        maintenance,
+		limits:             limits,
 	}
 }
 
@@ -46,6 +62,7 @@ type TransferInquiryRequest struct {
 	BankCode      string
 	AccountNumber string
 	Amount        int64
+	Method        string
 }
 
 // Inquiry handles transfer inquiry
@@ -59,36 +76,67 @@ func (s *TransferService) Inquiry(ctx context.Context, req TransferInquiryReques
 		return nil, domain.ErrUnauthorizedError
 	}
 
-	// Check if user is KYC verified
-	if user.KYCStatus != "verified" {
-		return nil, domain.ErrKYCRequired
+	// Transfers are allowed only for KYC statuses with a limit
+	limit, err := s.transferLimit(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate amount
 	if req.Amount < 10000 {
 		return nil, domain.ErrValidationFailed("Minimum transfer amount is Rp10.000")
 	}
+	if req.Method != "" && !domain.IsValidTransferMethod(req.Method) {
+		return nil, domain.ErrValidationFailed("Metode transfer harus bifast, online, atau rtgs")
+	}
+
+	// Under maintenance: show the notice without asking Gerbang
+	if window := s.maintenance.Check(ctx, transferMaintenanceTargets(req.BankCode)...); window != nil {
+		return &domain.TransferInquiryResponse{
+			Destination: &domain.TransferDestinationInfo{
+				BankCode:      req.BankCode,
+				AccountNumber: req.AccountNumber,
+			},
+			Transfer: &domain.TransferAmountInfo{
+				Amount:          req.Amount,
+				AmountFormatted: formatCurrency(req.Amount),
+			},
+			Notices: []*domain.NoticeInfo{window.Notice()},
+		}, nil
+	}
+
+	// Quote the remaining limit before asking Gerbang
+	dayStart, monthStart := domain.TransferPeriodStart(time.Now())
+	usage, err := s.transferRepo.SumUsage(ctx, req.UserID, dayStart.Local(), monthStart.Local())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer usage: %w", err)
+	}
+	limitInfo, err := checkTransferLimit(limit, usage, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limits.CheckOutgoing(ctx, nil, req.UserID, req.Amount); err != nil {
+		return nil, err
+	}
 
 	// Call Gerbang API to validate account and get account name
 	inquiryResp, err := s.gerbangClient.TransferInquiry(ctx, req.BankCode, req.AccountNumber)
 	if err != nil {
+		if gerbang.IsProviderUnavailable(err) {
+			return nil, domain.ErrProviderUnavailable
+		}
 		return nil, fmt.Errorf("failed to inquiry account: %w", err)
 	}
 
-	// Get estimated fee from banks table
 	bank, err := s.productRepo.FindBankByCode(ctx, req.BankCode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bank info: %w", err)
 	}
 
-	// Use transfer fee from bank info as estimated admin fee
-	// Actual fee will be known during execute from Gerbang response
-	adminFee := int64(0)
-	if bank != nil {
-		adminFee = bank.TransferFee
-	} else {
-		// Fallback to default if bank not found
-		adminFee = calculateAdminFee(req.BankCode, req.Amount)
+	// The quoted fee is what the user pays, whatever Gerbang charges us
+	adminFee, method, feeRuleID, err := s.quoteFee(ctx, bank, req.BankCode, user.Tier, req.Amount, req.Method)
+	if err != nil {
+		return nil, err
 	}
 
 	totalPayment := req.Amount + adminFee
@@ -119,6 +167,8 @@ func (s *TransferService) Inquiry(ctx context.Context, req TransferInquiryReques
 		TotalPayment:     totalPayment,
 		GerbangInquiryID: &inquiryResp.InquiryID,
 		Fee:              adminFee, // Store estimated fee, actual fee from execute
+		Method:           &method,
+		FeeRuleID:        feeRuleID,
 		ExpiresAt:        time.Now().Add(30 * time.Minute),
 		CreatedAt:        time.Now(),
 	}
@@ -147,7 +197,9 @@ func (s *TransferService) Inquiry(ctx context.Context, req TransferInquiryReques
 			AdminFeeFormatted:     formatCurrency(adminFee),
 			TotalPayment:          totalPayment,
 			TotalPaymentFormatted: formatCurrency(totalPayment),
+			Method:                method,
 		},
+		Limits:      limitInfo,
 		PINRequired: false, // TODO: Get from user settings
 		Notices: []*domain.NoticeInfo{
 			{
@@ -184,6 +236,10 @@ func (s *TransferService) Inquiry(ctx context.Context, req TransferInquiryReques
 		response.Payment.ShortfallFormatted = &shortfallFormatted
 	}
 
+	if s.contactService != nil {
+		response.Suggestions = s.contactService.SuggestionsFor(ctx, req.UserID, domain.TransactionTypeTransfer)
+	}
+
 	return response, nil
 }
 
@@ -194,6 +250,7 @@ type TransferExecuteRequest struct {
 	Purpose   *string // Purpose code (01, 02, 03, 99) - default "99" if nil
 	Note      *string
 	PIN       *string
+	Contact   *ContactSaveRequest // Explicit "save as contact" request, nil when not asked
 }
 
 // Execute handles transfer execution with database transaction
@@ -221,9 +278,10 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 		return nil, domain.ErrUnauthorizedError
 	}
 
-	// Re-check KYC verification
-	if user.KYCStatus != "verified" {
-		return nil, domain.ErrKYCRequired
+	// Re-check the KYC-based limit
+	limit, err := s.transferLimit(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check for existing transaction (idempotency)
@@ -248,27 +306,17 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 		}
 	}
 
-	// Execute transfer in database transaction
-	var balanceBefore, balanceAfter int64
-	var referenceNumber string
-	transactionID := repository.NewUUID()
-
-	// Begin transaction
-	tx, err := s.transferRepo.BeginTx(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	if err := s.maintenance.Guard(ctx, transferMaintenanceTargets(inquiry.BankCode)...); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	// Lock balance and deduct atomically
-	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, req.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to lock balance: %w", err)
-	}
-	if balance == nil {
-		return nil, domain.ErrValidationFailed("Balance not found")
+	// Fail fast while the transfer circuit is open instead of reserving the balance
+	if !s.gerbangClient.Available(gerbang.EndpointTransfer) {
+		return nil, domain.ErrProviderUnavailable
 	}
 
+	transactionID := repository.NewUUID()
+
 	// Prepare purpose code (default "99" if not provided)
 	purposeCode := "99"
 	if req.Purpose != nil && *req.Purpose != "" {
@@ -281,6 +329,62 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 		remark = *req.Note
 	}
 
+	// Reserve the estimated total and record the transfer as processing, then
+	// commit so no balance lock is held while Gerbang is called
+	tx, err := s.transferRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, balance, err := s.reservationService.Reserve(ctx, tx, req.UserID, inquiry.TotalPayment, domain.TransactionTypeTransfer, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	balanceBefore := balance.Amount + inquiry.TotalPayment
+
+	// Reserve locked the balance row, so concurrent transfers of the user wait
+	// here and see this one once it commits
+	dayStart, monthStart := domain.TransferPeriodStart(time.Now())
+	usage, err := s.transferRepo.SumUsageWithTx(ctx, tx, req.UserID, dayStart.Local(), monthStart.Local())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer usage: %w", err)
+	}
+	if _, err := checkTransferLimit(limit, usage, inquiry.Amount); err != nil {
+		return nil, err
+	}
+	if err := s.limits.CheckOutgoing(ctx, tx, req.UserID, inquiry.TotalPayment); err != nil {
+		return nil, err
+	}
+
+	transaction := &domain.TransferTransaction{
+		ID:            transactionID,
+		UserID:        req.UserID,
+		InquiryID:     req.InquiryID,
+		Status:        domain.TransactionProcessing,
+		BankCode:      inquiry.BankCode,
+		BankName:      inquiry.BankName,
+		AccountNumber: inquiry.AccountNumber,
+		AccountName:   inquiry.AccountName,
+		Amount:        inquiry.Amount,
+		AdminFee:      inquiry.AdminFee,
+		TotalPayment:  inquiry.TotalPayment,
+		Note:          req.Note,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  balance.Amount,
+		Purpose:       &purposeCode,
+		Fee:           inquiry.AdminFee,
+		Method:        inquiry.Method,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := s.transferRepo.CreateTransactionWithTx(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	// Call Gerbang API to execute transfer
 	gerbangReq := gerbang.GerbangTransferExecuteRequest{
 		ReferenceID:   transactionID,
@@ -289,40 +393,41 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 		AccountNumber: inquiry.AccountNumber,
 		AccountName:   inquiry.AccountName,
 		Amount:        inquiry.Amount,
+		TransferType:  gerbangTransferType(inquiry.Method),
 		Purpose:       purposeCode,
 		Remark:        remark,
 	}
 
+	if err := s.reservationService.MarkProviderCalled(ctx, reservation); err != nil {
+		if abortErr := s.abortTransfer(ctx, transactionID); abortErr != nil {
+			return nil, abortErr
+		}
+		return nil, err
+	}
 	gerbangResp, err := s.gerbangClient.TransferExecute(ctx, gerbangReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute transfer via Gerbang: %w", err)
-	}
-
-	// Use actual fee from Gerbang response
-	actualFee := gerbangResp.Fee
-	totalDeduction := inquiry.Amount + actualFee
-
-	// Check balance sufficiency with actual fee
-	if balance.Amount < totalDeduction {
-		return nil, domain.ErrInsufficientBalance
-	}
-
-	// Store balance before deduction
-	balanceBefore = balance.Amount
-	balance.Amount -= totalDeduction
+		if transferRejected(err) {
+			if abortErr := s.abortTransfer(ctx, transactionID); abortErr != nil {
+				return nil, abortErr
+			}
+			if gerbang.IsProviderUnavailable(err) {
+				return nil, domain.ErrProviderUnavailable
+			}
+			return nil, fmt.Errorf("failed to execute transfer via Gerbang: %w", err)
+		}
 
-	// Update balance within transaction
-	if err := s.balanceRepo.UpdateWithTx(ctx, tx, balance); err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+		// No definite answer: Gerbang may still send the money, so the funds
+		// stay charged and the webhook decides the final status
+		slog.Warn("transfer outcome unknown, keeping transaction processing",
+			slog.String("transaction_id", transactionID),
+			slog.String("error", err.Error()),
+		)
+		gerbangResp = &gerbang.GerbangTransferExecuteResponse{Status: "Processing", Fee: inquiry.AdminFee}
 	}
-	balanceAfter = balance.Amount
-
-	// Get reference number from Gerbang response
-	referenceNumber = gerbangResp.TransferID
 
-	// Create transaction record within DB transaction
-	completedAt := time.Now()
-	gerbangTransferID := gerbangResp.TransferID
+	// The user pays the quoted fee; Gerbang's fee is kept as our cost
+	actualFee := gerbangResp.Fee
+	totalDeduction := inquiry.TotalPayment
 
 	// Determine status from Gerbang response
 	status := domain.TransactionProcessing
@@ -332,32 +437,56 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 		status = domain.TransactionFailed
 	}
 
-	transaction := &domain.TransferTransaction{
-		ID:                transactionID,
-		UserID:            req.UserID,
-		InquiryID:         req.InquiryID,
-		Status:            status,
-		BankCode:          inquiry.BankCode,
-		BankName:          inquiry.BankName,
-		AccountNumber:     inquiry.AccountNumber,
-		AccountName:       inquiry.AccountName,
-		Amount:            inquiry.Amount,
-		AdminFee:          inquiry.AdminFee, // Keep estimate for reference
-		TotalPayment:      totalDeduction,   // Actual total with real fee
-		Note:              req.Note,
-		BalanceBefore:     balanceBefore,
-		BalanceAfter:      balanceAfter,
-		ReferenceNumber:   &referenceNumber,
-		GerbangTransferID: &gerbangTransferID,
-		Purpose:           &purposeCode,
-		Fee:               actualFee, // Actual fee from Gerbang
-		CompletedAt:       &completedAt,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
+	completedAt := time.Now()
+	transaction.Status = status
+	transaction.Fee = actualFee
+	transaction.TotalPayment = totalDeduction
+	transaction.CompletedAt = &completedAt
+	if gerbangResp.TransferID != "" {
+		referenceNumber := gerbangResp.TransferID
+		gerbangTransferID := gerbangResp.TransferID
+		transaction.ReferenceNumber = &referenceNumber
+		transaction.GerbangTransferID = &gerbangTransferID
+	}
+
+	// Settle (or, when Gerbang failed the transfer, release) the reservation
+	// and store the result
+	tx, err = s.transferRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if err := s.transferRepo.CreateTransactionWithTx(ctx, tx, transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	reservation, err = s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypeTransfer, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation != nil {
+		if status == domain.TransactionFailed {
+			balance, err = s.reservationService.Release(ctx, tx, reservation)
+			transaction.TotalPayment = inquiry.TotalPayment
+		} else {
+			balance, err = s.reservationService.Settle(ctx, tx, reservation, totalDeduction)
+		}
+		if err != nil {
+			return nil, err
+		}
+		transaction.BalanceAfter = balance.Amount
+	} else {
+		// A webhook resolved the transfer first; keep the status it set
+		current, err := s.transferRepo.FindTransactionByID(ctx, transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if current != nil {
+			transaction.Status = current.Status
+			transaction.TotalPayment = current.TotalPayment
+			status = current.Status
+		}
+	}
+
+	if err := s.transferRepo.UpdateTransactionResultWithTx(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
 
 	// Commit transaction
@@ -365,6 +494,19 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if s.contactService != nil && status != domain.TransactionFailed {
+		target := transferContactTarget(transaction)
+		if req.Contact != nil {
+			target.Name = req.Contact.Name
+			target.Save = true
+		}
+		if status == domain.TransactionSuccess {
+			s.contactService.RecordUsage(ctx, target)
+		} else if target.Save {
+			s.contactService.SaveTarget(ctx, target)
+		}
+	}
+
 	// Build response
 	completedAtStr := completedAt.Format(time.RFC3339)
 	response := &domain.TransferExecuteResponse{
@@ -385,14 +527,14 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 			Note:            req.Note,
 		},
 		Payment: &domain.TransferPaymentInfo{
-			TotalPayment:          totalDeduction,
-			TotalPaymentFormatted: formatCurrency(totalDeduction),
+			TotalPayment:          transaction.TotalPayment,
+			TotalPaymentFormatted: formatCurrency(transaction.TotalPayment),
 			BalanceBefore:         balanceBefore,
-			BalanceAfter:          balanceAfter,
-			BalanceAfterFormatted: formatCurrency(balanceAfter),
+			BalanceAfter:          transaction.BalanceAfter,
+			BalanceAfterFormatted: formatCurrency(transaction.BalanceAfter),
 		},
 		Receipt: &domain.ReceiptInfo{
-			ReferenceNumber: &referenceNumber,
+			ReferenceNumber: transaction.ReferenceNumber,
 		},
 		Message: &domain.MessageInfo{
 			Title:    getStatusTitle(status),
@@ -403,6 +545,102 @@ func (s *TransferService) Execute(ctx context.Context, req TransferExecuteReques
 	return response, nil
 }
 
+// transferMaintenanceTargets lists what a transfer sells: the transfer
+// service and the destination bank
+func transferMaintenanceTargets(bankCode string) []domain.MaintenanceTarget {
+	return []domain.MaintenanceTarget{
+		{Scope: domain.MaintenanceScopeService, Target: domain.TransactionTypeTransfer},
+		{Scope: domain.MaintenanceScopeBank, Target: bankCode},
+	}
+}
+
+// abortTransfer releases the reservation of a transfer Gerbang rejected and
+// removes its processing transaction so the inquiry can be executed again
+func (s *TransferService) abortTransfer(ctx context.Context, transactionID string) error {
+	tx, err := s.transferRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypeTransfer, transactionID)
+	if err != nil {
+		return err
+	}
+	if reservation == nil {
+		return nil // Already resolved by a webhook
+	}
+
+	if _, err := s.reservationService.Release(ctx, tx, reservation); err != nil {
+		return err
+	}
+	if err := s.transferRepo.DeleteTransactionWithTx(ctx, tx, transactionID); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// transferRejected reports whether Gerbang definitely did not accept a
+// transfer: the circuit was open or the request was refused with a 4xx.
+// 408, 409 and 429 are not refusals: the transfer may already be in flight,
+// so it stays processing for the webhook or status lookup.
+func transferRejected(err error) bool {
+	if gerbang.IsProviderUnavailable(err) {
+		return true
+	}
+	var gerr *gerbang.Error
+	if !errors.As(err, &gerr) || gerr.Code < 400 || gerr.Code >= 500 {
+		return false
+	}
+	switch gerr.Code {
+	case http.StatusRequestTimeout, gerbang.ErrCodeConflict, http.StatusTooManyRequests:
+		return false
+	default:
+		return true
+	}
+}
+
+// ReservationOutcome reports what Gerbang did with the reserved transfer,
+// from the recorded result or a status lookup. Implements ReservationResolver.
+func (s *TransferService) ReservationOutcome(ctx context.Context, reservation *domain.BalanceReservation) (string, error) {
+	transaction, err := s.transferRepo.FindTransactionByID(ctx, reservation.ReferenceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction == nil {
+		return ReservationOutcomeFailed, nil
+	}
+	switch transaction.Status {
+	case domain.TransactionSuccess:
+		return ReservationOutcomeCharged, nil
+	case domain.TransactionFailed:
+		return ReservationOutcomeFailed, nil
+	}
+
+	// Without Gerbang's transfer ID only the webhook can tell
+	if transaction.GerbangTransferID == nil || *transaction.GerbangTransferID == "" {
+		return ReservationOutcomeUnknown, nil
+	}
+	result, err := s.gerbangClient.GetTransferStatus(ctx, *transaction.GerbangTransferID)
+	if err != nil {
+		return "", err
+	}
+	return supplierOutcome(result.Status), nil
+}
+
+// AbortReservation removes the processing transaction of a released
+// reservation. Implements ReservationResolver.
+func (s *TransferService) AbortReservation(ctx context.Context, tx *sqlx.Tx, reservation *domain.BalanceReservation) error {
+	if err := s.transferRepo.DeleteTransactionWithTx(ctx, tx, reservation.ReferenceID); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+	return nil
+}
+
 // Helper to get status title
 func getStatusTitle(status string) string {
 	switch status {
@@ -576,14 +814,71 @@ func getBankIcon(bankCode string) string {
 	return "https://cdn.ppob.id/banks/default.png"
 }
 
-// calculateAdminFee calculates admin fee for transfer
-func calculateAdminFee(bankCode string, amount int64) int64 {
-	// Same bank (BCA to BCA example)
-	if bankCode == "014" {
-		return 0 // Free for same bank
+// transferLimit returns the transfer limit of the user's KYC status, or
+// ErrKYCRequired when the status may not transfer
+func (s *TransferService) transferLimit(ctx context.Context, user *domain.User) (*domain.TransferLimit, error) {
+	limit, err := s.transferRepo.FindLimit(ctx, user.KYCStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer limit: %w", err)
+	}
+	if !limit.Allowed() {
+		return nil, domain.ErrKYCRequired
 	}
-	// Other banks
-	return 6500
+	return limit, nil
+}
+
+// quoteFee prices a transfer from the fee schedule. Without a matching rule it
+// falls back to the bank's synced transfer fee over the online network.
+func (s *TransferService) quoteFee(ctx context.Context, bank *domain.Bank, bankCode, tier string, amount int64, method string) (int64, string, *string, error) {
+	rules, err := s.transferRepo.FindActiveFeeRules(ctx)
+	if err != nil {
+		return 0, "", nil, fmt.Errorf("failed to get transfer fee rules: %w", err)
+	}
+	if rule := domain.SelectTransferFee(rules, bankCode, tier, amount, method); rule != nil {
+		return rule.Fee, rule.Method, &rule.ID, nil
+	}
+	if bank == nil || (method != "" && method != domain.TransferMethodOnline) {
+		return 0, "", nil, domain.ErrMethodUnavailable
+	}
+	return bank.TransferFee, domain.TransferMethodOnline, nil, nil
+}
+
+// gerbangTransferType maps the quoted transfer method to the Gerbang rail, so
+// the transfer goes out over the method the user was charged for
+func gerbangTransferType(method *string) string {
+	if method == nil {
+		return ""
+	}
+	switch *method {
+	case domain.TransferMethodBIFast:
+		return gerbang.TransferTypeBIFast
+	case domain.TransferMethodOnline:
+		return gerbang.TransferTypeOnline
+	case domain.TransferMethodRTGS:
+		return gerbang.TransferTypeRTGS
+	default:
+		return ""
+	}
+}
+
+// checkTransferLimit rejects an amount above the remaining daily or monthly
+// limit and returns the limits as quoted to the user
+func checkTransferLimit(limit *domain.TransferLimit, usage domain.TransferUsage, amount int64) (*domain.TransferLimitInfo, error) {
+	daily, monthly := limit.Remaining(usage)
+	if amount > daily {
+		return nil, domain.ErrTransferLimitExceeded(fmt.Sprintf("Nominal melebihi sisa limit transfer harian (%s)", formatCurrency(daily)))
+	}
+	if amount > monthly {
+		return nil, domain.ErrTransferLimitExceeded(fmt.Sprintf("Nominal melebihi sisa limit transfer bulanan (%s)", formatCurrency(monthly)))
+	}
+	return &domain.TransferLimitInfo{
+		DailyLimit:                limit.DailyLimit,
+		DailyRemaining:            daily,
+		DailyRemainingFormatted:   formatCurrency(daily),
+		MonthlyLimit:              limit.MonthlyLimit,
+		MonthlyRemaining:          monthly,
+		MonthlyRemainingFormatted: formatCurrency(monthly),
+	}, nil
 }
 
 // getMockAccountName returns mock account name for testing
@@ -627,6 +922,26 @@ func (s *TransferService) Refund(ctx context.Context, transactionID, reason stri
 		return domain.ErrValidationFailed("Transaksi tidak dapat dikembalikan")
 	}
 
+	// Funds returned by releasing the reservation were never charged
+	released, err := s.reservationService.Released(ctx, domain.TransactionTypeTransfer, transaction.ID)
+	if err != nil {
+		return err
+	}
+	if released {
+		return domain.ErrValidationFailed("Transaksi tidak dapat dikembalikan")
+	}
+
+	// Settle a transfer still in flight so the webhook cannot release it again
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypeTransfer, transaction.ID)
+	if err != nil {
+		return err
+	}
+	if reservation != nil {
+		if _, err := s.reservationService.Settle(ctx, tx, reservation, reservation.Amount); err != nil {
+			return err
+		}
+	}
+
 	// Lock and restore balance
 	balance, err := s.balanceRepo.FindByUserIDForUpdate(ctx, tx, transaction.UserID)
 	if err != nil {
@@ -702,6 +1017,12 @@ func (s *TransferService) HandleWebhook(ctx context.Context, webhookData *gerban
 	}
 	defer tx.Rollback()
 
+	// Funds of a transfer whose Gerbang call has not returned yet are still reserved
+	reservation, err := s.reservationService.HeldForUpdate(ctx, tx, domain.TransactionTypeTransfer, transaction.ID)
+	if err != nil {
+		return err
+	}
+
 	// Update transaction based on webhook event
 	now := time.Now()
 	var newStatus string
@@ -739,6 +1060,21 @@ func (s *TransferService) HandleWebhook(ctx context.Context, webhookData *gerban
 		return fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
+	if reservation != nil {
+		switch newStatus {
+		case domain.TransactionSuccess:
+			if _, err := s.reservationService.Settle(ctx, tx, reservation, reservation.Amount); err != nil {
+				return err
+			}
+		case domain.TransactionFailed:
+			// The reserved funds were never charged, releasing them is the refund
+			if _, err := s.reservationService.Release(ctx, tx, reservation); err != nil {
+				return err
+			}
+			refundNeeded = false
+		}
+	}
+
 	// If transfer failed, refund balance to user
 	if refundNeeded {
 		if s.refundRepo != nil {
@@ -800,5 +1136,27 @@ func (s *TransferService) HandleWebhook(ctx context.Context, webhookData *gerban
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if newStatus == domain.TransactionSuccess && s.contactService != nil {
+		s.contactService.RecordUsage(ctx, transferContactTarget(transaction))
+	}
+
 	return nil
 }
+
+// transferContactTarget builds the contact target for a transfer destination
+func transferContactTarget(transaction *domain.TransferTransaction) ContactTarget {
+	metadata := map[string]interface{}{
+		"bankCode": transaction.BankCode,
+		"bankName": transaction.BankName,
+	}
+	if transaction.AccountName != "" {
+		metadata["accountName"] = transaction.AccountName
+	}
+
+	return ContactTarget{
+		UserID:      transaction.UserID,
+		ServiceType: domain.TransactionTypeTransfer,
+		Value:       transaction.AccountNumber,
+		Metadata:    metadata,
+	}
+}
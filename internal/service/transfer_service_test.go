@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/GTDGit/PPOB_BE/internal/domain"
+	"github.com/GTDGit/PPOB_BE/internal/external/gerbang"
+)
+
+func TestTransferRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"open circuit", fmt.Errorf("gerbang: %w", gerbang.ErrProviderUnavailable), true},
+		{"bad request", &gerbang.Error{Code: 400, Message: "invalid account"}, true},
+		{"insufficient funds", &gerbang.Error{Code: 422, Message: "saldo tidak cukup"}, true},
+		{"request timeout", &gerbang.Error{Code: 408, Message: "timeout"}, false},
+		{"conflict", &gerbang.Error{Code: 409, Message: "duplicate reference"}, false},
+		{"rate limited", &gerbang.Error{Code: 429, Message: "too many requests"}, false},
+		{"server error", &gerbang.Error{Code: 502, Message: "bad gateway"}, false},
+		{"network error", errors.New("connection reset by peer"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transferRejected(tt.err); got != tt.want {
+				t.Errorf("transferRejected(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGerbangTransferType(t *testing.T) {
+	method := func(m string) *string { return &m }
+	tests := map[*string]string{
+		method(domain.TransferMethodBIFast): gerbang.TransferTypeBIFast,
+		method(domain.TransferMethodOnline): gerbang.TransferTypeOnline,
+		method(domain.TransferMethodRTGS):   gerbang.TransferTypeRTGS,
+		method("sknbi"):                     "",
+		nil:                                 "",
+	}
+	for m, want := range tests {
+		if got := gerbangTransferType(m); got != want {
+			t.Errorf("gerbangTransferType(%v) = %q, want %q", m, got, want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+-- Migration: 041_add_contact_metadata
+-- Description: Store contact metadata as JSON and index contacts for transaction suggestions
+-- Created: 2026-10-18
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+-- Backfill metadata from the legacy per-type columns
+UPDATE contacts
+SET metadata = jsonb_strip_nulls(jsonb_build_object(
+    'operator', operator_id,
+    'operatorName', operator_name,
+    'bankCode', bank_code,
+    'bankName', bank_name,
+    'accountName', account_name,
+    'customerName', customer_name
+))
+WHERE metadata IS NULL;
+
+UPDATE contacts SET usage_count = 0 WHERE usage_count IS NULL;
+
+-- Suggestion lookups: recent and most frequently used targets per type
+CREATE INDEX IF NOT EXISTS idx_contacts_user_type_last_used
+ON contacts(user_id, type, last_used_at DESC NULLS LAST);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_user_type_usage
+ON contacts(user_id, type, usage_count DESC);
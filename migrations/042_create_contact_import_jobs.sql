@@ -0,0 +1,24 @@
+-- Migration: 042_create_contact_import_jobs
+-- Description: Track bulk contact imports processed in the background
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS contact_import_jobs (
+    id VARCHAR(36) PRIMARY KEY,                          -- cij_xxx
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing',    -- processing, completed, failed
+    format VARCHAR(10) NOT NULL,                         -- csv, xlsx
+    file_name VARCHAR(255),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    invalid_count INTEGER NOT NULL DEFAULT 0,
+    results JSONB,                                       -- per-row validation results
+    error_message TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_import_jobs_user
+ON contact_import_jobs(user_id, created_at DESC);
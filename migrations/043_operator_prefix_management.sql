@@ -0,0 +1,14 @@
+-- Migration: 043_operator_prefix_management
+-- Description: Operator prefixes are managed from the admin console; seed shared ranges and grant access
+-- Created: 2026-10-18
+
+-- 0851 is issued to both Telkomsel and by.U; detection reports both with split confidence
+UPDATE operators
+SET prefixes = (prefixes::jsonb || '["0851"]'::jsonb)::text,
+    updated_at = NOW()
+WHERE id = 'telkomsel'
+  AND NOT (prefixes::jsonb ? '0851');
+
+INSERT INTO admin_role_permissions (role_id, permission_key) VALUES
+('product_content', 'reference.manage')
+ON CONFLICT DO NOTHING;
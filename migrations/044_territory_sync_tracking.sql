@@ -0,0 +1,20 @@
+-- Migration: 044_territory_sync_tracking
+-- Description: Soft-delete territory codes removed upstream and track per-level sync progress for resumable syncs
+-- Created: 2026-10-18
+
+ALTER TABLE provinces ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE cities ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE districts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE sub_districts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE postal_codes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+ALTER TABLE territory_sync_log ADD COLUMN IF NOT EXISTS run_id VARCHAR(36);
+ALTER TABLE territory_sync_log ADD COLUMN IF NOT EXISTS inserted_count INT NOT NULL DEFAULT 0;
+ALTER TABLE territory_sync_log ADD COLUMN IF NOT EXISTS updated_count INT NOT NULL DEFAULT 0;
+ALTER TABLE territory_sync_log ADD COLUMN IF NOT EXISTS deleted_count INT NOT NULL DEFAULT 0;
+ALTER TABLE territory_sync_log ADD COLUMN IF NOT EXISTS checkpoint VARCHAR(10);
+
+CREATE INDEX IF NOT EXISTS idx_territory_sync_log_run_id ON territory_sync_log(run_id);
+
+COMMENT ON COLUMN territory_sync_log.run_id IS 'Groups the per-level logs of one hierarchical sync run';
+COMMENT ON COLUMN territory_sync_log.checkpoint IS 'Last parent code fully synced within the level, used to resume';
@@ -0,0 +1,90 @@
+-- Migration: 045_create_territory_search
+-- Description: Flattened territory search index (trigram + full text) for typeahead across all levels
+-- Created: 2026-10-18
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Lowercases a territory name and expands the abbreviations used in source
+-- data ("KAB. BOGOR", "KOTA ADM. JAKARTA SELATAN") so they match expanded queries
+CREATE OR REPLACE FUNCTION territory_search_normalize(name TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(
+        regexp_replace(
+            regexp_replace(LOWER(name), '\mkab\.\s*', 'kabupaten ', 'g'),
+            '\madm\.\s*', 'administrasi ', 'g'),
+        '[^a-z0-9 ]+', ' ', 'g')
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- One row per province, city, district and sub-district with its full hierarchy.
+-- search_path holds the normalized names of the row and all its ancestors (plus
+-- postal codes for sub-districts) so multi-word queries like "kebayoran jakarta"
+-- match. Refreshed by the territory sync job.
+CREATE MATERIALIZED VIEW IF NOT EXISTS territory_search AS
+SELECT
+    'province'::VARCHAR(20) AS level,
+    1 AS level_rank,
+    p.code,
+    p.name,
+    p.code AS province_code, p.name AS province_name,
+    NULL::VARCHAR(4) AS city_code, NULL::VARCHAR(100) AS city_name,
+    NULL::VARCHAR(6) AS district_code, NULL::VARCHAR(100) AS district_name,
+    NULL::TEXT AS postal_codes,
+    territory_search_normalize(p.name) AS search_name,
+    territory_search_normalize(p.name) AS search_path
+FROM provinces p
+WHERE p.deleted_at IS NULL
+UNION ALL
+SELECT
+    'city', 2,
+    c.code,
+    c.name,
+    p.code, p.name,
+    c.code, c.name,
+    NULL, NULL,
+    NULL,
+    territory_search_normalize(c.name),
+    territory_search_normalize(c.name || ' ' || p.name)
+FROM cities c
+JOIN provinces p ON p.code = c.province_code AND p.deleted_at IS NULL
+WHERE c.deleted_at IS NULL
+UNION ALL
+SELECT
+    'district', 3,
+    d.code,
+    d.name,
+    p.code, p.name,
+    c.code, c.name,
+    d.code, d.name,
+    NULL,
+    territory_search_normalize(d.name),
+    territory_search_normalize(d.name || ' ' || c.name || ' ' || p.name)
+FROM districts d
+JOIN cities c ON c.code = d.city_code AND c.deleted_at IS NULL
+JOIN provinces p ON p.code = c.province_code AND p.deleted_at IS NULL
+WHERE d.deleted_at IS NULL
+UNION ALL
+SELECT
+    'sub_district', 4,
+    sd.code,
+    sd.name,
+    p.code, p.name,
+    c.code, c.name,
+    d.code, d.name,
+    pc.postal_codes,
+    territory_search_normalize(sd.name),
+    territory_search_normalize(sd.name || ' ' || d.name || ' ' || c.name || ' ' || p.name || COALESCE(' ' || pc.postal_codes, ''))
+FROM sub_districts sd
+JOIN districts d ON d.code = sd.district_code AND d.deleted_at IS NULL
+JOIN cities c ON c.code = d.city_code AND c.deleted_at IS NULL
+JOIN provinces p ON p.code = c.province_code AND p.deleted_at IS NULL
+LEFT JOIN LATERAL (
+    SELECT string_agg(DISTINCT postal_code, ' ') AS postal_codes
+    FROM postal_codes
+    WHERE sub_district_code = sd.code AND deleted_at IS NULL
+) pc ON TRUE
+WHERE sd.deleted_at IS NULL;
+
+-- Unique index is required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX IF NOT EXISTS idx_territory_search_level_code ON territory_search(level, code);
+CREATE INDEX IF NOT EXISTS idx_territory_search_name_trgm ON territory_search USING GIN (search_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_territory_search_path_trgm ON territory_search USING GIN (search_path gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_territory_search_path_fts ON territory_search USING GIN (to_tsvector('simple', search_path));
@@ -0,0 +1,38 @@
+-- Migration: 046_create_pricing_rules
+-- Description: Keep supplier cost separate from selling price and derive the selling price from markup rules
+-- Created: 2026-10-18
+
+-- Supplier cost as reported by Gerbang; price/admin become our selling price
+ALTER TABLE products ADD COLUMN IF NOT EXISTS supplier_price BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS supplier_admin BIGINT NOT NULL DEFAULT 0;
+
+-- Until now price/admin held the supplier values, so they are the best known cost
+UPDATE products
+SET supplier_price = price,
+    supplier_admin = admin
+WHERE supplier_price = 0 AND supplier_admin = 0;
+
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id VARCHAR(36) PRIMARY KEY,                                -- prc_xxx
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(50) NOT NULL DEFAULT '',                  -- empty matches every category
+    brand VARCHAR(50) NOT NULL DEFAULT '',                     -- empty matches every brand
+    sku_code VARCHAR(50) NOT NULL DEFAULT '',                  -- empty matches every SKU
+    user_tier VARCHAR(20) NOT NULL DEFAULT '',                 -- empty is the base price shown to every tier
+    markup_type VARCHAR(20) NOT NULL DEFAULT 'fixed',          -- fixed, percentage
+    markup_value NUMERIC(14,4) NOT NULL DEFAULT 0,             -- rupiah for fixed, percent for percentage
+    admin_markup BIGINT NOT NULL DEFAULT 0,                    -- added to the supplier admin fee
+    rounding_step BIGINT NOT NULL DEFAULT 0,                   -- 0 disables rounding
+    rounding_mode VARCHAR(10) NOT NULL DEFAULT 'up',           -- up, nearest, down
+    min_price BIGINT NOT NULL DEFAULT 0,                       -- floor, 0 disables
+    max_price BIGINT NOT NULL DEFAULT 0,                       -- ceiling, 0 disables
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT pricing_rules_markup_type_check CHECK (markup_type IN ('fixed', 'percentage')),
+    CONSTRAINT pricing_rules_rounding_mode_check CHECK (rounding_mode IN ('up', 'nearest', 'down'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_active ON pricing_rules(is_active);
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_sku ON pricing_rules(sku_code) WHERE sku_code <> '';
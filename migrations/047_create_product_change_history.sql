@@ -0,0 +1,31 @@
+-- Migration: 047_create_product_change_history
+-- Description: Record product price and availability changes detected by sync and repricing
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS product_change_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id VARCHAR(36),
+    sku_code VARCHAR(50) NOT NULL,
+    source VARCHAR(20) NOT NULL,                 -- sync, pricing_rule
+    change_type VARCHAR(20) NOT NULL,            -- created, updated
+    changed_fields TEXT NOT NULL DEFAULT '',     -- comma separated: supplier_price, supplier_admin, price, admin, commission, is_active
+    old_supplier_price BIGINT,
+    new_supplier_price BIGINT NOT NULL DEFAULT 0,
+    old_supplier_admin BIGINT,
+    new_supplier_admin BIGINT NOT NULL DEFAULT 0,
+    old_price BIGINT,
+    new_price BIGINT NOT NULL DEFAULT 0,
+    old_admin BIGINT,
+    new_admin BIGINT NOT NULL DEFAULT 0,
+    old_commission BIGINT,
+    new_commission BIGINT NOT NULL DEFAULT 0,
+    old_is_active BOOLEAN,
+    new_is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    alerted BOOLEAN NOT NULL DEFAULT FALSE,
+    alert_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_change_history_sku ON product_change_history(sku_code, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_product_change_history_created ON product_change_history(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_product_change_history_alerted ON product_change_history(created_at DESC) WHERE alerted = TRUE;
@@ -0,0 +1,37 @@
+-- Migration: 048_create_suppliers
+-- Description: Supplier registry and per-SKU routing table for multi-supplier failover
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    code VARCHAR(30) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO suppliers (code, name, is_active) VALUES
+    ('gerbang', 'Gerbang', TRUE),
+    ('secondary', 'Secondary Supplier', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+-- Routes map our SKU to a supplier's SKU and cost. A SKU without a gerbang
+-- route is still routed to gerbang at the product's synced supplier price.
+CREATE TABLE IF NOT EXISTS supplier_products (
+    id VARCHAR(36) PRIMARY KEY,
+    supplier_code VARCHAR(30) NOT NULL REFERENCES suppliers(code) ON DELETE CASCADE,
+    sku_code VARCHAR(50) NOT NULL,
+    supplier_sku_code VARCHAR(50) NOT NULL,
+    cost_price BIGINT NOT NULL DEFAULT 0,
+    priority INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (supplier_code, sku_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_products_sku ON supplier_products(sku_code) WHERE is_active = TRUE;
+
+ALTER TABLE prepaid_transactions ADD COLUMN IF NOT EXISTS supplier_code VARCHAR(30);
+ALTER TABLE postpaid_inquiries ADD COLUMN IF NOT EXISTS supplier_code VARCHAR(30);
+ALTER TABLE postpaid_transactions ADD COLUMN IF NOT EXISTS supplier_code VARCHAR(30);
@@ -0,0 +1,25 @@
+-- Migration: 049_create_balance_reservations
+-- Description: Balance reservations held in balances.pending_amount while a provider call is in flight
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS balance_reservations (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL,
+    reference_type VARCHAR(30) NOT NULL,         -- prepaid, transfer
+    reference_id VARCHAR(36) NOT NULL,           -- prepaid order ID, transfer transaction ID
+    status VARCHAR(20) NOT NULL DEFAULT 'held',  -- held, settled, released
+    expires_at TIMESTAMP NOT NULL,               -- After this the recovery job resolves a held reservation
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Only one reservation per reference may be held at a time, which also
+-- stops two concurrent payments of the same order from both going through
+CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_reservations_held_reference
+    ON balance_reservations(reference_type, reference_id) WHERE status = 'held';
+CREATE INDEX IF NOT EXISTS idx_balance_reservations_held_expiry
+    ON balance_reservations(expires_at) WHERE status = 'held';
+CREATE INDEX IF NOT EXISTS idx_balance_reservations_reference
+    ON balance_reservations(reference_type, reference_id);
@@ -0,0 +1,46 @@
+-- Migration: 050_create_approval_policies
+-- Description: Multi-level approval policies, per-approver decisions, expiry and cancellation for admin approvals
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS admin_approval_policies (
+    id VARCHAR(36) PRIMARY KEY,
+    request_type VARCHAR(50) NOT NULL,           -- price_change, balance_adjustment
+    min_amount BIGINT NOT NULL DEFAULT 0,        -- Applies when the request amount is at least this; the highest match wins
+    required_approvals INT NOT NULL DEFAULT 1,
+    approver_role_id VARCHAR(64) REFERENCES admin_roles(id) ON DELETE SET NULL, -- NULL: any admin with approvals.act
+    expiry_hours INT NOT NULL DEFAULT 72,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_admin_approval_policies_required CHECK (required_approvals >= 1),
+    CONSTRAINT chk_admin_approval_policies_expiry CHECK (expiry_hours >= 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_approval_policies_type_amount
+    ON admin_approval_policies(request_type, min_amount);
+
+-- The policy is copied onto the request when it is created, so editing a
+-- policy does not change the rules of requests already in the queue
+ALTER TABLE admin_approval_requests
+    ADD COLUMN IF NOT EXISTS amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS policy_id VARCHAR(36),
+    ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS approver_role_id VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+-- status now also: cancelled, expired
+CREATE INDEX IF NOT EXISTS idx_admin_approval_requests_pending_expiry
+    ON admin_approval_requests(expires_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS admin_approval_decisions (
+    approval_request_id VARCHAR(36) NOT NULL REFERENCES admin_approval_requests(id) ON DELETE CASCADE,
+    approver_id VARCHAR(36) NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (approval_request_id, approver_id)
+);
+
+INSERT INTO admin_approval_policies (id, request_type, min_amount, required_approvals, approver_role_id, expiry_hours) VALUES
+('apl_price', 'price_change', 0, 1, NULL, 72),
+('apl_balance', 'balance_adjustment', 0, 1, NULL, 72),
+('apl_balance_10m', 'balance_adjustment', 10000000, 2, 'finance_approver', 48)
+ON CONFLICT (id) DO NOTHING;
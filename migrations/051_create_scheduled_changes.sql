@@ -0,0 +1,29 @@
+-- Migration: 051_create_scheduled_changes
+-- Description: Catalog changes (services, banners, vouchers) applied and optionally reverted on a schedule
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS scheduled_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    resource_type VARCHAR(30) NOT NULL,               -- service, banner, voucher
+    resource_id VARCHAR(80) NOT NULL,
+    payload JSONB NOT NULL,                           -- Fields to change; the rest keep their value at effective_at
+    previous_payload JSONB,                           -- Snapshot taken when applied, restored at revert_at
+    effective_at TIMESTAMP NOT NULL,
+    revert_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',  -- scheduled, applied, reverted, cancelled, failed
+    notes TEXT,
+    error_message TEXT,
+    applied_at TIMESTAMP,
+    reverted_at TIMESTAMP,
+    created_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_scheduled_changes_revert CHECK (revert_at IS NULL OR revert_at > effective_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_due
+    ON scheduled_changes(effective_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_revert_due
+    ON scheduled_changes(revert_at) WHERE status = 'applied' AND revert_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_resource
+    ON scheduled_changes(resource_type, resource_id);
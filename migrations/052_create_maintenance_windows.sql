@@ -0,0 +1,21 @@
+-- Migration: 052_create_maintenance_windows
+-- Description: Maintenance registry blocking a service type, an operator/brand, or a transfer bank
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id VARCHAR(36) PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL,            -- service, operator, bank
+    target VARCHAR(80) NOT NULL,           -- service type (pln_prepaid, transfer), operator ID or brand, bank code
+    message TEXT NOT NULL,                 -- Shown to users
+    starts_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP,                     -- ETA; NULL until the end is known
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_maintenance_windows_scope CHECK (scope IN ('service', 'operator', 'bank')),
+    CONSTRAINT chk_maintenance_windows_period CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_active
+    ON maintenance_windows(scope, target) WHERE is_active = TRUE;
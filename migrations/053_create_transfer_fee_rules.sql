@@ -0,0 +1,55 @@
+-- Migration: 053_create_transfer_fee_rules
+-- Description: Transfer fee schedule per bank, amount band, user tier and method, and KYC-based transfer limits
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS transfer_fee_rules (
+    id VARCHAR(36) PRIMARY KEY,
+    bank_code VARCHAR(10),                      -- NULL: any bank
+    user_tier VARCHAR(20),                      -- NULL: any tier
+    method VARCHAR(10) NOT NULL,                -- bifast, online, rtgs
+    min_amount BIGINT NOT NULL DEFAULT 0,
+    max_amount BIGINT,                          -- NULL: no upper bound
+    fee BIGINT NOT NULL DEFAULT 0,
+    priority INT NOT NULL DEFAULT 0,            -- Breaks ties between equally specific rules
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_transfer_fee_rules_method CHECK (method IN ('bifast', 'online', 'rtgs')),
+    CONSTRAINT chk_transfer_fee_rules_band CHECK (max_amount IS NULL OR max_amount >= min_amount),
+    CONSTRAINT chk_transfer_fee_rules_fee CHECK (fee >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_fee_rules_active ON transfer_fee_rules(method) WHERE is_active = TRUE;
+
+CREATE TABLE IF NOT EXISTS transfer_limits (
+    kyc_status VARCHAR(20) PRIMARY KEY,         -- unverified, pending, verified, rejected
+    daily_limit BIGINT NOT NULL DEFAULT 0,      -- 0: transfers not allowed
+    monthly_limit BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE transfer_inquiries
+    ADD COLUMN IF NOT EXISTS method VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS fee_rule_id VARCHAR(36);
+
+ALTER TABLE transfer_transactions
+    ADD COLUMN IF NOT EXISTS method VARCHAR(10);
+
+-- Daily and monthly usage is summed per user over recent transfers
+CREATE INDEX IF NOT EXISTS idx_transfer_transactions_user_created
+    ON transfer_transactions(user_id, created_at);
+
+-- BCA stays free over the online network, as before the fee schedule
+INSERT INTO transfer_fee_rules (id, bank_code, user_tier, method, min_amount, max_amount, fee) VALUES
+('tfr_bifast', NULL, NULL, 'bifast', 10000, 250000000, 2500),
+('tfr_online', NULL, NULL, 'online', 10000, 50000000, 6500),
+('tfr_online_bca', '014', NULL, 'online', 10000, 50000000, 0),
+('tfr_rtgs', NULL, NULL, 'rtgs', 100000000, NULL, 35000)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO transfer_limits (kyc_status, daily_limit, monthly_limit) VALUES
+('unverified', 0, 0),
+('pending', 0, 0),
+('rejected', 0, 0),
+('verified', 100000000, 500000000)
+ON CONFLICT (kyc_status) DO NOTHING;
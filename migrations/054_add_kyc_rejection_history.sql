@@ -0,0 +1,8 @@
+-- Migration: 054_add_kyc_rejection_history
+-- Description: Lookup of the latest KYC decision per user for rejection feedback and resubmission
+-- Created: 2026-10-18
+
+-- verification_rejected entries carry the structured reasons in metadata:
+-- {"reasons": [{"code", "artefact", "step", "message", "note"}], "resubmitFromStep": n}
+CREATE INDEX IF NOT EXISTS idx_kyc_history_user_action_created
+    ON kyc_history(user_id, action, created_at DESC);
@@ -0,0 +1,21 @@
+-- Migration: 055_create_kyc_identity_matches
+-- Description: Duplicate identity matches (same NIK or same face) between KYC submissions
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS kyc_identity_matches (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,         -- Account under verification
+    matched_user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Already verified account it collides with
+    match_type VARCHAR(10) NOT NULL,                 -- nik, face
+    similarity DECIMAL(5,2),                         -- Face similarity; NULL for NIK matches
+    detected_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP,                           -- Set when an admin approves or rejects the account
+    CONSTRAINT chk_kyc_identity_matches_type CHECK (match_type IN ('nik', 'face')),
+    CONSTRAINT uq_kyc_identity_matches UNIQUE (user_id, matched_user_id, match_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_identity_matches_open
+    ON kyc_identity_matches(user_id) WHERE resolved_at IS NULL;
+
+-- Candidate lookup for face comparison
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_date_of_birth ON kyc_verifications(date_of_birth);
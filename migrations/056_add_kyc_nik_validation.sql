@@ -0,0 +1,9 @@
+-- Migration: 056_add_kyc_nik_validation
+-- Description: Store the NIK structural validation (region, birth date, gender) for manual KYC review
+-- Created: 2026-10-18
+
+-- {"valid", "info": {...}, "mismatches": [{"field", "nik", "other", "message"}], "checkedAt"}
+ALTER TABLE kyc_sessions ADD COLUMN IF NOT EXISTS nik_validation JSONB;
+
+-- Copied from the session when the verification is stored, since the session is deleted then
+ALTER TABLE kyc_verifications ADD COLUMN IF NOT EXISTS nik_validation JSONB;
@@ -0,0 +1,14 @@
+-- Migration: 057_seed_account_limits
+-- Description: Seed the KYC-tiered account limits setting
+-- Created: 2026-10-18
+
+-- {"rules": [{"kycLevel", "tier", "maxBalance", "perTransaction", "dailyOutgoing", "monthlyOutgoing"}]}
+-- A zero cap means no limit; a rule with a tier overrides the rule without one.
+INSERT INTO admin_settings (key, value, description)
+VALUES (
+    'account_limits',
+    '{"rules":[{"kycLevel":"unverified","maxBalance":2000000,"perTransaction":1000000,"dailyOutgoing":2000000,"monthlyOutgoing":20000000},{"kycLevel":"verified","maxBalance":20000000,"perTransaction":20000000,"dailyOutgoing":100000000,"monthlyOutgoing":500000000}]}',
+    'Limit saldo dan transaksi keluar per level KYC dan tier'
+)
+ON CONFLICT (key) DO NOTHING;
+
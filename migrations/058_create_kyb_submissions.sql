@@ -0,0 +1,67 @@
+-- Migration: 058_create_kyb_submissions
+-- Description: Business (KYB) verification for merchants, its admin review permissions and merchant account limits
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS kyb_submissions (
+    id VARCHAR(36) PRIMARY KEY,                          -- kyb_xxx
+    user_id VARCHAR(36) UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',         -- draft, pending, approved, rejected
+    business_name VARCHAR(100) NOT NULL DEFAULT '',
+    license_type VARCHAR(10) NOT NULL DEFAULT '',        -- NIB, SIUP
+    license_number VARCHAR(50) NOT NULL DEFAULT '',
+    license_url TEXT,
+    npwp_number VARCHAR(16) NOT NULL DEFAULT '',
+    npwp_url TEXT,
+    storefront_url TEXT,
+    address_street TEXT NOT NULL DEFAULT '',
+    province_code VARCHAR(10) NOT NULL DEFAULT '',
+    province_name VARCHAR(100) NOT NULL DEFAULT '',
+    city_code VARCHAR(10) NOT NULL DEFAULT '',
+    city_name VARCHAR(100) NOT NULL DEFAULT '',
+    district_code VARCHAR(10) NOT NULL DEFAULT '',
+    district_name VARCHAR(100) NOT NULL DEFAULT '',
+    sub_district_code VARCHAR(15) NOT NULL DEFAULT '',
+    sub_district_name VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code VARCHAR(10) NOT NULL DEFAULT '',
+    rejection_reason TEXT,
+    submitted_at TIMESTAMP,
+    reviewed_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyb_submissions_status ON kyb_submissions(status, submitted_at);
+
+INSERT INTO admin_permissions (key, module, action, description) VALUES
+('kyb.view', 'kyb', 'view', 'Lihat review KYB merchant'),
+('kyb.approve', 'kyb', 'approve', 'Approve atau reject KYB merchant')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO admin_role_permissions (role_id, permission_key)
+SELECT 'super_admin', key FROM admin_permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO admin_role_permissions (role_id, permission_key) VALUES
+('admin_operasional', 'kyb.view'),
+('admin_operasional', 'kyb.approve'),
+('compliance_kyc', 'kyb.view'),
+('compliance_kyc', 'kyb.approve'),
+('customer_service', 'kyb.view'),
+('auditor_viewer', 'kyb.view')
+ON CONFLICT DO NOTHING;
+
+-- Approved merchants get their own account limits; without a merchant rule
+-- they fall back to the verified rule
+UPDATE admin_settings
+SET value = jsonb_set(
+        value,
+        '{rules}',
+        COALESCE(value->'rules', '[]'::jsonb) || '[{"kycLevel":"merchant","maxBalance":100000000,"perTransaction":50000000,"dailyOutgoing":250000000,"monthlyOutgoing":2000000000}]'::jsonb
+    ),
+    updated_at = NOW()
+WHERE key = 'account_limits'
+  AND NOT EXISTS (
+      SELECT 1 FROM jsonb_array_elements(COALESCE(value->'rules', '[]'::jsonb)) rule
+      WHERE rule->>'kycLevel' = 'merchant'
+  );
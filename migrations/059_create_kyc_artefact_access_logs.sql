@@ -0,0 +1,27 @@
+-- Migration: 059_create_kyc_artefact_access_logs
+-- Description: Access log for KYC/KYB artefact viewing links and purge markers for the retention job
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS kyc_artefact_access_logs (
+    id VARCHAR(36) PRIMARY KEY,                          -- kal_xxx
+    admin_user_id VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    artefact VARCHAR(30) NOT NULL,                       -- ktp, face, face_with_ktp, license, npwp, storefront
+    object_key TEXT NOT NULL,
+    purpose VARCHAR(20) NOT NULL,                        -- admin_review, provider
+    action VARCHAR(20) NOT NULL,                         -- issued, viewed
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_artefact_access_logs_user ON kyc_artefact_access_logs(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_kyc_artefact_access_logs_admin ON kyc_artefact_access_logs(admin_user_id, created_at DESC);
+
+COMMENT ON TABLE kyc_artefact_access_logs IS 'Every issued and opened link to a KYC/KYB artefact';
+
+ALTER TABLE kyc_verifications ADD COLUMN IF NOT EXISTS artefacts_purged_at TIMESTAMP;
+ALTER TABLE kyb_submissions ADD COLUMN IF NOT EXISTS documents_purged_at TIMESTAMP;
+
+COMMENT ON COLUMN kyc_verifications.artefacts_purged_at IS 'Set when the retention job deleted the KTP and face photos';
+COMMENT ON COLUMN kyb_submissions.documents_purged_at IS 'Set when the retention job deleted the business documents';
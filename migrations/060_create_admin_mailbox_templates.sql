@@ -0,0 +1,47 @@
+-- Migration: 060_create_admin_mailbox_templates
+-- Description: Canned responses for the admin mailbox, global or per mailbox, with usage tracking
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS admin_mailbox_templates (
+    id VARCHAR(36) PRIMARY KEY,                          -- amt_xxx
+    mailbox_id VARCHAR(36) REFERENCES admin_mailboxes(id) ON DELETE CASCADE, -- NULL = global
+    name VARCHAR(100) NOT NULL,
+    shortcut VARCHAR(40),                                -- e.g. "refund", typed as /refund
+    subject VARCHAR(255),
+    body TEXT NOT NULL,                                  -- may contain {{customer.name}} etc.
+    usage_count INT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    updated_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_mailbox_templates_mailbox_id ON admin_mailbox_templates(mailbox_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_mailbox_templates_shortcut
+    ON admin_mailbox_templates(COALESCE(mailbox_id, ''), shortcut)
+    WHERE shortcut IS NOT NULL AND is_active;
+
+CREATE TABLE IF NOT EXISTS admin_mailbox_template_usages (
+    id VARCHAR(36) PRIMARY KEY,                          -- amtu_xxx
+    template_id VARCHAR(36) NOT NULL REFERENCES admin_mailbox_templates(id) ON DELETE CASCADE,
+    thread_id VARCHAR(36) REFERENCES admin_email_threads(id) ON DELETE SET NULL,
+    admin_user_id VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_mailbox_template_usages_template_id ON admin_mailbox_template_usages(template_id, created_at DESC);
+
+INSERT INTO admin_permissions (key, module, action, description) VALUES
+('mailboxes.templates.manage', 'mailboxes', 'templates_manage', 'Kelola template balasan mailbox')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO admin_role_permissions (role_id, permission_key)
+SELECT 'super_admin', key FROM admin_permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO admin_role_permissions (role_id, permission_key) VALUES
+('customer_service', 'mailboxes.templates.manage'),
+('director', 'mailboxes.templates.manage')
+ON CONFLICT DO NOTHING;
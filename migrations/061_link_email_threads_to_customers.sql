@@ -0,0 +1,24 @@
+-- Migration: 061_link_email_threads_to_customers
+-- Description: Link admin email threads to the customer and to their transactions, deposits and KYC
+-- Created: 2026-10-18
+
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS customer_user_id VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS customer_link_source VARCHAR(20); -- email, phone, manual
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS customer_linked_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_admin_email_threads_customer_user_id
+    ON admin_email_threads(customer_user_id, latest_message_at DESC)
+    WHERE customer_user_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS admin_email_thread_links (
+    id VARCHAR(36) PRIMARY KEY,                          -- aetl_xxx
+    thread_id VARCHAR(36) NOT NULL REFERENCES admin_email_threads(id) ON DELETE CASCADE,
+    resource_type VARCHAR(20) NOT NULL,                  -- transaction, deposit, kyc
+    resource_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    created_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (thread_id, resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_email_thread_links_resource ON admin_email_thread_links(resource_type, resource_id);
@@ -0,0 +1,36 @@
+-- Migration: 062_create_admin_mailbox_sla
+-- Description: SLA policies per mailbox, SLA timers on threads and auto-assignment state
+-- Created: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS admin_mailbox_sla_policies (
+    mailbox_id VARCHAR(36) PRIMARY KEY REFERENCES admin_mailboxes(id) ON DELETE CASCADE,
+    first_response_minutes INT NOT NULL DEFAULT 0,       -- 0 = no first response target
+    resolution_minutes INT NOT NULL DEFAULT 0,           -- 0 = no resolution target
+    business_hours JSONB NOT NULL DEFAULT '[]'::jsonb,   -- [{"weekday":1,"start":"08:00","end":"17:00"}], empty = 24/7
+    timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta',
+    auto_assign_mode VARCHAR(20) NOT NULL DEFAULT 'none', -- none, round_robin, load_based
+    escalation_action VARCHAR(20) NOT NULL DEFAULT 'notify', -- notify, reassign
+    escalation_admin_id VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE admin_mailbox_members ADD COLUMN IF NOT EXISTS last_assigned_at TIMESTAMP;
+
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS sla_started_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS first_response_due_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS resolution_due_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS first_responded_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS first_response_breached_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS resolution_breached_at TIMESTAMP;
+ALTER TABLE admin_email_threads ADD COLUMN IF NOT EXISTS escalation_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_admin_email_threads_first_response_due
+    ON admin_email_threads(first_response_due_at)
+    WHERE first_responded_at IS NULL AND resolved_at IS NULL AND first_response_breached_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_admin_email_threads_resolution_due
+    ON admin_email_threads(resolution_due_at)
+    WHERE resolved_at IS NULL AND resolution_breached_at IS NULL;
@@ -0,0 +1,25 @@
+-- Migration: 063_add_admin_mailbox_search
+-- Description: Full-text search over admin mailbox messages and the extracted text of their attachments
+-- Created: 2026-10-18
+
+-- 'simple' keeps words as written: mail here mixes Indonesian and English and
+-- Postgres ships no Indonesian stemmer. Bodies are capped to stay under the
+-- tsvector size limit.
+ALTER TABLE admin_email_messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(subject, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(sender_name, '') || ' ' || COALESCE(sender_address, '')), 'B') ||
+        setweight(to_tsvector('simple', LEFT(COALESCE(text_body, ''), 200000)), 'C') ||
+        setweight(to_tsvector('simple', LEFT(regexp_replace(COALESCE(html_body, ''), '<[^>]*>', ' ', 'g'), 200000)), 'D')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_admin_email_messages_search_vector ON admin_email_messages USING GIN (search_vector);
+
+ALTER TABLE admin_email_attachments ADD COLUMN IF NOT EXISTS extracted_text TEXT; -- PDF/TXT text, NULL otherwise
+ALTER TABLE admin_email_attachments ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(file_name, '')), 'A') ||
+        setweight(to_tsvector('simple', LEFT(COALESCE(extracted_text, ''), 200000)), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_admin_email_attachments_search_vector ON admin_email_attachments USING GIN (search_vector);
@@ -0,0 +1,8 @@
+-- Migration: 064_add_reservation_provider_called
+-- Description: Mark balance reservations whose provider call went out so recovery can tell unsent payments apart
+-- Created: 2026-10-18
+
+-- Set right before the outbound provider call. Recovery releases held
+-- reservations without it (the provider never saw the payment) and asks the
+-- provider for the outcome of the others.
+ALTER TABLE balance_reservations ADD COLUMN IF NOT EXISTS provider_called_at TIMESTAMP;
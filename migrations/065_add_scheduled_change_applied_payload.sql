@@ -0,0 +1,5 @@
+-- Migration: 065_add_scheduled_change_applied_payload
+-- Description: Keep the values a scheduled change wrote so a revert only undoes fields nobody edited since
+-- Created: 2026-10-18
+
+ALTER TABLE scheduled_changes ADD COLUMN IF NOT EXISTS applied_payload JSONB; -- Changed fields as read back right after apply
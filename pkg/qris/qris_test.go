@@ -0,0 +1,71 @@
+package qris
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// staticPayload builds a static merchant payload with a valid checksum
+func staticPayload(name, city string) string {
+	body := "000201" + "010211" +
+		"26310017ID.CO.EXAMPLE.WWW0106ABC123" +
+		"5204549953033605802ID" +
+		fmt.Sprintf("59%02d%s60%02d%s", len(name), name, len(city), city) +
+		"610512345" + "6304"
+	return body + CRC(body)
+}
+
+func TestCRC(t *testing.T) {
+	if got := CRC("123456789"); got != "29B1" {
+		t.Errorf("CRC = %s; want 29B1", got)
+	}
+}
+
+func TestSetMerchant(t *testing.T) {
+	payload := staticPayload("BUDI SANTOSO", "JAKARTA")
+
+	got, err := SetMerchant(payload, "TOKO MAJU JAYA", "BANDUNG")
+	if err != nil {
+		t.Fatalf("SetMerchant: %v", err)
+	}
+	if want := staticPayload("TOKO MAJU JAYA", "BANDUNG"); got != want {
+		t.Errorf("SetMerchant =\n %s\nwant\n %s", got, want)
+	}
+
+	got, err = SetMerchant(payload, "TOKO MAJU JAYA", "")
+	if err != nil {
+		t.Fatalf("SetMerchant without city: %v", err)
+	}
+	if !strings.Contains(got, "6007JAKARTA") {
+		t.Errorf("empty city replaced the current one: %s", got)
+	}
+}
+
+func TestSetMerchantRejectsInvalidPayload(t *testing.T) {
+	payload := staticPayload("BUDI SANTOSO", "JAKARTA")
+	for name, bad := range map[string]string{
+		"checksum":  payload[:len(payload)-4] + "0000",
+		"truncated": payload[:len(payload)-10],
+		"no crc":    "000201010211",
+	} {
+		if _, err := SetMerchant(bad, "TOKO", "BANDUNG"); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("%s: err = %v; want ErrInvalidPayload", name, err)
+		}
+	}
+	if _, err := SetMerchant(payload, "TOKO\x00", "BANDUNG"); err == nil {
+		t.Error("control character in the merchant name was accepted")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	image, err := PNG(staticPayload("TOKO MAJU JAYA", "BANDUNG"), 256)
+	if err != nil {
+		t.Fatalf("PNG: %v", err)
+	}
+	if !bytes.HasPrefix(image, []byte("\x89PNG")) {
+		t.Error("PNG did not return a PNG image")
+	}
+}
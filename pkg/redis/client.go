@@ -119,3 +119,22 @@ func HomeServicesKey() string {
 func HomeBannersKey(placement, tier string) string {
 	return fmt.Sprintf("home:banners:%s:%s", placement, tier)
 }
+
+// Operator cache keys
+func OperatorPrefixTrieKey() string {
+	return "operators:prefix_trie"
+}
+
+// Territory search cache keys
+func TerritorySearchKey(query string, limit int) string {
+	return fmt.Sprintf("territory:search:%d:%s", limit, query)
+}
+
+func TerritorySearchHitsKey(query string) string {
+	return fmt.Sprintf("territory:search_hits:%s", query)
+}
+
+// Idempotency Keys
+func IdempotencyKey(userID, route, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s:%s", userID, route, key)
+}
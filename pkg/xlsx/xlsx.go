@@ -0,0 +1,365 @@
+// Package xlsx implements just enough of the Office Open XML spreadsheet
+// format to exchange simple tables: reading the first worksheet of a workbook
+// as rows of strings, and writing a single-sheet workbook of string cells.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the MIME type of .xlsx files
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// ErrNoWorksheet is returned when a workbook has no readable worksheet
+var ErrNoWorksheet = errors.New("xlsx: workbook has no worksheet")
+
+// ErrTooLarge is returned when a worksheet addresses more rows, columns or
+// cells than the reader accepts
+var ErrTooLarge = errors.New("xlsx: worksheet is too large")
+
+const (
+	// MaxRows and MaxColumns are the largest sheet dimensions Excel supports
+	MaxRows    = 1 << 20
+	MaxColumns = 16384
+
+	// maxPartSize caps the decompressed size of a single workbook part to
+	// guard against zip bombs
+	maxPartSize = 64 << 20
+	// maxCells caps the cells Read materializes, including the empty ones
+	// padding gaps, so sparse cell references cannot exhaust memory
+	maxCells = 4 << 20
+)
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type richTextXML struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richTextXML) text() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var sb strings.Builder
+	sb.WriteString(r.T)
+	for _, run := range r.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+type sharedStringsXML struct {
+	Items []richTextXML `xml:"si"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string      `xml:"r,attr"`
+			T      string      `xml:"t,attr"`
+			V      string      `xml:"v"`
+			Inline richTextXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Read parses the first worksheet of an .xlsx file into rows of cell text.
+// Gaps left by empty cells are filled with empty strings. Sheets with rows
+// beyond maxRows (MaxRows when zero), cells beyond MaxColumns or too many
+// cells overall fail with ErrTooLarge before anything is allocated for them.
+func Read(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	if maxRows <= 0 || maxRows > MaxRows {
+		maxRows = MaxRows
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: invalid archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared sharedStringsXML
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrNoWorksheet
+	}
+	var sheet worksheetXML
+	if err := decodePart(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	if len(sheet.Rows) > maxRows {
+		return nil, ErrTooLarge
+	}
+	rows := make([][]string, 0, len(sheet.Rows))
+	cells := 0
+	for i, row := range sheet.Rows {
+		// Pad skipped rows so row numbers stay meaningful to callers
+		rowIndex := row.R - 1
+		if rowIndex < 0 {
+			rowIndex = i
+		}
+		if rowIndex >= maxRows {
+			return nil, ErrTooLarge
+		}
+		for len(rows) < rowIndex {
+			rows = append(rows, []string{})
+		}
+
+		values := []string{}
+		for j, cell := range row.Cells {
+			col := j
+			if cell.R != "" {
+				if parsed, ok := columnIndex(cell.R); ok {
+					col = parsed
+				}
+			}
+			if col >= MaxColumns {
+				return nil, ErrTooLarge
+			}
+			if col >= len(values) {
+				if cells += col + 1 - len(values); cells > maxCells {
+					return nil, ErrTooLarge
+				}
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			var text string
+			switch cell.T {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(cell.V))
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("xlsx: invalid shared string reference in cell %s", cell.R)
+				}
+				text = shared.Items[idx].text()
+			case "inlineStr":
+				text = cell.Inline.text()
+			default:
+				text = cell.V
+			}
+
+			if col < len(values) {
+				values[col] = text
+			} else {
+				values = append(values, text)
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+// Write encodes rows as a single-sheet .xlsx workbook. All cells are written
+// as inline strings so values like phone numbers keep their leading zeros.
+func Write(w io.Writer, sheetName string, rows [][]string) error {
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookTemplate, escape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+		{"xl/worksheets/sheet1.xml", worksheet(rows)},
+	}
+
+	for _, part := range parts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", ErrNoWorksheet
+	}
+	var wb workbookXML
+	if err := decodePart(wbFile, &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", ErrNoWorksheet
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	var rels relationshipsXML
+	if err := decodePart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			return strings.TrimPrefix(target, "/"), nil
+		}
+		return path.Join("xl", target), nil
+	}
+
+	return fallback, nil
+}
+
+func decodePart(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return fmt.Errorf("xlsx: part %s is too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: failed to parse %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex converts a cell reference such as "AB12" to a zero-based column index
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch >= 'a' && ch <= 'z' {
+			ch -= 'a' - 'A'
+		}
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+		if col > MaxColumns {
+			return MaxColumns, true // Out of range; stop before the value overflows
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// columnName converts a zero-based column index to its letter name (0 -> A, 27 -> AB)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func worksheet(rows [][]string) string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, i+1)
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+			fmt.Fprintf(&sb, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				columnName(j), i+1, escape(value))
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookTemplate = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+	`<borders count="1"><border/></borders>` +
+	`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+	`<cellXfs count="1"><xf xfId="0"/></cellXfs>` +
+	`</styleSheet>`
@@ -0,0 +1,95 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"name", "type", "value"},
+		{"Ayah & Ibu", "phone", "081234567890"},
+		{"", "pln", "123456789012"},
+		{"Budi <BCA>", "bank", "0123456789"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Kontak", rows); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 0)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("round trip mismatch:\n got %q\nwant %q", got, rows)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	cases := map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "AB2": 27}
+	for ref, want := range cases {
+		got, ok := columnIndex(ref)
+		if !ok || got != want {
+			t.Errorf("columnIndex(%q) = %d, %v; want %d", ref, got, ok, want)
+		}
+		if name := columnName(want); ref[:len(name)] != name {
+			t.Errorf("columnName(%d) = %q; want prefix of %q", want, name, ref)
+		}
+	}
+}
+
+// sheetWorkbook builds a workbook whose first worksheet has the given sheetData
+func sheetWorkbook(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml":            fmt.Sprintf(workbookTemplate, "Sheet1"),
+		"xl/_rels/workbook.xml.rels": workbookRelsXML,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>` +
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		fw, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(fw, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadRejectsOversizedReferences(t *testing.T) {
+	cases := map[string]string{
+		"row beyond limit":    `<row r="2000000000"><c r="A2000000000" t="inlineStr"><is><t>x</t></is></c></row>`,
+		"column beyond XFD":   `<row r="1"><c r="ZZZZZZZ1" t="inlineStr"><is><t>x</t></is></c></row>`,
+		"sparse wide columns": strings.Repeat(`<row><c r="XFC1" t="inlineStr"><is><t>x</t></is></c></row>`, 300),
+	}
+	for name, sheetData := range cases {
+		data := sheetWorkbook(t, sheetData)
+		if _, err := Read(bytes.NewReader(data), int64(len(data)), 1000); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: Read error = %v; want ErrTooLarge", name, err)
+		}
+	}
+
+	data := sheetWorkbook(t, `<row r="3"><c r="B3" t="inlineStr"><is><t>x</t></is></c></row>`)
+	rows, err := Read(bytes.NewReader(data), int64(len(data)), 1000)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if want := [][]string{{}, {}, {"", "x"}}; !reflect.DeepEqual(rows, want) {
+		t.Fatalf("Read = %q; want %q", rows, want)
+	}
+}